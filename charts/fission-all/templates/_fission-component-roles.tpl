@@ -372,6 +372,15 @@ rules:
   - get
   - list
   - watch
+- apiGroups:
+  - ""
+  # Get only: the router reads the Secret an HTTPTrigger's apiKey block, or an
+  # async webhook destination's signingSecret, names on demand and never lists
+  # or watches Secrets.
+  resources:
+  - secrets
+  verbs:
+  - get
 {{- end }}
 {{- define "storagesvc-rules" }}
 rules:
@@ -252,6 +252,16 @@ rules:
   - get
   - list
   - watch
+{{- range .Values.kubewatcher.watchRules }}
+- apiGroups:
+{{ toYaml .apiGroups | indent 2 }}
+  resources:
+{{ toYaml .resources | indent 2 }}
+  verbs:
+  - get
+  - list
+  - watch
+{{- end }}
 {{- end }}
 {{- define "statestore-mqt-kuberules" }}
 rules:
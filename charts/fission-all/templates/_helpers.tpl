@@ -83,6 +83,21 @@ auth:
   authUriPath: {{ .Values.authentication.authUriPath | default "/auth/login" | quote}}
   jwtExpiryTime: {{ .Values.authentication.jwtExpiryTime | default 120 }}
   jwtIssuer: {{ .Values.authentication.jwtIssuer | default "fission" | quote }}
+  {{- with .Values.authentication.oidc }}
+  {{- if .issuerUrl }}
+  oidc:
+    issuerUrl: {{ .issuerUrl | quote }}
+    {{- with .jwksUrl }}
+    jwksUrl: {{ . | quote }}
+    {{- end }}
+    {{- with .audiences }}
+    audiences: {{ toJson . }}
+    {{- end }}
+  {{- end }}
+  {{- end }}
+  {{- with .Values.authentication.forwardClaims }}
+  forwardClaims: {{ toJson . }}
+  {{- end }}
   {{- end }}
 {{- end -}}
 
@@ -382,6 +397,22 @@ Mirrored by pkg/svcinfo.PortStatestore (RFC-0021).
 {{ (.Values.statestore | default dict).port | default 8891 }}
 {{- end -}}
 
+{{/*
+fission.statestoreEngine is the embedded statestore engine: sqlite (the
+default) or raft.
+*/}}
+{{- define "fission.statestoreEngine" -}}
+{{ dig "embedded" "engine" "sqlite" (.Values.statestore | default dict) | default "sqlite" }}
+{{- end -}}
+
+{{/*
+fission.statestorePeerPort is the raft engine's transport port between
+statestore replicas. Mirrored by pkg/svcinfo.PortStatestorePeer.
+*/}}
+{{- define "fission.statestorePeerPort" -}}
+{{ dig "embedded" "peerPort" 8894 (.Values.statestore | default dict) | default 8894 }}
+{{- end -}}
+
 {{/*
 fission.statesvcPort is the statesvc function-facing keyed-state API port.
 Mirrored by pkg/svcinfo.PortStateSvc (RFC-0023).
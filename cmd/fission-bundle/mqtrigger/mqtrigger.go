@@ -26,11 +26,13 @@ import (
 	"github.com/fission/fission/pkg/statestore"
 
 	// Statestore drivers the statestore MQ provider opens via STATESTORE_DRIVER:
-	// the HTTP client (embedded mode → svc/statestore) and Postgres (external
-	// mode → the DB directly). Registered here, not in the provider package, so
-	// importing the provider for its validator (fission CLI) links no drivers.
+	// the HTTP client (embedded mode → svc/statestore), Postgres and Redis
+	// (external mode → the store directly). Registered here, not in the provider
+	// package, so importing the provider for its validator (fission CLI) links no
+	// drivers.
 	_ "github.com/fission/fission/pkg/statestore/client"
 	_ "github.com/fission/fission/pkg/statestore/postgres"
+	_ "github.com/fission/fission/pkg/statestore/redis"
 	"github.com/fission/fission/pkg/utils/crmanager"
 	"github.com/fission/fission/pkg/utils/metrics"
 )
@@ -23,6 +23,7 @@ import (
 	"github.com/fission/fission/pkg/fission-cli/cmd/mqtrigger"
 	_package "github.com/fission/fission/pkg/fission-cli/cmd/package"
 	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/cmd/statestore"
 	"github.com/fission/fission/pkg/fission-cli/cmd/support"
 	"github.com/fission/fission/pkg/fission-cli/cmd/tenant"
 	"github.com/fission/fission/pkg/fission-cli/cmd/timetrigger"
@@ -94,7 +95,7 @@ func App(clientOptions cmd.ClientOptions) *cobra.Command {
 	groups = append(groups, helptemplate.CreateCmdGroup("Workflow Commands", workflow.Commands()))
 	groups = append(groups, helptemplate.CreateCmdGroup("Deploy Strategies Commands", canaryconfig.Commands()))
 	groups = append(groups, helptemplate.CreateCmdGroup("Declarative Application Commands", spec.Commands()))
-	groups = append(groups, helptemplate.CreateCmdGroup("Administration Commands", tenant.Commands()))
+	groups = append(groups, helptemplate.CreateCmdGroup("Administration Commands", tenant.Commands(), statestore.Commands()))
 	groups = append(groups, helptemplate.CreateCmdGroup("Other Commands", support.Commands(), version.Commands(), check.Commands()))
 	groups.Add(rootCmd)
 
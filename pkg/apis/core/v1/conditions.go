@@ -165,14 +165,17 @@ const (
 	HTTPTriggerReasonFunctionNotFound     = "FunctionNotFound"     // the referenced function does not exist; the route is not served
 	HTTPTriggerReasonRouteConflict        = "RouteConflict"        // another trigger registered the same route shape and wins by precedence; this one is shadowed
 	HTTPTriggerReasonInvalidRouteTemplate = "InvalidRouteTemplate" // the path's gorilla template does not compile (capturing groups, unbalanced braces, ...)
+	HTTPTriggerReasonInvalidTransform     = "InvalidTransform"     // the transform's body schema is not a valid, self-contained JSON Schema
 
 	// KubernetesWatchTrigger condition reasons
 	KubernetesWatchTriggerReasonSubscribed  = "Subscribed"
 	KubernetesWatchTriggerReasonStartFailed = "WatchStartFailed"
 
 	// TimeTrigger condition reasons
-	TimeTriggerReasonCronRegistered = "CronRegistered"
-	TimeTriggerReasonInvalidCron    = "InvalidCron" // cron failed the robfig/cron parser (CEL cannot express it)
+	TimeTriggerReasonCronRegistered  = "CronRegistered"
+	TimeTriggerReasonInvalidCron     = "InvalidCron"     // cron failed the robfig/cron parser (CEL cannot express it)
+	TimeTriggerReasonInvalidTimeZone = "InvalidTimeZone" // timeZone is not in the tz database the timer was built with
+	TimeTriggerReasonInvalidPayload  = "InvalidPayload"  // body/headers templates fail to parse or render (see pkg/timer/payload)
 
 	// MessageQueueTrigger condition reasons
 	MessageQueueTriggerReasonSubscribed = "Subscribed"
@@ -174,6 +174,21 @@ const (
 	VersioningModeManual VersioningMode = "manual"
 )
 
+// TimeTrigger concurrency policies (TimeTriggerSpec.ConcurrencyPolicy), named
+// after their batch/v1 CronJob counterparts.
+const (
+	TimeTriggerConcurrencyAllow   TimeTriggerConcurrencyPolicy = "Allow"
+	TimeTriggerConcurrencyForbid  TimeTriggerConcurrencyPolicy = "Forbid"
+	TimeTriggerConcurrencyReplace TimeTriggerConcurrencyPolicy = "Replace"
+)
+
+// Rate-limit key sources (RateLimitKey.Source).
+const (
+	RateLimitKeyClientIP RateLimitKeySource = "clientip"
+	RateLimitKeyHeader   RateLimitKeySource = "header"
+	RateLimitKeyJWTClaim RateLimitKeySource = "jwtclaim"
+)
+
 // RFC-0023 keyed-state defaults and sticky-routing sources.
 const (
 	StickySourceHeader     StickySource = "header"
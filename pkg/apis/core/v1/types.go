@@ -211,6 +211,11 @@ type (
 	// +genclient
 	// +kubebuilder:object:root=true
 	// +kubebuilder:subresource:status
+	// +kubebuilder:printcolumn:name="Cron",type=string,JSONPath=`.spec.cron`
+	// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+	// +kubebuilder:printcolumn:name="Next Schedule",type=string,JSONPath=`.status.nextScheduleTime`
+	// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+	// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 	TimeTrigger struct {
 		metav1.TypeMeta   `json:",inline"`
 		metav1.ObjectMeta `json:"metadata"`
@@ -850,6 +855,11 @@ type (
 		// SecondaryVersion receives 100-Weight. Name-pinned only.
 		// +optional
 		SecondaryVersion string `json:"secondaryVersion,omitempty"`
+		// Mirror copies a sampled share of the requests of every HTTPTrigger
+		// resolving through this alias to a shadow version, e.g. the next
+		// release before it takes any weight. A trigger's own Mirror wins.
+		// +optional
+		Mirror *TrafficMirror `json:"mirror,omitempty"`
 	}
 
 	// AliasTargetRecord is one entry in FunctionAliasStatus.History: a
@@ -1297,6 +1307,30 @@ type (
 		// +optional
 		Invocation *InvocationConfig `json:"invocation,omitempty"`
 
+		// RateLimit, when non-nil, is the default request-rate limit for the
+		// HTTPTriggers that route to this function. One set of buckets is shared
+		// by every such trigger; a trigger with its own RateLimit uses that
+		// instead. Requests on the router's internal listener (message queue
+		// triggers, async delivery) are not limited.
+		// +optional
+		RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+		// CircuitBreaker, when non-nil, has the router stop sending requests
+		// to this function while it is failing: once failures cross a
+		// threshold the circuit opens and requests are answered 503 with a
+		// Retry-After header, until probe requests find the function healthy
+		// again. Each published version has its own circuit. Each router
+		// replica keeps its own.
+		// +optional
+		CircuitBreaker *CircuitBreakerConfig `json:"circuitBreaker,omitempty"`
+
+		// OutlierEjection, when non-nil, has the router stop sending requests
+		// to a single pod of this function that keeps failing while its other
+		// pods serve. Applies to pods the router admits requests to directly
+		// (the EndpointSlice-fed warm path).
+		// +optional
+		OutlierEjection *OutlierEjectionConfig `json:"outlierEjection,omitempty"`
+
 		// Maximum number of pods to be specialized which will serve requests
 		// This is optional. If not specified default value will be taken as 500
 		// +optional
@@ -1474,6 +1508,124 @@ type (
 		Name string `json:"name"`
 	}
 
+	// RateLimitKeySource selects what the router keys rate-limit buckets on.
+	// +kubebuilder:validation:Enum=clientip;header;jwtclaim
+	RateLimitKeySource string
+
+	// RateLimitConfig is a token-bucket request-rate limit enforced by the
+	// router. Each key gets a bucket of Burst tokens refilled at Requests per
+	// Period; a request finding its bucket empty is answered 429 with a
+	// Retry-After header and never reaches the function. When the router runs
+	// with the statestore, buckets live in its KV store so the limit holds
+	// across router replicas; otherwise each replica enforces it on its own.
+	RateLimitConfig struct {
+		// Requests is the number of requests allowed per Period.
+		// +kubebuilder:validation:Minimum=1
+		Requests int32 `json:"requests"`
+
+		// Period is the window Requests is measured over. Defaults to 1s.
+		// +optional
+		Period *metav1.Duration `json:"period,omitempty"`
+
+		// Burst is how many requests a key may send at once after being idle.
+		// Defaults to Requests.
+		// +optional
+		// +kubebuilder:validation:Minimum=0
+		Burst int32 `json:"burst,omitempty"`
+
+		// Key selects what requests are grouped by. nil gives all requests a
+		// single shared bucket.
+		// +optional
+		Key *RateLimitKey `json:"key,omitempty"`
+	}
+
+	// CircuitBreakerConfig is a function's circuit breaker. A request
+	// fails when the function answers 5xx or the router cannot get an answer
+	// from it; client disconnects and executor capacity rejections do not
+	// count. The circuit opens when either threshold is crossed, rejects
+	// every request for OpenDuration, then lets HalfOpenRequests probe
+	// requests through: if they all succeed it closes, if any fails it opens
+	// again.
+	// +kubebuilder:validation:XValidation:rule="has(self.consecutiveFailures) || has(self.failureRatePercent)",message="at least one of consecutiveFailures and failureRatePercent must be set"
+	CircuitBreakerConfig struct {
+		// ConsecutiveFailures opens the circuit after this many failed
+		// requests in a row.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+		// FailureRatePercent opens the circuit when at least this share of
+		// the requests in the last Window failed.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=100
+		FailureRatePercent int32 `json:"failureRatePercent,omitempty"`
+
+		// MinimumRequests is how many requests Window must hold before
+		// FailureRatePercent applies. Defaults to 20.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		MinimumRequests int32 `json:"minimumRequests,omitempty"`
+
+		// Window is the span FailureRatePercent is measured over. Defaults
+		// to 10s.
+		// +optional
+		Window *metav1.Duration `json:"window,omitempty"`
+
+		// OpenDuration is how long an open circuit rejects requests before
+		// probing the function. Defaults to 30s.
+		// +optional
+		OpenDuration *metav1.Duration `json:"openDuration,omitempty"`
+
+		// HalfOpenRequests is how many probe requests a half-open circuit
+		// lets through at a time, and how many must succeed to close it.
+		// Defaults to 1.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		HalfOpenRequests int32 `json:"halfOpenRequests,omitempty"`
+	}
+
+	// OutlierEjectionConfig ejects a function's pod that fails
+	// ConsecutiveFailures requests in a row, with failures counted as for
+	// CircuitBreakerConfig: the router sends it no requests for
+	// EjectionDuration, or until the function's endpoints change. A pod is
+	// not ejected when that would leave more than MaxEjectionPercent of the
+	// function's pods ejected, so a function failing everywhere is left to
+	// its circuit breaker.
+	OutlierEjectionConfig struct {
+		// ConsecutiveFailures ejects a pod after this many failed requests
+		// in a row.
+		// +kubebuilder:validation:Minimum=1
+		ConsecutiveFailures int32 `json:"consecutiveFailures"`
+
+		// EjectionDuration is how long an ejected pod gets no requests.
+		// Defaults to 30s.
+		// +optional
+		EjectionDuration *metav1.Duration `json:"ejectionDuration,omitempty"`
+
+		// MaxEjectionPercent caps the share of the function's pods ejected
+		// at once; one pod of several can always be ejected. Defaults to 50.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=100
+		MaxEjectionPercent int32 `json:"maxEjectionPercent,omitempty"`
+	}
+
+	// RateLimitKey declares how a rate limit's bucket key is taken from a
+	// request. A request without the header or claim is keyed by its client IP.
+	RateLimitKey struct {
+		// Source is where to take the key from: the client IP (the connection's
+		// address, or its last X-Forwarded-For entry when it is a trusted proxy),
+		// a request header, or a claim of the bearer JWT. Only claims router auth
+		// verified are read; without them the request is keyed by its client IP.
+		Source RateLimitKeySource `json:"source"`
+
+		// Name is the header or claim name, e.g. "X-Api-Key" or "sub". Unused
+		// for clientip.
+		// +optional
+		Name string `json:"name,omitempty"`
+	}
+
 	// InvocationConfig tunes RFC-0024 asynchronous invocation for a function.
 	// Presence of the enclosing FunctionSpec.Invocation is optional — a function
 	// without it still accepts async mode (X-Fission-Invoke-Mode: async) with
@@ -1503,6 +1655,23 @@ type (
 		// spent, or MaxAge exceeded).
 		// +optional
 		OnFailure *DestinationRef `json:"onFailure,omitempty"`
+
+		// MaxInFlight caps how many async deliveries to this function run at
+		// once, across all router replicas. An invocation over the cap stays
+		// queued without spending a retry attempt (it still ages toward
+		// MaxAge). nil means no cap. Must be >= 1 when set.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		MaxInFlight *int32 `json:"maxInFlight,omitempty"`
+
+		// MaxDeliveriesPerSecond caps the rate at which async deliveries to this
+		// function start, across all router replicas, so a redriven backlog or a
+		// burst drains at a pace the function and its dependencies can take.
+		// Throttled invocations stay queued like MaxInFlight's. nil means no cap.
+		// Must be >= 1 when set.
+		// +optional
+		// +kubebuilder:validation:Minimum=1
+		MaxDeliveriesPerSecond *int32 `json:"maxDeliveriesPerSecond,omitempty"`
 	}
 
 	// DestinationRef routes an async invocation's result to exactly one target: a
@@ -1520,6 +1689,20 @@ type (
 		// Topic publishes the result envelope to a message-queue topic.
 		// +optional
 		Topic *TopicRef `json:"topic,omitempty"`
+
+		// Webhook POSTs the result envelope to an external HTTPS endpoint.
+		// Deliveries that keep failing are dead-lettered to the webhook queue
+		// (`fission fn dlq list --queue webhook`), recorded against this
+		// destination.
+		// +optional
+		Webhook *WebhookDestination `json:"webhook,omitempty"`
+
+		// CloudEvents wraps the result envelope in a CloudEvent of type
+		// io.fission.async.success or io.fission.async.failure, with an id
+		// derived from the invocation so a consumer can deduplicate. A function
+		// destination receives it in binary mode, a topic in structured mode.
+		// +optional
+		CloudEvents bool `json:"cloudEvents,omitempty"`
 	}
 
 	// TopicRef is a message-queue topic destination for an async invocation result.
@@ -1538,6 +1721,46 @@ type (
 		Topic string `json:"topic"`
 	}
 
+	// WebhookDestination is an external HTTPS endpoint for an async invocation
+	// result. Every delivery of one result carries the same
+	// X-Fission-Invocation-Id (the source invocation's id), so a receiver can
+	// drop the duplicates that retries produce.
+	WebhookDestination struct {
+		// URL is the https:// endpoint the result envelope is POSTed to.
+		// +kubebuilder:validation:MaxLength=2048
+		// +kubebuilder:validation:Pattern=`^https://`
+		URL string `json:"url"`
+
+		// Headers are set on every delivery. They cannot replace Content-Type,
+		// Content-Length, Host, or the X-Fission-* and Ce-* headers Fission
+		// sets itself.
+		// +optional
+		Headers map[string]string `json:"headers,omitempty"`
+
+		// SigningSecret, when set, signs every delivery with HMAC-SHA256 under
+		// a key held in a Secret in the function's namespace, sent as
+		// X-Fission-Signature: t=<unix seconds>,v1=<hex HMAC of "<t>.<body>">.
+		// The router rereads the Secret at most every 30 seconds, so a rotated
+		// key takes effect without touching the function.
+		// +optional
+		SigningSecret *WebhookSigningSecret `json:"signingSecret,omitempty"`
+
+		// Retry is the webhook's own delivery retry policy, independent of the
+		// function's; nil fields take the platform defaults.
+		// +optional
+		Retry RetryPolicy `json:"retry,omitempty"`
+	}
+
+	// WebhookSigningSecret names the Secret key holding a webhook's signing
+	// key.
+	WebhookSigningSecret struct {
+		// Name of the Secret, in the function's namespace.
+		Name string `json:"name"`
+
+		// Key of the signing key within the Secret.
+		Key string `json:"key"`
+	}
+
 	// RetryPolicy is the async delivery retry policy: the attempt budget and the
 	// exponential-backoff schedule between delivery attempts. All fields are
 	// optional; a nil field takes the platform default.
@@ -2029,6 +2252,264 @@ type (
 		// call this trigger cross-origin.
 		// +optional
 		CorsConfig *HTTPTriggerCorsConfig `json:"corsConfig,omitempty"`
+
+		// CloudEvents, when set, makes the router read requests to this trigger
+		// as CloudEvents 1.0. A binary-mode event is validated and passed on as
+		// it is; a structured-mode event is converted to binary mode, so the
+		// function always sees the context attributes as ce-* headers and the
+		// event data as the body. An invalid event is rejected with 400. nil
+		// leaves requests untouched.
+		// +optional
+		CloudEvents *HTTPTriggerCloudEvents `json:"cloudEvents,omitempty"`
+
+		// RateLimit, when set, limits the request rate through this trigger,
+		// replacing the target function's RateLimit default.
+		// +optional
+		RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+		// Authorization, when set, admits only callers whose verified token
+		// carries the listed scopes and claims; others get 403. Tokens are
+		// verified by the router's authentication, so a trigger with
+		// Authorization answers 401 to every request while authentication is
+		// disabled.
+		// +optional
+		Authorization *HTTPTriggerAuthorization `json:"authorization,omitempty"`
+
+		// APIKey, when set, admits only requests that present one of the API
+		// keys held in a Secret; others get 401. It is checked independently
+		// of the router's token authentication.
+		// +optional
+		APIKey *HTTPTriggerAPIKey `json:"apiKey,omitempty"`
+
+		// Cache, when set, lets the router answer GET and HEAD requests
+		// through this trigger from the function's earlier responses instead
+		// of invoking it again.
+		// +optional
+		Cache *HTTPTriggerCache `json:"cache,omitempty"`
+
+		// Mirror, when set, copies a sampled share of the requests through
+		// this trigger to another version of its function. It takes
+		// precedence over the Mirror of a FunctionAlias the trigger
+		// references. Only name-type function references can be mirrored.
+		// +optional
+		Mirror *TrafficMirror `json:"mirror,omitempty"`
+
+		// Transform, when set, checks and reshapes requests through this
+		// trigger before they reach the function, and edits the headers of
+		// its responses.
+		// +optional
+		Transform *HTTPTriggerTransform `json:"transform,omitempty"`
+	}
+
+	// HTTPTriggerTransform declares how the router checks and reshapes an
+	// HTTPTrigger's traffic. A request is checked first, against
+	// AllowedContentTypes, MaxBodyBytes and BodySchema in that order, and
+	// rejected before any function pod is involved: 415, 413 and 400
+	// respectively. An accepted request then has RequestHeaders applied,
+	// then Mappings. ResponseHeaders applies to the function's responses,
+	// not to errors the router answers itself.
+	HTTPTriggerTransform struct {
+		// RequestHeaders edits the headers of requests to the function.
+		// +optional
+		RequestHeaders *HeaderTransform `json:"requestHeaders,omitempty"`
+
+		// ResponseHeaders edits the headers of the function's responses.
+		// +optional
+		ResponseHeaders *HeaderTransform `json:"responseHeaders,omitempty"`
+
+		// Mappings copy path and query parameters of the request into its
+		// headers or JSON body. A mapping whose parameter is absent from the
+		// request is skipped.
+		// +optional
+		Mappings []ParameterMapping `json:"mappings,omitempty"`
+
+		// MaxBodyBytes rejects requests whose body is larger with 413.
+		// +optional
+		// +kubebuilder:validation:Minimum=0
+		MaxBodyBytes *int64 `json:"maxBodyBytes,omitempty"`
+
+		// AllowedContentTypes rejects requests that carry a body with any
+		// other media type with 415. An entry is a media type such as
+		// application/json, or a type wildcard such as text/*; parameters
+		// like charset are ignored.
+		// +optional
+		// +listType=set
+		AllowedContentTypes []string `json:"allowedContentTypes,omitempty"`
+
+		// BodySchema is a JSON Schema (draft 2020-12 or draft-07) the JSON
+		// request body must satisfy, else the request is rejected with 400.
+		// GET, HEAD and OPTIONS requests are not checked. The schema must be
+		// self-contained: references to other documents are not resolved.
+		// Stored as raw JSON so the CRD does not constrain the schema shape.
+		// +optional
+		// +kubebuilder:pruning:PreserveUnknownFields
+		BodySchema *apiextensionsv1.JSON `json:"bodySchema,omitempty"`
+	}
+
+	// HeaderTransform edits a message's headers: Rename first, then Remove,
+	// then Add. Header names are case-insensitive.
+	HeaderTransform struct {
+		// Add sets each header to its value, replacing any value the message
+		// carried.
+		// +optional
+		Add map[string]string `json:"add,omitempty"`
+
+		// Remove deletes the listed headers.
+		// +optional
+		// +listType=set
+		Remove []string `json:"remove,omitempty"`
+
+		// Rename moves each header's values to a new name, keyed by the old
+		// name. A header the message does not carry is left alone.
+		// +optional
+		Rename map[string]string `json:"rename,omitempty"`
+	}
+
+	// ParameterMapping copies one request parameter, a path parameter or a
+	// query parameter, into a request header or a field of the JSON body.
+	// +kubebuilder:validation:XValidation:rule="(has(self.path) && self.path != '') != (has(self.query) && self.query != '')",message="exactly one of path and query must be set"
+	// +kubebuilder:validation:XValidation:rule="(has(self.header) && self.header != '') != (has(self.bodyField) && self.bodyField != '')",message="exactly one of header and bodyField must be set"
+	ParameterMapping struct {
+		// Path names a parameter of the trigger's URL template, e.g. id for
+		// /items/{id}.
+		// +optional
+		Path string `json:"path,omitempty"`
+
+		// Query names a query parameter. The first value is used.
+		// +optional
+		Query string `json:"query,omitempty"`
+
+		// Header receives the value, replacing any value the request carried.
+		// +optional
+		Header string `json:"header,omitempty"`
+
+		// BodyField names the top-level field of the JSON request body that
+		// receives the value, as a string. The body must be empty or a JSON
+		// object; an empty body becomes one.
+		// +optional
+		BodyField string `json:"bodyField,omitempty"`
+	}
+
+	// TrafficMirror copies a sampled share of a route's requests to a shadow
+	// target: a FunctionVersion, or whatever version a FunctionAlias
+	// resolves to, of the same function. Copies are sent after the router
+	// has admitted the request and are fire-and-forget: the shadow's
+	// response is discarded, and its latency, status code and a comparison
+	// with the primary response are recorded as router metrics labelled by
+	// version. Requests answered from the response cache, async and
+	// streaming invocations, and requests with bodies over 1MiB are not
+	// mirrored. The shadow runs with real side effects; mirror only to
+	// versions that are safe to invoke twice.
+	// +kubebuilder:validation:XValidation:rule="(has(self.version) && self.version != '') != (has(self.alias) && self.alias != '')",message="exactly one of version and alias must be set"
+	TrafficMirror struct {
+		// Version names the FunctionVersion that receives the copies.
+		// +kubebuilder:validation:MaxLength=63
+		// +optional
+		Version string `json:"version,omitempty"`
+
+		// Alias names the FunctionAlias whose resolved version receives the
+		// copies. For a weighted alias that is its primary version.
+		// +kubebuilder:validation:MaxLength=63
+		// +optional
+		Alias string `json:"alias,omitempty"`
+
+		// Percent of requests to mirror, 1-100.
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=100
+		Percent int `json:"percent"`
+	}
+
+	// HTTPTriggerCache opts an HTTPTrigger into router-side response
+	// caching. Only 200 responses to GET requests are stored, keyed by host,
+	// path, query and the request's VaryHeaders; HEAD requests are answered
+	// from the same entries. The function's Cache-Control decides what is
+	// stored and for how long: no-store, no-cache and private responses, and
+	// responses setting cookies, are never stored, and s-maxage, else
+	// max-age, else Expires gives the freshness lifetime. A stale entry with
+	// an ETag or Last-Modified is revalidated with the function instead of
+	// fetched again, and a client's conditional request matching a fresh
+	// entry is answered 304. A response declaring no lifetime is stored only
+	// when DefaultTTL is set. Requests carrying an Authorization header or an
+	// API key are served and stored only for responses marked public or with
+	// s-maxage. The router bounds the memory all triggers' entries use
+	// together.
+	HTTPTriggerCache struct {
+		// DefaultTTL is the freshness lifetime of a response that declares
+		// none. Unset leaves such responses uncached.
+		// +optional
+		DefaultTTL *metav1.Duration `json:"defaultTTL,omitempty"`
+
+		// MaxTTL caps the freshness lifetime of every response, whatever the
+		// function declares. Defaults to 1h.
+		// +optional
+		MaxTTL *metav1.Duration `json:"maxTTL,omitempty"`
+
+		// VaryHeaders names the request headers whose values are part of
+		// the cache key, e.g. Accept-Language. A response whose Vary header
+		// names any other request header is not stored.
+		// +optional
+		// +listType=set
+		VaryHeaders []string `json:"varyHeaders,omitempty"`
+
+		// Shared keeps the entries in the statestore KV instead of each
+		// router replica's memory, so replicas serve one another's entries
+		// and a purge reaches all of them. Entries stay in memory when the
+		// router runs without the statestore.
+		// +optional
+		Shared bool `json:"shared,omitempty"`
+	}
+
+	// HTTPTriggerAPIKey names the Secret holding an HTTPTrigger's API keys
+	// and where requests carry the key. The Secret, in the trigger's
+	// namespace, maps each key's name to the hex-encoded SHA-256 digest of
+	// the key, so the key itself is never stored; `fission httptrigger apikey`
+	// manages it. The name of the matching key is recorded in the router's
+	// access log and metrics. The router rereads the Secret at most every 30
+	// seconds, which bounds how long a revoked key keeps working.
+	HTTPTriggerAPIKey struct {
+		SecretName string `json:"secretName"`
+
+		// Header carrying the key. Defaults to X-Api-Key unless QueryParam is
+		// set.
+		// +optional
+		Header string `json:"header,omitempty"`
+
+		// QueryParam carrying the key, read when the request has no Header.
+		// +optional
+		QueryParam string `json:"queryParam,omitempty"`
+	}
+
+	// HTTPTriggerAuthorization lists what a caller's token must grant to
+	// invoke through an HTTPTrigger. All entries must hold.
+	HTTPTriggerAuthorization struct {
+		// Scopes the token must all grant, read from its space-separated
+		// "scope" claim or its "scp" list.
+		// +optional
+		// +listType=set
+		Scopes []string `json:"scopes,omitempty"`
+
+		// Claims the token must carry.
+		// +optional
+		Claims []ClaimRequirement `json:"claims,omitempty"`
+	}
+
+	// ClaimRequirement is satisfied by a token whose claim Name equals one of
+	// Values or, for a list claim, contains one of them.
+	ClaimRequirement struct {
+		Name string `json:"name"`
+
+		// Values accepted for the claim. Empty only requires the claim to be
+		// present.
+		// +optional
+		Values []string `json:"values,omitempty"`
+	}
+
+	// HTTPTriggerCloudEvents configures how an HTTPTrigger accepts CloudEvents.
+	HTTPTriggerCloudEvents struct {
+		// Required rejects a request that carries no CloudEvent with 400. By
+		// default such requests reach the function unchanged.
+		// +optional
+		Required bool `json:"required,omitempty"`
 	}
 
 	// HTTPTriggerCorsConfig is the per-HTTPTrigger CORS allowlist.
@@ -2190,19 +2671,72 @@ type (
 	}
 
 	// KubernetesWatchTriggerSpec defines spec of KuberenetesWatchTrigger
+	// +kubebuilder:validation:XValidation:rule="has(self.apiVersion) == has(self.kind)",message="spec.apiVersion and spec.kind must be set together"
+	// +kubebuilder:validation:XValidation:rule="has(self.type) != has(self.kind)",message="set exactly one of spec.type or spec.apiVersion/spec.kind"
 	KubernetesWatchTriggerSpec struct {
 		// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 		// +kubebuilder:validation:MaxLength=63
 		Namespace string `json:"namespace"`
 
-		// Type of resource to watch (Pod, Service, etc.)
+		// Type of resource to watch (Pod, Service, etc.), the shorthand for the
+		// four built-in kinds the watcher has always supported. Use APIVersion
+		// and Kind for anything else.
 		// +kubebuilder:validation:XValidation:rule="self.upperAscii() in ['POD','SERVICE','REPLICATIONCONTROLLER','JOB']",message="spec.type must be one of POD, SERVICE, REPLICATIONCONTROLLER, JOB (case-insensitive)"
-		Type string `json:"type"`
+		// +optional
+		Type string `json:"type,omitempty"`
+
+		// APIVersion and Kind select any namespaced resource by group, version
+		// and kind instead of Type: "apps/v1" Deployment, "v1" ConfigMap,
+		// "fission.io/v1" Function or a third-party CRD such as
+		// "cert-manager.io/v1" Certificate. The kind must be served by the API
+		// server when the trigger is admitted, and the kubewatcher resolves it
+		// through discovery each time the watch starts. Cluster-scoped kinds are
+		// refused: a watch never reaches past the trigger's namespace. Admission
+		// checks that whoever creates or updates the trigger may list and watch
+		// the kind in that namespace, and the kubewatcher needs the same access
+		// (kubewatcher.watchRules in the chart).
+		// +kubebuilder:validation:MaxLength=253
+		// +optional
+		APIVersion string `json:"apiVersion,omitempty"`
+
+		// Kind is the kind to watch together with APIVersion, e.g. Deployment.
+		// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9]*$`
+		// +kubebuilder:validation:MaxLength=63
+		// +optional
+		Kind string `json:"kind,omitempty"`
 
-		// Resource labels
+		// Resource labels: only objects carrying all of them are watched.
 		// +optional
 		LabelSelector map[string]string `json:"labelselector"`
 
+		// FieldSelector narrows the watch server-side, e.g. "status.phase=Failed"
+		// for pods. The API server decides which fields a kind supports: only
+		// metadata.name and metadata.namespace for most custom resources, unless
+		// the CRD declares selectableFields.
+		// +kubebuilder:validation:MaxLength=1024
+		// +optional
+		FieldSelector string `json:"fieldSelector,omitempty"`
+
+		// EventTypes lists the watch event types delivered to the function;
+		// empty delivers ADDED, MODIFIED and DELETED.
+		// +kubebuilder:validation:MaxItems=3
+		// +kubebuilder:validation:items:Enum=ADDED;MODIFIED;DELETED
+		// +listType=set
+		// +optional
+		EventTypes []string `json:"eventTypes,omitempty"`
+
+		// ChangedPaths, when set, delivers a MODIFIED event only if the value
+		// at one of these JSONPaths differs from the last version of the object
+		// the watch saw, e.g. ".spec.replicas" or
+		// `.status.conditions[?(@.type=="Ready")].status`. Status churn and
+		// resyncs that leave them alone are suppressed. ADDED and DELETED
+		// events are not compared.
+		// +kubebuilder:validation:MaxItems=16
+		// +kubebuilder:validation:items:MaxLength=256
+		// +listType=atomic
+		// +optional
+		ChangedPaths []string `json:"changedPaths,omitempty"`
+
 		// The reference to a function for kubewatcher to invoke with
 		// when receiving events.
 		FunctionReference FunctionReference `json:"functionref"`
@@ -2270,6 +2804,31 @@ type (
 		// +optional
 		MqtKind string `json:"mqtkind,omitempty"`
 
+		// CloudEvents delivers each message to the function as a binary-mode
+		// CloudEvent, wrapping a plain message with an id derived from its
+		// position in the topic so redeliveries keep the same id, and publishes
+		// to ResponseTopic and ErrorTopic in structured mode. A message that is
+		// already a CloudEvent keeps its attributes. Not supported with mqtkind
+		// keda.
+		// +optional
+		CloudEvents bool `json:"cloudEvents,omitempty"`
+
+		// MessageGroupHeader names a message header whose value is the message
+		// group (FIFO key) of each message, e.g. a customer id. When set, each
+		// message is handed to the function as an async invocation (RFC-0024)
+		// in that group, sent with X-Fission-Message-Group: the trigger moves on
+		// once the router has durably accepted it, and the async dispatcher
+		// delivers each group's messages one at a time in topic order while
+		// different groups run in parallel. A message without the header is
+		// delivered async but unordered. The function's async invocation
+		// config, not MaxRetries, then governs delivery retries, and its
+		// onSuccess destination replaces ResponseTopic, which must be unset.
+		// Kafka only, where record headers are the message headers; not
+		// supported with mqtkind keda.
+		// +kubebuilder:validation:MaxLength=256
+		// +optional
+		MessageGroupHeader string `json:"messageGroupHeader,omitempty"`
+
 		// (Optional) Podspec allows modification of deployed runtime pod with Kubernetes PodSpec
 		// The merging logic is briefly described below and detailed MergePodSpec function
 		// - Volumes mounts and env variables for function and fetcher container are appended
@@ -2305,7 +2864,60 @@ type (
 		// +kubebuilder:default:="/"
 		// +optional
 		Subpath string `json:"subpath,omitempty"`
+
+		// TimeZone is the IANA time zone name (e.g. "Europe/Berlin") the Cron
+		// schedule is evaluated in. Empty means the timer process's local zone
+		// (UTC in the default deployment). A CRON_TZ= prefix on Cron itself
+		// still takes precedence, as with the robfig/cron parser it feeds.
+		// The name is checked against the tz database by the timer, which
+		// surfaces an unknown zone as Scheduled=False / InvalidTimeZone.
+		// +kubebuilder:validation:MaxLength=64
+		// +optional
+		TimeZone string `json:"timeZone,omitempty"`
+
+		// StartingDeadlineSeconds is the catch-up window for a firing missed
+		// while the timer was not running (a restart, a leader handover). On
+		// (re)registration the most recent missed slot is fired once if it is
+		// no older than this many seconds; older slots are skipped. nil (the
+		// default) keeps the historic behavior: missed firings are lost.
+		// +kubebuilder:validation:Minimum=0
+		// +optional
+		StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+		// ConcurrencyPolicy decides what happens when a slot comes due while the
+		// previous invocation of this trigger is still running, mirroring the
+		// batch/v1 CronJob field: Allow runs them side by side, Forbid skips the
+		// new slot, Replace cancels the running invocation and starts the new one.
+		// Under the timer's durable delivery a running invocation cannot be cut
+		// short, so Replace cancels it only if it has not started yet, and
+		// otherwise runs the new slot after it.
+		// +kubebuilder:default:=Allow
+		// +optional
+		ConcurrencyPolicy TimeTriggerConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+		// Body is the JSON request body sent on every firing, a Go text/template
+		// executed per firing with .TriggerName, .Namespace, .ScheduledTime (the
+		// cron slot) and .FireTime (when it actually fired; later on a catch-up),
+		// e.g. {"window":"hourly","slot":"{{ .ScheduledTime }}"}. Times print as
+		// RFC 3339 and keep time.Time's methods ({{ .ScheduledTime.Unix }}). It
+		// is sent with Content-Type application/json unless Headers sets one.
+		// Empty (the default) sends no body.
+		// +kubebuilder:validation:MaxLength=65536
+		// +optional
+		Body string `json:"body,omitempty"`
+
+		// Headers are extra request headers sent on every firing. Values are
+		// templates executed like Body. X-Fission-Timer-Name is always set by the
+		// timer and overrides an entry of the same name.
+		// +optional
+		Headers map[string]string `json:"headers,omitempty"`
 	}
+
+	// TimeTriggerConcurrencyPolicy selects how overlapping TimeTrigger
+	// invocations are handled.
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	TimeTriggerConcurrencyPolicy string
+
 	// FailureType refers to the type of failure
 	FailureType string
 
@@ -2418,6 +3030,14 @@ type (
 		// +optional
 		ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+		// DeliveredEvents counts the watch events sent to the function, and
+		// SuppressedEvents those dropped by EventTypes or ChangedPaths. Both
+		// are written by the kubewatcher every few seconds while events flow.
+		// +optional
+		DeliveredEvents int64 `json:"deliveredEvents,omitempty"`
+		// +optional
+		SuppressedEvents int64 `json:"suppressedEvents,omitempty"`
+
 		// +optional
 		// +patchMergeKey=type
 		// +patchStrategy=merge
@@ -2431,6 +3051,20 @@ type (
 		// +optional
 		ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+		// LastScheduleTime is the schedule slot the trigger last fired for (the
+		// slot time, not the wall-clock moment the timer got to it). It is also
+		// the starting point for StartingDeadlineSeconds catch-up after a timer
+		// restart.
+		// +optional
+		LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+		// NextScheduleTime is the next slot the timer has scheduled. A
+		// NextScheduleTime in the past means the timer is not running the
+		// trigger (or fell behind), so a missed schedule is visible without
+		// reading timer logs.
+		// +optional
+		NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
 		// +optional
 		// +patchMergeKey=type
 		// +patchStrategy=merge
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -19,10 +20,15 @@ import (
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"golang.org/x/net/http/httpguts"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation"
 
+	"github.com/fission/fission/pkg/kubewatcher/eventfilter"
 	"github.com/fission/fission/pkg/mqtrigger/validator"
+	"github.com/fission/fission/pkg/timer/payload"
 )
 
 const (
@@ -437,6 +443,15 @@ func (spec FunctionSpec) validateForAdmission() error {
 	if spec.ProvisionedConcurrency != nil {
 		errs = errors.Join(errs, spec.ProvisionedConcurrency.Validate())
 	}
+	if spec.RateLimit != nil {
+		errs = errors.Join(errs, spec.RateLimit.Validate("FunctionSpec.RateLimit"))
+	}
+	if spec.CircuitBreaker != nil {
+		errs = errors.Join(errs, spec.CircuitBreaker.Validate("FunctionSpec.CircuitBreaker"))
+	}
+	if spec.OutlierEjection != nil {
+		errs = errors.Join(errs, spec.OutlierEjection.Validate("FunctionSpec.OutlierEjection"))
+	}
 	errs = errors.Join(errs, spec.validateEnvForAdmission())
 	return errs
 }
@@ -496,6 +511,19 @@ const (
 // (0, MaxAsyncMaxAge]. These bounds keep the dispatcher's retry loop well-defined
 // (a zero attempt budget or max age would mean "accepted but never deliverable")
 // and keep one tenant from setting absurd values on the shared queue.
+// validateAsync checks an async delivery retry policy: an attempt budget in
+// [1, MaxAsyncAttempts] and the shared backoff bounds.
+func (r *RetryPolicy) validateAsync(field string) error {
+	var errs error
+	if r.MaxAttempts != nil && *r.MaxAttempts < 1 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".MaxAttempts", *r.MaxAttempts, "must be >= 1"))
+	}
+	if r.MaxAttempts != nil && *r.MaxAttempts > MaxAsyncAttempts {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".MaxAttempts", *r.MaxAttempts, fmt.Sprintf("must be <= %d (the platform async attempt budget)", MaxAsyncAttempts)))
+	}
+	return errors.Join(errs, r.validateBackoffBounds(field))
+}
+
 // validateBackoffBounds checks the ordering rules every RetryPolicy consumer
 // shares (base >= 0, cap >= 0, cap >= base). Attempt budgets stay at the
 // callers — async delivery clamps to MaxAsyncAttempts, workflows to
@@ -515,21 +543,19 @@ func (r *RetryPolicy) validateBackoffBounds(field string) error {
 }
 
 func (ic *InvocationConfig) Validate() error {
-	var errs error
-	r := ic.Retry
-	if r.MaxAttempts != nil && *r.MaxAttempts < 1 {
-		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Invocation.Retry.MaxAttempts", *r.MaxAttempts, "must be >= 1"))
-	}
-	if r.MaxAttempts != nil && *r.MaxAttempts > MaxAsyncAttempts {
-		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Invocation.Retry.MaxAttempts", *r.MaxAttempts, fmt.Sprintf("must be <= %d (the platform async attempt budget)", MaxAsyncAttempts)))
-	}
-	errs = errors.Join(errs, r.validateBackoffBounds("FunctionSpec.Invocation.Retry"))
+	errs := ic.Retry.validateAsync("FunctionSpec.Invocation.Retry")
 	if ic.MaxAge != nil && ic.MaxAge.Duration <= 0 {
 		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Invocation.MaxAge", ic.MaxAge.Duration, "must be > 0"))
 	}
 	if ic.MaxAge != nil && ic.MaxAge.Duration > MaxAsyncMaxAge {
 		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Invocation.MaxAge", ic.MaxAge.Duration, fmt.Sprintf("must be <= %s", MaxAsyncMaxAge)))
 	}
+	if ic.MaxInFlight != nil && *ic.MaxInFlight < 1 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Invocation.MaxInFlight", *ic.MaxInFlight, "must be >= 1"))
+	}
+	if ic.MaxDeliveriesPerSecond != nil && *ic.MaxDeliveriesPerSecond < 1 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Invocation.MaxDeliveriesPerSecond", *ic.MaxDeliveriesPerSecond, "must be >= 1"))
+	}
 	if ic.OnSuccess != nil {
 		errs = errors.Join(errs, ic.OnSuccess.Validate("FunctionSpec.Invocation.OnSuccess"))
 	}
@@ -539,19 +565,28 @@ func (ic *InvocationConfig) Validate() error {
 	return errs
 }
 
-// Validate checks a destination reference: exactly one of Function/Topic, a
-// function destination that references a single named function (weights make no
-// sense for a destination), and a topic destination on a supported provider —
+// Validate checks a destination reference: exactly one of Function/Topic/Webhook,
+// a function destination that references a single named function (weights make no
+// sense for a destination), a topic destination on a supported provider —
 // the built-in statestore (RFC-0027); broker types are rejected honestly until
-// the egress phase lands rather than accepted and dropped.
+// the egress phase lands rather than accepted and dropped — and a well-formed
+// webhook.
 func (d *DestinationRef) Validate(field string) error {
+	set := 0
+	for _, kind := range []bool{d.Function != nil, d.Topic != nil, d.Webhook != nil} {
+		if kind {
+			set++
+		}
+	}
 	switch {
-	case d.Function == nil && d.Topic == nil:
-		return MakeValidationErr(ErrorInvalidObject, field, "", "exactly one of function or topic must be set")
-	case d.Function != nil && d.Topic != nil:
-		return MakeValidationErr(ErrorInvalidObject, field, "", "only one of function or topic may be set")
+	case set == 0:
+		return MakeValidationErr(ErrorInvalidObject, field, "", "exactly one of function, topic or webhook must be set")
+	case set > 1:
+		return MakeValidationErr(ErrorInvalidObject, field, "", "only one of function, topic or webhook may be set")
 	case d.Topic != nil:
 		return d.Topic.Validate(field + ".topic")
+	case d.Webhook != nil:
+		return d.Webhook.Validate(field + ".webhook")
 	}
 	if d.Function.Type != FunctionReferenceTypeFunctionName {
 		return MakeValidationErr(ErrorInvalidValue, field+".function.type", d.Function.Type, fmt.Sprintf("must be %q", FunctionReferenceTypeFunctionName))
@@ -562,6 +597,44 @@ func (d *DestinationRef) Validate(field string) error {
 	return nil
 }
 
+// webhookReservedHeaders are the headers a webhook's Headers cannot set: the
+// body framing, and the identity and signature headers the router writes.
+var webhookReservedHeaders = map[string]struct{}{
+	"Content-Type":      {},
+	"Content-Length":    {},
+	"Host":              {},
+	"Transfer-Encoding": {},
+	"Connection":        {},
+}
+
+// Validate checks a webhook destination: an absolute https URL with a host,
+// settable header names, a complete Secret reference, and the async retry
+// bounds.
+func (w *WebhookDestination) Validate(field string) error {
+	var errs error
+	u, err := url.Parse(w.URL)
+	switch {
+	case len(w.URL) > 2048:
+		errs = MakeValidationErr(ErrorInvalidValue, field+".url", w.URL, "must be at most 2048 characters")
+	case err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil:
+		errs = MakeValidationErr(ErrorInvalidValue, field+".url", w.URL, "must be an absolute https:// URL without credentials")
+	}
+	for name, value := range w.Headers {
+		canon := http.CanonicalHeaderKey(name)
+		_, reserved := webhookReservedHeaders[canon]
+		switch {
+		case !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value):
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".headers", name, "must be a valid HTTP header"))
+		case reserved || strings.HasPrefix(canon, "X-Fission-") || strings.HasPrefix(canon, "Ce-"):
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".headers", name, "is set by Fission and cannot be overridden"))
+		}
+	}
+	if s := w.SigningSecret; s != nil && (s.Name == "" || s.Key == "") {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".signingSecret", s.Name+"/"+s.Key, "name and key are required"))
+	}
+	return errors.Join(errs, w.Retry.validateAsync(field+".retry"))
+}
+
 // topicNameRegexp bounds statestore topic names to a stream-safe charset.
 var topicNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
 
@@ -724,6 +797,228 @@ func (sc *StateConfig) Validate() error {
 	return errs
 }
 
+// Validate checks a rate limit's bounds and key declaration; field prefixes
+// the reported field names.
+func (rl *RateLimitConfig) Validate(field string) error {
+	var errs error
+	if rl.Requests < 1 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Requests", rl.Requests, "must be >= 1"))
+	}
+	if rl.Period != nil && rl.Period.Duration <= 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Period", rl.Period.Duration.String(), "must be > 0"))
+	}
+	if rl.Burst < 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Burst", rl.Burst, "must be >= 0"))
+	}
+	if rl.Key != nil {
+		switch rl.Key.Source {
+		case RateLimitKeyClientIP:
+		case RateLimitKeyHeader, RateLimitKeyJWTClaim:
+			if rl.Key.Name == "" {
+				errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Key.Name", rl.Key.Name, "a header or claim name is required"))
+			}
+		default:
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Key.Source", rl.Key.Source, "must be one of: clientip, header, jwtclaim"))
+		}
+	}
+	return errs
+}
+
+// Validate checks that at least one threshold is set and that every value
+// is in range.
+func (cb *CircuitBreakerConfig) Validate(field string) error {
+	var errs error
+	if cb.ConsecutiveFailures == 0 && cb.FailureRatePercent == 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field, "", "at least one of ConsecutiveFailures and FailureRatePercent must be set"))
+	}
+	if cb.ConsecutiveFailures < 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".ConsecutiveFailures", cb.ConsecutiveFailures, "must be >= 1"))
+	}
+	if cb.FailureRatePercent < 0 || cb.FailureRatePercent > 100 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".FailureRatePercent", cb.FailureRatePercent, "must be between 1 and 100"))
+	}
+	if cb.MinimumRequests < 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".MinimumRequests", cb.MinimumRequests, "must be >= 1"))
+	}
+	if cb.Window != nil && cb.Window.Duration <= 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Window", cb.Window.Duration.String(), "must be > 0"))
+	}
+	if cb.OpenDuration != nil && cb.OpenDuration.Duration <= 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".OpenDuration", cb.OpenDuration.Duration.String(), "must be > 0"))
+	}
+	if cb.HalfOpenRequests < 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".HalfOpenRequests", cb.HalfOpenRequests, "must be >= 1"))
+	}
+	return errs
+}
+
+// Validate checks that every value is in range.
+func (oe *OutlierEjectionConfig) Validate(field string) error {
+	var errs error
+	if oe.ConsecutiveFailures < 1 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".ConsecutiveFailures", oe.ConsecutiveFailures, "must be >= 1"))
+	}
+	if oe.EjectionDuration != nil && oe.EjectionDuration.Duration <= 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".EjectionDuration", oe.EjectionDuration.Duration.String(), "must be > 0"))
+	}
+	if oe.MaxEjectionPercent < 0 || oe.MaxEjectionPercent > 100 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".MaxEjectionPercent", oe.MaxEjectionPercent, "must be between 1 and 100"))
+	}
+	return errs
+}
+
+// Validate checks that every scope and claim requirement is named.
+func (az *HTTPTriggerAuthorization) Validate() error {
+	var errs error
+	for i, scope := range az.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t") {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, fmt.Sprintf("HTTPTriggerSpec.Authorization.Scopes[%d]", i), scope, "must be a single non-empty scope"))
+		}
+	}
+	for i, c := range az.Claims {
+		if c.Name == "" {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, fmt.Sprintf("HTTPTriggerSpec.Authorization.Claims[%d].Name", i), c.Name, "a claim name is required"))
+		}
+	}
+	return errs
+}
+
+// Validate checks the Secret name and the header name.
+func (k *HTTPTriggerAPIKey) Validate() error {
+	var errs error
+	for _, msg := range validation.IsDNS1123Subdomain(k.SecretName) {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.APIKey.SecretName", k.SecretName, msg))
+	}
+	if k.Header != "" && !httpguts.ValidHeaderFieldName(k.Header) {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.APIKey.Header", k.Header, "not a valid HTTP header name"))
+	}
+	return errs
+}
+
+// Validate checks that the cache's lifetimes are positive and ordered and its
+// vary headers are header names.
+func (c *HTTPTriggerCache) Validate() error {
+	var errs error
+	if c.DefaultTTL != nil && c.DefaultTTL.Duration <= 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Cache.DefaultTTL", c.DefaultTTL.Duration.String(), "must be > 0"))
+	}
+	if c.MaxTTL != nil && c.MaxTTL.Duration <= 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Cache.MaxTTL", c.MaxTTL.Duration.String(), "must be > 0"))
+	}
+	if c.DefaultTTL != nil && c.MaxTTL != nil && c.DefaultTTL.Duration > c.MaxTTL.Duration {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Cache.DefaultTTL", c.DefaultTTL.Duration.String(), "must not exceed MaxTTL"))
+	}
+	for i, h := range c.VaryHeaders {
+		if !httpguts.ValidHeaderFieldName(h) {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, fmt.Sprintf("HTTPTriggerSpec.Cache.VaryHeaders[%d]", i), h, "not a valid HTTP header name"))
+		}
+	}
+	return errs
+}
+
+// transformReservedHeaders are the headers that frame a message or route it
+// (Host is req.Host in Go, not a header); a transform cannot edit them.
+var transformReservedHeaders = []string{"Connection", "Content-Length", "Host", "Transfer-Encoding"}
+
+// Validate checks the transform's header names, mappings, size limit, media
+// types and that BodySchema is a JSON object. The router compiles the schema
+// and reports one that does not compile on the trigger's RouteAdmitted
+// condition.
+func (t *HTTPTriggerTransform) Validate() error {
+	var errs error
+	if t.RequestHeaders != nil {
+		errs = errors.Join(errs, t.RequestHeaders.Validate("HTTPTriggerSpec.Transform.RequestHeaders"))
+	}
+	if t.ResponseHeaders != nil {
+		errs = errors.Join(errs, t.ResponseHeaders.Validate("HTTPTriggerSpec.Transform.ResponseHeaders"))
+	}
+	for i, m := range t.Mappings {
+		errs = errors.Join(errs, m.Validate(fmt.Sprintf("HTTPTriggerSpec.Transform.Mappings[%d]", i)))
+	}
+	if t.MaxBodyBytes != nil && *t.MaxBodyBytes < 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Transform.MaxBodyBytes", *t.MaxBodyBytes, "must be >= 0"))
+	}
+	for i, ct := range t.AllowedContentTypes {
+		mediaType, params, err := mime.ParseMediaType(ct)
+		if err != nil || len(params) > 0 || mediaType != strings.ToLower(ct) || strings.Count(mediaType, "/") != 1 || strings.HasPrefix(mediaType, "*") {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, fmt.Sprintf("HTTPTriggerSpec.Transform.AllowedContentTypes[%d]", i), ct,
+				"must be a lowercase media type like application/json or a type wildcard like text/*, without parameters"))
+		}
+	}
+	if t.BodySchema != nil && len(t.BodySchema.Raw) > 0 {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(t.BodySchema.Raw, &obj); err != nil {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Transform.BodySchema", string(t.BodySchema.Raw), "must be a JSON object"))
+		}
+	}
+	return errs
+}
+
+// Validate checks the header names h edits. field prefixes the error fields.
+func (h *HeaderTransform) Validate(field string) error {
+	var errs error
+	for name, value := range h.Add {
+		errs = errors.Join(errs, validateTransformHeader(field+".Add", name))
+		if !httpguts.ValidHeaderFieldValue(value) {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Add["+name+"]", value, "not a valid HTTP header value"))
+		}
+	}
+	for i, name := range h.Remove {
+		errs = errors.Join(errs, validateTransformHeader(fmt.Sprintf("%s.Remove[%d]", field, i), name))
+	}
+	for from, to := range h.Rename {
+		errs = errors.Join(errs, validateTransformHeader(field+".Rename", from), validateTransformHeader(field+".Rename["+from+"]", to))
+	}
+	return errs
+}
+
+// validateTransformHeader checks a header name a transform edits.
+func validateTransformHeader(field, name string) error {
+	switch {
+	case !httpguts.ValidHeaderFieldName(name):
+		return MakeValidationErr(ErrorInvalidValue, field, name, "not a valid HTTP header name")
+	case slices.ContainsFunc(transformReservedHeaders, func(r string) bool { return strings.EqualFold(r, name) }):
+		return MakeValidationErr(ErrorInvalidValue, field, name, "the router manages this header")
+	}
+	return nil
+}
+
+// Validate checks that m copies exactly one parameter to exactly one
+// destination. field prefixes the error fields.
+func (m ParameterMapping) Validate(field string) error {
+	var errs error
+	if (m.Path == "") == (m.Query == "") {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidObject, field, "", "exactly one of path or query must be set"))
+	}
+	switch {
+	case (m.Header == "") == (m.BodyField == ""):
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidObject, field, "", "exactly one of header or bodyField must be set"))
+	case m.Header != "":
+		errs = errors.Join(errs, validateTransformHeader(field+".Header", m.Header))
+	}
+	return errs
+}
+
+// Validate checks that the mirror names exactly one shadow target and
+// samples a share of 1-100 percent. field prefixes the error fields.
+func (m *TrafficMirror) Validate(field string) error {
+	var errs error
+	switch {
+	case m.Version == "" && m.Alias == "":
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidObject, field, "", "exactly one of version or alias must be set"))
+	case m.Version != "" && m.Alias != "":
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidObject, field, "", "only one of version or alias may be set"))
+	case m.Version != "":
+		errs = errors.Join(errs, ValidateKubeName(field+".Version", m.Version))
+	default:
+		errs = errors.Join(errs, ValidateKubeName(field+".Alias", m.Alias))
+	}
+	if m.Percent < 1 || m.Percent > 100 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, field+".Percent", m.Percent, "must be 1-100"))
+	}
+	return errs
+}
+
 // Validate checks the provisioned concurrency config.
 func (pc *ProvisionedConcurrencyConfig) Validate() error {
 	var errs error
@@ -974,6 +1269,27 @@ func (spec HTTPTriggerSpec) Validate() error {
 	if spec.CorsConfig != nil {
 		errs = errors.Join(errs, spec.CorsConfig.Validate())
 	}
+	if spec.RateLimit != nil {
+		errs = errors.Join(errs, spec.RateLimit.Validate("HTTPTriggerSpec.RateLimit"))
+	}
+	if spec.Authorization != nil {
+		errs = errors.Join(errs, spec.Authorization.Validate())
+	}
+	if spec.APIKey != nil {
+		errs = errors.Join(errs, spec.APIKey.Validate())
+	}
+	if spec.Cache != nil {
+		errs = errors.Join(errs, spec.Cache.Validate())
+	}
+	if spec.Transform != nil {
+		errs = errors.Join(errs, spec.Transform.Validate())
+	}
+	if spec.Mirror != nil {
+		errs = errors.Join(errs, spec.Mirror.Validate("HTTPTriggerSpec.Mirror"))
+		if spec.FunctionReference.Type != FunctionReferenceTypeFunctionName {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidObject, "HTTPTriggerSpec.Mirror", "", "requires a function reference of type name"))
+		}
+	}
 
 	// Path validation. HTTPTrigger has no admission webhook on current main
 	// (the API server's CEL evaluation is the admission gate); these checks
@@ -1205,10 +1521,18 @@ func (config RouteConfig) Validate() error {
 func (spec KubernetesWatchTriggerSpec) Validate() error {
 	var errs error
 
-	switch strings.ToUpper(spec.Type) {
-	case "POD", "SERVICE", "REPLICATIONCONTROLLER", "JOB":
+	switch {
+	case spec.Kind != "" || spec.APIVersion != "":
+		if spec.Type != "" {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "KubernetesWatchTriggerSpec.Type", spec.Type, "set either type or apiVersion/kind, not both"))
+		}
+		if spec.Kind == "" || spec.APIVersion == "" {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "KubernetesWatchTriggerSpec.Kind", spec.Kind, "apiVersion and kind must be set together"))
+		}
 	default:
-		errs = errors.Join(errs, MakeValidationErr(ErrorUnsupportedType, "KubernetesWatchTriggerSpec.Type", spec.Type, "not a valid supported type"))
+		if _, ok := legacyWatchTypes[strings.ToUpper(spec.Type)]; !ok {
+			errs = errors.Join(errs, MakeValidationErr(ErrorUnsupportedType, "KubernetesWatchTriggerSpec.Type", spec.Type, "not a valid supported type"))
+		}
 	}
 
 	errs = errors.Join(errs,
@@ -1220,10 +1544,58 @@ func (spec KubernetesWatchTriggerSpec) Validate() error {
 }
 
 // validateForAdmission returns the KubernetesWatchTriggerSpec checks CEL cannot
-// express: label-selector qualified key/value validation. (Type, namespace, and
-// function-reference are enforced by CEL on the CRD.)
+// express: label-selector qualified key/value validation, the apiVersion
+// group/version syntax, and field-selector and changed-path parsing. (Type,
+// event types, namespace, and function-reference are enforced by CEL on the
+// CRD.)
 func (spec KubernetesWatchTriggerSpec) validateForAdmission() error {
-	return ValidateKubeLabel("KubernetesWatchTriggerSpec.LabelSelector", spec.LabelSelector)
+	errs := ValidateKubeLabel("KubernetesWatchTriggerSpec.LabelSelector", spec.LabelSelector)
+	if spec.APIVersion != "" {
+		if _, err := schema.ParseGroupVersion(spec.APIVersion); err != nil {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "KubernetesWatchTriggerSpec.APIVersion", spec.APIVersion, err.Error()))
+		}
+	}
+	if spec.FieldSelector != "" {
+		if _, err := fields.ParseSelector(spec.FieldSelector); err != nil {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "KubernetesWatchTriggerSpec.FieldSelector", spec.FieldSelector, err.Error()))
+		}
+	}
+	for _, t := range spec.EventTypes {
+		if _, err := eventfilter.ParseEventType(t); err != nil {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "KubernetesWatchTriggerSpec.EventTypes", t, err.Error()))
+		}
+	}
+	for _, p := range spec.ChangedPaths {
+		if _, err := eventfilter.ParsePath(p); err != nil {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "KubernetesWatchTriggerSpec.ChangedPaths", p, err.Error()))
+		}
+	}
+	return errs
+}
+
+// legacyWatchTypes maps the Type shorthands to the kinds they stand for.
+var legacyWatchTypes = map[string]schema.GroupVersionKind{
+	"POD":                   {Version: "v1", Kind: "Pod"},
+	"SERVICE":               {Version: "v1", Kind: "Service"},
+	"REPLICATIONCONTROLLER": {Version: "v1", Kind: "ReplicationController"},
+	"JOB":                   {Group: "batch", Version: "v1", Kind: "Job"},
+}
+
+// GroupVersionKind returns the kind the trigger watches: APIVersion/Kind when
+// set, else the kind the Type shorthand stands for. The kubewatcher and the
+// admission RBAC check both resolve the watched kind through it.
+func (spec KubernetesWatchTriggerSpec) GroupVersionKind() (schema.GroupVersionKind, error) {
+	if spec.Kind != "" {
+		gv, err := schema.ParseGroupVersion(spec.APIVersion)
+		if err != nil {
+			return schema.GroupVersionKind{}, err
+		}
+		return gv.WithKind(spec.Kind), nil
+	}
+	if gvk, ok := legacyWatchTypes[strings.ToUpper(spec.Type)]; ok {
+		return gvk, nil
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("unknown watch type %q", spec.Type)
 }
 
 func (spec MessageQueueTriggerSpec) Validate() error {
@@ -1260,6 +1632,26 @@ func (spec MessageQueueTriggerSpec) validateForAdmission() error {
 		}
 	}
 
+	// KEDA triggers are delivered by the connector images, which know nothing
+	// of CloudEvents; accepting the flag would silently do nothing.
+	if spec.CloudEvents && spec.MqtKind == "keda" {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.CloudEvents", spec.CloudEvents, "not supported with mqtkind keda"))
+	}
+
+	if spec.MessageGroupHeader != "" {
+		switch {
+		case spec.MessageQueueType != MessageQueueTypeKafka || spec.MqtKind == "keda":
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.MessageGroupHeader", spec.MessageGroupHeader, "supported only with messageQueueType kafka and mqtkind fission"))
+		case !httpguts.ValidHeaderFieldName(spec.MessageGroupHeader):
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.MessageGroupHeader", spec.MessageGroupHeader, "not a valid header name"))
+		}
+		// Grouped messages are invoked async; the router's 202 is not the
+		// function's response.
+		if spec.ResponseTopic != "" {
+			errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.ResponseTopic", spec.ResponseTopic, "not supported with messageGroupHeader; use the function's onSuccess destination"))
+		}
+	}
+
 	return errs
 }
 
@@ -1271,11 +1663,68 @@ func (spec TimeTriggerSpec) Validate() error {
 		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.Cron", spec.Cron, "not a valid cron spec"))
 	}
 
+	if _, err := spec.Location(); err != nil {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.TimeZone", spec.TimeZone, "not a known IANA time zone"))
+	}
+	if spec.StartingDeadlineSeconds != nil && *spec.StartingDeadlineSeconds < 0 {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.StartingDeadlineSeconds", *spec.StartingDeadlineSeconds, "must be >= 0"))
+	}
+	switch spec.ConcurrencyPolicy {
+	case "", TimeTriggerConcurrencyAllow, TimeTriggerConcurrencyForbid, TimeTriggerConcurrencyReplace: // no op
+	default:
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.ConcurrencyPolicy", spec.ConcurrencyPolicy, "must be one of: Allow, Forbid, Replace"))
+	}
+	if err := payload.Validate(spec.Body, nil); err != nil {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.Body", spec.Body, err.Error()))
+	}
+	if err := payload.Validate("", spec.Headers); err != nil {
+		errs = errors.Join(errs, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.Headers", spec.Headers, err.Error()))
+	}
+
 	errs = errors.Join(errs, spec.FunctionReference.Validate())
 
 	return errs
 }
 
+// Location resolves TimeZone to the location the cron schedule is evaluated
+// in: time.Local when TimeZone is empty, matching the timer's historic
+// behavior. The CLI and the timer share it so a zone one accepts the other
+// cannot reject.
+func (spec TimeTriggerSpec) Location() (*time.Location, error) {
+	if spec.TimeZone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(spec.TimeZone)
+}
+
+// Schedule parses Cron into the schedule the timer runs, evaluated in
+// Location. A CRON_TZ= (or TZ=) prefix on Cron wins over TimeZone, as the
+// robfig/cron parser already honors it.
+func (spec TimeTriggerSpec) Schedule() (cron.Schedule, error) {
+	loc, err := spec.Location()
+	if err != nil {
+		return nil, err
+	}
+	cronSpecParser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	sched, err := cronSpecParser.Parse(spec.Cron)
+	if err != nil {
+		return nil, err
+	}
+	if ss, ok := sched.(*cron.SpecSchedule); ok && !strings.HasPrefix(spec.Cron, "CRON_TZ=") && !strings.HasPrefix(spec.Cron, "TZ=") {
+		ss.Location = loc
+	}
+	return sched, nil
+}
+
+// GetConcurrencyPolicy returns ConcurrencyPolicy, treating the empty value an
+// object written before the field existed carries as Allow.
+func (spec TimeTriggerSpec) GetConcurrencyPolicy() TimeTriggerConcurrencyPolicy {
+	if spec.ConcurrencyPolicy == "" {
+		return TimeTriggerConcurrencyAllow
+	}
+	return spec.ConcurrencyPolicy
+}
+
 func validateMetadata(field string, m metav1.ObjectMeta) error {
 	return ValidateKubeReference(field, m.Name, m.Namespace)
 }
@@ -1585,6 +2034,10 @@ func (spec FunctionAliasSpec) Validate() error {
 		}
 	}
 
+	if spec.Mirror != nil {
+		errs = errors.Join(errs, spec.Mirror.Validate("FunctionAliasSpec.Mirror"))
+	}
+
 	return errs
 }
 
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHTTPTriggerCacheValidate(t *testing.T) {
+	t.Parallel()
+	d := func(v time.Duration) *metav1.Duration { return &metav1.Duration{Duration: v} }
+	tests := []struct {
+		name    string
+		cfg     HTTPTriggerCache
+		wantErr bool
+	}{
+		{"empty ok", HTTPTriggerCache{}, false},
+		{"ttls and vary ok", HTTPTriggerCache{DefaultTTL: d(time.Minute), MaxTTL: d(time.Hour), VaryHeaders: []string{"Accept-Language"}}, false},
+		{"zero default ttl rejected", HTTPTriggerCache{DefaultTTL: d(0)}, true},
+		{"negative max ttl rejected", HTTPTriggerCache{MaxTTL: d(-time.Second)}, true},
+		{"default above max rejected", HTTPTriggerCache{DefaultTTL: d(time.Hour), MaxTTL: d(time.Minute)}, true},
+		{"bad vary header rejected", HTTPTriggerCache{VaryHeaders: []string{"Accept Language"}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.cfg.Validate()
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -39,6 +39,10 @@ func TestInvocationConfigValidate(t *testing.T) {
 		{"zero maxAge", InvocationConfig{MaxAge: md(0)}, true},
 		{"negative maxAge", InvocationConfig{MaxAge: md(-time.Hour)}, true},
 		{"positive maxAge ok", InvocationConfig{MaxAge: md(time.Hour)}, false},
+		{"maxInFlight one ok", InvocationConfig{MaxInFlight: new(int32(1))}, false},
+		{"maxInFlight zero", InvocationConfig{MaxInFlight: new(int32(0))}, true},
+		{"maxDeliveriesPerSecond ok", InvocationConfig{MaxDeliveriesPerSecond: new(int32(50))}, false},
+		{"maxDeliveriesPerSecond negative", InvocationConfig{MaxDeliveriesPerSecond: new(int32(-1))}, true},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -130,6 +134,20 @@ func TestDestinationRefValidate(t *testing.T) {
 		{"statestore topic overlong rejected", DestinationRef{Topic: &TopicRef{MessageQueueType: MessageQueueTypeStatestore, Topic: strings.Repeat("a", 250)}}, true},
 		{"function weights rejected", DestinationRef{Function: &FunctionReference{Type: FunctionReferenceTypeFunctionWeights, Name: "w"}}, true},
 		{"empty function name", DestinationRef{Function: fnRef("  ")}, true},
+		{"webhook ok", DestinationRef{Webhook: &WebhookDestination{
+			URL:           "https://hooks.example.com/fission",
+			Headers:       map[string]string{"Authorization": "Bearer t"},
+			SigningSecret: &WebhookSigningSecret{Name: "hook", Key: "key"},
+			Retry:         RetryPolicy{MaxAttempts: new(2)},
+		}}, false},
+		{"webhook and function", DestinationRef{Function: fnRef("next"), Webhook: &WebhookDestination{URL: "https://h.example.com"}}, true},
+		{"webhook plain http rejected", DestinationRef{Webhook: &WebhookDestination{URL: "http://h.example.com"}}, true},
+		{"webhook relative url rejected", DestinationRef{Webhook: &WebhookDestination{URL: "https:///path"}}, true},
+		{"webhook credentials in url rejected", DestinationRef{Webhook: &WebhookDestination{URL: "https://u:p@h.example.com"}}, true},
+		{"webhook reserved header rejected", DestinationRef{Webhook: &WebhookDestination{URL: "https://h.example.com", Headers: map[string]string{"x-fission-invocation-id": "forged"}}}, true},
+		{"webhook invalid header rejected", DestinationRef{Webhook: &WebhookDestination{URL: "https://h.example.com", Headers: map[string]string{"bad header": "v"}}}, true},
+		{"webhook secret without key", DestinationRef{Webhook: &WebhookDestination{URL: "https://h.example.com", SigningSecret: &WebhookSigningSecret{Name: "hook"}}}, true},
+		{"webhook retry over budget", DestinationRef{Webhook: &WebhookDestination{URL: "https://h.example.com", Retry: RetryPolicy{MaxAttempts: new(MaxAsyncAttempts + 1)}}}, true},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
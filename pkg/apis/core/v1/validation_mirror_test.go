@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestTrafficMirrorValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		cfg     TrafficMirror
+		wantErr bool
+	}{
+		{"version ok", TrafficMirror{Version: "hello-v2", Percent: 10}, false},
+		{"alias ok", TrafficMirror{Alias: "canary", Percent: 100}, false},
+		{"no target rejected", TrafficMirror{Percent: 10}, true},
+		{"both targets rejected", TrafficMirror{Version: "hello-v2", Alias: "canary", Percent: 10}, true},
+		{"bad version name rejected", TrafficMirror{Version: "Hello_v2", Percent: 10}, true},
+		{"zero percent rejected", TrafficMirror{Version: "hello-v2"}, true},
+		{"percent above 100 rejected", TrafficMirror{Alias: "canary", Percent: 101}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.cfg.Validate("HTTPTriggerSpec.Mirror")
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPTriggerSpecMirrorNeedsNameReference(t *testing.T) {
+	t.Parallel()
+	spec := HTTPTriggerSpec{
+		RelativeURL: "/hello",
+		Methods:     []string{"GET"},
+		FunctionReference: FunctionReference{
+			Type:            FunctionReferenceTypeFunctionWeights,
+			FunctionWeights: map[string]int{"a": 50, "b": 50},
+		},
+		Mirror: &TrafficMirror{Version: "a-v2", Percent: 10},
+	}
+	if err := spec.Validate(); err == nil {
+		t.Fatalf("expected error for a mirrored weights reference, got nil")
+	}
+	spec.FunctionReference = FunctionReference{Type: FunctionReferenceTypeFunctionName, Name: "a"}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
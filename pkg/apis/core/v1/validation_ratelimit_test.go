@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		cfg     RateLimitConfig
+		wantErr bool
+	}{
+		{"requests only ok", RateLimitConfig{Requests: 10}, false},
+		{"zero requests rejected", RateLimitConfig{}, true},
+		{"period and burst ok", RateLimitConfig{Requests: 10, Period: &metav1.Duration{Duration: time.Minute}, Burst: 20}, false},
+		{"zero period rejected", RateLimitConfig{Requests: 10, Period: &metav1.Duration{}}, true},
+		{"negative burst rejected", RateLimitConfig{Requests: 10, Burst: -1}, true},
+		{"clientip key ok", RateLimitConfig{Requests: 1, Key: &RateLimitKey{Source: RateLimitKeyClientIP}}, false},
+		{"header key ok", RateLimitConfig{Requests: 1, Key: &RateLimitKey{Source: RateLimitKeyHeader, Name: "X-Api-Key"}}, false},
+		{"claim key without name rejected", RateLimitConfig{Requests: 1, Key: &RateLimitKey{Source: RateLimitKeyJWTClaim}}, true},
+		{"bad key source rejected", RateLimitConfig{Requests: 1, Key: &RateLimitKey{Source: "cookie", Name: "sid"}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.cfg.Validate("HTTPTriggerSpec.RateLimit")
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
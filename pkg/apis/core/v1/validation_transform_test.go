@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestHTTPTriggerTransformValidate(t *testing.T) {
+	t.Parallel()
+	negative := int64(-1)
+	tests := []struct {
+		name    string
+		cfg     HTTPTriggerTransform
+		wantErr bool
+	}{
+		{"empty ok", HTTPTriggerTransform{}, false},
+		{"header edits ok", HTTPTriggerTransform{
+			RequestHeaders:  &HeaderTransform{Add: map[string]string{"X-Api-Version": "2"}, Remove: []string{"Cookie"}},
+			ResponseHeaders: &HeaderTransform{Rename: map[string]string{"Server": "X-Served-By"}},
+		}, false},
+		{"invalid header name rejected", HTTPTriggerTransform{RequestHeaders: &HeaderTransform{Remove: []string{"Bad Header"}}}, true},
+		{"invalid header value rejected", HTTPTriggerTransform{RequestHeaders: &HeaderTransform{Add: map[string]string{"X-A": "a\nb"}}}, true},
+		{"framing header rejected", HTTPTriggerTransform{RequestHeaders: &HeaderTransform{Rename: map[string]string{"X-Len": "content-length"}}}, true},
+		{"mappings ok", HTTPTriggerTransform{Mappings: []ParameterMapping{{Path: "id", Header: "X-Id"}, {Query: "q", BodyField: "q"}}}, false},
+		{"mapping without source rejected", HTTPTriggerTransform{Mappings: []ParameterMapping{{Header: "X-Id"}}}, true},
+		{"mapping with two sources rejected", HTTPTriggerTransform{Mappings: []ParameterMapping{{Path: "id", Query: "id", Header: "X-Id"}}}, true},
+		{"mapping with two destinations rejected", HTTPTriggerTransform{Mappings: []ParameterMapping{{Path: "id", Header: "X-Id", BodyField: "id"}}}, true},
+		{"mapping to Host rejected", HTTPTriggerTransform{Mappings: []ParameterMapping{{Path: "id", Header: "Host"}}}, true},
+		{"negative max body rejected", HTTPTriggerTransform{MaxBodyBytes: &negative}, true},
+		{"content types ok", HTTPTriggerTransform{AllowedContentTypes: []string{"application/json", "text/*"}}, false},
+		{"content type with parameters rejected", HTTPTriggerTransform{AllowedContentTypes: []string{"application/json; charset=utf-8"}}, true},
+		{"content type wildcard rejected", HTTPTriggerTransform{AllowedContentTypes: []string{"*/*"}}, true},
+		{"content type without subtype rejected", HTTPTriggerTransform{AllowedContentTypes: []string{"json"}}, true},
+		{"schema ok", HTTPTriggerTransform{BodySchema: &apiextensionsv1.JSON{Raw: []byte(`{"type":"object"}`)}}, false},
+		{"schema not an object rejected", HTTPTriggerTransform{BodySchema: &apiextensionsv1.JSON{Raw: []byte(`["type"]`)}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.cfg.Validate()
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -44,6 +44,33 @@ func TestMessageQueueTriggerSpecValidateForAdmission(t *testing.T) {
 	require.Error(t, base(func(s *MessageQueueTriggerSpec) { s.MessageQueueType = "unregistered" }).validateForAdmission())
 }
 
+// TestMessageQueueTriggerMessageGroupHeader: message groups ride Kafka record
+// headers into async invocations, so they need a Kafka trigger delivered by
+// fission and have no synchronous response to publish.
+func TestMessageQueueTriggerMessageGroupHeader(t *testing.T) {
+	t.Parallel()
+	validator.Register(MessageQueueTypeKafka, func(string) bool { return true })
+
+	spec := func(mutate func(*MessageQueueTriggerSpec)) MessageQueueTriggerSpec {
+		s := MessageQueueTriggerSpec{
+			FunctionReference:  FunctionReference{Type: FunctionReferenceTypeFunctionName, Name: "fn"},
+			MessageQueueType:   MessageQueueTypeKafka,
+			MqtKind:            "fission",
+			Topic:              "orders",
+			MessageGroupHeader: "X-Customer",
+		}
+		if mutate != nil {
+			mutate(&s)
+		}
+		return s
+	}
+	require.NoError(t, spec(nil).validateForAdmission())
+	require.NoError(t, spec(func(s *MessageQueueTriggerSpec) { s.ErrorTopic = "errs" }).validateForAdmission())
+	require.Error(t, spec(func(s *MessageQueueTriggerSpec) { s.ResponseTopic = "replies" }).validateForAdmission())
+	require.Error(t, spec(func(s *MessageQueueTriggerSpec) { s.MqtKind = "keda" }).validateForAdmission())
+	require.Error(t, spec(func(s *MessageQueueTriggerSpec) { s.MessageGroupHeader = "bad header" }).validateForAdmission())
+}
+
 func TestValidateKubeName(t *testing.T) {
 	t.Parallel()
 	require.NoError(t, ValidateKubeName("f", "valid-name"))
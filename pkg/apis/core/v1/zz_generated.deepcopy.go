@@ -214,6 +214,51 @@ func (in *Checksum) DeepCopy() *Checksum {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreakerConfig) DeepCopyInto(out *CircuitBreakerConfig) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.OpenDuration != nil {
+		in, out := &in.OpenDuration, &out.OpenDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerConfig.
+func (in *CircuitBreakerConfig) DeepCopy() *CircuitBreakerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreakerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaimRequirement) DeepCopyInto(out *ClaimRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimRequirement.
+func (in *ClaimRequirement) DeepCopy() *ClaimRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaimRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
 	*out = *in
@@ -242,6 +287,11 @@ func (in *DestinationRef) DeepCopyInto(out *DestinationRef) {
 		*out = new(TopicRef)
 		**out = **in
 	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookDestination)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationRef.
@@ -590,6 +640,11 @@ func (in *FunctionAliasSpec) DeepCopyInto(out *FunctionAliasSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(TrafficMirror)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FunctionAliasSpec.
@@ -757,6 +812,21 @@ func (in *FunctionSpec) DeepCopyInto(out *FunctionSpec) {
 		*out = new(InvocationConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreakerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutlierEjection != nil {
+		in, out := &in.OutlierEjection, &out.OutlierEjection
+		*out = new(OutlierEjectionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ProvisionedConcurrency != nil {
 		in, out := &in.ProvisionedConcurrency, &out.ProvisionedConcurrency
 		*out = new(ProvisionedConcurrencyConfig)
@@ -943,6 +1013,93 @@ func (in *HTTPTrigger) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTriggerAPIKey) DeepCopyInto(out *HTTPTriggerAPIKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTriggerAPIKey.
+func (in *HTTPTriggerAPIKey) DeepCopy() *HTTPTriggerAPIKey {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTriggerAPIKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTriggerAuthorization) DeepCopyInto(out *HTTPTriggerAuthorization) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make([]ClaimRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTriggerAuthorization.
+func (in *HTTPTriggerAuthorization) DeepCopy() *HTTPTriggerAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTriggerAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTriggerCache) DeepCopyInto(out *HTTPTriggerCache) {
+	*out = *in
+	if in.DefaultTTL != nil {
+		in, out := &in.DefaultTTL, &out.DefaultTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxTTL != nil {
+		in, out := &in.MaxTTL, &out.MaxTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.VaryHeaders != nil {
+		in, out := &in.VaryHeaders, &out.VaryHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTriggerCache.
+func (in *HTTPTriggerCache) DeepCopy() *HTTPTriggerCache {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTriggerCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTriggerCloudEvents) DeepCopyInto(out *HTTPTriggerCloudEvents) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTriggerCloudEvents.
+func (in *HTTPTriggerCloudEvents) DeepCopy() *HTTPTriggerCloudEvents {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTriggerCloudEvents)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPTriggerCorsConfig) DeepCopyInto(out *HTTPTriggerCorsConfig) {
 	*out = *in
@@ -1035,6 +1192,41 @@ func (in *HTTPTriggerSpec) DeepCopyInto(out *HTTPTriggerSpec) {
 		*out = new(HTTPTriggerCorsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CloudEvents != nil {
+		in, out := &in.CloudEvents, &out.CloudEvents
+		*out = new(HTTPTriggerCloudEvents)
+		**out = **in
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(HTTPTriggerAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(HTTPTriggerAPIKey)
+		**out = **in
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(HTTPTriggerCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(TrafficMirror)
+		**out = **in
+	}
+	if in.Transform != nil {
+		in, out := &in.Transform, &out.Transform
+		*out = new(HTTPTriggerTransform)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTriggerSpec.
@@ -1069,6 +1261,85 @@ func (in *HTTPTriggerStatus) DeepCopy() *HTTPTriggerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPTriggerTransform) DeepCopyInto(out *HTTPTriggerTransform) {
+	*out = *in
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = new(HeaderTransform)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseHeaders != nil {
+		in, out := &in.ResponseHeaders, &out.ResponseHeaders
+		*out = new(HeaderTransform)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mappings != nil {
+		in, out := &in.Mappings, &out.Mappings
+		*out = make([]ParameterMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxBodyBytes != nil {
+		in, out := &in.MaxBodyBytes, &out.MaxBodyBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AllowedContentTypes != nil {
+		in, out := &in.AllowedContentTypes, &out.AllowedContentTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BodySchema != nil {
+		in, out := &in.BodySchema, &out.BodySchema
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPTriggerTransform.
+func (in *HTTPTriggerTransform) DeepCopy() *HTTPTriggerTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPTriggerTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderTransform) DeepCopyInto(out *HeaderTransform) {
+	*out = *in
+	if in.Add != nil {
+		in, out := &in.Add, &out.Add
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rename != nil {
+		in, out := &in.Rename, &out.Rename
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderTransform.
+func (in *HeaderTransform) DeepCopy() *HeaderTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 	*out = *in
@@ -1110,6 +1381,16 @@ func (in *InvocationConfig) DeepCopyInto(out *InvocationConfig) {
 		*out = new(DestinationRef)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaxInFlight != nil {
+		in, out := &in.MaxInFlight, &out.MaxInFlight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDeliveriesPerSecond != nil {
+		in, out := &in.MaxDeliveriesPerSecond, &out.MaxDeliveriesPerSecond
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InvocationConfig.
@@ -1207,6 +1488,16 @@ func (in *KubernetesWatchTriggerSpec) DeepCopyInto(out *KubernetesWatchTriggerSp
 			(*out)[key] = val
 		}
 	}
+	if in.EventTypes != nil {
+		in, out := &in.EventTypes, &out.EventTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChangedPaths != nil {
+		in, out := &in.ChangedPaths, &out.ChangedPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.FunctionReference.DeepCopyInto(&out.FunctionReference)
 }
 
@@ -1391,6 +1682,26 @@ func (in *OCIArchive) DeepCopy() *OCIArchive {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutlierEjectionConfig) DeepCopyInto(out *OutlierEjectionConfig) {
+	*out = *in
+	if in.EjectionDuration != nil {
+		in, out := &in.EjectionDuration, &out.EjectionDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutlierEjectionConfig.
+func (in *OutlierEjectionConfig) DeepCopy() *OutlierEjectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OutlierEjectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Package) DeepCopyInto(out *Package) {
 	*out = *in
@@ -1506,6 +1817,21 @@ func (in *PackageStatus) DeepCopy() *PackageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParameterMapping) DeepCopyInto(out *ParameterMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParameterMapping.
+func (in *ParameterMapping) DeepCopy() *ParameterMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ParameterMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProvisionedConcurrencyConfig) DeepCopyInto(out *ProvisionedConcurrencyConfig) {
 	*out = *in
@@ -1541,6 +1867,46 @@ func (in *ProvisionedWindow) DeepCopy() *ProvisionedWindow {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitConfig) DeepCopyInto(out *RateLimitConfig) {
+	*out = *in
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(RateLimitKey)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitConfig.
+func (in *RateLimitConfig) DeepCopy() *RateLimitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitKey) DeepCopyInto(out *RateLimitKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitKey.
+func (in *RateLimitKey) DeepCopy() *RateLimitKey {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
 	*out = *in
@@ -1781,6 +2147,18 @@ func (in *TimeTriggerList) DeepCopyObject() runtime.Object {
 func (in *TimeTriggerSpec) DeepCopyInto(out *TimeTriggerSpec) {
 	*out = *in
 	in.FunctionReference.DeepCopyInto(&out.FunctionReference)
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeTriggerSpec.
@@ -1796,6 +2174,14 @@ func (in *TimeTriggerSpec) DeepCopy() *TimeTriggerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeTriggerStatus) DeepCopyInto(out *TimeTriggerStatus) {
 	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -1850,6 +2236,21 @@ func (in *TopicRef) DeepCopy() *TopicRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficMirror) DeepCopyInto(out *TrafficMirror) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficMirror.
+func (in *TrafficMirror) DeepCopy() *TrafficMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValidationError) DeepCopyInto(out *ValidationError) {
 	*out = *in
@@ -1885,6 +2286,49 @@ func (in *VersioningConfig) DeepCopy() *VersioningConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDestination) DeepCopyInto(out *WebhookDestination) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SigningSecret != nil {
+		in, out := &in.SigningSecret, &out.SigningSecret
+		*out = new(WebhookSigningSecret)
+		**out = **in
+	}
+	in.Retry.DeepCopyInto(&out.Retry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDestination.
+func (in *WebhookDestination) DeepCopy() *WebhookDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookSigningSecret) DeepCopyInto(out *WebhookSigningSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookSigningSecret.
+func (in *WebhookSigningSecret) DeepCopy() *WebhookSigningSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookSigningSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workflow) DeepCopyInto(out *Workflow) {
 	*out = *in
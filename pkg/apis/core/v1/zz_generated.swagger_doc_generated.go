@@ -103,6 +103,29 @@ func (Checksum) SwaggerDoc() map[string]string {
 	return map_Checksum
 }
 
+var map_CircuitBreakerConfig = map[string]string{
+	"":                    "CircuitBreakerConfig is a function's circuit breaker. A request fails when the function answers 5xx or the router cannot get an answer from it; client disconnects and executor capacity rejections do not count. The circuit opens when either threshold is crossed, rejects every request for OpenDuration, then lets HalfOpenRequests probe requests through: if they all succeed it closes, if any fails it opens again.",
+	"consecutiveFailures": "ConsecutiveFailures opens the circuit after this many failed requests in a row.",
+	"failureRatePercent":  "FailureRatePercent opens the circuit when at least this share of the requests in the last Window failed.",
+	"minimumRequests":     "MinimumRequests is how many requests Window must hold before FailureRatePercent applies. Defaults to 20.",
+	"window":              "Window is the span FailureRatePercent is measured over. Defaults to 10s.",
+	"openDuration":        "OpenDuration is how long an open circuit rejects requests before probing the function. Defaults to 30s.",
+	"halfOpenRequests":    "HalfOpenRequests is how many probe requests a half-open circuit lets through at a time, and how many must succeed to close it. Defaults to 1.",
+}
+
+func (CircuitBreakerConfig) SwaggerDoc() map[string]string {
+	return map_CircuitBreakerConfig
+}
+
+var map_ClaimRequirement = map[string]string{
+	"":       "ClaimRequirement is satisfied by a token whose claim Name equals one of Values or, for a list claim, contains one of them.",
+	"values": "Values accepted for the claim. Empty only requires the claim to be present.",
+}
+
+func (ClaimRequirement) SwaggerDoc() map[string]string {
+	return map_ClaimRequirement
+}
+
 var map_ConfigMapReference = map[string]string{
 	"":          "ConfigMapReference is a reference to a kubernetes configmap.",
 	"mountPath": "MountPath redirects this configmap's file projection from the default /configs/<namespace>/<name>; relative to the /configs root. See SecretReference.MountPath for the constraint rationale.",
@@ -113,9 +136,11 @@ func (ConfigMapReference) SwaggerDoc() map[string]string {
 }
 
 var map_DestinationRef = map[string]string{
-	"":         "DestinationRef routes an async invocation's result to exactly one target: a Function (invoked async through the same machinery, depth-capped) or a Topic (published to a message queue). Exactly one of Function/Topic must be set. Topic destinations on the built-in statestore provider are supported (RFC-0027); broker types are rejected by the webhook until the egress phase lands.",
-	"function": "Function is a same-namespace function destination, invoked asynchronously with the result envelope as its body (depth-capped to stop runaway chains).",
-	"topic":    "Topic publishes the result envelope to a message-queue topic.",
+	"":            "DestinationRef routes an async invocation's result to exactly one target: a Function (invoked async through the same machinery, depth-capped) or a Topic (published to a message queue). Exactly one of Function/Topic must be set. Topic destinations on the built-in statestore provider are supported (RFC-0027); broker types are rejected by the webhook until the egress phase lands.",
+	"function":    "Function is a same-namespace function destination, invoked asynchronously with the result envelope as its body (depth-capped to stop runaway chains).",
+	"topic":       "Topic publishes the result envelope to a message-queue topic.",
+	"webhook":     "Webhook POSTs the result envelope to an external HTTPS endpoint. Deliveries that keep failing are dead-lettered to the webhook queue (`fission fn dlq list --queue webhook`), recorded against this destination.",
+	"cloudEvents": "CloudEvents wraps the result envelope in a CloudEvent of type io.fission.async.success or io.fission.async.failure, with an id derived from the invocation so a consumer can deduplicate. A function destination receives it in binary mode, a topic in structured mode.",
 }
 
 func (DestinationRef) SwaggerDoc() map[string]string {
@@ -255,6 +280,7 @@ var map_FunctionAliasSpec = map[string]string{
 	"packageDigest":    "PackageDigest pins declaratively (GitOps): resolved asynchronously to the FunctionVersion that recorded this digest; eventually consistent.",
 	"weight":           "Weight (0-100) served by the primary target; nil = 100%.",
 	"secondaryVersion": "SecondaryVersion receives 100-Weight. Name-pinned only.",
+	"mirror":           "Mirror copies a sampled share of the requests of every HTTPTrigger resolving through this alias to a shadow version, e.g. the next release before it takes any weight. A trigger's own Mirror wins.",
 }
 
 func (FunctionAliasSpec) SwaggerDoc() map[string]string {
@@ -318,6 +344,9 @@ var map_FunctionSpec = map[string]string{
 	"tool":                   "Tool, when non-nil, advertises this function as a Model Context Protocol (MCP) tool on the fission-bundle --mcpPort server. The MCP server watches Function CRDs and hot-updates its tool list from this field. Presence is the on switch (like Streaming): nil (the default) means the function is never advertised as a tool. Additive and backward compatible.",
 	"state":                  "State, when non-nil, opts this function into the RFC-0023 keyed-state API: a scoped statesvc keyspace backed by the RFC-0021 statestore, with a per-function token injected at specialization time. Presence is the on switch (like Streaming and Tool): nil (the default) means exactly today's behavior. Additive and backward compatible.",
 	"invocation":             "Invocation, when non-nil, tunes RFC-0024 asynchronous invocation (X-Fission-Invoke-Mode: async) for this function: the durable retry policy and the maximum event age before an undelivered invocation is dead-lettered. A function without it still accepts async mode with platform defaults; this field only tunes them. Additive and backward compatible.",
+	"rateLimit":              "RateLimit, when non-nil, is the default request-rate limit for the HTTPTriggers that route to this function. One set of buckets is shared by every such trigger; a trigger with its own RateLimit uses that instead. Requests on the router's internal listener (message queue triggers, async delivery) are not limited.",
+	"circuitBreaker":         "CircuitBreaker, when non-nil, has the router stop sending requests to this function while it is failing: once failures cross a threshold the circuit opens and requests are answered 503 with a Retry-After header, until probe requests find the function healthy again. Each published version has its own circuit. Each router replica keeps its own.",
+	"outlierEjection":        "OutlierEjection, when non-nil, has the router stop sending requests to a single pod of this function that keeps failing while its other pods serve. Applies to pods the router admits requests to directly (the EndpointSlice-fed warm path).",
 	"concurrency":            "Maximum number of pods to be specialized which will serve requests This is optional. If not specified default value will be taken as 500",
 	"requestsPerPod":         "RequestsPerPod indicates the maximum number of concurrent requests that can be served by a specialized pod This is optional. If not specified default value will be taken as 1",
 	"onceOnly":               "OnceOnly specifies if specialized pod will serve exactly one request in its lifetime and would be garbage collected after serving that one request This is optional. If not specified default value will be taken as false",
@@ -401,6 +430,47 @@ func (HTTPTrigger) SwaggerDoc() map[string]string {
 	return map_HTTPTrigger
 }
 
+var map_HTTPTriggerAPIKey = map[string]string{
+	"":           "HTTPTriggerAPIKey names the Secret holding an HTTPTrigger's API keys and where requests carry the key. The Secret, in the trigger's namespace, maps each key's name to the hex-encoded SHA-256 digest of the key, so the key itself is never stored; `fission httptrigger apikey` manages it. The name of the matching key is recorded in the router's access log and metrics. The router rereads the Secret at most every 30 seconds, which bounds how long a revoked key keeps working.",
+	"header":     "Header carrying the key. Defaults to X-Api-Key unless QueryParam is set.",
+	"queryParam": "QueryParam carrying the key, read when the request has no Header.",
+}
+
+func (HTTPTriggerAPIKey) SwaggerDoc() map[string]string {
+	return map_HTTPTriggerAPIKey
+}
+
+var map_HTTPTriggerAuthorization = map[string]string{
+	"":       "HTTPTriggerAuthorization lists what a caller's token must grant to invoke through an HTTPTrigger. All entries must hold.",
+	"scopes": "Scopes the token must all grant, read from its space-separated \"scope\" claim or its \"scp\" list.",
+	"claims": "Claims the token must carry.",
+}
+
+func (HTTPTriggerAuthorization) SwaggerDoc() map[string]string {
+	return map_HTTPTriggerAuthorization
+}
+
+var map_HTTPTriggerCache = map[string]string{
+	"":            "HTTPTriggerCache opts an HTTPTrigger into router-side response caching. Only 200 responses to GET requests are stored, keyed by host, path, query and the request's VaryHeaders; HEAD requests are answered from the same entries. The function's Cache-Control decides what is stored and for how long: no-store, no-cache and private responses, and responses setting cookies, are never stored, and s-maxage, else max-age, else Expires gives the freshness lifetime. A stale entry with an ETag or Last-Modified is revalidated with the function instead of fetched again, and a client's conditional request matching a fresh entry is answered 304. A response declaring no lifetime is stored only when DefaultTTL is set. Requests carrying an Authorization header or an API key are served and stored only for responses marked public or with s-maxage. The router bounds the memory all triggers' entries use together.",
+	"defaultTTL":  "DefaultTTL is the freshness lifetime of a response that declares none. Unset leaves such responses uncached.",
+	"maxTTL":      "MaxTTL caps the freshness lifetime of every response, whatever the function declares. Defaults to 1h.",
+	"varyHeaders": "VaryHeaders names the request headers whose values are part of the cache key, e.g. Accept-Language. A response whose Vary header names any other request header is not stored.",
+	"shared":      "Shared keeps the entries in the statestore KV instead of each router replica's memory, so replicas serve one another's entries and a purge reaches all of them. Entries stay in memory when the router runs without the statestore.",
+}
+
+func (HTTPTriggerCache) SwaggerDoc() map[string]string {
+	return map_HTTPTriggerCache
+}
+
+var map_HTTPTriggerCloudEvents = map[string]string{
+	"":         "HTTPTriggerCloudEvents configures how an HTTPTrigger accepts CloudEvents.",
+	"required": "Required rejects a request that carries no CloudEvent with 400. By default such requests reach the function unchanged.",
+}
+
+func (HTTPTriggerCloudEvents) SwaggerDoc() map[string]string {
+	return map_HTTPTriggerCloudEvents
+}
+
 var map_HTTPTriggerCorsConfig = map[string]string{
 	"":                 "HTTPTriggerCorsConfig is the per-HTTPTrigger CORS allowlist. It is consumed by the router public listener to attach a CORS middleware to the trigger's route. Triggers without a CorsConfig receive no Access-Control-* response headers and therefore deny cross-origin browser reads at the Same-Origin Policy layer.",
 	"allowOrigins":     "AllowOrigins is the list of allowed origins (scheme + host + port). Use [\"*\"] to allow any origin. Mixing \"*\" with AllowCredentials=true is a configuration error and is rejected by validation; browsers refuse the response in that combination.",
@@ -437,6 +507,13 @@ var map_HTTPTriggerSpec = map[string]string{
 	"ingressconfig":  "IngressConfig for router to set up Ingress. Deprecated: superseded by RouteConfig. See CreateIngress.",
 	"routeConfig":    "RouteConfig declares how the router exposes this trigger through an external route provider (Ingress or the Gateway API). It is the provider-neutral successor to CreateIngress + IngressConfig: when set it takes precedence over those fields. Leave nil to expose the function only through the router's own URL.",
 	"corsConfig":     "CorsConfig configures CORS response headers for browser callers of this trigger. When nil, the router emits no Access-Control-* headers and the browser's Same-Origin Policy enforces cluster isolation from cross-origin pages (the deny-by-default behaviour). Set this field to allowlist specific origins for SPAs that legitimately call this trigger cross-origin.",
+	"cloudEvents":    "CloudEvents, when set, makes the router read requests to this trigger as CloudEvents 1.0. A binary-mode event is validated and passed on as it is; a structured-mode event is converted to binary mode, so the function always sees the context attributes as ce-* headers and the event data as the body. An invalid event is rejected with 400. nil leaves requests untouched.",
+	"rateLimit":      "RateLimit, when set, limits the request rate through this trigger, replacing the target function's RateLimit default.",
+	"authorization":  "Authorization, when set, admits only callers whose verified token carries the listed scopes and claims; others get 403. Tokens are verified by the router's authentication, so a trigger with Authorization answers 401 to every request while authentication is disabled.",
+	"apiKey":         "APIKey, when set, admits only requests that present one of the API keys held in a Secret; others get 401. It is checked independently of the router's token authentication.",
+	"cache":          "Cache, when set, lets the router answer GET and HEAD requests through this trigger from the function's earlier responses instead of invoking it again.",
+	"mirror":         "Mirror, when set, copies a sampled share of the requests through this trigger to another version of its function. It takes precedence over the Mirror of a FunctionAlias the trigger references. Only name-type function references can be mirrored.",
+	"transform":      "Transform, when set, checks and reshapes requests through this trigger before they reach the function, and edits the headers of its responses.",
 }
 
 func (HTTPTriggerSpec) SwaggerDoc() map[string]string {
@@ -451,6 +528,31 @@ func (HTTPTriggerStatus) SwaggerDoc() map[string]string {
 	return map_HTTPTriggerStatus
 }
 
+var map_HTTPTriggerTransform = map[string]string{
+	"":                    "HTTPTriggerTransform declares how the router checks and reshapes an HTTPTrigger's traffic. A request is checked first, against AllowedContentTypes, MaxBodyBytes and BodySchema in that order, and rejected before any function pod is involved: 415, 413 and 400 respectively. An accepted request then has RequestHeaders applied, then Mappings. ResponseHeaders applies to the function's responses, not to errors the router answers itself.",
+	"requestHeaders":      "RequestHeaders edits the headers of requests to the function.",
+	"responseHeaders":     "ResponseHeaders edits the headers of the function's responses.",
+	"mappings":            "Mappings copy path and query parameters of the request into its headers or JSON body. A mapping whose parameter is absent from the request is skipped.",
+	"maxBodyBytes":        "MaxBodyBytes rejects requests whose body is larger with 413.",
+	"allowedContentTypes": "AllowedContentTypes rejects requests that carry a body with any other media type with 415. An entry is a media type such as application/json, or a type wildcard such as text/*; parameters like charset are ignored.",
+	"bodySchema":          "BodySchema is a JSON Schema (draft 2020-12 or draft-07) the JSON request body must satisfy, else the request is rejected with 400. GET, HEAD and OPTIONS requests are not checked. The schema must be self-contained: references to other documents are not resolved. Stored as raw JSON so the CRD does not constrain the schema shape.",
+}
+
+func (HTTPTriggerTransform) SwaggerDoc() map[string]string {
+	return map_HTTPTriggerTransform
+}
+
+var map_HeaderTransform = map[string]string{
+	"":       "HeaderTransform edits a message's headers: Rename first, then Remove, then Add. Header names are case-insensitive.",
+	"add":    "Add sets each header to its value, replacing any value the message carried.",
+	"remove": "Remove deletes the listed headers.",
+	"rename": "Rename moves each header's values to a new name, keyed by the old name. A header the message does not carry is left alone.",
+}
+
+func (HeaderTransform) SwaggerDoc() map[string]string {
+	return map_HeaderTransform
+}
+
 var map_IngressConfig = map[string]string{
 	"":            "IngressConfig is for router to set up Ingress. Deprecated: superseded by RouteConfig. The Kubernetes Ingress API is frozen; use RouteConfig with Provider \"gateway\" for new triggers.",
 	"annotations": "Annotations will be added to metadata when creating Ingress.",
@@ -464,11 +566,13 @@ func (IngressConfig) SwaggerDoc() map[string]string {
 }
 
 var map_InvocationConfig = map[string]string{
-	"":          "InvocationConfig tunes RFC-0024 asynchronous invocation for a function. Presence of the enclosing FunctionSpec.Invocation is optional — a function without it still accepts async mode (X-Fission-Invoke-Mode: async) with platform defaults; this struct only tunes them. Field bounds are validated in Go (InvocationConfig.Validate, run at admission via validateForAdmission), not CEL, because metav1.Duration CEL rules are unproven in this CRD. An external dead-letter target is a later RFC-0024 phase.",
-	"retry":     "Retry is the durable delivery retry policy. The zero value means platform defaults (a bounded exponential backoff over DefaultMaxAttempts attempts).",
-	"maxAge":    "MaxAge caps how long an invocation may wait for successful delivery, measured from its enqueue time; once exceeded it is dead-lettered with reason \"expired\". nil means the platform default. Must be > 0 when set.",
-	"onSuccess": "OnSuccess, when set, invokes a destination with a Lambda-shaped result envelope after the invocation is delivered successfully (2xx).",
-	"onFailure": "OnFailure, when set, invokes a destination with the result envelope after the invocation permanently fails (a non-retryable 4xx, the retry budget spent, or MaxAge exceeded).",
+	"":                       "InvocationConfig tunes RFC-0024 asynchronous invocation for a function. Presence of the enclosing FunctionSpec.Invocation is optional — a function without it still accepts async mode (X-Fission-Invoke-Mode: async) with platform defaults; this struct only tunes them. Field bounds are validated in Go (InvocationConfig.Validate, run at admission via validateForAdmission), not CEL, because metav1.Duration CEL rules are unproven in this CRD. An external dead-letter target is a later RFC-0024 phase.",
+	"retry":                  "Retry is the durable delivery retry policy. The zero value means platform defaults (a bounded exponential backoff over DefaultMaxAttempts attempts).",
+	"maxAge":                 "MaxAge caps how long an invocation may wait for successful delivery, measured from its enqueue time; once exceeded it is dead-lettered with reason \"expired\". nil means the platform default. Must be > 0 when set.",
+	"onSuccess":              "OnSuccess, when set, invokes a destination with a Lambda-shaped result envelope after the invocation is delivered successfully (2xx).",
+	"onFailure":              "OnFailure, when set, invokes a destination with the result envelope after the invocation permanently fails (a non-retryable 4xx, the retry budget spent, or MaxAge exceeded).",
+	"maxInFlight":            "MaxInFlight caps how many async deliveries to this function run at once, across all router replicas. An invocation over the cap stays queued without spending a retry attempt (it still ages toward MaxAge). nil means no cap. Must be >= 1 when set.",
+	"maxDeliveriesPerSecond": "MaxDeliveriesPerSecond caps the rate at which async deliveries to this function start, across all router replicas, so a redriven backlog or a burst drains at a pace the function and its dependencies can take. Throttled invocations stay queued like MaxInFlight's. nil means no cap. Must be >= 1 when set.",
 }
 
 func (InvocationConfig) SwaggerDoc() map[string]string {
@@ -503,8 +607,13 @@ func (KubernetesWatchTriggerList) SwaggerDoc() map[string]string {
 
 var map_KubernetesWatchTriggerSpec = map[string]string{
 	"":              "KubernetesWatchTriggerSpec defines spec of KuberenetesWatchTrigger",
-	"type":          "Type of resource to watch (Pod, Service, etc.)",
-	"labelselector": "Resource labels",
+	"type":          "Type of resource to watch (Pod, Service, etc.), the shorthand for the four built-in kinds the watcher has always supported. Use APIVersion and Kind for anything else.",
+	"apiVersion":    "APIVersion and Kind select any namespaced resource by group, version and kind instead of Type: \"apps/v1\" Deployment, \"v1\" ConfigMap, \"fission.io/v1\" Function or a third-party CRD such as \"cert-manager.io/v1\" Certificate. The kind must be served by the API server when the trigger is admitted, and the kubewatcher resolves it through discovery each time the watch starts. Cluster-scoped kinds are refused: a watch never reaches past the trigger's namespace. Admission checks that whoever creates or updates the trigger may list and watch the kind in that namespace, and the kubewatcher needs the same access (kubewatcher.watchRules in the chart).",
+	"kind":          "Kind is the kind to watch together with APIVersion, e.g. Deployment.",
+	"labelselector": "Resource labels: only objects carrying all of them are watched.",
+	"fieldSelector": "FieldSelector narrows the watch server-side, e.g. \"status.phase=Failed\" for pods. The API server decides which fields a kind supports: only metadata.name and metadata.namespace for most custom resources, unless the CRD declares selectableFields.",
+	"eventTypes":    "EventTypes lists the watch event types delivered to the function; empty delivers ADDED, MODIFIED and DELETED.",
+	"changedPaths":  "ChangedPaths, when set, delivers a MODIFIED event only if the value at one of these JSONPaths differs from the last version of the object the watch saw, e.g. \".spec.replicas\" or `.status.conditions[?(@.type==\"Ready\")].status`. Status churn and resyncs that leave them alone are suppressed. ADDED and DELETED events are not compared.",
 	"functionref":   "The reference to a function for kubewatcher to invoke with when receiving events.",
 }
 
@@ -513,7 +622,8 @@ func (KubernetesWatchTriggerSpec) SwaggerDoc() map[string]string {
 }
 
 var map_KubernetesWatchTriggerStatus = map[string]string{
-	"": "KubernetesWatchTriggerStatus describes the observed state of a KubernetesWatchTrigger.",
+	"":                "KubernetesWatchTriggerStatus describes the observed state of a KubernetesWatchTrigger.",
+	"deliveredEvents": "DeliveredEvents counts the watch events sent to the function, and SuppressedEvents those dropped by EventTypes or ChangedPaths. Both are written by the kubewatcher every few seconds while events flow.",
 }
 
 func (KubernetesWatchTriggerStatus) SwaggerDoc() map[string]string {
@@ -537,22 +647,24 @@ func (MessageQueueTriggerList) SwaggerDoc() map[string]string {
 }
 
 var map_MessageQueueTriggerSpec = map[string]string{
-	"":                 "MessageQueueTriggerSpec defines a binding from a topic in a message queue to a function.",
-	"functionref":      "The reference to a function for message queue trigger to invoke with when receiving messages from subscribed topic.",
-	"messageQueueType": "Type of message queue (NATS, Kafka, AzureQueue)",
-	"topic":            "Subscribed topic",
-	"respTopic":        "Topic for message queue trigger to sent response from function.",
-	"errorTopic":       "Topic to collect error response sent from function",
-	"maxRetries":       "Maximum times for message queue trigger to retry",
-	"contentType":      "Content type of payload",
-	"pollingInterval":  "The period to check each trigger source on every ScaledObject, and scale the deployment up or down accordingly",
-	"cooldownPeriod":   "The period to wait after the last trigger reported active before scaling the deployment back to 0",
-	"minReplicaCount":  "Minimum number of replicas KEDA will scale the deployment down to",
-	"maxReplicaCount":  "Maximum number of replicas KEDA will scale the deployment up to",
-	"metadata":         "ScalerTrigger fields",
-	"secret":           "Secret name",
-	"mqtkind":          "Kind of Message Queue Trigger to be created, by default its fission",
-	"podspec":          "(Optional) Podspec allows modification of deployed runtime pod with Kubernetes PodSpec The merging logic is briefly described below and detailed MergePodSpec function - Volumes mounts and env variables for function and fetcher container are appended - All additional containers and init containers are appended - Volume definitions are appended - Lists such as tolerations, ImagePullSecrets, HostAliases are appended - Structs are merged and variables from pod spec take precedence",
+	"":                   "MessageQueueTriggerSpec defines a binding from a topic in a message queue to a function.",
+	"functionref":        "The reference to a function for message queue trigger to invoke with when receiving messages from subscribed topic.",
+	"messageQueueType":   "Type of message queue (NATS, Kafka, AzureQueue)",
+	"topic":              "Subscribed topic",
+	"respTopic":          "Topic for message queue trigger to sent response from function.",
+	"errorTopic":         "Topic to collect error response sent from function",
+	"maxRetries":         "Maximum times for message queue trigger to retry",
+	"contentType":        "Content type of payload",
+	"pollingInterval":    "The period to check each trigger source on every ScaledObject, and scale the deployment up or down accordingly",
+	"cooldownPeriod":     "The period to wait after the last trigger reported active before scaling the deployment back to 0",
+	"minReplicaCount":    "Minimum number of replicas KEDA will scale the deployment down to",
+	"maxReplicaCount":    "Maximum number of replicas KEDA will scale the deployment up to",
+	"metadata":           "ScalerTrigger fields",
+	"secret":             "Secret name",
+	"mqtkind":            "Kind of Message Queue Trigger to be created, by default its fission",
+	"cloudEvents":        "CloudEvents delivers each message to the function as a binary-mode CloudEvent, wrapping a plain message with an id derived from its position in the topic so redeliveries keep the same id, and publishes to ResponseTopic and ErrorTopic in structured mode. A message that is already a CloudEvent keeps its attributes. Not supported with mqtkind keda.",
+	"messageGroupHeader": "MessageGroupHeader names a message header whose value is the message group (FIFO key) of each message, e.g. a customer id. When set, each message is handed to the function as an async invocation (RFC-0024) in that group, sent with X-Fission-Message-Group: the trigger moves on once the router has durably accepted it, and the async dispatcher delivers each group's messages one at a time in topic order while different groups run in parallel. A message without the header is delivered async but unordered. The function's async invocation config, not MaxRetries, then governs delivery retries, and its onSuccess destination replaces ResponseTopic, which must be unset. Kafka only, where record headers are the message headers; not supported with mqtkind keda.",
+	"podspec":            "(Optional) Podspec allows modification of deployed runtime pod with Kubernetes PodSpec The merging logic is briefly described below and detailed MergePodSpec function - Volumes mounts and env variables for function and fetcher container are appended - All additional containers and init containers are appended - Volume definitions are appended - Lists such as tolerations, ImagePullSecrets, HostAliases are appended - Structs are merged and variables from pod spec take precedence",
 }
 
 func (MessageQueueTriggerSpec) SwaggerDoc() map[string]string {
@@ -579,6 +691,17 @@ func (OCIArchive) SwaggerDoc() map[string]string {
 	return map_OCIArchive
 }
 
+var map_OutlierEjectionConfig = map[string]string{
+	"":                    "OutlierEjectionConfig ejects a function's pod that fails ConsecutiveFailures requests in a row, with failures counted as for CircuitBreakerConfig: the router sends it no requests for EjectionDuration, or until the function's endpoints change. A pod is not ejected when that would leave more than MaxEjectionPercent of the function's pods ejected, so a function failing everywhere is left to its circuit breaker.",
+	"consecutiveFailures": "ConsecutiveFailures ejects a pod after this many failed requests in a row.",
+	"ejectionDuration":    "EjectionDuration is how long an ejected pod gets no requests. Defaults to 30s.",
+	"maxEjectionPercent":  "MaxEjectionPercent caps the share of the function's pods ejected at once; one pod of several can always be ejected. Defaults to 50.",
+}
+
+func (OutlierEjectionConfig) SwaggerDoc() map[string]string {
+	return map_OutlierEjectionConfig
+}
+
 var map_Package = map[string]string{
 	"":       "Package Think of these as function-level images.",
 	"status": "Status indicates the build status of package.",
@@ -631,6 +754,18 @@ func (PackageStatus) SwaggerDoc() map[string]string {
 	return map_PackageStatus
 }
 
+var map_ParameterMapping = map[string]string{
+	"":          "ParameterMapping copies one request parameter, a path parameter or a query parameter, into a request header or a field of the JSON body.",
+	"path":      "Path names a parameter of the trigger's URL template, e.g. id for /items/{id}.",
+	"query":     "Query names a query parameter. The first value is used.",
+	"header":    "Header receives the value, replacing any value the request carried.",
+	"bodyField": "BodyField names the top-level field of the JSON request body that receives the value, as a string. The body must be empty or a JSON object; an empty body becomes one.",
+}
+
+func (ParameterMapping) SwaggerDoc() map[string]string {
+	return map_ParameterMapping
+}
+
 var map_ProvisionedConcurrencyConfig = map[string]string{
 	"":        "ProvisionedConcurrencyConfig opts this function into eager pre-warming of specialized pods (RFC-0026). Presence is the on switch: nil (the default) means the function uses the classic on-demand cold-start path. When non-nil, the executor's provisioner keeps at least Target specialized pods warm and published to the function's headless Service, exempt from the idle reaper. Additive and backward compatible.",
 	"target":  "Target is the base number of warm specialized pods to maintain outside any schedule window. Must be >= 1. Schedule windows may override this (see Windows). Bounded by the namespace cap (executor.provisionedConcurrency.maxPerFunction, default 20).",
@@ -653,6 +788,28 @@ func (ProvisionedWindow) SwaggerDoc() map[string]string {
 	return map_ProvisionedWindow
 }
 
+var map_RateLimitConfig = map[string]string{
+	"":         "RateLimitConfig is a token-bucket request-rate limit enforced by the router. Each key gets a bucket of Burst tokens refilled at Requests per Period; a request finding its bucket empty is answered 429 with a Retry-After header and never reaches the function. When the router runs with the statestore, buckets live in its KV store so the limit holds across router replicas; otherwise each replica enforces it on its own.",
+	"requests": "Requests is the number of requests allowed per Period.",
+	"period":   "Period is the window Requests is measured over. Defaults to 1s.",
+	"burst":    "Burst is how many requests a key may send at once after being idle. Defaults to Requests.",
+	"key":      "Key selects what requests are grouped by. nil gives all requests a single shared bucket.",
+}
+
+func (RateLimitConfig) SwaggerDoc() map[string]string {
+	return map_RateLimitConfig
+}
+
+var map_RateLimitKey = map[string]string{
+	"":       "RateLimitKey declares how a rate limit's bucket key is taken from a request. A request without the header or claim is keyed by its client IP.",
+	"source": "Source is where to take the key from: the client IP (the connection's address, or its last X-Forwarded-For entry when it is a trusted proxy), a request header, or a claim of the bearer JWT. Only claims router auth verified are read; without them the request is keyed by its client IP.",
+	"name":   "Name is the header or claim name, e.g. \"X-Api-Key\" or \"sub\". Unused for clientip.",
+}
+
+func (RateLimitKey) SwaggerDoc() map[string]string {
+	return map_RateLimitKey
+}
+
 var map_RetryPolicy = map[string]string{
 	"":            "RetryPolicy is the async delivery retry policy: the attempt budget and the exponential-backoff schedule between delivery attempts. All fields are optional; a nil field takes the platform default.",
 	"maxAttempts": "MaxAttempts is the total number of delivery attempts before the invocation is dead-lettered. nil means DefaultMaxAttempts. Must be >= 1 when set.",
@@ -759,11 +916,16 @@ func (TimeTriggerList) SwaggerDoc() map[string]string {
 }
 
 var map_TimeTriggerSpec = map[string]string{
-	"":            "TimeTriggerSpec invokes the specific function at a time or times specified by a cron string.",
-	"cron":        "Cron schedule",
-	"functionref": "The reference to function. Alias is read from the embedded FunctionReference.Alias (RFC-0025) — TimeTriggerSpec has no field of its own for it, so there is exactly one JSON path (spec.functionref.alias) and one Go path (spec.Alias, promoted) for the concept, never two competing ones. The timer publisher (a later RFC-0025 task) reads it the same way timer.go:80 already reads the promoted spec.Name today.",
-	"method":      "HTTP Method for trigger, ex : GET, POST, PUT, DELETE, HEAD (default: \"POST\")",
-	"subpath":     "Subpath to trigger a specific route if function internally supports routing, (default: \"/\")",
+	"":                        "TimeTriggerSpec invokes the specific function at a time or times specified by a cron string.",
+	"cron":                    "Cron schedule",
+	"functionref":             "The reference to function. Alias is read from the embedded FunctionReference.Alias (RFC-0025) — TimeTriggerSpec has no field of its own for it, so there is exactly one JSON path (spec.functionref.alias) and one Go path (spec.Alias, promoted) for the concept, never two competing ones. The timer publisher (a later RFC-0025 task) reads it the same way timer.go:80 already reads the promoted spec.Name today.",
+	"method":                  "HTTP Method for trigger, ex : GET, POST, PUT, DELETE, HEAD (default: \"POST\")",
+	"subpath":                 "Subpath to trigger a specific route if function internally supports routing, (default: \"/\")",
+	"timeZone":                "TimeZone is the IANA time zone name (e.g. \"Europe/Berlin\") the Cron schedule is evaluated in. Empty means the timer process's local zone (UTC in the default deployment). A CRON_TZ= prefix on Cron itself still takes precedence, as with the robfig/cron parser it feeds. The name is checked against the tz database by the timer, which surfaces an unknown zone as Scheduled=False / InvalidTimeZone.",
+	"startingDeadlineSeconds": "StartingDeadlineSeconds is the catch-up window for a firing missed while the timer was not running (a restart, a leader handover). On (re)registration the most recent missed slot is fired once if it is no older than this many seconds; older slots are skipped. nil (the default) keeps the historic behavior: missed firings are lost.",
+	"concurrencyPolicy":       "ConcurrencyPolicy decides what happens when a slot comes due while the previous invocation of this trigger is still running, mirroring the batch/v1 CronJob field: Allow runs them side by side, Forbid skips the new slot, Replace cancels the running invocation and starts the new one. Under the timer's durable delivery a running invocation cannot be cut short, so Replace cancels it only if it has not started yet, and otherwise runs the new slot after it.",
+	"body":                    "Body is the JSON request body sent on every firing, a Go text/template executed per firing with .TriggerName, .Namespace, .ScheduledTime (the cron slot) and .FireTime (when it actually fired; later on a catch-up), e.g. {\"window\":\"hourly\",\"slot\":\"{{ .ScheduledTime }}\"}. Times print as RFC 3339 and keep time.Time's methods ({{ .ScheduledTime.Unix }}). It is sent with Content-Type application/json unless Headers sets one. Empty (the default) sends no body.",
+	"headers":                 "Headers are extra request headers sent on every firing. Values are templates executed like Body. X-Fission-Timer-Name is always set by the timer and overrides an entry of the same name.",
 }
 
 func (TimeTriggerSpec) SwaggerDoc() map[string]string {
@@ -771,7 +933,9 @@ func (TimeTriggerSpec) SwaggerDoc() map[string]string {
 }
 
 var map_TimeTriggerStatus = map[string]string{
-	"": "TimeTriggerStatus describes the observed state of a TimeTrigger.",
+	"":                 "TimeTriggerStatus describes the observed state of a TimeTrigger.",
+	"lastScheduleTime": "LastScheduleTime is the schedule slot the trigger last fired for (the slot time, not the wall-clock moment the timer got to it). It is also the starting point for StartingDeadlineSeconds catch-up after a timer restart.",
+	"nextScheduleTime": "NextScheduleTime is the next slot the timer has scheduled. A NextScheduleTime in the past means the timer is not running the trigger (or fell behind), so a missed schedule is visible without reading timer logs.",
 }
 
 func (TimeTriggerStatus) SwaggerDoc() map[string]string {
@@ -800,6 +964,17 @@ func (TopicRef) SwaggerDoc() map[string]string {
 	return map_TopicRef
 }
 
+var map_TrafficMirror = map[string]string{
+	"":        "TrafficMirror copies a sampled share of a route's requests to a shadow target: a FunctionVersion, or whatever version a FunctionAlias resolves to, of the same function. Copies are sent after the router has admitted the request and are fire-and-forget: the shadow's response is discarded, and its latency, status code and a comparison with the primary response are recorded as router metrics labelled by version. Requests answered from the response cache, async and streaming invocations, and requests with bodies over 1MiB are not mirrored. The shadow runs with real side effects; mirror only to versions that are safe to invoke twice.",
+	"version": "Version names the FunctionVersion that receives the copies.",
+	"alias":   "Alias names the FunctionAlias whose resolved version receives the copies. For a weighted alias that is its primary version.",
+	"percent": "Percent of requests to mirror, 1-100.",
+}
+
+func (TrafficMirror) SwaggerDoc() map[string]string {
+	return map_TrafficMirror
+}
+
 var map_VersioningConfig = map[string]string{
 	"":       "VersioningConfig opts a Function into RFC-0025 immutable version snapshots and named aliases.",
 	"mode":   "Mode auto (default) mints a version on every runtime-affecting update once the referenced package build succeeds; manual mints only on explicit `fission fn publish`.",
@@ -810,6 +985,28 @@ func (VersioningConfig) SwaggerDoc() map[string]string {
 	return map_VersioningConfig
 }
 
+var map_WebhookDestination = map[string]string{
+	"":              "WebhookDestination is an external HTTPS endpoint for an async invocation result. Every delivery of one result carries the same X-Fission-Invocation-Id (the source invocation's id), so a receiver can drop the duplicates that retries produce.",
+	"url":           "URL is the https:// endpoint the result envelope is POSTed to.",
+	"headers":       "Headers are set on every delivery. They cannot replace Content-Type, Content-Length, Host, or the X-Fission-* and Ce-* headers Fission sets itself.",
+	"signingSecret": "SigningSecret, when set, signs every delivery with HMAC-SHA256 under a key held in a Secret in the function's namespace, sent as X-Fission-Signature: t=<unix seconds>,v1=<hex HMAC of \"<t>.<body>\">. The router rereads the Secret at most every 30 seconds, so a rotated key takes effect without touching the function.",
+	"retry":         "Retry is the webhook's own delivery retry policy, independent of the function's; nil fields take the platform defaults.",
+}
+
+func (WebhookDestination) SwaggerDoc() map[string]string {
+	return map_WebhookDestination
+}
+
+var map_WebhookSigningSecret = map[string]string{
+	"":     "WebhookSigningSecret names the Secret key holding a webhook's signing key.",
+	"name": "Name of the Secret, in the function's namespace.",
+	"key":  "Key of the signing key within the Secret.",
+}
+
+func (WebhookSigningSecret) SwaggerDoc() map[string]string {
+	return map_WebhookSigningSecret
+}
+
 var map_Workflow = map[string]string{
 	"": "Workflow declares a durable state machine whose task states are Fission functions (RFC-0022). The engine executes WorkflowRuns against a snapshot of this spec embedded in the run's event stream; editing a Workflow never changes in-flight runs.",
 }
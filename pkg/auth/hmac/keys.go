@@ -44,6 +44,10 @@ const (
 	// ServiceStatestore gates the embedded statestore's capability API
 	// (pkg/statestore/httpapi) served by the --statestorePort head (RFC-0021).
 	ServiceStatestore Service = "statestore"
+	// ServiceStatestorePeer gates the Raft transport between the replicas of
+	// the replicated embedded statestore (pkg/statestore/raft). It is apart
+	// from ServiceStatestore so a capability-API client cannot speak Raft.
+	ServiceStatestorePeer Service = "statestore-peer"
 	// ServiceWorkflow gates the workflow head's read-only history endpoint
 	// (RFC-0022): run I/O may contain user data, so reads are signed like
 	// every other internal channel.
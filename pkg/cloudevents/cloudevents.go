@@ -0,0 +1,371 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cloudevents implements the parts of CloudEvents 1.0 and its HTTP
+// protocol binding that Fission uses: reading an event sent in binary or
+// structured mode, validating its context attributes, and writing events in
+// either mode. The router uses it for HTTPTriggers that opt in, and message
+// queue triggers and async destinations use it to wrap what they send. It
+// depends only on the standard library.
+package cloudevents
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// SpecVersion is the only CloudEvents version accepted and produced.
+	SpecVersion = "1.0"
+
+	// ContentTypeStructured marks a structured-mode event; ContentTypeBatch
+	// a batch of them, which is not supported.
+	ContentTypeStructured = "application/cloudevents+json"
+	ContentTypeBatch      = "application/cloudevents-batch+json"
+
+	// HeaderPrefix starts every binary-mode attribute header.
+	HeaderPrefix      = "Ce-"
+	HeaderID          = "Ce-Id"
+	HeaderSource      = "Ce-Source"
+	HeaderType        = "Ce-Type"
+	HeaderSpecVersion = "Ce-Specversion"
+)
+
+var (
+	// ErrNotCloudEvent is returned by FromHTTP for a request that is in
+	// neither binary nor structured mode.
+	ErrNotCloudEvent = errors.New("not a CloudEvent")
+	// ErrBatch is returned by FromHTTP for a batched-mode request.
+	ErrBatch = errors.New("batched CloudEvents are not supported")
+)
+
+// Event is one CloudEvent: its context attributes and its data. Extension
+// attributes are carried as strings, which is how the HTTP binding transports
+// them in binary mode.
+type Event struct {
+	ID              string
+	Source          string
+	Type            string
+	SpecVersion     string
+	Subject         string
+	Time            string
+	DataContentType string
+	DataSchema      string
+	Extensions      map[string]string
+	Data            []byte
+}
+
+// New returns a 1.0 event with the required attributes set and the time set
+// to now.
+func New(id, source, eventType string, data []byte, dataContentType string) Event {
+	return Event{
+		ID:              id,
+		Source:          source,
+		Type:            eventType,
+		SpecVersion:     SpecVersion,
+		Time:            time.Now().UTC().Format(time.RFC3339Nano),
+		DataContentType: dataContentType,
+		Data:            data,
+	}
+}
+
+// NewID derives an event id from parts, so the same source occurrence (a
+// message offset, an invocation settling) always gets the same id and a
+// consumer can deduplicate redeliveries.
+func NewID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Validate checks the attributes CloudEvents 1.0 requires and the format of
+// the optional ones Fission reads.
+func (e Event) Validate() error {
+	var errs []error
+	if e.SpecVersion != SpecVersion {
+		errs = append(errs, fmt.Errorf("unsupported specversion %q, want %q", e.SpecVersion, SpecVersion))
+	}
+	if e.ID == "" {
+		errs = append(errs, errors.New("id is required"))
+	}
+	if e.Source == "" {
+		errs = append(errs, errors.New("source is required"))
+	}
+	if e.Type == "" {
+		errs = append(errs, errors.New("type is required"))
+	}
+	if e.Time != "" {
+		if _, err := time.Parse(time.RFC3339Nano, e.Time); err != nil {
+			errs = append(errs, fmt.Errorf("time %q is not an RFC 3339 timestamp", e.Time))
+		}
+	}
+	for name := range e.Extensions {
+		if !validAttributeName(name) {
+			errs = append(errs, fmt.Errorf("extension attribute name %q must be 1-20 lowercase letters or digits", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validAttributeName reports whether name is a legal attribute name: lowercase
+// ASCII letters and digits, at most 20 characters.
+func validAttributeName(name string) bool {
+	if name == "" || len(name) > 20 {
+		return false
+	}
+	for _, c := range []byte(name) {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Mode reports how h carries an event: "binary", "structured", "batch", or ""
+// when it does not carry one.
+func Mode(h http.Header) string {
+	if h.Get(HeaderSpecVersion) != "" {
+		return "binary"
+	}
+	mt, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch mt {
+	case ContentTypeStructured:
+		return "structured"
+	case ContentTypeBatch:
+		return "batch"
+	}
+	return ""
+}
+
+// FromHTTP reads the event carried by an HTTP message's headers and body, in
+// binary or structured mode. It returns ErrNotCloudEvent or ErrBatch when
+// there is no single event to read; the event is not validated.
+func FromHTTP(h http.Header, body []byte) (Event, error) {
+	switch Mode(h) {
+	case "binary":
+		return fromBinary(h, body), nil
+	case "structured":
+		return Unmarshal(body)
+	case "batch":
+		return Event{}, ErrBatch
+	}
+	return Event{}, ErrNotCloudEvent
+}
+
+func fromBinary(h http.Header, body []byte) Event {
+	e := Event{Data: body, DataContentType: h.Get("Content-Type")}
+	for name, vals := range h {
+		canon := http.CanonicalHeaderKey(name)
+		if !strings.HasPrefix(canon, HeaderPrefix) || len(vals) == 0 {
+			continue
+		}
+		attr := strings.ToLower(strings.TrimPrefix(canon, HeaderPrefix))
+		e.set(attr, decodeHeaderValue(vals[0]))
+	}
+	return e
+}
+
+// set assigns one attribute by its CloudEvents name.
+func (e *Event) set(attr, value string) {
+	switch attr {
+	case "id":
+		e.ID = value
+	case "source":
+		e.Source = value
+	case "type":
+		e.Type = value
+	case "specversion":
+		e.SpecVersion = value
+	case "subject":
+		e.Subject = value
+	case "time":
+		e.Time = value
+	case "datacontenttype":
+		e.DataContentType = value
+	case "dataschema":
+		e.DataSchema = value
+	default:
+		if e.Extensions == nil {
+			e.Extensions = map[string]string{}
+		}
+		e.Extensions[attr] = value
+	}
+}
+
+// attributes lists the event's set attributes by name, extensions included,
+// in a stable order.
+func (e Event) attributes() [][2]string {
+	attrs := [][2]string{
+		{"specversion", e.SpecVersion},
+		{"id", e.ID},
+		{"source", e.Source},
+		{"type", e.Type},
+	}
+	for _, a := range [][2]string{
+		{"subject", e.Subject},
+		{"time", e.Time},
+		{"dataschema", e.DataSchema},
+	} {
+		if a[1] != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(e.Extensions)) {
+		attrs = append(attrs, [2]string{name, e.Extensions[name]})
+	}
+	return attrs
+}
+
+// WriteBinary writes the event's attributes to h as binary-mode headers,
+// replacing any already there, and sets Content-Type to the data's content
+// type. The body to send is e.Data.
+func (e Event) WriteBinary(h http.Header) {
+	for name := range h {
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), HeaderPrefix) {
+			h.Del(name)
+		}
+	}
+	for _, a := range e.attributes() {
+		h.Set(HeaderPrefix+a[0], encodeHeaderValue(a[1]))
+	}
+	if e.DataContentType != "" {
+		h.Set("Content-Type", e.DataContentType)
+	} else {
+		h.Del("Content-Type")
+	}
+}
+
+// BinaryHeaders returns the binary-mode headers of WriteBinary as a flat map.
+func (e Event) BinaryHeaders() map[string]string {
+	h := http.Header{}
+	e.WriteBinary(h)
+	out := make(map[string]string, len(h))
+	for name := range h {
+		out[name] = h.Get(name)
+	}
+	return out
+}
+
+// Marshal encodes the event in structured mode. JSON data is embedded as
+// "data"; anything else goes base64-encoded in "data_base64".
+func (e Event) Marshal() ([]byte, error) {
+	m := make(map[string]any, 8)
+	for _, a := range e.attributes() {
+		m[a[0]] = a[1]
+	}
+	if e.DataContentType != "" {
+		m["datacontenttype"] = e.DataContentType
+	}
+	if len(e.Data) > 0 {
+		if isJSON(e.DataContentType) && json.Valid(e.Data) {
+			m["data"] = json.RawMessage(e.Data)
+		} else {
+			m["data_base64"] = base64.StdEncoding.EncodeToString(e.Data)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// Unmarshal decodes a structured-mode event. Attribute values other than
+// strings are kept in their JSON form; "data" is kept as raw JSON (a JSON
+// string is unquoted when the data is not itself JSON) and "data_base64" is
+// decoded.
+func Unmarshal(body []byte) (Event, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return Event{}, fmt.Errorf("decoding structured CloudEvent: %w", err)
+	}
+	var e Event
+	for attr, v := range raw {
+		switch attr {
+		case "data", "data_base64":
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(bytes.TrimSpace(v))
+		}
+		e.set(attr, s)
+	}
+	if d, ok := raw["data_base64"]; ok {
+		var s string
+		if err := json.Unmarshal(d, &s); err != nil {
+			return Event{}, errors.New("data_base64 must be a string")
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return Event{}, fmt.Errorf("decoding data_base64: %w", err)
+		}
+		e.Data = data
+	} else if d, ok := raw["data"]; ok {
+		e.Data = d
+		if !isJSON(e.DataContentType) {
+			var s string
+			if json.Unmarshal(d, &s) == nil {
+				e.Data = []byte(s)
+			}
+		}
+	}
+	if e.DataContentType == "" && len(e.Data) > 0 {
+		e.DataContentType = "application/json"
+	}
+	return e, nil
+}
+
+// isJSON reports whether a data content type is JSON: empty (the structured
+// default), application/json, or any +json suffix.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || mt == "text/json" || strings.HasSuffix(mt, "+json")
+}
+
+// encodeHeaderValue percent-encodes what the HTTP binding requires: space,
+// double quote, percent and anything outside printable ASCII.
+func encodeHeaderValue(v string) string {
+	var b strings.Builder
+	for _, c := range []byte(v) {
+		if c <= ' ' || c >= 0x7f || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// decodeHeaderValue reverses encodeHeaderValue, leaving a malformed escape as
+// it is.
+func decodeHeaderValue(v string) string {
+	if !strings.Contains(v, "%") {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '%' && i+2 < len(v) {
+			if n, err := hex.DecodeString(v[i+1 : i+3]); err == nil {
+				b.WriteByte(n[0])
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
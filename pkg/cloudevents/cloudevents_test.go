@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cloudevents
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+	e := New("id-1", "/orders", "com.example.order.created", []byte(`{"n":1}`), "application/json")
+	e.Subject = "order 42"
+	e.Extensions = map[string]string{"traceparent": "00-abc", "tenant": "ü"}
+	require.NoError(t, e.Validate())
+
+	h := http.Header{}
+	h.Set("Ce-Stale", "from an earlier hop")
+	e.WriteBinary(h)
+	assert.Empty(t, h.Get("Ce-Stale"), "existing attributes are replaced")
+	assert.Equal(t, "order%2042", h.Get("Ce-Subject"))
+	assert.Equal(t, "%C3%BC", h.Get("Ce-Tenant"))
+	assert.Equal(t, "application/json", h.Get("Content-Type"))
+	assert.Equal(t, "binary", Mode(h))
+
+	got, err := FromHTTP(h, e.Data)
+	require.NoError(t, err)
+	assert.Equal(t, e, got)
+}
+
+func TestStructuredRoundTrip(t *testing.T) {
+	t.Parallel()
+	for name, e := range map[string]Event{
+		"json data":   New("id-1", "/src", "t", []byte(`{"a":[1,2]}`), "application/json"),
+		"binary data": New("id-2", "/src", "t", []byte{0xff, 0x00, 0x01}, "application/octet-stream"),
+		"no data":     New("id-3", "/src", "t", nil, ""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			body, err := e.Marshal()
+			require.NoError(t, err)
+			h := http.Header{"Content-Type": {ContentTypeStructured + "; charset=utf-8"}}
+			assert.Equal(t, "structured", Mode(h))
+			got, err := FromHTTP(h, body)
+			require.NoError(t, err)
+			assert.Equal(t, e, got)
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+	e, err := Unmarshal([]byte(`{"specversion":"1.0","id":"x","source":"/s","type":"t",
+		"datacontenttype":"text/plain","data":"hello","priority":3}`))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), e.Data, "a string is unquoted when the data is not JSON")
+	assert.Equal(t, map[string]string{"priority": "3"}, e.Extensions)
+
+	e, err = Unmarshal([]byte(`{"specversion":"1.0","id":"x","source":"/s","type":"t","data":"hello"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`"hello"`), e.Data, "JSON data is kept as JSON")
+	assert.Equal(t, "application/json", e.DataContentType)
+
+	_, err = Unmarshal([]byte(`{"data_base64":"!!"}`))
+	require.Error(t, err)
+	_, err = Unmarshal([]byte(`[]`))
+	require.Error(t, err)
+}
+
+func TestFromHTTP_NotAnEvent(t *testing.T) {
+	t.Parallel()
+	_, err := FromHTTP(http.Header{"Content-Type": {"application/json"}}, []byte(`{}`))
+	require.ErrorIs(t, err, ErrNotCloudEvent)
+	_, err = FromHTTP(http.Header{"Content-Type": {ContentTypeBatch}}, []byte(`[]`))
+	require.ErrorIs(t, err, ErrBatch)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	valid := New("id", "/s", "t", nil, "")
+	require.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(*Event){
+		"specversion":    func(e *Event) { e.SpecVersion = "0.3" },
+		"id":             func(e *Event) { e.ID = "" },
+		"source":         func(e *Event) { e.Source = "" },
+		"type":           func(e *Event) { e.Type = "" },
+		"time":           func(e *Event) { e.Time = "yesterday" },
+		"extension name": func(e *Event) { e.Extensions = map[string]string{"Bad-Name": "v"} },
+	} {
+		e := valid
+		mutate(&e)
+		assert.Error(t, e.Validate(), name)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, NewID("topic", "0", "17"), NewID("topic", "0", "17"))
+	assert.NotEqual(t, NewID("topic", "0", "17"), NewID("topic", "0", "18"))
+	// Parts are delimited, so shifting a boundary changes the id.
+	assert.NotEqual(t, NewID("ab", "c"), NewID("a", "bc"))
+	assert.Len(t, NewID("x"), 32)
+}
+
+func TestHeaderValueEncoding(t *testing.T) {
+	t.Parallel()
+	for _, v := range []string{"", "plain", "with space", `quo"te`, "100%", "naïve", "%"} {
+		assert.Equal(t, v, decodeHeaderValue(encodeHeaderValue(v)), v)
+	}
+	assert.Equal(t, "%zz", decodeHeaderValue("%zz"), "a malformed escape is kept")
+	assert.Equal(t, "50%", decodeHeaderValue("50%"))
+}
+
+func TestBinaryHeaders(t *testing.T) {
+	t.Parallel()
+	e := New("id", "/s", "t", []byte(`{}`), "application/json")
+	h := e.BinaryHeaders()
+	assert.Equal(t, "1.0", h[HeaderSpecVersion])
+	assert.Equal(t, "id", h[HeaderID])
+	assert.Equal(t, "application/json", h["Content-Type"])
+}
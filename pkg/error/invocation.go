@@ -24,16 +24,23 @@ const (
 // contract (they appear in the error body), so values must not change once
 // shipped; add new ones rather than renaming.
 const (
-	ReasonFunctionTimeout      = "function_timeout"
-	ReasonStreamIdle           = "stream_idle"
-	ReasonStreamMaxDuration    = "stream_max_duration"
-	ReasonClientDisconnect     = "client_disconnect"
-	ReasonSpecializationFailed = "specialization_failed"
-	ReasonCapacityExceeded     = "capacity_exceeded"
-	ReasonExecutorUnavailable  = "executor_unavailable"
-	ReasonConnectionRefused    = "connection_refused"
-	ReasonDialError            = "dial_error"
-	ReasonFunctionError        = "function_error"
+	ReasonFunctionTimeout        = "function_timeout"
+	ReasonStreamIdle             = "stream_idle"
+	ReasonStreamMaxDuration      = "stream_max_duration"
+	ReasonClientDisconnect       = "client_disconnect"
+	ReasonSpecializationFailed   = "specialization_failed"
+	ReasonCapacityExceeded       = "capacity_exceeded"
+	ReasonExecutorUnavailable    = "executor_unavailable"
+	ReasonConnectionRefused      = "connection_refused"
+	ReasonDialError              = "dial_error"
+	ReasonFunctionError          = "function_error"
+	ReasonInvalidCloudEvent      = "invalid_cloudevent"
+	ReasonRateLimited            = "rate_limited"
+	ReasonUnauthenticated        = "unauthenticated"
+	ReasonForbidden              = "forbidden"
+	ReasonCredentialsUnavailable = "credentials_unavailable"
+	ReasonCircuitOpen            = "circuit_open"
+	ReasonInvalidRequest         = "invalid_request"
 )
 
 // InvocationError attributes a failed function invocation to a Component and a
@@ -35,5 +35,22 @@ type (
 		AuthUriPath   string        `json:"authUriPath"`
 		JWTExpiryTime time.Duration `json:"jwtExpiryTime"`
 		JWTIssuer     string        `json:"jwtIssuer"`
+		// OIDC, when set, makes the router accept tokens issued by an
+		// external OpenID Connect provider alongside its own login tokens.
+		OIDC *OIDCFeatureConfig `json:"oidc,omitempty"`
+		// ForwardClaims lists the verified token claims passed to functions,
+		// each as an X-Fission-Claim-<Name> request header.
+		ForwardClaims []string `json:"forwardClaims,omitempty"`
+	}
+
+	OIDCFeatureConfig struct {
+		// IssuerURL must equal the tokens' "iss" claim. The signing keys are
+		// found through its /.well-known/openid-configuration document
+		// unless JWKSURL is set.
+		IssuerURL string `json:"issuerUrl"`
+		JWKSURL   string `json:"jwksUrl,omitempty"`
+		// Audiences accepted in the tokens' "aud" claim; a token must name
+		// at least one. Empty skips the audience check.
+		Audiences []string `json:"audiences,omitempty"`
 	}
 )
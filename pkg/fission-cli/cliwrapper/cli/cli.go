@@ -42,6 +42,9 @@ type (
 		// Duration returns time duration of given flag.
 		Duration(key string) time.Duration
 
+		// Args returns the positional arguments left after the flags.
+		Args() []string
+
 		// Stdout returns io.Writer for stdout.
 		Stdout() io.Writer
 
@@ -232,6 +232,10 @@ func (u Cli) Duration(key string) time.Duration {
 	return v
 }
 
+func (u Cli) Args() []string {
+	return u.args
+}
+
 func (u Cli) Stdout() io.Writer {
 	return u.c.OutOrStdout()
 }
@@ -29,6 +29,7 @@ type Cli struct {
 	int64s       map[string]int64
 	int64Slices  map[string][]int64
 	durations    map[string]time.Duration
+	args         *[]string
 }
 
 // TestFlagSet returns an empty flag set for unit test purpose.
@@ -42,6 +43,7 @@ func TestFlagSet() Cli {
 		int64s:       make(map[string]int64),
 		int64Slices:  make(map[string][]int64),
 		durations:    make(map[string]time.Duration),
+		args:         new([]string),
 	}
 }
 
@@ -58,9 +60,12 @@ func TestFlagSetWith(flags ...Flag) Cli {
 	return c
 }
 
-func String(key, v string) Flag               { return func(c Cli) { c.SetString(key, v) } }
-func Int(key string, v int) Flag              { return func(c Cli) { c.SetInt(key, v) } }
-func StringSlice(key string, v []string) Flag { return func(c Cli) { c.SetStringSlice(key, v) } }
+func String(key, v string) Flag                 { return func(c Cli) { c.SetString(key, v) } }
+func Int(key string, v int) Flag                { return func(c Cli) { c.SetInt(key, v) } }
+func StringSlice(key string, v []string) Flag   { return func(c Cli) { c.SetStringSlice(key, v) } }
+func Bool(key string, v bool) Flag              { return func(c Cli) { c.SetBool(key, v) } }
+func Duration(key string, v time.Duration) Flag { return func(c Cli) { c.SetDuration(key, v) } }
+func Args(v ...string) Flag                     { return func(c Cli) { c.SetArgs(v...) } }
 
 func (u Cli) Context() context.Context {
 	return context.TODO()
@@ -73,6 +78,7 @@ func (u Cli) SetInt(key string, v int)                { u.ints[key] = v }
 func (u Cli) SetIntSlice(key string, v []int)         { u.intSlices[key] = v }
 func (u Cli) SetInt64(key string, v int64)            { u.int64s[key] = v }
 func (u Cli) SetDuration(key string, v time.Duration) { u.durations[key] = v }
+func (u Cli) SetArgs(v ...string)                     { *u.args = v }
 
 func (u Cli) IsSet(key string) bool {
 	if _, ok := u.bools[key]; ok {
@@ -108,6 +114,7 @@ func (u Cli) IntSlice(key string) []int         { return u.intSlices[key] }
 func (u Cli) Int64(key string) int64            { return u.int64s[key] }
 func (u Cli) Int64Slice(key string) []int64     { return u.int64Slices[key] }
 func (u Cli) Duration(key string) time.Duration { return u.durations[key] }
+func (u Cli) Args() []string                    { return *u.args }
 
 func (u Cli) Stdout() io.Writer {
 	return os.Stdout
@@ -32,8 +32,11 @@ func Commands() *cobra.Command {
 			flag.FnStateMaxValueBytes, flag.FnStateTTL,
 			flag.FnStateStickySource, flag.FnStateStickyName,
 			flag.FnAsyncMaxAttempts, flag.FnAsyncMaxAge,
+			flag.FnAsyncMaxInFlight, flag.FnAsyncMaxDeliveriesPerSecond,
 			flag.FnAsyncOnSuccess, flag.FnAsyncOnFailure,
-			flag.FnAsyncOnSuccessTopic, flag.FnAsyncOnFailureTopic,
+			flag.FnAsyncOnSuccessTopic, flag.FnAsyncOnFailureTopic, flag.FnAsyncCloudEvents,
+			flag.FnAsyncOnSuccessWebhook, flag.FnAsyncOnFailureWebhook,
+			flag.RateLimitRequests, flag.RateLimitPeriod, flag.RateLimitBurst, flag.RateLimitKey,
 			flag.FnOnceOnly, flag.Labels, flag.Annotation, flag.FnRetainPods,
 			flag.FnProvisionedConcurrency,
 			flag.FnVersioning, flag.FnRetainVersions,
@@ -104,8 +107,11 @@ func Commands() *cobra.Command {
 			flag.FnStateMaxValueBytes, flag.FnStateTTL,
 			flag.FnStateStickySource, flag.FnStateStickyName,
 			flag.FnAsyncMaxAttempts, flag.FnAsyncMaxAge,
+			flag.FnAsyncMaxInFlight, flag.FnAsyncMaxDeliveriesPerSecond,
 			flag.FnAsyncOnSuccess, flag.FnAsyncOnFailure,
-			flag.FnAsyncOnSuccessTopic, flag.FnAsyncOnFailureTopic,
+			flag.FnAsyncOnSuccessTopic, flag.FnAsyncOnFailureTopic, flag.FnAsyncCloudEvents,
+			flag.FnAsyncOnSuccessWebhook, flag.FnAsyncOnFailureWebhook,
+			flag.RateLimitRequests, flag.RateLimitPeriod, flag.RateLimitBurst, flag.RateLimitKey,
 			flag.FnOnceOnly, flag.Labels, flag.Annotation, flag.FnRetainPods,
 			flag.FnProvisionedConcurrency,
 			flag.FnVersioning, flag.FnRetainVersions,
@@ -328,7 +334,7 @@ func Commands() *cobra.Command {
 	}
 	command.AddCommand(createCmd, getCmd, getmetaCmd, describeCmd, updateCmd, deleteCmd, listCmd, logsCmd, testCmd,
 		runLocalCmd, runContainerCmd, updateContainerCmd, listPodsCmd, waitCmd, toolsCmd, publishCmd, versionsCmd,
-		rollbackCmd, gcVersionsCmd, DLQCommands(), StateCommands())
+		rollbackCmd, gcVersionsCmd, DLQCommands(), InvocationCommands(), StateCommands())
 
 	return command
 }
@@ -238,13 +238,17 @@ func getToolConfig(input cli.Input, existing *fv1.ToolConfig) (*fv1.ToolConfig,
 // --async-* flags, merging onto existing (the function's current config, or nil on
 // create) so an `fn update` that sets only one field keeps the rest. It returns nil
 // when nothing is configured. An empty --async-on-success/--async-on-failure (or
-// their -topic variants) clears that destination. Field bounds and the destination
+// their -topic and -webhook variants) clears that destination; --async-cloudevents applies to
+// whichever destinations are configured. Field bounds and the destination
 // shape are validated server-side by the Function admission webhook, so the CLI
 // stays thin.
 func getInvocationConfig(input cli.Input, existing *fv1.InvocationConfig) (*fv1.InvocationConfig, error) {
 	set := input.IsSet(flagkey.FnAsyncMaxAttempts) || input.IsSet(flagkey.FnAsyncMaxAge) ||
 		input.IsSet(flagkey.FnAsyncOnSuccess) || input.IsSet(flagkey.FnAsyncOnFailure) ||
-		input.IsSet(flagkey.FnAsyncOnSuccessTopic) || input.IsSet(flagkey.FnAsyncOnFailureTopic)
+		input.IsSet(flagkey.FnAsyncOnSuccessTopic) || input.IsSet(flagkey.FnAsyncOnFailureTopic) ||
+		input.IsSet(flagkey.FnAsyncOnSuccessWebhook) || input.IsSet(flagkey.FnAsyncOnFailureWebhook) ||
+		input.IsSet(flagkey.FnAsyncCloudEvents) ||
+		input.IsSet(flagkey.FnAsyncMaxInFlight) || input.IsSet(flagkey.FnAsyncMaxDeliveriesPerSecond)
 	if !set {
 		return existing, nil
 	}
@@ -258,38 +262,69 @@ func getInvocationConfig(input cli.Input, existing *fv1.InvocationConfig) (*fv1.
 	if input.IsSet(flagkey.FnAsyncMaxAge) {
 		ic.MaxAge = &metav1.Duration{Duration: input.Duration(flagkey.FnAsyncMaxAge)}
 	}
+	if input.IsSet(flagkey.FnAsyncMaxInFlight) {
+		ic.MaxInFlight = new(int32(input.Int(flagkey.FnAsyncMaxInFlight)))
+	}
+	if input.IsSet(flagkey.FnAsyncMaxDeliveriesPerSecond) {
+		ic.MaxDeliveriesPerSecond = new(int32(input.Int(flagkey.FnAsyncMaxDeliveriesPerSecond)))
+	}
 	var err error
-	if ic.OnSuccess, err = destinationFromFlags(input, flagkey.FnAsyncOnSuccess, flagkey.FnAsyncOnSuccessTopic, ic.OnSuccess); err != nil {
+	if ic.OnSuccess, err = destinationFromFlags(input, flagkey.FnAsyncOnSuccess, flagkey.FnAsyncOnSuccessTopic, flagkey.FnAsyncOnSuccessWebhook, ic.OnSuccess); err != nil {
 		return nil, err
 	}
-	if ic.OnFailure, err = destinationFromFlags(input, flagkey.FnAsyncOnFailure, flagkey.FnAsyncOnFailureTopic, ic.OnFailure); err != nil {
+	if ic.OnFailure, err = destinationFromFlags(input, flagkey.FnAsyncOnFailure, flagkey.FnAsyncOnFailureTopic, flagkey.FnAsyncOnFailureWebhook, ic.OnFailure); err != nil {
 		return nil, err
 	}
+	if input.IsSet(flagkey.FnAsyncCloudEvents) {
+		for _, dest := range []*fv1.DestinationRef{ic.OnSuccess, ic.OnFailure} {
+			if dest != nil {
+				dest.CloudEvents = input.Bool(flagkey.FnAsyncCloudEvents)
+			}
+		}
+	}
 	return ic, nil
 }
 
-// destinationFromFlags resolves one destination condition from its flag pair —
-// a same-namespace function (fnKey) or a statestore topic (topicKey). A
-// DestinationRef holds exactly one kind, so setting both non-empty is an
-// error; setting either to "" clears the destination; setting neither keeps
-// current.
-func destinationFromFlags(input cli.Input, fnKey, topicKey string, current *fv1.DestinationRef) (*fv1.DestinationRef, error) {
-	if !input.IsSet(fnKey) && !input.IsSet(topicKey) {
+// destinationFromFlags resolves one destination condition from its flags — a
+// same-namespace function (fnKey), a statestore topic (topicKey), or a webhook
+// URL (webhookKey). A DestinationRef holds exactly one kind, so setting more
+// than one non-empty is an error; setting any to "" clears the destination;
+// setting none keeps current. A replaced destination keeps current's
+// CloudEvents setting, and a new URL for a current webhook keeps its headers,
+// signing secret, and retry policy.
+func destinationFromFlags(input cli.Input, fnKey, topicKey, webhookKey string, current *fv1.DestinationRef) (*fv1.DestinationRef, error) {
+	if !input.IsSet(fnKey) && !input.IsSet(topicKey) && !input.IsSet(webhookKey) {
 		return current, nil
 	}
-	fnName, topic := input.String(fnKey), input.String(topicKey)
-	if fnName != "" && topic != "" {
-		return nil, fmt.Errorf("--%s and --%s are mutually exclusive (a destination is a function OR a topic)", fnKey, topicKey)
+	fnName, topic, webhook := input.String(fnKey), input.String(topicKey), input.String(webhookKey)
+	kinds := 0
+	for _, v := range []string{fnName, topic, webhook} {
+		if v != "" {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, fmt.Errorf("--%s, --%s, and --%s are mutually exclusive (a destination is a function, a topic, OR a webhook)", fnKey, topicKey, webhookKey)
 	}
+	cloudEvents := current != nil && current.CloudEvents
 	switch {
 	case fnName != "":
 		return &fv1.DestinationRef{
-			Function: &fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: fnName},
+			Function:    &fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: fnName},
+			CloudEvents: cloudEvents,
 		}, nil
 	case topic != "":
 		return &fv1.DestinationRef{
-			Topic: &fv1.TopicRef{MessageQueueType: fv1.MessageQueueTypeStatestore, Topic: topic},
+			Topic:       &fv1.TopicRef{MessageQueueType: fv1.MessageQueueTypeStatestore, Topic: topic},
+			CloudEvents: cloudEvents,
 		}, nil
+	case webhook != "":
+		wh := &fv1.WebhookDestination{}
+		if current != nil && current.Webhook != nil {
+			wh = current.Webhook.DeepCopy()
+		}
+		wh.URL = webhook
+		return &fv1.DestinationRef{Webhook: wh, CloudEvents: cloudEvents}, nil
 	default:
 		return nil, nil // explicit empty clears the destination
 	}
@@ -564,6 +599,11 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 		return err
 	}
 
+	rateLimit, err := util.GetRateLimitConfig(input, nil)
+	if err != nil {
+		return err
+	}
+
 	opts.function = &fv1.Function{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fnName,
@@ -582,6 +622,7 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 			Tool:                   toolConfig,
 			State:                  getStateConfig(input, nil),
 			Invocation:             invocation,
+			RateLimit:              rateLimit,
 			Versioning:             versioningConfig,
 			ProvisionedConcurrency: provisionedConcurrency,
 			Concurrency:            fnConcurrency,
@@ -51,11 +51,15 @@ type dlqMessage struct {
 	Namespace string `json:"namespace"`
 	Function  string `json:"function"`
 	// Topic is set instead of Function for broker egress jobs (--queue mq-egress-<type>).
-	Topic      string    `json:"topic"`
-	Reason     string    `json:"reason"`
-	Attempts   int       `json:"attempts"`
-	EnqueuedAt time.Time `json:"enqueuedAt"`
-	DiedAt     time.Time `json:"diedAt"`
+	Topic string `json:"topic"`
+	// Webhook and Destination are set for webhook deliveries (--queue webhook),
+	// alongside the Function whose destination it is.
+	Webhook     string    `json:"webhook,omitempty"`
+	Destination string    `json:"destination,omitempty"`
+	Reason      string    `json:"reason"`
+	Attempts    int       `json:"attempts"`
+	EnqueuedAt  time.Time `json:"enqueuedAt"`
+	DiedAt      time.Time `json:"diedAt"`
 }
 
 type dlqListResp struct {
@@ -65,7 +69,8 @@ type dlqListResp struct {
 
 type dlqShowResp struct {
 	dlqMessage
-	Envelope json.RawMessage `json:"envelope"`
+	Envelope   json.RawMessage `json:"envelope"`
+	WebhookJob json.RawMessage `json:"webhookJob,omitempty"`
 }
 
 type dlqRedriveReq struct {
@@ -134,7 +139,10 @@ func (opts *dlqSubCommand) list(input cli.Input) error {
 	headers := []string{"ID", "NAMESPACE", "FUNCTION", "REASON", "ATTEMPTS", "DIED"}
 	row := func(m dlqMessage) []string {
 		target := m.Function
-		if target == "" && m.Topic != "" {
+		switch {
+		case m.Webhook != "":
+			target = m.Function + " " + m.Destination + " webhook:" + m.Webhook
+		case target == "" && m.Topic != "":
 			target = "topic:" + m.Topic
 		}
 		return []string{m.ID, m.Namespace, target, m.Reason, strconv.Itoa(m.Attempts), util.AgeOf(metav1.NewTime(m.DiedAt))}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package function
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	wrapper "github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/cobra"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/flag"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// invocationAPIGet is the router INTERNAL listener's async invocation status
+// endpoint (GET reads a record, DELETE cancels the invocation); like the DLQ
+// API it is HMAC-signed with FISSION_INTERNAL_AUTH_SECRET.
+const invocationAPIGet = "/v1/async/invocation"
+
+// invocationStatus mirrors the router's asyncinvoke.InvocationStatus.
+type invocationStatus struct {
+	ID                string     `json:"id"`
+	Namespace         string     `json:"namespace"`
+	Function          string     `json:"function"`
+	State             string     `json:"state"`
+	Attempts          int        `json:"attempts"`
+	StatusCode        int        `json:"statusCode,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	Reason            string     `json:"reason,omitempty"`
+	Response          []byte     `json:"response,omitempty"`
+	ResponseTruncated bool       `json:"responseTruncated,omitempty"`
+	NextAttemptAt     *time.Time `json:"nextAttemptAt,omitempty"`
+	EnqueuedAt        time.Time  `json:"enqueuedAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// InvocationCommands builds the `fission function invocation` sub-group.
+func InvocationCommands() *cobra.Command {
+	getCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "get <invocation-id>",
+		Short: "Show the status and result of an async invocation",
+		Long: "Show the status of an async invocation by the id returned with its 202 " +
+			"(the X-Fission-Invocation-Id header): queued, running, retrying, succeeded or failed, " +
+			"with the last attempt's status code and the start of its response body.",
+		Args: cobra.ExactArgs(1),
+	}, InvocationGet, flag.FlagSet{
+		Optional: []flag.Flag{flag.Output},
+	})
+
+	cancelCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "cancel <invocation-id>",
+		Short: "Cancel an async invocation before it is delivered",
+		Long: "Cancel a queued, scheduled (X-Fission-Invoke-At/-Delay) or retrying async invocation. " +
+			"It is acknowledged without being delivered; an invocation already being delivered " +
+			"or settled cannot be cancelled.",
+		Args: cobra.ExactArgs(1),
+	}, InvocationCancel, flag.FlagSet{
+		Optional: []flag.Flag{flag.Output},
+	})
+
+	command := &cobra.Command{
+		Use:   "invocation",
+		Short: "Inspect and cancel async invocations",
+	}
+	command.AddCommand(getCmd, cancelCmd)
+	return command
+}
+
+type invocationSubCommand struct {
+	cmd.CommandActioner
+}
+
+func InvocationGet(input cli.Input) error { return (&invocationSubCommand{}).get(input) }
+
+func InvocationCancel(input cli.Input) error { return (&invocationSubCommand{}).cancel(input) }
+
+func (opts *invocationSubCommand) get(input cli.Input) error {
+	return opts.run(input, http.MethodGet)
+}
+
+func (opts *invocationSubCommand) cancel(input cli.Input) error {
+	return opts.run(input, http.MethodDelete)
+}
+
+// run reads (GET) or cancels (DELETE) the invocation named by the sole
+// argument and prints its status record.
+func (opts *invocationSubCommand) run(input cli.Input, method string) error {
+	format, err := util.ParseOutputFormat(input.String(flagkey.Output))
+	if err != nil {
+		return err
+	}
+	args := input.Args()
+	if len(args) != 1 || args[0] == "" {
+		return errors.New("an invocation id is required")
+	}
+	_, namespace, err := opts.GetResourceNamespace(input)
+	if err != nil {
+		return fmt.Errorf("error getting invocation: %w", err)
+	}
+	st, err := opts.call(input, method, namespace, args[0])
+	if err != nil {
+		return err
+	}
+	if ok, err := util.PrintStructured(format, st); ok {
+		return err
+	}
+	printInvocationStatus(st)
+	return nil
+}
+
+func (opts *invocationSubCommand) call(input cli.Input, method, namespace, id string) (*invocationStatus, error) {
+	internalURL, err := util.GetRouterInternalURL(input.Context(), opts.Client())
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the Fission router internal listener: %w", err)
+	}
+	u := internalURL.Clone()
+	u.Path = invocationAPIGet
+	u.RawQuery = url.Values{"namespace": {namespace}, "id": {id}}.Encode()
+	req, err := http.NewRequestWithContext(input.Context(), method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport
+	if secret := os.Getenv("FISSION_INTERNAL_AUTH_SECRET"); secret != "" {
+		transport = hmacauth.NewServiceSigningTransport([]byte(secret), hmacauth.ServiceRouterInternal, transport, "/v1/async/")
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling the router invocation status API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotImplemented:
+		return nil, errors.New("async invocation is not enabled on this cluster")
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("no status for invocation %q in namespace %q: unknown id, or its record expired", id, namespace)
+	case resp.StatusCode == http.StatusConflict:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return nil, fmt.Errorf("cannot cancel invocation %q: %s", id, strings.TrimSpace(string(msg)))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("router invocation status API rejected the request (%s); set FISSION_INTERNAL_AUTH_SECRET when authentication is enabled", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return nil, fmt.Errorf("router invocation status API returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var st invocationStatus
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return nil, fmt.Errorf("decoding router invocation status: %w", err)
+	}
+	return &st, nil
+}
+
+// printInvocationStatus renders st as the describe-style field list.
+func printInvocationStatus(st *invocationStatus) {
+	w := util.NewTabWriter(os.Stdout)
+	field := func(k, v string) { fmt.Fprintf(w, "%s:\t%s\n", k, v) }
+	field("ID", st.ID)
+	field("Function", st.Namespace+"/"+st.Function)
+	field("State", st.State)
+	field("Attempts", strconv.Itoa(st.Attempts))
+	if st.StatusCode != 0 {
+		field("Status Code", strconv.Itoa(st.StatusCode))
+	}
+	if st.Error != "" {
+		field("Error", st.Error)
+	}
+	if st.Reason != "" {
+		field("Reason", st.Reason)
+	}
+	if st.NextAttemptAt != nil {
+		field("Next Attempt", st.NextAttemptAt.Format(time.RFC3339))
+	}
+	field("Enqueued", st.EnqueuedAt.Format(time.RFC3339))
+	field("Updated", st.UpdatedAt.Format(time.RFC3339))
+	_ = w.Flush()
+	if len(st.Response) > 0 {
+		fmt.Println("Response:")
+		fmt.Println(string(st.Response))
+		if st.ResponseTruncated {
+			fmt.Println("(truncated)")
+		}
+	}
+}
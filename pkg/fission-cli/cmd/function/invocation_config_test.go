@@ -86,6 +86,19 @@ func TestGetInvocationConfig(t *testing.T) {
 		assert.Nil(t, existing.Retry.MaxAttempts, "the original is not mutated")
 	})
 
+	t.Run("delivery caps from flags", func(t *testing.T) {
+		in := fakeInvInput{
+			set: map[string]bool{flagkey.FnAsyncMaxInFlight: true, flagkey.FnAsyncMaxDeliveriesPerSecond: true},
+			i:   map[string]int{flagkey.FnAsyncMaxInFlight: 4, flagkey.FnAsyncMaxDeliveriesPerSecond: 20},
+		}
+		ic, err := getInvocationConfig(in, nil)
+		require.NoError(t, err)
+		require.NotNil(t, ic.MaxInFlight)
+		assert.EqualValues(t, 4, *ic.MaxInFlight)
+		require.NotNil(t, ic.MaxDeliveriesPerSecond)
+		assert.EqualValues(t, 20, *ic.MaxDeliveriesPerSecond)
+	})
+
 	t.Run("empty destination clears it", func(t *testing.T) {
 		existing := &fv1.InvocationConfig{
 			OnFailure: &fv1.DestinationRef{Function: &fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "old"}},
@@ -150,6 +163,36 @@ func TestGetInvocationConfig(t *testing.T) {
 		require.ErrorContains(t, err, "mutually exclusive")
 	})
 
+	t.Run("webhook flag keeps an existing webhook's headers and signing secret", func(t *testing.T) {
+		existing := &fv1.InvocationConfig{
+			OnFailure: &fv1.DestinationRef{Webhook: &fv1.WebhookDestination{
+				URL:           "https://old.example.com",
+				Headers:       map[string]string{"X-Team": "payments"},
+				SigningSecret: &fv1.WebhookSigningSecret{Name: "hook", Key: "hmac"},
+			}},
+		}
+		in := fakeInvInput{
+			set: map[string]bool{flagkey.FnAsyncOnFailureWebhook: true},
+			s:   map[string]string{flagkey.FnAsyncOnFailureWebhook: "https://new.example.com"},
+		}
+		ic, err := getInvocationConfig(in, existing)
+		require.NoError(t, err)
+		require.NotNil(t, ic.OnFailure.Webhook)
+		assert.Equal(t, "https://new.example.com", ic.OnFailure.Webhook.URL)
+		assert.Equal(t, "payments", ic.OnFailure.Webhook.Headers["X-Team"])
+		assert.Equal(t, "hook", ic.OnFailure.Webhook.SigningSecret.Name)
+		assert.Equal(t, "https://old.example.com", existing.OnFailure.Webhook.URL, "the existing config is not mutated")
+	})
+
+	t.Run("topic and webhook flags for one condition are mutually exclusive", func(t *testing.T) {
+		in := fakeInvInput{
+			set: map[string]bool{flagkey.FnAsyncOnSuccessTopic: true, flagkey.FnAsyncOnSuccessWebhook: true},
+			s:   map[string]string{flagkey.FnAsyncOnSuccessTopic: "orders", flagkey.FnAsyncOnSuccessWebhook: "https://x.example.com"},
+		}
+		_, err := getInvocationConfig(in, nil)
+		require.ErrorContains(t, err, "mutually exclusive")
+	})
+
 	t.Run("different conditions may use different kinds", func(t *testing.T) {
 		in := fakeInvInput{
 			set: map[string]bool{flagkey.FnAsyncOnSuccess: true, flagkey.FnAsyncOnFailureTopic: true},
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package function
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/dummy"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+func TestInvocationCLIGet(t *testing.T) {
+	got := mockRouter(t, func(r *http.Request) (int, string) {
+		if r.URL.Query().Get("id") == "asyncinv/404" {
+			return http.StatusNotFound, "not found"
+		}
+		return http.StatusOK, `{"id":"asyncinv/1","namespace":"ns","function":"fn","state":"succeeded","attempts":2,"statusCode":200,"response":"b2s="}`
+	})
+	in := func(id string) dummy.Cli {
+		return dummy.TestFlagSetWith(dummy.String(flagkey.Namespace, "ns"), dummy.String(flagkey.Output, "json"), dummy.Args(id))
+	}
+
+	require.NoError(t, (&invocationSubCommand{}).get(in("asyncinv/1")))
+	require.Len(t, *got, 1)
+	assert.Equal(t, http.MethodGet, (*got)[0].Method)
+	assert.Equal(t, invocationAPIGet, (*got)[0].Path)
+	assert.Equal(t, "id=asyncinv%2F1&namespace=ns", (*got)[0].Query)
+
+	err := (&invocationSubCommand{}).get(in("asyncinv/404"))
+	assert.ErrorContains(t, err, "unknown id, or its record expired")
+
+	assert.ErrorContains(t, (&invocationSubCommand{}).get(dummy.TestFlagSet()), "invocation id is required")
+}
+
+func TestInvocationCLICancel(t *testing.T) {
+	got := mockRouter(t, func(r *http.Request) (int, string) {
+		if r.URL.Query().Get("id") == "asyncinv/2" {
+			return http.StatusConflict, "invocation is already succeeded and can no longer be cancelled"
+		}
+		return http.StatusOK, `{"id":"asyncinv/1","namespace":"ns","function":"fn","state":"cancelled"}`
+	})
+	in := func(id string) dummy.Cli {
+		return dummy.TestFlagSetWith(dummy.String(flagkey.Namespace, "ns"), dummy.Args(id))
+	}
+
+	require.NoError(t, (&invocationSubCommand{}).cancel(in("asyncinv/1")))
+	require.Len(t, *got, 1)
+	assert.Equal(t, http.MethodDelete, (*got)[0].Method)
+	assert.Equal(t, invocationAPIGet, (*got)[0].Path)
+
+	err := (&invocationSubCommand{}).cancel(in("asyncinv/2"))
+	assert.ErrorContains(t, err, "already succeeded")
+}
@@ -141,6 +141,13 @@ func (opts *UpdateSubCommand) complete(input cli.Input) error {
 		return err
 	}
 
+	// --ratelimit-* merge onto the existing limit; --ratelimit-requests 0
+	// removes it.
+	function.Spec.RateLimit, err = util.GetRateLimitConfig(input, function.Spec.RateLimit)
+	if err != nil {
+		return err
+	}
+
 	// --versioning toggles the versioning config; 'off' clears it.
 	// --retain-versions merges onto the existing (or newly-set) config and
 	// requires versioning to be enabled either way.
@@ -24,6 +24,7 @@ func Commands() *cobra.Command {
 		Required: []flag.Flag{flag.AliasName, flag.AliasFunction},
 		Optional: []flag.Flag{
 			flag.AliasVersion, flag.AliasPackageDigest, flag.AliasWeight, flag.AliasSecondaryVersion,
+			flag.MirrorVersion, flag.MirrorAlias, flag.MirrorPercent,
 			flag.AliasWait, flag.WaitTimeout,
 		},
 	})
@@ -47,6 +48,7 @@ func Commands() *cobra.Command {
 		Required: []flag.Flag{flag.AliasName},
 		Optional: []flag.Flag{
 			flag.AliasVersion, flag.AliasPackageDigest, flag.AliasWeight, flag.AliasSecondaryVersion, flag.AliasClearWeight,
+			flag.MirrorVersion, flag.MirrorAlias, flag.MirrorPercent,
 			flag.AliasWait, flag.WaitTimeout,
 		},
 	})
@@ -13,6 +13,7 @@ import (
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
 	"github.com/fission/fission/pkg/fission-cli/cmd"
 	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/fission-cli/util"
 )
 
 type CreateSubCommand struct {
@@ -61,6 +62,10 @@ func (opts *CreateSubCommand) complete(input cli.Input) (err error) {
 	if input.IsSet(flagkey.AliasSecondaryVersion) {
 		spec.SecondaryVersion = input.String(flagkey.AliasSecondaryVersion)
 	}
+	spec.Mirror, err = util.GetMirrorConfig(input, nil, "FunctionAliasSpec.Mirror")
+	if err != nil {
+		return err
+	}
 
 	opts.alias = &fv1.FunctionAlias{
 		ObjectMeta: metav1.ObjectMeta{
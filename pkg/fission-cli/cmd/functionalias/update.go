@@ -44,7 +44,7 @@ func (opts *UpdateSubCommand) complete(input cli.Input) error {
 
 func (opts *UpdateSubCommand) run(input cli.Input) error {
 	aliases := opts.Client().FissionClientSet.CoreV1().FunctionAliases(opts.namespace)
-	updated, err := util.UpdateOnConflict(input.Context(), aliases, opts.name, mutateAliasSpec(input))
+	updated, err := util.TryUpdateOnConflict(input.Context(), aliases, opts.name, mutateAliasSpec(input))
 	if err != nil {
 		return fmt.Errorf("error updating function alias: %w", err)
 	}
@@ -67,16 +67,18 @@ func (opts *UpdateSubCommand) run(input cli.Input) error {
 }
 
 // mutateAliasSpec builds the IsSet-gated mutation applied to the freshly
-// fetched FunctionAlias by util.UpdateOnConflict: only flags the user
+// fetched FunctionAlias by util.TryUpdateOnConflict: only flags the user
 // actually passed change the resource, so an update that never mentions a
 // field leaves it intact. --version and --package-digest are mutually
 // exclusive pin styles (webhook-enforced XOR) — setting one clears the other
 // so a lone `alias update --version v2` moves a digest-pinned alias back to
 // name-pinning instead of tripping the XOR rule. --clear-weight is applied
 // last so it wins over a --weight/--secondary-version passed in the same
-// call, matching its "drop the split" intent.
-func mutateAliasSpec(input cli.Input) func(*fv1.FunctionAlias) {
-	return func(cur *fv1.FunctionAlias) {
+// call, matching its "drop the split" intent. The --mirror-* flags apply to
+// the alias's current mirror (see util.GetMirrorConfig), so an invalid
+// combination is only caught here and fails the update.
+func mutateAliasSpec(input cli.Input) func(*fv1.FunctionAlias) error {
+	return func(cur *fv1.FunctionAlias) error {
 		if input.IsSet(flagkey.AliasVersion) {
 			cur.Spec.Version = input.String(flagkey.AliasVersion)
 			cur.Spec.PackageDigest = ""
@@ -96,5 +98,11 @@ func mutateAliasSpec(input cli.Input) func(*fv1.FunctionAlias) {
 			cur.Spec.Weight = nil
 			cur.Spec.SecondaryVersion = ""
 		}
+		mirror, err := util.GetMirrorConfig(input, cur.Spec.Mirror, "FunctionAliasSpec.Mirror")
+		if err != nil {
+			return err
+		}
+		cur.Spec.Mirror = mirror
+		return nil
 	}
 }
@@ -195,3 +195,33 @@ func TestAliasUpdateWaitTimesOutWhenUnresolved(t *testing.T) {
 func fixedDigest() string {
 	return "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcd"
 }
+
+func TestAliasUpdateMirror(t *testing.T) {
+	fc := setAliasClient(newAlias())
+
+	in := dummy.TestFlagSet()
+	in.SetString(flagkey.AliasName, "prod")
+	in.SetString(flagkey.MirrorVersion, "hello-v2")
+	in.SetInt(flagkey.MirrorPercent, 10)
+
+	require.NoError(t, Update(in))
+
+	got, err := fc.CoreV1().FunctionAliases("default").Get(t.Context(), "prod", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &fv1.TrafficMirror{Version: "hello-v2", Percent: 10}, got.Spec.Mirror)
+}
+
+func TestAliasUpdateMirrorPercentWithoutTargetFails(t *testing.T) {
+	fc := setAliasClient(newAlias())
+
+	in := dummy.TestFlagSet()
+	in.SetString(flagkey.AliasName, "prod")
+	in.SetString(flagkey.AliasVersion, "hello-v2")
+	in.SetInt(flagkey.MirrorPercent, 10)
+
+	require.Error(t, Update(in), "an alias without a mirror has no target for --mirror-percent")
+
+	got, err := fc.CoreV1().FunctionAliases("default").Get(t.Context(), "prod", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "hello-v1", got.Spec.Version, "a rejected update writes nothing")
+}
@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httptrigger
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	apiv1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	wrapper "github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/cobra"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/flag"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+// apiKeyBytes is the entropy of a generated API key.
+const apiKeyBytes = 32
+
+// APIKeyCommands builds the `fission httptrigger apikey` sub-group. Keys live
+// only as SHA-256 digests in the Secret the trigger's apiKey block names; the
+// plaintext is printed once, by create, and never stored.
+func APIKeyCommands() *cobra.Command {
+	createCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "create",
+		Short: "Generate an API key for an HTTP trigger, enabling API key authentication on it if needed",
+	}, APIKeyCreate, flag.FlagSet{
+		Required: []flag.Flag{flag.HtName, flag.HtAPIKeyName},
+		Optional: []flag.Flag{flag.HtAPIKeySecret, flag.HtAPIKeyHeader, flag.HtAPIKeyQuery},
+	})
+	revokeCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke an HTTP trigger API key; the router stops accepting it within 30 seconds",
+	}, APIKeyRevoke, flag.FlagSet{
+		Required: []flag.Flag{flag.HtName, flag.HtAPIKeyName},
+	})
+	listCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the names of an HTTP trigger's API keys",
+	}, APIKeyList, flag.FlagSet{
+		Required: []flag.Flag{flag.HtName},
+	})
+
+	command := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage the API keys of an HTTP trigger",
+	}
+	command.AddCommand(createCmd, revokeCmd, listCmd)
+	return command
+}
+
+type apiKeySubCommand struct {
+	cmd.CommandActioner
+}
+
+func APIKeyCreate(input cli.Input) error { return (&apiKeySubCommand{}).create(input) }
+func APIKeyRevoke(input cli.Input) error { return (&apiKeySubCommand{}).revoke(input) }
+func APIKeyList(input cli.Input) error   { return (&apiKeySubCommand{}).list(input) }
+
+// trigger fetches the named trigger.
+func (opts *apiKeySubCommand) trigger(input cli.Input) (*fv1.HTTPTrigger, error) {
+	_, namespace, err := opts.GetResourceNamespace(input)
+	if err != nil {
+		return nil, fmt.Errorf("error in getting HTTP trigger: %w", err)
+	}
+	ht, err := opts.Client().FissionClientSet.CoreV1().HTTPTriggers(namespace).Get(input.Context(), input.String(flagkey.HtName), metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting HTTP trigger: %w", err)
+	}
+	return ht, nil
+}
+
+// secret fetches the trigger's API key Secret, nil when it does not exist yet.
+func (opts *apiKeySubCommand) secret(input cli.Input, ht *fv1.HTTPTrigger) (*apiv1.Secret, error) {
+	sec, err := opts.Client().KubernetesClient.CoreV1().Secrets(ht.Namespace).Get(input.Context(), ht.Spec.APIKey.SecretName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting API key secret %s: %w", ht.Spec.APIKey.SecretName, err)
+	}
+	return sec, nil
+}
+
+func (opts *apiKeySubCommand) create(input cli.Input) error {
+	name := input.String(flagkey.HtAPIKeyName)
+	if errs := validation.IsConfigMapKey(name); len(errs) > 0 {
+		return fmt.Errorf("invalid API key name %q: %v", name, errs)
+	}
+	ht, err := opts.trigger(input)
+	if err != nil {
+		return err
+	}
+	// A trigger without an apiKey block gets one, but only after the key is
+	// stored, so it is never left requiring a key that does not exist.
+	enable := ht.Spec.APIKey == nil
+	if enable {
+		secretName := input.String(flagkey.HtAPIKeySecret)
+		if secretName == "" {
+			secretName = ht.Name + "-apikeys"
+		}
+		ht.Spec.APIKey = &fv1.HTTPTriggerAPIKey{
+			SecretName: secretName,
+			Header:     input.String(flagkey.HtAPIKeyHeader),
+			QueryParam: input.String(flagkey.HtAPIKeyQuery),
+		}
+		if err := ht.Spec.APIKey.Validate(); err != nil {
+			return err
+		}
+	}
+
+	raw := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("error generating API key: %w", err)
+	}
+	key := base64.RawURLEncoding.EncodeToString(raw)
+	sum := sha256.Sum256([]byte(key))
+	digest := []byte(hex.EncodeToString(sum[:]))
+
+	secrets := opts.Client().KubernetesClient.CoreV1().Secrets(ht.Namespace)
+	sec, err := opts.secret(input, ht)
+	if err != nil {
+		return err
+	}
+	if sec == nil {
+		_, err = secrets.Create(input.Context(), &apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: ht.Spec.APIKey.SecretName, Namespace: ht.Namespace},
+			Data:       map[string][]byte{name: digest},
+		}, metav1.CreateOptions{})
+	} else {
+		if _, ok := sec.Data[name]; ok {
+			return fmt.Errorf("API key %q already exists for HTTP trigger %s; revoke it first", name, ht.Name)
+		}
+		if sec.Data == nil {
+			sec.Data = map[string][]byte{}
+		}
+		sec.Data[name] = digest
+		_, err = secrets.Update(input.Context(), sec, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error storing API key in secret %s: %w", ht.Spec.APIKey.SecretName, err)
+	}
+	if enable {
+		if _, err := opts.Client().FissionClientSet.CoreV1().HTTPTriggers(ht.Namespace).Update(input.Context(), ht, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error enabling API key authentication on HTTP trigger %s: %w", ht.Name, err)
+		}
+		fmt.Printf("HTTP trigger '%s' now requires an API key\n", ht.Name)
+	}
+
+	fmt.Printf("API key '%s' created for HTTP trigger '%s'. It is shown only once:\n%s\n", name, ht.Name, key)
+	return nil
+}
+
+func (opts *apiKeySubCommand) revoke(input cli.Input) error {
+	name := input.String(flagkey.HtAPIKeyName)
+	ht, err := opts.trigger(input)
+	if err != nil {
+		return err
+	}
+	if ht.Spec.APIKey == nil {
+		return fmt.Errorf("HTTP trigger %s does not use API keys", ht.Name)
+	}
+	sec, err := opts.secret(input, ht)
+	if err != nil {
+		return err
+	}
+	if sec == nil {
+		return fmt.Errorf("API key %q not found for HTTP trigger %s", name, ht.Name)
+	}
+	if _, ok := sec.Data[name]; !ok {
+		return fmt.Errorf("API key %q not found for HTTP trigger %s", name, ht.Name)
+	}
+	delete(sec.Data, name)
+	if _, err := opts.Client().KubernetesClient.CoreV1().Secrets(ht.Namespace).Update(input.Context(), sec, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error revoking API key %q: %w", name, err)
+	}
+	fmt.Printf("API key '%s' revoked for HTTP trigger '%s'\n", name, ht.Name)
+	return nil
+}
+
+func (opts *apiKeySubCommand) list(input cli.Input) error {
+	ht, err := opts.trigger(input)
+	if err != nil {
+		return err
+	}
+	if ht.Spec.APIKey == nil {
+		return fmt.Errorf("HTTP trigger %s does not use API keys", ht.Name)
+	}
+	sec, err := opts.secret(input, ht)
+	if err != nil {
+		return err
+	}
+	for _, name := range apiKeyNames(sec) {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// apiKeyNames returns the sorted key names held in sec.
+func apiKeyNames(sec *apiv1.Secret) []string {
+	if sec == nil {
+		return nil
+	}
+	names := make([]string, 0, len(sec.Data))
+	for name := range sec.Data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httptrigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/dummy"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	fissionfake "github.com/fission/fission/pkg/generated/clientset/versioned/fake"
+)
+
+// TestAPIKeyCreateRevoke: the first key enables API key authentication on
+// the trigger and creates its Secret; later keys join it; revoke drops one.
+//
+// Not run in parallel: cmd.SetClientset installs a package-level client.
+func TestAPIKeyCreateRevoke(t *testing.T) {
+	fc := fissionfake.NewSimpleClientset(existingRoute("r1")) //nolint:staticcheck
+	kc := k8sfake.NewClientset()
+	cmd.ResetClientsetForTest()
+	cmd.SetClientset(cmd.Client{FissionClientSet: fc, KubernetesClient: kc, Namespace: "default"})
+
+	in := func(keyName string) dummy.Cli {
+		return dummy.TestFlagSetWith(dummy.String(flagkey.HtName, "r1"), dummy.String(flagkey.HtAPIKeyName, keyName))
+	}
+
+	require.NoError(t, APIKeyCreate(in("partner-a")))
+	ht, err := fc.CoreV1().HTTPTriggers("default").Get(t.Context(), "r1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, ht.Spec.APIKey)
+	assert.Equal(t, "r1-apikeys", ht.Spec.APIKey.SecretName)
+
+	require.NoError(t, APIKeyCreate(in("partner-b")))
+	assert.ErrorContains(t, APIKeyCreate(in("partner-b")), "already exists")
+	assert.Error(t, APIKeyCreate(in("bad/name")))
+
+	sec, err := kc.CoreV1().Secrets("default").Get(t.Context(), "r1-apikeys", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"partner-a", "partner-b"}, apiKeyNames(sec))
+	assert.Len(t, sec.Data["partner-a"], 64, "only the hex SHA-256 digest is stored")
+
+	require.NoError(t, APIKeyRevoke(in("partner-a")))
+	assert.ErrorContains(t, APIKeyRevoke(in("partner-a")), "not found")
+	sec, err = kc.CoreV1().Secrets("default").Get(t.Context(), "r1-apikeys", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"partner-b"}, apiKeyNames(sec))
+}
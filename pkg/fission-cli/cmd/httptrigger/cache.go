@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httptrigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	wrapper "github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/cobra"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/flag"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// cacheAPIPurge is the router INTERNAL listener's response cache purge
+// endpoint; like the async APIs it is HMAC-signed with
+// FISSION_INTERNAL_AUTH_SECRET.
+const cacheAPIPurge = "/v1/cache/httptrigger"
+
+// CacheCommands builds the `fission httptrigger cache` sub-group.
+func CacheCommands() *cobra.Command {
+	purgeCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "purge",
+		Short: "Remove an HTTP trigger's cached responses",
+		Long: "Remove an HTTP trigger's cached responses from the statestore and from the router replica serving the request. " +
+			"Responses cached in the memory of other replicas expire with their TTL; use --cache-shared to make purges reach every replica.",
+	}, CachePurge, flag.FlagSet{
+		Required: []flag.Flag{flag.HtName},
+	})
+
+	command := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the response cache of an HTTP trigger",
+	}
+	command.AddCommand(purgeCmd)
+	return command
+}
+
+type cacheSubCommand struct {
+	cmd.CommandActioner
+}
+
+func CachePurge(input cli.Input) error { return (&cacheSubCommand{}).purge(input) }
+
+func (opts *cacheSubCommand) purge(input cli.Input) error {
+	_, namespace, err := opts.GetResourceNamespace(input)
+	if err != nil {
+		return fmt.Errorf("error purging HTTP trigger cache: %w", err)
+	}
+	name := input.String(flagkey.HtName)
+
+	internalURL, err := util.GetRouterInternalURL(input.Context(), opts.Client())
+	if err != nil {
+		return fmt.Errorf("connecting to the Fission router internal listener: %w", err)
+	}
+	u := internalURL.Clone()
+	u.Path = cacheAPIPurge
+	u.RawQuery = url.Values{"namespace": {namespace}, "name": {name}}.Encode()
+	req, err := http.NewRequestWithContext(input.Context(), http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport
+	if secret := os.Getenv("FISSION_INTERNAL_AUTH_SECRET"); secret != "" {
+		transport = hmacauth.NewServiceSigningTransport([]byte(secret), hmacauth.ServiceRouterInternal, transport, "/v1/cache/")
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return fmt.Errorf("calling the router cache API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("router cache API rejected the request (%s); set FISSION_INTERNAL_AUTH_SECRET when authentication is enabled", resp.Status)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("router cache API not found (%s); the router predates response caching", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return fmt.Errorf("router cache API returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var out struct {
+		Purged int `json:"purged"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("decoding router cache API response: %w", err)
+	}
+	fmt.Printf("purged %d cached responses of trigger '%s'\n", out.Purged, name)
+	return nil
+}
@@ -22,7 +22,11 @@ func Commands() *cobra.Command {
 			flag.HtRouteProvider, flag.HtRouteHost, flag.HtRoutePath, flag.HtRouteAnnotation,
 			flag.HtRouteTLS, flag.HtGateway,
 			flag.HtFnWeight, flag.HtFnAlias, flag.HtFnVersion, flag.HtHost, flag.SpecSave, flag.SpecDry,
-			flag.HtPrefix, flag.HtKeepPrefix, flag.HtInvocationMode},
+			flag.HtPrefix, flag.HtKeepPrefix, flag.HtInvocationMode,
+			flag.HtCloudEvents, flag.HtCloudEventsReq, flag.HtAuthScope, flag.HtAuthClaim,
+			flag.RateLimitRequests, flag.RateLimitPeriod, flag.RateLimitBurst, flag.RateLimitKey,
+			flag.HtCache, flag.HtCacheTTL, flag.HtCacheMaxTTL, flag.HtCacheVary, flag.HtCacheShared,
+			flag.MirrorVersion, flag.MirrorAlias, flag.MirrorPercent, flag.HtTransform},
 	})
 
 	getCmd := wrapper.SubCommand(&cobra.Command{
@@ -44,7 +48,11 @@ func Commands() *cobra.Command {
 			flag.HtMethod, flag.HtIngress, flag.HtIngressRule, flag.HtIngressAnnotation,
 			flag.HtIngressTLS, flag.HtRouteProvider, flag.HtRouteHost, flag.HtRoutePath,
 			flag.HtRouteAnnotation, flag.HtRouteTLS, flag.HtGateway,
-			flag.HtFnWeight, flag.HtFnAlias, flag.HtFnVersion, flag.HtHost, flag.HtPrefix, flag.HtKeepPrefix, flag.HtInvocationMode},
+			flag.HtFnWeight, flag.HtFnAlias, flag.HtFnVersion, flag.HtHost, flag.HtPrefix, flag.HtKeepPrefix, flag.HtInvocationMode,
+			flag.HtCloudEvents, flag.HtCloudEventsReq, flag.HtAuthScope, flag.HtAuthClaim,
+			flag.RateLimitRequests, flag.RateLimitPeriod, flag.RateLimitBurst, flag.RateLimitKey,
+			flag.HtCache, flag.HtCacheTTL, flag.HtCacheMaxTTL, flag.HtCacheVary, flag.HtCacheShared,
+			flag.MirrorVersion, flag.MirrorAlias, flag.MirrorPercent, flag.HtTransform},
 	})
 
 	deleteCmd := wrapper.SubCommand(&cobra.Command{
@@ -78,7 +86,7 @@ func Commands() *cobra.Command {
 		Optional: []flag.Flag{flag.WaitTimeout},
 	})
 
-	command.AddCommand(createCmd, getCmd, updateCmd, deleteCmd, listCmd, waitCmd)
+	command.AddCommand(createCmd, getCmd, updateCmd, deleteCmd, listCmd, waitCmd, APIKeyCommands(), CacheCommands())
 
 	return command
 }
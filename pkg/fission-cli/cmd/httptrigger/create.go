@@ -8,10 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"slices"
 	"strings"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
@@ -162,6 +165,27 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 		return fmt.Errorf("error parsing route configuration: %w", err)
 	}
 
+	rateLimit, err := util.GetRateLimitConfig(input, nil)
+	if err != nil {
+		return err
+	}
+	authorization, err := getAuthorization(input, nil)
+	if err != nil {
+		return err
+	}
+	cache, err := getCacheConfig(input, nil)
+	if err != nil {
+		return err
+	}
+	mirror, err := util.GetMirrorConfig(input, nil, "HTTPTriggerSpec.Mirror")
+	if err != nil {
+		return err
+	}
+	transform, err := getTransformConfig(input, nil)
+	if err != nil {
+		return err
+	}
+
 	opts.trigger = &fv1.HTTPTrigger{
 		ObjectMeta: m,
 		Spec: fv1.HTTPTriggerSpec{
@@ -175,6 +199,12 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 			Prefix:            &prefix,
 			KeepPrefix:        input.Bool(flagkey.HtKeepPrefix),
 			InvocationMode:    input.String(flagkey.HtInvocationMode),
+			CloudEvents:       getCloudEventsConfig(input, nil),
+			RateLimit:         rateLimit,
+			Authorization:     authorization,
+			Cache:             cache,
+			Mirror:            mirror,
+			Transform:         transform,
 		},
 	}
 
@@ -214,6 +244,127 @@ func warnIngressDeprecated(input cli.Input) {
 	}
 }
 
+// getCloudEventsConfig applies --cloudevents/--cloudevents-required to
+// current (nil on create). --cloudevents=false removes the config; setting
+// neither flag keeps current.
+func getCloudEventsConfig(input cli.Input, current *fv1.HTTPTriggerCloudEvents) *fv1.HTTPTriggerCloudEvents {
+	if !input.IsSet(flagkey.HtCloudEvents) && !input.IsSet(flagkey.HtCloudEventsReq) {
+		return current
+	}
+	if input.IsSet(flagkey.HtCloudEvents) && !input.Bool(flagkey.HtCloudEvents) {
+		return nil
+	}
+	cfg := &fv1.HTTPTriggerCloudEvents{}
+	if current != nil {
+		cfg = current.DeepCopy()
+	}
+	if input.IsSet(flagkey.HtCloudEventsReq) {
+		cfg.Required = input.Bool(flagkey.HtCloudEventsReq)
+	}
+	return cfg
+}
+
+// getAuthorization applies --auth-scope and --auth-claim to current. Each set
+// flag replaces its list; a trigger left with neither is unrestricted.
+func getAuthorization(input cli.Input, current *fv1.HTTPTriggerAuthorization) (*fv1.HTTPTriggerAuthorization, error) {
+	if !input.IsSet(flagkey.HtAuthScope) && !input.IsSet(flagkey.HtAuthClaim) {
+		return current, nil
+	}
+	az := &fv1.HTTPTriggerAuthorization{}
+	if current != nil {
+		az = current.DeepCopy()
+	}
+	if input.IsSet(flagkey.HtAuthScope) {
+		az.Scopes = nil
+		for _, scope := range input.StringSlice(flagkey.HtAuthScope) {
+			if scope != "" {
+				az.Scopes = append(az.Scopes, scope)
+			}
+		}
+	}
+	if input.IsSet(flagkey.HtAuthClaim) {
+		az.Claims = nil
+		for _, c := range input.StringSlice(flagkey.HtAuthClaim) {
+			if c == "" {
+				continue
+			}
+			name, value, hasValue := strings.Cut(c, "=")
+			if name == "" {
+				return nil, fmt.Errorf("invalid --%s %q: want NAME or NAME=VALUE", flagkey.HtAuthClaim, c)
+			}
+			i := slices.IndexFunc(az.Claims, func(r fv1.ClaimRequirement) bool { return r.Name == name })
+			if i < 0 {
+				az.Claims = append(az.Claims, fv1.ClaimRequirement{Name: name})
+				i = len(az.Claims) - 1
+			}
+			if hasValue {
+				az.Claims[i].Values = append(az.Claims[i].Values, value)
+			}
+		}
+	}
+	if len(az.Scopes) == 0 && len(az.Claims) == 0 {
+		return nil, nil
+	}
+	return az, az.Validate()
+}
+
+// getCacheConfig applies the --cache* flags to current (nil on create).
+// --cache=false removes the config; any other --cache* flag enables it;
+// setting none keeps current.
+func getCacheConfig(input cli.Input, current *fv1.HTTPTriggerCache) (*fv1.HTTPTriggerCache, error) {
+	if !input.IsSet(flagkey.HtCache) && !input.IsSet(flagkey.HtCacheTTL) && !input.IsSet(flagkey.HtCacheMaxTTL) &&
+		!input.IsSet(flagkey.HtCacheVary) && !input.IsSet(flagkey.HtCacheShared) {
+		return current, nil
+	}
+	if input.IsSet(flagkey.HtCache) && !input.Bool(flagkey.HtCache) {
+		return nil, nil
+	}
+	c := &fv1.HTTPTriggerCache{}
+	if current != nil {
+		c = current.DeepCopy()
+	}
+	if input.IsSet(flagkey.HtCacheTTL) {
+		c.DefaultTTL = &metav1.Duration{Duration: input.Duration(flagkey.HtCacheTTL)}
+	}
+	if input.IsSet(flagkey.HtCacheMaxTTL) {
+		c.MaxTTL = &metav1.Duration{Duration: input.Duration(flagkey.HtCacheMaxTTL)}
+	}
+	if input.IsSet(flagkey.HtCacheVary) {
+		c.VaryHeaders = nil
+		for _, h := range input.StringSlice(flagkey.HtCacheVary) {
+			if h != "" {
+				c.VaryHeaders = append(c.VaryHeaders, h)
+			}
+		}
+	}
+	if input.IsSet(flagkey.HtCacheShared) {
+		c.Shared = input.Bool(flagkey.HtCacheShared)
+	}
+	return c, c.Validate()
+}
+
+// getTransformConfig reads the transform block from the --transform file,
+// keeping current (nil on create) when the flag is not set. An empty path
+// removes the transform.
+func getTransformConfig(input cli.Input, current *fv1.HTTPTriggerTransform) (*fv1.HTTPTriggerTransform, error) {
+	if !input.IsSet(flagkey.HtTransform) {
+		return current, nil
+	}
+	path := input.String(flagkey.HtTransform)
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading transform file %q: %w", path, err)
+	}
+	t := &fv1.HTTPTriggerTransform{}
+	if err := yaml.UnmarshalStrict(raw, t); err != nil {
+		return nil, fmt.Errorf("parsing transform file %q: %w", path, err)
+	}
+	return t, t.Validate()
+}
+
 // GetMethod returns one of HTTP method
 func GetMethod(method string) (string, error) {
 	switch strings.ToUpper(method) {
@@ -5,10 +5,15 @@
 package httptrigger
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/dummy"
@@ -155,3 +160,147 @@ func TestCreateCompleteFnRefTagFlags(t *testing.T) {
 		assert.Empty(t, opts.trigger.Spec.FunctionReference.Version)
 	})
 }
+
+func TestGetAuthorization(t *testing.T) {
+	t.Parallel()
+	current := &fv1.HTTPTriggerAuthorization{
+		Scopes: []string{"fn:invoke"},
+		Claims: []fv1.ClaimRequirement{{Name: "tier", Values: []string{"gold"}}},
+	}
+	for name, tc := range map[string]struct {
+		flags   []dummy.Flag
+		want    *fv1.HTTPTriggerAuthorization
+		wantErr bool
+	}{
+		"no flags keeps the current rules": {want: current},
+		"scopes replace only scopes": {
+			flags: []dummy.Flag{dummy.StringSlice(flagkey.HtAuthScope, []string{"a", "b"})},
+			want:  &fv1.HTTPTriggerAuthorization{Scopes: []string{"a", "b"}, Claims: current.Claims},
+		},
+		"claims merge values by name": {
+			flags: []dummy.Flag{dummy.StringSlice(flagkey.HtAuthClaim, []string{"group=dev", "email_verified=true", "group=ops", "sub"})},
+			want: &fv1.HTTPTriggerAuthorization{Scopes: current.Scopes, Claims: []fv1.ClaimRequirement{
+				{Name: "group", Values: []string{"dev", "ops"}},
+				{Name: "email_verified", Values: []string{"true"}},
+				{Name: "sub"},
+			}},
+		},
+		"clearing both removes the rules": {
+			flags: []dummy.Flag{dummy.StringSlice(flagkey.HtAuthScope, []string{""}), dummy.StringSlice(flagkey.HtAuthClaim, []string{""})},
+		},
+		"unnamed claim": {
+			flags:   []dummy.Flag{dummy.StringSlice(flagkey.HtAuthClaim, []string{"=x"})},
+			wantErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := getAuthorization(dummy.TestFlagSetWith(tc.flags...), current)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetCacheConfig(t *testing.T) {
+	t.Parallel()
+	current := &fv1.HTTPTriggerCache{
+		DefaultTTL:  &metav1.Duration{Duration: time.Minute},
+		VaryHeaders: []string{"Accept-Language"},
+	}
+	for name, tc := range map[string]struct {
+		flags   []dummy.Flag
+		want    *fv1.HTTPTriggerCache
+		wantErr bool
+	}{
+		"no flags keeps the current config": {want: current},
+		"cache=false removes it": {
+			flags: []dummy.Flag{dummy.Bool(flagkey.HtCache, false)},
+		},
+		"vary replaces only the vary headers": {
+			flags: []dummy.Flag{dummy.StringSlice(flagkey.HtCacheVary, []string{"Accept", ""}), dummy.Bool(flagkey.HtCacheShared, true)},
+			want:  &fv1.HTTPTriggerCache{DefaultTTL: current.DefaultTTL, VaryHeaders: []string{"Accept"}, Shared: true},
+		},
+		"ttls": {
+			flags: []dummy.Flag{dummy.Duration(flagkey.HtCacheTTL, 30*time.Second), dummy.Duration(flagkey.HtCacheMaxTTL, time.Hour)},
+			want: &fv1.HTTPTriggerCache{
+				DefaultTTL:  &metav1.Duration{Duration: 30 * time.Second},
+				MaxTTL:      &metav1.Duration{Duration: time.Hour},
+				VaryHeaders: current.VaryHeaders,
+			},
+		},
+		"ttl above max ttl": {
+			flags:   []dummy.Flag{dummy.Duration(flagkey.HtCacheMaxTTL, time.Second)},
+			wantErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := getCacheConfig(dummy.TestFlagSetWith(tc.flags...), current)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetTransformConfig(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	valid := write("transform.yaml", `
+requestHeaders:
+  remove: [Cookie]
+mappings:
+- path: id
+  header: X-Item-Id
+bodySchema:
+  type: object
+  required: [name]
+`)
+	unknownField := write("unknown.yaml", "requestHeader:\n  remove: [Cookie]\n")
+	invalid := write("invalid.yaml", "mappings:\n- path: id\n")
+	current := &fv1.HTTPTriggerTransform{AllowedContentTypes: []string{"application/json"}}
+
+	for name, tc := range map[string]struct {
+		flags   []dummy.Flag
+		want    *fv1.HTTPTriggerTransform
+		wantErr bool
+	}{
+		"no flag keeps the current transform": {want: current},
+		"empty path removes it":               {flags: []dummy.Flag{dummy.String(flagkey.HtTransform, "")}},
+		"file replaces it": {
+			flags: []dummy.Flag{dummy.String(flagkey.HtTransform, valid)},
+			want: &fv1.HTTPTriggerTransform{
+				RequestHeaders: &fv1.HeaderTransform{Remove: []string{"Cookie"}},
+				Mappings:       []fv1.ParameterMapping{{Path: "id", Header: "X-Item-Id"}},
+				BodySchema:     &apiextensionsv1.JSON{Raw: []byte(`{"required":["name"],"type":"object"}`)},
+			},
+		},
+		"unknown field": {flags: []dummy.Flag{dummy.String(flagkey.HtTransform, unknownField)}, wantErr: true},
+		"invalid block": {flags: []dummy.Flag{dummy.String(flagkey.HtTransform, invalid)}, wantErr: true},
+		"missing file":  {flags: []dummy.Flag{dummy.String(flagkey.HtTransform, filepath.Join(dir, "nope.yaml"))}, wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := getTransformConfig(dummy.TestFlagSetWith(tc.flags...), current)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -79,6 +79,33 @@ func (opts *UpdateSubCommand) complete(input cli.Input) (err error) {
 		ht.Spec.InvocationMode = input.String(flagkey.HtInvocationMode)
 	}
 
+	ht.Spec.CloudEvents = getCloudEventsConfig(input, ht.Spec.CloudEvents)
+
+	ht.Spec.RateLimit, err = util.GetRateLimitConfig(input, ht.Spec.RateLimit)
+	if err != nil {
+		return err
+	}
+
+	ht.Spec.Authorization, err = getAuthorization(input, ht.Spec.Authorization)
+	if err != nil {
+		return err
+	}
+
+	ht.Spec.Cache, err = getCacheConfig(input, ht.Spec.Cache)
+	if err != nil {
+		return err
+	}
+
+	ht.Spec.Mirror, err = util.GetMirrorConfig(input, ht.Spec.Mirror, "HTTPTriggerSpec.Mirror")
+	if err != nil {
+		return err
+	}
+
+	ht.Spec.Transform, err = getTransformConfig(input, ht.Spec.Transform)
+	if err != nil {
+		return err
+	}
+
 	methods := input.StringSlice(flagkey.HtMethod)
 	if len(methods) > 0 {
 		for _, method := range methods {
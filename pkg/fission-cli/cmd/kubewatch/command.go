@@ -17,7 +17,8 @@ func Commands() *cobra.Command {
 		Short: "Create a kube watcher",
 	}, Create, flag.FlagSet{
 		Required: []flag.Flag{flag.KwFnName},
-		Optional: []flag.Flag{flag.KwName, flag.KwObjType, flag.SpecSave, flag.SpecDry},
+		Optional: []flag.Flag{flag.KwName, flag.KwObjType, flag.KwAPIVersion, flag.KwKind,
+			flag.KwEventType, flag.KwFieldSelector, flag.KwChangedPath, flag.SpecSave, flag.SpecDry},
 		// TODO: add label selector flag
 		// flag.KwLabelsFlag
 	})
@@ -54,7 +55,16 @@ func Commands() *cobra.Command {
 		Optional: []flag.Flag{flag.WaitTimeout},
 	})
 
-	command.AddCommand(createCmd, deleteCmd, listCmd, waitCmd)
+	replayCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "replay",
+		Short: "Re-deliver a kube watcher's recent events",
+		Long:  "Re-enqueue a kube watcher's retained events, starting at a sequence number, as new async invocations of its function. Requires durable delivery (async invocation enabled).",
+	}, Replay, flag.FlagSet{
+		Required: []flag.Flag{flag.KwName, flag.KwFromSeq},
+		Optional: []flag.Flag{flag.KwReplayLimit},
+	})
+
+	command.AddCommand(createCmd, deleteCmd, listCmd, waitCmd, replayCmd)
 
 	return command
 }
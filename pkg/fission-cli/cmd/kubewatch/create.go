@@ -15,6 +15,7 @@ import (
 	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
 	"github.com/fission/fission/pkg/fission-cli/console"
 	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/kubewatcher/eventfilter"
 	"github.com/fission/fission/pkg/utils/uuid"
 )
 
@@ -49,6 +50,27 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 	}
 
 	objType := input.String(flagkey.KwObjType)
+	apiVersion := input.String(flagkey.KwAPIVersion)
+	kind := input.String(flagkey.KwKind)
+	if kind != "" || apiVersion != "" {
+		if kind == "" || apiVersion == "" {
+			return fmt.Errorf("--%v and --%v must be set together", flagkey.KwAPIVersion, flagkey.KwKind)
+		}
+		if input.IsSet(flagkey.KwObjType) {
+			return fmt.Errorf("--%v cannot be used with --%v/--%v", flagkey.KwObjType, flagkey.KwAPIVersion, flagkey.KwKind)
+		}
+		// --type defaults to pod; a kind replaces it.
+		objType = ""
+	}
+
+	var eventTypes []string
+	for _, t := range input.StringSlice(flagkey.KwEventType) {
+		et, err := eventfilter.ParseEventType(t)
+		if err != nil {
+			return err
+		}
+		eventTypes = append(eventTypes, string(et))
+	}
 
 	if input.Bool(flagkey.SpecSave) {
 		if err := spec.CheckFunctionReferencesInSpecs(input, "KubernetesWatchTrigger", watchName, []string{fnName}, namespace); err != nil {
@@ -62,8 +84,13 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 			Namespace: namespace,
 		},
 		Spec: fv1.KubernetesWatchTriggerSpec{
-			Namespace: namespace,
-			Type:      objType,
+			Namespace:     namespace,
+			Type:          objType,
+			APIVersion:    apiVersion,
+			Kind:          kind,
+			FieldSelector: input.String(flagkey.KwFieldSelector),
+			EventTypes:    eventTypes,
+			ChangedPaths:  input.StringSlice(flagkey.KwChangedPath),
 			//LabelSelector: labels,
 			FunctionReference: fv1.FunctionReference{
 				Name: fnName,
@@ -6,6 +6,7 @@ package kubewatch
 
 import (
 	"fmt"
+	"strconv"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -55,12 +56,30 @@ func (opts *ListSubCommand) run(input cli.Input) (err error) {
 	headers := []string{"NAME", "NAMESPACE", "OBJTYPE", "LABELS", "FUNCTION_NAME", "READY"}
 	row := func(wa v1.KubernetesWatchTrigger) []string {
 		return []string{
-			wa.Name, wa.Spec.Namespace, wa.Spec.Type, fmt.Sprintf("%v", wa.Spec.LabelSelector), wa.Spec.FunctionReference.Name,
+			wa.Name, wa.Spec.Namespace, objType(wa.Spec), fmt.Sprintf("%v", wa.Spec.LabelSelector), wa.Spec.FunctionReference.Name,
 			util.ConditionStatus(wa.Status.Conditions, v1.KubernetesWatchTriggerConditionReady),
 		}
 	}
-	wideExtra := []string{"AGE"}
-	wideRow := func(wa v1.KubernetesWatchTrigger) []string { return []string{util.AgeOf(wa.CreationTimestamp)} }
+	wideExtra := []string{"DELIVERED", "SUPPRESSED", "AGE"}
+	wideRow := func(wa v1.KubernetesWatchTrigger) []string {
+		return []string{
+			strconv.FormatInt(wa.Status.DeliveredEvents, 10), strconv.FormatInt(wa.Status.SuppressedEvents, 10),
+			util.AgeOf(wa.CreationTimestamp),
+		}
+	}
 
 	return util.PrintObjects(format, ws.Items, headers, row, wideExtra, wideRow)
 }
+
+// objType is the OBJTYPE column: the Type shorthand, or Kind.group (just Kind
+// for the core group) for a trigger that selects its kind by apiVersion/kind.
+func objType(spec v1.KubernetesWatchTriggerSpec) string {
+	if spec.Kind == "" {
+		return spec.Type
+	}
+	gvk, err := spec.GroupVersionKind()
+	if err != nil || gvk.Group == "" {
+		return spec.Kind
+	}
+	return spec.Kind + "." + gvk.Group
+}
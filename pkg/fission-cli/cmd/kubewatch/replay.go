@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubewatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// replayAPIPath is the route the router registers (pkg/router/async_watch.go).
+const replayAPIPath = "/v1/async/watch/replay"
+
+type replayReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+	FromSeq   int64  `json:"fromSeq"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+type replayResp struct {
+	Head     int64 `json:"head"`
+	FirstSeq int64 `json:"firstSeq"`
+	LastSeq  int64 `json:"lastSeq"`
+	Count    int64 `json:"count"`
+}
+
+type ReplaySubCommand struct {
+	cmd.CommandActioner
+	name      string
+	namespace string
+}
+
+func Replay(input cli.Input) error {
+	return (&ReplaySubCommand{}).do(input)
+}
+
+func (opts *ReplaySubCommand) do(input cli.Input) error {
+	err := opts.complete(input)
+	if err != nil {
+		return err
+	}
+	return opts.run(input)
+}
+
+func (opts *ReplaySubCommand) complete(input cli.Input) (err error) {
+	opts.name = input.String(flagkey.KwName)
+	_, opts.namespace, err = opts.GetResourceNamespace(input)
+	if err != nil {
+		return fmt.Errorf("error replaying kubewatch events: %w", err)
+	}
+	if input.Int64(flagkey.KwFromSeq) < 1 {
+		return errors.New("--from-seq must be at least 1")
+	}
+	return nil
+}
+
+// run reads the trigger's UID, which names its event stream, and asks the
+// router INTERNAL listener to re-enqueue the events, HMAC-signed with the
+// ServiceRouterInternal key (FISSION_INTERNAL_AUTH_SECRET, empty →
+// pass-through) like `function dlq`.
+func (opts *ReplaySubCommand) run(input cli.Input) error {
+	w, err := opts.Client().FissionClientSet.CoreV1().KubernetesWatchTriggers(opts.namespace).Get(input.Context(), opts.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting kubewatch: %w", err)
+	}
+	body, err := json.Marshal(replayReq{
+		Namespace: w.Namespace,
+		Name:      w.Name,
+		UID:       string(w.UID),
+		FromSeq:   input.Int64(flagkey.KwFromSeq),
+		Limit:     input.Int(flagkey.KwReplayLimit),
+	})
+	if err != nil {
+		return err
+	}
+
+	internalURL, err := util.GetRouterInternalURL(input.Context(), opts.Client())
+	if err != nil {
+		return fmt.Errorf("connecting to the Fission router internal listener: %w", err)
+	}
+	u := internalURL.Clone()
+	u.Path = replayAPIPath
+	req, err := http.NewRequestWithContext(input.Context(), http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	transport := http.DefaultTransport
+	if secret := os.Getenv("FISSION_INTERNAL_AUTH_SECRET"); secret != "" {
+		transport = hmacauth.NewServiceSigningTransport([]byte(secret), hmacauth.ServiceRouterInternal, transport, "/v1/async/")
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return fmt.Errorf("calling the router watch replay API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotImplemented:
+		return errors.New("watch replay is not enabled on this cluster (requires async invocation)")
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("router watch replay API rejected the request (%s); set FISSION_INTERNAL_AUTH_SECRET when authentication is enabled", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return fmt.Errorf("router watch replay API returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var out replayResp
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("decoding router watch replay response: %w", err)
+	}
+
+	if out.Count == 0 {
+		fmt.Printf("no events to replay for trigger '%v' from sequence %d (head: %d)\n", opts.name, input.Int64(flagkey.KwFromSeq), out.Head)
+		return nil
+	}
+	fmt.Printf("replayed %d event(s) of trigger '%v': sequence %d to %d (head: %d)\n", out.Count, opts.name, out.FirstSeq, out.LastSeq, out.Head)
+	if from := input.Int64(flagkey.KwFromSeq); out.FirstSeq > from {
+		fmt.Printf("events before sequence %d are no longer retained\n", out.FirstSeq)
+	}
+	return nil
+}
@@ -21,7 +21,7 @@ func Commands() *cobra.Command {
 			flag.MqtErrorTopic, flag.MqtMaxRetries, flag.MqtMsgContentType,
 			flag.SpecSave, flag.SpecDry, flag.MqtPollingInterval,
 			flag.MqtCooldownPeriod, flag.MqtMinReplicaCount, flag.MqtMaxReplicaCount, flag.MqtSecret,
-			flag.MqtMetadata, flag.MqtKind},
+			flag.MqtMetadata, flag.MqtKind, flag.MqtCloudEvents, flag.MqtMessageGroup},
 	})
 
 	updateCmd := wrapper.SubCommand(&cobra.Command{
@@ -33,7 +33,7 @@ func Commands() *cobra.Command {
 		Optional: []flag.Flag{flag.MqtFnName, flag.MqtTopic, flag.MqtRespTopic, flag.MqtErrorTopic,
 			flag.MqtMaxRetries, flag.MqtMsgContentType, flag.MqtPollingInterval,
 			flag.MqtCooldownPeriod, flag.MqtMinReplicaCount, flag.MqtMaxReplicaCount, flag.MqtMetadata,
-			flag.MqtSecret, flag.MqtKind},
+			flag.MqtSecret, flag.MqtKind, flag.MqtCloudEvents, flag.MqtMessageGroup},
 	})
 
 	deleteCmd := wrapper.SubCommand(&cobra.Command{
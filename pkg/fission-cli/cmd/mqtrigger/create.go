@@ -144,19 +144,21 @@ func (opts *CreateSubCommand) complete(input cli.Input) error {
 				Type: fv1.FunctionReferenceTypeFunctionName,
 				Name: fnName,
 			},
-			MessageQueueType: mqType,
-			Topic:            topic,
-			ResponseTopic:    respTopic,
-			ErrorTopic:       errorTopic,
-			MaxRetries:       maxRetries,
-			ContentType:      contentType,
-			PollingInterval:  &pollingInterval,
-			CooldownPeriod:   &cooldownPeriod,
-			MinReplicaCount:  &minReplicaCount,
-			MaxReplicaCount:  &maxReplicaCount,
-			Metadata:         metadata,
-			Secret:           secret,
-			MqtKind:          mqtKind,
+			MessageQueueType:   mqType,
+			Topic:              topic,
+			ResponseTopic:      respTopic,
+			ErrorTopic:         errorTopic,
+			MaxRetries:         maxRetries,
+			ContentType:        contentType,
+			PollingInterval:    &pollingInterval,
+			CooldownPeriod:     &cooldownPeriod,
+			MinReplicaCount:    &minReplicaCount,
+			MaxReplicaCount:    &maxReplicaCount,
+			Metadata:           metadata,
+			Secret:             secret,
+			MqtKind:            mqtKind,
+			CloudEvents:        input.Bool(flagkey.MqtCloudEvents),
+			MessageGroupHeader: input.String(flagkey.MqtMessageGroup),
 		},
 	}
 
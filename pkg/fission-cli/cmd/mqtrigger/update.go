@@ -130,6 +130,16 @@ func (opts *UpdateSubCommand) complete(input cli.Input) (err error) {
 		updated = true
 	}
 
+	if input.IsSet(flagkey.MqtCloudEvents) {
+		mqt.Spec.CloudEvents = input.Bool(flagkey.MqtCloudEvents)
+		updated = true
+	}
+
+	if input.IsSet(flagkey.MqtMessageGroup) {
+		mqt.Spec.MessageGroupHeader = input.String(flagkey.MqtMessageGroup)
+		updated = true
+	}
+
 	if !updated {
 		return errors.New("nothing changed, see 'help' for more details")
 	}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package statestore implements `fission statestore export|import`: backup,
+// restore and cross-driver migration of the statestore as a versioned archive
+// (statestore.WriteArchive). Both talk to the cluster's embedded statestore
+// by default, or open a driver directly with --driver and --dsn, so an
+// archive exported from one backend loads into another.
+package statestore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	wrapper "github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/cobra"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/console"
+	"github.com/fission/fission/pkg/fission-cli/flag"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statestore/client"
+	storagesvcClient "github.com/fission/fission/pkg/storagesvc/client"
+	// The drivers --driver can name.
+	_ "github.com/fission/fission/pkg/statestore/postgres"
+	_ "github.com/fission/fission/pkg/statestore/redis"
+	_ "github.com/fission/fission/pkg/statestore/sqlite"
+)
+
+// Commands builds the `fission statestore` group.
+func Commands() *cobra.Command {
+	exportCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "export",
+		Short: "Export the statestore, or one namespace's state, to an archive file",
+		Long: "Write a consistent snapshot of KV keys (with versions and TTLs), EventLog streams and queues (with dead letters) to --file. " +
+			"With --namespace, only that namespace's keys and streams, including its workflow run histories, are exported; queues are shared and left out.",
+		Annotations: map[string]string{cmd.ClusterOptionalAnnotation: "true"},
+	}, Export, flag.FlagSet{
+		Required: []flag.Flag{flag.StatestoreFile},
+		Optional: []flag.Flag{flag.Namespace, flag.StatestoreDriver, flag.StatestoreDSN},
+	})
+	importCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "import",
+		Short: "Load an archive file into the statestore",
+		Long: "Load an archive written by `fission statestore export` into the statestore. Keys and streams replace those of the same name; " +
+			"messages are added under new ids, so import into an empty store to avoid duplicating them.",
+		Annotations: map[string]string{cmd.ClusterOptionalAnnotation: "true"},
+	}, Import, flag.FlagSet{
+		Required: []flag.Flag{flag.StatestoreFile},
+		Optional: []flag.Flag{flag.StatestoreDriver, flag.StatestoreDSN},
+	})
+
+	command := &cobra.Command{
+		Use:   "statestore",
+		Short: "Back up, restore and migrate the statestore",
+	}
+	command.AddCommand(exportCmd, importCmd)
+	return command
+}
+
+// open returns the store --driver and --dsn name, or else the cluster's
+// embedded statestore through the client driver.
+func open(input cli.Input, opts *cmd.CommandActioner) (statestore.Archiver, func(), error) {
+	c := statestore.Config{Driver: input.String(flagkey.StatestoreDriver), DSN: input.String(flagkey.StatestoreDSN)}
+	if c.Driver == "" {
+		if !opts.ClusterAvailable() {
+			return nil, nil, errors.New("no Kubernetes configuration found; use --driver and --dsn to open a statestore directly")
+		}
+		u, err := util.GetStatestoreURL(input.Context(), opts.Client())
+		if err != nil {
+			return nil, nil, fmt.Errorf("connecting to the embedded statestore: %w", err)
+		}
+		// No client timeout: an export streams for as long as the store is
+		// big, bounded by the command's context instead.
+		c := client.New(u.String(), &http.Client{Transport: statestoreTransport(input, opts)})
+		return c, func() { _ = c.Close() }, nil
+	}
+	caps, err := statestore.Open(input.Context(), c)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeFn := func() { _ = caps.Close() }
+	a, ok := caps.(statestore.Archiver)
+	if !ok {
+		closeFn()
+		return nil, nil, fmt.Errorf("statestore driver %q does not support export and import", c.Driver)
+	}
+	return a, closeFn, nil
+}
+
+// statestoreTransport signs requests with the ServiceStatestore key when the
+// cluster runs internal auth, read from the cluster's Secret rather than the
+// CLI user's environment (empty secret = pass-through, matching the
+// verifier). A FAILED secret read is not the same as "auth not configured":
+// say so, or the resulting 401 is undebuggable.
+func statestoreTransport(input cli.Input, opts *cmd.CommandActioner) http.RoundTripper {
+	master, err := storagesvcClient.HMACSecretFromCluster(input.Context(), opts.Client().KubernetesClient, util.GetFissionNamespace())
+	if err != nil {
+		console.Warn(fmt.Sprintf("could not read the internal auth secret (%v); sending unsigned requests — a 401 below means your kubeconfig lacks access to it, not that auth is off", err))
+		return http.DefaultTransport
+	}
+	if len(master) == 0 {
+		return http.DefaultTransport
+	}
+	return hmacauth.ServiceSigner(master, hmacauth.ServiceStatestore, http.DefaultTransport, time.Now)
+}
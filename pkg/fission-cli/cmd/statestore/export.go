@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestore
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/console"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/workflow"
+)
+
+type ExportSubCommand struct {
+	cmd.CommandActioner
+}
+
+func Export(input cli.Input) error {
+	return (&ExportSubCommand{}).do(input)
+}
+
+func (opts *ExportSubCommand) do(input cli.Input) error {
+	f, err := opts.filter(input)
+	if err != nil {
+		return err
+	}
+	a, closeFn, err := open(input, &opts.CommandActioner)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	path := input.String(flagkey.StatestoreFile)
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	st, err := statestore.WriteArchive(input.Context(), out, a, f)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("exporting the statestore: %w", err)
+	}
+	fmt.Printf("exported %d keys, %d streams with %d events, and %d messages to %s\n",
+		st.Keys, st.Streams, st.Events, st.Messages, path)
+	return nil
+}
+
+// filter returns the archive filter for --namespace. Workflow run histories
+// are keyed by run UID, so it names the streams of the namespace's current
+// WorkflowRuns.
+func (opts *ExportSubCommand) filter(input cli.Input) (statestore.ArchiveFilter, error) {
+	f := statestore.ArchiveFilter{Namespace: input.String(flagkey.Namespace)}
+	if f.Namespace == "" {
+		return f, nil
+	}
+	if !opts.ClusterAvailable() {
+		console.Warn("no Kubernetes configuration found; workflow run histories are not exported")
+		return f, nil
+	}
+	runs, err := opts.Client().FissionClientSet.CoreV1().WorkflowRuns(f.Namespace).List(input.Context(), metav1.ListOptions{})
+	if err != nil {
+		return f, fmt.Errorf("error listing workflow runs: %w", err)
+	}
+	for _, r := range runs.Items {
+		f.Streams = append(f.Streams, workflow.StreamNameForUID(string(r.UID)))
+	}
+	return f, nil
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestore
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type ImportSubCommand struct {
+	cmd.CommandActioner
+}
+
+func Import(input cli.Input) error {
+	return (&ImportSubCommand{}).do(input)
+}
+
+func (opts *ImportSubCommand) do(input cli.Input) error {
+	path := input.String(flagkey.StatestoreFile)
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	a, closeFn, err := open(input, &opts.CommandActioner)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	h, st, err := statestore.ReadArchive(input.Context(), in, a)
+	if err != nil {
+		// Batches before the failing one are in; say how far the import got.
+		return fmt.Errorf("importing %s after %d keys, %d streams, %d events and %d messages: %w",
+			path, st.Keys, st.Streams, st.Events, st.Messages, err)
+	}
+	fmt.Printf("imported %d keys, %d streams with %d events, and %d messages from %s (exported %s)\n",
+		st.Keys, st.Streams, st.Events, st.Messages, path, h.CreatedAt.Format(time.RFC3339))
+	return nil
+}
@@ -18,6 +18,8 @@ func Commands() *cobra.Command {
 	}, Create, flag.FlagSet{
 		Optional: []flag.Flag{flag.TtName, flag.TtFnName,
 			flag.TtCron, flag.TtMethod, flag.FnSubPath,
+			flag.TtTimeZone, flag.TtStartingDeadline, flag.TtConcurrencyPolicy,
+			flag.TtBody, flag.TtHeader,
 
 			flag.SpecSave, flag.SpecDry,
 		},
@@ -29,7 +31,9 @@ func Commands() *cobra.Command {
 		Short:   "Update a time trigger",
 	}, Update, flag.FlagSet{
 		Required: []flag.Flag{flag.TtName},
-		Optional: []flag.Flag{flag.TtFnName, flag.TtCron, flag.TtMethod, flag.FnSubPath},
+		Optional: []flag.Flag{flag.TtFnName, flag.TtCron, flag.TtMethod, flag.FnSubPath,
+			flag.TtTimeZone, flag.TtStartingDeadline, flag.TtConcurrencyPolicy,
+			flag.TtBody, flag.TtHeader},
 	})
 
 	deleteCmd := wrapper.SubCommand(&cobra.Command{
@@ -55,7 +59,16 @@ func Commands() *cobra.Command {
 		Aliases: []string{"show"},
 		Short:   "Show schedule for cron spec",
 	}, Show, flag.FlagSet{
-		Optional: []flag.Flag{flag.TtCron, flag.TtRound},
+		Optional: []flag.Flag{flag.TtCron, flag.TtTimeZone, flag.TtRound},
+	})
+
+	testCmd := wrapper.SubCommand(&cobra.Command{
+		Use:   "test",
+		Short: "Fire a time trigger once now, with its rendered body and headers",
+		Long:  "Invoke the time trigger's function once, as the timer would for a slot due now, and print the response. --body and --header try out templates without updating the trigger.",
+	}, Test, flag.FlagSet{
+		Required: []flag.Flag{flag.TtName},
+		Optional: []flag.Flag{flag.TtBody, flag.TtHeader, flag.FnTestTimeout},
 	})
 
 	command := &cobra.Command{
@@ -72,7 +85,7 @@ func Commands() *cobra.Command {
 		Optional: []flag.Flag{flag.WaitTimeout},
 	})
 
-	command.AddCommand(createCmd, updateCmd, deleteCmd, listCmd, showCmd, waitCmd)
+	command.AddCommand(createCmd, updateCmd, deleteCmd, listCmd, showCmd, testCmd, waitCmd)
 
 	return command
 }
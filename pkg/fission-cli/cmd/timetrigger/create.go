@@ -13,7 +13,6 @@ import (
 
 	"errors"
 
-	"github.com/robfig/cron/v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
@@ -22,6 +21,7 @@ import (
 	"github.com/fission/fission/pkg/fission-cli/console"
 	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
 	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/timer/payload"
 )
 
 type CreateSubCommand struct {
@@ -105,10 +105,31 @@ func (opts *CreateSubCommand) complete(input cli.Input) (err error) {
 				Type: fv1.FunctionReferenceTypeFunctionName,
 				Name: fnName,
 			},
-			Method:  input.String(flagkey.TtMethod),
-			Subpath: input.String(flagkey.FnSubPath),
+			Method:            input.String(flagkey.TtMethod),
+			Subpath:           input.String(flagkey.FnSubPath),
+			TimeZone:          input.String(flagkey.TtTimeZone),
+			ConcurrencyPolicy: fv1.TimeTriggerConcurrencyPolicy(input.String(flagkey.TtConcurrencyPolicy)),
 		},
 	}
+	if input.IsSet(flagkey.TtStartingDeadline) {
+		deadline := input.Int64(flagkey.TtStartingDeadline)
+		if deadline < 0 {
+			return errors.New("--starting-deadline must be >= 0")
+		}
+		opts.trigger.Spec.StartingDeadlineSeconds = &deadline
+	}
+	if _, err := opts.trigger.Spec.Location(); err != nil {
+		return fmt.Errorf("invalid --timezone %q: %w", opts.trigger.Spec.TimeZone, err)
+	}
+	if opts.trigger.Spec.Body, err = readBody(input); err != nil {
+		return err
+	}
+	if opts.trigger.Spec.Headers, err = readHeaders(input); err != nil {
+		return err
+	}
+	if err := payload.Validate(opts.trigger.Spec.Body, opts.trigger.Spec.Headers); err != nil {
+		return fmt.Errorf("invalid --body or --header: %w", err)
+	}
 
 	return nil
 }
@@ -128,7 +149,7 @@ func (opts *CreateSubCommand) run(input cli.Input) error {
 
 	t := util.GetServerInfo(input, opts.Client()).ServerTime.CurrentTime.UTC()
 
-	err = getCronNextNActivationTime(opts.trigger.Spec.Cron, t, 1)
+	err = getCronNextNActivationTime(opts.trigger.Spec, t, 1)
 	if err != nil {
 		return fmt.Errorf("error passing cron spec examination: %w", err)
 	}
@@ -136,12 +157,18 @@ func (opts *CreateSubCommand) run(input cli.Input) error {
 	return nil
 }
 
-func getCronNextNActivationTime(cronSpec string, serverTime time.Time, round int) error {
-	cronSpecParser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	sched, err := cronSpecParser.Parse(cronSpec)
+// getCronNextNActivationTime prints the next round firings of the trigger
+// spec's schedule, evaluated in its TimeZone the way the timer evaluates it.
+// Times print in that zone so a local-time cron reads naturally.
+func getCronNextNActivationTime(ttSpec fv1.TimeTriggerSpec, serverTime time.Time, round int) error {
+	sched, err := ttSpec.Schedule()
 	if err != nil {
 		return err
 	}
+	if ttSpec.TimeZone != "" {
+		loc, _ := ttSpec.Location() // already resolved by Schedule
+		serverTime = serverTime.In(loc)
+	}
 
 	fmt.Printf("Current Server Time: \t%v\n", serverTime.Format(time.RFC3339))
 
@@ -6,6 +6,7 @@ package timetrigger
 
 import (
 	"fmt"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -40,15 +41,27 @@ func (opts *ListSubCommand) do(input cli.Input) (err error) {
 		return err
 	}
 
-	headers := []string{"NAME", "CRON", "FUNCTION_NAME", "METHOD", "SUBPATH", "READY"}
+	headers := []string{"NAME", "CRON", "FUNCTION_NAME", "METHOD", "SUBPATH", "LAST_SCHEDULE", "NEXT_SCHEDULE", "READY"}
 	row := func(tt fv1.TimeTrigger) []string {
 		return []string{
 			tt.Name, tt.Spec.Cron, tt.Spec.Name, tt.Spec.Method, tt.Spec.Subpath,
+			scheduleTime(tt.Status.LastScheduleTime), scheduleTime(tt.Status.NextScheduleTime),
 			util.ConditionStatus(tt.Status.Conditions, fv1.TimeTriggerConditionReady),
 		}
 	}
-	wideExtra := []string{"AGE"}
-	wideRow := func(tt fv1.TimeTrigger) []string { return []string{util.AgeOf(tt.CreationTimestamp)} }
+	wideExtra := []string{"TIMEZONE", "CONCURRENCY_POLICY", "AGE"}
+	wideRow := func(tt fv1.TimeTrigger) []string {
+		return []string{tt.Spec.TimeZone, string(tt.Spec.GetConcurrencyPolicy()), util.AgeOf(tt.CreationTimestamp)}
+	}
 
 	return util.PrintObjects(format, tts.Items, headers, row, wideExtra, wideRow)
 }
+
+// scheduleTime renders a status schedule time as RFC3339 UTC, or
+// util.NoneValue before the timer has recorded one.
+func scheduleTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return util.NoneValue
+	}
+	return t.UTC().Format(time.RFC3339)
+}
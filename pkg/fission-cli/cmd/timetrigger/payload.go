@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package timetrigger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+// readBody parses --body: an inline template, or @path to a file holding one.
+func readBody(input cli.Input) (string, error) {
+	raw := input.String(flagkey.TtBody)
+	if !strings.HasPrefix(raw, "@") {
+		return raw, nil
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(raw, "@"))
+	if err != nil {
+		return "", fmt.Errorf("reading body file: %w", err)
+	}
+	return string(data), nil
+}
+
+// readHeaders parses the repeatable --header 'Name: value' flag. Empty
+// entries are skipped, so an empty --header yields no headers at all.
+func readHeaders(input cli.Input) (map[string]string, error) {
+	var headers map[string]string
+	for _, h := range input.StringSlice(flagkey.TtHeader) {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --header %q, want 'Name: value'", h)
+		}
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
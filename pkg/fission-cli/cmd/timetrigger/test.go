@@ -5,14 +5,27 @@
 package timetrigger
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"errors"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
 	"github.com/fission/fission/pkg/fission-cli/cmd"
 	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
 	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/timer/payload"
+	"github.com/fission/fission/pkg/utils"
+	"github.com/fission/fission/pkg/utils/correlation"
 )
 
 type ShowSubCommand struct {
@@ -37,10 +50,103 @@ func (opts *ShowSubCommand) run(flaginput cli.Input) error {
 
 	t := util.GetServerInfo(flaginput, opts.Client()).ServerTime.CurrentTime.UTC()
 
-	err := getCronNextNActivationTime(cronSpec, t, round)
+	err := getCronNextNActivationTime(fv1.TimeTriggerSpec{Cron: cronSpec, TimeZone: flaginput.String(flagkey.TtTimeZone)}, t, round)
 	if err != nil {
 		return fmt.Errorf("error passing cron spec examination: %w", err)
 	}
 
 	return nil
 }
+
+type TestSubCommand struct {
+	cmd.CommandActioner
+}
+
+func Test(input cli.Input) error {
+	return (&TestSubCommand{}).do(input)
+}
+
+// do fires the trigger once, the way the timer fires a slot due now: the
+// same rendered body and headers, method and route (alias/version suffix and
+// subpath included), sent to the router internal listener like `fission fn
+// test`. --body/--header stand in for the stored templates.
+func (opts *TestSubCommand) do(input cli.Input) error {
+	_, namespace, err := opts.GetResourceNamespace(input)
+	if err != nil {
+		return fmt.Errorf("error in testing time trigger : %w", err)
+	}
+	tt, err := opts.Client().FissionClientSet.CoreV1().TimeTriggers(namespace).Get(input.Context(), input.String(flagkey.TtName), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting time trigger: %w", err)
+	}
+
+	if input.IsSet(flagkey.TtBody) {
+		if tt.Spec.Body, err = readBody(input); err != nil {
+			return err
+		}
+	}
+	if input.IsSet(flagkey.TtHeader) {
+		if tt.Spec.Headers, err = readHeaders(input); err != nil {
+			return err
+		}
+	}
+	if err := payload.Validate(tt.Spec.Body, tt.Spec.Headers); err != nil {
+		return fmt.Errorf("invalid body or headers: %w", err)
+	}
+	now := time.Now()
+	body, headers, err := payload.Render(tt.Spec.Body, tt.Spec.Headers, payload.NewData(tt.Name, tt.Namespace, now, now))
+	if err != nil {
+		return err
+	}
+
+	ctx := input.Context()
+	if timeout := input.Duration(flagkey.FnTestTimeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	internalURL, err := util.GetRouterInternalURL(ctx, opts.Client())
+	if err != nil {
+		return fmt.Errorf("resolving the router internal listener: %w", err)
+	}
+	target := internalURL.JoinPath(utils.UrlForFunctionReference(tt.Spec.FunctionReference, tt.Namespace) + tt.Spec.Subpath)
+
+	method := tt.Spec.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("X-Fission-Timer-Name", tt.Name)
+
+	// Sign like `fission fn test` when internal auth is enabled; an empty
+	// secret passes the request through unsigned.
+	transport := http.DefaultTransport
+	if secret := os.Getenv("FISSION_INTERNAL_AUTH_SECRET"); secret != "" {
+		transport = hmacauth.NewServiceSigningTransport([]byte(secret), hmacauth.ServiceRouterInternal, transport, "/fission-function/")
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return fmt.Errorf("error executing HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from function: %w", err)
+	}
+	if reqID := resp.Header.Get(correlation.HeaderRequestID); reqID != "" {
+		fmt.Fprintf(os.Stderr, "Request ID: %s\n", reqID)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("function '%s' returned %s: %s", tt.Spec.Name, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	os.Stdout.Write(respBody)
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/fission/fission/pkg/fission-cli/console"
 	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
 	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/timer/payload"
 )
 
 type UpdateSubCommand struct {
@@ -76,8 +77,47 @@ func (opts *UpdateSubCommand) complete(input cli.Input) error {
 		updated = true
 	}
 
+	if input.IsSet(flagkey.TtTimeZone) {
+		tt.Spec.TimeZone = input.String(flagkey.TtTimeZone)
+		if _, err := tt.Spec.Location(); err != nil {
+			return fmt.Errorf("invalid --timezone %q: %w", tt.Spec.TimeZone, err)
+		}
+		updated = true
+	}
+
+	if input.IsSet(flagkey.TtStartingDeadline) {
+		if deadline := input.Int64(flagkey.TtStartingDeadline); deadline >= 0 {
+			tt.Spec.StartingDeadlineSeconds = &deadline
+		} else {
+			tt.Spec.StartingDeadlineSeconds = nil
+		}
+		updated = true
+	}
+
+	if input.IsSet(flagkey.TtConcurrencyPolicy) {
+		tt.Spec.ConcurrencyPolicy = fv1.TimeTriggerConcurrencyPolicy(input.String(flagkey.TtConcurrencyPolicy))
+		updated = true
+	}
+
+	if input.IsSet(flagkey.TtBody) {
+		if tt.Spec.Body, err = readBody(input); err != nil {
+			return err
+		}
+		updated = true
+	}
+
+	if input.IsSet(flagkey.TtHeader) {
+		if tt.Spec.Headers, err = readHeaders(input); err != nil {
+			return err
+		}
+		updated = true
+	}
+
 	if !updated {
-		return errors.New("nothing to update. Use --cron or --function or --method or --subpath")
+		return errors.New("nothing to update. Use --cron or --function or --method or --subpath or --timezone or --starting-deadline or --concurrency-policy or --body or --header")
+	}
+	if err := payload.Validate(tt.Spec.Body, tt.Spec.Headers); err != nil {
+		return fmt.Errorf("invalid --body or --header: %w", err)
 	}
 
 	opts.trigger = tt
@@ -109,7 +149,7 @@ func (opts *UpdateSubCommand) run(input cli.Input) error {
 
 	t := util.GetServerInfo(input, opts.Client()).ServerTime.CurrentTime.UTC()
 
-	err = getCronNextNActivationTime(opts.trigger.Spec.Cron, t, 1)
+	err = getCronNextNActivationTime(opts.trigger.Spec, t, 1)
 	if err != nil {
 		return fmt.Errorf("error passing cron spec examination: %w", err)
 	}
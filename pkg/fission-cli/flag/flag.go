@@ -148,6 +148,15 @@ var (
 	RunTimeMaxMemory   = Flag{Type: Int, Name: flagkey.RuntimeMaxmemory, Usage: "Maximum memory to be assigned to pod (In megabyte)"}
 	RunImagePullSecret = Flag{Type: String, Name: flagkey.RunImagePullSecret, Usage: "Secret for Kubernetes to pull an image from a private registry"}
 
+	RateLimitRequests = Flag{Type: Int, Name: flagkey.RateLimitRequests, Usage: "Requests allowed per --ratelimit-period before the router answers 429; 0 removes the rate limit"}
+	RateLimitPeriod   = Flag{Type: Duration, Name: flagkey.RateLimitPeriod, Usage: "Window --ratelimit-requests is measured over (default 1s)"}
+	RateLimitBurst    = Flag{Type: Int, Name: flagkey.RateLimitBurst, Usage: "Requests a client may send at once after being idle (default --ratelimit-requests)"}
+	RateLimitKey      = Flag{Type: String, Name: flagkey.RateLimitKey, Usage: "What requests are limited by: clientip, header:<name> or jwtclaim:<claim>; empty shares one limit among all requests"}
+
+	MirrorVersion = Flag{Type: String, Name: flagkey.MirrorVersion, Usage: "FunctionVersion of the same function that receives shadow copies of sampled requests; their responses are discarded (exactly one of --mirror-version/--mirror-alias)"}
+	MirrorAlias   = Flag{Type: String, Name: flagkey.MirrorAlias, Usage: "FunctionAlias of the same function whose current version receives shadow copies of sampled requests (exactly one of --mirror-version/--mirror-alias)"}
+	MirrorPercent = Flag{Type: Int, Name: flagkey.MirrorPercent, Usage: "Percentage (1-100) of requests copied to the mirror target; 0 removes the mirror"}
+
 	ReplicasMin = Flag{Type: Int, Name: flagkey.ReplicasMinscale, Usage: "Minimum number of pods (Uses resource inputs to configure HPA)", DefaultInt: 1}
 	ReplicasMax = Flag{Type: Int, Name: flagkey.ReplicasMaxscale, Usage: "Maximum number of pods (Uses resource inputs to configure HPA)", DefaultInt: 1}
 
@@ -249,7 +258,7 @@ var (
 	TopicLimit       = Flag{Type: Int, Name: flagkey.TopicLimit, Usage: "Maximum events to peek", DefaultInt: 10}
 
 	// RFC-0024 async dead-letter-queue admin flags.
-	DlqQueue = Flag{Type: String, Name: flagkey.DlqQueue, Usage: "Dead-letter queue to operate on: empty for async invocations, or a broker egress queue (mq-egress-<type>, e.g. mq-egress-kafka)"}
+	DlqQueue = Flag{Type: String, Name: flagkey.DlqQueue, Usage: "Dead-letter queue to operate on: empty for async invocations, webhook for webhook destination deliveries, or a broker egress queue (mq-egress-<type>, e.g. mq-egress-kafka)"}
 	DlqID    = Flag{Type: String, Name: flagkey.DlqID, Usage: "Durable invocation id of a dead-lettered async invocation"}
 	DlqAll   = Flag{Type: Bool, Name: flagkey.DlqAll, Usage: "Apply to every dead-lettered invocation"}
 	DlqLimit = Flag{Type: Int, Name: flagkey.DlqLimit, Usage: "Maximum number of dead-lettered invocations to list", DefaultInt: 100}
@@ -275,10 +284,19 @@ var (
 	FnAsyncMaxAge      = Flag{Type: Duration, Name: flagkey.FnAsyncMaxAge, Usage: "Max time an async invocation may wait for successful delivery before it is dead-lettered"}
 	FnAsyncOnSuccess   = Flag{Type: String, Name: flagkey.FnAsyncOnSuccess, Usage: "Same-namespace function to invoke with the result after a successful async delivery; empty clears it"}
 	FnAsyncOnFailure   = Flag{Type: String, Name: flagkey.FnAsyncOnFailure, Usage: "Same-namespace function to invoke with the result after a permanent async failure; empty clears it"}
+	// Async delivery caps; a throttled invocation stays queued without spending
+	// a retry attempt.
+	FnAsyncMaxInFlight            = Flag{Type: Int, Name: flagkey.FnAsyncMaxInFlight, Usage: "Max concurrent async deliveries to the function across router replicas"}
+	FnAsyncMaxDeliveriesPerSecond = Flag{Type: Int, Name: flagkey.FnAsyncMaxDeliveriesPerSecond, Usage: "Max async deliveries started per second to the function across router replicas"}
 	// RFC-0027 statestore topic destinations. Mutually exclusive per condition
 	// with the function-destination flag above.
 	FnAsyncOnSuccessTopic = Flag{Type: String, Name: flagkey.FnAsyncOnSuccessTopic, Usage: "Statestore topic to publish the result envelope to after a successful async delivery; empty clears it"}
 	FnAsyncOnFailureTopic = Flag{Type: String, Name: flagkey.FnAsyncOnFailureTopic, Usage: "Statestore topic to publish the result envelope to after a permanent async failure; empty clears it"}
+	FnAsyncCloudEvents    = Flag{Type: Bool, Name: flagkey.FnAsyncCloudEvents, Usage: "Send the result envelope to the async destinations as a CloudEvent (binary mode to a function, structured mode to a topic)"}
+	// Webhook destinations, mutually exclusive per condition with the function
+	// and topic flags. Headers and the signing secret are set in the spec.
+	FnAsyncOnSuccessWebhook = Flag{Type: String, Name: flagkey.FnAsyncOnSuccessWebhook, Usage: "https:// URL to POST the result envelope to after a successful async delivery; empty clears it"}
+	FnAsyncOnFailureWebhook = Flag{Type: String, Name: flagkey.FnAsyncOnFailureWebhook, Usage: "https:// URL to POST the result envelope to after a permanent async failure; empty clears it"}
 	// Termination Grace Period configurable at function creation/update only for container functions
 	FnTerminationGracePeriod = Flag{Type: Int64, Name: flagkey.FnGracePeriod, Usage: "Grace time (in seconds) for pod to perform connection draining before termination (only non-negative values considered)", DefaultInt64: 360}
 
@@ -304,6 +322,20 @@ var (
 	HtFnFilter          = Flag{Type: String, Name: flagkey.HtFilter, Usage: "Name of the function for trigger(s)"}
 	HtPrefix            = Flag{Type: String, Name: flagkey.HtPrefix, Usage: "Prefix with which functions are exposed. NOTE: Prefix takes precedence over URL/RelativeURL [DEPRECATED for 'fn create', use 'route create' instead]"}
 	HtKeepPrefix        = Flag{Type: Bool, Name: flagkey.HtKeepPrefix, Usage: "Keep the prefix in the URL while forwarding request to the function"}
+	HtCloudEvents       = Flag{Type: Bool, Name: flagkey.HtCloudEvents, Usage: "Read requests as CloudEvents 1.0: reject invalid events and deliver structured-mode events to the function in binary mode (ce-* headers); false removes the setting"}
+	HtCloudEventsReq    = Flag{Type: Bool, Name: flagkey.HtCloudEventsReq, Usage: "Reject requests that carry no CloudEvent with 400; implies --cloudevents"}
+	HtAuthScope         = Flag{Type: StringSlice, Name: flagkey.HtAuthScope, Usage: "Scope the caller's token must grant to invoke through the trigger; repeatable. Replaces the trigger's scopes; --auth-scope=\"\" removes them. Requires router authentication"}
+	HtAuthClaim         = Flag{Type: StringSlice, Name: flagkey.HtAuthClaim, Usage: "Claim the caller's token must carry, as NAME (any value) or NAME=VALUE; repeat a NAME to accept several values. Replaces the trigger's claims; --auth-claim=\"\" removes them. Requires router authentication"}
+	HtAPIKeyName        = Flag{Type: String, Name: flagkey.HtAPIKeyName, Usage: "Name of the API key; stamped into the router's access log and metrics for requests presenting it"}
+	HtAPIKeySecret      = Flag{Type: String, Name: flagkey.HtAPIKeySecret, Usage: "Secret holding the trigger's API key digests, used when the trigger has no API key block yet (default <trigger>-apikeys)"}
+	HtAPIKeyHeader      = Flag{Type: String, Name: flagkey.HtAPIKeyHeader, Usage: "Request header carrying the API key, used when the trigger has no API key block yet (default X-Api-Key)"}
+	HtAPIKeyQuery       = Flag{Type: String, Name: flagkey.HtAPIKeyQuery, Usage: "Query parameter carrying the API key, used when the trigger has no API key block yet"}
+	HtCache             = Flag{Type: Bool, Name: flagkey.HtCache, Usage: "Let the router answer GET and HEAD requests from cached function responses, as the function's Cache-Control allows; false removes the setting"}
+	HtCacheTTL          = Flag{Type: Duration, Name: flagkey.HtCacheTTL, Usage: "How long to cache a response that declares no max-age or Expires; implies --cache"}
+	HtCacheMaxTTL       = Flag{Type: Duration, Name: flagkey.HtCacheMaxTTL, Usage: "Longest a response is cached, whatever the function declares (default 1h); implies --cache"}
+	HtCacheVary         = Flag{Type: StringSlice, Name: flagkey.HtCacheVary, Usage: "Request header whose value is part of the cache key, e.g. Accept-Language; repeatable. Replaces the trigger's vary headers; implies --cache"}
+	HtCacheShared       = Flag{Type: Bool, Name: flagkey.HtCacheShared, Usage: "Keep cached responses in the statestore, shared by all router replicas; implies --cache"}
+	HtTransform         = Flag{Type: String, Name: flagkey.HtTransform, Usage: "Path to a YAML or JSON file holding the trigger's transform block: header edits, parameter mappings, body size, content type and JSON Schema checks. Replaces the trigger's transform; --transform=\"\" removes it"}
 
 	TokUsername = Flag{Type: String, Name: flagkey.TokUsername, Usage: "Username to generate token for function invocation"}
 	TokPassword = Flag{Type: String, Name: flagkey.TokPassword, Usage: "Password to generate token for function invocation"}
@@ -326,6 +358,12 @@ var (
 	TtRound  = Flag{Type: Int, Name: flagkey.TtRound, Usage: "Get next N rounds of invocation time", DefaultInt: 1}
 	TtMethod = Flag{Type: String, Name: flagkey.TtMethod, Usage: "HTTP Methods: GET,POST,PUT,DELETE,HEAD."}
 
+	TtTimeZone          = Flag{Type: String, Name: flagkey.TtTimeZone, Usage: "IANA time zone the cron spec is evaluated in, e.g. 'Europe/Berlin'; empty means the timer's local zone (UTC by default)"}
+	TtStartingDeadline  = Flag{Type: Int64, Name: flagkey.TtStartingDeadline, Usage: "Catch up the most recent firing missed while the timer was down if it is at most this many seconds old; on update, a negative value turns catch-up off"}
+	TtConcurrencyPolicy = Flag{Type: String, Name: flagkey.TtConcurrencyPolicy, Usage: "What to do when a firing is due while the previous one still runs; one of 'Allow', 'Forbid' (skip the new firing), 'Replace' (cancel the running one)"}
+	TtBody              = Flag{Type: String, Name: flagkey.TtBody, Short: "b", Usage: "JSON request body template sent on every firing, inline or @path/to/file.json; may use {{ .TriggerName }}, {{ .Namespace }}, {{ .ScheduledTime }} and {{ .FireTime }}. On update, an empty value removes the body"}
+	TtHeader            = Flag{Type: StringSlice, Name: flagkey.TtHeader, Short: "H", Usage: "Request header template sent on every firing, as 'Name: value'; repeatable, values take the same variables as --body. On update, replaces all headers; --header '' removes them"}
+
 	MqtName            = Flag{Type: String, Name: flagkey.MqtName, Usage: "Message queue trigger name"}
 	MqtFnName          = Flag{Type: String, Name: flagkey.MqtFnName, Usage: "Function name"}
 	MqtMQType          = Flag{Type: String, Name: flagkey.MqtMQType, Usage: "For mqtkind \"fission\" => kafka, statestore (the built-in, no-broker option)\n\t\t\t\t\t For mqtkind \"keda\" => kafka, aws-sqs-queue, aws-kinesis-stream, gcp-pubsub, stan, nats-jetstream, rabbitmq, redis", DefaultString: "kafka"}
@@ -341,6 +379,8 @@ var (
 	MqtMetadata        = Flag{Type: StringSlice, Name: flagkey.MqtMetadata, Usage: "Metadata needed for connecting to source system in format: --metadata key1=value1 --metadata key2=value2"}
 	MqtSecret          = Flag{Type: String, Name: flagkey.MqtSecret, Usage: "Name of secret object", DefaultString: ""}
 	MqtKind            = Flag{Type: String, Name: flagkey.MqtKind, Usage: "Kind of Message Queue Trigger, e.g. fission, keda", DefaultString: "keda"}
+	MqtCloudEvents     = Flag{Type: Bool, Name: flagkey.MqtCloudEvents, Usage: "Deliver messages to the function as CloudEvents (binary mode) and publish response/error topic messages as structured-mode CloudEvents; not supported with --mqtkind keda"}
+	MqtMessageGroup    = Flag{Type: String, Name: flagkey.MqtMessageGroup, Usage: "Kafka record header naming each message's message group: messages are invoked async, in order within a group; not supported with --resptopic or --mqtkind keda. An empty value removes the setting"}
 
 	EnvName            = Flag{Type: String, Name: flagkey.EnvName, Usage: "Environment name"}
 	EnvPoolsize        = Flag{Type: Int, Name: flagkey.EnvPoolsize, Usage: "Size of the pool", DefaultInt: 3}
@@ -362,11 +402,18 @@ var (
 	EnvBuilder                = Flag{Type: StringSlice, Name: flagkey.EnvBuilder, Usage: "Environment variable to be set in the builder container"}
 	EnvRuntime                = Flag{Type: StringSlice, Name: flagkey.EnvRuntime, Usage: "Environment variable to be set in the runtime container"}
 
-	KwName      = Flag{Type: String, Name: flagkey.KwName, Usage: "Watch name"}
-	KwFnName    = Flag{Type: String, Name: flagkey.KwFnName, Usage: "Function name"}
-	KwNamespace = Flag{Type: String, Name: flagkey.KwNamespace, Aliases: []string{"ns"}, Usage: "Namespace of resource to watch"}
-	KwObjType   = Flag{Type: String, Name: flagkey.KwObjType, Usage: "Type of resource to watch (Pod, Service, etc.)", DefaultString: "pod"}
-	KwLabels    = Flag{Type: String, Name: flagkey.KwLabels, Usage: "Label selector of the form a=b,c=d"}
+	KwName          = Flag{Type: String, Name: flagkey.KwName, Usage: "Watch name"}
+	KwFnName        = Flag{Type: String, Name: flagkey.KwFnName, Usage: "Function name"}
+	KwNamespace     = Flag{Type: String, Name: flagkey.KwNamespace, Aliases: []string{"ns"}, Usage: "Namespace of resource to watch"}
+	KwObjType       = Flag{Type: String, Name: flagkey.KwObjType, Usage: "Type of resource to watch (Pod, Service, etc.)", DefaultString: "pod"}
+	KwLabels        = Flag{Type: String, Name: flagkey.KwLabels, Usage: "Label selector of the form a=b,c=d"}
+	KwAPIVersion    = Flag{Type: String, Name: flagkey.KwAPIVersion, Usage: "API version of the kind to watch, e.g. apps/v1 or cert-manager.io/v1; use with --kind instead of --type"}
+	KwKind          = Flag{Type: String, Name: flagkey.KwKind, Usage: "Kind to watch, e.g. Deployment or Certificate; use with --api-version instead of --type"}
+	KwEventType     = Flag{Type: StringSlice, Name: flagkey.KwEventType, Usage: "Event type to deliver (ADDED, MODIFIED or DELETED); repeat for more than one. Default: all"}
+	KwFieldSelector = Flag{Type: String, Name: flagkey.KwFieldSelector, Usage: "Field selector for the watched objects, e.g. status.phase=Failed"}
+	KwChangedPath   = Flag{Type: StringSlice, Name: flagkey.KwChangedPath, Usage: "JSONPath, e.g. .spec.replicas; MODIFIED events are delivered only when one of these values changed. Repeatable"}
+	KwFromSeq       = Flag{Type: Int64, Name: flagkey.KwFromSeq, Usage: "Sequence number of the first event to replay (see the X-Fission-Watch-Sequence header of a delivered event)"}
+	KwReplayLimit   = Flag{Type: Int, Name: flagkey.KwReplayLimit, Usage: "Maximum number of events to replay", DefaultInt: 100}
 
 	PkgName           = Flag{Type: String, Name: flagkey.PkgName, Usage: "Package name"}
 	PkgForce          = Flag{Type: Bool, Name: flagkey.PkgForce, Short: "f", Usage: "Force update a package even if it is used by one or more functions"}
@@ -425,6 +472,11 @@ var (
 	TenantBuilderNamespace  = Flag{Type: String, Name: flagkey.TenantBuilderNamespace, Usage: "Namespace where this tenant's builder pods run (defaults to the tenant namespace)"}
 	TenantForce             = Flag{Type: Bool, Name: flagkey.TenantForce, Usage: "Disable the tenant even if it still has functions/triggers (they will stop being served)", DefaultBool: false}
 
+	// `fission statestore export|import`.
+	StatestoreFile   = Flag{Type: String, Name: flagkey.StatestoreFile, Short: "f", Usage: "Archive file to write (export) or read (import)"}
+	StatestoreDriver = Flag{Type: String, Name: flagkey.StatestoreDriver, Usage: "Statestore driver to open directly (postgres, sqlite, redis or client) instead of the cluster's embedded statestore"}
+	StatestoreDSN    = Flag{Type: String, Name: flagkey.StatestoreDSN, Usage: "Connection string for --driver, as in STATESTORE_DSN"}
+
 	// RFC-0025 `fission fn publish`.
 	PublishDescription = Flag{Type: String, Name: flagkey.PublishDescription, Usage: "Human-readable description recorded on the minted FunctionVersion"}
 	PublishWait        = Flag{Type: Bool, Name: flagkey.PublishWait, Usage: "Wait for the function's referenced package build to finish before publishing (see --timeout)"}
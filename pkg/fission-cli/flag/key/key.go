@@ -128,6 +128,11 @@ const (
 	FnAsyncOnSuccess   = "async-on-success"
 	FnAsyncOnFailure   = "async-on-failure"
 	DlqQueue           = "queue"
+
+	// Per-function async delivery caps, enforced across router replicas.
+	FnAsyncMaxInFlight            = "async-max-in-flight"
+	FnAsyncMaxDeliveriesPerSecond = "async-max-deliveries-per-second"
+
 	// RFC-0027 `fission topic` dev commands.
 	TopicName        = "topic"
 	TopicData        = "data"
@@ -138,6 +143,10 @@ const (
 	// RFC-0027 topic destinations (statestore built-in eventing).
 	FnAsyncOnSuccessTopic = "async-on-success-topic"
 	FnAsyncOnFailureTopic = "async-on-failure-topic"
+	FnAsyncCloudEvents    = "async-cloudevents"
+	// Webhook destinations.
+	FnAsyncOnSuccessWebhook = "async-on-success-webhook"
+	FnAsyncOnFailureWebhook = "async-on-failure-webhook"
 
 	// RFC-0023 `fission fn state` admin commands.
 	StateKey       = "key"
@@ -177,6 +186,20 @@ const (
 	HtFilter            = HtFnName
 	HtPrefix            = "prefix"
 	HtKeepPrefix        = "keepprefix"
+	HtCloudEvents       = "cloudevents"
+	HtCloudEventsReq    = "cloudevents-required"
+	HtAuthScope         = "auth-scope"
+	HtAuthClaim         = "auth-claim"
+	HtAPIKeyName        = "key-name"
+	HtAPIKeySecret      = "apikey-secret"
+	HtAPIKeyHeader      = "apikey-header"
+	HtAPIKeyQuery       = "apikey-query"
+	HtCache             = "cache"
+	HtCacheTTL          = "cache-ttl"
+	HtCacheMaxTTL       = "cache-max-ttl"
+	HtCacheVary         = "cache-vary"
+	HtCacheShared       = "cache-shared"
+	HtTransform         = "transform"
 
 	TokUsername = "username"
 	TokPassword = "password"
@@ -188,6 +211,12 @@ const (
 	TtRound  = "round"
 	TtMethod = "method"
 
+	TtTimeZone          = "timezone"
+	TtStartingDeadline  = "starting-deadline"
+	TtConcurrencyPolicy = "concurrency-policy"
+	TtBody              = "body"
+	TtHeader            = "header"
+
 	WfName     = resourceName
 	WfFile     = "file"
 	WfOffline  = "offline"
@@ -211,6 +240,8 @@ const (
 	MqtMetadata        = "metadata"
 	MqtSecret          = "secret"
 	MqtKind            = "mqtkind"
+	MqtCloudEvents     = "cloudevents"
+	MqtMessageGroup    = "message-group-header"
 
 	EnvName            = resourceName
 	EnvPoolsize        = "poolsize"
@@ -227,11 +258,18 @@ const (
 	EnvBuilder         = "builder-env"
 	EnvRuntime         = "runtime-env"
 
-	KwName      = resourceName
-	KwFnName    = "function"
-	KwNamespace = "namespace"
-	KwObjType   = "type"
-	KwLabels    = "labels"
+	KwName          = resourceName
+	KwFnName        = "function"
+	KwNamespace     = "namespace"
+	KwObjType       = "type"
+	KwLabels        = "labels"
+	KwAPIVersion    = "api-version"
+	KwKind          = "kind"
+	KwEventType     = "event-type"
+	KwFieldSelector = "field-selector"
+	KwChangedPath   = "changed-path"
+	KwFromSeq       = "from-seq"
+	KwReplayLimit   = "limit"
 
 	PkgName           = resourceName
 	PkgForce          = force
@@ -284,11 +322,27 @@ const (
 	WaitFor     = "for"
 	WaitTimeout = "timeout"
 
+	// Rate limits, shared by `fn` and `httptrigger` create/update.
+	RateLimitRequests = "ratelimit-requests"
+	RateLimitPeriod   = "ratelimit-period"
+	RateLimitBurst    = "ratelimit-burst"
+	RateLimitKey      = "ratelimit-key"
+
+	// Request mirroring, shared by `httptrigger` and `alias` create/update.
+	MirrorVersion = "mirror-version"
+	MirrorAlias   = "mirror-alias"
+	MirrorPercent = "mirror-percent"
+
 	// FissionTenant (multi-namespace tenancy)
 	TenantFunctionNamespace = "function-namespace"
 	TenantBuilderNamespace  = "builder-namespace"
 	TenantForce             = "force"
 
+	// `fission statestore export|import`.
+	StatestoreFile   = "file"
+	StatestoreDriver = "driver"
+	StatestoreDSN    = "dsn"
+
 	// RFC-0025 `fission fn publish`.
 	PublishDescription = "description"
 	PublishWait        = "wait"
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+// GetMirrorConfig applies the --mirror-* flags to current (nil on create),
+// so an update that only changes the percentage keeps the target. Setting
+// none keeps current; --mirror-percent 0 removes the mirror. --mirror-version
+// and --mirror-alias name the target exclusively: setting one clears the
+// other.
+func GetMirrorConfig(input cli.Input, current *fv1.TrafficMirror, field string) (*fv1.TrafficMirror, error) {
+	if !input.IsSet(flagkey.MirrorVersion) && !input.IsSet(flagkey.MirrorAlias) && !input.IsSet(flagkey.MirrorPercent) {
+		return current, nil
+	}
+	if input.IsSet(flagkey.MirrorPercent) && input.Int(flagkey.MirrorPercent) == 0 {
+		return nil, nil
+	}
+	if err := MutuallyExclusive(flagkey.MirrorVersion, input.String(flagkey.MirrorVersion),
+		flagkey.MirrorAlias, input.String(flagkey.MirrorAlias)); err != nil {
+		return nil, err
+	}
+	m := &fv1.TrafficMirror{}
+	if current != nil {
+		m = current.DeepCopy()
+	}
+	if input.IsSet(flagkey.MirrorVersion) {
+		m.Version, m.Alias = input.String(flagkey.MirrorVersion), ""
+	}
+	if input.IsSet(flagkey.MirrorAlias) {
+		m.Alias, m.Version = input.String(flagkey.MirrorAlias), ""
+	}
+	if input.IsSet(flagkey.MirrorPercent) {
+		m.Percent = input.Int(flagkey.MirrorPercent)
+	}
+	if m.Version == "" && m.Alias == "" {
+		return nil, fmt.Errorf("--%s or --%s is required to set a mirror", flagkey.MirrorVersion, flagkey.MirrorAlias)
+	}
+	if m.Percent == 0 {
+		return nil, fmt.Errorf("--%s is required to set a mirror", flagkey.MirrorPercent)
+	}
+	return m, m.Validate(field)
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/dummy"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+func TestGetMirrorConfig(t *testing.T) {
+	t.Parallel()
+	existing := &fv1.TrafficMirror{Version: "hello-v2", Percent: 10}
+
+	tests := []struct {
+		name     string
+		flags    []dummy.Flag
+		existing *fv1.TrafficMirror
+		want     *fv1.TrafficMirror
+		wantErr  bool
+	}{
+		{
+			name:     "no flags keeps the existing mirror",
+			existing: existing,
+			want:     existing,
+		},
+		{
+			name:  "create",
+			flags: []dummy.Flag{dummy.String(flagkey.MirrorAlias, "next"), dummy.Int(flagkey.MirrorPercent, 5)},
+			want:  &fv1.TrafficMirror{Alias: "next", Percent: 5},
+		},
+		{
+			name:     "percent keeps the target",
+			flags:    []dummy.Flag{dummy.Int(flagkey.MirrorPercent, 50)},
+			existing: existing,
+			want:     &fv1.TrafficMirror{Version: "hello-v2", Percent: 50},
+		},
+		{
+			name:     "alias replaces the version",
+			flags:    []dummy.Flag{dummy.String(flagkey.MirrorAlias, "next")},
+			existing: existing,
+			want:     &fv1.TrafficMirror{Alias: "next", Percent: 10},
+		},
+		{
+			name:     "zero percent removes the mirror",
+			flags:    []dummy.Flag{dummy.Int(flagkey.MirrorPercent, 0)},
+			existing: existing,
+		},
+		{
+			name:    "percent without a target",
+			flags:   []dummy.Flag{dummy.Int(flagkey.MirrorPercent, 5)},
+			wantErr: true,
+		},
+		{
+			name:    "target without a percent",
+			flags:   []dummy.Flag{dummy.String(flagkey.MirrorVersion, "hello-v2")},
+			wantErr: true,
+		},
+		{
+			name: "version and alias",
+			flags: []dummy.Flag{dummy.String(flagkey.MirrorVersion, "hello-v2"), dummy.String(flagkey.MirrorAlias, "next"),
+				dummy.Int(flagkey.MirrorPercent, 5)},
+			wantErr: true,
+		},
+		{
+			name:    "percent over 100",
+			flags:   []dummy.Flag{dummy.String(flagkey.MirrorVersion, "hello-v2"), dummy.Int(flagkey.MirrorPercent, 101)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := GetMirrorConfig(dummy.TestFlagSetWith(tt.flags...), tt.existing, "Mirror")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+// GetRateLimitConfig applies the --ratelimit-* flags to current (nil on
+// create), so an update that sets one flag keeps the others. Setting none
+// keeps current; --ratelimit-requests 0 removes the limit.
+func GetRateLimitConfig(input cli.Input, current *fv1.RateLimitConfig) (*fv1.RateLimitConfig, error) {
+	if !input.IsSet(flagkey.RateLimitRequests) && !input.IsSet(flagkey.RateLimitPeriod) &&
+		!input.IsSet(flagkey.RateLimitBurst) && !input.IsSet(flagkey.RateLimitKey) {
+		return current, nil
+	}
+	if input.IsSet(flagkey.RateLimitRequests) && input.Int(flagkey.RateLimitRequests) == 0 {
+		return nil, nil
+	}
+	rl := &fv1.RateLimitConfig{}
+	if current != nil {
+		rl = current.DeepCopy()
+	}
+	if input.IsSet(flagkey.RateLimitRequests) {
+		rl.Requests = int32(input.Int(flagkey.RateLimitRequests))
+	}
+	if input.IsSet(flagkey.RateLimitPeriod) {
+		rl.Period = &metav1.Duration{Duration: input.Duration(flagkey.RateLimitPeriod)}
+	}
+	if input.IsSet(flagkey.RateLimitBurst) {
+		rl.Burst = int32(input.Int(flagkey.RateLimitBurst))
+	}
+	if input.IsSet(flagkey.RateLimitKey) {
+		key, err := parseRateLimitKey(input.String(flagkey.RateLimitKey))
+		if err != nil {
+			return nil, err
+		}
+		rl.Key = key
+	}
+	if rl.Requests == 0 {
+		return nil, fmt.Errorf("--%s is required to set a rate limit", flagkey.RateLimitRequests)
+	}
+	return rl, rl.Validate("RateLimit")
+}
+
+// parseRateLimitKey parses clientip, header:<name> or jwtclaim:<claim>; ""
+// means one shared bucket.
+func parseRateLimitKey(s string) (*fv1.RateLimitKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	source, name, _ := strings.Cut(s, ":")
+	key := &fv1.RateLimitKey{Source: fv1.RateLimitKeySource(source), Name: name}
+	switch key.Source {
+	case fv1.RateLimitKeyClientIP:
+		if name != "" {
+			return nil, fmt.Errorf("--%s clientip takes no name, got %q", flagkey.RateLimitKey, s)
+		}
+	case fv1.RateLimitKeyHeader, fv1.RateLimitKeyJWTClaim:
+		if name == "" {
+			return nil, fmt.Errorf("--%s %s needs a name: %s:<name>", flagkey.RateLimitKey, source, source)
+		}
+	default:
+		return nil, fmt.Errorf("--%s must be clientip, header:<name> or jwtclaim:<claim>, got %q", flagkey.RateLimitKey, s)
+	}
+	return key, nil
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/dummy"
+	flagkey "github.com/fission/fission/pkg/fission-cli/flag/key"
+)
+
+func TestGetRateLimitConfig(t *testing.T) {
+	t.Parallel()
+	existing := &fv1.RateLimitConfig{Requests: 10, Burst: 20, Key: &fv1.RateLimitKey{Source: fv1.RateLimitKeyClientIP}}
+
+	tests := []struct {
+		name     string
+		flags    []dummy.Flag
+		period   time.Duration
+		existing *fv1.RateLimitConfig
+		want     *fv1.RateLimitConfig
+		wantErr  bool
+	}{
+		{
+			name:     "no flags keeps the existing limit",
+			existing: existing,
+			want:     existing,
+		},
+		{
+			name:  "create",
+			flags: []dummy.Flag{dummy.Int(flagkey.RateLimitRequests, 5), dummy.String(flagkey.RateLimitKey, "header:X-Api-Key")},
+			want:  &fv1.RateLimitConfig{Requests: 5, Key: &fv1.RateLimitKey{Source: fv1.RateLimitKeyHeader, Name: "X-Api-Key"}},
+		},
+		{
+			name:     "update merges",
+			flags:    []dummy.Flag{dummy.Int(flagkey.RateLimitBurst, 50)},
+			period:   time.Minute,
+			existing: existing,
+			want: &fv1.RateLimitConfig{Requests: 10, Burst: 50, Period: &metav1.Duration{Duration: time.Minute},
+				Key: &fv1.RateLimitKey{Source: fv1.RateLimitKeyClientIP}},
+		},
+		{
+			name:     "empty key shares one bucket",
+			flags:    []dummy.Flag{dummy.String(flagkey.RateLimitKey, "")},
+			existing: existing,
+			want:     &fv1.RateLimitConfig{Requests: 10, Burst: 20},
+		},
+		{
+			name:     "zero requests removes the limit",
+			flags:    []dummy.Flag{dummy.Int(flagkey.RateLimitRequests, 0)},
+			existing: existing,
+		},
+		{
+			name:    "burst without requests",
+			flags:   []dummy.Flag{dummy.Int(flagkey.RateLimitBurst, 5)},
+			wantErr: true,
+		},
+		{
+			name:    "unknown key source",
+			flags:   []dummy.Flag{dummy.Int(flagkey.RateLimitRequests, 5), dummy.String(flagkey.RateLimitKey, "cookie:sid")},
+			wantErr: true,
+		},
+		{
+			name:    "claim without a name",
+			flags:   []dummy.Flag{dummy.Int(flagkey.RateLimitRequests, 5), dummy.String(flagkey.RateLimitKey, "jwtclaim")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			in := dummy.TestFlagSetWith(tt.flags...)
+			if tt.period != 0 {
+				in.SetDuration(flagkey.RateLimitPeriod, tt.period)
+			}
+			got, err := GetRateLimitConfig(in, tt.existing)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -33,13 +33,25 @@ type updatableClient[T any] interface {
 // (last-write-wins); the common conflict — a controller status write racing a
 // CLI spec write — touches a different field and is resolved cleanly.
 func UpdateOnConflict[T any](ctx context.Context, c updatableClient[T], name string, mutate func(T)) (T, error) {
+	return TryUpdateOnConflict(ctx, c, name, func(cur T) error {
+		mutate(cur)
+		return nil
+	})
+}
+
+// TryUpdateOnConflict is UpdateOnConflict for a mutate that can reject the
+// fetched object, e.g. when a flag only makes sense given its current state.
+// An error from mutate is returned as is, without updating.
+func TryUpdateOnConflict[T any](ctx context.Context, c updatableClient[T], name string, mutate func(T) error) (T, error) {
 	var out T
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		cur, err := c.Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			return err
 		}
-		mutate(cur)
+		if err := mutate(cur); err != nil {
+			return err
+		}
 		out, err = c.Update(ctx, cur, metav1.UpdateOptions{})
 		return err
 	})
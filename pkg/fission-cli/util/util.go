@@ -253,6 +253,20 @@ func GetStateSvcURL(ctx context.Context, cmdClient cmd.Client) (*url.URL, error)
 	return url.Parse(fmt.Sprintf("%s%s", localhostURL, localPort))
 }
 
+// GetStatestoreURL locates the embedded statestore's capability API for the
+// `fission statestore` admin commands: FISSION_STATESTORE_URL first, else an
+// in-process port-forward to a statestore pod.
+func GetStatestoreURL(ctx context.Context, cmdClient cmd.Client) (*url.URL, error) {
+	if u := os.Getenv("FISSION_STATESTORE_URL"); u != "" {
+		return url.Parse(u)
+	}
+	localPort, err := SetupPortForwardToPort(ctx, cmdClient, GetFissionNamespace(), "application=fission-statestore", svcinfo.PortStatestore)
+	if err != nil {
+		return nil, err
+	}
+	return url.Parse(fmt.Sprintf("%s%s", localhostURL, localPort))
+}
+
 func GetResourceReqs(input cli.Input, resReqs *v1.ResourceRequirements) (*v1.ResourceRequirements, error) {
 	r := &v1.ResourceRequirements{}
 
@@ -21,6 +21,16 @@ type DestinationRefApplyConfiguration struct {
 	Function *FunctionReferenceApplyConfiguration `json:"function,omitempty"`
 	// Topic publishes the result envelope to a message-queue topic.
 	Topic *TopicRefApplyConfiguration `json:"topic,omitempty"`
+	// Webhook POSTs the result envelope to an external HTTPS endpoint.
+	// Deliveries that keep failing are dead-lettered to the webhook queue
+	// (`fission fn dlq list --queue webhook`), recorded against this
+	// destination.
+	Webhook *WebhookDestinationApplyConfiguration `json:"webhook,omitempty"`
+	// CloudEvents wraps the result envelope in a CloudEvent of type
+	// io.fission.async.success or io.fission.async.failure, with an id
+	// derived from the invocation so a consumer can deduplicate. A function
+	// destination receives it in binary mode, a topic in structured mode.
+	CloudEvents *bool `json:"cloudEvents,omitempty"`
 }
 
 // DestinationRefApplyConfiguration constructs a declarative configuration of the DestinationRef type for use with
@@ -44,3 +54,19 @@ func (b *DestinationRefApplyConfiguration) WithTopic(value *TopicRefApplyConfigu
 	b.Topic = value
 	return b
 }
+
+// WithWebhook sets the Webhook field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Webhook field is set to the value of the last call.
+func (b *DestinationRefApplyConfiguration) WithWebhook(value *WebhookDestinationApplyConfiguration) *DestinationRefApplyConfiguration {
+	b.Webhook = value
+	return b
+}
+
+// WithCloudEvents sets the CloudEvents field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CloudEvents field is set to the value of the last call.
+func (b *DestinationRefApplyConfiguration) WithCloudEvents(value bool) *DestinationRefApplyConfiguration {
+	b.CloudEvents = &value
+	return b
+}
@@ -21,6 +21,10 @@ type FunctionAliasSpecApplyConfiguration struct {
 	Weight *int `json:"weight,omitempty"`
 	// SecondaryVersion receives 100-Weight. Name-pinned only.
 	SecondaryVersion *string `json:"secondaryVersion,omitempty"`
+	// Mirror copies a sampled share of the requests of every HTTPTrigger
+	// resolving through this alias to a shadow version, e.g. the next
+	// release before it takes any weight. A trigger's own Mirror wins.
+	Mirror *TrafficMirrorApplyConfiguration `json:"mirror,omitempty"`
 }
 
 // FunctionAliasSpecApplyConfiguration constructs a declarative configuration of the FunctionAliasSpec type for use with
@@ -68,3 +72,11 @@ func (b *FunctionAliasSpecApplyConfiguration) WithSecondaryVersion(value string)
 	b.SecondaryVersion = &value
 	return b
 }
+
+// WithMirror sets the Mirror field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mirror field is set to the value of the last call.
+func (b *FunctionAliasSpecApplyConfiguration) WithMirror(value *TrafficMirrorApplyConfiguration) *FunctionAliasSpecApplyConfiguration {
+	b.Mirror = value
+	return b
+}
@@ -97,6 +97,24 @@ type FunctionSpecApplyConfiguration struct {
 	// dead-lettered. A function without it still accepts async mode with platform
 	// defaults; this field only tunes them. Additive and backward compatible.
 	Invocation *InvocationConfigApplyConfiguration `json:"invocation,omitempty"`
+	// RateLimit, when non-nil, is the default request-rate limit for the
+	// HTTPTriggers that route to this function. One set of buckets is shared
+	// by every such trigger; a trigger with its own RateLimit uses that
+	// instead. Requests on the router's internal listener (message queue
+	// triggers, async delivery) are not limited.
+	RateLimit *RateLimitConfigApplyConfiguration `json:"rateLimit,omitempty"`
+	// CircuitBreaker, when non-nil, has the router stop sending requests
+	// to this function while it is failing: once failures cross a
+	// threshold the circuit opens and requests are answered 503 with a
+	// Retry-After header, until probe requests find the function healthy
+	// again. Each published version has its own circuit. Each router
+	// replica keeps its own.
+	CircuitBreaker *CircuitBreakerConfigApplyConfiguration `json:"circuitBreaker,omitempty"`
+	// OutlierEjection, when non-nil, has the router stop sending requests
+	// to a single pod of this function that keeps failing while its other
+	// pods serve. Applies to pods the router admits requests to directly
+	// (the EndpointSlice-fed warm path).
+	OutlierEjection *OutlierEjectionConfigApplyConfiguration `json:"outlierEjection,omitempty"`
 	// Maximum number of pods to be specialized which will serve requests
 	// This is optional. If not specified default value will be taken as 500
 	Concurrency *int `json:"concurrency,omitempty"`
@@ -259,6 +277,30 @@ func (b *FunctionSpecApplyConfiguration) WithInvocation(value *InvocationConfigA
 	return b
 }
 
+// WithRateLimit sets the RateLimit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RateLimit field is set to the value of the last call.
+func (b *FunctionSpecApplyConfiguration) WithRateLimit(value *RateLimitConfigApplyConfiguration) *FunctionSpecApplyConfiguration {
+	b.RateLimit = value
+	return b
+}
+
+// WithCircuitBreaker sets the CircuitBreaker field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CircuitBreaker field is set to the value of the last call.
+func (b *FunctionSpecApplyConfiguration) WithCircuitBreaker(value *CircuitBreakerConfigApplyConfiguration) *FunctionSpecApplyConfiguration {
+	b.CircuitBreaker = value
+	return b
+}
+
+// WithOutlierEjection sets the OutlierEjection field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the OutlierEjection field is set to the value of the last call.
+func (b *FunctionSpecApplyConfiguration) WithOutlierEjection(value *OutlierEjectionConfigApplyConfiguration) *FunctionSpecApplyConfiguration {
+	b.OutlierEjection = value
+	return b
+}
+
 // WithConcurrency sets the Concurrency field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Concurrency field is set to the value of the last call.
@@ -62,6 +62,39 @@ type HTTPTriggerSpecApplyConfiguration struct {
 	// allowlist specific origins for SPAs that legitimately
 	// call this trigger cross-origin.
 	CorsConfig *HTTPTriggerCorsConfigApplyConfiguration `json:"corsConfig,omitempty"`
+	// CloudEvents, when set, makes the router read requests to this trigger
+	// as CloudEvents 1.0. A binary-mode event is validated and passed on as
+	// it is; a structured-mode event is converted to binary mode, so the
+	// function always sees the context attributes as ce-* headers and the
+	// event data as the body. An invalid event is rejected with 400. nil
+	// leaves requests untouched.
+	CloudEvents *HTTPTriggerCloudEventsApplyConfiguration `json:"cloudEvents,omitempty"`
+	// RateLimit, when set, limits the request rate through this trigger,
+	// replacing the target function's RateLimit default.
+	RateLimit *RateLimitConfigApplyConfiguration `json:"rateLimit,omitempty"`
+	// Authorization, when set, admits only callers whose verified token
+	// carries the listed scopes and claims; others get 403. Tokens are
+	// verified by the router's authentication, so a trigger with
+	// Authorization answers 401 to every request while authentication is
+	// disabled.
+	Authorization *HTTPTriggerAuthorizationApplyConfiguration `json:"authorization,omitempty"`
+	// APIKey, when set, admits only requests that present one of the API
+	// keys held in a Secret; others get 401. It is checked independently
+	// of the router's token authentication.
+	APIKey *HTTPTriggerAPIKeyApplyConfiguration `json:"apiKey,omitempty"`
+	// Cache, when set, lets the router answer GET and HEAD requests
+	// through this trigger from the function's earlier responses instead
+	// of invoking it again.
+	Cache *HTTPTriggerCacheApplyConfiguration `json:"cache,omitempty"`
+	// Mirror, when set, copies a sampled share of the requests through
+	// this trigger to another version of its function. It takes
+	// precedence over the Mirror of a FunctionAlias the trigger
+	// references. Only name-type function references can be mirrored.
+	Mirror *TrafficMirrorApplyConfiguration `json:"mirror,omitempty"`
+	// Transform, when set, checks and reshapes requests through this
+	// trigger before they reach the function, and edits the headers of
+	// its responses.
+	Transform *HTTPTriggerTransformApplyConfiguration `json:"transform,omitempty"`
 }
 
 // HTTPTriggerSpecApplyConfiguration constructs a declarative configuration of the HTTPTriggerSpec type for use with
@@ -167,3 +200,59 @@ func (b *HTTPTriggerSpecApplyConfiguration) WithCorsConfig(value *HTTPTriggerCor
 	b.CorsConfig = value
 	return b
 }
+
+// WithCloudEvents sets the CloudEvents field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CloudEvents field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithCloudEvents(value *HTTPTriggerCloudEventsApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.CloudEvents = value
+	return b
+}
+
+// WithRateLimit sets the RateLimit field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RateLimit field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithRateLimit(value *RateLimitConfigApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.RateLimit = value
+	return b
+}
+
+// WithAuthorization sets the Authorization field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Authorization field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithAuthorization(value *HTTPTriggerAuthorizationApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.Authorization = value
+	return b
+}
+
+// WithAPIKey sets the APIKey field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the APIKey field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithAPIKey(value *HTTPTriggerAPIKeyApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.APIKey = value
+	return b
+}
+
+// WithCache sets the Cache field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Cache field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithCache(value *HTTPTriggerCacheApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.Cache = value
+	return b
+}
+
+// WithMirror sets the Mirror field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Mirror field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithMirror(value *TrafficMirrorApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.Mirror = value
+	return b
+}
+
+// WithTransform sets the Transform field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Transform field is set to the value of the last call.
+func (b *HTTPTriggerSpecApplyConfiguration) WithTransform(value *HTTPTriggerTransformApplyConfiguration) *HTTPTriggerSpecApplyConfiguration {
+	b.Transform = value
+	return b
+}
@@ -35,6 +35,17 @@ type InvocationConfigApplyConfiguration struct {
 	// the invocation permanently fails (a non-retryable 4xx, the retry budget
 	// spent, or MaxAge exceeded).
 	OnFailure *DestinationRefApplyConfiguration `json:"onFailure,omitempty"`
+	// MaxInFlight caps how many async deliveries to this function run at
+	// once, across all router replicas. An invocation over the cap stays
+	// queued without spending a retry attempt (it still ages toward
+	// MaxAge). nil means no cap. Must be >= 1 when set.
+	MaxInFlight *int32 `json:"maxInFlight,omitempty"`
+	// MaxDeliveriesPerSecond caps the rate at which async deliveries to this
+	// function start, across all router replicas, so a redriven backlog or a
+	// burst drains at a pace the function and its dependencies can take.
+	// Throttled invocations stay queued like MaxInFlight's. nil means no cap.
+	// Must be >= 1 when set.
+	MaxDeliveriesPerSecond *int32 `json:"maxDeliveriesPerSecond,omitempty"`
 }
 
 // InvocationConfigApplyConfiguration constructs a declarative configuration of the InvocationConfig type for use with
@@ -74,3 +85,19 @@ func (b *InvocationConfigApplyConfiguration) WithOnFailure(value *DestinationRef
 	b.OnFailure = value
 	return b
 }
+
+// WithMaxInFlight sets the MaxInFlight field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxInFlight field is set to the value of the last call.
+func (b *InvocationConfigApplyConfiguration) WithMaxInFlight(value int32) *InvocationConfigApplyConfiguration {
+	b.MaxInFlight = &value
+	return b
+}
+
+// WithMaxDeliveriesPerSecond sets the MaxDeliveriesPerSecond field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxDeliveriesPerSecond field is set to the value of the last call.
+func (b *InvocationConfigApplyConfiguration) WithMaxDeliveriesPerSecond(value int32) *InvocationConfigApplyConfiguration {
+	b.MaxDeliveriesPerSecond = &value
+	return b
+}
@@ -12,10 +12,40 @@ package v1
 // KubernetesWatchTriggerSpec defines spec of KuberenetesWatchTrigger
 type KubernetesWatchTriggerSpecApplyConfiguration struct {
 	Namespace *string `json:"namespace,omitempty"`
-	// Type of resource to watch (Pod, Service, etc.)
+	// Type of resource to watch (Pod, Service, etc.), the shorthand for the
+	// four built-in kinds the watcher has always supported. Use APIVersion
+	// and Kind for anything else.
 	Type *string `json:"type,omitempty"`
-	// Resource labels
+	// APIVersion and Kind select any namespaced resource by group, version
+	// and kind instead of Type: "apps/v1" Deployment, "v1" ConfigMap,
+	// "fission.io/v1" Function or a third-party CRD such as
+	// "cert-manager.io/v1" Certificate. The kind must be served by the API
+	// server when the trigger is admitted, and the kubewatcher resolves it
+	// through discovery each time the watch starts. Cluster-scoped kinds are
+	// refused: a watch never reaches past the trigger's namespace. Admission
+	// checks that whoever creates or updates the trigger may list and watch
+	// the kind in that namespace, and the kubewatcher needs the same access
+	// (kubewatcher.watchRules in the chart).
+	APIVersion *string `json:"apiVersion,omitempty"`
+	// Kind is the kind to watch together with APIVersion, e.g. Deployment.
+	Kind *string `json:"kind,omitempty"`
+	// Resource labels: only objects carrying all of them are watched.
 	LabelSelector map[string]string `json:"labelselector,omitempty"`
+	// FieldSelector narrows the watch server-side, e.g. "status.phase=Failed"
+	// for pods. The API server decides which fields a kind supports: only
+	// metadata.name and metadata.namespace for most custom resources, unless
+	// the CRD declares selectableFields.
+	FieldSelector *string `json:"fieldSelector,omitempty"`
+	// EventTypes lists the watch event types delivered to the function;
+	// empty delivers ADDED, MODIFIED and DELETED.
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// ChangedPaths, when set, delivers a MODIFIED event only if the value
+	// at one of these JSONPaths differs from the last version of the object
+	// the watch saw, e.g. ".spec.replicas" or
+	// `.status.conditions[?(@.type=="Ready")].status`. Status churn and
+	// resyncs that leave them alone are suppressed. ADDED and DELETED
+	// events are not compared.
+	ChangedPaths []string `json:"changedPaths,omitempty"`
 	// The reference to a function for kubewatcher to invoke with
 	// when receiving events.
 	FunctionReference *FunctionReferenceApplyConfiguration `json:"functionref,omitempty"`
@@ -43,6 +73,22 @@ func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithType(value string) *K
 	return b
 }
 
+// WithAPIVersion sets the APIVersion field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the APIVersion field is set to the value of the last call.
+func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithAPIVersion(value string) *KubernetesWatchTriggerSpecApplyConfiguration {
+	b.APIVersion = &value
+	return b
+}
+
+// WithKind sets the Kind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kind field is set to the value of the last call.
+func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithKind(value string) *KubernetesWatchTriggerSpecApplyConfiguration {
+	b.Kind = &value
+	return b
+}
+
 // WithLabelSelector puts the entries into the LabelSelector field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, the entries provided by each call will be put on the LabelSelector field,
@@ -57,6 +103,34 @@ func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithLabelSelector(entries
 	return b
 }
 
+// WithFieldSelector sets the FieldSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FieldSelector field is set to the value of the last call.
+func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithFieldSelector(value string) *KubernetesWatchTriggerSpecApplyConfiguration {
+	b.FieldSelector = &value
+	return b
+}
+
+// WithEventTypes adds the given value to the EventTypes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the EventTypes field.
+func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithEventTypes(values ...string) *KubernetesWatchTriggerSpecApplyConfiguration {
+	for i := range values {
+		b.EventTypes = append(b.EventTypes, values[i])
+	}
+	return b
+}
+
+// WithChangedPaths adds the given value to the ChangedPaths field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ChangedPaths field.
+func (b *KubernetesWatchTriggerSpecApplyConfiguration) WithChangedPaths(values ...string) *KubernetesWatchTriggerSpecApplyConfiguration {
+	for i := range values {
+		b.ChangedPaths = append(b.ChangedPaths, values[i])
+	}
+	return b
+}
+
 // WithFunctionReference sets the FunctionReference field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the FunctionReference field is set to the value of the last call.
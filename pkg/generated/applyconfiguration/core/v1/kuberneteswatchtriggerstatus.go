@@ -15,8 +15,13 @@ import (
 //
 // KubernetesWatchTriggerStatus describes the observed state of a KubernetesWatchTrigger.
 type KubernetesWatchTriggerStatusApplyConfiguration struct {
-	ObservedGeneration *int64                               `json:"observedGeneration,omitempty"`
-	Conditions         []metav1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
+	// DeliveredEvents counts the watch events sent to the function, and
+	// SuppressedEvents those dropped by EventTypes or ChangedPaths. Both
+	// are written by the kubewatcher every few seconds while events flow.
+	DeliveredEvents  *int64                               `json:"deliveredEvents,omitempty"`
+	SuppressedEvents *int64                               `json:"suppressedEvents,omitempty"`
+	Conditions       []metav1.ConditionApplyConfiguration `json:"conditions,omitempty"`
 }
 
 // KubernetesWatchTriggerStatusApplyConfiguration constructs a declarative configuration of the KubernetesWatchTriggerStatus type for use with
@@ -33,6 +38,22 @@ func (b *KubernetesWatchTriggerStatusApplyConfiguration) WithObservedGeneration(
 	return b
 }
 
+// WithDeliveredEvents sets the DeliveredEvents field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DeliveredEvents field is set to the value of the last call.
+func (b *KubernetesWatchTriggerStatusApplyConfiguration) WithDeliveredEvents(value int64) *KubernetesWatchTriggerStatusApplyConfiguration {
+	b.DeliveredEvents = &value
+	return b
+}
+
+// WithSuppressedEvents sets the SuppressedEvents field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SuppressedEvents field is set to the value of the last call.
+func (b *KubernetesWatchTriggerStatusApplyConfiguration) WithSuppressedEvents(value int64) *KubernetesWatchTriggerStatusApplyConfiguration {
+	b.SuppressedEvents = &value
+	return b
+}
+
 // WithConditions adds the given value to the Conditions field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, values provided by each call will be appended to the Conditions field.
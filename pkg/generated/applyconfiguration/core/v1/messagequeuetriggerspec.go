@@ -46,6 +46,26 @@ type MessageQueueTriggerSpecApplyConfiguration struct {
 	Secret *string `json:"secret,omitempty"`
 	// Kind of Message Queue Trigger to be created, by default its fission
 	MqtKind *string `json:"mqtkind,omitempty"`
+	// CloudEvents delivers each message to the function as a binary-mode
+	// CloudEvent, wrapping a plain message with an id derived from its
+	// position in the topic so redeliveries keep the same id, and publishes
+	// to ResponseTopic and ErrorTopic in structured mode. A message that is
+	// already a CloudEvent keeps its attributes. Not supported with mqtkind
+	// keda.
+	CloudEvents *bool `json:"cloudEvents,omitempty"`
+	// MessageGroupHeader names a message header whose value is the message
+	// group (FIFO key) of each message, e.g. a customer id. When set, each
+	// message is handed to the function as an async invocation (RFC-0024)
+	// in that group, sent with X-Fission-Message-Group: the trigger moves on
+	// once the router has durably accepted it, and the async dispatcher
+	// delivers each group's messages one at a time in topic order while
+	// different groups run in parallel. A message without the header is
+	// delivered async but unordered. The function's async invocation
+	// config, not MaxRetries, then governs delivery retries, and its
+	// onSuccess destination replaces ResponseTopic, which must be unset.
+	// Kafka only, where record headers are the message headers; not
+	// supported with mqtkind keda.
+	MessageGroupHeader *string `json:"messageGroupHeader,omitempty"`
 	// (Optional) Podspec allows modification of deployed runtime pod with Kubernetes PodSpec
 	// The merging logic is briefly described below and detailed MergePodSpec function
 	// - Volumes mounts and env variables for function and fetcher container are appended
@@ -180,6 +200,22 @@ func (b *MessageQueueTriggerSpecApplyConfiguration) WithMqtKind(value string) *M
 	return b
 }
 
+// WithCloudEvents sets the CloudEvents field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CloudEvents field is set to the value of the last call.
+func (b *MessageQueueTriggerSpecApplyConfiguration) WithCloudEvents(value bool) *MessageQueueTriggerSpecApplyConfiguration {
+	b.CloudEvents = &value
+	return b
+}
+
+// WithMessageGroupHeader sets the MessageGroupHeader field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MessageGroupHeader field is set to the value of the last call.
+func (b *MessageQueueTriggerSpecApplyConfiguration) WithMessageGroupHeader(value string) *MessageQueueTriggerSpecApplyConfiguration {
+	b.MessageGroupHeader = &value
+	return b
+}
+
 // WithPodSpec sets the PodSpec field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the PodSpec field is set to the value of the last call.
@@ -30,6 +30,39 @@ type TimeTriggerSpecApplyConfiguration struct {
 	// Subpath to trigger a specific route if function
 	// internally supports routing, (default: "/")
 	Subpath *string `json:"subpath,omitempty"`
+	// TimeZone is the IANA time zone name (e.g. "Europe/Berlin") the Cron
+	// schedule is evaluated in. Empty means the timer process's local zone
+	// (UTC in the default deployment). A CRON_TZ= prefix on Cron itself
+	// still takes precedence, as with the robfig/cron parser it feeds.
+	// The name is checked against the tz database by the timer, which
+	// surfaces an unknown zone as Scheduled=False / InvalidTimeZone.
+	TimeZone *string `json:"timeZone,omitempty"`
+	// StartingDeadlineSeconds is the catch-up window for a firing missed
+	// while the timer was not running (a restart, a leader handover). On
+	// (re)registration the most recent missed slot is fired once if it is
+	// no older than this many seconds; older slots are skipped. nil (the
+	// default) keeps the historic behavior: missed firings are lost.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// ConcurrencyPolicy decides what happens when a slot comes due while the
+	// previous invocation of this trigger is still running, mirroring the
+	// batch/v1 CronJob field: Allow runs them side by side, Forbid skips the
+	// new slot, Replace cancels the running invocation and starts the new one.
+	// Under the timer's durable delivery a running invocation cannot be cut
+	// short, so Replace cancels it only if it has not started yet, and
+	// otherwise runs the new slot after it.
+	ConcurrencyPolicy *corev1.TimeTriggerConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Body is the JSON request body sent on every firing, a Go text/template
+	// executed per firing with .TriggerName, .Namespace, .ScheduledTime (the
+	// cron slot) and .FireTime (when it actually fired; later on a catch-up),
+	// e.g. {"window":"hourly","slot":"{{ .ScheduledTime }}"}. Times print as
+	// RFC 3339 and keep time.Time's methods ({{ .ScheduledTime.Unix }}). It
+	// is sent with Content-Type application/json unless Headers sets one.
+	// Empty (the default) sends no body.
+	Body *string `json:"body,omitempty"`
+	// Headers are extra request headers sent on every firing. Values are
+	// templates executed like Body. X-Fission-Timer-Name is always set by the
+	// timer and overrides an entry of the same name.
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
 // TimeTriggerSpecApplyConfiguration constructs a declarative configuration of the TimeTriggerSpec type for use with
@@ -118,3 +151,49 @@ func (b *TimeTriggerSpecApplyConfiguration) WithSubpath(value string) *TimeTrigg
 	b.Subpath = &value
 	return b
 }
+
+// WithTimeZone sets the TimeZone field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TimeZone field is set to the value of the last call.
+func (b *TimeTriggerSpecApplyConfiguration) WithTimeZone(value string) *TimeTriggerSpecApplyConfiguration {
+	b.TimeZone = &value
+	return b
+}
+
+// WithStartingDeadlineSeconds sets the StartingDeadlineSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StartingDeadlineSeconds field is set to the value of the last call.
+func (b *TimeTriggerSpecApplyConfiguration) WithStartingDeadlineSeconds(value int64) *TimeTriggerSpecApplyConfiguration {
+	b.StartingDeadlineSeconds = &value
+	return b
+}
+
+// WithConcurrencyPolicy sets the ConcurrencyPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ConcurrencyPolicy field is set to the value of the last call.
+func (b *TimeTriggerSpecApplyConfiguration) WithConcurrencyPolicy(value corev1.TimeTriggerConcurrencyPolicy) *TimeTriggerSpecApplyConfiguration {
+	b.ConcurrencyPolicy = &value
+	return b
+}
+
+// WithBody sets the Body field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Body field is set to the value of the last call.
+func (b *TimeTriggerSpecApplyConfiguration) WithBody(value string) *TimeTriggerSpecApplyConfiguration {
+	b.Body = &value
+	return b
+}
+
+// WithHeaders puts the entries into the Headers field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Headers field,
+// overwriting an existing map entries in Headers field with the same key.
+func (b *TimeTriggerSpecApplyConfiguration) WithHeaders(entries map[string]string) *TimeTriggerSpecApplyConfiguration {
+	if b.Headers == nil && len(entries) > 0 {
+		b.Headers = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Headers[k] = v
+	}
+	return b
+}
@@ -7,7 +7,8 @@
 package v1
 
 import (
-	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	applyconfigurationsmetav1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // TimeTriggerStatusApplyConfiguration represents a declarative configuration of the TimeTriggerStatus type for use
@@ -15,8 +16,18 @@ import (
 //
 // TimeTriggerStatus describes the observed state of a TimeTrigger.
 type TimeTriggerStatusApplyConfiguration struct {
-	ObservedGeneration *int64                               `json:"observedGeneration,omitempty"`
-	Conditions         []metav1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	ObservedGeneration *int64 `json:"observedGeneration,omitempty"`
+	// LastScheduleTime is the schedule slot the trigger last fired for (the
+	// slot time, not the wall-clock moment the timer got to it). It is also
+	// the starting point for StartingDeadlineSeconds catch-up after a timer
+	// restart.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// NextScheduleTime is the next slot the timer has scheduled. A
+	// NextScheduleTime in the past means the timer is not running the
+	// trigger (or fell behind), so a missed schedule is visible without
+	// reading timer logs.
+	NextScheduleTime *metav1.Time                                            `json:"nextScheduleTime,omitempty"`
+	Conditions       []applyconfigurationsmetav1.ConditionApplyConfiguration `json:"conditions,omitempty"`
 }
 
 // TimeTriggerStatusApplyConfiguration constructs a declarative configuration of the TimeTriggerStatus type for use with
@@ -33,10 +44,26 @@ func (b *TimeTriggerStatusApplyConfiguration) WithObservedGeneration(value int64
 	return b
 }
 
+// WithLastScheduleTime sets the LastScheduleTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastScheduleTime field is set to the value of the last call.
+func (b *TimeTriggerStatusApplyConfiguration) WithLastScheduleTime(value metav1.Time) *TimeTriggerStatusApplyConfiguration {
+	b.LastScheduleTime = &value
+	return b
+}
+
+// WithNextScheduleTime sets the NextScheduleTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NextScheduleTime field is set to the value of the last call.
+func (b *TimeTriggerStatusApplyConfiguration) WithNextScheduleTime(value metav1.Time) *TimeTriggerStatusApplyConfiguration {
+	b.NextScheduleTime = &value
+	return b
+}
+
 // WithConditions adds the given value to the Conditions field in the declarative configuration
 // and returns the receiver, so that objects can be build by chaining "With" function invocations.
 // If called multiple times, values provided by each call will be appended to the Conditions field.
-func (b *TimeTriggerStatusApplyConfiguration) WithConditions(values ...*metav1.ConditionApplyConfiguration) *TimeTriggerStatusApplyConfiguration {
+func (b *TimeTriggerStatusApplyConfiguration) WithConditions(values ...*applyconfigurationsmetav1.ConditionApplyConfiguration) *TimeTriggerStatusApplyConfiguration {
 	for i := range values {
 		if values[i] == nil {
 			panic("nil value passed to WithConditions")
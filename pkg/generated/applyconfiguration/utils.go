@@ -34,6 +34,10 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &corev1.CanaryConfigStatusApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("Checksum"):
 		return &corev1.ChecksumApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("CircuitBreakerConfig"):
+		return &corev1.CircuitBreakerConfigApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("ClaimRequirement"):
+		return &corev1.ClaimRequirementApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("ConfigMapReference"):
 		return &corev1.ConfigMapReferenceApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("DestinationRef"):
@@ -78,14 +82,26 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &corev1.GatewayParentRefApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("GatewayRouteConfig"):
 		return &corev1.GatewayRouteConfigApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HeaderTransform"):
+		return &corev1.HeaderTransformApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("HTTPTrigger"):
 		return &corev1.HTTPTriggerApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HTTPTriggerAPIKey"):
+		return &corev1.HTTPTriggerAPIKeyApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HTTPTriggerAuthorization"):
+		return &corev1.HTTPTriggerAuthorizationApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HTTPTriggerCache"):
+		return &corev1.HTTPTriggerCacheApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HTTPTriggerCloudEvents"):
+		return &corev1.HTTPTriggerCloudEventsApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("HTTPTriggerCorsConfig"):
 		return &corev1.HTTPTriggerCorsConfigApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("HTTPTriggerSpec"):
 		return &corev1.HTTPTriggerSpecApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("HTTPTriggerStatus"):
 		return &corev1.HTTPTriggerStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("HTTPTriggerTransform"):
+		return &corev1.HTTPTriggerTransformApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("IngressConfig"):
 		return &corev1.IngressConfigApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("InvocationConfig"):
@@ -106,6 +122,8 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &corev1.MessageQueueTriggerStatusApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("OCIArchive"):
 		return &corev1.OCIArchiveApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("OutlierEjectionConfig"):
+		return &corev1.OutlierEjectionConfigApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("Package"):
 		return &corev1.PackageApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("PackageRef"):
@@ -114,10 +132,16 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &corev1.PackageSpecApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("PackageStatus"):
 		return &corev1.PackageStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("ParameterMapping"):
+		return &corev1.ParameterMappingApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("ProvisionedConcurrencyConfig"):
 		return &corev1.ProvisionedConcurrencyConfigApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("ProvisionedWindow"):
 		return &corev1.ProvisionedWindowApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("RateLimitConfig"):
+		return &corev1.RateLimitConfigApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("RateLimitKey"):
+		return &corev1.RateLimitKeyApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("RetryPolicy"):
 		return &corev1.RetryPolicyApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("RouteConfig"):
@@ -142,8 +166,14 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &corev1.ToolConfigApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("TopicRef"):
 		return &corev1.TopicRefApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("TrafficMirror"):
+		return &corev1.TrafficMirrorApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("VersioningConfig"):
 		return &corev1.VersioningConfigApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("WebhookDestination"):
+		return &corev1.WebhookDestinationApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("WebhookSigningSecret"):
+		return &corev1.WebhookSigningSecretApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("Workflow"):
 		return &corev1.WorkflowApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("WorkflowBranch"):
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubewatcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/kubewatcher/watchlog"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// durableKeyspace holds each trigger's forward cursor and watch position.
+	durableKeyspace = "kubewatch"
+
+	// forwardBatch is how many events one stream read forwards;
+	// forwardRetryInterval is how often a backlog the store refused is retried
+	// when no new event wakes the forwarder.
+	forwardBatch         = 32
+	forwardRetryInterval = 5 * time.Second
+
+	// replayRetention is how many forwarded events a stream keeps for
+	// `fission watch replay`; older ones are trimmed.
+	replayRetention = 1000
+
+	// appendAttempts / appendBackoff bound how long the dispatch loop retries
+	// an unreachable store before an event is reported lost.
+	appendAttempts = 5
+	appendBackoff  = 500 * time.Millisecond
+)
+
+// durableSink is the kubewatcher's durable delivery path. Each delivered
+// event is appended to the trigger's statestore EventLog stream (see
+// watchlog) as a ready-to-enqueue async envelope, and a per-subscription
+// forwarder moves the stream onto the router's RFC-0024 async queue behind a
+// cursor, so retries, the dead-letter queue and `fission fn dlq` redrive are
+// the async dispatcher's. The stream outlives delivery by replayRetention
+// events, which is what `fission watch replay` re-enqueues from.
+//
+// Delivery is at least once: a forward retried after an ambiguous store
+// error is collapsed by the message DedupKey, and a restart resumes the watch
+// from the last appended object so events are neither lost nor replayed
+// wholesale, but an event appended just before a crash may be appended twice.
+type durableSink struct {
+	eventLog  statestore.EventLog
+	queue     statestore.Queue
+	kv        statestore.KVStore
+	queueName string
+	// resolveFn stamps the target function's InvocationConfig into each
+	// envelope at append time, exactly as a router enqueue does.
+	resolveFn asyncinvoke.FunctionConfigResolver
+	now       func() time.Time
+}
+
+// watchPosition is the resourceVersion of the last object appended for a
+// trigger, recorded with the generation it was watched under.
+type watchPosition struct {
+	Generation      int64  `json:"generation"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// durableScope is the KV scope of a trigger's cursor and position.
+func durableScope(w *fv1.KubernetesWatchTrigger) statestore.Scope {
+	return statestore.Scope{
+		Namespace: w.Namespace,
+		Owner:     "kuberneteswatchtrigger/" + w.Name,
+		Keyspace:  durableKeyspace,
+	}
+}
+
+func cursorKey(w *fv1.KubernetesWatchTrigger) string   { return "cursor/" + string(w.UID) }
+func positionKey(w *fv1.KubernetesWatchTrigger) string { return "position/" + string(w.UID) }
+
+// eventEnvelope builds the async envelope that invokes w's function with one
+// serialized event. An alias pin rides the function route name, a version pin
+// rides FunctionVersion, as the timer's durable slots do.
+func eventEnvelope(w *fv1.KubernetesWatchTrigger, cfg asyncinvoke.FunctionConfig, body []byte, headers map[string]string, now time.Time) asyncinvoke.Envelope {
+	ref := w.Spec.FunctionReference
+	function := ref.Name
+	if ref.Version == "" && ref.Alias != "" {
+		function += ":" + ref.Alias
+	}
+	return asyncinvoke.Envelope{
+		Version:         asyncinvoke.EnvelopeVersion,
+		Namespace:       w.Namespace,
+		Function:        function,
+		FunctionVersion: ref.Version,
+		Method:          http.MethodPost,
+		Headers:         headers,
+		Body:            body,
+		EnqueueTime:     now,
+		FunctionTimeout: cfg.FunctionTimeout,
+		Policy:          cfg.Policy,
+		OnSuccess:       cfg.OnSuccess,
+		OnFailure:       cfg.OnFailure,
+	}
+}
+
+// append appends one event for w and records rv as the watch position,
+// returning the event's sequence number.
+func (s *durableSink) append(ctx context.Context, w *fv1.KubernetesWatchTrigger, eventType string, body []byte, headers map[string]string, rv string) (int64, error) {
+	var cfg asyncinvoke.FunctionConfig
+	if s.resolveFn != nil {
+		cfg, _ = s.resolveFn(ctx, w.Namespace, w.Spec.FunctionReference.Name)
+	}
+	data, err := eventEnvelope(w, cfg, body, headers, s.now()).Encode()
+	if err != nil {
+		return 0, fmt.Errorf("encoding event: %w", err)
+	}
+	seq, err := s.eventLog.Append(ctx, watchlog.Stream(w.Namespace, w.Name, w.UID), statestore.AppendAny,
+		[]statestore.Event{{Type: eventType, Payload: data}})
+	if err != nil {
+		return 0, fmt.Errorf("appending event: %w", err)
+	}
+	if rv != "" {
+		// Best effort: a stale position only means a restart redelivers the
+		// events after it.
+		pos, _ := json.Marshal(watchPosition{Generation: w.Generation, ResourceVersion: rv})
+		_ = s.kv.Set(ctx, durableScope(w), positionKey(w), pos, statestore.SetOptions{})
+	}
+	return seq, nil
+}
+
+// position returns the resourceVersion to resume w's watch from, or "" to
+// start afresh: nothing was appended yet, or the spec changed since (a
+// different selector or kind must not resume from the old watch's position).
+func (s *durableSink) position(ctx context.Context, w *fv1.KubernetesWatchTrigger) string {
+	v, err := s.kv.Get(ctx, durableScope(w), positionKey(w))
+	if err != nil {
+		return ""
+	}
+	var pos watchPosition
+	if json.Unmarshal(v.Data, &pos) != nil || pos.Generation != w.Generation {
+		return ""
+	}
+	return pos.ResourceVersion
+}
+
+// forward enqueues w's events past the cursor onto the async queue, advancing
+// the cursor event by event, then trims the stream to replayRetention events
+// behind it. It returns how many events were enqueued.
+func (s *durableSink) forward(ctx context.Context, w *fv1.KubernetesWatchTrigger) (int, error) {
+	stream := watchlog.Stream(w.Namespace, w.Name, w.UID)
+	cursor, err := s.cursor(ctx, w)
+	if err != nil {
+		return 0, err
+	}
+	forwarded := 0
+	for {
+		events, err := s.eventLog.Read(ctx, stream, cursor, forwardBatch)
+		if err != nil {
+			return forwarded, fmt.Errorf("reading events: %w", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+		for _, ev := range events {
+			env, err := watchlog.Envelope(ev, time.Time{})
+			var data []byte
+			if err == nil {
+				data, err = env.Encode()
+			}
+			// An undecodable event can never be delivered; skip it rather
+			// than wedge the stream behind it.
+			if err == nil {
+				_, err = s.queue.Enqueue(ctx, s.queueName, statestore.Message{Body: data},
+					statestore.EnqueueOptions{DedupKey: watchlog.DedupKey(w.UID, ev.Seq)})
+				if err != nil {
+					return forwarded, fmt.Errorf("enqueuing event %d: %w", ev.Seq, err)
+				}
+				forwarded++
+			}
+			cursor = ev.Seq
+			if err := s.kv.Set(ctx, durableScope(w), cursorKey(w), []byte(strconv.FormatInt(cursor, 10)), statestore.SetOptions{}); err != nil {
+				return forwarded, fmt.Errorf("saving cursor: %w", err)
+			}
+		}
+	}
+	if cursor > replayRetention {
+		if err := s.eventLog.Trim(ctx, stream, cursor-replayRetention+1); err != nil {
+			return forwarded, fmt.Errorf("trimming stream: %w", err)
+		}
+	}
+	return forwarded, nil
+}
+
+// cursor returns the sequence number of the last event forwarded for w.
+func (s *durableSink) cursor(ctx context.Context, w *fv1.KubernetesWatchTrigger) (int64, error) {
+	v, err := s.kv.Get(ctx, durableScope(w), cursorKey(w))
+	if errors.Is(err, statestore.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("loading cursor: %w", err)
+	}
+	cursor, err := strconv.ParseInt(string(v.Data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing cursor %q: %w", v.Data, err)
+	}
+	return cursor, nil
+}
+
+// forget drops a deleted trigger's stream, cursor and position.
+func (s *durableSink) forget(ctx context.Context, w *fv1.KubernetesWatchTrigger) error {
+	stream := watchlog.Stream(w.Namespace, w.Name, w.UID)
+	head, err := s.eventLog.Head(ctx, stream)
+	if err != nil {
+		return err
+	}
+	if head > 0 {
+		if err := s.eventLog.Trim(ctx, stream, head+1); err != nil {
+			return err
+		}
+	}
+	return errors.Join(
+		s.kv.Delete(ctx, durableScope(w), cursorKey(w), 0),
+		s.kv.Delete(ctx, durableScope(w), positionKey(w), 0),
+	)
+}
+
+// appendDurable appends a delivered event, retrying an unreachable store with
+// a bounded doubling backoff, and wakes the forwarder. An event that still
+// cannot be appended is lost and logged at error level.
+func (ws *watchSubscription) appendDurable(ctx context.Context, eventType string, body []byte, headers map[string]string, rv string) {
+	delay := appendBackoff
+	var err error
+	for attempt := 1; attempt <= appendAttempts; attempt++ {
+		actx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		_, err = ws.durable.append(actx, &ws.watch, eventType, body, headers, rv)
+		cancel()
+		if err == nil {
+			ws.delivered.Add(1)
+			select {
+			case ws.wake <- struct{}{}:
+			default:
+			}
+			return
+		}
+		if attempt < appendAttempts && !ws.sleep(ctx, delay) {
+			ws.logger.Info("watch stopping; abandoning watch event", "watch_name", ws.watch.Name, "event_type", eventType, "error", err)
+			return
+		}
+		delay *= 2
+	}
+	ws.logger.Error(err, "appending watch event failed, giving up", "watch_name", ws.watch.Name, "event_type", eventType)
+}
+
+// sleep waits d, or returns false when ctx ends or the subscription stops
+// first.
+func (ws *watchSubscription) sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ws.done:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// forwardLoop runs the subscription's forwarder until it stops: once at start,
+// which picks up a backlog left by a previous leader or a crash, then on
+// every appended event, and after interval while the store refuses a backlog.
+func (ws *watchSubscription) forwardLoop(ctx context.Context, interval time.Duration) {
+	for {
+		var retry <-chan time.Time
+		if n, err := ws.durable.forward(ctx, &ws.watch); err != nil {
+			ws.logger.Error(err, "forwarding watch events failed, will retry", "watch_name", ws.watch.Name, "forwarded", n)
+			retry = time.After(interval)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ws.done:
+			return
+		case <-ws.wake:
+		case <-retry:
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubewatcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/kubewatcher/watchlog"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+	// Register the in-memory driver for the durable delivery tests.
+	_ "github.com/fission/fission/pkg/statestore/memory"
+)
+
+func memDurableSink(t *testing.T) *durableSink {
+	t.Helper()
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	el, err := caps.EventLog()
+	require.NoError(t, err)
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	return &durableSink{eventLog: el, queue: q, kv: kv, queueName: asyncinvoke.DefaultQueue, now: time.Now}
+}
+
+func durableTrigger() *fv1.KubernetesWatchTrigger {
+	return &fv1.KubernetesWatchTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "kwt", Namespace: "ns", UID: "uid-1", Generation: 1},
+		Spec: fv1.KubernetesWatchTriggerSpec{
+			Type:              "POD",
+			FunctionReference: fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "fn", Alias: "live"},
+		},
+	}
+}
+
+// leaseAll leases every visible message on the async queue and decodes it.
+func leaseAll(t *testing.T, s *durableSink) []asyncinvoke.Envelope {
+	t.Helper()
+	msgs, err := s.queue.Lease(t.Context(), s.queueName, 100, time.Minute)
+	require.NoError(t, err)
+	envs := make([]asyncinvoke.Envelope, 0, len(msgs))
+	for _, m := range msgs {
+		env, err := asyncinvoke.Decode(m.Body)
+		require.NoError(t, err)
+		envs = append(envs, env)
+	}
+	return envs
+}
+
+// failingEnqueue fails every enqueue, standing in for an unreachable store.
+type failingEnqueue struct{ statestore.Queue }
+
+func (failingEnqueue) Enqueue(context.Context, string, statestore.Message, statestore.EnqueueOptions) (string, error) {
+	return "", errors.New("store unavailable")
+}
+
+// failingAppend fails every append, standing in for an unreachable store.
+type failingAppend struct{ statestore.EventLog }
+
+func (failingAppend) Append(context.Context, string, int64, []statestore.Event) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+// TestAppendDurable_StopsWithSubscription pins that the append backoff ends
+// when the subscription stops instead of sleeping out every attempt.
+func TestAppendDurable_StopsWithSubscription(t *testing.T) {
+	t.Parallel()
+	s := memDurableSink(t)
+	s.eventLog = failingAppend{s.eventLog}
+	ws := &watchSubscription{logger: logr.Discard(), watch: *durableTrigger(), durable: s, done: make(chan struct{}), wake: make(chan struct{}, 1)}
+
+	returned := make(chan struct{})
+	go func() {
+		ws.appendDurable(t.Context(), "ADDED", []byte(`{}`), nil, "10")
+		close(returned)
+	}()
+	close(ws.done)
+	select {
+	case <-returned:
+	case <-time.After(appendBackoff * 4):
+		t.Fatal("appendDurable kept retrying after the subscription stopped")
+	}
+	assert.Zero(t, ws.delivered.Load())
+}
+
+func TestDurableSink_AppendAndForward(t *testing.T) {
+	t.Parallel()
+	s := memDurableSink(t)
+	w := durableTrigger()
+	ctx := t.Context()
+
+	for i, rv := range []string{"10", "11"} {
+		seq, err := s.append(ctx, w, "ADDED", []byte(`{"n":1}`), map[string]string{"X-Kubernetes-Event-Type": "ADDED"}, rv)
+		require.NoError(t, err)
+		assert.EqualValues(t, i+1, seq)
+	}
+	assert.Equal(t, "11", s.position(ctx, w))
+
+	// A store outage leaves the cursor where it was; nothing is skipped.
+	live := s.queue
+	s.queue = failingEnqueue{live}
+	n, err := s.forward(ctx, w)
+	require.Error(t, err)
+	assert.Zero(t, n)
+	s.queue = live
+
+	n, err = s.forward(ctx, w)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	envs := leaseAll(t, s)
+	require.Len(t, envs, 2)
+	for i, env := range envs {
+		assert.Equal(t, "ns", env.Namespace)
+		assert.Equal(t, "fn:live", env.Function)
+		assert.Equal(t, []byte(`{"n":1}`), env.Body)
+		assert.Equal(t, "ADDED", env.Headers["X-Kubernetes-Event-Type"])
+		assert.Equal(t, []string{"1", "2"}[i], env.Headers[watchlog.HeaderSequence])
+		assert.Empty(t, env.Headers[watchlog.HeaderReplay])
+	}
+
+	// Forwarded events are not forwarded again, but stay in the stream.
+	n, err = s.forward(ctx, w)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	events, err := s.eventLog.Read(ctx, watchlog.Stream("ns", "kwt", "uid-1"), 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// A spec change starts the watch afresh.
+	w.Generation = 2
+	assert.Empty(t, s.position(ctx, w))
+
+	require.NoError(t, s.forget(ctx, w))
+	events, err = s.eventLog.Read(ctx, watchlog.Stream("ns", "kwt", "uid-1"), 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	cursor, err := s.cursor(ctx, w)
+	require.NoError(t, err)
+	assert.Zero(t, cursor)
+}
+
+// TestKubeWatcher_DurableDelivery runs events through a subscription with
+// durable delivery: they reach the async queue instead of the publisher, and
+// a restarted subscription resumes the watch from the last appended object.
+func TestKubeWatcher_DurableDelivery(t *testing.T) {
+	t.Parallel()
+	s := memDurableSink(t)
+	w := durableTrigger()
+
+	kc := kubefake.NewClientset()
+	fw := watch.NewFakeWithChanSize(10, false)
+	var resumedFrom string
+	kc.PrependWatchReactor("pods", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		resumedFrom = action.(clienttesting.WatchAction).GetWatchRestrictions().ResourceVersion
+		return true, fw, nil
+	})
+	pub := &recordingPublisher{}
+	kw := MakeKubeWatcher(t.Context(), logr.Discard(), kc, nil, nil, nil, pub)
+	kw.durable = s
+	require.NoError(t, kw.addWatch(t.Context(), w))
+	assert.Empty(t, resumedFrom)
+
+	fw.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "ns", UID: "pod-uid", ResourceVersion: "42"}})
+	var envs []asyncinvoke.Envelope
+	require.Eventually(t, func() bool {
+		envs = append(envs, leaseAll(t, s)...)
+		return len(envs) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "ADDED", envs[0].Headers["X-Kubernetes-Event-Type"])
+	assert.Equal(t, "1", envs[0].Headers[watchlog.HeaderSequence])
+	assert.Empty(t, pub.published())
+
+	// A new leader (or a restart) resumes after the appended object.
+	fw = watch.NewFakeWithChanSize(10, false)
+	kw2 := MakeKubeWatcher(t.Context(), logr.Discard(), kc, nil, nil, nil, pub)
+	kw2.durable = s
+	require.NoError(t, kw2.addWatch(t.Context(), w))
+	assert.Equal(t, "42", resumedFrom)
+	key := types.NamespacedName{Namespace: w.Namespace, Name: w.Name}
+	kw.watches[key].stop()
+	kw2.watches[key].stop()
+}
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package eventfilter decides which watch events a KubernetesWatchTrigger
+// delivers: an event-type allowlist and an "only when these JSONPaths changed"
+// comparison of each object against the last version the watch saw. It
+// imports nothing from this repo, so the API validation (pkg/apis/core/v1) and
+// the kubewatcher parse paths the same way: a path the webhook admits is
+// exactly one the kubewatcher can evaluate.
+package eventfilter
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// EventTypes are the watch event types a trigger can allowlist.
+var EventTypes = []watch.EventType{watch.Added, watch.Modified, watch.Deleted}
+
+// Filter is the per-watch filter state. It is not safe for concurrent use:
+// each watch subscription owns one and calls it from its dispatch loop.
+type Filter struct {
+	// types is the allowlist; nil allows every type.
+	types map[watch.EventType]bool
+	paths []*jsonpath.JSONPath
+	// seen holds a digest of each live object's path values. Digests keep
+	// the memory per object fixed however large the selected values are.
+	seen map[types.UID][sha256.Size]byte
+}
+
+// New builds a filter from a trigger's event-type allowlist and changed
+// paths. Both empty yields a filter that delivers everything.
+func New(eventTypes, changedPaths []string) (*Filter, error) {
+	f := &Filter{}
+	if len(eventTypes) > 0 {
+		f.types = make(map[watch.EventType]bool, len(eventTypes))
+		for _, t := range eventTypes {
+			et, err := ParseEventType(t)
+			if err != nil {
+				return nil, err
+			}
+			f.types[et] = true
+		}
+	}
+	for _, p := range changedPaths {
+		j, err := ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		f.paths = append(f.paths, j)
+	}
+	if len(f.paths) > 0 {
+		f.seen = map[types.UID][sha256.Size]byte{}
+	}
+	return f, nil
+}
+
+// ParseEventType accepts ADDED, MODIFIED or DELETED in any case.
+func ParseEventType(s string) (watch.EventType, error) {
+	for _, et := range EventTypes {
+		if strings.EqualFold(s, string(et)) {
+			return et, nil
+		}
+	}
+	return "", fmt.Errorf("event type %q: must be one of ADDED, MODIFIED, DELETED", s)
+}
+
+// ParsePath parses a changed-path expression. kubectl's relaxed forms are
+// accepted, so ".spec.replicas", "spec.replicas" and "{.spec.replicas}" all
+// select the same field. A path that matches nothing in an object evaluates
+// to nothing rather than failing.
+func ParsePath(p string) (*jsonpath.JSONPath, error) {
+	expr := strings.TrimSpace(p)
+	if expr == "" {
+		return nil, errors.New("changed path: must not be empty")
+	}
+	if !strings.HasPrefix(expr, "{") {
+		if !strings.HasPrefix(expr, ".") {
+			expr = "." + expr
+		}
+		expr = "{" + expr + "}"
+	}
+	j := jsonpath.New(p).AllowMissingKeys(true)
+	if err := j.Parse(expr); err != nil {
+		return nil, fmt.Errorf("changed path %q: %w", p, err)
+	}
+	return j, nil
+}
+
+// Deliver reports whether the event should reach the function. Every event
+// updates the filter's view of the object, suppressed or not, so a MODIFIED
+// is compared against the object's last state even when ADDED events are not
+// delivered. A MODIFIED for an object the filter has not seen yet (e.g. the
+// watch resumed from a resource version) is delivered: there is nothing to
+// compare it against.
+func (f *Filter) Deliver(ev watch.Event) bool {
+	changed := f.track(ev)
+	if f.types != nil && !f.types[ev.Type] {
+		return false
+	}
+	return ev.Type != watch.Modified || changed
+}
+
+// track records the object's path digest and reports whether it differs from
+// the previous one. Without changed paths every event counts as a change.
+func (f *Filter) track(ev watch.Event) bool {
+	if f.seen == nil {
+		return true
+	}
+	m, err := meta.Accessor(ev.Object)
+	if err != nil {
+		return true
+	}
+	uid := m.GetUID()
+	if ev.Type == watch.Deleted {
+		delete(f.seen, uid)
+		return true
+	}
+	sum, err := f.digest(ev.Object)
+	if err != nil {
+		return true
+	}
+	prev, ok := f.seen[uid]
+	f.seen[uid] = sum
+	return !ok || prev != sum
+}
+
+func (f *Filter) digest(obj runtime.Object) ([sha256.Size]byte, error) {
+	var data map[string]any
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		data = u.Object
+	} else {
+		var err error
+		if data, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return [sha256.Size]byte{}, err
+		}
+	}
+	values := make([][]any, len(f.paths))
+	for i, j := range f.paths {
+		results, err := j.FindResults(data)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		for _, r := range results {
+			for _, v := range r {
+				values[i] = append(values[i], v.Interface())
+			}
+		}
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(b), nil
+}
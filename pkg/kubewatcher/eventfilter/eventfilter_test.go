@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package eventfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func deployment(uid types.UID, replicas int32, ready int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{UID: uid, Name: string(uid)},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestDeliverChangedPaths(t *testing.T) {
+	t.Parallel()
+	f, err := New(nil, []string{".spec.replicas", "metadata.labels"})
+	require.NoError(t, err)
+
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Added, Object: deployment("a", 1, 0)}))
+	// Status churn leaves the selected paths alone.
+	assert.False(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 1, 1)}))
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 3, 1)}))
+	assert.False(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 3, 3)}))
+
+	// A label appearing is a change to metadata.labels.
+	d := deployment("a", 3, 3)
+	d.Labels = map[string]string{"tier": "web"}
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: d}))
+
+	// An object first seen as MODIFIED has nothing to compare against.
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("b", 1, 0)}))
+
+	// DELETED is delivered and forgets the object.
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Deleted, Object: deployment("a", 3, 3)}))
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 3, 3)}))
+}
+
+func TestDeliverEventTypes(t *testing.T) {
+	t.Parallel()
+	f, err := New([]string{"modified"}, []string{"{.spec.replicas}"})
+	require.NoError(t, err)
+
+	// The suppressed ADDED still records the object's state...
+	assert.False(t, f.Deliver(watch.Event{Type: watch.Added, Object: deployment("a", 1, 0)}))
+	// ...so an unchanged MODIFIED is suppressed too.
+	assert.False(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 1, 1)}))
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 2, 1)}))
+	assert.False(t, f.Deliver(watch.Event{Type: watch.Deleted, Object: deployment("a", 2, 1)}))
+}
+
+func TestDeliverUnstructured(t *testing.T) {
+	t.Parallel()
+	f, err := New(nil, []string{".status.conditions[?(@.type==\"Ready\")].status"})
+	require.NoError(t, err)
+	cert := func(ready string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{"conditions": []any{
+				map[string]any{"type": "Issuing", "status": "True"},
+				map[string]any{"type": "Ready", "status": ready},
+			}},
+		}}
+		u.SetUID("c")
+		return u
+	}
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Added, Object: cert("False")}))
+	assert.False(t, f.Deliver(watch.Event{Type: watch.Modified, Object: cert("False")}))
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: cert("True")}))
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	f, err := New(nil, nil)
+	require.NoError(t, err)
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 1, 0)}))
+	assert.True(t, f.Deliver(watch.Event{Type: watch.Modified, Object: deployment("a", 1, 0)}))
+
+	_, err = New([]string{"BOOKMARK"}, nil)
+	assert.ErrorContains(t, err, "must be one of ADDED, MODIFIED, DELETED")
+	_, err = New(nil, []string{".spec[replicas"})
+	assert.ErrorContains(t, err, `changed path ".spec[replicas"`)
+	_, err = New(nil, []string{" "})
+	assert.ErrorContains(t, err, "must not be empty")
+}
@@ -20,24 +20,42 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/kubewatcher/eventfilter"
 	"github.com/fission/fission/pkg/publisher"
 	"github.com/fission/fission/pkg/utils"
 )
 
 type (
 	KubeWatcher struct {
-		logger           logr.Logger
-		watches          map[types.NamespacedName]*watchSubscription
-		kubernetesClient kubernetes.Interface
-		publisher        publisher.Publisher
+		logger       logr.Logger
+		watches      map[types.NamespacedName]*watchSubscription
+		clients      watchClients
+		statusClient client.Client
+		publisher    publisher.Publisher
+		// durable, when set, replaces publisher with durable delivery
+		// through the statestore (see durableSink).
+		durable *durableSink
+	}
+
+	// watchClients are what a watch is opened through: the typed client for
+	// the legacy Type shorthands, the dynamic client and RESTMapper for an
+	// APIVersion/Kind.
+	watchClients struct {
+		kubernetes kubernetes.Interface
+		dynamic    dynamic.Interface
+		mapper     meta.RESTMapper
 	}
 
 	watchSubscription struct {
@@ -46,17 +64,42 @@ type (
 		kubeWatch           watch.Interface
 		lastResourceVersion string
 		stopped             atomic.Int32
-		kubernetesClient    kubernetes.Interface
+		clients             watchClients
 		publisher           publisher.Publisher
+
+		// filter is only used from eventDispatchLoop.
+		filter *eventfilter.Filter
+		// delivered and suppressed are the trigger's running totals, flushed
+		// to its status by flushCounters through statusClient (nil disables
+		// flushing); done stops the flusher.
+		delivered    atomic.Int64
+		suppressed   atomic.Int64
+		statusClient client.Client
+		done         chan struct{}
+
+		// durable is the KubeWatcher's durable sink (nil: events go to
+		// publisher); wake nudges its forwarder after an append.
+		durable *durableSink
+		wake    chan struct{}
 	}
 )
 
-func MakeKubeWatcher(ctx context.Context, logger logr.Logger, kubernetesClient kubernetes.Interface, publisher publisher.Publisher) *KubeWatcher {
+// statusFlushInterval is how often a subscription writes changed event
+// counters to its trigger's status. Overridable in tests.
+var statusFlushInterval = 10 * time.Second
+
+func MakeKubeWatcher(ctx context.Context, logger logr.Logger, kubernetesClient kubernetes.Interface,
+	dynamicClient dynamic.Interface, mapper meta.RESTMapper, statusClient client.Client, publisher publisher.Publisher) *KubeWatcher {
 	kw := &KubeWatcher{
-		logger:           logger.WithName("kube_watcher"),
-		watches:          make(map[types.NamespacedName]*watchSubscription),
-		kubernetesClient: kubernetesClient,
-		publisher:        publisher,
+		logger:  logger.WithName("kube_watcher"),
+		watches: make(map[types.NamespacedName]*watchSubscription),
+		clients: watchClients{
+			kubernetes: kubernetesClient,
+			dynamic:    dynamicClient,
+			mapper:     mapper,
+		},
+		statusClient: statusClient,
+		publisher:    publisher,
 	}
 	return kw
 }
@@ -89,7 +132,7 @@ func printKubernetesObject(obj runtime.Object, w io.Writer) error {
 	return err
 }
 
-func createKubernetesWatch(ctx context.Context, kubeClient kubernetes.Interface, w *fv1.KubernetesWatchTrigger, resourceVersion string) (watch.Interface, error) {
+func createKubernetesWatch(ctx context.Context, clients watchClients, w *fv1.KubernetesWatchTrigger, resourceVersion string) (watch.Interface, error) {
 	var wi watch.Interface
 	var err error
 	var watchTimeoutSec int64 = 120
@@ -112,13 +155,20 @@ func createKubernetesWatch(ctx context.Context, kubeClient kubernetes.Interface,
 		target = w.Namespace
 	}
 
-	// TODO populate labelselector and fieldselector
 	listOptions := metav1.ListOptions{
+		LabelSelector:   labels.SelectorFromSet(w.Spec.LabelSelector).String(),
+		FieldSelector:   w.Spec.FieldSelector,
 		ResourceVersion: resourceVersion,
 		TimeoutSeconds:  &watchTimeoutSec,
 	}
 
-	// TODO handle the full list of types
+	if w.Spec.Kind != "" {
+		return createDynamicWatch(ctx, clients, w, target, listOptions)
+	}
+
+	// The Type shorthands stay on the typed clients so the payload delivered
+	// to existing triggers is unchanged.
+	kubeClient := clients.kubernetes
 	switch strings.ToUpper(w.Spec.Type) {
 	case "POD":
 		wi, err = kubeClient.CoreV1().Pods(target).Watch(ctx, listOptions)
@@ -134,6 +184,38 @@ func createKubernetesWatch(ctx context.Context, kubeClient kubernetes.Interface,
 	return wi, err
 }
 
+// createDynamicWatch watches an APIVersion/Kind through the dynamic client.
+// The kind is resolved on every (re)start, so a CRD installed after the
+// trigger is found once discovery catches up.
+func createDynamicWatch(ctx context.Context, clients watchClients, w *fv1.KubernetesWatchTrigger, namespace string, listOptions metav1.ListOptions) (watch.Interface, error) {
+	if clients.dynamic == nil || clients.mapper == nil {
+		return nil, fmt.Errorf("watching %s %s is not supported by this kubewatcher", w.Spec.APIVersion, w.Spec.Kind)
+	}
+	gvk, err := w.Spec.GroupVersionKind()
+	if err != nil {
+		return nil, errors.NewBadRequest(fmt.Sprintf("invalid apiVersion %q: %v", w.Spec.APIVersion, err))
+	}
+	mapping, err := clients.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", gvk, err)
+	}
+	// A cluster-scoped watch would see every tenant's objects.
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return nil, errors.NewBadRequest(fmt.Sprintf("%s is cluster-scoped; only namespaced kinds can be watched", gvk.Kind))
+	}
+	return clients.dynamic.Resource(mapping.Resource).Namespace(namespace).Watch(ctx, listOptions)
+}
+
+// objectKind names the watched object's kind for the X-Kubernetes-Object-Type
+// header: the Go type name for typed objects, as it always was, and the kind
+// itself for unstructured ones.
+func objectKind(obj runtime.Object) string {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.GetKind()
+	}
+	return reflect.TypeOf(obj).Elem().Name()
+}
+
 // addWatch (re)starts the watch subscription for a trigger. An existing
 // subscription for the same trigger is stopped before being replaced so a
 // re-reconcile (e.g. a spec change or a retried failure) can't leak the old
@@ -150,15 +232,26 @@ func (kw *KubeWatcher) addWatch(ctx context.Context, w *fv1.KubernetesWatchTrigg
 	// config; the trigger is simply marked not-Ready and the reconcile is
 	// retried. (A transient start failure on an unchanged spec re-creates the
 	// watch on the next requeue.)
+	//
+	// The replacement carries on the old subscription's event counters; a
+	// first subscription starts from what the trigger's status last recorded.
+	delivered, suppressed := w.Status.DeliveredEvents, w.Status.SuppressedEvents
 	if old, ok := kw.watches[key]; ok {
 		old.stop()
 		delete(kw.watches, key)
+		delivered, suppressed = old.delivered.Load(), old.suppressed.Load()
 	}
 
-	ws, err := MakeWatchSubscription(ctx, kw.logger.WithName("watchsubscription"), w, kw.kubernetesClient, kw.publisher)
+	ws, err := makeWatchSubscription(ctx, kw.logger.WithName("watchsubscription"), w, kw.clients, kw.publisher, kw.durable)
 	if err != nil {
 		return err
 	}
+	ws.delivered.Store(delivered)
+	ws.suppressed.Store(suppressed)
+	if kw.statusClient != nil {
+		ws.statusClient = kw.statusClient
+		go ws.flushCounters(ctx, statusFlushInterval, delivered, suppressed)
+	}
 	kw.watches[key] = ws
 	return nil
 }
@@ -174,25 +267,52 @@ func (kw *KubeWatcher) removeWatch(key types.NamespacedName) {
 	kw.logger.Info("removing watch", "name", key.Name, "namespace", key.Namespace)
 	delete(kw.watches, key)
 	ws.stop()
+	if kw.durable != nil {
+		// The deleted trigger's events can no longer be replayed; drop them.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := kw.durable.forget(ctx, &ws.watch); err != nil {
+			kw.logger.Error(err, "dropping durable watch events", "name", key.Name, "namespace", key.Namespace)
+		}
+	}
 }
 
-func MakeWatchSubscription(ctx context.Context, logger logr.Logger, w *fv1.KubernetesWatchTrigger, kubeClient kubernetes.Interface, publisher publisher.Publisher) (*watchSubscription, error) {
+func makeWatchSubscription(ctx context.Context, logger logr.Logger, w *fv1.KubernetesWatchTrigger, clients watchClients, publisher publisher.Publisher, durable *durableSink) (*watchSubscription, error) {
+	filter, err := eventfilter.New(w.Spec.EventTypes, w.Spec.ChangedPaths)
+	if err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
 
 	ws := &watchSubscription{
 		logger:              logger.WithName("watch_subscription"),
 		watch:               *w,
 		kubeWatch:           nil,
-		kubernetesClient:    kubeClient,
+		clients:             clients,
 		publisher:           publisher,
 		lastResourceVersion: "",
+		filter:              filter,
+		done:                make(chan struct{}),
+		durable:             durable,
+	}
+	if durable != nil {
+		// Resume where the last appended event left off, so a restart or a
+		// leader change neither drops the events in between nor redelivers
+		// every existing object as ADDED.
+		pctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		ws.lastResourceVersion = durable.position(pctx, w)
+		cancel()
+		ws.wake = make(chan struct{}, 1)
 	}
 
-	err := ws.restartWatch(ctx)
+	err = ws.restartWatch(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	go ws.eventDispatchLoop(ctx)
+	if durable != nil {
+		go ws.forwardLoop(ctx, forwardRetryInterval)
+	}
 	return ws, nil
 }
 
@@ -208,8 +328,10 @@ func (ws *watchSubscription) restartWatch(ctx context.Context) error {
 			"watch", ws.watch.ObjectMeta,
 			"namespace", ws.watch.Spec.Namespace,
 			"type", ws.watch.Spec.Type,
+			"apiVersion", ws.watch.Spec.APIVersion,
+			"kind", ws.watch.Spec.Kind,
 			"last_resource_version", ws.lastResourceVersion)
-		wi, err := createKubernetesWatch(ctx, ws.kubernetesClient, &ws.watch, ws.lastResourceVersion)
+		wi, err := createKubernetesWatch(ctx, ws.clients, &ws.watch, ws.lastResourceVersion)
 		if err != nil {
 			retries--
 			if retries > 0 {
@@ -277,6 +399,11 @@ func (ws *watchSubscription) eventDispatchLoop(ctx context.Context) {
 			ws.lastResourceVersion = rv
 		}
 
+		if !ws.filter.Deliver(ev) {
+			ws.suppressed.Add(1)
+			continue
+		}
+
 		// Serialize the object
 		var buf bytes.Buffer
 		err = printKubernetesObject(ev.Object, &buf)
@@ -289,7 +416,7 @@ func (ws *watchSubscription) eventDispatchLoop(ctx context.Context) {
 		headers := map[string]string{
 			"Content-Type":             "application/json",
 			"X-Kubernetes-Event-Type":  string(ev.Type),
-			"X-Kubernetes-Object-Type": reflect.TypeOf(ev.Object).Elem().Name(),
+			"X-Kubernetes-Object-Type": objectKind(ev.Object),
 		}
 
 		// TODO support other function ref types. Or perhaps delegate to router?
@@ -304,13 +431,58 @@ func (ws *watchSubscription) eventDispatchLoop(ctx context.Context) {
 		// so essentially, function namespace = trigger namespace.
 		// RFC-0025: append the alias/version suffix when the reference carries
 		// one; resolution stays entirely router-side.
+		if ws.durable != nil {
+			ws.appendDurable(ctx, string(ev.Type), buf.Bytes(), headers, rv)
+			continue
+		}
 		url := utils.UrlForFunctionReference(ws.watch.Spec.FunctionReference, ws.watch.Namespace)
 		ws.publisher.Publish(ctx, buf.String(), headers, http.MethodPost, url)
+		ws.delivered.Add(1)
+	}
+}
+
+// flushCounters writes the event counters to the trigger's status whenever
+// they moved since the last write, until the subscription stops. It is a
+// merge patch of just the two counters with absolute values, so it never
+// fights the reconciler's condition writes and a lost write is repaired by
+// the next one. Best effort: a failed write only delays what kubectl shows.
+func (ws *watchSubscription) flushCounters(ctx context.Context, interval time.Duration, delivered, suppressed int64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ws.done:
+			return
+		case <-ticker.C:
+		}
+		d, s := ws.delivered.Load(), ws.suppressed.Load()
+		if d == delivered && s == suppressed {
+			continue
+		}
+		data, err := json.Marshal(map[string]any{"status": map[string]any{
+			"deliveredEvents":  d,
+			"suppressedEvents": s,
+		}})
+		if err != nil {
+			continue
+		}
+		// A fresh object: Patch decodes the response into it, and ws.watch is
+		// read concurrently by the dispatch loop.
+		obj := &fv1.KubernetesWatchTrigger{ObjectMeta: metav1.ObjectMeta{Name: ws.watch.Name, Namespace: ws.watch.Namespace}}
+		if err := ws.statusClient.Status().Patch(ctx, obj, client.RawPatch(types.MergePatchType, data)); err != nil {
+			ws.logger.V(1).Info("status update failed", "watch_name", ws.watch.Name, "error", err)
+			continue
+		}
+		delivered, suppressed = d, s
 	}
 }
 
 func (ws *watchSubscription) stop() {
-	ws.stopped.Store(1)
+	if ws.stopped.Swap(1) == 0 {
+		close(ws.done)
+	}
 	ws.kubeWatch.Stop()
 }
 
@@ -9,7 +9,13 @@ import (
 	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	clienttesting "k8s.io/client-go/testing"
 
@@ -27,7 +33,7 @@ func captureWatchedNamespace(t *testing.T, w *fv1.KubernetesWatchTrigger) (strin
 		ns = action.GetNamespace()
 		return true, watch.NewFake(), nil
 	})
-	_, err := createKubernetesWatch(context.Background(), kc, w, "")
+	_, err := createKubernetesWatch(context.Background(), watchClients{kubernetes: kc}, w, "")
 	return ns, err
 }
 
@@ -45,7 +51,7 @@ func TestCreateKubernetesWatch_RejectsCrossNamespace(t *testing.T) {
 		called = true
 		return true, watch.NewFake(), nil
 	})
-	_, err := createKubernetesWatch(context.Background(), kc, w, "")
+	_, err := createKubernetesWatch(context.Background(), watchClients{kubernetes: kc}, w, "")
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
@@ -92,3 +98,95 @@ func TestCreateKubernetesWatch_SameNamespaceAccepted(t *testing.T) {
 		t.Fatalf("expected namespace %q, got %q", "default", ns)
 	}
 }
+
+// TestCreateKubernetesWatch_SelectorsSent pins what reaches the API server:
+// both the label selector and the field selector narrow the watch there.
+func TestCreateKubernetesWatch_SelectorsSent(t *testing.T) {
+	w := &fv1.KubernetesWatchTrigger{
+		Name: "kwt-1", Namespace: "default",
+		Spec: fv1.KubernetesWatchTriggerSpec{
+			Type:          "POD",
+			LabelSelector: map[string]string{"app": "web"},
+			FieldSelector: "status.phase=Failed",
+		},
+	}
+	kc := fake.NewSimpleClientset()
+	var restrictions clienttesting.WatchRestrictions
+	kc.PrependWatchReactor("pods", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		restrictions = action.(clienttesting.WatchAction).GetWatchRestrictions()
+		return true, watch.NewFake(), nil
+	})
+	if _, err := createKubernetesWatch(context.Background(), watchClients{kubernetes: kc}, w, ""); err != nil {
+		t.Fatalf("expected acceptance, got: %v", err)
+	}
+	if got := restrictions.Labels.String(); got != "app=web" {
+		t.Fatalf("expected label selector %q, got %q", "app=web", got)
+	}
+	if got := restrictions.Fields.String(); got != "status.phase=Failed" {
+		t.Fatalf("expected field selector %q, got %q", "status.phase=Failed", got)
+	}
+}
+
+func dynamicWatchClients(t *testing.T, watched *schema.GroupVersionResource, ns *string) watchClients {
+	t.Helper()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer"}, meta.RESTScopeRoot)
+	dc := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	dc.PrependWatchReactor("*", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		*watched = action.GetResource()
+		*ns = action.GetNamespace()
+		return true, watch.NewFake(), nil
+	})
+	return watchClients{kubernetes: fake.NewSimpleClientset(), dynamic: dc, mapper: mapper}
+}
+
+func TestCreateKubernetesWatch_Dynamic(t *testing.T) {
+	var gvr schema.GroupVersionResource
+	var ns string
+	clients := dynamicWatchClients(t, &gvr, &ns)
+	w := &fv1.KubernetesWatchTrigger{
+		Name: "kwt-1", Namespace: "default",
+		Spec: fv1.KubernetesWatchTriggerSpec{
+			Namespace:  "default",
+			APIVersion: "cert-manager.io/v1",
+			Kind:       "Certificate",
+		},
+	}
+	if _, err := createKubernetesWatch(context.Background(), clients, w, ""); err != nil {
+		t.Fatalf("expected acceptance, got: %v", err)
+	}
+	if want := (schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}); gvr != want {
+		t.Fatalf("expected resource %v, got %v", want, gvr)
+	}
+	if ns != "default" {
+		t.Fatalf("expected namespace %q, got %q", "default", ns)
+	}
+
+	// A cluster-scoped kind would see every tenant's objects.
+	gvr = schema.GroupVersionResource{}
+	w.Spec.Kind = "ClusterIssuer"
+	_, err := createKubernetesWatch(context.Background(), clients, w, "")
+	if err == nil || !strings.Contains(err.Error(), "cluster-scoped") {
+		t.Fatalf("expected cluster-scoped error, got: %v", err)
+	}
+	if !gvr.Empty() {
+		t.Fatalf("Watch must not have been invoked for a cluster-scoped kind")
+	}
+
+	w.Spec.Kind = "Issuer"
+	if _, err := createKubernetesWatch(context.Background(), clients, w, ""); !meta.IsNoMatchError(err) {
+		t.Fatalf("expected no-match error for an unknown kind, got: %v", err)
+	}
+}
+
+func TestObjectKind(t *testing.T) {
+	u := &unstructured.Unstructured{}
+	u.SetKind("Certificate")
+	if got := objectKind(u); got != "Certificate" {
+		t.Fatalf("expected %q, got %q", "Certificate", got)
+	}
+	if got := objectKind(&corev1.Pod{}); got != "Pod" {
+		t.Fatalf("expected %q, got %q", "Pod", got)
+	}
+}
@@ -7,15 +7,27 @@ package kubewatcher
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/dynamic"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	"github.com/fission/fission/pkg/controller"
 	"github.com/fission/fission/pkg/crd"
 	"github.com/fission/fission/pkg/publisher"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/router/asyncinvoke/fnconfig"
+	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/utils/crmanager"
+
+	// The statestore drivers durable delivery opens, as the router's async
+	// path does: the HTTP client (embedded mode), Postgres and Redis (external).
+	_ "github.com/fission/fission/pkg/statestore/client"
+	_ "github.com/fission/fission/pkg/statestore/postgres"
+	_ "github.com/fission/fission/pkg/statestore/redis"
 )
 
 func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger logr.Logger, _ *errgroup.Group, routerUrl string) error {
@@ -24,8 +36,14 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 		return fmt.Errorf("failed to get kubernetes client: %w", err)
 	}
 
-	poster := publisher.MakeWebhookPublisher(logger, routerUrl)
-	kubeWatch := MakeKubeWatcher(ctx, logger, kubeClient, poster)
+	restConfig, err := clientGen.GetRestConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get rest config: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get dynamic client: %w", err)
+	}
 
 	// Active-passive HA via native controller-runtime leader election: only the
 	// elected leader registers watches, so two replicas don't double-register /
@@ -36,6 +54,46 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 	if err != nil {
 		return err
 	}
+
+	// APIVersion/Kind watches resolve through the Manager's discovery-backed
+	// RESTMapper, which picks up CRDs installed after startup.
+	poster := publisher.MakeWebhookPublisher(logger, routerUrl)
+	kubeWatch := MakeKubeWatcher(ctx, logger, kubeClient, dynamicClient, crMgr.GetRESTMapper(), crMgr.GetClient(), poster)
+
+	// Durable delivery (KUBEWATCHER_DURABLE_DELIVERY, set by the chart when
+	// async invocation is on): events are appended to a per-trigger statestore
+	// stream and forwarded onto the router's RFC-0024 async queue, which is
+	// also what `fission watch replay` re-enqueues from. Open does not dial;
+	// an unreachable store surfaces per event.
+	if os.Getenv("KUBEWATCHER_DURABLE_DELIVERY") == "true" {
+		opened, err := statestore.Open(ctx, statestore.FromEnv())
+		if err != nil {
+			return fmt.Errorf("durable delivery: opening statestore: %w", err)
+		}
+		caps := statestore.NewScoped(opened, nil)
+		defer func() { _ = caps.Close() }()
+		eventLog, err := caps.EventLog()
+		if err != nil {
+			return fmt.Errorf("durable delivery: statestore eventlog capability: %w", err)
+		}
+		queue, err := caps.Queue()
+		if err != nil {
+			return fmt.Errorf("durable delivery: statestore queue capability: %w", err)
+		}
+		kv, err := caps.KV()
+		if err != nil {
+			return fmt.Errorf("durable delivery: statestore KV capability: %w", err)
+		}
+		kubeWatch.durable = &durableSink{
+			eventLog:  eventLog,
+			queue:     queue,
+			kv:        kv,
+			queueName: asyncinvoke.DefaultQueue,
+			resolveFn: fnconfig.NewResolver(crMgr.GetClient(), logger),
+			now:       time.Now,
+		}
+		logger.Info("kubewatcher durable delivery enabled", "queue", asyncinvoke.DefaultQueue)
+	}
 	r := &KubernetesWatchTriggerReconciler{
 		logger:      logger.WithName("kuberneteswatchtrigger_reconciler"),
 		client:      crMgr.GetClient(),
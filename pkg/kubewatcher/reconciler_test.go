@@ -5,11 +5,17 @@
 package kubewatcher
 
 import (
+	"context"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -45,7 +51,7 @@ func TestKubernetesWatchTriggerReconciler(t *testing.T) {
 	r := &KubernetesWatchTriggerReconciler{
 		logger:      logr.Discard(),
 		client:      c,
-		kubeWatcher: MakeKubeWatcher(t.Context(), logr.Discard(), kc, publisher.MakeWebhookPublisher(logr.Discard(), "http://router.fission")),
+		kubeWatcher: MakeKubeWatcher(t.Context(), logr.Discard(), kc, nil, nil, nil, publisher.MakeWebhookPublisher(logr.Discard(), "http://router.fission")),
 	}
 	key := types.NamespacedName{Namespace: "default", Name: "kwt1"}
 	req := ctrl.Request{NamespacedName: key}
@@ -104,7 +110,7 @@ func TestKubernetesWatchTriggerReconciler_FailedRestartStopsStaleWatch(t *testin
 	r := &KubernetesWatchTriggerReconciler{
 		logger:      logr.Discard(),
 		client:      c,
-		kubeWatcher: MakeKubeWatcher(t.Context(), logr.Discard(), kc, publisher.MakeWebhookPublisher(logr.Discard(), "http://router.fission")),
+		kubeWatcher: MakeKubeWatcher(t.Context(), logr.Discard(), kc, nil, nil, nil, publisher.MakeWebhookPublisher(logr.Discard(), "http://router.fission")),
 	}
 	key := types.NamespacedName{Namespace: "default", Name: "kwt1"}
 	req := ctrl.Request{NamespacedName: key}
@@ -131,3 +137,95 @@ func TestKubernetesWatchTriggerReconciler_FailedRestartStopsStaleWatch(t *testin
 	require.NoError(t, c.Get(ctx, key, got))
 	assert.False(t, conditions.IsTrue(got.Status.Conditions, fv1.KubernetesWatchTriggerConditionReady), "trigger should be not-Ready after a failed watch start")
 }
+
+// recordingPublisher records the event types it is asked to publish.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, _ string, headers map[string]string, _, _ string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, headers["X-Kubernetes-Event-Type"])
+}
+
+func (p *recordingPublisher) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.events)
+}
+
+// TestKubernetesWatchTriggerReconciler_FiltersAndCounts covers the selectors
+// handed to the API server, the event-type and changed-path filters, and the
+// delivered/suppressed counters written to the trigger's status.
+func TestKubernetesWatchTriggerReconciler_FiltersAndCounts(t *testing.T) {
+	orig := statusFlushInterval
+	statusFlushInterval = 10 * time.Millisecond
+	t.Cleanup(func() { statusFlushInterval = orig })
+
+	w := &fv1.KubernetesWatchTrigger{
+		Name: "kwt1", Namespace: "default", Generation: 1,
+		Spec: fv1.KubernetesWatchTriggerSpec{
+			Type:              "POD",
+			LabelSelector:     map[string]string{"app": "web"},
+			FieldSelector:     "status.phase=Running",
+			EventTypes:        []string{"MODIFIED"},
+			ChangedPaths:      []string{".spec.nodeName"},
+			FunctionReference: fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "fn"},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(w).
+		WithStatusSubresource(&fv1.KubernetesWatchTrigger{}).
+		Build()
+	kc := kubefake.NewClientset()
+	fw := watch.NewFakeWithChanSize(10, false)
+	var restrictions clienttesting.WatchRestrictions
+	kc.PrependWatchReactor("pods", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		restrictions = action.(clienttesting.WatchAction).GetWatchRestrictions()
+		return true, fw, nil
+	})
+	pub := &recordingPublisher{}
+	r := &KubernetesWatchTriggerReconciler{
+		logger:      logr.Discard(),
+		client:      c,
+		kubeWatcher: MakeKubeWatcher(t.Context(), logr.Discard(), kc, nil, nil, c, pub),
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "kwt1"}
+	_, err := r.Reconcile(t.Context(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, "app=web", restrictions.Labels.String(), "the label selector narrows the watch server-side")
+	assert.Equal(t, "status.phase=Running", restrictions.Fields.String())
+
+	pod := func(node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", UID: "pod-uid"},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+	fw.Add(pod(""))         // suppressed: not an allowed event type
+	fw.Modify(pod(""))      // suppressed: spec.nodeName unchanged
+	fw.Modify(pod("node1")) // delivered
+
+	require.Eventually(t, func() bool {
+		got := &fv1.KubernetesWatchTrigger{}
+		require.NoError(t, c.Get(t.Context(), key, got))
+		return got.Status.DeliveredEvents == 1 && got.Status.SuppressedEvents == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"MODIFIED"}, pub.published())
+
+	// A spec change replaces the subscription, which carries the counters on.
+	cur := &fv1.KubernetesWatchTrigger{}
+	require.NoError(t, c.Get(t.Context(), key, cur))
+	cur.Spec.EventTypes = nil
+	cur.Status = fv1.KubernetesWatchTriggerStatus{}
+	require.NoError(t, c.Update(t.Context(), cur))
+	fw = watch.NewFakeWithChanSize(10, false)
+	_, err = r.Reconcile(t.Context(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	ws := r.kubeWatcher.watches[key]
+	assert.EqualValues(t, 1, ws.delivered.Load())
+	assert.EqualValues(t, 2, ws.suppressed.Load())
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package watchlog is the shared format of a KubernetesWatchTrigger's durable
+// event stream: the kubewatcher appends to it and forwards it onto the async
+// invocation queue, and the router's replay endpoint re-enqueues from it. Each
+// event's Payload is the encoded asyncinvoke.Envelope that invokes the
+// trigger's function, and its Type is the watch event type.
+package watchlog
+
+import (
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// HeaderSequence carries the event's sequence number in its stream, so a
+	// function can tell a redelivery or replay of an event it already handled.
+	HeaderSequence = "X-Fission-Watch-Sequence"
+	// HeaderReplay is set on invocations enqueued by `fission watch replay`.
+	HeaderReplay = "X-Fission-Watch-Replay"
+)
+
+// Stream returns the EventLog stream of one trigger incarnation. The UID keeps
+// a deleted-and-recreated trigger of the same name from inheriting the old
+// events; namespace and name never contain "/", so the mapping cannot alias.
+func Stream(namespace, name string, uid types.UID) string {
+	return "kubewatch/" + namespace + "/" + name + "/" + string(uid)
+}
+
+// DedupKey is the queue dedup key of the forwarded event seq, which collapses
+// a forward retried after an ambiguous store error.
+func DedupKey(uid types.UID, seq int64) string {
+	return "kubewatch/" + string(uid) + "/" + strconv.FormatInt(seq, 10)
+}
+
+// Envelope decodes the invocation stored in ev and stamps its sequence
+// header. A replay also marks the invocation and restarts its age: MaxAge is
+// measured from EnqueueTime, and a replayed event is usually older than it.
+func Envelope(ev statestore.Event, replayAt time.Time) (asyncinvoke.Envelope, error) {
+	env, err := asyncinvoke.Decode(ev.Payload)
+	if err != nil {
+		return asyncinvoke.Envelope{}, err
+	}
+	if env.Headers == nil {
+		env.Headers = map[string]string{}
+	}
+	env.Headers[HeaderSequence] = strconv.FormatInt(ev.Seq, 10)
+	if !replayAt.IsZero() {
+		env.Headers[HeaderReplay] = "true"
+		env.EnqueueTime = replayAt
+	}
+	return env, nil
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package messageQueue
+
+import (
+	"net/http"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+)
+
+// CloudEvent types of what a trigger with CloudEvents set sends: the message
+// it delivers to the function, and what it publishes to its ResponseTopic and
+// ErrorTopic.
+const (
+	EventTypeMessage  = "io.fission.mqtrigger.message"
+	EventTypeResponse = "io.fission.mqtrigger.response"
+	EventTypeError    = "io.fission.mqtrigger.error"
+)
+
+// EventSource is the CloudEvents source of the events a trigger creates.
+func EventSource(trigger *fv1.MessageQueueTrigger) string {
+	return "/fission/namespaces/" + trigger.Namespace + "/messagequeuetriggers/" + trigger.Name
+}
+
+// DeliveryEvent returns the event to deliver to the function for one message,
+// whose headers h carry its content type and, in binary mode, its attributes.
+// A message that already is a valid CloudEvent keeps its attributes; any other
+// is wrapped with id, which the caller derives from the message's position in
+// the topic so a redelivery keeps it.
+func DeliveryEvent(trigger *fv1.MessageQueueTrigger, id string, h http.Header, payload []byte) cloudevents.Event {
+	return wrapEvent(trigger, EventTypeMessage, id, h, payload)
+}
+
+// PublishEvent encodes what a trigger publishes to its ResponseTopic or
+// ErrorTopic (eventType) in structured mode, and returns it with its content
+// type. A body that already is a CloudEvent, such as a function's reply in
+// binary mode, is re-encoded as it is. The id of a wrapped body is derived
+// from messageID, the id of the message being handled, and eventType.
+func PublishEvent(trigger *fv1.MessageQueueTrigger, eventType, messageID string, h http.Header, body []byte) (string, []byte, error) {
+	data, err := wrapEvent(trigger, eventType, cloudevents.NewID(messageID, eventType), h, body).Marshal()
+	if err != nil {
+		return "", nil, err
+	}
+	return cloudevents.ContentTypeStructured, data, nil
+}
+
+func wrapEvent(trigger *fv1.MessageQueueTrigger, eventType, id string, h http.Header, body []byte) cloudevents.Event {
+	if ev, err := cloudevents.FromHTTP(h, body); err == nil && ev.Validate() == nil {
+		return ev
+	}
+	ev := cloudevents.New(id, EventSource(trigger), eventType, body, h.Get("Content-Type"))
+	ev.Subject = trigger.Spec.Topic
+	return ev
+}
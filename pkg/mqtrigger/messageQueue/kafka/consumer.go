@@ -20,7 +20,10 @@ import (
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	"github.com/fission/fission/pkg/cloudevents"
 	"github.com/fission/fission/pkg/mqtrigger"
+	"github.com/fission/fission/pkg/mqtrigger/messageQueue"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
 	"github.com/fission/fission/pkg/utils"
 	"github.com/fission/fission/pkg/utils/httpx"
 )
@@ -187,11 +190,30 @@ func (ch *MqtConsumerGroupHandler) kafkaMsgHandler(msg *sarama.ConsumerMessage)
 		ch.logger.Error(nil, "headers are not supported by current Kafka version, needs v0.11+: no record headers to add in HTTP request",
 			"current_version", ch.version)
 	}
+	recordContentType := req.Header.Get("Content-Type")
 
 	for k, v := range ch.fissionHeaders {
 		req.Header.Set(k, v)
 	}
 
+	// With a message group header the record is handed over as an ordered
+	// async invocation; the router's 202 is the success to wait for.
+	grouped := ch.trigger.Spec.MessageGroupHeader != ""
+	if grouped {
+		req.Header.Set(asyncinvoke.HeaderInvokeMode, asyncinvoke.InvokeModeAsync)
+		if group := req.Header.Get(ch.trigger.Spec.MessageGroupHeader); group != "" {
+			req.Header.Set(asyncinvoke.HeaderMessageGroup, group)
+		}
+	}
+
+	// messageID is the CloudEvents id of this record, the same on every
+	// redelivery; the response and error events derive theirs from it.
+	var messageID string
+	if ch.trigger.Spec.CloudEvents {
+		messageID = cloudevents.NewID(msg.Topic, strconv.Itoa(int(msg.Partition)), strconv.FormatInt(msg.Offset, 10))
+		value = string(toCloudEvent(ch.trigger, req.Header, recordContentType, messageID, msg.Value))
+	}
+
 	// Make the request via the per-handler client so HMAC
 	// signing (when configured) is applied. Reset the body on every
 	// retry: net/http closes req.Body after each Do() call, AND the
@@ -215,7 +237,7 @@ func (ch *MqtConsumerGroupHandler) kafkaMsgHandler(msg *sarama.ConsumerMessage)
 		if resp == nil {
 			continue
 		}
-		if err == nil && resp.StatusCode == http.StatusOK {
+		if err == nil && delivered(resp.StatusCode, grouped) {
 			// Success, quit retrying
 			break
 		}
@@ -238,7 +260,7 @@ func (ch *MqtConsumerGroupHandler) kafkaMsgHandler(msg *sarama.ConsumerMessage)
 	if resp == nil {
 		errorString := fmt.Sprintf("request exceed retries: %v", ch.trigger.Spec.MaxRetries)
 		errorHeaders := generateErrorHeaders(errorString)
-		errorHandler(ch.logger, ch.trigger, ch.producer, ch.fnUrl,
+		errorHandler(ch.logger, ch.trigger, ch.producer, ch.fnUrl, messageID,
 			errors.New(errorString), errorHeaders)
 		return
 	}
@@ -253,14 +275,14 @@ func (ch *MqtConsumerGroupHandler) kafkaMsgHandler(msg *sarama.ConsumerMessage)
 	if err != nil {
 		errorString := "request body error: " + string(body)
 		errorHeaders := generateErrorHeaders(errorString)
-		errorHandler(ch.logger, ch.trigger, ch.producer, ch.fnUrl,
+		errorHandler(ch.logger, ch.trigger, ch.producer, ch.fnUrl, messageID,
 			fmt.Errorf("%s: %w", errorString, err), errorHeaders)
 		return
 	}
-	if resp.StatusCode != 200 {
+	if !delivered(resp.StatusCode, grouped) {
 		errorString := fmt.Sprintf("request returned failure: %v, request body error: %v", resp.StatusCode, body)
 		errorHeaders := generateErrorHeaders(errorString)
-		errorHandler(ch.logger, ch.trigger, ch.producer, ch.fnUrl,
+		errorHandler(ch.logger, ch.trigger, ch.producer, ch.fnUrl, messageID,
 			fmt.Errorf("request returned failure: %v", resp.StatusCode), errorHeaders)
 		return
 	}
@@ -278,6 +300,15 @@ func (ch *MqtConsumerGroupHandler) kafkaMsgHandler(msg *sarama.ConsumerMessage)
 			ch.logger.Error(nil, "headers are not supported by current Kafka version, needs v0.11+: no record headers to add in HTTP request",
 				"current_version", ch.version)
 		}
+		if ch.trigger.Spec.CloudEvents {
+			contentType, data, err := messageQueue.PublishEvent(ch.trigger, messageQueue.EventTypeResponse, messageID, resp.Header, body)
+			if err != nil {
+				ch.logger.Error(err, "failed to encode response from function invocation as a CloudEvent", "function_url", ch.fnUrl)
+				return
+			}
+			body = data
+			kafkaRecordHeaders = structuredHeaders(ch.version, contentType)
+		}
 
 		_, _, err := ch.producer.SendMessage(&sarama.ProducerMessage{
 			Topic:   ch.trigger.Spec.ResponseTopic,
@@ -292,11 +323,64 @@ func (ch *MqtConsumerGroupHandler) kafkaMsgHandler(msg *sarama.ConsumerMessage)
 	}
 }
 
-func errorHandler(logger logr.Logger, trigger *fv1.MessageQueueTrigger, producer sarama.SyncProducer, funcUrl string, err error, errorTopicHeaders []sarama.RecordHeader) {
+// delivered reports whether status completes a delivery: 200 for a
+// synchronous invocation, 202 for an async one, which the router returns once
+// the invocation is durably enqueued.
+func delivered(status int, async bool) bool {
+	if async {
+		return status == http.StatusAccepted
+	}
+	return status == http.StatusOK
+}
+
+// toCloudEvent rewrites a delivery's headers h as a binary-mode CloudEvent
+// and returns the body to send. The Kafka binding names binary-mode attributes
+// ce_<name>, which arrive on h as Ce_<name> record headers; the record's own
+// content type, which marks a structured-mode event, takes precedence over the
+// trigger's.
+func toCloudEvent(trigger *fv1.MessageQueueTrigger, h http.Header, recordContentType, id string, value []byte) []byte {
+	for name, vals := range h {
+		if attr, ok := strings.CutPrefix(name, "Ce_"); ok {
+			delete(h, name)
+			h[http.CanonicalHeaderKey(cloudevents.HeaderPrefix+attr)] = vals
+		}
+	}
+	if recordContentType != "" {
+		h.Set("Content-Type", recordContentType)
+	}
+	ev := messageQueue.DeliveryEvent(trigger, id, h, value)
+	ev.WriteBinary(h)
+	return ev.Data
+}
+
+// structuredHeaders returns the record headers of a structured-mode event,
+// none when the broker version predates record headers.
+func structuredHeaders(version sarama.KafkaVersion, contentType string) []sarama.RecordHeader {
+	if !version.IsAtLeast(sarama.V0_11_0_0) {
+		return nil
+	}
+	return []sarama.RecordHeader{{Key: []byte("content-type"), Value: []byte(contentType)}}
+}
+
+func errorHandler(logger logr.Logger, trigger *fv1.MessageQueueTrigger, producer sarama.SyncProducer, funcUrl, messageID string, err error, errorTopicHeaders []sarama.RecordHeader) {
 	if len(trigger.Spec.ErrorTopic) > 0 {
+		value := []byte(err.Error())
+		if trigger.Spec.CloudEvents {
+			contentType, data, e := messageQueue.PublishEvent(trigger, messageQueue.EventTypeError, messageID,
+				http.Header{"Content-Type": {"text/plain"}}, value)
+			if e != nil {
+				logger.Error(e, "failed to encode error as a CloudEvent", "trigger", trigger.Name, "message", err.Error())
+				return
+			}
+			value = data
+			// The headers are empty exactly when the broker predates them.
+			if len(errorTopicHeaders) > 0 {
+				errorTopicHeaders = append(errorTopicHeaders, sarama.RecordHeader{Key: []byte("content-type"), Value: []byte(contentType)})
+			}
+		}
 		_, _, e := producer.SendMessage(&sarama.ProducerMessage{
 			Topic:   trigger.Spec.ErrorTopic,
-			Value:   sarama.StringEncoder(err.Error()),
+			Value:   sarama.ByteEncoder(value),
 			Headers: errorTopicHeaders,
 		})
 		if e != nil {
@@ -5,6 +5,7 @@
 package kafka
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -16,6 +17,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+	"github.com/fission/fission/pkg/mqtrigger/messageQueue"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
 )
 
 func TestNewKafkaHTTPClient(t *testing.T) {
@@ -145,6 +149,92 @@ func TestKafkaMsgHandler(t *testing.T) {
 		}
 		ch.kafkaMsgHandler(&sarama.ConsumerMessage{Value: []byte("hello")})
 	})
+
+	t.Run("CloudEvents are delivered in binary mode and published in structured mode", func(t *testing.T) {
+		var got http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("function-output"))
+		}))
+		defer srv.Close()
+
+		wantID := cloudevents.NewID("in", "3", "42")
+		producer := newProducerMock(t)
+		producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+			ev, err := cloudevents.Unmarshal(val)
+			if err != nil {
+				return err
+			}
+			if ev.Type != messageQueue.EventTypeResponse || ev.ID != cloudevents.NewID(wantID, messageQueue.EventTypeResponse) {
+				return fmt.Errorf("unexpected response event %+v", ev)
+			}
+			return nil
+		})
+		defer func() { require.NoError(t, producer.Close()) }()
+
+		tr := nameRefTrigger()
+		tr.Spec.CloudEvents = true
+		ch := &MqtConsumerGroupHandler{
+			version:        sarama.V2_0_0_0,
+			logger:         logr.Discard(),
+			trigger:        tr,
+			fissionHeaders: map[string]string{"Content-Type": "application/json"},
+			producer:       producer,
+			fnUrl:          srv.URL,
+			httpClient:     srv.Client(),
+		}
+		ch.kafkaMsgHandler(&sarama.ConsumerMessage{Topic: "in", Partition: 3, Offset: 42, Value: []byte(`{"n":1}`)})
+		assert.Equal(t, wantID, got.Get(cloudevents.HeaderID))
+		assert.Equal(t, messageQueue.EventTypeMessage, got.Get(cloudevents.HeaderType))
+		assert.Equal(t, "application/json", got.Get("Content-Type"))
+
+		// A record in the Kafka binary binding keeps its own attributes.
+		producer.ExpectSendMessageAndSucceed()
+		ch.kafkaMsgHandler(&sarama.ConsumerMessage{Topic: "in", Value: []byte(`{"n":2}`), Headers: []*sarama.RecordHeader{
+			{Key: []byte("ce_specversion"), Value: []byte("1.0")},
+			{Key: []byte("ce_id"), Value: []byte("producer-id")},
+			{Key: []byte("ce_source"), Value: []byte("/shop")},
+			{Key: []byte("ce_type"), Value: []byte("com.example.order")},
+		}})
+		assert.Equal(t, "producer-id", got.Get(cloudevents.HeaderID))
+		assert.Equal(t, "com.example.order", got.Get(cloudevents.HeaderType))
+		assert.Empty(t, got.Get("Ce_id"))
+	})
+
+	t.Run("a message group header hands the record over as a grouped async invocation", func(t *testing.T) {
+		var got []http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.Header.Clone())
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer srv.Close()
+
+		// No producer expectations: a 202 is a success, with nothing to publish.
+		producer := newProducerMock(t)
+		defer func() { require.NoError(t, producer.Close()) }()
+
+		tr := nameRefTrigger()
+		tr.Spec.ResponseTopic = ""
+		tr.Spec.MessageGroupHeader = "X-Customer"
+		ch := &MqtConsumerGroupHandler{
+			version:    sarama.V2_0_0_0,
+			logger:     logr.Discard(),
+			trigger:    tr,
+			producer:   producer,
+			fnUrl:      srv.URL,
+			httpClient: srv.Client(),
+		}
+		ch.kafkaMsgHandler(&sarama.ConsumerMessage{Value: []byte("a"), Headers: []*sarama.RecordHeader{
+			{Key: []byte("x-customer"), Value: []byte("c42")},
+		}})
+		ch.kafkaMsgHandler(&sarama.ConsumerMessage{Value: []byte("b")})
+		require.Len(t, got, 2, "a 202 is not retried")
+		assert.Equal(t, asyncinvoke.InvokeModeAsync, got[0].Get(asyncinvoke.HeaderInvokeMode))
+		assert.Equal(t, "c42", got[0].Get(asyncinvoke.HeaderMessageGroup))
+		assert.Equal(t, asyncinvoke.InvokeModeAsync, got[1].Get(asyncinvoke.HeaderInvokeMode))
+		assert.Empty(t, got[1].Get(asyncinvoke.HeaderMessageGroup), "a record without the header is unordered")
+	})
 }
 
 func TestErrorHandler(t *testing.T) {
@@ -154,7 +244,7 @@ func TestErrorHandler(t *testing.T) {
 		defer func() { require.NoError(t, producer.Close()) }()
 
 		tr := nameRefTrigger()
-		errorHandler(logr.Discard(), tr, producer, tr.Spec.ResponseTopic, assert.AnError, nil)
+		errorHandler(logr.Discard(), tr, producer, tr.Spec.ResponseTopic, "", assert.AnError, nil)
 	})
 
 	t.Run("no-ops when no error topic is configured", func(t *testing.T) {
@@ -163,6 +253,6 @@ func TestErrorHandler(t *testing.T) {
 
 		tr := nameRefTrigger()
 		tr.Spec.ErrorTopic = ""
-		errorHandler(logr.Discard(), tr, producer, "http://fn", assert.AnError, nil)
+		errorHandler(logr.Discard(), tr, producer, "http://fn", "", assert.AnError, nil)
 	})
 }
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,6 +18,8 @@ import (
 	"time"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+	"github.com/fission/fission/pkg/mqtrigger/messageQueue"
 	"github.com/fission/fission/pkg/mqtrigger/mqpub"
 	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/utils"
@@ -248,8 +251,16 @@ func (sub *subscription) handle(ctx context.Context, ev statestore.Event) bool {
 	// delivery budget against the (already failing) function — E5 deliberately
 	// prices re-delivery below losing the event.
 	if sub.trigger.Spec.ErrorTopic != "" {
-		err := sub.s.pub.Publish(ctx, sub.trigger.Namespace, fv1.MessageQueueTypeStatestore,
-			sub.trigger.Spec.ErrorTopic, ev.Type, ev.Payload)
+		contentType, payload := ev.Type, ev.Payload
+		var err error
+		if sub.trigger.Spec.CloudEvents {
+			contentType, payload, err = messageQueue.PublishEvent(sub.trigger, messageQueue.EventTypeError,
+				sub.eventID(ev), http.Header{"Content-Type": {ev.Type}}, ev.Payload)
+		}
+		if err == nil {
+			err = sub.s.pub.Publish(ctx, sub.trigger.Namespace, fv1.MessageQueueTypeStatestore,
+				sub.trigger.Spec.ErrorTopic, contentType, payload)
+		}
 		if err != nil {
 			recordErrorTopic(ctx, "error")
 			sub.logger.Error(err, "publishing exhausted event to error topic; will retry",
@@ -272,6 +283,12 @@ func (sub *subscription) handle(ctx context.Context, ev statestore.Event) bool {
 	return true
 }
 
+// eventID is the CloudEvents id of the event at ev's position in the topic,
+// the same for every trigger and every redelivery.
+func (sub *subscription) eventID(ev statestore.Event) string {
+	return cloudevents.NewID(sub.stream, strconv.FormatInt(ev.Seq, 10))
+}
+
 // deliver POSTs the event to the function via the router internal listener,
 // retrying up to MaxRetries. Success is any 2xx — a deliberate deviation from
 // the kafka provider's strict 200, consistent with the RFC-0024 settle matrix.
@@ -281,6 +298,16 @@ func (sub *subscription) deliver(ctx context.Context, ev statestore.Event) bool
 	if contentType == "" {
 		contentType = sub.trigger.Spec.ContentType
 	}
+	header, payload := http.Header{}, ev.Payload
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if sub.trigger.Spec.CloudEvents {
+		ce := messageQueue.DeliveryEvent(sub.trigger, sub.eventID(ev), header, ev.Payload)
+		header = http.Header{}
+		ce.WriteBinary(header)
+		payload = ce.Data
+	}
 	for attempt := 0; attempt <= sub.trigger.Spec.MaxRetries; attempt++ {
 		if attempt > 0 {
 			recordRetry(ctx)
@@ -293,14 +320,12 @@ func (sub *subscription) deliver(ctx context.Context, ev statestore.Event) bool
 		// rather than short-circuiting a "terminal" verdict the event never
 		// earned (zero real attempts must not read as exhausted-and-error-
 		// topic'd in the normal way retries do).
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.fnURL, bytes.NewReader(ev.Payload))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.fnURL, bytes.NewReader(payload))
 		if err != nil {
 			sub.logger.Error(err, "building delivery request", "url", sub.fnURL, "seq", ev.Seq, "attempt", attempt)
 			continue
 		}
-		if contentType != "" {
-			req.Header.Set("Content-Type", contentType)
-		}
+		maps.Copy(req.Header, header)
 		req.Header.Set("X-Fission-MQTrigger-Topic", sub.trigger.Spec.Topic)
 		req.Header.Set("X-Fission-MQTrigger-RespTopic", sub.trigger.Spec.ResponseTopic)
 		req.Header.Set("X-Fission-MQTrigger-ErrorTopic", sub.trigger.Spec.ErrorTopic)
@@ -329,8 +354,16 @@ func (sub *subscription) deliver(ctx context.Context, ev statestore.Event) bool
 		}
 		if sub.trigger.Spec.ResponseTopic != "" {
 			respCT := resp.Header.Get("Content-Type")
-			if err := sub.s.pub.Publish(ctx, sub.trigger.Namespace, fv1.MessageQueueTypeStatestore,
-				sub.trigger.Spec.ResponseTopic, respCT, body); err != nil {
+			var err error
+			if sub.trigger.Spec.CloudEvents {
+				respCT, body, err = messageQueue.PublishEvent(sub.trigger, messageQueue.EventTypeResponse,
+					sub.eventID(ev), resp.Header, body)
+			}
+			if err == nil {
+				err = sub.s.pub.Publish(ctx, sub.trigger.Namespace, fv1.MessageQueueTypeStatestore,
+					sub.trigger.Spec.ResponseTopic, respCT, body)
+			}
+			if err != nil {
 				recordResponseTopic(ctx, "error")
 				sub.logger.Error(err, "publishing response to response topic (best-effort)",
 					"responseTopic", sub.trigger.Spec.ResponseTopic, "seq", ev.Seq)
@@ -18,6 +18,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+	"github.com/fission/fission/pkg/mqtrigger/messageQueue"
 	"github.com/fission/fission/pkg/mqtrigger/mqpub"
 	"github.com/fission/fission/pkg/statestore"
 	_ "github.com/fission/fission/pkg/statestore/memory"
@@ -29,6 +31,8 @@ type received struct {
 	ContentType string
 	Topic       string
 	RespTopic   string
+	EventID     string
+	EventType   string
 }
 
 // fnEndpoint is a scripted stand-in for the router-internal function URL.
@@ -48,6 +52,8 @@ func (f *fnEndpoint) handler(w http.ResponseWriter, r *http.Request) {
 		ContentType: r.Header.Get("Content-Type"),
 		Topic:       r.Header.Get("X-Fission-MQTrigger-Topic"),
 		RespTopic:   r.Header.Get("X-Fission-MQTrigger-RespTopic"),
+		EventID:     r.Header.Get(cloudevents.HeaderID),
+		EventType:   r.Header.Get(cloudevents.HeaderType),
 	})
 	n := len(f.got)
 	failN, status, body := f.failN, f.status, f.body
@@ -235,6 +241,54 @@ func TestSubscriptionResponseTopic(t *testing.T) {
 	assert.Equal(t, "text/plain", evs[0].Type, "the function's response Content-Type travels")
 }
 
+// TestSubscriptionCloudEvents: with CloudEvents set, a plain message reaches
+// the function as a binary-mode event whose id follows from its position, a
+// message that already is an event keeps its id, and the response topic gets
+// structured-mode events.
+func TestSubscriptionCloudEvents(t *testing.T) {
+	t.Parallel()
+	fn := &fnEndpoint{body: "fn-response"}
+	srv := httptest.NewServer(http.HandlerFunc(fn.handler))
+	defer srv.Close()
+	s := newTestProvider(t, srv.URL)
+
+	startSub(t, s, testTrigger("t1", "orders", func(tr *fv1.MessageQueueTrigger) {
+		tr.Spec.CloudEvents = true
+		tr.Spec.ResponseTopic = "orders-replies"
+	}))
+	publish(t, s, "orders", "application/json", `{"n":1}`)
+	structured, err := cloudevents.New("producer-id", "/shop", "com.example.order", []byte(`{"n":2}`), "application/json").Marshal()
+	require.NoError(t, err)
+	publish(t, s, "orders", cloudevents.ContentTypeStructured, string(structured))
+
+	require.Eventually(t, func() bool { return len(fn.deliveries()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	got := fn.deliveries()
+	stream := mqpub.StreamForTopic("ns", "orders")
+	assert.Equal(t, `{"n":1}`, got[0].Body)
+	assert.Equal(t, "application/json", got[0].ContentType)
+	assert.Equal(t, cloudevents.NewID(stream, "1"), got[0].EventID)
+	assert.Equal(t, messageQueue.EventTypeMessage, got[0].EventType)
+	assert.Equal(t, `{"n":2}`, got[1].Body, "a structured-mode message is delivered in binary mode")
+	assert.Equal(t, "producer-id", got[1].EventID)
+	assert.Equal(t, "com.example.order", got[1].EventType)
+
+	respStream := mqpub.StreamForTopic("ns", "orders-replies")
+	require.Eventually(t, func() bool {
+		evs, err := s.el.Read(t.Context(), respStream, 0, 10)
+		return err == nil && len(evs) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	evs, err := s.el.Read(t.Context(), respStream, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, cloudevents.ContentTypeStructured, evs[0].Type)
+	resp, err := cloudevents.Unmarshal(evs[0].Payload)
+	require.NoError(t, err)
+	require.NoError(t, resp.Validate())
+	assert.Equal(t, messageQueue.EventTypeResponse, resp.Type)
+	assert.Equal(t, cloudevents.NewID(cloudevents.NewID(stream, "1"), messageQueue.EventTypeResponse), resp.ID)
+	assert.Equal(t, "/fission/namespaces/ns/messagequeuetriggers/t1", resp.Source)
+	assert.Equal(t, []byte("fn-response"), resp.Data)
+}
+
 func TestSubscriptionResumesFromDurableCursor(t *testing.T) {
 	t.Parallel()
 	fn := &fnEndpoint{}
@@ -5,6 +5,7 @@
 package publisher
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -170,3 +171,56 @@ func TestWebhookPublisherTransportFailureRetriesQuietly(t *testing.T) {
 	}, 300*time.Millisecond, 30*time.Millisecond,
 		"transport retries must log quietly (V(1)), not one error per attempt")
 }
+
+// TestWebhookPublisherSend covers the synchronous path the timer's
+// concurrency policy relies on: Send retries a transient 404 inline and
+// returns nil once it settles, returns a terminal 4xx as an error without
+// retrying, and returns promptly when its context is cancelled mid-request.
+func TestWebhookPublisherSend(t *testing.T) {
+	var mu sync.Mutex
+	hits := 0
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			mu.Lock()
+			hits++
+			n := hits
+			mu.Unlock()
+			if n < 2 {
+				http.NotFound(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/bad":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/slow":
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	p := MakeWebhookPublisher(logr.Discard(), srv.URL)
+	p.retryDelay = 10 * time.Millisecond
+
+	require.NoError(t, p.Send(t.Context(), "", nil, http.MethodPost, "flaky"))
+	require.Equal(t, 2, hits, "Send should retry the transient 404 before returning")
+
+	require.Error(t, p.Send(t.Context(), "", nil, http.MethodPost, "bad"))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- p.Send(ctx, "", nil, http.MethodPost, "slow") }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send did not return after its context was cancelled")
+	}
+}
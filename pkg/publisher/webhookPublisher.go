@@ -143,10 +143,49 @@ func (p *WebhookPublisher) Publish(ctx context.Context, body string, headers map
 	}
 }
 
+// Send is the synchronous form of Publish: it makes the request on the calling
+// goroutine, retrying transient failures (404, transport errors) with the same
+// bounded, doubling backoff, and returns once the request has settled — nil on a
+// 2xx/3xx, the last failure otherwise. Cancelling ctx aborts the attempt in
+// flight and stops further retries. Callers that must know when an invocation
+// finished (the timer's concurrency policy) use it instead of Publish.
+func (p *WebhookPublisher) Send(ctx context.Context, body string, headers map[string]string, method, target string) error {
+	tracer := otel.Tracer("WebhookPublisher")
+	ctx, span := tracer.Start(ctx, "WebhookPublisher/Send")
+	defer span.End()
+
+	r := &publishRequest{
+		ctx:        ctx,
+		body:       body,
+		headers:    headers,
+		method:     method,
+		target:     target,
+		retries:    p.maxRetries,
+		retryDelay: p.retryDelay,
+	}
+	for {
+		retry, err := p.makeHTTPRequest(r)
+		if !retry {
+			return err
+		}
+		t := time.NewTimer(r.retryDelay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+}
+
 func (p *WebhookPublisher) svc() {
 	for {
 		r := <-p.requestChannel
-		p.makeHTTPRequest(r)
+		if retry, _ := p.makeHTTPRequest(r); retry {
+			time.AfterFunc(r.retryDelay, func() {
+				p.requestChannel <- r
+			})
+		}
 	}
 }
 
@@ -157,7 +196,11 @@ var WebhookHttpClient = &http.Client{
 	Transport: otelhttp.NewTransport(http.DefaultTransport),
 }
 
-func (p *WebhookPublisher) makeHTTPRequest(r *publishRequest) {
+// makeHTTPRequest makes one attempt for r and logs its outcome once. It
+// returns retry=true when the attempt failed transiently and r still has retry
+// budget (r.retryDelay already grown for the next attempt); the caller decides
+// how to wait for it. err describes the failure; it is nil only on success.
+func (p *WebhookPublisher) makeHTTPRequest(r *publishRequest) (retry bool, err error) {
 	url := p.baseURL + "/" + strings.TrimPrefix(r.target, "/")
 
 	msg := fmt.Sprintf("making HTTP %s request", r.method)
@@ -185,7 +228,7 @@ func (p *WebhookPublisher) makeHTTPRequest(r *publishRequest) {
 	req, err := http.NewRequest(r.method, url, &buf)
 	if err != nil {
 		logger = logger.WithValues("error", err)
-		return
+		return false, err
 	}
 	for k, v := range r.headers {
 		req.Header.Set(k, v)
@@ -218,8 +261,10 @@ func (p *WebhookPublisher) makeHTTPRequest(r *publishRequest) {
 			logger = logger.WithValues("status_code", resp.StatusCode, "body", string(body))
 			if resp.StatusCode >= 200 && resp.StatusCode < 400 {
 				msgType = "info"
-				return
-			} else if resp.StatusCode == http.StatusNotFound {
+				return false, nil
+			}
+			err = fmt.Errorf("%s %s returned status code %d", r.method, url, resp.StatusCode)
+			if resp.StatusCode == http.StatusNotFound {
 				// The router returns 404 while a freshly created trigger's
 				// route is still propagating to the mux; treat it as
 				// transient and retry (bounded by maxRetries) instead of
@@ -243,24 +288,22 @@ func (p *WebhookPublisher) makeHTTPRequest(r *publishRequest) {
 				// fall through to retry scheduling below
 			} else if resp.StatusCode < 500 {
 				msg = "request returned bad request status code"
-				return
+				return false, err
 			} else {
 				msg = "request returned failure status code"
-				return
+				return false, err
 			}
 		}
 	}
 
-	// Schedule a retry, or give up if out of retries
+	// Ask for a retry, or give up if out of retries
 	r.retries--
 	if r.retries > 0 {
 		r.retryDelay *= time.Duration(2)
-		time.AfterFunc(r.retryDelay, func() {
-			p.requestChannel <- r
-		})
-	} else {
-		msg = "final retry failed, giving up"
-		msgType = "error" // dropped events always surface at error level
-		// Event dropped
+		return true, err
 	}
+	msg = "final retry failed, giving up"
+	msgType = "error" // dropped events always surface at error level
+	// Event dropped
+	return false, err
 }
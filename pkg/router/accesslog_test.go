@@ -32,6 +32,7 @@ type accessRecord struct {
 	FunctionUID       string `json:"fission.function.uid"`
 	StatusCode        int    `json:"http.status_code"`
 	Backend           string `json:"backend"`
+	CloudEventID      string `json:"cloudevents.event_id"`
 }
 
 // capturingSink records every Info line as JSON so a test can decode the one
@@ -69,17 +70,20 @@ func TestAccessRecord(t *testing.T) {
 	backend, _ := url.Parse("http://10.0.0.5:8888")
 	resp := &http.Response{StatusCode: 200}
 
-	emit := func(accessLog bool) *capturingSink {
+	emit := func(accessLog bool, ceID string) *capturingSink {
 		sink := &capturingSink{}
 		fh := functionHandler{logger: sink.logger(), function: fn, accessLog: accessLog}
 		req := httptest.NewRequest(http.MethodGet, "http://x/fn", nil)
+		if ceID != "" {
+			req.Header.Set("Ce-Id", ceID)
+		}
 		req = req.WithContext(correlation.NewContext(req.Context(), "req-xyz"))
 		fh.collectFunctionMetric(time.Now(), &RetryingRoundTripper{serviceURL: backend, totalRetry: 1}, req, resp)
 		return sink
 	}
 
 	t.Run("emits the access record when enabled", func(t *testing.T) {
-		rec, ok := emit(true).find(t, "function access")
+		rec, ok := emit(true, "").find(t, "function access")
 		require.True(t, ok, "access record must be emitted when DISPLAY_ACCESS_LOG is on")
 		assert.Equal(t, "req-xyz", rec.RequestID)
 		assert.Equal(t, "fn", rec.FunctionName)
@@ -87,10 +91,17 @@ func TestAccessRecord(t *testing.T) {
 		assert.Equal(t, string(fn.UID), rec.FunctionUID)
 		assert.Equal(t, 200, rec.StatusCode)
 		assert.Equal(t, "10.0.0.5:8888", rec.Backend)
+		assert.Empty(t, rec.CloudEventID)
+	})
+
+	t.Run("carries the CloudEvent id", func(t *testing.T) {
+		rec, ok := emit(true, "evt-1").find(t, "function access")
+		require.True(t, ok)
+		assert.Equal(t, "evt-1", rec.CloudEventID)
 	})
 
 	t.Run("no access record when disabled (default)", func(t *testing.T) {
-		_, ok := emit(false).find(t, "function access")
+		_, ok := emit(false, "").find(t, "function access")
 		assert.False(t, ok, "access record must be off by default")
 	})
 }
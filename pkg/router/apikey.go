@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/singleflight"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/router/respcache"
+)
+
+const (
+	// apiKeyCacheTTL is how long a read of an API-key Secret is trusted, and
+	// so how long a revoked key keeps working.
+	apiKeyCacheTTL = 30 * time.Second
+	// apiKeyFetchTimeout bounds one Secret read on the request path.
+	apiKeyFetchTimeout = 2 * time.Second
+	// defaultAPIKeyHeader carries the key when the trigger names no source.
+	defaultAPIKeyHeader = "X-Api-Key"
+	// attrAPIKeyName is the access-log attribute naming the key a request
+	// presented.
+	attrAPIKeyName = "fission.apikey.name"
+)
+
+var (
+	errAPIKeyMissing     = errors.New("unauthorized: API key required")
+	errAPIKeyInvalid     = errors.New("unauthorized: invalid API key")
+	errAPIKeyUnavailable = errors.New("API keys are temporarily unavailable")
+)
+
+// apiKeyStore reads the Secrets named by HTTPTrigger APIKey blocks on demand
+// and caches them for apiKeyCacheTTL. It gets Secrets one by one rather than
+// watching them, so the router needs only `get` on Secrets, never list. A
+// failed reread keeps serving the previous digests.
+type apiKeyStore struct {
+	logger     logr.Logger
+	kubeClient kubernetes.Interface
+	now        func() time.Time
+	group      singleflight.Group
+
+	mu      sync.Mutex
+	secrets map[string]apiKeySecret
+}
+
+// apiKeySecret maps each key name to the SHA-256 digest of the key.
+type apiKeySecret struct {
+	digests map[string][]byte
+	fetched time.Time
+}
+
+func newAPIKeyStore(logger logr.Logger, kubeClient kubernetes.Interface) *apiKeyStore {
+	return &apiKeyStore{logger: logger, kubeClient: kubeClient, now: time.Now, secrets: map[string]apiKeySecret{}}
+}
+
+// match returns the name of the key in namespace/secret whose digest is
+// key's, or "" when none is.
+func (s *apiKeyStore) match(ctx context.Context, namespace, secret, key string) (string, error) {
+	digests, err := s.digests(ctx, namespace, secret)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	var name string
+	// Compare against every entry so the time taken does not depend on
+	// which key matched.
+	for n, d := range digests {
+		if subtle.ConstantTimeCompare(sum[:], d) == 1 {
+			name = n
+		}
+	}
+	return name, nil
+}
+
+func (s *apiKeyStore) digests(ctx context.Context, namespace, secret string) (map[string][]byte, error) {
+	id := namespace + "/" + secret
+	s.mu.Lock()
+	cached, ok := s.secrets[id]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.fetched) < apiKeyCacheTTL {
+		return cached.digests, nil
+	}
+	v, err, _ := s.group.Do(id, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), apiKeyFetchTimeout)
+		defer cancel()
+		sec, err := s.kubeClient.CoreV1().Secrets(namespace).Get(ctx, secret, metav1.GetOptions{})
+		var digests map[string][]byte
+		switch {
+		case kerrors.IsNotFound(err):
+			// No Secret, no valid keys.
+			digests = map[string][]byte{}
+		case err != nil:
+			return nil, err
+		default:
+			digests = parseAPIKeyDigests(sec.Data)
+		}
+		s.mu.Lock()
+		s.secrets[id] = apiKeySecret{digests: digests, fetched: s.now()}
+		s.mu.Unlock()
+		return digests, nil
+	})
+	if err != nil {
+		if ok {
+			s.logger.Error(err, "error rereading API key secret; using the cached keys", "secret", id)
+			return cached.digests, nil
+		}
+		return nil, fmt.Errorf("reading API key secret %s: %w", id, err)
+	}
+	return v.(map[string][]byte), nil
+}
+
+// parseAPIKeyDigests decodes a Secret's entries, skipping any that are not a
+// hex SHA-256 digest.
+func parseAPIKeyDigests(data map[string][]byte) map[string][]byte {
+	digests := make(map[string][]byte, len(data))
+	for name, v := range data {
+		d, err := hex.DecodeString(strings.TrimSpace(string(v)))
+		if err != nil || len(d) != sha256.Size {
+			continue
+		}
+		digests[name] = d
+	}
+	return digests
+}
+
+// apiKeySource resolves where requests carry the key under cfg.
+func apiKeySource(cfg *fv1.HTTPTriggerAPIKey) (header, queryParam string) {
+	if cfg.Header == "" && cfg.QueryParam == "" {
+		return defaultAPIKeyHeader, ""
+	}
+	return cfg.Header, cfg.QueryParam
+}
+
+// apiKeyFromRequest returns the key req presents under cfg.
+func apiKeyFromRequest(cfg *fv1.HTTPTriggerAPIKey, req *http.Request) string {
+	header, queryParam := apiKeySource(cfg)
+	if header != "" {
+		if key := req.Header.Get(header); key != "" {
+			return key
+		}
+	}
+	if queryParam != "" {
+		return req.URL.Query().Get(queryParam)
+	}
+	return ""
+}
+
+// stripAPIKey removes the key from req once the router is done with it (a
+// rate limit may be keyed on the header), so the function never sees the
+// credential.
+func stripAPIKey(cfg *fv1.HTTPTriggerAPIKey, req *http.Request) {
+	header, queryParam := apiKeySource(cfg)
+	if header != "" {
+		req.Header.Del(header)
+	}
+	if queryParam != "" {
+		if q := req.URL.Query(); q.Has(queryParam) {
+			q.Del(queryParam)
+			req.URL.RawQuery = q.Encode()
+		}
+	}
+}
+
+type apiKeyNameKey struct{}
+
+// apiKeyName returns the name of the API key the request was admitted with.
+func apiKeyName(ctx context.Context) string {
+	name, _ := ctx.Value(apiKeyNameKey{}).(string)
+	return name
+}
+
+// admitAPIKey checks the request's API key against the trigger's Secret,
+// answering 401 for a missing or unknown key and 503 when the Secret cannot
+// be read. An admitted request carries the key's name in its context for the
+// access log. It returns the request to continue with, nil when it answered.
+func (fh functionHandler) admitAPIKey(rw http.ResponseWriter, req *http.Request) *http.Request {
+	cfg := fh.httpTrigger.Spec.APIKey
+	key := apiKeyFromRequest(cfg, req)
+	if key == "" {
+		recordAPIKeyRequest(req.Context(), fh.httpTrigger, "", apiKeyResultMissing)
+		fh.writeInvocationError(rw, req, http.StatusUnauthorized, ferror.ComponentRouter, ferror.ReasonUnauthenticated, errAPIKeyMissing.Error(), errAPIKeyMissing)
+		return nil
+	}
+	name, err := fh.apiKeys.match(req.Context(), fh.httpTrigger.Namespace, cfg.SecretName, key)
+	if err != nil {
+		fh.logger.Error(err, "error checking API key", "trigger", fh.httpTrigger.Name)
+		fh.writeInvocationError(rw, req, http.StatusServiceUnavailable, ferror.ComponentRouter, ferror.ReasonCredentialsUnavailable, errAPIKeyUnavailable.Error(), err)
+		return nil
+	}
+	if name == "" {
+		recordAPIKeyRequest(req.Context(), fh.httpTrigger, "", apiKeyResultInvalid)
+		fh.writeInvocationError(rw, req, http.StatusUnauthorized, ferror.ComponentRouter, ferror.ReasonUnauthenticated, errAPIKeyInvalid.Error(), errAPIKeyInvalid)
+		return nil
+	}
+	recordAPIKeyRequest(req.Context(), fh.httpTrigger, name, apiKeyResultAccepted)
+	// The key is stripped before the response cache sees the request; mark
+	// it so one key's private response is never served to another.
+	return respcache.WithCredentials(req.WithContext(context.WithValue(req.Context(), apiKeyNameKey{}, name)))
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/router/respcache"
+)
+
+func apiKeyDigest(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return []byte(hex.EncodeToString(sum[:]))
+}
+
+func apiKeySecretObject(data map[string][]byte) *apiv1.Secret {
+	return &apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ht-apikeys", Namespace: "default"}, Data: data}
+}
+
+func TestAPIKeyStore(t *testing.T) {
+	t.Parallel()
+	kube := k8sfake.NewClientset(apiKeySecretObject(map[string][]byte{
+		"partner-a": apiKeyDigest("key-a"),
+		"partner-b": apiKeyDigest("key-b"),
+		"garbage":   []byte("not-a-digest"),
+	}))
+	s := newAPIKeyStore(logr.Discard(), kube)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	name, err := s.match(t.Context(), "default", "ht-apikeys", "key-b")
+	require.NoError(t, err)
+	assert.Equal(t, "partner-b", name)
+	name, err = s.match(t.Context(), "default", "ht-apikeys", "not-a-digest")
+	require.NoError(t, err)
+	assert.Empty(t, name, "entries that are not digests never match")
+
+	// Revoke partner-a: it keeps working until the cached read expires.
+	require.NoError(t, kube.CoreV1().Secrets("default").Delete(t.Context(), "ht-apikeys", metav1.DeleteOptions{}))
+	_, err = kube.CoreV1().Secrets("default").Create(t.Context(), apiKeySecretObject(map[string][]byte{"partner-b": apiKeyDigest("key-b")}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	name, _ = s.match(t.Context(), "default", "ht-apikeys", "key-a")
+	assert.Equal(t, "partner-a", name)
+	now = now.Add(apiKeyCacheTTL)
+	name, _ = s.match(t.Context(), "default", "ht-apikeys", "key-a")
+	assert.Empty(t, name, "a revoked key stops working once the cache expires")
+
+	// A failing reread keeps serving the cached digests.
+	kube.PrependReactor("get", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("apiserver down")
+	})
+	now = now.Add(apiKeyCacheTTL)
+	name, err = s.match(t.Context(), "default", "ht-apikeys", "key-b")
+	require.NoError(t, err)
+	assert.Equal(t, "partner-b", name)
+	_, err = s.match(t.Context(), "default", "other", "key-b")
+	assert.Error(t, err, "nothing cached to fall back on")
+}
+
+func TestFunctionHandler_APIKey(t *testing.T) {
+	t.Parallel()
+	kube := k8sfake.NewClientset(apiKeySecretObject(map[string][]byte{"partner-a": apiKeyDigest("key-a")}))
+	newHandler := func(cfg *fv1.HTTPTriggerAPIKey) *functionHandler {
+		return &functionHandler{
+			logger:           logr.Discard(),
+			function:         &fv1.Function{ObjectMeta: metav1.ObjectMeta{Name: "fn", Namespace: "default"}},
+			structuredErrors: true,
+			apiKeys:          newAPIKeyStore(logr.Discard(), kube),
+			httpTrigger: &fv1.HTTPTrigger{
+				ObjectMeta: metav1.ObjectMeta{Name: "ht", Namespace: "default"},
+				Spec:       fv1.HTTPTriggerSpec{APIKey: cfg},
+			},
+		}
+	}
+
+	t.Run("header", func(t *testing.T) {
+		t.Parallel()
+		fh := newHandler(&fv1.HTTPTriggerAPIKey{SecretName: "ht-apikeys"})
+		req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+		req.Header.Set("X-Api-Key", "key-a")
+		admitted := fh.admitAPIKey(httptest.NewRecorder(), req)
+		require.NotNil(t, admitted)
+		assert.Equal(t, "partner-a", apiKeyName(admitted.Context()))
+		assert.False(t, respcache.Usable(admitted, &respcache.Entry{}), "a keyed request is served only public entries")
+
+		stripAPIKey(fh.httpTrigger.Spec.APIKey, admitted)
+		assert.Empty(t, admitted.Header.Get("X-Api-Key"), "the function never sees the key")
+	})
+
+	t.Run("query parameter", func(t *testing.T) {
+		t.Parallel()
+		fh := newHandler(&fv1.HTTPTriggerAPIKey{SecretName: "ht-apikeys", QueryParam: "api_key"})
+		req := httptest.NewRequest(http.MethodGet, "/fn?api_key=key-a&x=1", nil)
+		admitted := fh.admitAPIKey(httptest.NewRecorder(), req)
+		require.NotNil(t, admitted)
+		stripAPIKey(fh.httpTrigger.Spec.APIKey, admitted)
+		assert.Equal(t, "x=1", admitted.URL.RawQuery)
+	})
+
+	for name, key := range map[string]string{"missing key": "", "unknown key": "key-z"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			fh := newHandler(&fv1.HTTPTriggerAPIKey{SecretName: "ht-apikeys", Header: "X-Partner-Key"})
+			req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+			if key != "" {
+				req.Header.Set("X-Partner-Key", key)
+			}
+			rec := httptest.NewRecorder()
+			fh.handler(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			var body ferror.InvocationError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, ferror.ReasonUnauthenticated, body.Reason)
+		})
+	}
+}
@@ -5,6 +5,7 @@
 package router
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -55,11 +56,16 @@ type dlqMessage struct {
 	Function  string `json:"function,omitempty"`
 	// Topic is set for dead-lettered broker egress jobs (?queue=mq-egress-<type>)
 	// instead of Function.
-	Topic      string    `json:"topic,omitempty"`
-	Reason     string    `json:"reason,omitempty"`
-	Attempts   int       `json:"attempts"`
-	EnqueuedAt time.Time `json:"enqueuedAt"`
-	DiedAt     time.Time `json:"diedAt"`
+	Topic string `json:"topic,omitempty"`
+	// Webhook and Destination are set for dead-lettered webhook deliveries
+	// (?queue=webhook): the endpoint, and which of Function's destinations
+	// (onSuccess/onFailure) it is.
+	Webhook     string    `json:"webhook,omitempty"`
+	Destination string    `json:"destination,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Attempts    int       `json:"attempts"`
+	EnqueuedAt  time.Time `json:"enqueuedAt"`
+	DiedAt      time.Time `json:"diedAt"`
 }
 
 type dlqListResp struct {
@@ -74,6 +80,9 @@ type dlqShowResp struct {
 	// job (?queue=mq-egress-<type>) — payload included, so the operator can
 	// inspect the event that failed to publish.
 	EgressJob *mqpub.EgressJob `json:"egressJob,omitempty"`
+	// WebhookJob is set for a dead-lettered webhook delivery (?queue=webhook),
+	// the result envelope it carried included.
+	WebhookJob *asyncinvoke.WebhookJob `json:"webhookJob,omitempty"`
 }
 
 type dlqRedriveReq struct {
@@ -111,7 +120,8 @@ func (ts *HTTPTriggerSet) dlqQueue(w http.ResponseWriter) (statestore.Queue, boo
 var dlqEgressQueueRegexp = regexp.MustCompile(`^mq-egress-[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
 
 // dlqQueueName resolves the ?queue= parameter: empty means the async invocation
-// queue; otherwise it must be an RFC-0027 broker egress queue (mq-egress-<type>).
+// queue; otherwise it must be the webhook destination queue or an RFC-0027
+// broker egress queue (mq-egress-<type>).
 // Allowlisted by shape, not free-form — the DLQ surface must not become a
 // read/redrive/purge primitive over arbitrary statestore queues.
 func dlqQueueName(w http.ResponseWriter, r *http.Request) (string, bool) {
@@ -119,10 +129,10 @@ func dlqQueueName(w http.ResponseWriter, r *http.Request) (string, bool) {
 	if name == "" || name == asyncinvoke.DefaultQueue {
 		return asyncinvoke.DefaultQueue, true
 	}
-	if dlqEgressQueueRegexp.MatchString(name) {
+	if name == asyncinvoke.WebhookQueue || dlqEgressQueueRegexp.MatchString(name) {
 		return name, true
 	}
-	http.Error(w, "queue must be empty (async invocations) or an mq-egress-<type> egress queue", http.StatusBadRequest)
+	http.Error(w, "queue must be empty (async invocations), "+asyncinvoke.WebhookQueue+", or an mq-egress-<type> egress queue", http.StatusBadRequest)
 	return "", false
 }
 
@@ -246,7 +256,9 @@ func (ts *HTTPTriggerSet) dlqRedrive(w http.ResponseWriter, r *http.Request) {
 }
 
 // dlqPurge permanently deletes every dead-lettered invocation and reports the
-// count removed.
+// count removed. The spilled request bodies of the purged invocations are
+// released afterwards; one dead-lettered between the scan and the purge keeps
+// its body (a leak, never a body deleted under a live invocation).
 func (ts *HTTPTriggerSet) dlqPurge(w http.ResponseWriter, r *http.Request) {
 	q, ok := ts.dlqQueue(w)
 	if !ok {
@@ -256,18 +268,55 @@ func (ts *HTTPTriggerSet) dlqPurge(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	payloads := ts.asyncInvoker.payloads
+	var spilled []asyncinvoke.Envelope
+	if payloads != nil && queueName == asyncinvoke.DefaultQueue {
+		var err error
+		if spilled, err = dlqSpilled(r.Context(), q, queueName); err != nil {
+			ts.logger.Error(err, "reading async dead letters")
+			http.Error(w, "reading dead letters", http.StatusInternalServerError)
+			return
+		}
+	}
 	n, err := q.Purge(r.Context(), queueName)
 	if err != nil {
 		ts.logger.Error(err, "purging async dead letters")
 		http.Error(w, "purging dead letters", http.StatusInternalServerError)
 		return
 	}
+	ctx := context.WithoutCancel(r.Context())
+	for _, env := range spilled {
+		payloads.Release(ctx, env)
+	}
 	dlqWriteJSON(w, ts, dlqMutateResp{Count: n})
 }
 
+// dlqSpilled returns the dead-lettered envelopes of queueName that reference a
+// spilled request body. Records that do not decode are skipped.
+func dlqSpilled(ctx context.Context, q statestore.Queue, queueName string) ([]asyncinvoke.Envelope, error) {
+	var spilled []asyncinvoke.Envelope
+	token := ""
+	for {
+		dead, err := q.DeadLetters(ctx, queueName, statestore.Page{Token: token, Limit: dlqDefaultLimit})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dead {
+			if env, derr := asyncinvoke.Decode(d.Body); derr == nil && env.BodyRef != nil {
+				spilled = append(spilled, env)
+			}
+		}
+		if len(dead) < dlqDefaultLimit {
+			return spilled, nil
+		}
+		token = dead[len(dead)-1].ID
+	}
+}
+
 // dlqSummary maps a DeadMessage to the list summary, decoding the body for
 // display fields (best-effort — a corrupt record still lists): an async
-// invocation envelope yields namespace/function, a broker egress job yields
+// invocation envelope yields namespace/function, a webhook job yields the
+// source namespace/function and its webhook, a broker egress job yields
 // namespace/topic.
 func dlqSummary(d statestore.DeadMessage) dlqMessage {
 	m := dlqMessage{
@@ -277,6 +326,13 @@ func dlqSummary(d statestore.DeadMessage) dlqMessage {
 		EnqueuedAt: d.EnqueuedAt,
 		DiedAt:     d.DiedAt,
 	}
+	// A webhook job is tried first: it also has a function field, so the
+	// envelope decode would accept it.
+	if job, err := asyncinvoke.DecodeWebhookJob(d.Body); err == nil {
+		m.Namespace, m.Function = job.Namespace, job.Function
+		m.Webhook, m.Destination = job.Webhook.URL, job.Destination
+		return m
+	}
 	if env, err := asyncinvoke.Decode(d.Body); err == nil && env.Function != "" {
 		m.Namespace, m.Function = env.Namespace, env.Function
 		return m
@@ -293,7 +349,9 @@ func dlqWriteShow(w http.ResponseWriter, ts *HTTPTriggerSet, d statestore.DeadMe
 	// The gates mirror dlqSummary's classification: a lenient json.Unmarshal
 	// happily decodes an EgressJob body into a half-empty Envelope, so decode
 	// success alone must not pick the shape.
-	if env, err := asyncinvoke.Decode(d.Body); err == nil && env.Function != "" {
+	if job, err := asyncinvoke.DecodeWebhookJob(d.Body); err == nil {
+		resp.WebhookJob = &job
+	} else if env, err := asyncinvoke.Decode(d.Body); err == nil && env.Function != "" {
 		resp.Envelope = &env
 	} else if job := new(mqpub.EgressJob); json.Unmarshal(d.Body, job) == nil && job.Topic != "" {
 		resp.EgressJob = job
@@ -5,7 +5,10 @@
 package router
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -173,6 +176,55 @@ func TestDLQPurge(t *testing.T) {
 	assert.Empty(t, dead, "purge emptied the dead set")
 }
 
+// blobCounter is an in-memory asyncinvoke.BlobStore.
+type blobCounter struct{ blobs map[string][]byte }
+
+func (b *blobCounter) PutPayload(_ context.Context, ns, name string, r io.Reader, _ int64) error {
+	data, err := io.ReadAll(r)
+	b.blobs[ns+"/"+name] = data
+	return err
+}
+
+func (b *blobCounter) GetPayload(_ context.Context, ns, name string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(b.blobs[ns+"/"+name])), nil
+}
+
+func (b *blobCounter) DeletePayload(_ context.Context, ns, name string) error {
+	delete(b.blobs, ns+"/"+name)
+	return nil
+}
+
+// TestDLQPurgeReleasesSpilledPayloads dead-letters an invocation whose body was
+// spilled: the blob outlives the dead-lettering (for a redrive) and goes with
+// the purge.
+func TestDLQPurgeReleasesSpilledPayloads(t *testing.T) {
+	t.Parallel()
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	blobs := &blobCounter{blobs: map[string][]byte{}}
+	payloads := asyncinvoke.NewPayloads(kv, blobs, 1, logr.Discard())
+
+	r := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader("spilled body"))
+	_, err = asyncinvoke.Enqueue(t.Context(), q, httptest.NewRecorder(), r, asyncinvoke.Params{Namespace: "ns", Function: "fn", Payloads: payloads})
+	require.NoError(t, err)
+	l, err := q.Lease(t.Context(), asyncinvoke.DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	require.NoError(t, q.Kill(t.Context(), l[0].Receipt, "permanent"))
+	require.Len(t, blobs.blobs, 1)
+
+	ts := &HTTPTriggerSet{logger: logr.Discard(), asyncInvoker: &asyncInvoker{queue: q, logger: logr.Discard(), payloads: payloads}}
+	rr := httptest.NewRecorder()
+	ts.dlqPurge(rr, httptest.NewRequest(http.MethodPost, dlqPathPurge, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, blobs.blobs, "the purge collects the spilled body")
+}
+
 // TestDLQDisabledReturns501 asserts every DLQ handler fails closed with 501 when
 // async invocation is not enabled (nil invoker or nil queue), not a 404/500.
 func TestDLQDisabledReturns501(t *testing.T) {
@@ -277,3 +329,44 @@ func TestDLQShowEgressJob(t *testing.T) {
 	assert.Equal(t, "orders", resp.EgressJob.Topic)
 	assert.Equal(t, []byte("ev-1"), resp.EgressJob.Payload, "the failed event's payload is inspectable")
 }
+
+// TestDLQWebhookQueue: ?queue=webhook lists dead-lettered webhook deliveries
+// by their source function and destination, and show returns the job, never
+// an Envelope decoded from its function field.
+func TestDLQWebhookQueue(t *testing.T) {
+	t.Parallel()
+	ts, q, _ := dlqTestSet(t)
+	body, err := json.Marshal(asyncinvoke.WebhookJob{
+		Namespace: "ns1", Function: "fn-a", Destination: "onFailure", InvocationID: "inv-1",
+		Webhook: asyncinvoke.Webhook{URL: "https://hooks.example.com/x"},
+		Body:    []byte(`{"version":"1.0"}`),
+	})
+	require.NoError(t, err)
+	id, err := q.Enqueue(t.Context(), asyncinvoke.WebhookQueue, statestore.Message{Body: body}, statestore.EnqueueOptions{})
+	require.NoError(t, err)
+	l, err := q.Lease(t.Context(), asyncinvoke.WebhookQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	require.NoError(t, q.Kill(t.Context(), l[0].Receipt, asyncinvoke.ReasonHTTP4xx))
+
+	rr := httptest.NewRecorder()
+	ts.dlqList(rr, httptest.NewRequest(http.MethodGet, dlqPathList+"?queue=webhook", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list dlqListResp
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	require.Len(t, list.Messages, 1)
+	m := list.Messages[0]
+	assert.Equal(t, id, m.ID)
+	assert.Equal(t, "fn-a", m.Function)
+	assert.Equal(t, "onFailure", m.Destination)
+	assert.Equal(t, "https://hooks.example.com/x", m.Webhook)
+
+	rr = httptest.NewRecorder()
+	ts.dlqShow(rr, httptest.NewRequest(http.MethodGet, dlqPathShow+"?queue=webhook&id="+id, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var show dlqShowResp
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &show))
+	assert.Nil(t, show.Envelope)
+	require.NotNil(t, show.WebhookJob)
+	assert.Equal(t, "inv-1", show.WebhookJob.InvocationID)
+}
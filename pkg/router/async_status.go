@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/utils/httpmux"
+)
+
+// Async invocation status API — the surface behind `fission function
+// invocation get` and `cancel`. It reads (or cancels through) the lifecycle
+// record the dispatcher keeps for each invocation id it returned in a 202.
+// Same posture as the DLQ API: INTERNAL listener only, 501 when async
+// invocation is not enabled.
+const asyncStatusPath = "/v1/async/invocation"
+
+func (ts *HTTPTriggerSet) registerAsyncStatusRoutes(internal *httpmux.Mux) {
+	internal.HandleFunc(asyncStatusPath, ts.asyncStatus).Methods(http.MethodGet)
+	internal.HandleFunc(asyncStatusPath, ts.asyncCancel).Methods(http.MethodDelete)
+}
+
+// asyncStatus returns the status record of ?id in ?namespace: 404 when there
+// is none, because the id is unknown in that namespace or its record expired.
+func (ts *HTTPTriggerSet) asyncStatus(w http.ResponseWriter, r *http.Request) {
+	namespace, id, ok := ts.asyncStatusRequest(w, r)
+	if !ok {
+		return
+	}
+	st, err := ts.asyncInvoker.status.Get(r.Context(), namespace, id)
+	if errors.Is(err, statestore.ErrNotFound) {
+		http.Error(w, "no status recorded for this invocation (unknown id, or its record expired)", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		ts.logger.Error(err, "reading async invocation status", "namespace", namespace, "id", id)
+		http.Error(w, "reading invocation status", http.StatusInternalServerError)
+		return
+	}
+	dlqWriteJSON(w, ts, st)
+}
+
+// asyncCancel cancels the queued, scheduled or retrying invocation ?id in
+// ?namespace and returns its cancelled record: 404 when there is no record,
+// 409 when an attempt is already in flight or the invocation has settled.
+func (ts *HTTPTriggerSet) asyncCancel(w http.ResponseWriter, r *http.Request) {
+	namespace, id, ok := ts.asyncStatusRequest(w, r)
+	if !ok {
+		return
+	}
+	st, err := ts.asyncInvoker.status.Cancel(r.Context(), namespace, id)
+	switch {
+	case errors.Is(err, statestore.ErrNotFound):
+		http.Error(w, "no status recorded for this invocation (unknown id, or its record expired)", http.StatusNotFound)
+		return
+	case errors.Is(err, asyncinvoke.ErrNotCancellable):
+		http.Error(w, "invocation is already "+st.State+" and can no longer be cancelled", http.StatusConflict)
+		return
+	case err != nil:
+		ts.logger.Error(err, "cancelling async invocation", "namespace", namespace, "id", id)
+		http.Error(w, "cancelling invocation", http.StatusInternalServerError)
+		return
+	}
+	dlqWriteJSON(w, ts, st)
+}
+
+// asyncStatusRequest validates the ?namespace and ?id of a status API request,
+// writing the error response and returning false when it cannot be served.
+func (ts *HTTPTriggerSet) asyncStatusRequest(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	if ts.asyncInvoker == nil || !ts.asyncInvoker.enabled() || ts.asyncInvoker.status == nil {
+		http.Error(w, "async invocation is not enabled on this cluster", http.StatusNotImplemented)
+		return "", "", false
+	}
+	namespace, id := r.URL.Query().Get("namespace"), r.URL.Query().Get("id")
+	// Invocation ids are queue message ids, which may contain '/'; only the
+	// namespace is a path segment of the record's scope.
+	if namespace == "" || strings.Contains(namespace, "/") || len(namespace) > 253 || id == "" || len(id) > 253 {
+		http.Error(w, "namespace and id are required, and namespace must not contain '/'", http.StatusBadRequest)
+		return "", "", false
+	}
+	return namespace, id, true
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+)
+
+func TestAsyncStatus(t *testing.T) {
+	t.Parallel()
+	ts, caps := topicTestSet(t)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	ts.asyncInvoker.status = asyncinvoke.NewStatusStore(kv, time.Hour, logr.Discard())
+
+	r := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader("payload"))
+	id, err := asyncinvoke.Enqueue(t.Context(), ts.asyncInvoker.queue, httptest.NewRecorder(), r,
+		asyncinvoke.Params{Namespace: "ns1", Function: "fn", Status: ts.asyncInvoker.status})
+	require.NoError(t, err)
+
+	get := func(ts *HTTPTriggerSet, namespace, id string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		q := url.Values{"namespace": {namespace}, "id": {id}}.Encode()
+		ts.asyncStatus(rr, httptest.NewRequest(http.MethodGet, asyncStatusPath+"?"+q, nil))
+		return rr
+	}
+	rr := get(ts, "ns1", id)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var st asyncinvoke.InvocationStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &st))
+	assert.Equal(t, id, st.ID)
+	assert.Equal(t, asyncinvoke.StateQueued, st.State)
+
+	assert.Equal(t, http.StatusNotFound, get(ts, "ns2", id).Code, "another namespace")
+	assert.Equal(t, http.StatusNotFound, get(ts, "ns1", "asyncinv/unknown").Code)
+	assert.Equal(t, http.StatusBadRequest, get(ts, "a/b", id).Code, "slash namespace")
+	assert.Equal(t, http.StatusBadRequest, get(ts, "ns1", "").Code, "missing id")
+	assert.Equal(t, http.StatusNotImplemented, get(&HTTPTriggerSet{logger: logr.Discard()}, "ns1", id).Code, "async disabled")
+
+	cancel := func(namespace, id string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		q := url.Values{"namespace": {namespace}, "id": {id}}.Encode()
+		ts.asyncCancel(rr, httptest.NewRequest(http.MethodDelete, asyncStatusPath+"?"+q, nil))
+		return rr
+	}
+	assert.Equal(t, http.StatusNotFound, cancel("ns2", id).Code)
+	rr = cancel("ns1", id)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &st))
+	assert.Equal(t, asyncinvoke.StateCancelled, st.State)
+}
+
+type okDeliverer struct{}
+
+func (okDeliverer) Deliver(context.Context, asyncinvoke.Envelope, string, int) asyncinvoke.DeliveryResult {
+	return asyncinvoke.DeliveryResult{StatusCode: http.StatusOK}
+}
+
+// TestAsyncCancelConflict: a delivered invocation can no longer be cancelled.
+func TestAsyncCancelConflict(t *testing.T) {
+	t.Parallel()
+	ts, caps := topicTestSet(t)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	status := asyncinvoke.NewStatusStore(kv, time.Hour, logr.Discard())
+	ts.asyncInvoker.status = status
+
+	r := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader("payload"))
+	id, err := asyncinvoke.Enqueue(t.Context(), ts.asyncInvoker.queue, httptest.NewRecorder(), r,
+		asyncinvoke.Params{Namespace: "ns1", Function: "fn", Status: status})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	d := asyncinvoke.New(asyncinvoke.Options{
+		Queue: ts.asyncInvoker.queue, Deliverer: okDeliverer{}, Logger: logr.Discard(),
+		Status: status, PollInterval: 5 * time.Millisecond,
+	})
+	go func() { _ = d.Run(ctx) }()
+	require.Eventually(t, func() bool {
+		st, err := status.Get(t.Context(), "ns1", id)
+		return err == nil && st.State == asyncinvoke.StateSucceeded
+	}, 5*time.Second, 5*time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	q := url.Values{"namespace": {"ns1"}, "id": {id}}.Encode()
+	ts.asyncCancel(rr, httptest.NewRequest(http.MethodDelete, asyncStatusPath+"?"+q, nil))
+	assert.Equal(t, http.StatusConflict, rr.Code, rr.Body.String())
+}
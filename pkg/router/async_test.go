@@ -14,7 +14,6 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	"github.com/fission/fission/pkg/router/asyncinvoke"
@@ -259,72 +258,6 @@ func TestHandlerAsyncBranchTriggerMode(t *testing.T) {
 	require.Len(t, l, 1)
 }
 
-func TestDestinationsFromSpec(t *testing.T) {
-	t.Parallel()
-	onS, onF := destinationsFromSpec(nil, "ns")
-	assert.Nil(t, onS)
-	assert.Nil(t, onF)
-
-	ic := &fv1.InvocationConfig{
-		OnSuccess: &fv1.DestinationRef{Function: &fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "next"}},
-		OnFailure: &fv1.DestinationRef{Topic: &fv1.TopicRef{MessageQueueType: fv1.MessageQueueTypeStatestore, Topic: "errs"}},
-	}
-	onS, onF = destinationsFromSpec(ic, "ns")
-	require.NotNil(t, onS)
-	assert.Equal(t, "ns", onS.FunctionNamespace, "function destination inherits the source namespace")
-	assert.Equal(t, "next", onS.FunctionName)
-	assert.True(t, onS.IsFunction())
-	require.NotNil(t, onF)
-	assert.Equal(t, "ns", onF.FunctionNamespace, "topic destination inherits the source namespace too (RFC-0027)")
-	assert.Equal(t, "errs", onF.Topic)
-	assert.Equal(t, fv1.MessageQueueTypeStatestore, onF.MQType)
-	assert.True(t, onF.IsTopic())
-}
-
-// TestDestinationsFromSpec_AliasVersion pins the RFC-0025 Task 5 mapping: a
-// function destination's Alias/Version pin (fv1.FunctionReference) carries
-// through to the envelope-side Destination's own Alias/Version fields.
-func TestDestinationsFromSpec_AliasVersion(t *testing.T) {
-	t.Parallel()
-	ic := &fv1.InvocationConfig{
-		OnSuccess: &fv1.DestinationRef{Function: &fv1.FunctionReference{
-			Type: fv1.FunctionReferenceTypeFunctionName, Name: "next", Version: "next-v3",
-		}},
-		OnFailure: &fv1.DestinationRef{Function: &fv1.FunctionReference{
-			Type: fv1.FunctionReferenceTypeFunctionName, Name: "handler", Alias: "prod",
-		}},
-	}
-	onS, onF := destinationsFromSpec(ic, "ns")
-	require.NotNil(t, onS)
-	assert.Equal(t, "next-v3", onS.Version)
-	assert.Empty(t, onS.Alias)
-	require.NotNil(t, onF)
-	assert.Equal(t, "prod", onF.Alias)
-	assert.Empty(t, onF.Version)
-}
-
-func TestPolicyFromSpec(t *testing.T) {
-	t.Parallel()
-	assert.Equal(t, asyncinvoke.Policy{}, policyFromSpec(nil), "nil config → zero policy")
-
-	jitterOff := false
-	ic := &fv1.InvocationConfig{
-		Retry: fv1.RetryPolicy{
-			MaxAttempts: new(7),
-			BackoffBase: &metav1.Duration{Duration: 2 * time.Second},
-			BackoffCap:  &metav1.Duration{Duration: time.Minute},
-			Jitter:      &jitterOff,
-		},
-		MaxAge: &metav1.Duration{Duration: 3 * time.Hour},
-	}
-	got := policyFromSpec(ic)
-	assert.Equal(t, 7, got.MaxAttempts)
-	assert.Equal(t, 2*time.Second, got.BackoffBase)
-	assert.Equal(t, time.Minute, got.BackoffCap)
-	assert.Equal(t, 3*time.Hour, got.MaxAge)
-	assert.True(t, got.NoJitter, "Jitter:false → NoJitter:true")
-}
-
 // TestAsyncInvokerHandleDedup asserts the handler wires X-Fission-Dedup-Key
 // through to Enqueue: two async requests with the same key collapse to one
 // durable invocation (same id, one queued message).
@@ -370,3 +303,52 @@ func TestAsyncInvokerHandleIgnoresCallerDepth(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, env.Depth, "caller-supplied depth must not be trusted")
 }
+
+func TestAsyncInvokerHandleSchedule(t *testing.T) {
+	t.Parallel()
+	q := routerMemQueue(t)
+	inv := &asyncInvoker{queue: q, logger: logr.Discard(), maxInvokeDelay: time.Hour}
+	fn := &fv1.Function{Name: "fn", Namespace: "ns"}
+	send := func(header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/x", strings.NewReader("payload"))
+		r.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		inv.handle(w, r, fn)
+		return w
+	}
+
+	assert.Equal(t, 400, send(asyncinvoke.HeaderInvokeDelay, "2h").Code, "beyond the configured max delay")
+	assert.Equal(t, 400, send(asyncinvoke.HeaderInvokeAt, "not-a-time").Code)
+
+	require.Equal(t, 202, send(asyncinvoke.HeaderInvokeDelay, "30m").Code)
+	l, err := q.Lease(t.Context(), asyncinvoke.DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, l, "the scheduled invocation is not yet visible")
+	stats, err := q.Stats(t.Context(), asyncinvoke.DefaultQueue)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Visible)
+}
+
+func TestAsyncInvokerHandleMessageGroup(t *testing.T) {
+	t.Parallel()
+	q := routerMemQueue(t)
+	inv := &asyncInvoker{queue: q, logger: logr.Discard()}
+	fn := &fv1.Function{Name: "fn", Namespace: "ns"}
+	send := func(group string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/x", strings.NewReader("payload"))
+		r.Header.Set(asyncinvoke.HeaderMessageGroup, group)
+		w := httptest.NewRecorder()
+		inv.handle(w, r, fn)
+		return w
+	}
+
+	assert.Equal(t, 400, send(strings.Repeat("g", asyncinvoke.MaxMessageGroupLength+1)).Code)
+	require.Equal(t, 202, send("customer-42").Code)
+	require.Equal(t, 202, send("customer-42").Code)
+	l, err := q.(statestore.GroupedQueue).LeaseGrouped(t.Context(), asyncinvoke.DefaultQueue, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1, "the group's second invocation waits for the first")
+	env, err := asyncinvoke.Decode(l[0].Body)
+	require.NoError(t, err)
+	assert.Equal(t, "customer-42", env.MessageGroup)
+}
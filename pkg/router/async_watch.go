@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fission/fission/pkg/kubewatcher/watchlog"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/utils/httpmux"
+)
+
+// KubernetesWatchTrigger replay API — the surface behind `fission watch
+// replay`. With durable delivery the kubewatcher keeps each trigger's recent
+// events in a statestore stream (watchlog); replay re-enqueues a range of
+// them onto the async queue as fresh invocations. Same posture as the DLQ
+// and topic APIs: INTERNAL listener only, 501 when the statestore is not
+// wired.
+const (
+	watchPathReplay = "/v1/async/watch/replay"
+
+	// watchReplayDefault / watchReplayMax bound how many events one replay
+	// re-enqueues.
+	watchReplayDefault = 100
+	watchReplayMax     = 1000
+)
+
+// watchReplayReq names one trigger incarnation by its UID, which the CLI
+// reads from the trigger, and the first sequence number to replay.
+type watchReplayReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+	FromSeq   int64  `json:"fromSeq"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+// watchReplayResp reports the replayed range. FirstSeq above the requested
+// FromSeq means the older events were already trimmed; LastSeq below Head
+// means the limit cut the range short.
+type watchReplayResp struct {
+	Head     int64 `json:"head"`
+	FirstSeq int64 `json:"firstSeq,omitempty"`
+	LastSeq  int64 `json:"lastSeq,omitempty"`
+	Count    int64 `json:"count"`
+}
+
+func (ts *HTTPTriggerSet) registerWatchRoutes(internal *httpmux.Mux) {
+	internal.HandleFunc(watchPathReplay, ts.watchReplay).Methods(http.MethodPost)
+}
+
+// watchReplay re-enqueues the trigger's events from FromSeq on. A replayed
+// invocation carries watchlog.HeaderReplay and no dedup key: it is meant to
+// run again even if the original is still queued.
+func (ts *HTTPTriggerSet) watchReplay(w http.ResponseWriter, r *http.Request) {
+	if ts.asyncInvoker == nil || !ts.asyncInvoker.enabled() || ts.asyncInvoker.eventLog == nil {
+		http.Error(w, "watch replay is not enabled on this cluster (requires the statestore)", http.StatusNotImplemented)
+		return
+	}
+	var req watchReplayReq
+	if !dlqDecodeJSON(w, r, &req) {
+		return
+	}
+	for _, v := range []string{req.Namespace, req.Name, req.UID} {
+		if v == "" || strings.Contains(v, "/") || len(v) > 253 {
+			http.Error(w, "namespace, name and uid are required and must not contain '/'", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.FromSeq < 1 {
+		http.Error(w, "fromSeq must be at least 1", http.StatusBadRequest)
+		return
+	}
+	limit := watchReplayDefault
+	if req.Limit > 0 {
+		limit = min(req.Limit, watchReplayMax)
+	}
+
+	stream := watchlog.Stream(req.Namespace, req.Name, types.UID(req.UID))
+	head, err := ts.asyncInvoker.eventLog.Head(r.Context(), stream)
+	if err != nil {
+		ts.logger.Error(err, "watch replay: reading head", "stream", stream)
+		http.Error(w, "reading watch events", http.StatusInternalServerError)
+		return
+	}
+	events, err := ts.asyncInvoker.eventLog.Read(r.Context(), stream, req.FromSeq-1, limit)
+	if err != nil {
+		ts.logger.Error(err, "watch replay: reading events", "stream", stream)
+		http.Error(w, "reading watch events", http.StatusInternalServerError)
+		return
+	}
+
+	resp := watchReplayResp{Head: head}
+	now := time.Now()
+	for _, ev := range events {
+		env, err := watchlog.Envelope(ev, now)
+		if err != nil {
+			ts.logger.Error(err, "watch replay: skipping undecodable event", "stream", stream, "seq", ev.Seq)
+			continue
+		}
+		data, err := env.Encode()
+		if err == nil {
+			_, err = ts.asyncInvoker.queue.Enqueue(r.Context(), asyncinvoke.DefaultQueue, statestore.Message{Body: data}, statestore.EnqueueOptions{})
+		}
+		if err != nil {
+			// The events before this one are already enqueued; name it so
+			// the caller can resume from there.
+			ts.logger.Error(err, "watch replay: enqueuing event", "stream", stream, "seq", ev.Seq)
+			http.Error(w, fmt.Sprintf("enqueuing watch event %d failed, earlier events were replayed (see router logs)", ev.Seq), http.StatusBadGateway)
+			return
+		}
+		if resp.FirstSeq == 0 {
+			resp.FirstSeq = ev.Seq
+		}
+		resp.LastSeq = ev.Seq
+		resp.Count++
+	}
+	dlqWriteJSON(w, ts, resp)
+}
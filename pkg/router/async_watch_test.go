@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/kubewatcher/watchlog"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func TestWatchReplay(t *testing.T) {
+	t.Parallel()
+	ts, caps := topicTestSet(t)
+	q, err := caps.Queue()
+	require.NoError(t, err)
+
+	stream := watchlog.Stream("ns1", "kwt", "uid-1")
+	enqueued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, evType := range []string{"ADDED", "MODIFIED", "DELETED"} {
+		data, err := asyncinvoke.Envelope{
+			Version: asyncinvoke.EnvelopeVersion, Namespace: "ns1", Function: "fn", Method: http.MethodPost,
+			Headers: map[string]string{"X-Kubernetes-Event-Type": evType}, EnqueueTime: enqueued,
+		}.Encode()
+		require.NoError(t, err)
+		_, err = ts.asyncInvoker.eventLog.Append(t.Context(), stream, statestore.AppendAny,
+			[]statestore.Event{{Type: evType, Payload: data}})
+		require.NoError(t, err)
+	}
+
+	replay := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		ts.watchReplay(rr, httptest.NewRequest(http.MethodPost, watchPathReplay, strings.NewReader(body)))
+		return rr
+	}
+	rr := replay(`{"namespace":"ns1","name":"kwt","uid":"uid-1","fromSeq":2,"limit":1}`)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp watchReplayResp
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, watchReplayResp{Head: 3, FirstSeq: 2, LastSeq: 2, Count: 1}, resp)
+
+	msgs, err := q.Lease(t.Context(), asyncinvoke.DefaultQueue, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	env, err := asyncinvoke.Decode(msgs[0].Body)
+	require.NoError(t, err)
+	assert.Equal(t, "MODIFIED", env.Headers["X-Kubernetes-Event-Type"])
+	assert.Equal(t, "2", env.Headers[watchlog.HeaderSequence])
+	assert.Equal(t, "true", env.Headers[watchlog.HeaderReplay])
+	assert.True(t, env.EnqueueTime.After(enqueued), "a replay restarts the invocation's age")
+
+	// Another incarnation of the trigger has its own, empty stream.
+	rr = replay(`{"namespace":"ns1","name":"kwt","uid":"uid-2","fromSeq":1}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var empty watchReplayResp
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &empty))
+	assert.Equal(t, watchReplayResp{}, empty)
+}
+
+func TestWatchReplay_Rejects(t *testing.T) {
+	t.Parallel()
+	ts, _ := topicTestSet(t)
+	replay := func(ts *HTTPTriggerSet, body string) int {
+		rr := httptest.NewRecorder()
+		ts.watchReplay(rr, httptest.NewRequest(http.MethodPost, watchPathReplay, strings.NewReader(body)))
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, replay(ts, `{"namespace":"ns1","name":"kwt","fromSeq":1}`), "missing uid")
+	assert.Equal(t, http.StatusBadRequest, replay(ts, `{"namespace":"a/b","name":"kwt","uid":"u","fromSeq":1}`), "slash namespace")
+	assert.Equal(t, http.StatusBadRequest, replay(ts, `{"namespace":"ns1","name":"kwt","uid":"u","fromSeq":0}`), "fromSeq below 1")
+	assert.Equal(t, http.StatusBadRequest, replay(ts, `not json`), "malformed body")
+	assert.Equal(t, http.StatusNotImplemented,
+		replay(&HTTPTriggerSet{logger: logr.Discard()}, `{"namespace":"ns1","name":"kwt","uid":"u","fromSeq":1}`), "async disabled")
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/singleflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// webhookKeyCacheTTL is how long a read of a webhook signing Secret is
+	// trusted, and so how long a rotated key takes to be used.
+	webhookKeyCacheTTL = 30 * time.Second
+	// webhookKeyFetchTimeout bounds one Secret read.
+	webhookKeyFetchTimeout = 5 * time.Second
+)
+
+// webhookKeyStore reads the Secrets named by webhook destinations' signing
+// secrets, caching them for webhookKeyCacheTTL. Like apiKeyStore it gets
+// Secrets one by one, so the router needs only `get` on Secrets, and a failed
+// reread keeps signing with the previous keys.
+type webhookKeyStore struct {
+	logger     logr.Logger
+	kubeClient kubernetes.Interface
+	now        func() time.Time
+	group      singleflight.Group
+
+	mu      sync.Mutex
+	secrets map[string]webhookKeySecret
+}
+
+type webhookKeySecret struct {
+	data    map[string][]byte
+	fetched time.Time
+}
+
+func newWebhookKeyStore(logger logr.Logger, kubeClient kubernetes.Interface) *webhookKeyStore {
+	return &webhookKeyStore{logger: logger, kubeClient: kubeClient, now: time.Now, secrets: map[string]webhookKeySecret{}}
+}
+
+// signingKey returns the key under key in namespace/secret; it is the
+// dispatcher's asyncinvoke.SigningKeyFunc. A missing Secret or key is an error,
+// so the delivery is retried until it is created or the attempts run out.
+func (s *webhookKeyStore) signingKey(ctx context.Context, namespace, secret, key string) ([]byte, error) {
+	data, err := s.data(ctx, namespace, secret)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := data[key]
+	if !ok || len(v) == 0 {
+		return nil, fmt.Errorf("webhook signing secret %s/%s has no key %q", namespace, secret, key)
+	}
+	return v, nil
+}
+
+func (s *webhookKeyStore) data(ctx context.Context, namespace, secret string) (map[string][]byte, error) {
+	id := namespace + "/" + secret
+	s.mu.Lock()
+	cached, ok := s.secrets[id]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.fetched) < webhookKeyCacheTTL {
+		return cached.data, nil
+	}
+	v, err, _ := s.group.Do(id, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookKeyFetchTimeout)
+		defer cancel()
+		sec, err := s.kubeClient.CoreV1().Secrets(namespace).Get(ctx, secret, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.secrets[id] = webhookKeySecret{data: sec.Data, fetched: s.now()}
+		s.mu.Unlock()
+		return sec.Data, nil
+	})
+	if err != nil {
+		if ok {
+			s.logger.Error(err, "error rereading webhook signing secret; using the cached keys", "secret", id)
+			return cached.data, nil
+		}
+		return nil, fmt.Errorf("reading webhook signing secret %s: %w", id, err)
+	}
+	return v.(map[string][]byte), nil
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWebhookKeyStore(t *testing.T) {
+	t.Parallel()
+	kube := k8sfake.NewClientset(&apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "default"},
+		Data:       map[string][]byte{"hmac": []byte("old")},
+	})
+	s := newWebhookKeyStore(logr.Discard(), kube)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	key, err := s.signingKey(t.Context(), "default", "hook", "hmac")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), key)
+	_, err = s.signingKey(t.Context(), "default", "hook", "other")
+	assert.Error(t, err, "a missing key fails the delivery")
+	_, err = s.signingKey(t.Context(), "default", "absent", "hmac")
+	assert.Error(t, err, "a missing Secret fails the delivery")
+
+	// A rotated key is picked up once the cached read expires.
+	_, err = kube.CoreV1().Secrets("default").Update(t.Context(), &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "default"},
+		Data:       map[string][]byte{"hmac": []byte("new")},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	key, _ = s.signingKey(t.Context(), "default", "hook", "hmac")
+	assert.Equal(t, []byte("old"), key)
+	now = now.Add(webhookKeyCacheTTL)
+	key, _ = s.signingKey(t.Context(), "default", "hook", "hmac")
+	assert.Equal(t, []byte("new"), key)
+}
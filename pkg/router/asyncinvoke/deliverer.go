@@ -7,6 +7,8 @@ package asyncinvoke
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -57,6 +59,27 @@ type httpDeliverer struct {
 	client  *http.Client
 	baseURL string
 	logger  logr.Logger
+	// minBody is how much of a response body is captured even when no
+	// destination will consume it (WithResponseCapture).
+	minBody int
+	// payloads streams a spilled request body (Envelope.BodyRef) back.
+	payloads *Payloads
+}
+
+// DelivererOption configures NewHTTPDeliverer.
+type DelivererOption func(*httpDeliverer)
+
+// WithResponseCapture makes the deliverer capture up to n bytes of every
+// response body, for the invocation status record, not only the bodies a
+// destination consumes.
+func WithResponseCapture(n int) DelivererOption {
+	return func(h *httpDeliverer) { h.minBody = n }
+}
+
+// WithPayloads makes the deliverer stream spilled request bodies from p. An
+// envelope with a BodyRef fails delivery, and is retried, without one.
+func WithPayloads(p *Payloads) DelivererOption {
+	return func(h *httpDeliverer) { h.payloads = p }
 }
 
 // NewHTTPDeliverer builds a Deliverer that POSTs to the router internal listener
@@ -64,25 +87,30 @@ type httpDeliverer struct {
 // master is non-empty (the same signer the timer/mqtrigger publishers use, so
 // the router's internal verifier accepts it). An empty master leaves requests
 // unsigned (pass-through mode). A nil transport uses http.DefaultTransport.
-func NewHTTPDeliverer(baseURL string, master []byte, transport http.RoundTripper, logger logr.Logger) Deliverer {
+func NewHTTPDeliverer(baseURL string, master []byte, transport http.RoundTripper, logger logr.Logger, opts ...DelivererOption) Deliverer {
 	if transport == nil {
 		transport = http.DefaultTransport
 	}
 	if len(master) > 0 {
 		transport = hmacauth.ServiceSigner(master, hmacauth.ServiceRouterInternal, transport, time.Now)
 	}
-	return &httpDeliverer{
+	h := &httpDeliverer{
 		client:  &http.Client{Transport: transport},
 		baseURL: strings.TrimRight(baseURL, "/"),
 		logger:  logger,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *httpDeliverer) Deliver(ctx context.Context, env Envelope, invocationID string, attempt int) DeliveryResult {
 	// Deliver at the function's canonical internal URL (UrlForFunction folds the
 	// default namespace), preserving the query. The original trigger path is kept
 	// in the envelope for inspection but not replayed as a subpath in phase 1 —
-	// async delivery invokes the function, the body carries the event.
+	// async delivery invokes the function, the body carries the event. An
+	// explicit env.Subpath (a timer slot) is the one exception.
 	funcPath := utils.UrlForFunction(env.Function, env.Namespace)
 
 	// RFC-0025 Task 5: a version-pinned envelope tries the versioned internal
@@ -105,22 +133,36 @@ func (h *httpDeliverer) Deliver(ctx context.Context, env Envelope, invocationID
 	// re-delivered -- a function whose business logic legitimately answers
 	// 404 is no longer double-invoked on the version-pinned path.
 	if env.FunctionVersion != "" {
-		result := h.deliverOnce(ctx, env, invocationID, attempt, h.targetURL(utils.UrlForFunctionRef(env.Function, env.Namespace, env.FunctionVersion), env.Query))
+		result := h.deliverOnce(ctx, env, invocationID, attempt, h.targetURL(utils.UrlForFunctionRef(env.Function, env.Namespace, env.FunctionVersion)+env.Subpath, env.Query))
 		if result.Err == nil && result.StatusCode == http.StatusNotFound && result.routeMiss {
 			recordVersionFallback(ctx)
 			h.logger.Info("async delivery: versioned route not found, falling back to bare function route",
 				"namespace", env.Namespace, "function", env.Function, "version", env.FunctionVersion,
 				"invocationId", invocationID, "attempt", attempt)
-			return h.deliverOnce(ctx, env, invocationID, attempt, h.targetURL(funcPath, env.Query))
+			return h.deliverOnce(ctx, env, invocationID, attempt, h.targetURL(funcPath+env.Subpath, env.Query))
 		}
 		return result
 	}
-	return h.deliverOnce(ctx, env, invocationID, attempt, h.targetURL(funcPath, env.Query))
+	return h.deliverOnce(ctx, env, invocationID, attempt, h.targetURL(funcPath+env.Subpath, env.Query))
+}
+
+// openSpilled streams env's spilled body from the payload store (the
+// transport closes it). A missing or corrupt spilled body fails the attempt
+// like a transport error, so it is retried.
+func (h *httpDeliverer) openSpilled(ctx context.Context, env Envelope) (io.ReadCloser, error) {
+	if h.payloads == nil {
+		return nil, errors.New("asyncinvoke: envelope references a spilled payload but no payload store is configured")
+	}
+	rc, err := h.payloads.open(ctx, env.Namespace, *env.BodyRef)
+	if err != nil {
+		return nil, fmt.Errorf("asyncinvoke: opening spilled payload: %w", err)
+	}
+	return rc, nil
 }
 
 // targetURL joins the deliverer's baseURL with an internal-listener function
-// path (from utils.UrlForFunction, optionally suffixed `:<version>`) and an
-// optional query string.
+// path (from utils.UrlForFunction, optionally suffixed `:<version>` and
+// followed by the envelope's Subpath) and an optional query string.
 func (h *httpDeliverer) targetURL(funcPath, query string) string {
 	target := h.baseURL + "/" + strings.TrimPrefix(funcPath, "/")
 	if query != "" {
@@ -133,16 +175,30 @@ func (h *httpDeliverer) targetURL(funcPath, query string) string {
 // versioned URL, or the bare-name fallback. Broken out of Deliver so the
 // version-fallback retry above is a second call, not a duplicated request
 // build (a bytes.Reader can only be sent once, so each call gets its own
-// fresh one over env.Body).
+// fresh one over env.Body, or its own stream of a spilled body).
 func (h *httpDeliverer) deliverOnce(ctx context.Context, env Envelope, invocationID string, attempt int, target string) DeliveryResult {
 	method := env.Method
 	if method == "" {
 		method = http.MethodPost
 	}
-	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(env.Body))
+	var reqBody io.Reader = bytes.NewReader(env.Body)
+	if env.BodyRef != nil {
+		spilled, err := h.openSpilled(ctx, env)
+		if err != nil {
+			return DeliveryResult{Err: err}
+		}
+		reqBody = spilled
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
 	if err != nil {
 		return DeliveryResult{Err: err}
 	}
+	if env.BodyRef != nil {
+		// GetBody lets the HMAC signer hash a second stream instead of
+		// buffering the whole spilled body.
+		req.ContentLength = env.BodyRef.Size
+		req.GetBody = func() (io.ReadCloser, error) { return h.openSpilled(ctx, env) }
+	}
 	for k, v := range env.Headers {
 		req.Header.Set(k, v)
 	}
@@ -164,20 +220,25 @@ func (h *httpDeliverer) deliverOnce(ctx context.Context, env Envelope, invocatio
 	// OnFailure. So skip the up-to-64KiB read when the relevant destination is unset
 	// — a 2xx with only OnFailure, or a non-2xx with only OnSuccess, feeds nothing.
 	// This only ever skips a body no destination would consume (it never drops one a
-	// fire needs), and drains for keep-alive either way.
+	// fire needs), and drains for keep-alive either way. WithResponseCapture keeps
+	// a smaller prefix of every other body for the status record.
 	is2xx := resp.StatusCode >= 200 && resp.StatusCode < 300
 	needBody := (is2xx && env.OnSuccess != nil) || (!is2xx && env.OnFailure != nil)
-	if !needBody {
+	limit := h.minBody
+	if needBody {
+		limit = MaxPayloadBytes
+	}
+	if limit <= 0 {
 		_, _ = io.Copy(io.Discard, resp.Body)
 		return DeliveryResult{StatusCode: resp.StatusCode, routeMiss: routeMiss}
 	}
-	// Capture up to MaxPayloadBytes for a destination result envelope, flagging any
-	// truncation (over the cap, or a mid-stream read error that leaves the body
-	// incomplete), then drain the remainder so keep-alive can reuse the connection.
-	body, readErr := io.ReadAll(io.LimitReader(resp.Body, MaxPayloadBytes+1))
-	truncated := readErr != nil || len(body) > MaxPayloadBytes
-	if len(body) > MaxPayloadBytes {
-		body = body[:MaxPayloadBytes]
+	// Capture up to limit bytes, flagging any truncation (over the cap, or a
+	// mid-stream read error that leaves the body incomplete), then drain the
+	// remainder so keep-alive can reuse the connection.
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	truncated := readErr != nil || len(body) > limit
+	if len(body) > limit {
+		body = body[:limit]
 	}
 	_, _ = io.Copy(io.Discard, resp.Body)
 	return DeliveryResult{StatusCode: resp.StatusCode, Body: body, BodyTruncated: truncated, routeMiss: routeMiss}
@@ -94,6 +94,26 @@ func TestHTTPDelivererSkipsBodyWithoutDestination(t *testing.T) {
 	assert.False(t, res.BodyTruncated)
 }
 
+// TestHTTPDelivererResponseCapture: WithResponseCapture keeps a bounded prefix
+// of a body no destination consumes, for the status record.
+func TestHTTPDelivererResponseCapture(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "response-body")
+	}))
+	defer srv.Close()
+
+	d := NewHTTPDeliverer(srv.URL, nil, nil, logr.Discard(), WithResponseCapture(8))
+	res := d.Deliver(context.Background(), Envelope{Namespace: "ns", Function: "fn"}, "id", 1)
+	require.NoError(t, res.Err)
+	assert.Equal(t, []byte("response"), res.Body)
+	assert.True(t, res.BodyTruncated)
+
+	res = d.Deliver(context.Background(), Envelope{Namespace: "ns", Function: "fn", OnSuccess: &Destination{FunctionName: "s"}}, "id", 1)
+	assert.Equal(t, []byte("response-body"), res.Body, "a firing destination still gets the full body")
+}
+
 // TestHTTPDelivererCapturesOnlyFiringDestinationBody proves the capture is
 // status-aware: the body is read only for the destination this outcome fires (2xx
 // → OnSuccess, non-2xx → OnFailure), so the non-firing destination's presence does
@@ -131,6 +151,23 @@ func TestHTTPDelivererDefaultNamespaceFold(t *testing.T) {
 	assert.Equal(t, "/fission-function/fn", gotPath, "default namespace folds (matches the registered route)")
 }
 
+// TestHTTPDelivererSubpath: a timer-slot envelope's Subpath is replayed after
+// the function route (and after a version pin), ahead of the query.
+func TestHTTPDelivererSubpath(t *testing.T) {
+	t.Parallel()
+	var gotURI string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.URL.RequestURI()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	d := NewHTTPDeliverer(srv.URL, nil, nil, logr.Discard())
+	d.Deliver(context.Background(), Envelope{Namespace: "ns", Function: "fn", Subpath: "/sub", Query: "a=1"}, "id", 1)
+	assert.Equal(t, "/fission-function/ns/fn/sub?a=1", gotURI)
+	d.Deliver(context.Background(), Envelope{Namespace: "ns", Function: "fn", FunctionVersion: "fn-v2", Subpath: "/sub"}, "id", 1)
+	assert.Equal(t, "/fission-function/ns/fn:fn-v2/sub", gotURI)
+}
+
 func TestHTTPDelivererMethodDefaultsPost(t *testing.T) {
 	t.Parallel()
 	var gotMethod string
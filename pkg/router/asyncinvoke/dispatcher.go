@@ -9,11 +9,14 @@ import (
 	"errors"
 	"math/rand/v2"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
 
+	"github.com/fission/fission/pkg/cloudevents"
 	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/utils/backoff"
 )
@@ -95,6 +98,10 @@ type FunctionConfig struct {
 	OnSuccess       *Destination
 	OnFailure       *Destination
 	FunctionTimeout int
+	// Throttle is read at delivery rather than stamped into the envelope, so
+	// a cap set on a function also holds back the invocations already queued
+	// for it (a DLQ redrive, a backlog).
+	Throttle Throttle
 }
 
 // FunctionConfigResolver resolves a function's async config at destination-fire
@@ -143,6 +150,18 @@ type Options struct {
 	// nil → topic destinations are dropped as unsupported (logged + metered).
 	PublishTopic TopicPublishFunc
 
+	// Status records each invocation's attempts and outcome. nil → no status
+	// records.
+	Status *StatusStore
+
+	// Payloads garbage-collects an invocation's spilled request body once its
+	// message is acked. nil → envelopes carry no spilled bodies.
+	Payloads *Payloads
+
+	// Throttler enforces each function's Throttle, resolved through
+	// ResolveFunctionConfig. nil → deliveries are unthrottled.
+	Throttler *Throttler
+
 	Now  func() time.Time // nil → time.Now
 	Rand func() float64   // nil → rand/v2 Float64; returns [0,1) for backoff jitter
 }
@@ -163,8 +182,14 @@ type Dispatcher struct {
 	leaseDuration time.Duration
 	resolveFn     FunctionConfigResolver
 	publishFn     TopicPublishFunc
+	status        *StatusStore
+	payloads      *Payloads
+	throttler     *Throttler
 	now           func() time.Time
 	rand          func() float64
+	// ungrouped is set once the queue turned out not to lease by message
+	// group, so later polls go straight to Lease.
+	ungrouped atomic.Bool
 }
 
 // New builds a Dispatcher from Options, applying defaults.
@@ -179,6 +204,9 @@ func New(opts Options) *Dispatcher {
 		leaseDuration: opts.LeaseDuration,
 		resolveFn:     opts.ResolveFunctionConfig,
 		publishFn:     opts.PublishTopic,
+		status:        opts.Status,
+		payloads:      opts.Payloads,
+		throttler:     opts.Throttler,
 		now:           opts.Now,
 		rand:          opts.Rand,
 	}
@@ -223,7 +251,7 @@ func (d *Dispatcher) Run(ctx context.Context) error {
 
 // pollOnce leases one batch and delivers it concurrently, returning the count.
 func (d *Dispatcher) pollOnce(ctx context.Context) int {
-	msgs, err := d.q.Lease(ctx, d.queueName, d.batchSize, d.leaseDuration)
+	msgs, err := d.lease(ctx)
 	if err != nil {
 		if ctx.Err() == nil {
 			d.logger.Error(err, "lease failed", "queue", d.queueName)
@@ -238,6 +266,21 @@ func (d *Dispatcher) pollOnce(ctx context.Context) int {
 	return len(msgs)
 }
 
+// lease leases one batch, by message group when the queue supports it so a
+// group's invocations are delivered one at a time, in order. Over a queue that
+// does not, grouped invocations are delivered unordered, which is logged once.
+func (d *Dispatcher) lease(ctx context.Context) ([]statestore.LeasedMessage, error) {
+	if gq, ok := d.q.(statestore.GroupedQueue); ok && !d.ungrouped.Load() {
+		msgs, err := gq.LeaseGrouped(ctx, d.queueName, d.batchSize, d.leaseDuration)
+		if !errors.Is(err, statestore.ErrCapabilityUnavailable) {
+			return msgs, err
+		}
+		d.ungrouped.Store(true)
+		d.logger.Info("the state store cannot lease by message group; message groups will be delivered unordered", "queue", d.queueName)
+	}
+	return d.q.Lease(ctx, d.queueName, d.batchSize, d.leaseDuration)
+}
+
 // process delivers one leased invocation and settles it per the settle matrix.
 // The terminal settle (Ack/Nack/Kill) runs on a context detached from ctx, so a
 // settle for already-completed work still lands during a graceful drain rather
@@ -257,11 +300,28 @@ func (d *Dispatcher) process(ctx context.Context, msg statestore.LeasedMessage)
 
 	// Dead-letter an invocation that waited past its MaxAge before delivering it —
 	// no delivery happened, so the result envelope carries a zero response.
-	if d.now().Sub(env.EnqueueTime) > policy.MaxAge {
+	if d.now().Sub(env.ageOrigin()) > policy.MaxAge {
 		d.settleFail(sctx, msg, env, DeliveryResult{}, ReasonExpired, ConditionEventAgeExceeded)
 		return
 	}
 
+	throttled, release := d.throttle(sctx, msg, env)
+	if throttled {
+		return
+	}
+
+	// A cancelled invocation is acked undelivered: no attempt, no destination.
+	if !d.status.start(sctx, msg.ID, env, msg.Attempts) {
+		release(sctx)
+		if err := d.q.Ack(sctx, msg.Receipt); err != nil {
+			d.logSettle("ack", msg.ID, err)
+			return
+		}
+		d.payloads.Release(sctx, env)
+		recordCancelled(sctx)
+		d.logger.V(1).Info("async invocation cancelled before delivery", "id", msg.ID, "namespace", env.Namespace, "function", env.Function)
+		return
+	}
 	dctx, dcancel := context.WithTimeout(ctx, d.deliveryTimeout(env))
 	res := d.deliverer.Deliver(dctx, env, msg.ID, msg.Attempts)
 	dcancel()
@@ -281,6 +341,7 @@ func (d *Dispatcher) process(ctx context.Context, msg statestore.LeasedMessage)
 	scancel()
 	sctx, scancel = context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
 	defer scancel()
+	release(sctx)
 
 	recordDelivery(sctx, deliveryCondition(res))
 
@@ -304,6 +365,40 @@ func (d *Dispatcher) process(ctx context.Context, msg statestore.LeasedMessage)
 	}
 }
 
+// throttle holds env back when its function is at its MaxInFlight or
+// MaxDeliveriesPerSecond cap: the message is deferred — requeued with its
+// attempt refunded, since it was never delivered — and throttled is true.
+// Otherwise release frees the in-flight slot the delivery took.
+func (d *Dispatcher) throttle(ctx context.Context, msg statestore.LeasedMessage, env Envelope) (throttled bool, release func(context.Context)) {
+	release = func(context.Context) {}
+	if d.throttler == nil || d.resolveFn == nil {
+		return false, release
+	}
+	// An alias-pinned destination routes to "<function>:<alias>"; the caps are
+	// the function's.
+	function, _, _ := strings.Cut(env.Function, ":")
+	cfg, found := d.resolveFn(ctx, env.Namespace, function)
+	if !found || !cfg.Throttle.enabled() {
+		return false, release
+	}
+	now := d.now()
+	reason, wait := d.throttler.acquire(ctx, env.Namespace, function, msg.ID, cfg.Throttle, now, now.Add(d.deliveryTimeout(env)))
+	if reason == "" {
+		if cfg.Throttle.MaxInFlight > 0 {
+			release = func(ctx context.Context) { d.throttler.release(ctx, env.Namespace, function, msg.ID) }
+		}
+		return false, release
+	}
+	// Spread the deferred messages so they do not all return at once.
+	wait += time.Duration(d.rand() * float64(wait) / 2)
+	if err := d.q.Defer(ctx, msg.Receipt, wait); err != nil {
+		d.logSettle("defer", msg.ID, err)
+		return true, release
+	}
+	recordThrottled(ctx, reason)
+	return true, release
+}
+
 // settleSuccess acks the delivered message and, only when the Ack actually landed
 // (not a stale receipt — A3), fires the OnSuccess destination. It mirrors
 // settleFail so every settle arm of process is a single settle-then-fire call.
@@ -312,6 +407,10 @@ func (d *Dispatcher) settleSuccess(ctx context.Context, msg statestore.LeasedMes
 		d.logSettle("ack", msg.ID, err)
 		return // a stale/failed ack must not fire the OnSuccess destination (A3)
 	}
+	// A dead-lettered invocation keeps its spilled body for a redrive; only
+	// the ack (or a DLQ purge) lets it go.
+	d.payloads.Release(ctx, env)
+	d.status.settled(ctx, msg.ID, env, msg.Attempts, StateSucceeded, res, "", time.Time{})
 	d.fireDestination(ctx, env.OnSuccess, env.Depth, d.buildResult(env, msg, ConditionSuccess, res))
 }
 
@@ -326,7 +425,7 @@ func (d *Dispatcher) retry(ctx context.Context, msg statestore.LeasedMessage, en
 	backoff := d.backoff(policy, msg.Attempts)
 	// If the retry would land after MaxAge, dead-letter now rather than requeue
 	// work that can only expire (invariant A4: the reason is the true one).
-	if d.now().Add(backoff).Sub(env.EnqueueTime) > policy.MaxAge {
+	if d.now().Add(backoff).Sub(env.ageOrigin()) > policy.MaxAge {
 		d.settleFail(ctx, msg, env, res, ReasonExpired, ConditionEventAgeExceeded)
 		return
 	}
@@ -335,6 +434,7 @@ func (d *Dispatcher) retry(ctx context.Context, msg statestore.LeasedMessage, en
 		return
 	}
 	recordRetry(ctx)
+	d.status.settled(ctx, msg.ID, env, msg.Attempts, StateRetrying, res, "", d.now().Add(backoff))
 }
 
 // settleFail dead-letters the message and, only when the Kill actually settled
@@ -344,6 +444,7 @@ func (d *Dispatcher) settleFail(ctx context.Context, msg statestore.LeasedMessag
 	if !d.killReason(ctx, msg, reason) {
 		return
 	}
+	d.status.settled(ctx, msg.ID, env, msg.Attempts, StateFailed, res, reason, time.Time{})
 	d.fireDestination(ctx, env.OnFailure, env.Depth, d.buildResult(env, msg, condition, res))
 }
 
@@ -387,6 +488,10 @@ func (d *Dispatcher) fireDestination(ctx context.Context, dest *Destination, dep
 		d.publishTopicDestination(ctx, dest, result)
 		return
 	}
+	if dest.IsWebhook() {
+		d.enqueueWebhook(ctx, dest, result)
+		return
+	}
 	next := depth + 1
 	// Drop once the chain would exceed MaxChainDepth; the negative/zero guard also
 	// rejects a forged envelope with a corrupt (negative or overflowed) depth, so the
@@ -419,6 +524,10 @@ func (d *Dispatcher) fireDestination(ctx context.Context, dest *Destination, dep
 		d.logger.Error(err, "encoding destination result envelope", "function", dest.FunctionName)
 		return
 	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	if dest.CloudEvents {
+		headers = result.resultEvent(body).BinaryHeaders()
+	}
 	env := Envelope{
 		Version:   EnvelopeVersion,
 		Namespace: dest.FunctionNamespace,
@@ -429,7 +538,7 @@ func (d *Dispatcher) fireDestination(ctx context.Context, dest *Destination, dep
 		// doc comment for why this must not dead-letter on routine GC).
 		FunctionVersion: dest.Version,
 		Method:          http.MethodPost,
-		Headers:         map[string]string{"Content-Type": "application/json"},
+		Headers:         headers,
 		Body:            body,
 		EnqueueTime:     d.now(),
 		Depth:           next,
@@ -474,7 +583,17 @@ func (d *Dispatcher) publishTopicDestination(ctx context.Context, dest *Destinat
 	}
 	// The result envelope is JSON by construction, so the delivery Content-Type a
 	// consuming trigger replays is application/json.
-	if err := d.publishFn(ctx, dest.FunctionNamespace, dest.MQType, dest.Topic, "application/json", body); err != nil {
+	contentType := "application/json"
+	if dest.CloudEvents {
+		if body, err = result.resultEvent(body).Marshal(); err != nil {
+			recordDestination(ctx, "encode_error")
+			d.logger.Error(err, "encoding destination CloudEvent",
+				"namespace", dest.FunctionNamespace, "topic", dest.Topic)
+			return
+		}
+		contentType = cloudevents.ContentTypeStructured
+	}
+	if err := d.publishFn(ctx, dest.FunctionNamespace, dest.MQType, dest.Topic, contentType, body); err != nil {
 		if errors.Is(err, ErrTopicUnsupported) {
 			// Admission enforces the supported set, so this is a forged or legacy
 			// envelope — expected-shaped noise, dropped at Info (distinct from an
@@ -494,7 +613,7 @@ func (d *Dispatcher) publishTopicDestination(ctx context.Context, dest *Destinat
 
 // buildResult assembles the Lambda-shaped result envelope for a destination. The
 // request payload is included only when the original body fits MaxPayloadBytes
-// (RequestPayloadOmitted flags the elision); the response payload was captured and
+// and was not spilled (RequestPayloadOmitted flags the elision); the response payload was captured and
 // truncation-flagged by the deliverer, so a destination can tell partial from whole.
 func (d *Dispatcher) buildResult(env Envelope, msg statestore.LeasedMessage, condition string, res DeliveryResult) ResultEnvelope {
 	re := ResultEnvelope{
@@ -509,7 +628,7 @@ func (d *Dispatcher) buildResult(env Envelope, msg statestore.LeasedMessage, con
 		ResponseContext: ResponseContext{StatusCode: res.StatusCode, Truncated: res.BodyTruncated},
 		ResponsePayload: res.Body,
 	}
-	if len(env.Body) <= MaxPayloadBytes {
+	if env.BodyRef == nil && len(env.Body) <= MaxPayloadBytes {
 		re.RequestPayload = env.Body
 	} else {
 		re.RequestPayloadOmitted = true
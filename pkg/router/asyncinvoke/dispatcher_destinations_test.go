@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/fission/fission/pkg/cloudevents"
 	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/utils"
 )
@@ -400,3 +401,50 @@ func TestFireDestinationTopicPublishError(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, l)
 }
+
+// TestFireDestinationCloudEvents: a destination with CloudEvents set gets the
+// result envelope as a CloudEvent, binary mode for a function and structured
+// mode for a topic, with the same invocation-derived id either way.
+func TestFireDestinationCloudEvents(t *testing.T) {
+	t.Parallel()
+	q := memQueue(t)
+	rec := &publishRecorder{}
+	d := destDispatcher(q, scriptedDeliverer{}, time.Unix(1, 0), resolverFor(FunctionConfig{}))
+	d.publishFn = rec.publish
+	result := ResultEnvelope{
+		Version:        EnvelopeVersion,
+		RequestContext: RequestContext{InvocationID: "id-1", FunctionRef: "ns/src", Condition: ConditionRetriesExhausted, Attempts: 3},
+	}
+	wantID := cloudevents.NewID("id-1", ConditionRetriesExhausted)
+
+	d.fireDestination(context.Background(), &Destination{FunctionNamespace: "ns", FunctionName: "next", CloudEvents: true}, 0, result)
+	l, err := q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	destEnv, err := Decode(l[0].Body)
+	require.NoError(t, err)
+	h := http.Header{}
+	for k, v := range destEnv.Headers {
+		h.Set(k, v)
+	}
+	ev, err := cloudevents.FromHTTP(h, destEnv.Body)
+	require.NoError(t, err)
+	require.NoError(t, ev.Validate())
+	assert.Equal(t, wantID, ev.ID)
+	assert.Equal(t, EventTypeFailure, ev.Type)
+	assert.Equal(t, "/fission/namespaces/ns/functions/src", ev.Source)
+	assert.Equal(t, "id-1", ev.Subject)
+	var re ResultEnvelope
+	require.NoError(t, json.Unmarshal(ev.Data, &re), "the data is the result envelope")
+	assert.Equal(t, 3, re.RequestContext.Attempts)
+
+	d.fireDestination(context.Background(), &Destination{FunctionNamespace: "ns", Topic: "orders", MQType: MQTypeStatestore, CloudEvents: true}, 0, result)
+	calls := rec.recorded()
+	require.Len(t, calls, 1)
+	assert.Equal(t, cloudevents.ContentTypeStructured, calls[0].contentType)
+	ev, err = cloudevents.Unmarshal(calls[0].payload)
+	require.NoError(t, err)
+	assert.Equal(t, wantID, ev.ID)
+	require.NoError(t, json.Unmarshal(ev.Data, &re))
+	assert.Equal(t, "id-1", re.RequestContext.InvocationID)
+}
@@ -34,6 +34,12 @@ type Params struct {
 	FunctionVersion string
 	Depth           int
 	DedupKey        string
+	// InvokeAt schedules the invocation (see ParseSchedule): the message is
+	// enqueued invisible until then. Zero (or a past time) delivers immediately.
+	InvokeAt time.Time
+	// MessageGroup orders the invocation behind earlier ones of the function
+	// in the same group (see ParseMessageGroup); empty is unordered.
+	MessageGroup string
 	// Policy is the resolved retry/age policy stamped into the envelope (zero
 	// fields take dispatcher defaults).
 	Policy Policy
@@ -42,9 +48,14 @@ type Params struct {
 	OnSuccess *Destination
 	OnFailure *Destination
 	// QueueName defaults to DefaultQueue when empty. MaxBodyBytes defaults to
-	// DefaultMaxBodyBytes when <= 0.
+	// DefaultMaxBodyBytes when <= 0 (DefaultMaxSpillBytes with Payloads).
 	QueueName    string
 	MaxBodyBytes int64
+	// Status, when set, gets a queued record for the new invocation.
+	Status *StatusStore
+	// Payloads, when set, spills a body above its threshold out of the
+	// envelope instead of the message carrying it.
+	Payloads *Payloads
 }
 
 // Enqueue reads the request body under a cap, builds the durable Envelope, and
@@ -56,10 +67,15 @@ type Params struct {
 // oversized request is rejected without buffering it whole, and a mid-body read
 // failure produces an error rather than a partial enqueue (invariant A1).
 func Enqueue(ctx context.Context, q statestore.Queue, w http.ResponseWriter, r *http.Request, p Params) (string, error) {
-	body, err := readCappedBody(w, r, p.MaxBodyBytes)
+	maxBytes := p.MaxBodyBytes
+	if maxBytes <= 0 && p.Payloads != nil {
+		maxBytes = DefaultMaxSpillBytes
+	}
+	body, err := readCappedBody(w, r, maxBytes)
 	if err != nil {
 		return "", err
 	}
+	now := time.Now()
 	env := Envelope{
 		Version:         EnvelopeVersion,
 		Namespace:       p.Namespace,
@@ -70,7 +86,8 @@ func Enqueue(ctx context.Context, q statestore.Queue, w http.ResponseWriter, r *
 		Query:           r.URL.RawQuery,
 		Headers:         allowedHeaders(r.Header),
 		Body:            body,
-		EnqueueTime:     time.Now(),
+		EnqueueTime:     now,
+		MessageGroup:    p.MessageGroup,
 		Depth:           p.Depth,
 		FunctionTimeout: p.FunctionTimeout,
 		Policy:          p.Policy,
@@ -81,7 +98,31 @@ func Enqueue(ctx context.Context, q statestore.Queue, w http.ResponseWriter, r *
 	if queue == "" {
 		queue = DefaultQueue
 	}
-	return encodeAndEnqueue(ctx, q, queue, env, statestore.EnqueueOptions{DedupKey: p.DedupKey})
+	opts := statestore.EnqueueOptions{DedupKey: p.DedupKey}
+	if p.MessageGroup != "" {
+		opts.Group = GroupKey(p.Namespace, p.Function, p.MessageGroup)
+	}
+	if p.InvokeAt.After(now) {
+		env.InvokeAt = p.InvokeAt
+		opts.Delay = p.InvokeAt.Sub(now)
+	}
+	if p.Payloads != nil && len(body) > p.Payloads.threshold {
+		if env.BodyRef, err = p.Payloads.spill(ctx, p.Namespace, body); err != nil {
+			return "", err
+		}
+		env.Body = nil
+	}
+	id, err := encodeAndEnqueue(ctx, q, queue, env, opts)
+	if err != nil {
+		p.Payloads.Release(ctx, env)
+		return "", err
+	}
+	if !p.Status.Queued(ctx, id, env) {
+		// A dedup-collapsed enqueue returned the original invocation's id; this
+		// copy of the body was never queued.
+		p.Payloads.Release(ctx, env)
+	}
+	return id, nil
 }
 
 // encodeAndEnqueue is the single write path for the durable envelope wire-shape:
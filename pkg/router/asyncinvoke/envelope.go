@@ -18,6 +18,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/fission/fission/pkg/cloudevents"
 )
 
 const (
@@ -36,12 +38,15 @@ const (
 	DefaultMaxBodyBytes = 256 << 10
 )
 
-// Request/replay headers. The enqueue branch reads the first three from the
+// Request/replay headers. The enqueue branch reads the first five from the
 // incoming request; the dispatcher sets the last three on each delivery so the
 // function (and downstream correlation) can see the invocation identity.
 const (
 	HeaderInvokeMode        = "X-Fission-Invoke-Mode"        // "async" opts a request into async mode
 	HeaderDedupKey          = "X-Fission-Dedup-Key"          // idempotency key for enqueue collapse
+	HeaderInvokeAt          = "X-Fission-Invoke-At"          // RFC3339 time to deliver a scheduled invocation
+	HeaderInvokeDelay       = "X-Fission-Invoke-Delay"       // Go duration to delay a scheduled invocation by
+	HeaderMessageGroup      = "X-Fission-Message-Group"      // FIFO key: one group's invocations deliver in order
 	HeaderInvocationID      = "X-Fission-Invocation-Id"      // durable invocation id, replayed on delivery
 	HeaderInvocationAttempt = "X-Fission-Invocation-Attempt" // 1-based delivery attempt, replayed on delivery
 	HeaderInvocationDepth   = "X-Fission-Invocation-Depth"   // destination-chain depth, replayed on delivery
@@ -100,9 +105,26 @@ type Envelope struct {
 	Query   string            `json:"query,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 	Body    []byte            `json:"body,omitempty"`
+	// BodyRef, when set, replaces Body: the request body was above the spill
+	// threshold and lives in the payload store (see Payloads), streamed back
+	// on delivery and deleted once the invocation is acked or purged.
+	BodyRef *PayloadRef `json:"bodyRef,omitempty"`
+	// Subpath is appended to the function route on delivery. Only the timer's
+	// durable slot enqueue sets it (TimeTriggerSpec.Subpath, which the direct
+	// publisher always replayed); a router enqueue leaves it empty, its
+	// original request path staying inspection-only in Path.
+	Subpath string `json:"subpath,omitempty"`
 	// EnqueueTime is when the request was accepted; the dispatcher measures MaxAge
-	// from it.
+	// from it, or from InvokeAt for a scheduled invocation.
 	EnqueueTime time.Time `json:"enqueueTime"`
+	// InvokeAt is when a scheduled invocation (HeaderInvokeAt/HeaderInvokeDelay)
+	// becomes deliverable; zero for an immediate one. The queue message is
+	// enqueued with the matching visibility delay.
+	InvokeAt time.Time `json:"invokeAt,omitzero"`
+	// MessageGroup is the caller's HeaderMessageGroup, empty for an unordered
+	// invocation. The queue message carries it, scoped by GroupKey, as its
+	// statestore group; it is recorded here for inspection.
+	MessageGroup string `json:"messageGroup,omitempty"`
 	// Depth is the destination-chain depth (0 for a direct caller); phase 2's
 	// depth cap enforces against it. Carried now so phase 2 is additive.
 	Depth int `json:"depth"`
@@ -121,8 +143,18 @@ type Envelope struct {
 	OnFailure *Destination `json:"onFailure,omitempty"`
 }
 
+// ageOrigin is the instant MaxAge counts from: a scheduled invocation does not
+// start ageing while it waits for its InvokeAt.
+func (e Envelope) ageOrigin() time.Time {
+	if e.InvokeAt.After(e.EnqueueTime) {
+		return e.InvokeAt
+	}
+	return e.EnqueueTime
+}
+
 // Destination is a settled-invocation destination stamped into the envelope: a
-// same-namespace function (FunctionName set) or a message-queue topic (Topic set).
+// same-namespace function (FunctionName set), a message-queue topic (Topic set),
+// or an external webhook (Webhook set).
 // It is the envelope-side flat form of fv1.DestinationRef. FunctionNamespace is
 // the destination's namespace for BOTH kinds — topics are namespace-scoped too
 // (RFC-0027, mirroring the same-namespace rule R6), the field name predating the
@@ -155,6 +187,23 @@ type Destination struct {
 	// free -- see fireDestination and Envelope.FunctionVersion's doc.
 	Alias   string `json:"alias,omitempty"`
 	Version string `json:"version,omitempty"`
+	// CloudEvents wraps the result envelope as a CloudEvent: binary mode for
+	// a function or webhook destination, structured mode for a topic
+	// (resultEvent).
+	CloudEvents bool `json:"cloudEvents,omitempty"`
+	// Webhook is set for an external HTTPS destination.
+	Webhook *Webhook `json:"webhook,omitempty"`
+}
+
+// Webhook is the envelope-side form of fv1.WebhookDestination. The signing
+// key is named, not carried: it is read from the Secret at delivery, so it
+// never enters the queue and a rotation reaches queued deliveries.
+type Webhook struct {
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	SecretName string            `json:"secretName,omitempty"`
+	SecretKey  string            `json:"secretKey,omitempty"`
+	Policy     Policy            `json:"policy,omitzero"`
 }
 
 // IsFunction reports whether the destination targets a function.
@@ -178,6 +227,9 @@ func (d Destination) functionRouteName() string {
 // IsTopic reports whether the destination targets a topic.
 func (d Destination) IsTopic() bool { return d.Topic != "" }
 
+// IsWebhook reports whether the destination targets an external webhook.
+func (d Destination) IsWebhook() bool { return d.Webhook != nil }
+
 // Encode marshals the envelope for a statestore Queue message body.
 func (e Envelope) Encode() ([]byte, error) { return json.Marshal(e) }
 
@@ -242,10 +294,34 @@ type ResponseContext struct {
 // Encode marshals the result envelope for a destination invocation body.
 func (r ResultEnvelope) Encode() ([]byte, error) { return json.Marshal(r) }
 
+// CloudEvent types of a result envelope delivered to a destination with
+// CloudEvents set.
+const (
+	EventTypeSuccess = "io.fission.async.success"
+	EventTypeFailure = "io.fission.async.failure"
+)
+
+// resultEvent wraps an encoded result envelope as a CloudEvent. The id is
+// derived from the invocation and its condition, so a consumer can drop a
+// duplicate; the source names the function that was invoked.
+func (r ResultEnvelope) resultEvent(body []byte) cloudevents.Event {
+	eventType := EventTypeFailure
+	if r.RequestContext.Condition == ConditionSuccess {
+		eventType = EventTypeSuccess
+	}
+	ns, fn, _ := strings.Cut(r.RequestContext.FunctionRef, "/")
+	ev := cloudevents.New(
+		cloudevents.NewID(r.RequestContext.InvocationID, r.RequestContext.Condition),
+		"/fission/namespaces/"+ns+"/functions/"+fn, eventType, body, "application/json")
+	ev.Subject = r.RequestContext.InvocationID
+	return ev
+}
+
 // allowedHeaders returns the subset of request headers to replay on async
 // delivery. It is an allowlist, not a denylist: Content-Type and Accept (so the
-// function can parse the body), plus caller-set X-* headers EXCEPT internal
-// X-Fission-* control headers (which the dispatcher sets itself). Host,
+// function can parse the body), binary-mode CloudEvents attributes (Ce-*), plus
+// caller-set X-* headers EXCEPT internal X-Fission-* control headers (which the
+// dispatcher sets itself). Host,
 // Content-Length, hop-by-hop, Cookie, and Authorization are intentionally dropped
 // — an async invocation is decoupled from the caller's session. Multi-valued
 // headers are comma-joined (the HTTP-canonical single-line form).
@@ -275,5 +351,5 @@ func replayableHeader(canonicalName string) bool {
 	if strings.HasPrefix(canonicalName, "X-Fission-") {
 		return false
 	}
-	return strings.HasPrefix(canonicalName, "X-")
+	return strings.HasPrefix(canonicalName, "X-") || strings.HasPrefix(canonicalName, cloudevents.HeaderPrefix)
 }
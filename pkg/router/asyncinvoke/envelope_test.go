@@ -192,6 +192,7 @@ func TestAllowedHeaders(t *testing.T) {
 	h.Set("X-Request-Id", "abc")
 	h.Add("X-Multi", "a")
 	h.Add("X-Multi", "b")
+	h.Set("Ce-Id", "evt-1")                 // CloudEvents attribute: kept
 	h.Set("X-Fission-Invoke-Mode", "async") // internal control header: dropped
 	h.Set("Authorization", "Bearer secret") // caller session: dropped
 	h.Set("Host", "example.com")            // not X-*: dropped
@@ -202,6 +203,7 @@ func TestAllowedHeaders(t *testing.T) {
 	assert.Equal(t, "text/plain", got["Accept"])
 	assert.Equal(t, "abc", got["X-Request-Id"])
 	assert.Equal(t, "a,b", got["X-Multi"], "multi-valued headers comma-joined")
+	assert.Equal(t, "evt-1", got["Ce-Id"])
 	assert.NotContains(t, got, "X-Fission-Invoke-Mode")
 	assert.NotContains(t, got, "Authorization")
 	assert.NotContains(t, got, "Host")
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fnconfig maps a Function's InvocationConfig to the RFC-0024 async
+// config the asyncinvoke envelope carries. It is the fv1 side of that
+// translation, split out so asyncinvoke stays a pure library and every
+// enqueuer (the router, the timer) stamps envelopes the same way.
+package fnconfig
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+)
+
+// FromFunction is the single mapper from a Function to its resolved async
+// config (policy + destinations + timeout). The router's initial enqueue, each
+// destination-chain hop (NewResolver), and the timer's durable slot enqueue all
+// go through it, so the fv1↔asyncinvoke translation lives in one place and
+// cannot drift between them.
+func FromFunction(fn *fv1.Function) asyncinvoke.FunctionConfig {
+	onSuccess, onFailure := Destinations(fn.Spec.Invocation, fn.Namespace)
+	return asyncinvoke.FunctionConfig{
+		Policy:          Policy(fn.Spec.Invocation),
+		OnSuccess:       onSuccess,
+		OnFailure:       onFailure,
+		FunctionTimeout: fn.Spec.FunctionTimeout,
+		Throttle:        Throttle(fn.Spec.Invocation),
+	}
+}
+
+// NewResolver resolves a function's async config from the controller-runtime
+// Function cache, so each hop of a destination chain stamps its
+// own policy + onward destinations (and the depth cap is reachable), and each
+// delivery sees its function's current throttle. A genuinely
+// missing function → found=false → the destination is dropped rather than looping;
+// a transient lookup error is logged (not silently conflated with absence) so a
+// lost destination is diagnosable.
+func NewResolver(c client.Client, logger logr.Logger) asyncinvoke.FunctionConfigResolver {
+	return func(ctx context.Context, ns, name string) (asyncinvoke.FunctionConfig, bool) {
+		var fn fv1.Function
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, &fn); err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "resolving function async config",
+					"namespace", ns, "function", name)
+			}
+			return asyncinvoke.FunctionConfig{}, false
+		}
+		return FromFunction(&fn), true
+	}
+}
+
+// Policy maps a function's InvocationConfig to the durable async policy
+// stamped into the envelope. A nil config yields the zero policy (dispatcher
+// defaults). A false Jitter pointer disables jitter; nil leaves it enabled.
+func Policy(ic *fv1.InvocationConfig) asyncinvoke.Policy {
+	if ic == nil {
+		return asyncinvoke.Policy{}
+	}
+	p := retryPolicy(ic.Retry)
+	if ic.MaxAge != nil {
+		p.MaxAge = ic.MaxAge.Duration
+	}
+	return p
+}
+
+// retryPolicy maps a RetryPolicy's set fields; unset fields stay zero so the
+// dispatcher's defaults apply.
+func retryPolicy(r fv1.RetryPolicy) asyncinvoke.Policy {
+	p := asyncinvoke.Policy{}
+	if r.MaxAttempts != nil {
+		p.MaxAttempts = *r.MaxAttempts
+	}
+	if r.BackoffBase != nil {
+		p.BackoffBase = r.BackoffBase.Duration
+	}
+	if r.BackoffCap != nil {
+		p.BackoffCap = r.BackoffCap.Duration
+	}
+	if r.Jitter != nil && !*r.Jitter {
+		p.NoJitter = true
+	}
+	return p
+}
+
+// Throttle maps a function's InvocationConfig delivery caps. A nil config or
+// unset cap is uncapped.
+func Throttle(ic *fv1.InvocationConfig) asyncinvoke.Throttle {
+	var t asyncinvoke.Throttle
+	if ic == nil {
+		return t
+	}
+	if ic.MaxInFlight != nil {
+		t.MaxInFlight = int(*ic.MaxInFlight)
+	}
+	if ic.MaxDeliveriesPerSecond != nil {
+		t.PerSecond = int(*ic.MaxDeliveriesPerSecond)
+	}
+	return t
+}
+
+// Destinations maps a function's InvocationConfig destinations to the
+// flat envelope form. Function destinations are same-namespace (FunctionReference
+// has no namespace), so they inherit the source function's namespace.
+func Destinations(ic *fv1.InvocationConfig, fnNamespace string) (onSuccess, onFailure *asyncinvoke.Destination) {
+	if ic == nil {
+		return nil, nil
+	}
+	return destFromRef(ic.OnSuccess, fnNamespace), destFromRef(ic.OnFailure, fnNamespace)
+}
+
+func destFromRef(ref *fv1.DestinationRef, fnNamespace string) *asyncinvoke.Destination {
+	switch {
+	case ref == nil:
+		return nil
+	case ref.Function != nil:
+		// Alias/Version (RFC-0025) mirror FunctionReference's own mutually
+		// exclusive pair; carried through so fireDestination can suffix the
+		// delivery URL with `:<alias>`/`:<version>` when the destination
+		// itself is pinned, exactly like a trigger referencing an alias or
+		// version would resolve.
+		return &asyncinvoke.Destination{
+			FunctionNamespace: fnNamespace,
+			FunctionName:      ref.Function.Name,
+			Alias:             ref.Function.Alias,
+			Version:           ref.Function.Version,
+			CloudEvents:       ref.CloudEvents,
+		}
+	case ref.Topic != nil:
+		// Topics are namespace-scoped (RFC-0027): the destination inherits the
+		// source function's namespace, exactly like function destinations (R6).
+		return &asyncinvoke.Destination{FunctionNamespace: fnNamespace, Topic: ref.Topic.Topic, MQType: string(ref.Topic.MessageQueueType), CloudEvents: ref.CloudEvents}
+	case ref.Webhook != nil:
+		// The signing Secret, like a topic, lives in the source function's
+		// namespace.
+		wh := &asyncinvoke.Webhook{
+			URL:     ref.Webhook.URL,
+			Headers: ref.Webhook.Headers,
+			Policy:  retryPolicy(ref.Webhook.Retry),
+		}
+		if s := ref.Webhook.SigningSecret; s != nil {
+			wh.SecretName, wh.SecretKey = s.Name, s.Key
+		}
+		return &asyncinvoke.Destination{FunctionNamespace: fnNamespace, Webhook: wh, CloudEvents: ref.CloudEvents}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package fnconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+)
+
+func TestDestinations(t *testing.T) {
+	t.Parallel()
+	onS, onF := Destinations(nil, "ns")
+	assert.Nil(t, onS)
+	assert.Nil(t, onF)
+
+	ic := &fv1.InvocationConfig{
+		OnSuccess: &fv1.DestinationRef{Function: &fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "next"}},
+		OnFailure: &fv1.DestinationRef{Topic: &fv1.TopicRef{MessageQueueType: fv1.MessageQueueTypeStatestore, Topic: "errs"}},
+	}
+	onS, onF = Destinations(ic, "ns")
+	require.NotNil(t, onS)
+	assert.Equal(t, "ns", onS.FunctionNamespace, "function destination inherits the source namespace")
+	assert.Equal(t, "next", onS.FunctionName)
+	assert.True(t, onS.IsFunction())
+	require.NotNil(t, onF)
+	assert.Equal(t, "ns", onF.FunctionNamespace, "topic destination inherits the source namespace too (RFC-0027)")
+	assert.Equal(t, "errs", onF.Topic)
+	assert.Equal(t, fv1.MessageQueueTypeStatestore, onF.MQType)
+	assert.True(t, onF.IsTopic())
+}
+
+// TestDestinations_AliasVersion pins the RFC-0025 Task 5 mapping: a
+// function destination's Alias/Version pin (fv1.FunctionReference) carries
+// through to the envelope-side Destination's own Alias/Version fields.
+func TestDestinations_AliasVersion(t *testing.T) {
+	t.Parallel()
+	ic := &fv1.InvocationConfig{
+		OnSuccess: &fv1.DestinationRef{Function: &fv1.FunctionReference{
+			Type: fv1.FunctionReferenceTypeFunctionName, Name: "next", Version: "next-v3",
+		}},
+		OnFailure: &fv1.DestinationRef{Function: &fv1.FunctionReference{
+			Type: fv1.FunctionReferenceTypeFunctionName, Name: "handler", Alias: "prod",
+		}},
+	}
+	onS, onF := Destinations(ic, "ns")
+	require.NotNil(t, onS)
+	assert.Equal(t, "next-v3", onS.Version)
+	assert.Empty(t, onS.Alias)
+	require.NotNil(t, onF)
+	assert.Equal(t, "prod", onF.Alias)
+	assert.Empty(t, onF.Version)
+}
+
+// TestDestinations_Webhook maps a webhook destination: its own retry policy,
+// headers, and signing Secret reference carry through.
+func TestDestinations_Webhook(t *testing.T) {
+	t.Parallel()
+	ic := &fv1.InvocationConfig{
+		OnFailure: &fv1.DestinationRef{
+			Webhook: &fv1.WebhookDestination{
+				URL:           "https://hooks.example.com/fission",
+				Headers:       map[string]string{"X-Team": "payments"},
+				SigningSecret: &fv1.WebhookSigningSecret{Name: "hook", Key: "hmac"},
+				Retry:         fv1.RetryPolicy{MaxAttempts: new(3)},
+			},
+			CloudEvents: true,
+		},
+	}
+	onS, onF := Destinations(ic, "ns")
+	assert.Nil(t, onS)
+	require.NotNil(t, onF)
+	assert.True(t, onF.IsWebhook())
+	assert.False(t, onF.IsFunction())
+	assert.False(t, onF.IsTopic())
+	assert.Equal(t, "ns", onF.FunctionNamespace, "the signing Secret is read from the source namespace")
+	assert.True(t, onF.CloudEvents)
+	assert.Equal(t, asyncinvoke.Webhook{
+		URL:        "https://hooks.example.com/fission",
+		Headers:    map[string]string{"X-Team": "payments"},
+		SecretName: "hook",
+		SecretKey:  "hmac",
+		Policy:     asyncinvoke.Policy{MaxAttempts: 3},
+	}, *onF.Webhook)
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, asyncinvoke.Policy{}, Policy(nil), "nil config → zero policy")
+
+	jitterOff := false
+	ic := &fv1.InvocationConfig{
+		Retry: fv1.RetryPolicy{
+			MaxAttempts: new(7),
+			BackoffBase: &metav1.Duration{Duration: 2 * time.Second},
+			BackoffCap:  &metav1.Duration{Duration: time.Minute},
+			Jitter:      &jitterOff,
+		},
+		MaxAge: &metav1.Duration{Duration: 3 * time.Hour},
+	}
+	got := Policy(ic)
+	assert.Equal(t, 7, got.MaxAttempts)
+	assert.Equal(t, 2*time.Second, got.BackoffBase)
+	assert.Equal(t, time.Minute, got.BackoffCap)
+	assert.Equal(t, 3*time.Hour, got.MaxAge)
+	assert.True(t, got.NoJitter, "Jitter:false → NoJitter:true")
+}
+
+func TestThrottle(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, asyncinvoke.Throttle{}, Throttle(nil), "nil config → uncapped")
+	assert.Equal(t, asyncinvoke.Throttle{}, Throttle(&fv1.InvocationConfig{}))
+	got := Throttle(&fv1.InvocationConfig{MaxInFlight: new(int32(4)), MaxDeliveriesPerSecond: new(int32(25))})
+	assert.Equal(t, asyncinvoke.Throttle{MaxInFlight: 4, PerSecond: 25}, got)
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode"
+)
+
+// MaxMessageGroupLength caps HeaderMessageGroup, as SQS caps a FIFO message
+// group id.
+const MaxMessageGroupLength = 128
+
+// ErrInvalidMessageGroup is returned by ParseMessageGroup for an overlong or
+// non-printable group; the router maps it to 400.
+var ErrInvalidMessageGroup = errors.New("asyncinvoke: invalid message group")
+
+// ParseMessageGroup reads HeaderMessageGroup off h, returning "" when it is
+// unset. Invocations of one function in the same group are delivered one at a
+// time, in enqueue order (see statestore.GroupedQueue).
+func ParseMessageGroup(h http.Header) (string, error) {
+	group := h.Get(HeaderMessageGroup)
+	if len(group) > MaxMessageGroupLength {
+		return "", fmt.Errorf("%w: %s is longer than %d bytes", ErrInvalidMessageGroup, HeaderMessageGroup, MaxMessageGroupLength)
+	}
+	for _, r := range group {
+		if !unicode.IsPrint(r) {
+			return "", fmt.Errorf("%w: %s must be printable", ErrInvalidMessageGroup, HeaderMessageGroup)
+		}
+	}
+	return group, nil
+}
+
+// GroupKey is the statestore message group of a function's invocations in
+// group: scoped to the function, so the same group name used by two
+// functions orders each function's invocations independently.
+func GroupKey(namespace, function, group string) string {
+	return namespace + "/" + function + "/" + group
+}
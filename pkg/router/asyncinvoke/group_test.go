@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func TestParseMessageGroup(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name, group string
+		wantErr     bool
+	}{
+		{name: "unset"},
+		{name: "group", group: "customer-42"},
+		{name: "longest", group: strings.Repeat("g", MaxMessageGroupLength)},
+		{name: "too long", group: strings.Repeat("g", MaxMessageGroupLength+1), wantErr: true},
+		{name: "control character", group: "a\tb", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := http.Header{}
+			if tc.group != "" {
+				h.Set(HeaderMessageGroup, tc.group)
+			}
+			got, err := ParseMessageGroup(h)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidMessageGroup)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.group, got)
+		})
+	}
+}
+
+// TestDispatcherLeasesByGroup: one invocation per function and group is
+// leased at a time, in enqueue order; the same group name on another function
+// and ungrouped invocations are not held back.
+func TestDispatcherLeasesByGroup(t *testing.T) {
+	t.Parallel()
+	q := memQueue(t)
+	enqueue := func(fn, group, body string) {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		_, err := Enqueue(t.Context(), q, httptest.NewRecorder(), r, Params{Namespace: "ns", Function: fn, MessageGroup: group})
+		require.NoError(t, err)
+	}
+	enqueue("fn", "c1", "first")
+	enqueue("fn", "c1", "second")
+	enqueue("other", "c1", "other")
+	enqueue("fn", "", "unordered")
+
+	d := newTestDispatcher(q, scriptedDeliverer{}, time.Now())
+	bodies := func(msgs []statestore.LeasedMessage) []string {
+		var out []string
+		for _, m := range msgs {
+			env, err := Decode(m.Body)
+			require.NoError(t, err)
+			out = append(out, string(env.Body))
+		}
+		return out
+	}
+	msgs, err := d.lease(t.Context())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"first", "other", "unordered"}, bodies(msgs))
+	env, err := Decode(msgs[0].Body)
+	require.NoError(t, err)
+	assert.Equal(t, "c1", env.MessageGroup)
+
+	for _, m := range msgs {
+		require.NoError(t, q.Ack(t.Context(), m.Receipt))
+	}
+	msgs, err = d.lease(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second"}, bodies(msgs))
+}
+
+// ungroupedQueue is a Queue whose grouped lease is unavailable, as the
+// statestore's metered wrapper reports over a driver without one.
+type ungroupedQueue struct {
+	statestore.Queue
+	calls int
+}
+
+func (u *ungroupedQueue) LeaseGrouped(context.Context, string, int, time.Duration) ([]statestore.LeasedMessage, error) {
+	u.calls++
+	return nil, statestore.ErrCapabilityUnavailable
+}
+
+func TestDispatcherLeaseFallsBackUngrouped(t *testing.T) {
+	t.Parallel()
+	q := &ungroupedQueue{Queue: memQueue(t)}
+	r := httptest.NewRequest("POST", "/", strings.NewReader("x"))
+	_, err := Enqueue(t.Context(), q, httptest.NewRecorder(), r, Params{Namespace: "ns", Function: "fn", MessageGroup: "c1"})
+	require.NoError(t, err)
+
+	d := newTestDispatcher(q, scriptedDeliverer{}, time.Now())
+	msgs, err := d.lease(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, msgs, 1, "the batch is leased ungrouped instead")
+	_, err = d.lease(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, q.calls, "the grouped lease is not retried")
+}
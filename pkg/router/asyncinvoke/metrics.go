@@ -25,11 +25,21 @@ var (
 	asyncDLQ = metrics.Int64Counter("fission_async_dlq_total",
 		"Count of async invocations dead-lettered, labeled by reason")
 	asyncDestinations = metrics.Int64Counter("fission_async_destinations_total",
-		"Count of async destination fires, labeled by outcome (enqueued/dropped/depth_capped/published/publish_error/publisher_unconfigured/topic_unsupported/encode_error/enqueue_error)")
+		"Count of async destination fires, labeled by outcome (enqueued/dropped/depth_capped/published/publish_error/publisher_unconfigured/topic_unsupported/webhook_enqueued/encode_error/enqueue_error)")
 	asyncDepthCap = metrics.Int64Counter("fission_async_depth_cap_total",
 		"Count of async destination invocations dropped for exceeding the chain depth cap (A6)")
 	asyncVersionFallback = metrics.Int64Counter("fission_async_version_fallback_total",
 		"Count of async deliveries that fell back to the bare function route after a route-miss-marked 404 on a version-pinned route (RFC-0025)")
+	asyncStatusWriteErrors = metrics.Int64Counter("fission_async_status_write_errors_total",
+		"Count of async invocation status records that failed to write")
+	asyncCancelled = metrics.Int64Counter("fission_async_cancelled_total",
+		"Count of async invocations acked undelivered because they were cancelled")
+	asyncThrottled = metrics.Int64Counter("fission_async_throttled_total",
+		"Count of async deliveries deferred by a function's delivery caps, labeled by reason (in_flight/rate)")
+	asyncWebhookDeliveries = metrics.Int64Counter("fission_async_webhook_deliveries_total",
+		"Count of async webhook destination delivery attempts, labeled by response condition")
+	asyncWebhookDLQ = metrics.Int64Counter("fission_async_webhook_dlq_total",
+		"Count of async webhook destination deliveries dead-lettered, labeled by reason")
 )
 
 func recordDelivery(ctx context.Context, condition string) {
@@ -56,6 +66,26 @@ func recordVersionFallback(ctx context.Context) {
 	asyncVersionFallback.Add(ctx, 1)
 }
 
+func recordStatusWriteError(ctx context.Context) {
+	asyncStatusWriteErrors.Add(ctx, 1)
+}
+
+func recordCancelled(ctx context.Context) {
+	asyncCancelled.Add(ctx, 1)
+}
+
+func recordThrottled(ctx context.Context, reason string) {
+	asyncThrottled.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+func recordWebhookDelivery(ctx context.Context, condition string) {
+	asyncWebhookDeliveries.Add(ctx, 1, metric.WithAttributes(attribute.String("condition", condition)))
+}
+
+func recordWebhookDLQ(ctx context.Context, reason string) {
+	asyncWebhookDLQ.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
 // deliveryCondition classifies a DeliveryResult for the deliveries_total label:
 // the raw response class of one delivery attempt (distinct from the settle
 // action, which classify() decides).
@@ -74,13 +104,19 @@ func deliveryCondition(res DeliveryResult) string {
 	}
 }
 
-// RegisterQueueGauges registers the async depth and oldest-age observable gauges,
-// read from q.Stats(queueName) on each metrics collection. Call it once at router
-// start (only when async invocation is enabled).
+// RegisterQueueGauges registers the async depth, throttled, and oldest-age
+// observable gauges, read from q.Stats(queueName) on each metrics collection.
+// Depth counts messages ready for delivery; throttled counts those a function's
+// delivery caps deferred, which are waiting on the function rather than on the
+// dispatcher. Call it once at router start (only when async invocation is
+// enabled).
 func RegisterQueueGauges(q statestore.Queue, queueName string) {
 	metrics.Int64ObservableGauge("fission_async_queue_depth",
-		"Async invocation queue depth: visible messages awaiting delivery",
+		"Async invocation queue depth: visible messages ready for delivery",
 		observeStat(q, queueName, func(st statestore.QueueStats) int64 { return st.Visible }))
+	metrics.Int64ObservableGauge("fission_async_queue_throttled",
+		"Async invocations deferred by a function's delivery caps (maxInFlight/maxDeliveriesPerSecond), not yet visible again",
+		observeStat(q, queueName, func(st statestore.QueueStats) int64 { return st.Deferred }))
 	metrics.Int64ObservableGauge("fission_async_oldest_age_seconds",
 		"Age in seconds of the oldest visible async invocation (0 when none)",
 		observeStat(q, queueName, func(st statestore.QueueStats) int64 {
@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// DefaultSpillThreshold is the body size above which Payloads moves a
+	// body out of the envelope, leaving the queue message well below the
+	// statestore's message size.
+	DefaultSpillThreshold = 128 << 10
+	// DefaultMaxSpillBytes is the async request-body cap when large payloads
+	// are spilled (Params.MaxBodyBytes <= 0).
+	DefaultMaxSpillBytes = 32 << 20
+
+	payloadKeyspace = "payload"
+)
+
+// ErrPayloadCorrupt is returned while streaming a spilled body that does not
+// hash to its PayloadRef digest.
+var ErrPayloadCorrupt = errors.New("asyncinvoke: spilled payload does not match its digest")
+
+// BlobStore keeps spilled bodies; the storagesvc client implements it, on
+// whichever backend (local volume or S3-compatible object store) storagesvc
+// runs. Names are "<sha256 hex>.<generation>", unique within a namespace.
+type BlobStore interface {
+	PutPayload(ctx context.Context, namespace, name string, r io.Reader, size int64) error
+	GetPayload(ctx context.Context, namespace, name string) (io.ReadCloser, error)
+	DeletePayload(ctx context.Context, namespace, name string) error
+}
+
+// PayloadRef is the envelope's reference to a spilled body.
+type PayloadRef struct {
+	// Digest is the hex SHA-256 of the body; Generation tells apart successive
+	// blobs of the same content (see Payloads).
+	Digest     string `json:"digest"`
+	Generation string `json:"generation"`
+	Size       int64  `json:"size"`
+	// Holder is this invocation's entry in the blob's reference record.
+	Holder string `json:"holder"`
+}
+
+func (r PayloadRef) name() string { return r.Digest + "." + r.Generation }
+
+// payloadRecord is the statestore KV reference record of one spilled blob:
+// the invocations holding it. Identical bodies share a blob while it is held.
+type payloadRecord struct {
+	Generation string   `json:"generation"`
+	Holders    []string `json:"holders"`
+}
+
+// Payloads spills async request bodies above a threshold to a BlobStore,
+// content-addressed by SHA-256, and garbage-collects each blob when the last
+// invocation holding it is acked or purged from the DLQ (a dead-lettered
+// invocation keeps its blob, so a redrive still has its body).
+//
+// Holders are tracked in a KV record per (namespace, digest), updated by CAS.
+// The record also fixes the blob's generation: the release that empties it
+// CAS-deletes the record before deleting the blob, so a concurrent spill of
+// the same content either lands in the record first (and the release keeps
+// the blob) or creates a new record with a new generation — a different blob
+// the delete cannot touch.
+type Payloads struct {
+	kv        statestore.KVStore
+	blobs     BlobStore
+	threshold int
+	logger    logr.Logger
+}
+
+// NewPayloads returns a Payloads that spills bodies larger than threshold
+// (<= 0 → DefaultSpillThreshold) to blobs, tracking them in kv.
+func NewPayloads(kv statestore.KVStore, blobs BlobStore, threshold int, logger logr.Logger) *Payloads {
+	if threshold <= 0 {
+		threshold = DefaultSpillThreshold
+	}
+	return &Payloads{kv: kv, blobs: blobs, threshold: threshold, logger: logger}
+}
+
+func payloadScope(namespace string) statestore.Scope {
+	return statestore.Scope{Namespace: namespace, Owner: statusOwner, Keyspace: payloadKeyspace}
+}
+
+// spill stores body as a blob held by a new holder and returns its reference.
+func (p *Payloads) spill(ctx context.Context, namespace string, body []byte) (*PayloadRef, error) {
+	sum := sha256.Sum256(body)
+	ref := &PayloadRef{
+		Digest: hex.EncodeToString(sum[:]),
+		Size:   int64(len(body)),
+		Holder: strconv.FormatUint(rand.Uint64(), 36),
+	}
+	err := p.update(ctx, namespace, ref.Digest, func(rec *payloadRecord) bool {
+		if rec.Generation == "" {
+			rec.Generation = strconv.FormatUint(rand.Uint64(), 36)
+		}
+		rec.Holders = append(rec.Holders, ref.Holder)
+		ref.Generation = rec.Generation
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("asyncinvoke: recording spilled payload: %w", err)
+	}
+	// Upload even when the record already existed: its first holder may still
+	// be uploading, or may have failed to. The blob is content-addressed, so
+	// storing it twice is harmless.
+	if err := p.blobs.PutPayload(ctx, namespace, ref.name(), bytes.NewReader(body), ref.Size); err != nil {
+		p.release(ctx, namespace, *ref)
+		return nil, fmt.Errorf("asyncinvoke: spilling payload: %w", err)
+	}
+	return ref, nil
+}
+
+// Release drops env's hold on its spilled body, deleting the blob when no
+// other invocation holds it. It is a no-op for an envelope without one. A
+// failure is logged: the blob leaks rather than being deleted early.
+func (p *Payloads) Release(ctx context.Context, env Envelope) {
+	if p == nil || env.BodyRef == nil {
+		return
+	}
+	p.release(ctx, env.Namespace, *env.BodyRef)
+}
+
+func (p *Payloads) release(ctx context.Context, namespace string, ref PayloadRef) {
+	drained := false
+	err := p.update(ctx, namespace, ref.Digest, func(rec *payloadRecord) bool {
+		if rec.Generation != ref.Generation {
+			return false // already collected, and perhaps re-spilled since
+		}
+		rec.Holders = slices.DeleteFunc(rec.Holders, func(h string) bool { return h == ref.Holder })
+		drained = len(rec.Holders) == 0
+		return true
+	})
+	if err != nil {
+		p.logger.Error(err, "releasing spilled async payload", "namespace", namespace, "digest", ref.Digest)
+		return
+	}
+	if drained {
+		if err := p.blobs.DeletePayload(ctx, namespace, ref.name()); err != nil {
+			p.logger.Error(err, "deleting spilled async payload", "namespace", namespace, "digest", ref.Digest)
+		}
+	}
+}
+
+// update applies fn to the reference record of digest under CAS, retrying a
+// conflict up to casAttempts times. fn returns false to leave the record as
+// it is; a record left with no holders is deleted. A missing record reaches
+// fn empty.
+func (p *Payloads) update(ctx context.Context, namespace, digest string, fn func(*payloadRecord) bool) error {
+	scope := payloadScope(namespace)
+	for range casAttempts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var rec payloadRecord
+		var version int64
+		v, err := p.kv.Get(ctx, scope, digest)
+		switch {
+		case errors.Is(err, statestore.ErrNotFound):
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(v.Data, &rec); err != nil {
+				return err
+			}
+			version = v.Version
+		}
+		if !fn(&rec) {
+			return nil
+		}
+		if len(rec.Holders) == 0 {
+			if version == 0 {
+				return nil
+			}
+			err = p.kv.Delete(ctx, scope, digest, version)
+		} else {
+			var data []byte
+			if data, err = json.Marshal(rec); err != nil {
+				return err
+			}
+			err = p.kv.Set(ctx, scope, digest, data, statestore.SetOptions{IfVersion: &version})
+		}
+		if !errors.Is(err, statestore.ErrVersionConflict) {
+			return err
+		}
+	}
+	return casExhausted()
+}
+
+// open streams the spilled body of ref, failing the read with
+// ErrPayloadCorrupt at EOF if it does not hash to ref.Digest.
+func (p *Payloads) open(ctx context.Context, namespace string, ref PayloadRef) (io.ReadCloser, error) {
+	rc, err := p.blobs.GetPayload(ctx, namespace, ref.name())
+	if err != nil {
+		return nil, err
+	}
+	return &verifyingReader{rc: rc, h: sha256.New(), digest: ref.Digest}, nil
+}
+
+type verifyingReader struct {
+	rc     io.ReadCloser
+	h      hash.Hash
+	digest string
+}
+
+func (v *verifyingReader) Read(b []byte) (int, error) {
+	n, err := v.rc.Read(b)
+	v.h.Write(b[:n])
+	if errors.Is(err, io.EOF) && hex.EncodeToString(v.h.Sum(nil)) != v.digest {
+		return n, ErrPayloadCorrupt
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error { return v.rc.Close() }
@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+// memBlobs is an in-memory BlobStore.
+type memBlobs struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (m *memBlobs) PutPayload(_ context.Context, ns, name string, r io.Reader, _ int64) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[ns+"/"+name] = b
+	return nil
+}
+
+func (m *memBlobs) GetPayload(_ context.Context, ns, name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.blobs[ns+"/"+name]
+	if !ok {
+		return nil, statestore.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (m *memBlobs) DeletePayload(_ context.Context, ns, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blobs, ns+"/"+name)
+	return nil
+}
+
+func (m *memBlobs) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.blobs)
+}
+
+func memPayloads(t *testing.T, threshold int) (*Payloads, *memBlobs) {
+	t.Helper()
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	blobs := &memBlobs{blobs: map[string][]byte{}}
+	return NewPayloads(kv, blobs, threshold, logr.Discard()), blobs
+}
+
+// TestPayloadSpillDeliverAndCollect enqueues a body above the threshold: the
+// envelope carries only a reference, delivery streams the body back, and the
+// ack collects the blob.
+func TestPayloadSpillDeliverAndCollect(t *testing.T) {
+	t.Parallel()
+	payloads, blobs := memPayloads(t, 64)
+	body := strings.Repeat("large body ", 100)
+
+	var gotBody string
+	var gotLength int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotLength = string(b), r.ContentLength
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	q, status := memStore(t)
+	r := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader(body))
+	_, err := Enqueue(t.Context(), q, httptest.NewRecorder(), r, Params{Namespace: "ns", Function: "fn", Status: status, Payloads: payloads})
+	require.NoError(t, err)
+	msgs, err := q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	env, err := Decode(msgs[0].Body)
+	require.NoError(t, err)
+	assert.Empty(t, env.Body, "the body is not in the message")
+	require.NotNil(t, env.BodyRef)
+	assert.Equal(t, int64(len(body)), env.BodyRef.Size)
+	assert.Equal(t, 1, blobs.len())
+
+	d := New(Options{
+		Queue: q, Logger: logr.Discard(), Status: status, Payloads: payloads,
+		Deliverer: NewHTTPDeliverer(srv.URL, []byte("master"), nil, logr.Discard(), WithPayloads(payloads)),
+	})
+	d.process(t.Context(), msgs[0])
+	assert.Equal(t, body, gotBody)
+	assert.Equal(t, int64(len(body)), gotLength)
+	assert.Equal(t, StateSucceeded, statusOf(t, status, msgs[0].ID).State)
+	assert.Zero(t, blobs.len(), "the ack collects the blob")
+
+	small := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader("small"))
+	_, err = Enqueue(t.Context(), q, httptest.NewRecorder(), small, Params{Namespace: "ns", Function: "fn", Payloads: payloads})
+	require.NoError(t, err)
+	assert.Zero(t, blobs.len(), "a body under the threshold stays in the envelope")
+}
+
+// TestPayloadSharedDigest spills the same content twice: the invocations
+// share one blob, which goes only with the last holder, and a re-spill after
+// that gets a new generation.
+func TestPayloadSharedDigest(t *testing.T) {
+	t.Parallel()
+	payloads, blobs := memPayloads(t, 1)
+	body := []byte("same content")
+
+	first, err := payloads.spill(t.Context(), "ns", body)
+	require.NoError(t, err)
+	second, err := payloads.spill(t.Context(), "ns", body)
+	require.NoError(t, err)
+	assert.Equal(t, first.name(), second.name())
+	assert.NotEqual(t, first.Holder, second.Holder)
+	assert.Equal(t, 1, blobs.len())
+
+	payloads.Release(t.Context(), Envelope{Namespace: "ns", BodyRef: first})
+	assert.Equal(t, 1, blobs.len(), "still held by the second invocation")
+	payloads.Release(t.Context(), Envelope{Namespace: "ns", BodyRef: first})
+	assert.Equal(t, 1, blobs.len(), "releasing twice is a no-op")
+	payloads.Release(t.Context(), Envelope{Namespace: "ns", BodyRef: second})
+	assert.Zero(t, blobs.len())
+
+	third, err := payloads.spill(t.Context(), "ns", body)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Generation, third.Generation)
+	payloads.Release(t.Context(), Envelope{Namespace: "ns", BodyRef: second})
+	assert.Equal(t, 1, blobs.len(), "a stale release cannot collect a newer generation")
+}
+
+// TestPayloadUpdateBounded pins that a reference record that keeps
+// conflicting fails the spill instead of spinning, and that a spill stops at
+// once when ctx ends.
+func TestPayloadUpdateBounded(t *testing.T) {
+	t.Parallel()
+	payloads, blobs := memPayloads(t, 1)
+	kv := &conflictingKV{KVStore: payloads.kv}
+	payloads.kv = kv
+
+	_, err := payloads.spill(t.Context(), "ns", []byte("body"))
+	require.ErrorIs(t, err, statestore.ErrVersionConflict)
+	assert.Equal(t, casAttempts, kv.writes)
+	assert.Zero(t, blobs.len())
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	kv.writes = 0
+	_, err = payloads.spill(ctx, "ns", []byte("body"))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, kv.writes)
+}
+
+// TestPayloadDeliveryFailures fails an attempt, for a retry, when the spilled
+// body is gone or does not match its digest.
+func TestPayloadDeliveryFailures(t *testing.T) {
+	t.Parallel()
+	payloads, blobs := memPayloads(t, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+	defer srv.Close()
+
+	ref, err := payloads.spill(t.Context(), "ns", []byte("original"))
+	require.NoError(t, err)
+	env := Envelope{Namespace: "ns", Function: "fn", BodyRef: ref}
+
+	blobs.blobs["ns/"+ref.name()] = []byte("tampered")
+	res := NewHTTPDeliverer(srv.URL, nil, nil, logr.Discard(), WithPayloads(payloads)).Deliver(t.Context(), env, "id", 1)
+	require.ErrorIs(t, res.Err, ErrPayloadCorrupt)
+	assert.Equal(t, actionRetry, classify(res))
+
+	require.NoError(t, blobs.DeletePayload(t.Context(), "ns", ref.name()))
+	res = NewHTTPDeliverer(srv.URL, nil, nil, logr.Discard(), WithPayloads(payloads)).Deliver(t.Context(), env, "id", 1)
+	require.ErrorIs(t, res.Err, statestore.ErrNotFound)
+
+	res = NewHTTPDeliverer(srv.URL, nil, nil, logr.Discard()).Deliver(t.Context(), env, "id", 1)
+	assert.Error(t, res.Err, "no payload store configured")
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultMaxInvokeDelay is how far ahead a scheduled invocation may be placed
+// when the router config sets no limit.
+const DefaultMaxInvokeDelay = 7 * 24 * time.Hour
+
+// ErrInvalidSchedule is returned by ParseSchedule for a malformed, conflicting
+// or out-of-range schedule; the router maps it to 400.
+var ErrInvalidSchedule = errors.New("asyncinvoke: invalid invocation schedule")
+
+// ParseSchedule reads HeaderInvokeAt (an RFC3339 time) or HeaderInvokeDelay (a
+// Go duration such as "90s" or "2h") off h and returns when the invocation
+// becomes deliverable. It returns the zero time for an immediate invocation:
+// neither header set, a zero delay, or an InvokeAt already in the past. A
+// schedule further than maxDelay from now is rejected (maxDelay <= 0 →
+// DefaultMaxInvokeDelay).
+func ParseSchedule(h http.Header, now time.Time, maxDelay time.Duration) (time.Time, error) {
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxInvokeDelay
+	}
+	at, delay := h.Get(HeaderInvokeAt), h.Get(HeaderInvokeDelay)
+	var invokeAt time.Time
+	switch {
+	case at == "" && delay == "":
+		return time.Time{}, nil
+	case at != "" && delay != "":
+		return time.Time{}, fmt.Errorf("%w: set only one of %s and %s", ErrInvalidSchedule, HeaderInvokeAt, HeaderInvokeDelay)
+	case at != "":
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s must be an RFC3339 time: %w", ErrInvalidSchedule, HeaderInvokeAt, err)
+		}
+		invokeAt = t
+	default:
+		d, err := time.ParseDuration(delay)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("%w: %s must be a non-negative duration such as \"90s\" or \"2h\"", ErrInvalidSchedule, HeaderInvokeDelay)
+		}
+		invokeAt = now.Add(d)
+	}
+	if !invokeAt.After(now) {
+		return time.Time{}, nil
+	}
+	if invokeAt.Sub(now) > maxDelay {
+		return time.Time{}, fmt.Errorf("%w: the invocation is scheduled more than %s ahead", ErrInvalidSchedule, maxDelay)
+	}
+	return invokeAt, nil
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name, at, delay string
+		want            time.Time
+		wantErr         bool
+	}{
+		{name: "immediate"},
+		{name: "delay", delay: "2h", want: now.Add(2 * time.Hour)},
+		{name: "zero delay", delay: "0s"},
+		{name: "invoke at", at: "2026-01-01T13:30:00Z", want: now.Add(90 * time.Minute)},
+		{name: "invoke at with offset", at: "2026-01-01T14:00:00+01:00", want: now.Add(time.Hour)},
+		{name: "past invoke at runs now", at: "2025-12-31T00:00:00Z"},
+		{name: "both", at: "2026-01-01T13:00:00Z", delay: "1h", wantErr: true},
+		{name: "bad time", at: "tomorrow", wantErr: true},
+		{name: "bad delay", delay: "soon", wantErr: true},
+		{name: "negative delay", delay: "-1m", wantErr: true},
+		{name: "beyond max", delay: "25h", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			h := http.Header{}
+			if tc.at != "" {
+				h.Set(HeaderInvokeAt, tc.at)
+			}
+			if tc.delay != "" {
+				h.Set(HeaderInvokeDelay, tc.delay)
+			}
+			got, err := ParseSchedule(h, now, 24*time.Hour)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSchedule)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(got), "got %v, want %v", got, tc.want)
+		})
+	}
+
+	_, err := ParseSchedule(http.Header{HeaderInvokeDelay: {"144h"}}, now, 0)
+	assert.NoError(t, err, "maxDelay <= 0 takes DefaultMaxInvokeDelay")
+}
+
+func TestProcessScheduledAgesFromInvokeAt(t *testing.T) {
+	t.Parallel()
+	rq := &recordingQueue{}
+	now := time.Unix(1_000_000, 0)
+	// Enqueued 7h ago for delivery 1h ago: past the 6h MaxAge from enqueue,
+	// but only 1h old from its schedule.
+	d := newTestDispatcher(rq, scriptedDeliverer{DeliveryResult{StatusCode: 200}}, now)
+	d.process(t.Context(), leasedMsg(t, Envelope{EnqueueTime: now.Add(-7 * time.Hour), InvokeAt: now.Add(-time.Hour)}, 1))
+	assert.Empty(t, rq.kills)
+	assert.Len(t, rq.acks, 1)
+}
@@ -0,0 +1,281 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+// Invocation states recorded in an InvocationStatus.
+const (
+	StateQueued    = "queued"    // accepted, not yet leased
+	StateRunning   = "running"   // an attempt is being delivered
+	StateRetrying  = "retrying"  // an attempt failed; the next is scheduled
+	StateSucceeded = "succeeded" // delivered with a 2xx
+	StateFailed    = "failed"    // dead-lettered (Reason says why)
+	StateCancelled = "cancelled" // cancelled before delivery; never delivered
+)
+
+// ErrNotCancellable is returned by StatusStore.Cancel for an invocation that
+// is already being delivered or has settled; the router maps it to 409.
+var ErrNotCancellable = errors.New("asyncinvoke: invocation is no longer cancellable")
+
+const (
+	// DefaultStatusTTL is how long an invocation's status record outlives its
+	// last update.
+	DefaultStatusTTL = 24 * time.Hour
+	// MaxStatusBodyBytes caps the response body kept in a status record, so a
+	// record stays small next to the 256KiB request cap.
+	MaxStatusBodyBytes = 4 << 10
+
+	statusOwner    = "router/asyncinvoke"
+	statusKeyspace = "status"
+
+	// casAttempts bounds the read-modify-write retries of one record, so a
+	// record that keeps changing fails the write instead of spinning.
+	casAttempts = 16
+)
+
+// InvocationStatus is the lifecycle record of one async invocation, keyed by
+// its invocation id. Each transition overwrites the whole record, so it always
+// describes the latest attempt.
+type InvocationStatus struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Function  string `json:"function"`
+	State     string `json:"state"`
+	// Attempts is the number of the attempt in flight or last settled (0
+	// while queued).
+	Attempts int `json:"attempts"`
+	// StatusCode is the last attempt's HTTP status; Error is set instead when
+	// the attempt got no response.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Reason is the dead-letter reason of a failed invocation.
+	Reason string `json:"reason,omitempty"`
+	// Response is the last attempt's response body, up to MaxStatusBodyBytes.
+	Response          []byte `json:"response,omitempty"`
+	ResponseTruncated bool   `json:"responseTruncated,omitempty"`
+	// NextAttemptAt is when a retrying or scheduled invocation is next
+	// delivered.
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	EnqueuedAt    time.Time  `json:"enqueuedAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// StatusStore keeps InvocationStatus records in the statestore KV, scoped to
+// the invocation's namespace. Recording is best-effort: a failed write is
+// logged and never fails or delays the invocation itself. A nil StatusStore
+// records nothing.
+type StatusStore struct {
+	kv     statestore.KVStore
+	ttl    time.Duration
+	logger logr.Logger
+	now    func() time.Time
+}
+
+// NewStatusStore returns a StatusStore over kv whose records expire ttl after
+// their last update (ttl <= 0 → DefaultStatusTTL).
+func NewStatusStore(kv statestore.KVStore, ttl time.Duration, logger logr.Logger) *StatusStore {
+	if ttl <= 0 {
+		ttl = DefaultStatusTTL
+	}
+	return &StatusStore{kv: kv, ttl: ttl, logger: logger, now: time.Now}
+}
+
+func statusScope(namespace string) statestore.Scope {
+	return statestore.Scope{Namespace: namespace, Owner: statusOwner, Keyspace: statusKeyspace}
+}
+
+// Get returns the status of invocation id in namespace, or an error wrapping
+// statestore.ErrNotFound when there is none (never recorded, or expired).
+func (s *StatusStore) Get(ctx context.Context, namespace, id string) (InvocationStatus, error) {
+	st, _, err := s.get(ctx, namespace, id)
+	return st, err
+}
+
+// Cancel cancels a queued, scheduled or retrying invocation so the dispatcher
+// acks it without delivering it when it is next leased. It returns the
+// cancelled record, an error wrapping statestore.ErrNotFound when there is
+// none, or ErrNotCancellable once an attempt is in flight or the invocation
+// has settled. Cancel and the dispatcher's start of an attempt are both CAS
+// writes of the same record, so exactly one of them wins a race.
+func (s *StatusStore) Cancel(ctx context.Context, namespace, id string) (InvocationStatus, error) {
+	for range casAttempts {
+		if err := ctx.Err(); err != nil {
+			return InvocationStatus{}, err
+		}
+		st, version, err := s.get(ctx, namespace, id)
+		if err != nil {
+			return InvocationStatus{}, err
+		}
+		switch st.State {
+		case StateQueued, StateRetrying:
+		case StateCancelled:
+			return st, nil
+		default:
+			return st, fmt.Errorf("%w: it is %s", ErrNotCancellable, st.State)
+		}
+		// Keep the record until the dispatcher leases the message, or it
+		// would expire and the message be delivered after all.
+		var until time.Time
+		if st.NextAttemptAt != nil {
+			until = *st.NextAttemptAt
+		}
+		st.State, st.NextAttemptAt, st.UpdatedAt = StateCancelled, nil, s.now()
+		err = s.set(ctx, st, &version, until)
+		if errors.Is(err, statestore.ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return InvocationStatus{}, err
+		}
+		return st, nil
+	}
+	return InvocationStatus{}, casExhausted()
+}
+
+// casExhausted is the error of a CAS loop that ran out of casAttempts.
+func casExhausted() error {
+	return fmt.Errorf("%w: still conflicting after %d attempts", statestore.ErrVersionConflict, casAttempts)
+}
+
+func (s *StatusStore) get(ctx context.Context, namespace, id string) (InvocationStatus, int64, error) {
+	v, err := s.kv.Get(ctx, statusScope(namespace), id)
+	if err != nil {
+		return InvocationStatus{}, 0, err
+	}
+	var st InvocationStatus
+	if err := json.Unmarshal(v.Data, &st); err != nil {
+		return InvocationStatus{}, 0, err
+	}
+	return st, v.Version, nil
+}
+
+// Queued records a freshly enqueued invocation; Enqueue calls it, and so
+// does any other producer of envelopes (the timer's slots). It is
+// create-only: an enqueue the dedup key collapsed onto an in-flight
+// invocation must not rewind that invocation's record, and it reports false
+// for one — the record was already there, which a fresh message id never
+// finds.
+func (s *StatusStore) Queued(ctx context.Context, id string, env Envelope) bool {
+	if s == nil {
+		return true
+	}
+	created := int64(0)
+	st := InvocationStatus{ID: id, Namespace: env.Namespace, Function: env.Function, State: StateQueued, EnqueuedAt: env.EnqueueTime}
+	if !env.InvokeAt.IsZero() {
+		st.NextAttemptAt = &env.InvokeAt
+	}
+	st.UpdatedAt = s.now()
+	err := s.set(ctx, st, &created, env.InvokeAt)
+	if errors.Is(err, statestore.ErrVersionConflict) {
+		return false
+	}
+	if err != nil {
+		s.failed(ctx, err, id, StateQueued)
+	}
+	return true
+}
+
+// start records the start of attempt and reports whether to deliver it: false
+// means the invocation was cancelled. The record is rewritten by CAS on the
+// version it was read at, so a concurrent Cancel either lands first (and start
+// sees it) or conflicts. Any other store failure is logged and delivers — the
+// status record never blocks an invocation.
+func (s *StatusStore) start(ctx context.Context, id string, env Envelope, attempt int) bool {
+	if s == nil {
+		return true
+	}
+	for range casAttempts {
+		if err := ctx.Err(); err != nil {
+			s.failed(ctx, err, id, StateRunning)
+			return true
+		}
+		st, version, err := s.get(ctx, env.Namespace, id)
+		switch {
+		case errors.Is(err, statestore.ErrNotFound):
+			version = 0
+		case err != nil:
+			s.failed(ctx, err, id, StateRunning)
+			return true
+		case st.State == StateCancelled:
+			return false
+		}
+		next := s.base(id, env, StateRunning, attempt)
+		next.UpdatedAt = s.now()
+		err = s.set(ctx, next, &version, time.Time{})
+		if errors.Is(err, statestore.ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			s.failed(ctx, err, id, StateRunning)
+		}
+		return true
+	}
+	s.failed(ctx, casExhausted(), id, StateRunning)
+	return true
+}
+
+// settled records an attempt's outcome: state is StateRetrying (with the next
+// attempt's time), StateSucceeded, or StateFailed (with the dead-letter
+// reason).
+func (s *StatusStore) settled(ctx context.Context, id string, env Envelope, attempt int, state string, res DeliveryResult, reason string, nextAttempt time.Time) {
+	st := s.base(id, env, state, attempt)
+	st.StatusCode = res.StatusCode
+	if res.Err != nil {
+		st.Error = res.Err.Error()
+	}
+	st.Reason = reason
+	st.Response, st.ResponseTruncated = res.Body, res.BodyTruncated
+	if len(st.Response) > MaxStatusBodyBytes {
+		st.Response, st.ResponseTruncated = st.Response[:MaxStatusBodyBytes], true
+	}
+	if !nextAttempt.IsZero() {
+		st.NextAttemptAt = &nextAttempt
+	}
+	s.write(ctx, st, nextAttempt)
+}
+
+func (s *StatusStore) base(id string, env Envelope, state string, attempt int) InvocationStatus {
+	return InvocationStatus{ID: id, Namespace: env.Namespace, Function: env.Function, State: state, Attempts: attempt, EnqueuedAt: env.EnqueueTime}
+}
+
+// write stores st best-effort, logging a failure. until extends the record's
+// TTL so it outlives a scheduled or backed-off next attempt.
+func (s *StatusStore) write(ctx context.Context, st InvocationStatus, until time.Time) {
+	if s == nil {
+		return
+	}
+	st.UpdatedAt = s.now()
+	if err := s.set(ctx, st, nil, until); err != nil {
+		s.failed(ctx, err, st.ID, st.State)
+	}
+}
+
+func (s *StatusStore) set(ctx context.Context, st InvocationStatus, ifVersion *int64, until time.Time) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	ttl := s.ttl
+	if d := until.Sub(s.now()); d > 0 {
+		ttl += d
+	}
+	return s.kv.Set(ctx, statusScope(st.Namespace), st.ID, data, statestore.SetOptions{IfVersion: ifVersion, TTL: ttl})
+}
+
+func (s *StatusStore) failed(ctx context.Context, err error, id, state string) {
+	recordStatusWriteError(ctx)
+	s.logger.Error(err, "recording async invocation status", "id", id, "state", state)
+}
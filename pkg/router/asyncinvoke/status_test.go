@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func memStore(t *testing.T) (statestore.Queue, *StatusStore) {
+	t.Helper()
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	return q, NewStatusStore(kv, time.Hour, logr.Discard())
+}
+
+func statusOf(t *testing.T, s *StatusStore, id string) InvocationStatus {
+	t.Helper()
+	st, err := s.Get(t.Context(), "ns", id)
+	require.NoError(t, err)
+	return st
+}
+
+// TestStatusLifecycle follows one invocation from enqueue through a retry to
+// success, then a second one to the dead-letter queue.
+func TestStatusLifecycle(t *testing.T) {
+	t.Parallel()
+	q, status := memStore(t)
+	enqueue := func(dedup string) string {
+		r := httptest.NewRequest("POST", "/fn", strings.NewReader("payload"))
+		id, err := Enqueue(t.Context(), q, httptest.NewRecorder(), r, Params{
+			Namespace: "ns", Function: "fn", DedupKey: dedup, Status: status,
+			Policy: Policy{NoJitter: true, BackoffBase: time.Millisecond, BackoffCap: time.Millisecond, MaxAge: time.Hour},
+		})
+		require.NoError(t, err)
+		return id
+	}
+	id := enqueue("k1")
+	st := statusOf(t, status, id)
+	assert.Equal(t, StateQueued, st.State)
+	assert.Equal(t, "fn", st.Function)
+	assert.False(t, st.EnqueuedAt.IsZero())
+
+	var res DeliveryResult
+	d := New(Options{
+		Queue: q, Logger: logr.Discard(), Status: status, Rand: func() float64 { return 0.5 },
+		Deliverer: delivererFunc(func(_ context.Context, _ Envelope, id string, _ int) DeliveryResult {
+			assert.Equal(t, StateRunning, statusOf(t, status, id).State, "the attempt is recorded before delivery")
+			return res
+		}),
+	})
+	lease := func() statestore.LeasedMessage {
+		var msgs []statestore.LeasedMessage
+		require.Eventually(t, func() bool {
+			var err error
+			msgs, err = q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+			return err == nil && len(msgs) == 1
+		}, time.Second, time.Millisecond)
+		return msgs[0]
+	}
+
+	res = DeliveryResult{StatusCode: 503, Body: []byte("busy")}
+	d.process(t.Context(), lease())
+	st = statusOf(t, status, id)
+	assert.Equal(t, StateRetrying, st.State)
+	assert.Equal(t, 1, st.Attempts)
+	assert.Equal(t, 503, st.StatusCode)
+	require.NotNil(t, st.NextAttemptAt)
+
+	// A dedup-collapsed enqueue of the same work must not rewind the record.
+	assert.Equal(t, id, enqueue("k1"))
+	assert.Equal(t, StateRetrying, statusOf(t, status, id).State)
+
+	res = DeliveryResult{StatusCode: 200, Body: bytes.Repeat([]byte("x"), MaxStatusBodyBytes+1)}
+	d.process(t.Context(), lease())
+	st = statusOf(t, status, id)
+	assert.Equal(t, StateSucceeded, st.State)
+	assert.Equal(t, 2, st.Attempts)
+	assert.Equal(t, 200, st.StatusCode)
+	assert.Nil(t, st.NextAttemptAt)
+	assert.Len(t, st.Response, MaxStatusBodyBytes)
+	assert.True(t, st.ResponseTruncated)
+
+	failed := enqueue("")
+	res = DeliveryResult{StatusCode: 400, Body: []byte("bad input")}
+	d.process(t.Context(), lease())
+	st = statusOf(t, status, failed)
+	assert.Equal(t, StateFailed, st.State)
+	assert.Equal(t, ReasonHTTP4xx, st.Reason)
+	assert.Equal(t, []byte("bad input"), st.Response)
+
+	_, err := status.Get(t.Context(), "other", id)
+	assert.ErrorIs(t, err, statestore.ErrNotFound, "records are namespace-scoped")
+}
+
+// TestStatusCancel cancels a scheduled invocation, which the dispatcher then
+// acks undelivered once it becomes visible.
+func TestStatusCancel(t *testing.T) {
+	t.Parallel()
+	q, status := memStore(t)
+	r := httptest.NewRequest("POST", "/fn", strings.NewReader("payload"))
+	invokeAt := time.Now().Add(50 * time.Millisecond)
+	id, err := Enqueue(t.Context(), q, httptest.NewRecorder(), r, Params{Namespace: "ns", Function: "fn", Status: status, InvokeAt: invokeAt})
+	require.NoError(t, err)
+
+	st := statusOf(t, status, id)
+	assert.Equal(t, StateQueued, st.State)
+	require.NotNil(t, st.NextAttemptAt)
+	assert.True(t, invokeAt.Equal(*st.NextAttemptAt))
+	msgs, err := q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, msgs, "a scheduled invocation is invisible until its InvokeAt")
+
+	st, err = status.Cancel(t.Context(), "ns", id)
+	require.NoError(t, err)
+	assert.Equal(t, StateCancelled, st.State)
+	assert.Nil(t, st.NextAttemptAt)
+	_, err = status.Cancel(t.Context(), "ns", id)
+	require.NoError(t, err, "cancelling twice is a no-op")
+	_, err = status.Cancel(t.Context(), "ns", "asyncinv/unknown")
+	require.ErrorIs(t, err, statestore.ErrNotFound)
+
+	delivered := false
+	d := New(Options{
+		Queue: q, Logger: logr.Discard(), Status: status,
+		Deliverer: delivererFunc(func(context.Context, Envelope, string, int) DeliveryResult {
+			delivered = true
+			return DeliveryResult{StatusCode: 200}
+		}),
+	})
+	require.Eventually(t, func() bool {
+		msgs, err = q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+		return err == nil && len(msgs) == 1
+	}, time.Second, 5*time.Millisecond)
+	d.process(t.Context(), msgs[0])
+	assert.False(t, delivered, "a cancelled invocation is never delivered")
+	stats, err := q.Stats(t.Context(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Leased, "the cancelled message is acked")
+	assert.Equal(t, StateCancelled, statusOf(t, status, id).State)
+}
+
+func TestStatusCancelAfterStart(t *testing.T) {
+	t.Parallel()
+	_, status := memStore(t)
+	env := Envelope{Namespace: "ns", Function: "fn"}
+	status.Queued(t.Context(), "asyncinv/1", env)
+	require.True(t, status.start(t.Context(), "asyncinv/1", env, 1))
+
+	st, err := status.Cancel(t.Context(), "ns", "asyncinv/1")
+	require.ErrorIs(t, err, ErrNotCancellable)
+	assert.Equal(t, StateRunning, st.State)
+
+	status.settled(t.Context(), "asyncinv/1", env, 1, StateSucceeded, DeliveryResult{StatusCode: 200}, "", time.Time{})
+	_, err = status.Cancel(t.Context(), "ns", "asyncinv/1")
+	require.ErrorIs(t, err, ErrNotCancellable)
+}
+
+// conflictingKV reports a version conflict on every conditional write,
+// standing in for a record some other writer keeps changing.
+type conflictingKV struct {
+	statestore.KVStore
+	writes int
+}
+
+func (kv *conflictingKV) Set(ctx context.Context, s statestore.Scope, key string, val []byte, o statestore.SetOptions) error {
+	if o.IfVersion == nil {
+		return kv.KVStore.Set(ctx, s, key, val, o)
+	}
+	kv.writes++
+	return statestore.ErrVersionConflict
+}
+
+func (kv *conflictingKV) Delete(context.Context, statestore.Scope, string, int64) error {
+	kv.writes++
+	return statestore.ErrVersionConflict
+}
+
+// TestStatusCASBounded pins that Cancel and start give up on a record that
+// keeps conflicting, and stop at once when ctx ends.
+func TestStatusCASBounded(t *testing.T) {
+	t.Parallel()
+	_, status := memStore(t)
+	env := Envelope{Namespace: "ns", Function: "fn"}
+	status.Queued(t.Context(), "asyncinv/1", env)
+	kv := &conflictingKV{KVStore: status.kv}
+	status.kv = kv
+
+	_, err := status.Cancel(t.Context(), "ns", "asyncinv/1")
+	require.ErrorIs(t, err, statestore.ErrVersionConflict)
+	assert.Equal(t, casAttempts, kv.writes)
+
+	kv.writes = 0
+	assert.True(t, status.start(t.Context(), "asyncinv/1", env, 1), "a status failure never blocks delivery")
+	assert.Equal(t, casAttempts, kv.writes)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	kv.writes = 0
+	_, err = status.Cancel(ctx, "ns", "asyncinv/1")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, kv.writes)
+}
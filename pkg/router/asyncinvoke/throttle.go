@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	inflightKeyspace = "inflight"
+
+	// throttleRetry is how long a delivery held back by MaxInFlight waits
+	// before it is leased again; the dispatcher adds jitter so a deferred
+	// backlog does not come back in one wave.
+	throttleRetry = time.Second
+	// minRateWait floors the wait of a delivery held back by the rate cap, so
+	// a sub-millisecond RetryAfter does not spin the message through the queue.
+	minRateWait = 10 * time.Millisecond
+
+	// Throttle reasons, the fission_async_throttled_total label.
+	throttledInFlight = "in_flight"
+	throttledRate     = "rate"
+)
+
+// Throttle is a function's async delivery caps; a zero field is uncapped.
+type Throttle struct {
+	// MaxInFlight bounds the function's concurrent deliveries.
+	MaxInFlight int
+	// PerSecond bounds how many deliveries to the function start per second.
+	PerSecond int
+}
+
+func (t Throttle) enabled() bool { return t.MaxInFlight > 0 || t.PerSecond > 0 }
+
+// RateFunc takes one delivery from a function's shared per-second budget. It
+// returns true when the delivery may start, or false and the time until the
+// budget has room again. The router backs it with its rate-limit buckets —
+// injected as a function so this package stays free of them.
+type RateFunc func(ctx context.Context, namespace, function string, perSecond int) (bool, time.Duration)
+
+// inflightRecord is the statestore KV record of a function's deliveries in
+// progress: invocation ID → the instant its slot lapses.
+type inflightRecord struct {
+	Holders map[string]time.Time `json:"holders"`
+}
+
+// Throttler enforces functions' Throttle caps across router replicas. The
+// in-flight count is a KV record per function, updated by CAS, whose slots
+// lapse at their delivery deadline: a replica that dies mid-delivery frees its
+// slots when the lease it was delivering under would have expired anyway.
+//
+// A store failure lets the delivery through (logged): the queue lives in the
+// same store, so a throttle that failed closed would stall delivery outright.
+// A record too contended to update is not a failure: the delivery is held
+// back like any over the cap, since contention is exactly a stampede.
+type Throttler struct {
+	kv     statestore.KVStore
+	rate   RateFunc
+	logger logr.Logger
+}
+
+// NewThrottler returns a Throttler keeping in-flight slots in kv and taking
+// rate budget from rate. A nil rate leaves PerSecond unenforced.
+func NewThrottler(kv statestore.KVStore, rate RateFunc, logger logr.Logger) *Throttler {
+	return &Throttler{kv: kv, rate: rate, logger: logger}
+}
+
+func inflightScope(namespace string) statestore.Scope {
+	return statestore.Scope{Namespace: namespace, Owner: statusOwner, Keyspace: inflightKeyspace}
+}
+
+// acquire admits invocation id of namespace/function under t, holding an
+// in-flight slot until release or deadline. When a cap is reached it returns
+// the throttle reason and how long to hold the invocation back.
+func (th *Throttler) acquire(ctx context.Context, namespace, function, id string, t Throttle, now, deadline time.Time) (reason string, wait time.Duration) {
+	if t.MaxInFlight > 0 {
+		full := false
+		err := th.update(ctx, namespace, function, func(rec *inflightRecord) bool {
+			for h, until := range rec.Holders {
+				if !until.After(now) {
+					delete(rec.Holders, h)
+				}
+			}
+			// A redelivery of an invocation already holding a slot (its previous
+			// lease expired mid-delivery) takes the slot over.
+			if _, held := rec.Holders[id]; !held && len(rec.Holders) >= t.MaxInFlight {
+				full = true
+				return false
+			}
+			rec.Holders[id] = deadline
+			return true
+		})
+		switch {
+		case full, errors.Is(err, statestore.ErrVersionConflict), ctx.Err() != nil:
+			return throttledInFlight, throttleRetry
+		case err != nil:
+			th.logger.Error(err, "taking async in-flight slot; delivering unthrottled", "namespace", namespace, "function", function)
+		}
+	}
+	if t.PerSecond > 0 && th.rate != nil {
+		if ok, retryAfter := th.rate(ctx, namespace, function, t.PerSecond); !ok {
+			if t.MaxInFlight > 0 {
+				th.release(ctx, namespace, function, id)
+			}
+			return throttledRate, max(retryAfter, minRateWait)
+		}
+	}
+	return "", 0
+}
+
+// release frees id's in-flight slot. A failure is logged: the slot lapses at
+// its deadline instead.
+func (th *Throttler) release(ctx context.Context, namespace, function, id string) {
+	err := th.update(ctx, namespace, function, func(rec *inflightRecord) bool {
+		if _, held := rec.Holders[id]; !held {
+			return false
+		}
+		delete(rec.Holders, id)
+		return true
+	})
+	if err != nil {
+		th.logger.Error(err, "releasing async in-flight slot", "namespace", namespace, "function", function)
+	}
+}
+
+// update applies fn to function's in-flight record under CAS, retrying a
+// conflict up to casAttempts times. fn returns false to leave the record as
+// it is; a record left with no holders is deleted. A missing record reaches
+// fn empty.
+func (th *Throttler) update(ctx context.Context, namespace, function string, fn func(*inflightRecord) bool) error {
+	scope := inflightScope(namespace)
+	for range casAttempts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rec := inflightRecord{Holders: map[string]time.Time{}}
+		var version int64
+		v, err := th.kv.Get(ctx, scope, function)
+		switch {
+		case errors.Is(err, statestore.ErrNotFound):
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(v.Data, &rec); err != nil {
+				return err
+			}
+			if rec.Holders == nil {
+				rec.Holders = map[string]time.Time{}
+			}
+			version = v.Version
+		}
+		if !fn(&rec) {
+			return nil
+		}
+		if len(rec.Holders) == 0 {
+			if version == 0 {
+				return nil
+			}
+			err = th.kv.Delete(ctx, scope, function, version)
+		} else {
+			var data []byte
+			if data, err = json.Marshal(rec); err != nil {
+				return err
+			}
+			err = th.kv.Set(ctx, scope, function, data, statestore.SetOptions{IfVersion: &version})
+		}
+		if !errors.Is(err, statestore.ErrVersionConflict) {
+			return err
+		}
+	}
+	return casExhausted()
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func memQueueKV(t *testing.T) (statestore.Queue, statestore.KVStore) {
+	t.Helper()
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	return q, kv
+}
+
+func holders(t *testing.T, kv statestore.KVStore, function string) int {
+	t.Helper()
+	v, err := kv.Get(t.Context(), inflightScope("ns"), function)
+	if errors.Is(err, statestore.ErrNotFound) {
+		return 0
+	}
+	require.NoError(t, err)
+	rec := inflightRecord{}
+	require.NoError(t, json.Unmarshal(v.Data, &rec))
+	return len(rec.Holders)
+}
+
+func throttleDispatcher(q statestore.Queue, th *Throttler, d Deliverer, t Throttle) *Dispatcher {
+	return New(Options{
+		Queue: q, Deliverer: d, Logger: logr.Discard(), Throttler: th,
+		ResolveFunctionConfig: resolverFor(FunctionConfig{Throttle: t}),
+		Rand:                  func() float64 { return 0 },
+	})
+}
+
+func leaseNext(t *testing.T, q statestore.Queue) statestore.LeasedMessage {
+	t.Helper()
+	msgs, err := q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	return msgs[0]
+}
+
+// TestThrottleInFlight fills a function's MaxInFlight: the next delivery is
+// deferred undelivered (counted as throttled, not ready), and a delivery that runs
+// frees its slot when it settles.
+func TestThrottleInFlight(t *testing.T) {
+	t.Parallel()
+	q, kv := memQueueKV(t)
+	th := NewThrottler(kv, nil, logr.Discard())
+	delivered := 0
+	d := throttleDispatcher(q, th, delivererFunc(func(context.Context, Envelope, string, int) DeliveryResult {
+		delivered++
+		return DeliveryResult{StatusCode: 200}
+	}), Throttle{MaxInFlight: 1})
+	env := Envelope{Version: EnvelopeVersion, Namespace: "ns", Function: "fn", EnqueueTime: time.Now()}
+
+	now := time.Now()
+	reason, _ := th.acquire(t.Context(), "ns", "fn", "elsewhere", Throttle{MaxInFlight: 1}, now, now.Add(time.Minute))
+	require.Empty(t, reason, "the first delivery takes the only slot")
+
+	enqueueEnvelope(t, q, env)
+	msg := leaseNext(t, q)
+	d.process(t.Context(), msg)
+	assert.Zero(t, delivered, "over the cap: not delivered")
+	st, err := q.Stats(t.Context(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), st.Deferred)
+	assert.Zero(t, st.Visible)
+	assert.Zero(t, st.Leased)
+
+	th.release(t.Context(), "ns", "fn", "elsewhere")
+	assert.Zero(t, holders(t, kv, "fn"))
+	enqueueEnvelope(t, q, env)
+	d.process(t.Context(), leaseNext(t, q))
+	assert.Equal(t, 1, delivered)
+	assert.Zero(t, holders(t, kv, "fn"), "a settled delivery frees its slot")
+}
+
+// TestThrottleSlotsLapse shows a slot whose holder died mid-delivery frees at
+// its deadline, and a redelivery of a holder reuses its own slot.
+func TestThrottleSlotsLapse(t *testing.T) {
+	t.Parallel()
+	_, kv := memQueueKV(t)
+	th := NewThrottler(kv, nil, logr.Discard())
+	cap1 := Throttle{MaxInFlight: 1}
+	now := time.Now()
+
+	reason, _ := th.acquire(t.Context(), "ns", "fn", "a", cap1, now, now.Add(time.Minute))
+	require.Empty(t, reason)
+	reason, _ = th.acquire(t.Context(), "ns", "fn", "a", cap1, now, now.Add(time.Minute))
+	assert.Empty(t, reason, "a redelivery takes over its own slot")
+	reason, wait := th.acquire(t.Context(), "ns", "fn", "b", cap1, now, now.Add(time.Minute))
+	assert.Equal(t, throttledInFlight, reason)
+	assert.Equal(t, throttleRetry, wait)
+	reason, _ = th.acquire(t.Context(), "ns", "other", "b", cap1, now, now.Add(time.Minute))
+	assert.Empty(t, reason, "caps are per function")
+
+	later := now.Add(2 * time.Minute)
+	reason, _ = th.acquire(t.Context(), "ns", "fn", "b", cap1, later, later.Add(time.Minute))
+	assert.Empty(t, reason, "a's slot lapsed at its deadline")
+	assert.Equal(t, 1, holders(t, kv, "fn"))
+}
+
+// TestThrottleUpdateBounded pins that an in-flight record that keeps
+// conflicting gives up and holds the delivery back rather than letting it
+// past the cap, and that an update stops at once when ctx ends.
+func TestThrottleUpdateBounded(t *testing.T) {
+	t.Parallel()
+	_, kv := memQueueKV(t)
+	ckv := &conflictingKV{KVStore: kv}
+	th := NewThrottler(ckv, nil, logr.Discard())
+	now := time.Now()
+
+	reason, wait := th.acquire(t.Context(), "ns", "fn", "a", Throttle{MaxInFlight: 1}, now, now.Add(time.Minute))
+	assert.Equal(t, throttledInFlight, reason)
+	assert.Equal(t, throttleRetry, wait)
+	assert.Equal(t, casAttempts, ckv.writes)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	ckv.writes = 0
+	err := th.update(ctx, "ns", "fn", func(*inflightRecord) bool { return true })
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, ckv.writes)
+}
+
+// TestThrottleCapHoldsUnderConflicts takes one of a function's two slots,
+// then makes every update of its record conflict, as a stampede across
+// replicas does: a delivery that cannot record its slot is held back, so
+// none slips past the cap uncounted.
+func TestThrottleCapHoldsUnderConflicts(t *testing.T) {
+	t.Parallel()
+	_, kv := memQueueKV(t)
+	cap2 := Throttle{MaxInFlight: 2}
+	now := time.Now()
+	th := NewThrottler(kv, nil, logr.Discard())
+	reason, _ := th.acquire(t.Context(), "ns", "fn", "a", cap2, now, now.Add(time.Minute))
+	require.Empty(t, reason)
+
+	th.kv = &conflictingKV{KVStore: kv}
+	for _, id := range []string{"c", "d", "e"} {
+		reason, wait := th.acquire(t.Context(), "ns", "fn", id, cap2, now, now.Add(time.Minute))
+		assert.Equal(t, throttledInFlight, reason, id)
+		assert.Equal(t, throttleRetry, wait, id)
+	}
+	assert.Equal(t, 1, holders(t, kv, "fn"))
+}
+
+// TestThrottleRate defers a delivery the rate cap refuses, by the wait the
+// rate function reports, and gives back the in-flight slot it took.
+func TestThrottleRate(t *testing.T) {
+	t.Parallel()
+	q, kv := memQueueKV(t)
+	var gotFunction string
+	var gotPerSecond int
+	th := NewThrottler(kv, func(_ context.Context, _, function string, perSecond int) (bool, time.Duration) {
+		gotFunction, gotPerSecond = function, perSecond
+		return false, time.Hour
+	}, logr.Discard())
+	delivered := 0
+	d := throttleDispatcher(q, th, delivererFunc(func(context.Context, Envelope, string, int) DeliveryResult {
+		delivered++
+		return DeliveryResult{StatusCode: 200}
+	}), Throttle{MaxInFlight: 5, PerSecond: 20})
+
+	// An alias-pinned destination is capped as its function.
+	enqueueEnvelope(t, q, Envelope{Version: EnvelopeVersion, Namespace: "ns", Function: "fn:live", EnqueueTime: time.Now()})
+	d.process(t.Context(), leaseNext(t, q))
+	assert.Zero(t, delivered)
+	assert.Equal(t, "fn", gotFunction)
+	assert.Equal(t, 20, gotPerSecond)
+	assert.Zero(t, holders(t, kv, "fn"), "the refused delivery gave its slot back")
+	st, err := q.Stats(t.Context(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), st.Deferred)
+}
@@ -0,0 +1,434 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/utils/backoff"
+)
+
+const (
+	// WebhookQueue carries webhook destination deliveries. It is separate from
+	// DefaultQueue so a slow or failing external endpoint never holds up
+	// function deliveries, and its dead letters are the webhook destinations'
+	// own DLQ.
+	WebhookQueue = "webhook"
+
+	// HeaderSignature carries a signed webhook delivery's HMAC:
+	// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+	HeaderSignature = "X-Fission-Signature"
+
+	// webhookTimeout bounds one webhook delivery; webhookLease stays above it
+	// so a delivery's context always expires before its lease (invariant A7).
+	webhookTimeout = 30 * time.Second
+	webhookLease   = 2 * time.Minute
+
+	// webhookMaxResponseBytes is how much of a webhook's response is read (and
+	// discarded) so the connection can be reused.
+	webhookMaxResponseBytes = 64 << 10
+)
+
+// WebhookJob is one webhook destination delivery, enqueued on WebhookQueue
+// when the source invocation settles. Namespace, Function, and Destination
+// name the destination it was fired for (the dead-letter listing shows them);
+// InvocationID is the source invocation's, sent on every attempt so the
+// receiver can deduplicate.
+type WebhookJob struct {
+	Namespace    string  `json:"namespace"`
+	Function     string  `json:"function"`
+	Destination  string  `json:"destination"` // "onSuccess" or "onFailure"
+	InvocationID string  `json:"invocationId"`
+	Webhook      Webhook `json:"webhook"`
+	// Headers are the content headers of Body: Content-Type, or the binary-mode
+	// CloudEvent attributes.
+	Headers     map[string]string `json:"headers"`
+	Body        []byte            `json:"body"`
+	EnqueueTime time.Time         `json:"enqueueTime"`
+}
+
+// DecodeWebhookJob parses a WebhookQueue message body. A body without a
+// webhook URL is not a job.
+func DecodeWebhookJob(data []byte) (WebhookJob, error) {
+	var job WebhookJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return job, err
+	}
+	if job.Webhook.URL == "" {
+		return job, errors.New("asyncinvoke: webhook job has no url")
+	}
+	return job, nil
+}
+
+// enqueueWebhook fires a webhook destination: the result is queued for the
+// WebhookDispatcher, which delivers it under the webhook's own retry policy.
+// Like a topic, a webhook is a leaf of the destination chain. The enqueue is
+// deduplicated per invocation and destination, so a settle that fires twice
+// still delivers once.
+func (d *Dispatcher) enqueueWebhook(ctx context.Context, dest *Destination, result ResultEnvelope) {
+	body, err := result.Encode()
+	if err != nil {
+		recordDestination(ctx, "encode_error")
+		d.logger.Error(err, "encoding destination result envelope", "namespace", dest.FunctionNamespace, "webhook", dest.Webhook.URL)
+		return
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	if dest.CloudEvents {
+		headers = result.resultEvent(body).BinaryHeaders()
+	}
+	destination := "onFailure"
+	if result.RequestContext.Condition == ConditionSuccess {
+		destination = "onSuccess"
+	}
+	_, fn, _ := strings.Cut(result.RequestContext.FunctionRef, "/")
+	job, err := json.Marshal(WebhookJob{
+		Namespace:    dest.FunctionNamespace,
+		Function:     fn,
+		Destination:  destination,
+		InvocationID: result.RequestContext.InvocationID,
+		Webhook:      *dest.Webhook,
+		Headers:      headers,
+		Body:         body,
+		EnqueueTime:  d.now(),
+	})
+	if err != nil {
+		recordDestination(ctx, "encode_error")
+		d.logger.Error(err, "encoding webhook job", "namespace", dest.FunctionNamespace, "webhook", dest.Webhook.URL)
+		return
+	}
+	_, err = d.q.Enqueue(ctx, WebhookQueue, statestore.Message{Body: job}, statestore.EnqueueOptions{
+		DedupKey: result.RequestContext.InvocationID + "/" + destination,
+	})
+	if err != nil {
+		recordDestination(ctx, "enqueue_error")
+		d.logger.Error(err, "enqueuing webhook destination", "namespace", dest.FunctionNamespace, "webhook", dest.Webhook.URL)
+		return
+	}
+	recordDestination(ctx, "webhook_enqueued")
+}
+
+// SigningKeyFunc returns the signing key held under key in the Secret
+// namespace/name. Injected so this package stays free of the Kubernetes
+// client.
+type SigningKeyFunc func(ctx context.Context, namespace, name, key string) ([]byte, error)
+
+// WebhookOptions configures a WebhookDispatcher. Queue and Logger are
+// required; the rest default.
+type WebhookOptions struct {
+	Queue  statestore.Queue
+	Logger logr.Logger
+
+	// SigningKey reads the key of a webhook with a signing secret. nil →
+	// deliveries of such webhooks fail, and are retried.
+	SigningKey SigningKeyFunc
+
+	// Transport sends the deliveries. nil → a transport that refuses
+	// internal addresses (see publicTransport).
+	Transport http.RoundTripper
+	// AllowedNetworks are private networks the default transport may reach
+	// anyway: the intended in-cluster receivers. Loopback, link-local and
+	// unspecified addresses stay refused.
+	AllowedNetworks []netip.Prefix
+
+	BatchSize    int           // 0 → DefaultBatchSize
+	PollInterval time.Duration // 0 → DefaultPollInterval
+
+	Now  func() time.Time // nil → time.Now
+	Rand func() float64   // nil → rand/v2 Float64
+}
+
+// WebhookDispatcher leases WebhookQueue and POSTs each job to its webhook,
+// settling it with the same matrix as a function delivery (classify): a 2xx
+// acks, a permanent 4xx dead-letters, anything else retries with backoff
+// until the webhook's attempt budget or max age is spent.
+type WebhookDispatcher struct {
+	q            statestore.Queue
+	logger       logr.Logger
+	signingKey   SigningKeyFunc
+	client       *http.Client
+	batchSize    int
+	pollInterval time.Duration
+	now          func() time.Time
+	rand         func() float64
+}
+
+// NewWebhookDispatcher builds a WebhookDispatcher from opts, applying defaults.
+func NewWebhookDispatcher(opts WebhookOptions) *WebhookDispatcher {
+	w := &WebhookDispatcher{
+		q:            opts.Queue,
+		logger:       opts.Logger,
+		signingKey:   opts.SigningKey,
+		batchSize:    opts.BatchSize,
+		pollInterval: opts.PollInterval,
+		now:          opts.Now,
+		rand:         opts.Rand,
+	}
+	transport := opts.Transport
+	if transport == nil {
+		transport = publicTransport(opts.AllowedNetworks)
+	}
+	// A redirect is the receiver's answer, not a hop to follow: following it
+	// would send the signed result to an address admission never saw.
+	w.client = &http.Client{
+		Transport:     transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = DefaultBatchSize
+	}
+	if w.pollInterval <= 0 {
+		w.pollInterval = DefaultPollInterval
+	}
+	if w.now == nil {
+		w.now = time.Now
+	}
+	if w.rand == nil {
+		w.rand = rand.Float64
+	}
+	return w
+}
+
+// cgnatNetwork is the RFC 6598 shared address space, which carriers and some
+// clusters' pod networks use and IsPrivate does not cover.
+var cgnatNetwork = netip.MustParsePrefix("100.64.0.0/10")
+
+// ParseWebhookNetworks parses a comma-separated list of CIDRs for
+// WebhookOptions.AllowedNetworks.
+func ParseWebhookNetworks(s string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(f)
+		if err != nil {
+			return nil, fmt.Errorf("webhook network %q: %w", f, err)
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// webhookAddrAllowed reports whether a webhook may be delivered to ip. It
+// refuses the addresses a webhook must never reach from inside the router:
+// loopback, link-local (including the cloud metadata endpoint), unspecified,
+// and the private ranges — RFC 1918, ULA fc00::/7 and CGNAT 100.64/10 —
+// where the cluster's Services, pods and control plane live. A private
+// address inside allowed is let through.
+func webhookAddrAllowed(ip netip.Addr, allowed []netip.Prefix) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	if !ip.IsPrivate() && !cgnatNetwork.Contains(ip) {
+		return true
+	}
+	for _, prefix := range allowed {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicTransport is http.DefaultTransport with a dialer that refuses the
+// addresses webhookAddrAllowed does. The check runs on the resolved address,
+// so a DNS name pointing there is refused too. It never uses an environment
+// proxy: the dialer would then check the proxy's address, not the target's.
+func publicTransport(allowed []netip.Prefix) http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !webhookAddrAllowed(ip, allowed) {
+				return fmt.Errorf("asyncinvoke: webhook address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = dialer.DialContext
+	t.Proxy = nil
+	return t
+}
+
+// Run leases and settles webhook jobs until ctx is cancelled, like
+// Dispatcher.Run.
+func (w *WebhookDispatcher) Run(ctx context.Context) error {
+	w.logger.Info("async webhook dispatcher started", "queue", WebhookQueue)
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if n := w.pollOnce(ctx); n == 0 {
+			if !sleepCtx(ctx, w.pollInterval) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (w *WebhookDispatcher) pollOnce(ctx context.Context) int {
+	msgs, err := w.q.Lease(ctx, WebhookQueue, w.batchSize, webhookLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error(err, "lease failed", "queue", WebhookQueue)
+		}
+		return 0
+	}
+	var wg sync.WaitGroup
+	for _, msg := range msgs {
+		wg.Go(func() { w.process(ctx, msg) })
+	}
+	wg.Wait()
+	return len(msgs)
+}
+
+// process delivers one webhook job and settles it, on a context detached from
+// ctx as in Dispatcher.process.
+func (w *WebhookDispatcher) process(ctx context.Context, msg statestore.LeasedMessage) {
+	job, err := DecodeWebhookJob(msg.Body)
+	if err != nil {
+		w.logger.Error(err, "webhook job will not decode; dead-lettering", "id", msg.ID)
+		w.kill(ctx, msg, ReasonUndecodable)
+		return
+	}
+	policy := resolvePolicy(job.Webhook.Policy)
+	if w.now().Sub(job.EnqueueTime) > policy.MaxAge {
+		w.kill(ctx, msg, ReasonExpired)
+		return
+	}
+
+	dctx, dcancel := context.WithTimeout(ctx, webhookTimeout)
+	res := w.deliver(dctx, job, msg.Attempts)
+	dcancel()
+	recordWebhookDelivery(ctx, deliveryCondition(res))
+
+	switch classify(res) {
+	case actionAck:
+		sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+		defer cancel()
+		w.logSettle("ack", msg.ID, w.q.Ack(sctx, msg.Receipt))
+		return
+	case actionKill:
+		w.logFailure(job, msg, res)
+		w.kill(ctx, msg, ReasonHTTP4xx)
+		return
+	}
+	w.logFailure(job, msg, res)
+	if msg.Attempts >= policy.MaxAttempts {
+		w.kill(ctx, msg, statestore.ReasonRetriesExhausted)
+		return
+	}
+	rand := w.rand
+	if policy.NoJitter {
+		rand = nil
+	}
+	delay := backoff.ExpFullJitter(policy.BackoffBase, policy.BackoffCap, msg.Attempts, rand)
+	if w.now().Add(delay).Sub(job.EnqueueTime) > policy.MaxAge {
+		w.kill(ctx, msg, ReasonExpired)
+		return
+	}
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+	w.logSettle("nack", msg.ID, w.q.Nack(sctx, msg.Receipt, delay))
+}
+
+// deliver POSTs job's body to its webhook, signed when it names a signing
+// secret.
+func (w *WebhookDispatcher) deliver(ctx context.Context, job WebhookJob, attempt int) DeliveryResult {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.Webhook.URL, bytes.NewReader(job.Body))
+	if err != nil {
+		return DeliveryResult{Err: err}
+	}
+	for k, v := range job.Webhook.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range job.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(HeaderInvocationID, job.InvocationID)
+	req.Header.Set(HeaderInvocationAttempt, strconv.Itoa(attempt))
+	if job.Webhook.SecretName != "" {
+		if w.signingKey == nil {
+			return DeliveryResult{Err: errors.New("asyncinvoke: no signing key source configured")}
+		}
+		key, err := w.signingKey(ctx, job.Namespace, job.Webhook.SecretName, job.Webhook.SecretKey)
+		if err != nil {
+			return DeliveryResult{Err: fmt.Errorf("reading webhook signing key: %w", err)}
+		}
+		req.Header.Set(HeaderSignature, SignWebhook(key, w.now(), job.Body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return DeliveryResult{Err: err}
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxResponseBytes))
+	_ = resp.Body.Close()
+	return DeliveryResult{StatusCode: resp.StatusCode}
+}
+
+// SignWebhook returns the HeaderSignature value for body signed with key at
+// t. The timestamp is inside the MAC, so a receiver that rejects old
+// timestamps also rejects a replayed delivery.
+func SignWebhook(key []byte, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *WebhookDispatcher) kill(ctx context.Context, msg statestore.LeasedMessage, reason string) {
+	sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+	if err := w.q.Kill(sctx, msg.Receipt, reason); err != nil {
+		w.logSettle("kill", msg.ID, err)
+		return
+	}
+	recordWebhookDLQ(sctx, reason)
+}
+
+func (w *WebhookDispatcher) logFailure(job WebhookJob, msg statestore.LeasedMessage, res DeliveryResult) {
+	w.logger.V(1).Info("async webhook delivery failed",
+		"id", msg.ID, "namespace", job.Namespace, "function", job.Function, "destination", job.Destination,
+		"attempt", msg.Attempts, "statusCode", res.StatusCode, "err", res.Err)
+}
+
+func (w *WebhookDispatcher) logSettle(op, id string, err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, statestore.ErrInvalidReceipt) {
+		w.logger.V(1).Info("settle raced a newer lease (expected)", "op", op, "id", id)
+		return
+	}
+	w.logger.Error(err, op+" failed", "id", id)
+}
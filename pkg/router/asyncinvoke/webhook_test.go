@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package asyncinvoke
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func webhookDispatcher(q statestore.Queue, now time.Time, key SigningKeyFunc) *WebhookDispatcher {
+	return NewWebhookDispatcher(WebhookOptions{
+		Queue: q, Logger: logr.Discard(), SigningKey: key,
+		// httptest servers listen on loopback, which the default transport refuses.
+		Transport: http.DefaultTransport,
+		Now:       func() time.Time { return now },
+		Rand:      func() float64 { return 0 },
+	})
+}
+
+func enqueueWebhookJob(t *testing.T, q statestore.Queue, d *Dispatcher, url string, policy Policy) {
+	t.Helper()
+	result := ResultEnvelope{
+		Version:        EnvelopeVersion,
+		RequestContext: RequestContext{InvocationID: "id-1", FunctionRef: "ns/src", Condition: ConditionRetriesExhausted, Attempts: 3},
+	}
+	d.fireDestination(t.Context(), &Destination{
+		FunctionNamespace: "ns",
+		Webhook:           &Webhook{URL: url, Headers: map[string]string{"X-Team": "payments"}, SecretName: "hook", SecretKey: "hmac", Policy: policy},
+	}, 0, result)
+}
+
+func leaseWebhook(t *testing.T, q statestore.Queue) statestore.LeasedMessage {
+	t.Helper()
+	msgs, err := q.Lease(t.Context(), WebhookQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	return msgs[0]
+}
+
+// TestFireDestinationWebhook: a webhook destination is queued on WebhookQueue
+// with the source's identity — a leaf, enqueued once however often the settle
+// fires.
+func TestFireDestinationWebhook(t *testing.T) {
+	t.Parallel()
+	q := memQueue(t)
+	now := time.Unix(1000, 0).UTC()
+	d := destDispatcher(q, scriptedDeliverer{}, now, resolverFor(FunctionConfig{}))
+
+	enqueueWebhookJob(t, q, d, "https://hooks.example.com/x", Policy{})
+	enqueueWebhookJob(t, q, d, "https://hooks.example.com/x", Policy{})
+
+	st, err := q.Stats(t.Context(), WebhookQueue)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), st.Visible, "the enqueue is deduplicated per invocation and destination")
+	job, err := DecodeWebhookJob(leaseWebhook(t, q).Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ns", job.Namespace)
+	assert.Equal(t, "src", job.Function)
+	assert.Equal(t, "onFailure", job.Destination)
+	assert.Equal(t, "id-1", job.InvocationID)
+	assert.Equal(t, "https://hooks.example.com/x", job.Webhook.URL)
+	assert.Equal(t, "application/json", job.Headers["Content-Type"])
+	assert.Equal(t, now, job.EnqueueTime)
+
+	l, err := q.Lease(t.Context(), DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, l, "a webhook is a leaf: nothing continues on the async queue")
+}
+
+// TestWebhookDeliverySigned: a delivery POSTs the result envelope with the
+// custom headers, the source invocation id, and a signature the receiver can
+// verify with the shared key.
+func TestWebhookDeliverySigned(t *testing.T) {
+	t.Parallel()
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	q := memQueue(t)
+	now := time.Unix(2000, 0).UTC()
+	enqueueWebhookJob(t, q, destDispatcher(q, scriptedDeliverer{}, now, resolverFor(FunctionConfig{})), srv.URL, Policy{})
+	var keyRef string
+	w := webhookDispatcher(q, now, func(_ context.Context, namespace, name, key string) ([]byte, error) {
+		keyRef = namespace + "/" + name + "/" + key
+		return []byte("s3cret"), nil
+	})
+	w.process(t.Context(), leaseWebhook(t, q))
+
+	require.NotNil(t, got)
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "ns/hook/hmac", keyRef)
+	assert.Equal(t, "payments", got.Header.Get("X-Team"))
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "id-1", got.Header.Get(HeaderInvocationID))
+	assert.Equal(t, "1", got.Header.Get(HeaderInvocationAttempt))
+	assert.Equal(t, SignWebhook([]byte("s3cret"), now, body), got.Header.Get(HeaderSignature))
+	assert.True(t, strings.HasPrefix(got.Header.Get(HeaderSignature), "t=2000,v1="))
+	assert.Contains(t, string(body), `"invocationId":"id-1"`)
+
+	st, err := q.Stats(t.Context(), WebhookQueue)
+	require.NoError(t, err)
+	assert.Zero(t, st.Visible+st.Leased+st.Dead, "a 2xx acks")
+}
+
+// TestWebhookDeliveryFailures: a permanent 4xx dead-letters at once, with the
+// job still naming its destination; an unreadable signing key retries until the
+// last attempt dead-letters.
+func TestWebhookDeliveryFailures(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+	now := time.Unix(3000, 0).UTC()
+	policy := Policy{MaxAttempts: 2, NoJitter: true, BackoffBase: time.Millisecond, BackoffCap: time.Millisecond}
+
+	t.Run("4xx", func(t *testing.T) {
+		q := memQueue(t)
+		enqueueWebhookJob(t, q, destDispatcher(q, scriptedDeliverer{}, now, resolverFor(FunctionConfig{})), srv.URL, policy)
+		webhookDispatcher(q, now, func(context.Context, string, string, string) ([]byte, error) {
+			return []byte("k"), nil
+		}).process(t.Context(), leaseWebhook(t, q))
+
+		dead, err := q.DeadLetters(t.Context(), WebhookQueue, statestore.Page{})
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, ReasonHTTP4xx, dead[0].Reason)
+		job, err := DecodeWebhookJob(dead[0].Body)
+		require.NoError(t, err)
+		assert.Equal(t, "onFailure", job.Destination)
+		assert.Equal(t, srv.URL, job.Webhook.URL)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		q := memQueue(t)
+		enqueueWebhookJob(t, q, destDispatcher(q, scriptedDeliverer{}, now, resolverFor(FunctionConfig{})), srv.URL, policy)
+		w := webhookDispatcher(q, now, func(context.Context, string, string, string) ([]byte, error) {
+			return nil, errors.New("secret not found")
+		})
+		w.process(t.Context(), leaseWebhook(t, q))
+		st, err := q.Stats(t.Context(), WebhookQueue)
+		require.NoError(t, err)
+		assert.Zero(t, st.Dead, "an unreadable signing key is retried")
+
+		time.Sleep(5 * time.Millisecond)
+		w.process(t.Context(), leaseWebhook(t, q))
+		dead, err := q.DeadLetters(t.Context(), WebhookQueue, statestore.Page{})
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, statestore.ReasonRetriesExhausted, dead[0].Reason)
+	})
+}
+
+// TestWebhookTransportRefusesInternal: the default transport will not dial
+// loopback, the link-local metadata address, or a private address outside
+// the allowed networks.
+func TestWebhookTransportRefusesInternal(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	t.Cleanup(srv.Close)
+	client := &http.Client{Transport: publicTransport(nil), Timeout: 5 * time.Second}
+	for _, url := range []string{srv.URL, "http://169.254.169.254/latest/meta-data", "http://10.96.0.1/", "http://[fd00::1]/"} {
+		resp, err := client.Get(url)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		require.Error(t, err, url)
+		assert.Contains(t, err.Error(), "is not allowed", url)
+	}
+}
+
+// TestWebhookTransportIgnoresProxy: with a proxy in the environment, a
+// webhook to an internal address is still refused, rather than handed to a
+// proxy the dialer would check instead.
+func TestWebhookTransportIgnoresProxy(t *testing.T) {
+	var proxied atomic.Bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		proxied.Store(true)
+	}))
+	t.Cleanup(proxy.Close)
+	t.Setenv("HTTP_PROXY", proxy.URL)
+	t.Setenv("HTTPS_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "")
+
+	// The proxy itself would pass the dial check.
+	tr := publicTransport([]netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")})
+	assert.Nil(t, tr.(*http.Transport).Proxy)
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+	resp, err := client.Get("http://10.96.0.1/")
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not allowed")
+	assert.False(t, proxied.Load(), "the request must not reach the proxy")
+}
+
+func TestWebhookAddrAllowed(t *testing.T) {
+	t.Parallel()
+	allowed, err := ParseWebhookNetworks("10.20.0.0/16, fd12::/16")
+	require.NoError(t, err)
+	for addr, want := range map[string]bool{
+		"203.0.113.7":        true,
+		"2001:db8::1":        true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"169.254.169.254":    false,
+		"fe80::1":            false,
+		"0.0.0.0":            false,
+		"::":                 false,
+		"10.96.0.1":          false, // RFC 1918, e.g. the Service CIDR
+		"172.16.5.4":         false,
+		"192.168.1.1":        false,
+		"fd00::1":            false, // ULA
+		"100.64.0.1":         false, // CGNAT
+		"100.127.255.254":    false,
+		"::ffff:192.168.1.1": false, // IPv4-mapped
+		"100.128.0.1":        true,
+		"10.20.3.4":          true, // allowed network
+		"fd12::8":            true,
+		"::ffff:10.20.3.4":   true,
+		"fd13::8":            false,
+		"10.21.0.1":          false,
+	} {
+		assert.Equal(t, want, webhookAddrAllowed(netip.MustParseAddr(addr), allowed), addr)
+	}
+	// The allowed networks never open loopback or link-local.
+	wide, err := ParseWebhookNetworks("0.0.0.0/0,::/0")
+	require.NoError(t, err)
+	assert.False(t, webhookAddrAllowed(netip.MustParseAddr("127.0.0.1"), wide))
+	assert.False(t, webhookAddrAllowed(netip.MustParseAddr("169.254.169.254"), wide))
+
+	_, err = ParseWebhookNetworks("10.0.0.0/8,10.0.0.1")
+	assert.Error(t, err, "a bare address is not a network")
+}
@@ -5,6 +5,7 @@
 package router
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
 	"errors"
@@ -12,12 +13,16 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/golang-jwt/jwt/v4"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
 	config "github.com/fission/fission/pkg/featureconfig"
 )
 
@@ -27,65 +32,294 @@ var (
 	errInvalidCreds   = errors.New("unauthorized: invalid username or password")
 )
 
-func checkAuthToken(r *http.Request) error {
-	authHeader := strings.Split(r.Header.Get("Authorization"), "Bearer ")
-	if len(authHeader) != 2 || len(authHeader[1]) == 0 {
-		// malformed token
-		return errMalformedToken
+// authenticator verifies the bearer token on public-listener requests: an
+// HS256 token issued by the router's own login endpoint, or, when OIDC is
+// configured, an RS256/ES256 token from the external issuer. It is built once
+// per auth config (see HTTPTriggerSet.authenticatorFor) so the OIDC key cache
+// survives mux rebuilds.
+type authenticator struct {
+	cfg config.AuthFeatureConfig
+	// signingKey verifies login tokens; empty disables them.
+	signingKey []byte
+	oidc       *oidcVerifier
+	// claimHeaders maps each forwarded claim to its request header.
+	claimHeaders map[string]string
+}
+
+func newAuthenticator(logger logr.Logger, cfg config.AuthFeatureConfig) *authenticator {
+	a := &authenticator{
+		cfg:          cfg,
+		signingKey:   []byte(os.Getenv("JWT_SIGNING_KEY")),
+		claimHeaders: make(map[string]string, len(cfg.ForwardClaims)),
+	}
+	if cfg.OIDC != nil && cfg.OIDC.IssuerURL != "" {
+		a.oidc = newOIDCVerifier(logger.WithName("oidc"), cfg.OIDC)
 	}
+	for _, claim := range cfg.ForwardClaims {
+		a.claimHeaders[claim] = claimHeader(claim)
+	}
+	return a
+}
 
-	jwtToken := authHeader[1]
-	token, err := jwt.Parse(jwtToken, func(token *jwt.Token) (any, error) {
-		return []byte(os.Getenv("JWT_SIGNING_KEY")), nil
-	})
+// authenticate verifies r's bearer token and returns its claims. A token is
+// routed by its alg header: HMAC tokens are the router's own, anything else
+// goes to the OIDC issuer. Each verifier pins its own algorithms, so a token
+// cannot pick which key material checks it.
+func (a *authenticator) authenticate(r *http.Request) (jwt.MapClaims, error) {
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		return nil, errMalformedToken
+	}
+	var (
+		claims jwt.MapClaims
+		err    error
+	)
+	if a.oidc != nil && !isHMACToken(raw) {
+		claims, err = a.oidc.verify(r.Context(), raw)
+	} else {
+		claims, err = a.verifyLoginToken(raw)
+	}
+	if err != nil {
+		return nil, tokenError(err)
+	}
+	return claims, nil
+}
 
-	if token != nil && token.Valid {
-		// valid token
-		return nil
+func (a *authenticator) verifyLoginToken(raw string) (jwt.MapClaims, error) {
+	if len(a.signingKey) == 0 {
+		return nil, errors.New("unauthorized: router login tokens are not enabled")
 	}
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if _, err := parser.ParseWithClaims(raw, claims, func(*jwt.Token) (any, error) {
+		return a.signingKey, nil
+	}); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
 
+func isHMACToken(raw string) bool {
+	token, _, err := jwt.NewParser().ParseUnverified(raw, jwt.MapClaims{})
+	if err != nil {
+		return false
+	}
+	_, ok := token.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// tokenError maps a verification failure onto the 401 body callers see.
+func tokenError(err error) error {
 	if ve, ok := err.(*jwt.ValidationError); ok {
-		if ve.Errors&jwt.ValidationErrorMalformed != 0 {
-			// malformed token
-			err = errMalformedToken
-		} else if ve.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0 {
-			// token is either expired or not active yet
-			err = errExpiredToken
-		} else {
-			err = fmt.Errorf("unauthorized: %w", err)
+		switch {
+		case ve.Errors&jwt.ValidationErrorMalformed != 0:
+			return errMalformedToken
+		case ve.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0:
+			return errExpiredToken
+		default:
+			return fmt.Errorf("unauthorized: %w", err)
 		}
 	}
+	return err
+}
 
-	if err == nil {
-		err = errors.New("unauthorized: invalid token")
+// forwardClaims replaces any X-Fission-Claim-* headers the caller sent with
+// the configured claims of its verified token, so a function can trust them.
+func (a *authenticator) forwardClaims(r *http.Request, claims jwt.MapClaims) {
+	stripClaimHeaders(r.Header)
+	for claim, header := range a.claimHeaders {
+		if v, ok := claims[claim]; ok {
+			r.Header.Set(header, claimString(v))
+		}
 	}
+}
 
-	return err
+// headerFissionClaimPrefix prefixes the headers carrying forwarded claims.
+const headerFissionClaimPrefix = "X-Fission-Claim-"
+
+// stripClaimHeaders drops every X-Fission-Claim-* header from h.
+func stripClaimHeaders(h http.Header) {
+	for name := range h {
+		if strings.HasPrefix(name, headerFissionClaimPrefix) {
+			h.Del(name)
+		}
+	}
+}
+
+// stripClaimsMiddleware drops caller-sent X-Fission-Claim-* headers on the
+// public listener whether or not authentication is enabled, so a function
+// only ever sees claim headers the router set from a verified token.
+func stripClaimsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stripClaimHeaders(r.Header)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// claimHeader names the header a claim is forwarded in. Claim names may be
+// URLs (namespaced custom claims), so characters a header name cannot carry
+// become '-'.
+func claimHeader(claim string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, claim)
+	return http.CanonicalHeaderKey(headerFissionClaimPrefix + name)
+}
+
+// claimString renders a claim value as a header value: strings as they are,
+// a list of strings comma-joined, anything else as JSON.
+func claimString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				b, _ := json.Marshal(v)
+				return string(b)
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ",")
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+type authClaimsKey struct{}
+
+func withAuthClaims(ctx context.Context, claims jwt.MapClaims) context.Context {
+	return context.WithValue(ctx, authClaimsKey{}, claims)
+}
+
+// authClaims returns the verified token claims the auth middleware attached
+// to r, if any.
+func authClaims(r *http.Request) (jwt.MapClaims, bool) {
+	claims, ok := r.Context().Value(authClaimsKey{}).(jwt.MapClaims)
+	return claims, ok
 }
 
 // authMiddleware gates the public listener on a valid JWT. It runs as an
 // httpmux middleware, i.e. BEFORE route matching, so an unauthenticated request
 // to an unknown path returns 401 rather than 404 (it does not reveal which
 // paths exist). The router-owned probe/login endpoints are exempted by path.
-func authMiddleware(featureConfig *config.FeatureConfig) func(http.Handler) http.Handler {
+// The verified claims ride on the request context for per-trigger
+// authorization.
+func authMiddleware(a *authenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Exempt the router-owned probe endpoints: kubelet liveness
 			// (/router-healthz) and readiness (/readyz) probes are
 			// unauthenticated, so requiring a token here would keep the pod
 			// permanently NotReady when auth is enabled.
-			if r.URL.Path != featureConfig.AuthConfig.AuthUriPath && r.URL.Path != "/router-healthz" && r.URL.Path != "/readyz" {
-				err := checkAuthToken(r)
+			if r.URL.Path != a.cfg.AuthUriPath && r.URL.Path != "/router-healthz" && r.URL.Path != "/readyz" {
+				claims, err := a.authenticate(r)
 				if err != nil {
+					w.Header().Set("WWW-Authenticate", "Bearer")
 					http.Error(w, err.Error(), http.StatusUnauthorized)
 					return
 				}
+				a.forwardClaims(r, claims)
+				r = r.WithContext(withAuthClaims(r.Context(), claims))
 			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// authenticatorFor returns the trigger set's authenticator for cfg, building
+// a new one only when the feature config changed since the last mux build.
+func (ts *HTTPTriggerSet) authenticatorFor(cfg config.AuthFeatureConfig) *authenticator {
+	ts.authMu.Lock()
+	defer ts.authMu.Unlock()
+	if ts.auth == nil || !reflect.DeepEqual(ts.auth.cfg, cfg) {
+		ts.auth = newAuthenticator(ts.logger.WithName("auth"), cfg)
+	}
+	return ts.auth
+}
+
+var (
+	errAuthenticationRequired = errors.New("unauthorized: this trigger requires an authenticated caller")
+	errNotAuthorized          = errors.New("forbidden: token does not grant access to this trigger")
+)
+
+// admitAuthorized checks the caller's verified claims against the trigger's
+// Authorization, answering 401 when there are none (authentication is
+// disabled) and 403 when they fall short.
+func (fh functionHandler) admitAuthorized(rw http.ResponseWriter, req *http.Request) bool {
+	claims, ok := authClaims(req)
+	if !ok {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		fh.writeInvocationError(rw, req, http.StatusUnauthorized, ferror.ComponentRouter, ferror.ReasonUnauthenticated, errAuthenticationRequired.Error(), errAuthenticationRequired)
+		return false
+	}
+	if !authorized(fh.httpTrigger.Spec.Authorization, claims) {
+		fh.writeInvocationError(rw, req, http.StatusForbidden, ferror.ComponentRouter, ferror.ReasonForbidden, errNotAuthorized.Error(), errNotAuthorized)
+		return false
+	}
+	return true
+}
+
+// authorized reports whether claims satisfy every scope and claim
+// requirement of az.
+func authorized(az *fv1.HTTPTriggerAuthorization, claims jwt.MapClaims) bool {
+	if len(az.Scopes) > 0 {
+		granted := tokenScopes(claims)
+		for _, scope := range az.Scopes {
+			if !slices.Contains(granted, scope) {
+				return false
+			}
+		}
+	}
+	for _, req := range az.Claims {
+		v, ok := claims[req.Name]
+		if !ok {
+			return false
+		}
+		if len(req.Values) > 0 && !claimMatches(v, req.Values) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenScopes reads the OAuth 2.0 "scope" claim (space-separated), falling
+// back to the "scp" list some issuers use instead.
+func tokenScopes(claims jwt.MapClaims) []string {
+	if s, ok := claims["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []any:
+		scopes := make([]string, 0, len(scp))
+		for _, e := range scp {
+			if s, ok := e.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+// claimMatches reports whether v equals one of values or, for a list claim,
+// contains one. Non-string values compare by their JSON rendering, so a
+// boolean claim matches "true".
+func claimMatches(v any, values []string) bool {
+	if list, ok := v.([]any); ok {
+		return slices.ContainsFunc(list, func(e any) bool { return claimMatches(e, values) })
+	}
+	return slices.Contains(values, claimString(v))
+}
+
 type AuthConf struct {
 	username      string
 	password      string
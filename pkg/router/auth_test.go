@@ -16,6 +16,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
 	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/util/wait"
 
@@ -44,7 +45,7 @@ func GetRouterWithAuth() http.Handler {
 	// Mirror the public listener: auth runs as a pre-match middleware, metrics
 	// per route. The auth middleware exempts the login + healthz paths by path.
 	m := httpmux.New(
-		httpmux.WithMiddleware(authMiddleware(&featureConfig)),
+		httpmux.WithMiddleware(authMiddleware(newAuthenticator(logr.Discard(), featureConfig.AuthConfig))),
 		httpmux.WithMetrics(metrics.HTTPRecorder{}),
 	)
 	m.HandleFunc("/auth/login", authLoginHandler(&featureConfig)).Methods("POST")
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-logr/logr"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/crd"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/router/circuitbreaker"
+	"github.com/fission/fission/pkg/router/endpointcache"
+	"github.com/fission/fission/pkg/utils/httpmux"
+	otelUtils "github.com/fission/fission/pkg/utils/otel"
+)
+
+var errCircuitOpen = errors.New("function circuit breaker is open")
+
+// newCircuitBreakers returns the router's breaker registry, logging and
+// counting every state change.
+func newCircuitBreakers(logger logr.Logger) *circuitbreaker.Registry {
+	return circuitbreaker.NewRegistry(func(key circuitbreaker.Key, tr circuitbreaker.Transition) {
+		logger.Info("circuit breaker state changed", "namespace", key.Namespace, "function", key.Function,
+			"version", key.Version, "from", tr.From, "to", tr.To)
+		recordCircuitTransition(context.Background(), key, tr)
+	})
+}
+
+// breakerKey names fn's breaker: a published version's breaker is its own,
+// apart from the live function's.
+func breakerKey(fn *fv1.Function) circuitbreaker.Key {
+	return circuitbreaker.Key{Namespace: fn.Namespace, Function: fn.Name, Version: backendVersion(fn)}
+}
+
+// precomputeBreakers looks up the breakers of the backend functions with a
+// CircuitBreaker, keyed like policyByUID. It returns nil when none has one.
+func precomputeBreakers(reg *circuitbreaker.Registry, fns map[string]*fv1.Function) map[crd.CacheKeyUG]*circuitbreaker.Breaker {
+	if reg == nil {
+		return nil
+	}
+	var breakers map[crd.CacheKeyUG]*circuitbreaker.Breaker
+	for _, fn := range fns {
+		if fn == nil || fn.Spec.CircuitBreaker == nil {
+			continue
+		}
+		if breakers == nil {
+			breakers = make(map[crd.CacheKeyUG]*circuitbreaker.Breaker, len(fns))
+		}
+		breakers[crd.CacheKeyUGFromMeta(&fn.ObjectMeta)] = reg.Get(breakerKey(fn), circuitbreaker.ConfigFor(fn.Spec.CircuitBreaker))
+	}
+	return breakers
+}
+
+// admitCircuit lets req through b, or answers it 503 with a Retry-After
+// while b is open.
+func (fh functionHandler) admitCircuit(rw http.ResponseWriter, req *http.Request, b *circuitbreaker.Breaker) (circuitbreaker.Ticket, bool) {
+	t, retryAfter, ok := b.Allow()
+	if ok {
+		return t, true
+	}
+	ctx := req.Context()
+	recordCircuitRejected(ctx, fh.function)
+	seconds := strconv.FormatInt(max(int64(math.Ceil(retryAfter.Seconds())), 1), 10)
+	if otelUtils.SpanIsRecording(ctx) {
+		otelUtils.SpanTrackEvent(ctx, "circuitBreakerOpen", append(otelUtils.GetAttributesForFunction(fh.function),
+			otelUtils.MapToAttributes(map[string]string{"retry_after": seconds})...)...)
+	}
+	rw.Header().Set("Retry-After", seconds)
+	fh.writeInvocationError(rw, req, http.StatusServiceUnavailable, ferror.ComponentRouter, ferror.ReasonCircuitOpen, errCircuitOpen.Error(), errCircuitOpen)
+	return t, false
+}
+
+// circuitDone records a request's outcome on b, tracing the state change it
+// caused.
+func (fh functionHandler) circuitDone(ctx context.Context, b *circuitbreaker.Breaker, t circuitbreaker.Ticket, o circuitbreaker.Outcome) {
+	tr := b.Done(t, o)
+	if tr != nil && otelUtils.SpanIsRecording(ctx) {
+		otelUtils.SpanTrackEvent(ctx, "circuitBreakerTransition", append(otelUtils.GetAttributesForFunction(fh.function),
+			otelUtils.MapToAttributes(map[string]string{"from": string(tr.From), "to": string(tr.To)})...)...)
+	}
+}
+
+// circuitOutcomeForStatus classifies a function response: only a 5xx counts
+// against the function.
+func circuitOutcomeForStatus(status int) circuitbreaker.Outcome {
+	if status >= http.StatusInternalServerError {
+		return circuitbreaker.Failure
+	}
+	return circuitbreaker.Success
+}
+
+// circuitOutcomeForError classifies a proxy error. A client that went away
+// and a function at its concurrency cap say nothing about the function's
+// health; everything else, timeouts included, is a failure.
+func circuitOutcomeForError(err error) circuitbreaker.Outcome {
+	var invErr *ferror.InvocationError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return circuitbreaker.Ignored
+	case errors.As(err, &invErr) && invErr.Reason == ferror.ReasonCapacityExceeded:
+		return circuitbreaker.Ignored
+	default:
+		return circuitbreaker.Failure
+	}
+}
+
+// Circuit debug API: the circuit breakers this replica holds and the
+// endpoints its index has taken out of rotation, dial quarantines and
+// outlier ejections alike. INTERNAL listener only; both are per replica.
+// ?namespace narrows the listing to one namespace.
+const circuitDebugPath = "/v1/debug/circuits"
+
+func (ts *HTTPTriggerSet) registerCircuitDebugRoutes(internal *httpmux.Mux) {
+	internal.HandleFunc(circuitDebugPath, ts.circuitDebug).Methods(http.MethodGet)
+}
+
+// circuitDebugResponse is the debug API's response. Quarantines is empty
+// when the router does not run the endpoint index.
+type circuitDebugResponse struct {
+	Breakers    []circuitbreaker.Status          `json:"breakers"`
+	Quarantines []endpointcache.QuarantineStatus `json:"quarantines"`
+}
+
+func (ts *HTTPTriggerSet) circuitDebug(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	resp := circuitDebugResponse{
+		Breakers:    []circuitbreaker.Status{},
+		Quarantines: []endpointcache.QuarantineStatus{},
+	}
+	if ts.circuitBreakers != nil {
+		for _, st := range ts.circuitBreakers.Statuses() {
+			if namespace == "" || st.Namespace == namespace {
+				resp.Breakers = append(resp.Breakers, st)
+			}
+		}
+	}
+	if f, ok := ts.addressResolver.(*fallbackResolver); ok {
+		for _, q := range f.index.Quarantines() {
+			if namespace == "" || q.Namespace == namespace {
+				resp.Quarantines = append(resp.Quarantines, q)
+			}
+		}
+	}
+	dlqWriteJSON(w, ts, resp)
+}
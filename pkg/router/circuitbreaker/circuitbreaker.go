@@ -0,0 +1,308 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package circuitbreaker keeps the router's per-function circuit breakers
+// (fv1.CircuitBreakerConfig). A breaker counts its function's request
+// outcomes in a sliding window and a consecutive-failure run, opens when
+// either crosses its threshold, and after the open period lets a few probe
+// requests through to decide whether to close again. State is per router
+// replica: a breaker only sees the requests its replica proxied.
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+)
+
+const (
+	defaultMinimumRequests  = 20
+	defaultWindow           = 10 * time.Second
+	defaultOpenDuration     = 30 * time.Second
+	defaultHalfOpenRequests = 1
+
+	// windowBuckets is how many slices the failure-rate window is kept in;
+	// the window slides one slice at a time.
+	windowBuckets = 10
+	// probeRetryAfter is the Retry-After of a request turned away while the
+	// half-open probes are out: their outcome is due within a request's
+	// time, not an open period's.
+	probeRetryAfter = time.Second
+)
+
+// State is a breaker's state.
+type State string
+
+const (
+	// Closed lets every request through.
+	Closed State = "closed"
+	// Open rejects every request until the open period ends.
+	Open State = "open"
+	// HalfOpen lets a bounded number of probe requests through.
+	HalfOpen State = "half_open"
+)
+
+// Outcome is how a request a breaker let through ended.
+type Outcome int
+
+const (
+	// Success is a response below 500.
+	Success Outcome = iota
+	// Failure is a 5xx response or no response at all.
+	Failure
+	// Ignored says nothing about the function's health, e.g. the client
+	// went away; it only returns a probe's slot.
+	Ignored
+)
+
+// Config is a breaker's thresholds.
+type Config struct {
+	// ConsecutiveFailures opens the breaker after that many failures in a
+	// row; 0 disables the check.
+	ConsecutiveFailures int
+	// FailureRatePercent opens the breaker when at least that share of the
+	// requests in Window failed, once Window holds MinimumRequests; 0
+	// disables the check.
+	FailureRatePercent int
+	MinimumRequests    int
+	Window             time.Duration
+	OpenDuration       time.Duration
+	HalfOpenRequests   int
+}
+
+// ConfigFor converts a CircuitBreakerConfig, applying its defaults.
+func ConfigFor(c *fv1.CircuitBreakerConfig) Config {
+	cfg := Config{
+		ConsecutiveFailures: int(c.ConsecutiveFailures),
+		FailureRatePercent:  int(c.FailureRatePercent),
+		MinimumRequests:     int(c.MinimumRequests),
+		Window:              defaultWindow,
+		OpenDuration:        defaultOpenDuration,
+		HalfOpenRequests:    int(c.HalfOpenRequests),
+	}
+	if cfg.MinimumRequests <= 0 {
+		cfg.MinimumRequests = defaultMinimumRequests
+	}
+	if c.Window != nil && c.Window.Duration > 0 {
+		cfg.Window = c.Window.Duration
+	}
+	if c.OpenDuration != nil && c.OpenDuration.Duration > 0 {
+		cfg.OpenDuration = c.OpenDuration.Duration
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = defaultHalfOpenRequests
+	}
+	return cfg
+}
+
+// Transition is a change of a breaker's state.
+type Transition struct {
+	From, To State
+}
+
+// Ticket is a request let through by Allow, to be handed back to Done.
+type Ticket struct {
+	// epoch is the breaker's epoch when the request was let through: the
+	// outcome of a request admitted before a state change says nothing
+	// about the new state.
+	epoch uint64
+	probe bool
+}
+
+// bucket is one slice of the failure-rate window.
+type bucket struct {
+	slot          int64
+	total, failed int
+}
+
+// Breaker is one function's circuit breaker. Its methods are safe for
+// concurrent use.
+type Breaker struct {
+	key      Key
+	now      func() time.Time
+	registry *Registry
+
+	mu          sync.Mutex
+	cfg         Config
+	state       State
+	epoch       uint64
+	consecutive int
+	buckets     [windowBuckets]bucket
+	// openUntil is when an open breaker turns half-open.
+	openUntil time.Time
+	// changed is when the breaker last changed state.
+	changed time.Time
+	// probes counts the half-open probes out; probeSuccesses those that
+	// came back successful.
+	probes, probeSuccesses int
+	lastUsed               time.Time
+}
+
+// Allow reports whether a request may go to the function. When it may not,
+// retryAfter is when the caller should try again.
+func (b *Breaker) Allow() (t Ticket, retryAfter time.Duration, ok bool) {
+	now := b.now()
+	b.mu.Lock()
+	b.lastUsed = now
+	var tr *Transition
+	if b.state == Open {
+		if now.Before(b.openUntil) {
+			retryAfter = b.openUntil.Sub(now)
+			b.mu.Unlock()
+			return Ticket{}, retryAfter, false
+		}
+		tr = b.setLocked(HalfOpen, now)
+	}
+	switch {
+	case b.state == Closed:
+		t, ok = Ticket{epoch: b.epoch}, true
+	case b.probes < b.cfg.HalfOpenRequests:
+		b.probes++
+		t, ok = Ticket{epoch: b.epoch, probe: true}, true
+	default:
+		retryAfter = probeRetryAfter
+	}
+	b.mu.Unlock()
+	b.notify(tr)
+	return t, retryAfter, ok
+}
+
+// Done records the outcome of a request Allow let through, and returns the
+// state change it caused, if any.
+func (b *Breaker) Done(t Ticket, o Outcome) *Transition {
+	now := b.now()
+	b.mu.Lock()
+	if t.epoch != b.epoch {
+		b.mu.Unlock()
+		return nil
+	}
+	var tr *Transition
+	switch {
+	case t.probe:
+		b.probes--
+		switch o {
+		case Failure:
+			tr = b.setLocked(Open, now)
+		case Success:
+			b.probeSuccesses++
+			if b.probeSuccesses >= b.cfg.HalfOpenRequests {
+				tr = b.setLocked(Closed, now)
+			}
+		}
+	case o != Ignored:
+		total, failed := b.recordLocked(now, o == Failure)
+		if o == Failure {
+			b.consecutive++
+		} else {
+			b.consecutive = 0
+		}
+		if b.tripsLocked(total, failed) {
+			tr = b.setLocked(Open, now)
+		}
+	}
+	b.mu.Unlock()
+	b.notify(tr)
+	return tr
+}
+
+// tripsLocked reports whether the closed breaker's counts cross a
+// threshold.
+func (b *Breaker) tripsLocked(total, failed int) bool {
+	if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+		return true
+	}
+	return b.cfg.FailureRatePercent > 0 && total >= b.cfg.MinimumRequests &&
+		failed*100 >= b.cfg.FailureRatePercent*total
+}
+
+// recordLocked adds one outcome to the window and returns the window's
+// totals.
+func (b *Breaker) recordLocked(now time.Time, failed bool) (total, failures int) {
+	width := max(b.cfg.Window/windowBuckets, time.Millisecond)
+	slot := now.UnixNano() / int64(width)
+	cur := &b.buckets[slot%windowBuckets]
+	if cur.slot != slot {
+		*cur = bucket{slot: slot}
+	}
+	cur.total++
+	if failed {
+		cur.failed++
+	}
+	for _, bk := range b.buckets {
+		if bk.slot > slot-windowBuckets {
+			total += bk.total
+			failures += bk.failed
+		}
+	}
+	return total, failures
+}
+
+// setLocked moves the breaker to state, starting a new epoch with fresh
+// counts.
+func (b *Breaker) setLocked(state State, now time.Time) *Transition {
+	tr := &Transition{From: b.state, To: state}
+	b.state = state
+	b.epoch++
+	b.changed = now
+	b.consecutive = 0
+	b.buckets = [windowBuckets]bucket{}
+	b.probes, b.probeSuccesses = 0, 0
+	if state == Open {
+		b.openUntil = now.Add(b.cfg.OpenDuration)
+	}
+	return tr
+}
+
+// notify reports a state change, outside mu, to the registry.
+func (b *Breaker) notify(tr *Transition) {
+	if tr != nil {
+		b.registry.changed(b, *tr)
+	}
+}
+
+// Status is a breaker's state for display.
+type Status struct {
+	Namespace string `json:"namespace"`
+	Function  string `json:"function"`
+	Version   string `json:"version,omitempty"`
+	State     State  `json:"state"`
+	// Since is when the breaker entered State.
+	Since time.Time `json:"since"`
+	// RetryAt is when an open breaker turns half-open.
+	RetryAt             *time.Time `json:"retryAt,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	WindowRequests      int        `json:"windowRequests"`
+	WindowFailures      int        `json:"windowFailures"`
+	ProbesInFlight      int        `json:"probesInFlight,omitempty"`
+}
+
+// Status returns the breaker's current state.
+func (b *Breaker) Status() Status {
+	now := b.now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := Status{
+		Namespace:           b.key.Namespace,
+		Function:            b.key.Function,
+		Version:             b.key.Version,
+		State:               b.state,
+		Since:               b.changed,
+		ConsecutiveFailures: b.consecutive,
+		ProbesInFlight:      b.probes,
+	}
+	if b.state == Open {
+		retryAt := b.openUntil
+		st.RetryAt = &retryAt
+	}
+	width := max(b.cfg.Window/windowBuckets, time.Millisecond)
+	slot := now.UnixNano() / int64(width)
+	for _, bk := range b.buckets {
+		if bk.slot > slot-windowBuckets {
+			st.WindowRequests += bk.total
+			st.WindowFailures += bk.failed
+		}
+	}
+	return st
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time             { return c.t }
+func (c *clock) advance(d time.Duration)    { c.t = c.t.Add(d) }
+func newClock() *clock                      { return &clock{t: time.Unix(1_700_000_000, 0)} }
+func (c *clock) registry() *Registry        { return newRegistry(nil, c.now) }
+func key(fn string) Key                     { return Key{Namespace: "default", Function: fn} }
+func run(b *Breaker, o Outcome) *Transition { t, _, ok := b.Allow(); return doneIf(b, t, ok, o) }
+
+func doneIf(b *Breaker, t Ticket, ok bool, o Outcome) *Transition {
+	if !ok {
+		return nil
+	}
+	return b.Done(t, o)
+}
+
+func TestConfigFor(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, Config{ConsecutiveFailures: 5, MinimumRequests: 20, Window: 10 * time.Second, OpenDuration: 30 * time.Second, HalfOpenRequests: 1},
+		ConfigFor(&fv1.CircuitBreakerConfig{ConsecutiveFailures: 5}))
+	assert.Equal(t, Config{FailureRatePercent: 50, MinimumRequests: 4, Window: time.Minute, OpenDuration: time.Second, HalfOpenRequests: 3},
+		ConfigFor(&fv1.CircuitBreakerConfig{FailureRatePercent: 50, MinimumRequests: 4, Window: &metav1.Duration{Duration: time.Minute},
+			OpenDuration: &metav1.Duration{Duration: time.Second}, HalfOpenRequests: 3}))
+}
+
+func TestConsecutiveFailuresOpen(t *testing.T) {
+	t.Parallel()
+	c := newClock()
+	b := c.registry().Get(key("fn"), Config{ConsecutiveFailures: 3, OpenDuration: 30 * time.Second, HalfOpenRequests: 1, Window: 10 * time.Second, MinimumRequests: 1})
+
+	run(b, Failure)
+	run(b, Failure)
+	run(b, Success)
+	run(b, Failure)
+	run(b, Failure)
+	assert.Equal(t, Closed, b.Status().State, "a success breaks the run")
+	assert.Equal(t, &Transition{From: Closed, To: Open}, run(b, Failure))
+
+	_, retryAfter, ok := b.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, retryAfter)
+	st := b.Status()
+	require.NotNil(t, st.RetryAt)
+	assert.Equal(t, c.t.Add(30*time.Second), *st.RetryAt)
+}
+
+func TestFailureRateOpens(t *testing.T) {
+	t.Parallel()
+	c := newClock()
+	b := c.registry().Get(key("fn"), Config{FailureRatePercent: 50, MinimumRequests: 4, Window: 10 * time.Second, OpenDuration: time.Second, HalfOpenRequests: 1})
+
+	run(b, Failure)
+	run(b, Success)
+	run(b, Failure)
+	assert.Equal(t, Closed, b.Status().State, "below MinimumRequests the rate does not apply")
+	assert.Equal(t, &Transition{From: Closed, To: Open}, run(b, Success), "2 of 4 failed")
+
+	// Outcomes slide out of the window.
+	b = c.registry().Get(key("fn"), Config{FailureRatePercent: 50, MinimumRequests: 4, Window: 10 * time.Second, OpenDuration: time.Second, HalfOpenRequests: 1})
+	run(b, Failure)
+	run(b, Failure)
+	c.advance(11 * time.Second)
+	run(b, Success)
+	run(b, Success)
+	run(b, Failure)
+	assert.Equal(t, Closed, b.Status().State)
+	assert.Equal(t, 3, b.Status().WindowRequests)
+}
+
+func TestHalfOpenProbes(t *testing.T) {
+	t.Parallel()
+	c := newClock()
+	b := c.registry().Get(key("fn"), Config{ConsecutiveFailures: 1, OpenDuration: 5 * time.Second, HalfOpenRequests: 2, Window: 10 * time.Second, MinimumRequests: 1})
+	run(b, Failure)
+	require.Equal(t, Open, b.Status().State)
+
+	c.advance(5 * time.Second)
+	p1, _, ok := b.Allow()
+	require.True(t, ok)
+	assert.Equal(t, HalfOpen, b.Status().State)
+	p2, _, ok := b.Allow()
+	require.True(t, ok)
+	_, retryAfter, ok := b.Allow()
+	assert.False(t, ok, "only HalfOpenRequests probes at a time")
+	assert.Equal(t, probeRetryAfter, retryAfter)
+
+	assert.Nil(t, b.Done(p1, Success))
+	assert.Equal(t, &Transition{From: HalfOpen, To: Closed}, b.Done(p2, Success))
+
+	// A failed probe opens the circuit again.
+	run(b, Failure)
+	c.advance(5 * time.Second)
+	p, _, ok := b.Allow()
+	require.True(t, ok)
+	assert.Equal(t, &Transition{From: HalfOpen, To: Open}, b.Done(p, Failure))
+
+	// An ignored probe only returns its slot.
+	c.advance(5 * time.Second)
+	p, _, _ = b.Allow()
+	p2, _, _ = b.Allow()
+	assert.Nil(t, b.Done(p, Ignored))
+	_, _, ok = b.Allow()
+	assert.True(t, ok, "the ignored probe's slot is free again")
+	assert.Nil(t, b.Done(p2, Success))
+	assert.Equal(t, HalfOpen, b.Status().State)
+}
+
+func TestStaleOutcomesAreIgnored(t *testing.T) {
+	t.Parallel()
+	c := newClock()
+	b := c.registry().Get(key("fn"), Config{ConsecutiveFailures: 2, OpenDuration: time.Second, HalfOpenRequests: 1, Window: 10 * time.Second, MinimumRequests: 1})
+	slow, _, _ := b.Allow()
+	run(b, Failure)
+	run(b, Failure)
+	require.Equal(t, Open, b.Status().State)
+	c.advance(time.Second)
+	probe, _, _ := b.Allow()
+	assert.Nil(t, b.Done(slow, Failure), "a request let through while closed does not count against the probes")
+	assert.Equal(t, &Transition{From: HalfOpen, To: Closed}, b.Done(probe, Success))
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+	c := newClock()
+	var transitions []Transition
+	r := newRegistry(func(_ Key, tr Transition) { transitions = append(transitions, tr) }, c.now)
+	cfg := Config{ConsecutiveFailures: 1, OpenDuration: time.Second, HalfOpenRequests: 1, Window: 10 * time.Second, MinimumRequests: 1}
+
+	b := r.Get(key("b"), cfg)
+	run(b, Failure)
+	assert.Same(t, b, r.Get(key("b"), cfg), "a breaker outlives the handlers built around it")
+	a := r.Get(Key{Namespace: "default", Function: "a", Version: "a-v1"}, cfg)
+	statuses := r.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "a", statuses[0].Function)
+	assert.Equal(t, Open, statuses[1].State)
+	assert.Equal(t, []Transition{{From: Closed, To: Open}}, transitions)
+
+	// Idle closed breakers are forgotten; an open one is kept.
+	c.advance(idleTTL)
+	r.Get(key("c"), cfg)
+	assert.Len(t, r.Statuses(), 2)
+
+	// A swept breaker still in use is listed again once it opens.
+	run(a, Failure)
+	assert.Len(t, r.Statuses(), 3)
+}
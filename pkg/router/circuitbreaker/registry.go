@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package circuitbreaker
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// idleTTL is how long a closed breaker goes unused before the registry
+	// forgets it, which is how the breakers of deleted functions and
+	// versions go away. A closed breaker holds nothing worth keeping.
+	idleTTL = 10 * time.Minute
+	// sweepInterval is how often Get sweeps for idle breakers.
+	sweepInterval = time.Minute
+)
+
+// Key names a breaker: one per function and published version.
+type Key struct {
+	Namespace string
+	Function  string
+	// Version is the published FunctionVersion, "" for the live function.
+	Version string
+}
+
+// Registry holds the router's breakers, so a breaker's state outlives the
+// route handlers rebuilt around it.
+type Registry struct {
+	now          func() time.Time
+	onTransition func(Key, Transition)
+
+	mu        sync.Mutex
+	breakers  map[Key]*Breaker
+	lastSweep time.Time
+}
+
+// NewRegistry returns an empty registry. onTransition, when non-nil, is
+// called on every breaker's state changes.
+func NewRegistry(onTransition func(Key, Transition)) *Registry {
+	return newRegistry(onTransition, time.Now)
+}
+
+func newRegistry(onTransition func(Key, Transition), now func() time.Time) *Registry {
+	return &Registry{now: now, onTransition: onTransition, breakers: make(map[Key]*Breaker)}
+}
+
+// Get returns key's breaker with cfg as its thresholds, creating it closed
+// on first use. A breaker whose config changed keeps its state.
+func (r *Registry) Get(key Key, cfg Config) *Breaker {
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.lastSweep) >= sweepInterval {
+		r.sweepLocked(now)
+	}
+	b, ok := r.breakers[key]
+	if !ok {
+		b = &Breaker{key: key, now: r.now, registry: r, cfg: cfg, state: Closed, changed: now, lastUsed: now}
+		r.breakers[key] = b
+		return b
+	}
+	b.mu.Lock()
+	b.cfg = cfg
+	b.lastUsed = now
+	b.mu.Unlock()
+	return b
+}
+
+// changed handles b's state change. A handler built before b was swept may
+// still be using it, so a breaker leaving Closed is put back if it is
+// missing, to keep it listed.
+func (r *Registry) changed(b *Breaker, tr Transition) {
+	r.mu.Lock()
+	if _, ok := r.breakers[b.key]; !ok && tr.To != Closed {
+		r.breakers[b.key] = b
+	}
+	r.mu.Unlock()
+	if r.onTransition != nil {
+		r.onTransition(b.key, tr)
+	}
+}
+
+func (r *Registry) sweepLocked(now time.Time) {
+	r.lastSweep = now
+	for key, b := range r.breakers {
+		b.mu.Lock()
+		idle := b.state == Closed && now.Sub(b.lastUsed) >= idleTTL
+		b.mu.Unlock()
+		if idle {
+			delete(r.breakers, key)
+		}
+	}
+}
+
+// Statuses returns every breaker's state, ordered by namespace, function
+// and version.
+func (r *Registry) Statuses() []Status {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		breakers = append(breakers, b)
+	}
+	r.mu.Unlock()
+	out := make([]Status, 0, len(breakers))
+	for _, b := range breakers {
+		out = append(out, b.Status())
+	}
+	slices.SortFunc(out, func(a, b Status) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Function, b.Function), cmp.Compare(a.Version, b.Version))
+	})
+	return out
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/crd"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/router/circuitbreaker"
+)
+
+// TestFunctionHandler_CircuitBreaker: once a function's failures trip its
+// breaker, requests are answered 503 circuit_open with a Retry-After
+// without reaching the function.
+func TestFunctionHandler_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(upstream.Close)
+
+	fn := streamingFn("uid-cb", nil)
+	fn.Spec.CircuitBreaker = &fv1.CircuitBreakerConfig{ConsecutiveFailures: 2, OpenDuration: &metav1.Duration{Duration: 90 * time.Second}}
+	fh := newHandlerForUpstream(t, fn, upstream, 60)
+	fh.structuredErrors = true
+	reg := circuitbreaker.NewRegistry(nil)
+	fh.breakerByUID = precomputeBreakers(reg, map[string]*fv1.Function{fn.Name: fn})
+	require.Len(t, fh.breakerByUID, 1)
+
+	for range 2 {
+		rr := httptest.NewRecorder()
+		fh.handler(rr, httptest.NewRequest(http.MethodGet, "/fn", nil))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	}
+
+	rr := httptest.NewRecorder()
+	fh.handler(rr, httptest.NewRequest(http.MethodGet, "/fn", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "90", rr.Header().Get("Retry-After"))
+	var body ferror.InvocationError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, ferror.ComponentRouter, body.Component)
+	assert.Equal(t, ferror.ReasonCircuitOpen, body.Reason)
+	assert.EqualValues(t, 2, calls.Load(), "an open circuit keeps requests off the function")
+
+	statuses := reg.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, circuitbreaker.Open, statuses[0].State)
+}
+
+func TestCircuitOutcome(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, circuitbreaker.Success, circuitOutcomeForStatus(http.StatusNotFound))
+	assert.Equal(t, circuitbreaker.Failure, circuitOutcomeForStatus(http.StatusBadGateway))
+	assert.Equal(t, circuitbreaker.Ignored, circuitOutcomeForError(context.Canceled))
+	assert.Equal(t, circuitbreaker.Ignored, circuitOutcomeForError(
+		ferror.NewInvocationError(ferror.ComponentExecutor, ferror.ReasonCapacityExceeded, errors.New("at cap"))))
+	assert.Equal(t, circuitbreaker.Failure, circuitOutcomeForError(context.DeadlineExceeded))
+	assert.Equal(t, circuitbreaker.Failure, circuitOutcomeForError(errors.New("connection reset")))
+}
+
+func TestPrecomputeBreakers(t *testing.T) {
+	t.Parallel()
+	reg := circuitbreaker.NewRegistry(nil)
+	live := streamingFn("uid-live", nil)
+	live.Spec.CircuitBreaker = &fv1.CircuitBreakerConfig{FailureRatePercent: 50}
+	version := live.DeepCopy()
+	version.Generation = 2
+	version.Labels = map[string]string{fv1.FUNCTION_VERSION: "fn-v1"}
+	plain := streamingFn("uid-plain", nil)
+	plain.Name = "plain"
+
+	assert.Nil(t, precomputeBreakers(reg, map[string]*fv1.Function{"plain": plain}))
+	breakers := precomputeBreakers(reg, map[string]*fv1.Function{"fn": live, "fn:fn-v1": version, "plain": plain})
+	require.Len(t, breakers, 2)
+	assert.NotSame(t, breakers[crd.CacheKeyUGFromMeta(&live.ObjectMeta)], breakers[crd.CacheKeyUGFromMeta(&version.ObjectMeta)],
+		"a version's breaker is its own")
+	assert.Same(t, breakers[crd.CacheKeyUGFromMeta(&live.ObjectMeta)],
+		precomputeBreakers(reg, map[string]*fv1.Function{"fn": live})[crd.CacheKeyUGFromMeta(&live.ObjectMeta)],
+		"a rebuilt route keeps its breaker")
+}
+
+func TestCircuitDebug(t *testing.T) {
+	t.Parallel()
+	ts := &HTTPTriggerSet{logger: logr.Discard(), circuitBreakers: circuitbreaker.NewRegistry(nil)}
+	cfg := circuitbreaker.Config{ConsecutiveFailures: 1, HalfOpenRequests: 1, MinimumRequests: 1}
+	ts.circuitBreakers.Get(circuitbreaker.Key{Namespace: "a", Function: "fn"}, cfg)
+	ts.circuitBreakers.Get(circuitbreaker.Key{Namespace: "b", Function: "fn"}, cfg)
+
+	rr := httptest.NewRecorder()
+	ts.circuitDebug(rr, httptest.NewRequest(http.MethodGet, circuitDebugPath+"?namespace=b", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp circuitDebugResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp.Breakers, 1)
+	assert.Equal(t, "b", resp.Breakers[0].Namespace)
+	assert.Equal(t, circuitbreaker.Closed, resp.Breakers[0].State)
+	assert.Empty(t, resp.Quarantines)
+}
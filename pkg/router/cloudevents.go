@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+)
+
+// maxStructuredEventBytes bounds the structured-mode body the router buffers
+// to convert an event to binary mode. Binary-mode requests are streamed and
+// not subject to it.
+const maxStructuredEventBytes = 8 << 20
+
+// Span and access-log attribute keys, following the OpenTelemetry semantic
+// conventions for CloudEvents.
+const (
+	attrCloudEventID     = "cloudevents.event_id"
+	attrCloudEventSource = "cloudevents.event_source"
+	attrCloudEventType   = "cloudevents.event_type"
+)
+
+// readCloudEvent applies an HTTPTrigger's CloudEvents binding to req. A
+// binary-mode event is validated in place; a structured-mode event is
+// validated and rewritten to binary mode, so the function sees one shape
+// whichever mode the producer used. On rejection it returns the status to
+// answer with and why.
+func readCloudEvent(req *http.Request, cfg *fv1.HTTPTriggerCloudEvents) (int, error) {
+	switch cloudevents.Mode(req.Header) {
+	case "binary":
+		// The attributes are all in the headers; leave the body streaming.
+		ev, err := cloudevents.FromHTTP(req.Header, nil)
+		if err == nil {
+			err = ev.Validate()
+		}
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("invalid CloudEvent: %w", err)
+		}
+		return 0, nil
+	case "structured":
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxStructuredEventBytes+1))
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("reading CloudEvent: %w", err)
+		}
+		if len(body) > maxStructuredEventBytes {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("structured CloudEvent exceeds %d bytes", maxStructuredEventBytes)
+		}
+		ev, err := cloudevents.Unmarshal(body)
+		if err == nil {
+			err = ev.Validate()
+		}
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("invalid CloudEvent: %w", err)
+		}
+		ev.WriteBinary(req.Header)
+		req.Body = io.NopCloser(bytes.NewReader(ev.Data))
+		req.ContentLength = int64(len(ev.Data))
+		req.TransferEncoding = nil
+		return 0, nil
+	case "batch":
+		return http.StatusUnsupportedMediaType, cloudevents.ErrBatch
+	}
+	if cfg.Required {
+		return http.StatusBadRequest, cloudevents.ErrNotCloudEvent
+	}
+	return 0, nil
+}
+
+// annotateCloudEvent records a binary-mode event's identity on the request's
+// server span, so a trace can be found from the event id. It applies to every
+// route, not just opted-in triggers: message queue triggers and async
+// destinations deliver to functions in binary mode through the internal
+// listener.
+func annotateCloudEvent(req *http.Request) {
+	id := req.Header.Get(cloudevents.HeaderID)
+	if id == "" {
+		return
+	}
+	trace.SpanFromContext(req.Context()).SetAttributes(
+		attribute.String(attrCloudEventID, id),
+		attribute.String(attrCloudEventSource, req.Header.Get(cloudevents.HeaderSource)),
+		attribute.String(attrCloudEventType, req.Header.Get(cloudevents.HeaderType)),
+	)
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+func TestReadCloudEvent_StructuredToBinary(t *testing.T) {
+	t.Parallel()
+	body := `{"specversion":"1.0","id":"evt-1","source":"/orders","type":"com.example.created",` +
+		`"datacontenttype":"application/json","data":{"n":1},"tenant":"acme"}`
+	req := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader(body))
+	req.Header.Set("Content-Type", cloudevents.ContentTypeStructured)
+
+	status, err := readCloudEvent(req, &fv1.HTTPTriggerCloudEvents{})
+	require.NoError(t, err)
+	assert.Zero(t, status)
+	assert.Equal(t, "evt-1", req.Header.Get(cloudevents.HeaderID))
+	assert.Equal(t, "/orders", req.Header.Get(cloudevents.HeaderSource))
+	assert.Equal(t, "acme", req.Header.Get("Ce-Tenant"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	data, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"n":1}`, string(data), "the function gets the event data as its body")
+	assert.EqualValues(t, len(data), req.ContentLength)
+}
+
+func TestReadCloudEvent(t *testing.T) {
+	t.Parallel()
+	binary := func(h http.Header) {
+		h.Set("Ce-Specversion", "1.0")
+		h.Set("Ce-Id", "evt-1")
+		h.Set("Ce-Source", "/orders")
+		h.Set("Ce-Type", "com.example.created")
+	}
+	for name, tc := range map[string]struct {
+		header   func(http.Header)
+		body     string
+		required bool
+		status   int
+	}{
+		"binary":                 {header: binary, body: `{"n":1}`},
+		"binary without id":      {header: func(h http.Header) { binary(h); h.Del("Ce-Id") }, status: http.StatusBadRequest},
+		"binary wrong version":   {header: func(h http.Header) { binary(h); h.Set("Ce-Specversion", "0.3") }, status: http.StatusBadRequest},
+		"structured malformed":   {header: func(h http.Header) { h.Set("Content-Type", cloudevents.ContentTypeStructured) }, body: `{`, status: http.StatusBadRequest},
+		"structured incomplete":  {header: func(h http.Header) { h.Set("Content-Type", cloudevents.ContentTypeStructured) }, body: `{"specversion":"1.0"}`, status: http.StatusBadRequest},
+		"batch":                  {header: func(h http.Header) { h.Set("Content-Type", cloudevents.ContentTypeBatch) }, body: `[]`, status: http.StatusUnsupportedMediaType},
+		"plain request":          {header: func(h http.Header) { h.Set("Content-Type", "application/json") }, body: `{}`},
+		"plain request required": {header: func(h http.Header) { h.Set("Content-Type", "application/json") }, body: `{}`, required: true, status: http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader(tc.body))
+			tc.header(req.Header)
+			status, err := readCloudEvent(req, &fv1.HTTPTriggerCloudEvents{Required: tc.required})
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.status != 0, err != nil, "%v", err)
+			if tc.status == 0 {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.body, string(data), "the body is passed on untouched")
+			}
+		})
+	}
+}
+
+// TestFunctionHandler_RejectsInvalidCloudEvent: a trigger with CloudEvents set
+// answers an invalid event itself, attributed to the router, without
+// reaching the function.
+func TestFunctionHandler_RejectsInvalidCloudEvent(t *testing.T) {
+	t.Parallel()
+	fn := &fv1.Function{ObjectMeta: metav1.ObjectMeta{Name: "fn", Namespace: "default"}}
+	fh := &functionHandler{
+		logger:           logr.Discard(),
+		function:         fn,
+		structuredErrors: true,
+		httpTrigger: &fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{
+			CloudEvents: &fv1.HTTPTriggerCloudEvents{Required: true},
+		}},
+	}
+	rr := httptest.NewRecorder()
+	fh.handler(rr, httptest.NewRequest(http.MethodPost, "/fn", strings.NewReader(`{}`)))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var body ferror.InvocationError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, ferror.ComponentRouter, body.Component)
+	assert.Equal(t, ferror.ReasonInvalidCloudEvent, body.Reason)
+}
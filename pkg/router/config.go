@@ -6,6 +6,7 @@ package router
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"time"
@@ -13,6 +14,8 @@ import (
 	"github.com/go-logr/logr"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/router/ratelimit"
 	"github.com/fission/fission/pkg/router/streaming"
 )
 
@@ -72,15 +75,37 @@ type routerConfig struct {
 	// DISPLAY_ACCESS_LOG flag (chart: router.displayAccessLog, default false);
 	// off adds no per-request log volume.
 	accessLog bool
+	// responseCacheMaxBytes bounds the memory HTTPTrigger response cache
+	// entries use together (ROUTER_RESPONSE_CACHE_MAX_BYTES; optional, 0 =
+	// the built-in default, soft-fail).
+	responseCacheMaxBytes int64
+	// trustedProxies are the networks of the proxies in front of the router
+	// whose X-Forwarded-For rate limits believe (ROUTER_TRUSTED_PROXIES,
+	// comma-separated CIDRs; optional, empty trusts none, soft-fail).
+	trustedProxies ratelimit.TrustedProxies
 
 	// RFC-0024 async invocation. All lenient/optional: the chart sets these only
 	// when asyncInvocation.enabled, so an unset value must never abort startup.
 	// asyncInvocationEnabled gates the enqueue branch and the dispatcher;
 	// statestoreDriver/DSN open the statestore queue (driver "client" → the
-	// embedded statestore service).
+	// embedded statestore service). asyncStatusTTL is how long an
+	// invocation's status record is kept (ASYNC_STATUS_TTL; 0 = the default).
+	// asyncMaxInvokeDelay bounds a scheduled invocation's delay
+	// (ASYNC_MAX_INVOKE_DELAY; 0 = the default). asyncSpillStorageURL, when
+	// set, spills large request bodies to that storagesvc
+	// (ASYNC_SPILL_STORAGE_URL), and asyncMaxSpillBytes caps them
+	// (ASYNC_MAX_SPILL_BYTES; 0 = the default). asyncWebhookAllowedNetworks
+	// are the private networks webhook destinations may still reach
+	// (ASYNC_WEBHOOK_ALLOWED_NETWORKS, comma-separated CIDRs; empty = none).
 	asyncInvocationEnabled bool
 	statestoreDriver       string
 	statestoreDSN          string
+	asyncStatusTTL         time.Duration
+	asyncMaxInvokeDelay    time.Duration
+	asyncSpillStorageURL   string
+	asyncMaxSpillBytes     int64
+
+	asyncWebhookAllowedNetworks []netip.Prefix
 }
 
 // loadRouterConfig parses the router's environment configuration. Behavior is
@@ -234,6 +259,27 @@ func loadRouterConfig(logger logr.Logger) (routerConfig, error) {
 		}
 	}
 
+	// Optional sizing knob; unset or unparsable keeps the built-in default.
+	if raw := os.Getenv("ROUTER_RESPONSE_CACHE_MAX_BYTES"); raw != "" {
+		maxBytes, perr := strconv.ParseInt(raw, 10, 64)
+		if perr != nil || maxBytes <= 0 {
+			logger.Error(perr, "failed to parse 'ROUTER_RESPONSE_CACHE_MAX_BYTES' - using the default", "value", raw)
+		} else {
+			cfg.responseCacheMaxBytes = maxBytes
+		}
+	}
+
+	// Optional; unparsable keeps trusting no proxy, so clients are keyed by
+	// their connection's peer.
+	if raw := os.Getenv("ROUTER_TRUSTED_PROXIES"); raw != "" {
+		proxies, perr := ratelimit.ParseTrustedProxies(raw)
+		if perr != nil {
+			logger.Error(perr, "failed to parse 'ROUTER_TRUSTED_PROXIES' - trusting no proxy", "value", raw)
+		} else {
+			cfg.trustedProxies = proxies
+		}
+	}
+
 	// RFC-0024 async invocation. Optional and lenient: an unset/blank env means
 	// disabled and must never abort startup (the chart sets these only when the
 	// feature is on). An unparsable ASYNC_INVOCATION_ENABLED logs and stays off.
@@ -247,6 +293,45 @@ func loadRouterConfig(logger logr.Logger) (routerConfig, error) {
 	}
 	cfg.statestoreDriver = os.Getenv("STATESTORE_DRIVER")
 	cfg.statestoreDSN = os.Getenv("STATESTORE_DSN")
+	if raw := os.Getenv("ASYNC_STATUS_TTL"); raw != "" {
+		ttl, perr := time.ParseDuration(raw)
+		if perr != nil || ttl <= 0 {
+			logger.Error(perr, "failed to parse 'ASYNC_STATUS_TTL' - using the default", "value", raw)
+		} else {
+			cfg.asyncStatusTTL = ttl
+		}
+	}
+	if raw := os.Getenv("ASYNC_MAX_INVOKE_DELAY"); raw != "" {
+		maxDelay, perr := time.ParseDuration(raw)
+		if perr != nil || maxDelay <= 0 {
+			logger.Error(perr, "failed to parse 'ASYNC_MAX_INVOKE_DELAY' - using the default", "value", raw)
+		} else {
+			cfg.asyncMaxInvokeDelay = maxDelay
+		}
+	}
+	cfg.asyncSpillStorageURL = os.Getenv("ASYNC_SPILL_STORAGE_URL")
+	if raw := os.Getenv("ASYNC_MAX_SPILL_BYTES"); raw != "" {
+		maxBytes, perr := strconv.ParseInt(raw, 10, 64)
+		switch {
+		case perr != nil || maxBytes <= 0:
+			logger.Error(perr, "failed to parse 'ASYNC_MAX_SPILL_BYTES' - using the default", "value", raw)
+		case maxBytes > internalListenerMaxBodyBytes:
+			// Delivery replays the body through the internal listener, which
+			// rejects anything larger.
+			logger.Info("'ASYNC_MAX_SPILL_BYTES' exceeds the internal listener body cap - clamping", "value", raw, "max", internalListenerMaxBodyBytes)
+			cfg.asyncMaxSpillBytes = internalListenerMaxBodyBytes
+		default:
+			cfg.asyncMaxSpillBytes = maxBytes
+		}
+	}
+	if raw := os.Getenv("ASYNC_WEBHOOK_ALLOWED_NETWORKS"); raw != "" {
+		networks, perr := asyncinvoke.ParseWebhookNetworks(raw)
+		if perr != nil {
+			logger.Error(perr, "failed to parse 'ASYNC_WEBHOOK_ALLOWED_NETWORKS' - webhooks may reach no private network", "value", raw)
+		} else {
+			cfg.asyncWebhookAllowedNetworks = networks
+		}
+	}
 
 	switch mode := endpointSliceCacheMode(os.Getenv("ROUTER_ENDPOINTSLICE_CACHE_MODE")); mode {
 	case "", endpointSliceCacheOff:
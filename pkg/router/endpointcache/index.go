@@ -122,6 +122,17 @@ type (
 		// reports (never by Admit), so a plain mu-guarded map suffices;
 		// cleared alongside quarantined on slice events.
 		strikes map[string]dialStrike
+		// failures counts each address's consecutive failed responses for
+		// outlier ejection (see ReportResult), and failing how many
+		// addresses have a run going, so a success report for a function
+		// with none returns without taking mu. ejected maps the addresses
+		// ejected as outliers to their ejection's expiry; unlike a dial
+		// quarantine, an ejection outlives slice events, since a pod
+		// answering with errors is still Ready. All three are mu-guarded
+		// except failing.
+		failures map[string]int
+		failing  atomic.Int32
+		ejected  map[string]time.Time
 		// eps is the merged endpoint list, swapped copy-on-write. Hot-path
 		// readers load it without taking mu.
 		eps atomic.Pointer[[]Endpoint]
@@ -295,8 +306,7 @@ func (ix *Index) ApplySlice(es *discoveryv1.EndpointSlice) {
 	// Any slice event for this function lifts quarantines (and pending
 	// strikes): dead endpoints have been (or are being) removed by the slice
 	// controller, so survivors are trustworthy again.
-	e.quarantined.Store(nil)
-	e.strikes = nil
+	e.liftQuarantinesLocked(time.Now())
 	e.rebuildLocked()
 	e.mu.Unlock()
 }
@@ -320,8 +330,7 @@ func (ix *Index) DeleteSlice(es *discoveryv1.EndpointSlice) {
 
 	e.mu.Lock()
 	delete(e.slices, sliceKey)
-	e.quarantined.Store(nil)
-	e.strikes = nil
+	e.liftQuarantinesLocked(time.Now())
 	empty := len(e.slices) == 0
 	e.rebuildLocked()
 	e.mu.Unlock()
@@ -381,8 +390,7 @@ func (ix *Index) RemoveNamespace(ns string) {
 				e.mu.Unlock()
 				continue
 			}
-			e.quarantined.Store(nil)
-			e.strikes = nil
+			e.liftQuarantinesLocked(time.Now())
 			e.rebuildLocked()
 			if len(e.slices) == 0 {
 				delete(s.m, key)
@@ -59,6 +59,13 @@ var (
 		"fission_router_endpointcache_dial_timeout_strikes_total",
 		"Soft dial failures (timeouts) recorded against endpoints; quarantine requires several within one TTL window.",
 	)
+	// outlierEjections counts endpoints ejected for failing their
+	// function's requests (Function OutlierEjection), labelled like
+	// stickyTeleports.
+	outlierEjections = metrics.Int64Counter(
+		"fission_router_endpointcache_outlier_ejections_total",
+		"Endpoints ejected from the index after consecutive failed responses (Function outlierEjection).",
+	)
 	// fallbacks counts warm-path requests routed to the executor for a
 	// specific reason (strict-mode annotation, no endpoints, all endpoints
 	// saturated, or the executor not supporting ensureCapacity).
@@ -106,6 +113,16 @@ func RecordQuarantine() { quarantines.Add(context.Background(), 1) }
 // RecordDialTimeoutStrike counts one soft (timeout) dial-failure strike.
 func RecordDialTimeoutStrike() { dialTimeoutStrikes.Add(context.Background(), 1) }
 
+// RecordOutlierEjection counts one outlier ejection from a function's warm
+// pool; version is as in RecordStickyTeleport.
+func RecordOutlierEjection(namespace, name, version string) {
+	outlierEjections.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("function_namespace", namespace),
+		attribute.String("function_name", name),
+		attribute.String("function_version", version),
+	))
+}
+
 // RecordStickyTeleport counts one sticky-pick change for a function's warm
 // pool. version is the RFC-0025 warm-pool selector ("" for the unversioned
 // pool) — empty surfaces as an absent label on the Prometheus bridge, so
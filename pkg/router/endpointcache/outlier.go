@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package endpointcache
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// OutlierPolicy is a function's outlier ejection thresholds
+// (fv1.OutlierEjectionConfig).
+type OutlierPolicy struct {
+	// ConsecutiveFailures ejects an endpoint after that many failed
+	// responses in a row.
+	ConsecutiveFailures int
+	// EjectionDuration is how long an ejected endpoint is skipped by Admit.
+	EjectionDuration time.Duration
+	// MaxEjectionPercent caps the share of the function's ready endpoints
+	// ejected at once; one endpoint of several can always be ejected.
+	MaxEjectionPercent int
+}
+
+// ReportResult records the outcome of a request an index-admitted endpoint
+// answered, ejecting the endpoint once p.ConsecutiveFailures failures land
+// in a row. An ejection is a quarantine that lasts p.EjectionDuration and
+// survives slice events; it is refused when it would leave the function
+// with no endpoint, or eject more than p.MaxEjectionPercent of its ready
+// endpoints. The return value reports whether THIS call ejected address.
+//
+// A success for a function with no failure run going returns without
+// locking, so reporting every response costs the healthy path nothing.
+func (ix *Index) ReportResult(namespace, name, version, address string, failed bool, p OutlierPolicy) bool {
+	key := FnKey{Namespace: namespace, Name: name, Version: version}
+	s := ix.shard(key)
+	s.mu.RLock()
+	e, ok := s.m[key]
+	s.mu.RUnlock()
+	if !ok || (!failed && e.failing.Load() == 0) {
+		return false
+	}
+	now := time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !failed {
+		if _, ok := e.failures[address]; ok {
+			delete(e.failures, address)
+			e.failing.Add(-1)
+		}
+		return false
+	}
+	// Failures of an endpoint already out of rotation are requests admitted
+	// before it went out; they must not start its next run.
+	if cur := e.quarantined.Load(); cur != nil {
+		if expiry, out := (*cur)[address]; out && now.Before(expiry) {
+			return false
+		}
+	}
+	if e.failures == nil {
+		e.failures = make(map[string]int)
+	}
+	n, running := e.failures[address]
+	if !running {
+		e.failing.Add(1)
+	}
+	n++
+	if n < p.ConsecutiveFailures || !e.canEjectLocked(now, p.MaxEjectionPercent) {
+		e.failures[address] = n
+		return false
+	}
+	delete(e.failures, address)
+	e.failing.Add(-1)
+	if e.ejected == nil {
+		e.ejected = make(map[string]time.Time)
+	}
+	for a, expiry := range e.ejected {
+		if !now.Before(expiry) {
+			delete(e.ejected, a)
+		}
+	}
+	e.ejected[address] = now.Add(p.EjectionDuration)
+	e.quarantineLocked(address, now, p.EjectionDuration)
+	RecordOutlierEjection(namespace, name, version)
+	return true
+}
+
+// canEjectLocked reports whether one more endpoint may be ejected within
+// maxPercent of the ready endpoints. Caller holds e.mu.
+func (e *fnEntry) canEjectLocked(now time.Time, maxPercent int) bool {
+	ready := 0
+	if eps := e.eps.Load(); eps != nil {
+		for _, ep := range *eps {
+			if ep.Ready {
+				ready++
+			}
+		}
+	}
+	if ready < 2 {
+		return false
+	}
+	ejected := 0
+	for _, expiry := range e.ejected {
+		if now.Before(expiry) {
+			ejected++
+		}
+	}
+	return ejected == 0 || (ejected+1)*100 <= ready*maxPercent
+}
+
+// liftQuarantinesLocked clears the dial quarantines, dial strikes and
+// failure runs on a slice event, keeping the outlier ejections still in
+// force. Caller holds e.mu.
+func (e *fnEntry) liftQuarantinesLocked(now time.Time) {
+	e.strikes = nil
+	e.failures = nil
+	e.failing.Store(0)
+	var kept map[string]time.Time
+	for address, expiry := range e.ejected {
+		if !now.Before(expiry) {
+			delete(e.ejected, address)
+			continue
+		}
+		if kept == nil {
+			kept = make(map[string]time.Time, len(e.ejected))
+		}
+		kept[address] = expiry
+	}
+	if kept == nil {
+		e.ejected = nil
+		e.quarantined.Store(nil)
+		return
+	}
+	e.quarantined.Store(&kept)
+}
+
+// QuarantineReason says why an endpoint is out of rotation.
+type QuarantineReason string
+
+const (
+	// QuarantineDial is a dial failure quarantine (Quarantine,
+	// ReportDialTimeout).
+	QuarantineDial QuarantineReason = "dial"
+	// QuarantineOutlier is an outlier ejection (ReportResult).
+	QuarantineOutlier QuarantineReason = "outlier"
+)
+
+// QuarantineStatus is one endpoint out of rotation, for display.
+type QuarantineStatus struct {
+	Namespace string           `json:"namespace"`
+	Function  string           `json:"function"`
+	Version   string           `json:"version,omitempty"`
+	Address   string           `json:"address"`
+	Reason    QuarantineReason `json:"reason"`
+	Until     time.Time        `json:"until"`
+}
+
+// Quarantines lists the endpoints currently out of rotation, ordered by
+// function and address.
+func (ix *Index) Quarantines() []QuarantineStatus {
+	now := time.Now()
+	var out []QuarantineStatus
+	for i := range ix.shards {
+		s := &ix.shards[i]
+		s.mu.RLock()
+		for key, e := range s.m {
+			e.mu.Lock()
+			if cur := e.quarantined.Load(); cur != nil {
+				for address, expiry := range *cur {
+					if !now.Before(expiry) {
+						continue
+					}
+					reason := QuarantineDial
+					if ejectedUntil, ok := e.ejected[address]; ok && ejectedUntil.Equal(expiry) {
+						reason = QuarantineOutlier
+					}
+					out = append(out, QuarantineStatus{
+						Namespace: key.Namespace,
+						Function:  key.Name,
+						Version:   key.Version,
+						Address:   address,
+						Reason:    reason,
+						Until:     expiry,
+					})
+				}
+			}
+			e.mu.Unlock()
+		}
+		s.mu.RUnlock()
+	}
+	slices.SortFunc(out, func(a, b QuarantineStatus) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Function, b.Function),
+			cmp.Compare(a.Version, b.Version), cmp.Compare(a.Address, b.Address))
+	})
+	return out
+}
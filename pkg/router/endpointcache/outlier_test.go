@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package endpointcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReportResult pins outlier ejection: an endpoint failing its function's
+// requests in a row is taken out of rotation, within the ejection cap, and
+// stays out across slice events for the ejection's duration.
+func TestReportResult(t *testing.T) {
+	t.Parallel()
+	policy := OutlierPolicy{ConsecutiveFailures: 3, EjectionDuration: time.Minute, MaxEjectionPercent: 50}
+
+	t.Run("ejects after consecutive failures", func(t *testing.T) {
+		t.Parallel()
+		ix := NewIndex()
+		ix.ApplySlice(slice("s1", "fn-a", "default", 8888, "10.0.0.1", "10.0.0.2"))
+
+		assert.False(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy))
+		assert.False(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy))
+		assert.False(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", false, policy), "a success ends the run")
+		assert.False(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy))
+		assert.False(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy))
+		assert.True(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy))
+
+		for range 4 {
+			ep, release, result := ix.Admit("default", "fn-a", "", 10, "")
+			require.Equal(t, Admitted, result)
+			assert.Equal(t, "10.0.0.2:8888", ep.Address, "the ejected endpoint gets no traffic")
+			release()
+		}
+		q := ix.Quarantines()
+		require.Len(t, q, 1)
+		assert.Equal(t, QuarantineOutlier, q[0].Reason)
+		assert.Equal(t, "10.0.0.1:8888", q[0].Address)
+	})
+
+	t.Run("never ejects the only endpoint", func(t *testing.T) {
+		t.Parallel()
+		ix := NewIndex()
+		ix.ApplySlice(slice("s1", "fn-a", "default", 8888, "10.0.0.1"))
+		for range 5 {
+			assert.False(t, ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy))
+		}
+		_, release, result := ix.Admit("default", "fn-a", "", 1, "")
+		require.Equal(t, Admitted, result)
+		release()
+	})
+
+	t.Run("respects the ejection cap", func(t *testing.T) {
+		t.Parallel()
+		ix := NewIndex()
+		ix.ApplySlice(slice("s1", "fn-a", "default", 8888, "10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"))
+		eject := func(addr string) bool {
+			var ejected bool
+			for range policy.ConsecutiveFailures {
+				ejected = ix.ReportResult("default", "fn-a", "", addr, true, policy)
+			}
+			return ejected
+		}
+		assert.True(t, eject("10.0.0.1:8888"))
+		assert.True(t, eject("10.0.0.2:8888"), "2 of 4 is within 50%")
+		assert.False(t, eject("10.0.0.3:8888"), "3 of 4 is over 50%")
+		assert.Len(t, ix.Quarantines(), 2)
+	})
+
+	t.Run("ejections survive slice events", func(t *testing.T) {
+		t.Parallel()
+		ix := NewIndex()
+		ix.ApplySlice(slice("s1", "fn-a", "default", 8888, "10.0.0.1", "10.0.0.2"))
+		ix.Quarantine("default", "fn-a", "", "10.0.0.2:8888")
+		for range policy.ConsecutiveFailures {
+			ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, policy)
+		}
+		require.Len(t, ix.Quarantines(), 2)
+
+		ix.ApplySlice(slice("s1", "fn-a", "default", 8888, "10.0.0.1", "10.0.0.2", "10.0.0.3"))
+		q := ix.Quarantines()
+		require.Len(t, q, 1, "the dial quarantine is lifted")
+		assert.Equal(t, QuarantineOutlier, q[0].Reason)
+		assert.Equal(t, "10.0.0.1:8888", q[0].Address)
+	})
+
+	t.Run("ejections expire", func(t *testing.T) {
+		t.Parallel()
+		ix := NewIndex()
+		ix.ApplySlice(slice("s1", "fn-a", "default", 8888, "10.0.0.1", "10.0.0.2"))
+		short := policy
+		short.EjectionDuration = 30 * time.Millisecond
+		for range short.ConsecutiveFailures {
+			ix.ReportResult("default", "fn-a", "", "10.0.0.1:8888", true, short)
+		}
+		require.Len(t, ix.Quarantines(), 1)
+		time.Sleep(60 * time.Millisecond)
+		assert.Empty(t, ix.Quarantines())
+	})
+
+	t.Run("unknown function is a no-op", func(t *testing.T) {
+		t.Parallel()
+		ix := NewIndex()
+		assert.False(t, ix.ReportResult("default", "nope", "", "10.0.0.1:8888", true, policy))
+	})
+}
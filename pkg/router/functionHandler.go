@@ -24,6 +24,9 @@ import (
 	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/error/network"
 	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/router/circuitbreaker"
+	"github.com/fission/fission/pkg/router/ratelimit"
+	"github.com/fission/fission/pkg/router/respcache"
 	"github.com/fission/fission/pkg/router/streaming"
 	"github.com/fission/fission/pkg/utils"
 	"github.com/fission/fission/pkg/utils/correlation"
@@ -86,12 +89,35 @@ type functionHandler struct {
 	rtLogger    logr.Logger
 	policyByUID map[crd.CacheKeyUG]proxyPolicy
 	basesByUID  map[crd.CacheKeyUG][]string
+	// breakerByUID holds the circuit breaker of each backend function with a
+	// CircuitBreaker, keyed the same way.
+	breakerByUID map[crd.CacheKeyUG]*circuitbreaker.Breaker
 	// asyncInvoker enqueues RFC-0024 async invocations. Set on both the public
 	// HTTPTrigger handlers and the internal direct-function handlers, so a signed
 	// direct caller can go async; the dispatcher's own deliveries are excluded by
 	// the X-Fission-Invocation-Id guard in handler(), not by a nil invoker. May be
 	// nil (or hold a nil queue) when the feature is off.
 	asyncInvoker *asyncInvoker
+	// rateLimit is the route's resolved rate limit (hoisted like policyByUID),
+	// enforced through rateLimiter. Only HTTPTrigger routes carry one.
+	rateLimit   *routeRateLimit
+	rateLimiter *ratelimit.Limiter
+	// trustedProxies are the proxies whose X-Forwarded-For keys a clientIP
+	// rate limit.
+	trustedProxies ratelimit.TrustedProxies
+	// cacheRoute is the route's response cache policy, kept in respCache.
+	// Only HTTPTrigger routes with a Cache block carry one.
+	cacheRoute *routeCache
+	respCache  *respcache.Cache
+	// mirror is the route's resolved shadow version, whose copies mirrorer
+	// sends. Only HTTPTrigger routes with a Mirror carry one.
+	mirror   *mirrorTarget
+	mirrorer *mirrorer
+	// apiKeys checks the keys of a trigger with an APIKey block.
+	apiKeys *apiKeyStore
+	// transform is the route's compiled Transform. Only HTTPTrigger routes
+	// with a Transform carry one.
+	transform *routeTransform
 }
 
 // stickyMode names which of the two ways handler() derives its sticky key,
@@ -249,6 +275,23 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 	// fh.function is known.
 	stickyKey := stickyKeyFromRequest(fh.stickySource, request)
 
+	// Ahead of everything that costs the function capacity, the backend pick
+	// included.
+	if fh.httpTrigger != nil && fh.httpTrigger.Spec.APIKey != nil {
+		if request = fh.admitAPIKey(responseWriter, request); request == nil {
+			return
+		}
+	}
+	if fh.httpTrigger != nil && fh.httpTrigger.Spec.Authorization != nil && !fh.admitAuthorized(responseWriter, request) {
+		return
+	}
+	if fh.rateLimit != nil && !fh.admitRateLimited(responseWriter, request) {
+		return
+	}
+	if fh.httpTrigger != nil && fh.httpTrigger.Spec.APIKey != nil {
+		stripAPIKey(fh.httpTrigger.Spec.APIKey, request)
+	}
+
 	if len(fh.fnWeightDistributionList) > 0 {
 		// Weighted backend selection: stickyModePerBackend's legacy canary AND
 		// an RFC-0025 weighted FunctionAlias (stickyModeSingleSource,
@@ -295,6 +338,24 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 	// system params
 	setFunctionMetadataToHeader(&fh.function.ObjectMeta, request)
 
+	// Before the async branch, so a queued invocation carries the event in
+	// binary mode too.
+	if fh.httpTrigger != nil && fh.httpTrigger.Spec.CloudEvents != nil {
+		if status, err := readCloudEvent(request, fh.httpTrigger.Spec.CloudEvents); err != nil {
+			fh.writeInvocationError(responseWriter, request, status, ferror.ComponentRouter, ferror.ReasonInvalidCloudEvent, err.Error(), err)
+			return
+		}
+	}
+	annotateCloudEvent(request)
+
+	// After the CloudEvents binding, so the checks see the event data.
+	if fh.transform != nil {
+		if status, err := fh.transform.request(request); err != nil {
+			fh.writeInvocationError(responseWriter, request, status, ferror.ComponentRouter, ferror.ReasonInvalidRequest, err.Error(), err)
+			return
+		}
+	}
+
 	// RFC-0024: async invocation. handle() writes 501 when the feature is off (nil
 	// invoker/queue), answering an async-mode request honestly.
 	if fh.asyncRequested(request) {
@@ -309,6 +370,35 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 
 	policy := fh.proxyPolicyFor(fh.function, time.Duration(fnTimeout)*time.Second)
 
+	// Streamed responses are never cached.
+	var cached *cachedRequest
+	if fh.cacheRoute != nil && !policy.streaming {
+		var served bool
+		if cached, served = fh.serveCached(responseWriter, request); served {
+			return
+		}
+	}
+
+	// An open circuit turns away the request before it costs the function
+	// anything; cache hits are still served. outcome is set by the proxy's
+	// response and error handlers and recorded once the proxy is done.
+	breaker := fh.breakerByUID[crd.CacheKeyUGFromMeta(&fh.function.ObjectMeta)]
+	outcome := circuitbreaker.Ignored
+	if breaker != nil {
+		ticket, ok := fh.admitCircuit(responseWriter, request, breaker)
+		if !ok {
+			return
+		}
+		defer func() { fh.circuitDone(request.Context(), breaker, ticket, outcome) }()
+	}
+
+	// Only requests that reach the function are mirrored, so after the
+	// cache has had its say.
+	var shadow *mirroredRequest
+	if fh.mirror != nil && !policy.streaming {
+		shadow = fh.mirrorRequest(request, time.Duration(fnTimeout)*time.Second)
+	}
+
 	// Streaming: scope the request to a max-duration ceiling and an idle
 	// Watchdog (see setupStreamContext). Classic path: the request context is
 	// used unchanged (byte-identical behavior).
@@ -336,11 +426,19 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 
 	start := time.Now()
 
+	errorHandler := fh.getProxyErrorHandler(start, rrt)
+	if breaker != nil {
+		proxyError := errorHandler
+		errorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
+			outcome = circuitOutcomeForError(err)
+			proxyError(rw, req, err)
+		}
+	}
 	proxy := &httputil.ReverseProxy{
 		Rewrite:      directorParityRewrite,
 		Transport:    rrt,
 		BufferPool:   proxyResponseBufferPool,
-		ErrorHandler: fh.getProxyErrorHandler(start, rrt),
+		ErrorHandler: errorHandler,
 		ModifyResponse: func(resp *http.Response) error {
 			// The route-miss marker may ONLY ever be set by the router's own
 			// internal-listener not-found handler (internalRouteMissHandler).
@@ -350,6 +448,18 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 			// fallback) or any other marker consumer. Strip it from every
 			// proxied response.
 			resp.Header.Del(utils.HeaderRouteMiss)
+			if fh.transform != nil {
+				fh.transform.response(resp)
+			}
+			if breaker != nil {
+				outcome = circuitOutcomeForStatus(resp.StatusCode)
+			}
+			if cached != nil {
+				fh.storeResponse(cached, request, resp)
+			}
+			if shadow != nil {
+				shadow.observePrimary(resp)
+			}
 			// One goroutine for metric collection + the cached-URL tap (the
 			// historical pairing — the tap is a buffered channel send and does
 			// not warrant a spawn of its own).
@@ -401,6 +511,9 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 		otelUtils.SpanTrackEvent(request.Context(), "functionRequestProxy", otelUtils.GetAttributesForFunction(fh.function)...)
 	}
 	proxy.ServeHTTP(responseWriter, request)
+	if shadow != nil {
+		shadow.primaryDone()
+	}
 }
 
 // classifyFunctionError returns the stable reason for a function-side
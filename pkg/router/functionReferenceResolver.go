@@ -94,6 +94,13 @@ type (
 		// recomputing the key from whichever backend the (unkeyed, random)
 		// pick lands on.
 		stickySource *fv1.Function
+		// mirror is the shadow version the route copies sampled requests to,
+		// nil when it mirrors nothing (see resolveMirror).
+		mirror *mirrorTarget
+		// aliasMirror is the Mirror of the FunctionAlias resolveByAlias
+		// resolved through; resolveMirror applies it when the trigger sets
+		// none of its own.
+		aliasMirror *fv1.TrafficMirror
 	}
 )
 
@@ -117,7 +124,14 @@ func makeFunctionReferenceResolver(logger logr.Logger, reader client.Reader) *fu
 func (frr *functionReferenceResolver) resolve(ctx context.Context, trigger fv1.HTTPTrigger) (*resolveResult, error) {
 	switch trigger.Spec.FunctionReference.Type {
 	case fv1.FunctionReferenceTypeFunctionName:
-		return frr.resolveByName(ctx, trigger.Namespace, trigger.Spec.FunctionReference)
+		rr, err := frr.resolveByName(ctx, trigger.Namespace, trigger.Spec.FunctionReference)
+		if err != nil {
+			return nil, err
+		}
+		if err := frr.resolveMirror(ctx, &trigger, rr); err != nil {
+			return nil, err
+		}
+		return rr, nil
 	case fv1.FunctionReferenceTypeFunctionWeights:
 		return frr.resolveByFunctionWeights(ctx, trigger.Namespace, &trigger.Spec.FunctionReference)
 	default:
@@ -305,6 +319,7 @@ func (frr *functionReferenceResolver) resolveByAlias(ctx context.Context, namesp
 		rr := singleFunctionResult(primaryKey, primary)
 		rr.AliasGens = map[string]int64{ref.Alias: alias.Generation}
 		rr.stickySource = live
+		rr.aliasMirror = alias.Spec.Mirror
 		return rr, nil
 	}
 
@@ -335,10 +350,73 @@ func (frr *functionReferenceResolver) resolveByAlias(ctx context.Context, namesp
 		},
 		AliasGens:    map[string]int64{ref.Alias: alias.Generation},
 		stickySource: live,
+		aliasMirror:  alias.Spec.Mirror,
 	}
 	return &rr, nil
 }
 
+// resolveMirror pins the shadow version rr's route mirrors to: the
+// trigger's own Mirror, else that of the FunctionAlias the trigger resolves
+// through. A version target is checked to exist and belong to the trigger's
+// function; an alias target resolves to its effective version, and the
+// alias joins rr.AliasGens so a repoint (or its creation) re-applies the
+// trigger. A target that does not resolve leaves the route unmirrored
+// rather than unserved — a shadow must never cost the primary its route —
+// and the next event or resync retries it. Only transient reader errors
+// fail the resolve.
+func (frr *functionReferenceResolver) resolveMirror(ctx context.Context, trigger *fv1.HTTPTrigger, rr *resolveResult) error {
+	m := trigger.Spec.Mirror
+	if m == nil {
+		m = rr.aliasMirror
+	}
+	if m == nil {
+		return nil
+	}
+	namespace, name := trigger.Namespace, trigger.Spec.FunctionReference.Name
+
+	version := m.Version
+	if m.Alias != "" {
+		alias := &fv1.FunctionAlias{}
+		err := frr.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: m.Alias}, alias)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if rr.AliasGens == nil {
+			rr.AliasGens = make(map[string]int64, 1)
+		}
+		rr.AliasGens[m.Alias] = alias.Generation
+		switch {
+		case err != nil:
+			frr.logger.Info("mirror alias does not exist; not mirroring", "trigger", trigger.Name, "namespace", namespace, "alias", m.Alias)
+			return nil
+		case alias.Spec.FunctionName != name:
+			frr.logger.Info("mirror alias targets another function; not mirroring", "trigger", trigger.Name, "namespace", namespace,
+				"alias", m.Alias, "aliasFunction", alias.Spec.FunctionName, "function", name)
+			return nil
+		}
+		if version = alias.EffectiveTarget(); version == "" {
+			frr.logger.Info("mirror alias has not resolved to a version yet; not mirroring", "trigger", trigger.Name, "namespace", namespace, "alias", m.Alias)
+			return nil
+		}
+	}
+
+	v := &fv1.FunctionVersion{}
+	err := frr.reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: version}, v)
+	switch {
+	case apierrors.IsNotFound(err):
+		frr.logger.Info("mirror version does not exist; not mirroring", "trigger", trigger.Name, "namespace", namespace, "version", version)
+		return nil
+	case err != nil:
+		return err
+	case v.Spec.FunctionName != name:
+		frr.logger.Info("mirror version belongs to another function; not mirroring", "trigger", trigger.Name, "namespace", namespace,
+			"version", version, "versionFunction", v.Spec.FunctionName, "function", name)
+		return nil
+	}
+	rr.mirror = &mirrorTarget{function: name, version: version, percent: m.Percent}
+	return nil
+}
+
 func (frr *functionReferenceResolver) resolveByFunctionWeights(ctx context.Context, namespace string, fr *fv1.FunctionReference) (*resolveResult, error) {
 	functionMap := make(map[string]*fv1.Function)
 	fnWtDistrList := make([]functionWeightDistribution, 0)
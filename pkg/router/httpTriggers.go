@@ -30,6 +30,9 @@ import (
 	config "github.com/fission/fission/pkg/featureconfig"
 	"github.com/fission/fission/pkg/generated/clientset/versioned"
 	"github.com/fission/fission/pkg/info"
+	"github.com/fission/fission/pkg/router/circuitbreaker"
+	"github.com/fission/fission/pkg/router/ratelimit"
+	"github.com/fission/fission/pkg/router/respcache"
 	"github.com/fission/fission/pkg/router/routetable"
 	"github.com/fission/fission/pkg/throttler"
 	"github.com/fission/fission/pkg/utils/httpmux"
@@ -108,6 +111,37 @@ type HTTPTriggerSet struct {
 	// makeHTTPTriggerSet (like addressResolver) when ASYNC_INVOCATION_ENABLED, and
 	// copied into public function handlers by buildTriggerHandler. nil = feature off.
 	asyncInvoker *asyncInvoker
+
+	// rateLimiter enforces HTTPTrigger and Function rate limits on the public
+	// handlers. makeHTTPTriggerSet starts it on per-replica buckets; Start
+	// replaces it with one over the statestore KV when the statestore is open.
+	rateLimiter *ratelimit.Limiter
+	// trustedProxies are the proxies whose X-Forwarded-For a clientIP rate
+	// limit key believes (ROUTER_TRUSTED_PROXIES).
+	trustedProxies ratelimit.TrustedProxies
+
+	// respCache holds the responses of HTTPTriggers with a Cache block.
+	// makeHTTPTriggerSet starts it with the default memory cap; Start
+	// replaces it with one sized by ROUTER_RESPONSE_CACHE_MAX_BYTES, over the
+	// statestore KV when the statestore is open.
+	respCache *respcache.Cache
+
+	// mirrorer sends the shadow copies of HTTPTriggers with a Mirror, their
+	// own or their alias's. Set by Start; nil leaves every route unmirrored.
+	mirrorer *mirrorer
+
+	// circuitBreakers holds the breakers of Functions with a CircuitBreaker,
+	// kept across mux builds so a rebuild never closes an open circuit.
+	circuitBreakers *circuitbreaker.Registry
+
+	// auth is the public listener's token verifier, kept across mux builds
+	// (see authenticatorFor).
+	authMu sync.Mutex
+	auth   *authenticator
+
+	// apiKeys reads and caches the Secrets named by HTTPTrigger APIKey
+	// blocks, shared by every handler so a Secret is read once per TTL.
+	apiKeys *apiKeyStore
 }
 
 // initIncrementalRoutes wires the route table and feature-config source for the
@@ -136,6 +170,10 @@ func makeHTTPTriggerSet(logger logr.Logger, fmap *functionServiceMap, fissionCli
 		isDebugEnv:                 isDebugEnv,
 		useEncodedPath:             useEncodedPath,
 		syncDebouncer:              debounce.New(time.Millisecond * 20),
+		rateLimiter:                ratelimit.New(logger.WithName("ratelimit"), nil),
+		respCache:                  respcache.New(logger.WithName("respcache"), 0, nil),
+		circuitBreakers:            newCircuitBreakers(logger.WithName("circuitbreaker")),
+		apiKeys:                    newAPIKeyStore(logger.WithName("apikeys"), kubeClient),
 	}
 	httpTriggerSet.resolver = makeFunctionReferenceResolver(logger, cl)
 	// The address resolver and tapper are the proxy path's injected seams
@@ -380,6 +418,10 @@ func (ts *HTTPTriggerSet) buildMuxes(ctx context.Context, fnTimeoutMap map[crd.C
 	ts.registerRouterOwnedRoutes(publicMux, featureConfig, homeHandled)
 	ts.registerAsyncDLQRoutes(internalMux)
 	ts.registerTopicRoutes(internalMux)
+	ts.registerWatchRoutes(internalMux)
+	ts.registerAsyncStatusRoutes(internalMux)
+	ts.registerResponseCacheRoutes(internalMux)
+	ts.registerCircuitDebugRoutes(internalMux)
 
 	return publicMux, internalMux, nil
 }
@@ -438,6 +480,9 @@ func triggerConfigError(trigger *fv1.HTTPTrigger) (reason string, err error) {
 	if e := validateRouteTemplate(deriveRouteShape(trigger)); e != nil {
 		return fv1.HTTPTriggerReasonInvalidRouteTemplate, e
 	}
+	if _, e := compileTransform(trigger.Spec.Transform); e != nil {
+		return fv1.HTTPTriggerReasonInvalidTransform, e
+	}
 	return "", nil
 }
 
@@ -158,6 +158,30 @@ func TestAuthPreMatch401OnUnknownPath(t *testing.T) {
 		"/readyz must be exempt from auth (probes carry no token)")
 }
 
+// TestClaimHeadersStrippedWithoutAuth: with authentication off there are no
+// verified claims, so caller-sent X-Fission-Claim-* headers must still never
+// reach a function.
+func TestClaimHeadersStrippedWithoutAuth(t *testing.T) {
+	ts := newTestTriggerSet(t, nil, nil)
+	public, _ := ts.newListenerMuxes(&config.FeatureConfig{})
+	var seen http.Header
+	public.HandleFunc("/fn", func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+	req.Header.Set("X-Fission-Claim-Sub", "admin")
+	req.Header.Set("X-Fission-Claim-Groups", "root")
+	req.Header.Set("X-Request-Id", "kept")
+	rr := httptest.NewRecorder()
+	public.Handler().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, seen.Get("X-Fission-Claim-Sub"))
+	assert.Empty(t, seen.Get("X-Fission-Claim-Groups"))
+	assert.Equal(t, "kept", seen.Get("X-Request-Id"))
+}
+
 // TestRouterOwnedRoutesSkipInvalidAuthPath pins the operator-misconfig guard: a
 // malformed AuthUriPath (httpmux would panic compiling it) must NOT crash the
 // mux build — the login route is skipped (logged) and the rest of the listener
@@ -173,6 +173,10 @@ func (ts *HTTPTriggerSet) applyTriggerIncremental(ctx context.Context, trigger *
 	if rr.stickySource != nil {
 		stickyGen = rr.stickySource.Generation
 	}
+	var mirror string
+	if rr.mirror != nil {
+		mirror = rr.mirror.key()
+	}
 	spec := &routetable.RouteSpec{
 		TriggerUID: trigger.UID,
 		Namespace:  trigger.Namespace,
@@ -181,6 +185,7 @@ func (ts *HTTPTriggerSet) applyTriggerIncremental(ctx context.Context, trigger *
 		FnGens:     fnGens,
 		AliasGens:  rr.AliasGens,
 		StickyGen:  stickyGen,
+		Mirror:     mirror,
 		ExactPath:  shape.exactPath,
 		PrefixPath: shape.prefixPath,
 		Host:       shape.host,
@@ -647,6 +652,10 @@ func (ts *HTTPTriggerSet) buildIncrementalMuxes(featureConfig *config.FeatureCon
 	ts.registerRouterOwnedRoutes(publicMux, featureConfig, m.HomeClaimed)
 	ts.registerAsyncDLQRoutes(internalMux)
 	ts.registerTopicRoutes(internalMux)
+	ts.registerWatchRoutes(internalMux)
+	ts.registerAsyncStatusRoutes(internalMux)
+	ts.registerResponseCacheRoutes(internalMux)
+	ts.registerCircuitDebugRoutes(internalMux)
 	return publicMux, internalMux
 }
 
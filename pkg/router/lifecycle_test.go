@@ -104,7 +104,7 @@ func TestAuthMiddlewareExemptsProbes(t *testing.T) {
 	fc.AuthConfig.IsEnabled = true
 	fc.AuthConfig.AuthUriPath = "/auth/login"
 
-	h := authMiddleware(fc)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	h := authMiddleware(newAuthenticator(logr.Discard(), fc.AuthConfig))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
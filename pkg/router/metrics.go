@@ -5,6 +5,7 @@
 package router
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"sync"
@@ -15,6 +16,8 @@ import (
 	"go.opentelemetry.io/otel/metric"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/cloudevents"
+	"github.com/fission/fission/pkg/router/circuitbreaker"
 	"github.com/fission/fission/pkg/utils/correlation"
 	"github.com/fission/fission/pkg/utils/metrics"
 	otelUtils "github.com/fission/fission/pkg/utils/otel"
@@ -103,8 +106,165 @@ var (
 		"fission_invocation_failures_total",
 		"Count of failed function invocations attributed by component and reason (RFC-0015).",
 	)
+	// API-key checks on HTTPTriggers with an APIKey block, labelled by
+	// namespace/trigger, the matching key's name (empty unless accepted) and
+	// result (accepted, missing, invalid). Key names are chosen by the
+	// trigger's owner, so the cardinality is theirs to bound.
+	apiKeyRequests = metrics.Int64Counter(
+		"fission_router_apikey_requests_total",
+		"API-key checks on HTTP triggers by trigger, key name and result.",
+	)
+	// Response cache lookups on HTTPTriggers with a Cache block, labelled by
+	// namespace/trigger and result: hit (answered from a fresh entry, 304s
+	// to conditional requests included), miss (proxied to the function) and
+	// bypass (never cacheable: a method other than GET or HEAD, no-store or
+	// a Range request). revalidated counts the misses the function answered
+	// 304 to the router's own conditional request, so the stale entry's body
+	// was served and the entry refreshed.
+	responseCacheRequests = metrics.Int64Counter(
+		"fission_router_response_cache_requests_total",
+		"Response cache lookups on HTTP triggers by trigger and result.",
+	)
+	// Traffic mirroring on HTTPTriggers with a Mirror (their own or their
+	// alias's), labelled by namespace/trigger and the shadow's function and
+	// version. Requests count the shadow copies sent by the code the shadow
+	// answered ("0" when it did not); the diff counter also carries the
+	// primary's version (empty for the live function) and compares the two
+	// responses: match, status_mismatch, body_mismatch, or incomplete when
+	// either side failed or was cut short. Skipped counts sampled requests
+	// that could not be copied (body_too_large, overloaded).
+	mirrorRequests = metrics.Int64Counter(
+		"fission_router_mirror_requests_total",
+		"Shadow copies of mirrored HTTP trigger requests by trigger, shadow version and code.",
+	)
+	mirrorDuration = metrics.Float64Histogram(
+		"fission_router_mirror_duration_seconds",
+		"Latency of shadow copies of mirrored HTTP trigger requests by trigger and shadow version.",
+		prometheus.DefBuckets,
+	)
+	mirrorDiffs = metrics.Int64Counter(
+		"fission_router_mirror_diffs_total",
+		"Comparisons of shadow and primary responses by trigger, shadow and primary version and result.",
+	)
+	mirrorSkipped = metrics.Int64Counter(
+		"fission_router_mirror_skipped_total",
+		"Sampled HTTP trigger requests that were not mirrored, by trigger and reason.",
+	)
+	// Circuit breakers of Functions with a CircuitBreaker, labelled by
+	// namespace and the breaker's function_name and function_version (empty
+	// for the live function). The state gauge is 0 closed, 1 half-open and
+	// 2 open, as of the breaker's last change on this replica; transitions
+	// carry from/to, and rejections count the requests answered 503
+	// circuit_open.
+	circuitState = metrics.Int64Gauge(
+		"fission_router_circuit_breaker_state",
+		"Circuit breaker state by function and version: 0 closed, 1 half-open, 2 open.",
+	)
+	circuitTransitions = metrics.Int64Counter(
+		"fission_router_circuit_breaker_transitions_total",
+		"Circuit breaker state changes by function, version and from/to state.",
+	)
+	circuitRejections = metrics.Int64Counter(
+		"fission_router_circuit_breaker_rejections_total",
+		"Requests rejected by an open circuit breaker, by function and version.",
+	)
 )
 
+const (
+	apiKeyResultAccepted = "accepted"
+	apiKeyResultMissing  = "missing"
+	apiKeyResultInvalid  = "invalid"
+)
+
+const (
+	responseCacheHit         = "hit"
+	responseCacheMiss        = "miss"
+	responseCacheBypass      = "bypass"
+	responseCacheRevalidated = "revalidated"
+)
+
+func recordResponseCache(ctx context.Context, rc *routeCache, result string) {
+	responseCacheRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("namespace", rc.namespace),
+		attribute.String("trigger", rc.trigger),
+		attribute.String("result", result),
+	))
+}
+
+const (
+	mirrorDiffMatch      = "match"
+	mirrorDiffStatus     = "status_mismatch"
+	mirrorDiffBody       = "body_mismatch"
+	mirrorDiffIncomplete = "incomplete"
+
+	mirrorSkipBodyTooLarge = "body_too_large"
+	mirrorSkipOverloaded   = "overloaded"
+)
+
+func mirrorAttrs(mr *mirroredRequest, extra ...attribute.KeyValue) metric.MeasurementOption {
+	return metric.WithAttributes(append([]attribute.KeyValue{
+		attribute.String("namespace", mr.namespace),
+		attribute.String("trigger", mr.trigger),
+		attribute.String("function_name", mr.target.function),
+		attribute.String("function_version", mr.target.version),
+	}, extra...)...)
+}
+
+func recordMirrorRequest(ctx context.Context, mr *mirroredRequest, code int, duration time.Duration) {
+	mirrorRequests.Add(ctx, 1, mirrorAttrs(mr, attribute.String("code", strconv.Itoa(code))))
+	mirrorDuration.Record(ctx, duration.Seconds(), mirrorAttrs(mr))
+}
+
+func recordMirrorDiff(ctx context.Context, mr *mirroredRequest, result string) {
+	mirrorDiffs.Add(ctx, 1, mirrorAttrs(mr,
+		attribute.String("primary_version", mr.primaryVersion),
+		attribute.String("result", result),
+	))
+}
+
+func recordMirrorSkipped(ctx context.Context, mr *mirroredRequest, reason string) {
+	mirrorSkipped.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("namespace", mr.namespace),
+		attribute.String("trigger", mr.trigger),
+		attribute.String("reason", reason),
+	))
+}
+
+var circuitStateValues = map[circuitbreaker.State]int64{
+	circuitbreaker.Closed:   0,
+	circuitbreaker.HalfOpen: 1,
+	circuitbreaker.Open:     2,
+}
+
+func circuitAttrs(key circuitbreaker.Key, extra ...attribute.KeyValue) metric.MeasurementOption {
+	return metric.WithAttributes(append([]attribute.KeyValue{
+		attribute.String("namespace", key.Namespace),
+		attribute.String("function_name", key.Function),
+		attribute.String("function_version", key.Version),
+	}, extra...)...)
+}
+
+func recordCircuitTransition(ctx context.Context, key circuitbreaker.Key, tr circuitbreaker.Transition) {
+	circuitState.Record(ctx, circuitStateValues[tr.To], circuitAttrs(key))
+	circuitTransitions.Add(ctx, 1, circuitAttrs(key,
+		attribute.String("from", string(tr.From)),
+		attribute.String("to", string(tr.To)),
+	))
+}
+
+func recordCircuitRejected(ctx context.Context, fn *fv1.Function) {
+	circuitRejections.Add(ctx, 1, circuitAttrs(breakerKey(fn)))
+}
+
+func recordAPIKeyRequest(ctx context.Context, trigger *fv1.HTTPTrigger, keyName, result string) {
+	apiKeyRequests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("namespace", trigger.Namespace),
+		attribute.String("trigger", trigger.Name),
+		attribute.String("key", keyName),
+		attribute.String("result", result),
+	))
+}
+
 // functionCallAttrsCache memoizes the metric.MeasurementOption (which wraps a
 // sorted, deduped attribute.Set) per (namespace,name,version,path,method,code)
 // so the warm path does a comparable-array map lookup — allocating nothing —
@@ -186,7 +346,7 @@ func (fh functionHandler) logAccessRecord(rrt *RetryingRoundTripper, req *http.R
 		backend = rrt.serviceURL.Host
 	}
 	ctx := req.Context()
-	fh.logger.Info("function access",
+	kv := []any{
 		"fission.request.id", correlation.FromContext(ctx),
 		"trace_id", otelUtils.TraceIDFromContext(ctx),
 		"fission.function.name", fh.function.Name,
@@ -198,5 +358,12 @@ func (fh functionHandler) logAccessRecord(rrt *RetryingRoundTripper, req *http.R
 		"backend", backend,
 		"retry", rrt.totalRetry,
 		"duration_ms", duration.Milliseconds(),
-	)
+	}
+	if id := req.Header.Get(cloudevents.HeaderID); id != "" {
+		kv = append(kv, attrCloudEventID, id)
+	}
+	if name := apiKeyName(ctx); name != "" {
+		kv = append(kv, attrAPIKeyName, name)
+	}
+	fh.logger.Info("function access", kv...)
 }
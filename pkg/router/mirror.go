@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	"github.com/fission/fission/pkg/router/routetable"
+)
+
+const (
+	// mirrorMaxBodyBytes bounds the request bodies the router buffers to
+	// copy; larger ones, and bodies of unknown length, are not mirrored.
+	mirrorMaxBodyBytes = 1 << 20
+	// defaultMirrorMaxInFlight bounds the shadow requests a router has
+	// outstanding across all routes; a request sampled beyond it is not
+	// mirrored, so a slow shadow can never pile up goroutines and buffers.
+	defaultMirrorMaxInFlight = 256
+	// headerMirrorOf marks a shadow copy for the function, naming the
+	// trigger it was copied from.
+	headerMirrorOf = "X-Fission-Mirror-Of"
+)
+
+// mirrorTarget is a route's resolved shadow: a FunctionVersion of the
+// trigger's function and the share of requests it receives.
+type mirrorTarget struct {
+	function string
+	version  string
+	percent  int
+}
+
+func (m *mirrorTarget) key() string { return routetable.BackendKey(m.function, m.version) }
+
+// mirrorer sends shadow copies to the router's own internal listener, at
+// the `:<version>` route materialized for every FunctionVersion, so a copy
+// is resolved, cold-started and proxied exactly like any invocation of that
+// version, and is signed like the async deliverer's deliveries.
+type mirrorer struct {
+	logger   logr.Logger
+	client   *http.Client
+	baseURL  string
+	inFlight chan struct{}
+}
+
+func newMirrorer(logger logr.Logger, baseURL string, master []byte, transport http.RoundTripper, maxInFlight int) *mirrorer {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if len(master) > 0 {
+		transport = hmacauth.ServiceSigner(master, hmacauth.ServiceRouterInternal, transport, time.Now)
+	}
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMirrorMaxInFlight
+	}
+	return &mirrorer{
+		logger:   logger,
+		client:   &http.Client{Transport: transport},
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+}
+
+// mirrorResult is one side of a mirrored request's comparison.
+type mirrorResult struct {
+	status int
+	digest []byte
+	// complete is false when the side failed or its body was not read to
+	// the end, so there is nothing to compare.
+	complete bool
+}
+
+// mirroredRequest is one sampled request's shadow copy. The primary side
+// is captured on the request goroutine (observePrimary, primaryDone) and
+// handed to the goroutine sending the copy through primary.
+type mirroredRequest struct {
+	trigger        string
+	namespace      string
+	target         *mirrorTarget
+	primaryVersion string
+	req            *http.Request
+	body           []byte
+
+	primaryStatus int
+	primaryHash   hash.Hash
+	primaryEOF    bool
+	primary       chan mirrorResult
+}
+
+// mirrorRequest samples request for the route's mirror and, when it is
+// picked, buffers its body (restoring it for the primary) and starts the
+// shadow copy. It returns nil when the request is not mirrored.
+func (fh functionHandler) mirrorRequest(request *http.Request, timeout time.Duration) *mirroredRequest {
+	m := fh.mirror
+	if rand.IntN(100) >= m.percent || request.Header.Get("Upgrade") != "" {
+		return nil
+	}
+	mr := &mirroredRequest{
+		trigger:        fh.httpTrigger.Name,
+		namespace:      fh.httpTrigger.Namespace,
+		target:         m,
+		primaryVersion: fh.function.Labels[fv1.FUNCTION_VERSION],
+		primary:        make(chan mirrorResult, 1),
+	}
+	if request.Body != nil && request.Body != http.NoBody {
+		if request.ContentLength < 0 || request.ContentLength > mirrorMaxBodyBytes {
+			recordMirrorSkipped(request.Context(), mr, mirrorSkipBodyTooLarge)
+			return nil
+		}
+		body, err := io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			// The primary sees the same failure the router did.
+			request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), &errReader{err: err}))
+			return nil
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		mr.body = body
+	}
+	select {
+	case fh.mirrorer.inFlight <- struct{}{}:
+	default:
+		recordMirrorSkipped(request.Context(), mr, mirrorSkipOverloaded)
+		return nil
+	}
+
+	// The copy outlives the client's request: detach it from cancellation,
+	// keeping its values (trace, correlation id), and bound it by the
+	// function's own timeout.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(request.Context()), timeout)
+	url := fh.mirrorer.baseURL + "/fission-function/" + mr.namespace + "/" + m.function + ":" + m.version +
+		mirrorSubpath(fh.httpTrigger, request.URL.Path)
+	if request.URL.RawQuery != "" {
+		url += "?" + request.URL.RawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, request.Method, url, bytes.NewReader(mr.body))
+	if err != nil {
+		cancel()
+		<-fh.mirrorer.inFlight
+		fh.logger.Error(err, "building mirror request", "version", m.version)
+		return nil
+	}
+	req.Header = request.Header.Clone()
+	req.Header.Set(headerMirrorOf, mr.trigger)
+	req.Host = request.Host
+	mr.req = req
+
+	go func() {
+		defer func() { <-fh.mirrorer.inFlight }()
+		defer cancel()
+		fh.mirrorer.send(mr, timeout)
+	}()
+	return mr
+}
+
+// mirrorSubpath is the part of path the primary hands the function (see
+// rewriteFunctionURL), which the internal route hands the shadow in turn
+// once it has trimmed its own /fission-function/... prefix.
+func mirrorSubpath(trigger *fv1.HTTPTrigger, path string) string {
+	if trigger.Spec.Prefix == nil || *trigger.Spec.Prefix == "" {
+		return ""
+	}
+	if !trigger.Spec.KeepPrefix {
+		path = strings.TrimPrefix(path, *trigger.Spec.Prefix)
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// observePrimary records the primary response's status and digests its
+// body as the proxy copies it to the client.
+func (mr *mirroredRequest) observePrimary(resp *http.Response) {
+	mr.primaryStatus = resp.StatusCode
+	mr.primaryHash = sha256.New()
+	// The proxy may never read a body that cannot have any.
+	mr.primaryEOF = resp.Body == http.NoBody || resp.ContentLength == 0
+	resp.Body = &digestReader{ReadCloser: resp.Body, h: mr.primaryHash, eof: &mr.primaryEOF}
+}
+
+// primaryDone hands the primary side to the shadow's goroutine once the
+// proxy has finished with the response.
+func (mr *mirroredRequest) primaryDone() {
+	res := mirrorResult{status: mr.primaryStatus, complete: mr.primaryEOF}
+	if mr.primaryHash != nil {
+		res.digest = mr.primaryHash.Sum(nil)
+	}
+	mr.primary <- res
+}
+
+// send sends the shadow copy, discards its response after digesting it,
+// and compares it with the primary's once that is in, waiting at most
+// timeout for it.
+func (m *mirrorer) send(mr *mirroredRequest, timeout time.Duration) {
+	ctx := mr.req.Context()
+	start := time.Now()
+	var shadow mirrorResult
+	resp, err := m.client.Do(mr.req)
+	if err == nil {
+		h := sha256.New()
+		_, err = io.Copy(h, resp.Body)
+		_ = resp.Body.Close()
+		shadow = mirrorResult{status: resp.StatusCode, digest: h.Sum(nil), complete: err == nil}
+	}
+	if err != nil {
+		m.logger.V(1).Info("mirror request failed", "namespace", mr.namespace, "trigger", mr.trigger, "version", mr.target.version, "error", err.Error())
+	}
+	recordMirrorRequest(ctx, mr, shadow.status, time.Since(start))
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case primary := <-mr.primary:
+		recordMirrorDiff(ctx, mr, compareMirror(primary, shadow))
+	case <-timer.C:
+		recordMirrorDiff(ctx, mr, mirrorDiffIncomplete)
+	}
+}
+
+// compareMirror summarizes how the shadow's response differs from the
+// primary's.
+func compareMirror(primary, shadow mirrorResult) string {
+	switch {
+	case !primary.complete || !shadow.complete:
+		return mirrorDiffIncomplete
+	case primary.status != shadow.status:
+		return mirrorDiffStatus
+	case !bytes.Equal(primary.digest, shadow.digest):
+		return mirrorDiffBody
+	default:
+		return mirrorDiffMatch
+	}
+}
+
+// digestReader hashes a response body as it is read and notes reaching
+// its end.
+type digestReader struct {
+	io.ReadCloser
+	h   hash.Hash
+	eof *bool
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	d.h.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		*d.eof = true
+	}
+	return n, err
+}
+
+type errReader struct{ err error }
+
+func (e *errReader) Read([]byte) (int, error) { return 0, e.err }
@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/routetable"
+)
+
+func TestMirrorSubpath(t *testing.T) {
+	t.Parallel()
+	prefix := func(p string, keep bool) *fv1.HTTPTrigger {
+		return &fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{Prefix: &p, KeepPrefix: keep}}
+	}
+	for _, tc := range []struct {
+		name    string
+		trigger *fv1.HTTPTrigger
+		path    string
+		want    string
+	}{
+		{name: "relative url", trigger: &fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{RelativeURL: "/catalog"}}, path: "/catalog", want: ""},
+		{name: "prefix trimmed", trigger: prefix("/api", false), path: "/api/items/1", want: "/items/1"},
+		{name: "prefix with slash trimmed", trigger: prefix("/api/", false), path: "/api/items", want: "/items"},
+		{name: "prefix itself", trigger: prefix("/api", false), path: "/api", want: ""},
+		{name: "prefix kept", trigger: prefix("/api", true), path: "/api/items", want: "/api/items"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, mirrorSubpath(tc.trigger, tc.path))
+		})
+	}
+}
+
+func TestCompareMirror(t *testing.T) {
+	t.Parallel()
+	ok := func(status int, digest string) mirrorResult {
+		return mirrorResult{status: status, digest: []byte(digest), complete: true}
+	}
+	assert.Equal(t, mirrorDiffMatch, compareMirror(ok(200, "a"), ok(200, "a")))
+	assert.Equal(t, mirrorDiffStatus, compareMirror(ok(200, "a"), ok(500, "a")))
+	assert.Equal(t, mirrorDiffBody, compareMirror(ok(200, "a"), ok(200, "b")))
+	assert.Equal(t, mirrorDiffIncomplete, compareMirror(ok(200, "a"), mirrorResult{}))
+	assert.Equal(t, mirrorDiffIncomplete, compareMirror(mirrorResult{status: 200}, ok(200, "a")))
+}
+
+// shadowRequest is what the fake internal listener saw of a shadow copy.
+type shadowRequest struct {
+	path, query, mirrorOf, body string
+}
+
+// mirroringHandler builds a functionHandler for the trigger "ht" that
+// mirrors every request to fn-v2, whose copies land on a fake internal
+// listener reporting them on the returned channel after release is closed.
+func mirroringHandler(t *testing.T, upstream *httptest.Server, maxInFlight int, release <-chan struct{}) (functionHandler, <-chan shadowRequest) {
+	t.Helper()
+	seen := make(chan shadowRequest, 8)
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		<-release
+		seen <- shadowRequest{path: r.URL.Path, query: r.URL.RawQuery, mirrorOf: r.Header.Get(headerMirrorOf), body: string(body)}
+		_, _ = w.Write([]byte("shadow"))
+	}))
+	t.Cleanup(internal.Close)
+
+	fh := newHandlerForUpstream(t, streamingFn("uid-mirror", nil), upstream, 60)
+	fh.httpTrigger = &fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{RelativeURL: "/orders"}}
+	fh.httpTrigger.Name, fh.httpTrigger.Namespace = "ht", "default"
+	fh.mirror = &mirrorTarget{function: "fn", version: "fn-v2", percent: 100}
+	fh.mirrorer = newMirrorer(logr.Discard(), internal.URL, nil, nil, maxInFlight)
+	return fh, seen
+}
+
+// TestFunctionHandler_Mirror: a mirrored request reaches the primary
+// unchanged, and its copy, body included, reaches the shadow version's
+// internal route.
+func TestFunctionHandler_Mirror(t *testing.T) {
+	t.Parallel()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("primary:" + string(body)))
+	}))
+	t.Cleanup(upstream.Close)
+	release := make(chan struct{})
+	close(release)
+	fh, seen := mirroringHandler(t, upstream, 4, release)
+
+	rr := httptest.NewRecorder()
+	fh.handler(rr, httptest.NewRequest(http.MethodPost, "/orders?id=7", strings.NewReader("order")))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "primary:order", rr.Body.String(), "the primary's response is the client's")
+
+	select {
+	case got := <-seen:
+		assert.Equal(t, shadowRequest{path: "/fission-function/default/fn:fn-v2", query: "id=7", mirrorOf: "ht", body: "order"}, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the shadow never received its copy")
+	}
+}
+
+// TestFunctionHandler_MirrorSkips: requests that cannot be copied, because
+// their body is too large or too many copies are outstanding, still reach
+// the primary unchanged.
+func TestFunctionHandler_MirrorSkips(t *testing.T) {
+	t.Parallel()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(http.StatusText(http.StatusOK) + ":" + strings.Repeat("x", int(min(n, 3)))))
+	}))
+	t.Cleanup(upstream.Close)
+	release := make(chan struct{})
+	fh, seen := mirroringHandler(t, upstream, 1, release)
+
+	big := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(strings.Repeat("x", mirrorMaxBodyBytes+1)))
+	rr := httptest.NewRecorder()
+	fh.handler(rr, big)
+	assert.Equal(t, "OK:xxx", rr.Body.String())
+	assert.Empty(t, fh.mirrorer.inFlight, "a body over the limit is not copied")
+
+	// The first copy holds the only in-flight slot until released.
+	fh.handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	rr = httptest.NewRecorder()
+	fh.handler(rr, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("x")))
+	assert.Equal(t, "OK:x", rr.Body.String())
+
+	close(release)
+	select {
+	case got := <-seen:
+		assert.Empty(t, got.body, "only the first, bodiless request was copied")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the shadow never received its copy")
+	}
+	select {
+	case got := <-seen:
+		t.Fatalf("an overloaded request was copied: %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestResolveMirror(t *testing.T) {
+	t.Parallel()
+	fn := resolverFn("hello", "default", "fn-uid", 5, 60)
+	v1 := resolverVersion("hello-v1", "default", "hello", "fn-uid", 5, 1, 60)
+	v2 := resolverVersion("hello-v2", "default", "hello", "fn-uid", 6, 2, 60)
+	other := resolverVersion("other-v1", "default", "other", "other-uid", 1, 1, 60)
+	prod := resolverAlias("prod", "default", "hello", func(a *fv1.FunctionAlias) {
+		a.Spec.Version = "hello-v1"
+		a.Spec.Mirror = &fv1.TrafficMirror{Alias: "next", Percent: 5}
+		a.Generation = 3
+	})
+	next := resolverAlias("next", "default", "hello", func(a *fv1.FunctionAlias) {
+		a.Spec.Version = "hello-v2"
+		a.Generation = 7
+	})
+	frr := newResolver(t, fn, v1, v2, other, prod, next)
+
+	trigger := func(ref fv1.FunctionReference, m *fv1.TrafficMirror) fv1.HTTPTrigger {
+		ht := fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{FunctionReference: ref, Mirror: m}}
+		ht.Name, ht.Namespace = "ht", "default"
+		return ht
+	}
+	plain := fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "hello"}
+	viaProd := fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "hello", Alias: "prod"}
+
+	for _, tc := range []struct {
+		name      string
+		trigger   fv1.HTTPTrigger
+		want      *mirrorTarget
+		aliasGens map[string]int64
+	}{
+		{name: "no mirror", trigger: trigger(plain, nil)},
+		{
+			name:    "trigger version",
+			trigger: trigger(plain, &fv1.TrafficMirror{Version: "hello-v2", Percent: 10}),
+			want:    &mirrorTarget{function: "hello", version: "hello-v2", percent: 10},
+		},
+		{
+			name:      "trigger alias",
+			trigger:   trigger(plain, &fv1.TrafficMirror{Alias: "next", Percent: 10}),
+			want:      &mirrorTarget{function: "hello", version: "hello-v2", percent: 10},
+			aliasGens: map[string]int64{"next": 7},
+		},
+		{
+			name:      "the alias's own mirror",
+			trigger:   trigger(viaProd, nil),
+			want:      &mirrorTarget{function: "hello", version: "hello-v2", percent: 5},
+			aliasGens: map[string]int64{"prod": 3, "next": 7},
+		},
+		{
+			name:      "the trigger's mirror wins",
+			trigger:   trigger(viaProd, &fv1.TrafficMirror{Version: "hello-v1", Percent: 50}),
+			want:      &mirrorTarget{function: "hello", version: "hello-v1", percent: 50},
+			aliasGens: map[string]int64{"prod": 3},
+		},
+		{name: "missing version", trigger: trigger(plain, &fv1.TrafficMirror{Version: "hello-v9", Percent: 10})},
+		{name: "another function's version", trigger: trigger(plain, &fv1.TrafficMirror{Version: "other-v1", Percent: 10})},
+		{
+			name:      "missing alias is watched",
+			trigger:   trigger(plain, &fv1.TrafficMirror{Alias: "beta", Percent: 10}),
+			aliasGens: map[string]int64{"beta": 0},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			rr, err := frr.resolve(t.Context(), tc.trigger)
+			require.NoError(t, err, "a mirror never fails the primary's resolve")
+			assert.Equal(t, tc.want, rr.mirror)
+			assert.Equal(t, tc.aliasGens, rr.AliasGens)
+			primary := routetable.BackendKey("hello", "")
+			if tc.trigger.Spec.FunctionReference.Alias != "" {
+				primary = routetable.BackendKey("hello", "hello-v1")
+			}
+			assert.Contains(t, rr.functionMap, primary, "the primary resolves as it would unmirrored")
+		})
+	}
+}
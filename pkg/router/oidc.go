@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/sync/singleflight"
+
+	config "github.com/fission/fission/pkg/featureconfig"
+)
+
+const (
+	// jwksRefreshInterval is how long a fetched key set is trusted before the
+	// next token verification refetches it, picking up keys the issuer has
+	// rotated in or out.
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefetchInterval bounds how often a token signed with an unknown
+	// key id triggers an early refetch, so a stream of forged kids cannot
+	// turn the router into a load generator against the issuer.
+	jwksMinRefetchInterval = 30 * time.Second
+	// jwksFetchTimeout bounds one discovery or JWKS request.
+	jwksFetchTimeout = 10 * time.Second
+	// jwksMaxBody caps the discovery and JWKS documents read.
+	jwksMaxBody = 1 << 20
+)
+
+// oidcSigningMethods are the algorithms accepted from an OIDC issuer. HMAC is
+// deliberately absent: an issuer's public key must never double as a shared
+// secret.
+var oidcSigningMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+
+// oidcVerifier verifies tokens issued by one OpenID Connect provider against
+// its published key set. Keys are fetched lazily on first use and cached;
+// a token naming an unknown key id refetches early (rate-limited) so a key
+// rotation is picked up without waiting out jwksRefreshInterval. A failed
+// refetch keeps serving the previous keys. Fetches run outside mu, one at
+// a time through group, so a slow issuer never blocks tokens signed with a
+// cached key.
+type oidcVerifier struct {
+	logger    logr.Logger
+	issuer    string
+	audiences []string
+	client    *http.Client
+	now       func() time.Time
+	group     singleflight.Group
+
+	mu sync.RWMutex
+	// jwksURL is the configured URL, or the one discovered from the issuer.
+	jwksURL     string
+	keys        map[string]any
+	fetched     time.Time
+	lastAttempt time.Time
+}
+
+func newOIDCVerifier(logger logr.Logger, cfg *config.OIDCFeatureConfig) *oidcVerifier {
+	return &oidcVerifier{
+		logger:    logger,
+		issuer:    cfg.IssuerURL,
+		audiences: cfg.Audiences,
+		jwksURL:   cfg.JWKSURL,
+		client:    &http.Client{Timeout: jwksFetchTimeout},
+		now:       time.Now,
+	}
+}
+
+// verify checks raw's signature, expiry, issuer and audience, returning its
+// claims.
+func (v *oidcVerifier) verify(ctx context.Context, raw string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(oidcSigningMethods))
+	_, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// MapClaims.Valid treats a missing exp as never expiring; an OIDC token
+	// always carries one, so its absence is an error here.
+	if !claims.VerifyExpiresAt(v.now().Unix(), true) {
+		return nil, errExpiredToken
+	}
+	if !claims.VerifyIssuer(v.issuer, true) {
+		return nil, errors.New("unauthorized: token issuer not accepted")
+	}
+	if len(v.audiences) > 0 && !v.audienceAccepted(claims) {
+		return nil, errors.New("unauthorized: token audience not accepted")
+	}
+	return claims, nil
+}
+
+func (v *oidcVerifier) audienceAccepted(claims jwt.MapClaims) bool {
+	for _, aud := range v.audiences {
+		if claims.VerifyAudience(aud, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the public key named kid. A token without a kid is accepted
+// only when the key set holds exactly one key.
+func (v *oidcVerifier) key(ctx context.Context, kid string) (any, error) {
+	k, known, stale := v.lookup(kid)
+	if !known || stale {
+		// Waiters share the one fetch; the caller's cancellation must not
+		// fail it for the others.
+		_, _, _ = v.group.Do("jwks", func() (any, error) {
+			v.maybeRefresh(context.WithoutCancel(ctx))
+			return nil, nil
+		})
+		k, known, _ = v.lookup(kid)
+	}
+	if known {
+		return k, nil
+	}
+	return nil, fmt.Errorf("no signing key %q for issuer %s", kid, v.issuer)
+}
+
+// lookup returns the cached key named kid and whether the set is due a
+// refresh.
+func (v *oidcVerifier) lookup(kid string) (key any, known, stale bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	stale = v.now().Sub(v.fetched) >= jwksRefreshInterval
+	if k, ok := v.keys[kid]; ok {
+		return k, true, stale
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, true, stale
+		}
+	}
+	return nil, false, stale
+}
+
+// maybeRefresh refetches the key set unless the last attempt was under
+// jwksMinRefetchInterval ago.
+func (v *oidcVerifier) maybeRefresh(ctx context.Context) {
+	v.mu.Lock()
+	now := v.now()
+	if now.Sub(v.lastAttempt) < jwksMinRefetchInterval {
+		v.mu.Unlock()
+		return
+	}
+	v.lastAttempt = now
+	v.mu.Unlock()
+	if err := v.refresh(ctx); err != nil {
+		v.logger.Error(err, "error refreshing OIDC signing keys; using the cached set", "issuer", v.issuer)
+	}
+}
+
+// refresh refetches the key set, discovering its URL first when none is
+// known. Called without v.mu held; it takes the lock only to publish.
+func (v *oidcVerifier) refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+	v.mu.RLock()
+	jwksURL := v.jwksURL
+	v.mu.RUnlock()
+	if jwksURL == "" {
+		var doc struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, strings.TrimSuffix(v.issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+			return fmt.Errorf("OIDC discovery: %w", err)
+		}
+		if doc.Issuer != v.issuer {
+			return fmt.Errorf("OIDC discovery: document is for issuer %q, want %q", doc.Issuer, v.issuer)
+		}
+		if doc.JWKSURI == "" {
+			return errors.New("OIDC discovery: document has no jwks_uri")
+		}
+		jwksURL = doc.JWKSURI
+		v.mu.Lock()
+		v.jwksURL = jwksURL
+		v.mu.Unlock()
+	}
+	var set jwkSet
+	if err := v.getJSON(ctx, jwksURL, &set); err != nil {
+		return fmt.Errorf("fetching JWKS: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			v.logger.V(1).Info("skipping unusable JWKS key", "issuer", v.issuer, "kid", k.Kid, "error", err.Error())
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return errors.New("fetching JWKS: no usable signing keys")
+	}
+	v.mu.Lock()
+	v.keys, v.fetched = keys, v.now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, into any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, jwksMaxBody)).Decode(into)
+}
+
+// jwkSet is a JSON Web Key Set (RFC 7517) restricted to the members needed
+// for RSA and P-256 public keys.
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		// Build the uncompressed point so the stdlib validates it is on
+		// the curve.
+		point := append([]byte{4}, append(leftPad(x, 32), leftPad(y, 32)...)...)
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return nil, err
+		}
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func leftPad(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b
+	}
+	return append(make([]byte, n-len(b)), b...)
+}
@@ -0,0 +1,329 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	config "github.com/fission/fission/pkg/featureconfig"
+)
+
+// jwksStub is a local OIDC issuer: a discovery document and a JWKS whose
+// keys the test can rotate.
+type jwksStub struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []jwk
+	fetches atomic.Int32
+}
+
+func newJWKSStub(t *testing.T) *jwksStub {
+	s := &jwksStub{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": s.URL, "jwks_uri": s.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(jwkSet{Keys: s.keys})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksStub) publish(keys ...jwk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func rsaJWK(t *testing.T, kid string) (*rsa.PrivateKey, jwk) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key, jwk{Kty: "RSA", Kid: kid, Use: "sig", N: b64(key.N.Bytes()), E: b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func ecJWK(t *testing.T, kid string) (*ecdsa.PrivateKey, jwk) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	point, err := key.PublicKey.Bytes()
+	require.NoError(t, err)
+	return key, jwk{Kty: "EC", Kid: kid, Crv: "P-256", X: b64(point[1:33]), Y: b64(point[33:])}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestOIDCVerifier(t *testing.T) {
+	stub := newJWKSStub(t)
+	rsaKey, rsaPub := rsaJWK(t, "rsa-1")
+	ecKey, ecPub := ecJWK(t, "ec-1")
+	stub.publish(rsaPub, ecPub)
+	v := newOIDCVerifier(logr.Discard(), &config.OIDCFeatureConfig{IssuerURL: stub.URL, Audiences: []string{"fission"}})
+
+	claims := func(mut func(jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{"iss": stub.URL, "aud": "fission", "sub": "alice", "exp": time.Now().Add(time.Minute).Unix()}
+		if mut != nil {
+			mut(c)
+		}
+		return c
+	}
+	for name, tc := range map[string]struct {
+		token   string
+		wantErr bool
+	}{
+		"RS256":           {token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil))},
+		"ES256":           {token: signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, claims(nil))},
+		"audience list":   {token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(func(c jwt.MapClaims) { c["aud"] = []any{"other", "fission"} }))},
+		"wrong audience":  {token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(func(c jwt.MapClaims) { c["aud"] = "other" })), wantErr: true},
+		"wrong issuer":    {token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(func(c jwt.MapClaims) { c["iss"] = "https://evil.example" })), wantErr: true},
+		"expired":         {token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() })), wantErr: true},
+		"no expiry":       {token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(func(c jwt.MapClaims) { delete(c, "exp") })), wantErr: true},
+		"key mismatch":    {token: signToken(t, jwt.SigningMethodRS256, "ec-1", rsaKey, claims(nil)), wantErr: true},
+		"HS256 forgery":   {token: signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte(rsaPub.N), claims(nil)), wantErr: true},
+		"unsigned (none)": {token: signToken(t, jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType, claims(nil)), wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := v.verify(t.Context(), tc.token)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alice", got["sub"])
+		})
+	}
+	assert.EqualValues(t, 1, stub.fetches.Load(), "keys are cached across verifications")
+}
+
+func TestOIDCVerifierKeyRotation(t *testing.T) {
+	stub := newJWKSStub(t)
+	oldKey, oldPub := rsaJWK(t, "old")
+	newKey, newPub := rsaJWK(t, "new")
+	stub.publish(oldPub)
+	v := newOIDCVerifier(logr.Discard(), &config.OIDCFeatureConfig{IssuerURL: stub.URL, JWKSURL: stub.URL + "/keys"})
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	claims := jwt.MapClaims{"iss": stub.URL, "exp": now.Add(2 * jwksRefreshInterval).Unix()}
+
+	_, err := v.verify(t.Context(), signToken(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	require.NoError(t, err)
+
+	stub.publish(newPub)
+	_, err = v.verify(t.Context(), signToken(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	require.Error(t, err, "an unknown kid refetches at most every jwksMinRefetchInterval")
+
+	now = now.Add(jwksMinRefetchInterval)
+	_, err = v.verify(t.Context(), signToken(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	require.NoError(t, err, "the rotated key is picked up")
+	_, err = v.verify(t.Context(), signToken(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	require.Error(t, err, "the retired key is gone")
+
+	stub.Close()
+	now = now.Add(jwksRefreshInterval)
+	_, err = v.verify(t.Context(), signToken(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	require.NoError(t, err, "a failed refresh keeps serving the cached keys")
+}
+
+// TestOIDCVerifierFetchOutsideLock pins that a JWKS fetch in flight, here
+// one a token with an unknown kid started against a hung issuer, does not
+// hold up tokens signed with a cached key.
+func TestOIDCVerifierFetchOutsideLock(t *testing.T) {
+	stub := newJWKSStub(t)
+	key, pub := rsaJWK(t, "k")
+	stub.publish(pub)
+	release := make(chan struct{})
+	hung := make(chan struct{})
+	var once sync.Once
+	keys := stub.URL + "/keys"
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stub.fetches.Load() > 0 {
+			once.Do(func() { close(hung) })
+			<-release
+		}
+		http.Redirect(w, r, keys, http.StatusFound)
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+	v := newOIDCVerifier(logr.Discard(), &config.OIDCFeatureConfig{IssuerURL: stub.URL, JWKSURL: slow.URL})
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	claims := jwt.MapClaims{"iss": stub.URL, "exp": now.Add(time.Minute).Unix()}
+	known := signToken(t, jwt.SigningMethodRS256, "k", key, claims)
+
+	_, err := v.verify(t.Context(), known)
+	require.NoError(t, err)
+
+	unknown := signToken(t, jwt.SigningMethodRS256, "unknown", key, claims)
+	now = now.Add(jwksMinRefetchInterval)
+	go func() { _, _ = v.verify(t.Context(), unknown) }()
+	<-hung
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := v.verify(t.Context(), known)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a cached key waited on a JWKS fetch")
+	}
+}
+
+func TestAuthMiddlewareOIDC(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "login-secret")
+	stub := newJWKSStub(t)
+	key, pub := rsaJWK(t, "k")
+	stub.publish(pub)
+
+	cfg := config.AuthFeatureConfig{
+		AuthUriPath:   "/auth/login",
+		OIDC:          &config.OIDCFeatureConfig{IssuerURL: stub.URL},
+		ForwardClaims: []string{"sub", "groups", "https://example.com/tenant"},
+	}
+	var seen http.Header
+	h := authMiddleware(newAuthenticator(logr.Discard(), cfg))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+		_, ok := authClaims(r)
+		assert.True(t, ok)
+	}))
+	call := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Fission-Claim-Role", "admin")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(signToken(t, jwt.SigningMethodRS256, "k", key, jwt.MapClaims{
+		"iss": stub.URL, "exp": time.Now().Add(time.Minute).Unix(), "sub": "alice",
+		"groups": []any{"dev", "ops"}, "https://example.com/tenant": "acme",
+	}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "alice", seen.Get("X-Fission-Claim-Sub"))
+	assert.Equal(t, "dev,ops", seen.Get("X-Fission-Claim-Groups"))
+	assert.Equal(t, "acme", seen.Get("X-Fission-Claim-Https---Example-Com-Tenant"))
+	assert.Empty(t, seen.Get("X-Fission-Claim-Role"), "caller-supplied claim headers are dropped")
+
+	rec = call(signToken(t, jwt.SigningMethodHS256, "", []byte("login-secret"), jwt.MapClaims{"sub": "bob"}))
+	assert.Equal(t, http.StatusOK, rec.Code, "router login tokens keep working alongside OIDC")
+	assert.Equal(t, "bob", seen.Get("X-Fission-Claim-Sub"))
+
+	rec = call(signToken(t, jwt.SigningMethodHS256, "", []byte("guess"), jwt.MapClaims{"sub": "mallory"}))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthenticatorWithoutSigningKeyRejectsLoginTokens(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "")
+	a := newAuthenticator(logr.Discard(), config.AuthFeatureConfig{})
+	req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.SigningMethodHS256, "", []byte(""), jwt.MapClaims{}))
+	_, err := a.authenticate(req)
+	assert.Error(t, err)
+}
+
+func TestAuthorized(t *testing.T) {
+	t.Parallel()
+	claims := jwt.MapClaims{
+		"scope":  "fn:read fn:invoke",
+		"groups": []any{"dev", "ops"},
+		"tier":   "gold",
+		"admin":  true,
+	}
+	for name, tc := range map[string]struct {
+		az   fv1.HTTPTriggerAuthorization
+		want bool
+	}{
+		"empty":             {want: true},
+		"scopes granted":    {az: fv1.HTTPTriggerAuthorization{Scopes: []string{"fn:invoke", "fn:read"}}, want: true},
+		"scope missing":     {az: fv1.HTTPTriggerAuthorization{Scopes: []string{"fn:write"}}},
+		"claim value":       {az: fv1.HTTPTriggerAuthorization{Claims: []fv1.ClaimRequirement{{Name: "tier", Values: []string{"silver", "gold"}}}}, want: true},
+		"claim wrong value": {az: fv1.HTTPTriggerAuthorization{Claims: []fv1.ClaimRequirement{{Name: "tier", Values: []string{"silver"}}}}},
+		"list claim":        {az: fv1.HTTPTriggerAuthorization{Claims: []fv1.ClaimRequirement{{Name: "groups", Values: []string{"ops"}}}}, want: true},
+		"boolean claim":     {az: fv1.HTTPTriggerAuthorization{Claims: []fv1.ClaimRequirement{{Name: "admin", Values: []string{"true"}}}}, want: true},
+		"claim present":     {az: fv1.HTTPTriggerAuthorization{Claims: []fv1.ClaimRequirement{{Name: "groups"}}}, want: true},
+		"claim absent":      {az: fv1.HTTPTriggerAuthorization{Claims: []fv1.ClaimRequirement{{Name: "org"}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, authorized(&tc.az, claims))
+		})
+	}
+	assert.True(t, authorized(&fv1.HTTPTriggerAuthorization{Scopes: []string{"b"}}, jwt.MapClaims{"scp": []any{"a", "b"}}), "scp list")
+}
+
+// TestFunctionHandler_Authorization: a trigger's Authorization is checked
+// against the claims the auth middleware verified, before the function is
+// resolved.
+func TestFunctionHandler_Authorization(t *testing.T) {
+	t.Parallel()
+	fh := &functionHandler{
+		logger:           logr.Discard(),
+		function:         &fv1.Function{ObjectMeta: metav1.ObjectMeta{Name: "fn", Namespace: "default"}},
+		structuredErrors: true,
+		httpTrigger: &fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{
+			Authorization: &fv1.HTTPTriggerAuthorization{Scopes: []string{"fn:invoke"}},
+		}},
+	}
+	serve := func(claims jwt.MapClaims) (int, ferror.InvocationError) {
+		req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+		if claims != nil {
+			req = req.WithContext(withAuthClaims(req.Context(), claims))
+		}
+		rec := httptest.NewRecorder()
+		fh.handler(rec, req)
+		var body ferror.InvocationError
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	code, body := serve(nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, ferror.ReasonUnauthenticated, body.Reason)
+
+	code, body = serve(jwt.MapClaims{"scope": "fn:read"})
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, ferror.ReasonForbidden, body.Reason)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fn", nil)
+	assert.True(t, fh.admitAuthorized(rec, req.WithContext(withAuthClaims(req.Context(), jwt.MapClaims{"scope": "fn:invoke"}))))
+}
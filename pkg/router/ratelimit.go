@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/router/ratelimit"
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// routeRateLimit is the rate limit an HTTPTrigger route enforces: the
+// trigger's own RateLimit, else its function's default. The buckets belong to
+// whichever object declared the limit, so a function's default is shared by
+// every trigger that falls back to it.
+type routeRateLimit struct {
+	namespace string
+	owner     string
+	limit     ratelimit.Limit
+	key       *fv1.RateLimitKey
+}
+
+// rateLimitFor resolves a trigger route's rate limit at mux build. fn is the
+// function whose default applies (the resolution's stickySource: the single
+// resolved function, or the live function behind an alias); nil for the
+// legacy weighted canary, whose backends have no single default.
+func rateLimitFor(trigger *fv1.HTTPTrigger, fn *fv1.Function) *routeRateLimit {
+	if rl := trigger.Spec.RateLimit; rl != nil {
+		return &routeRateLimit{namespace: trigger.Namespace, owner: "httptrigger/" + trigger.Name, limit: ratelimit.LimitFor(rl), key: rl.Key}
+	}
+	if fn != nil && fn.Spec.RateLimit != nil {
+		rl := fn.Spec.RateLimit
+		return &routeRateLimit{namespace: fn.Namespace, owner: "function/" + fn.Name, limit: ratelimit.LimitFor(rl), key: rl.Key}
+	}
+	return nil
+}
+
+// admitRateLimited takes a token for req from the route's rate limit. When
+// the bucket is empty it answers 429 with Retry-After in whole seconds,
+// rounded up, and returns false. A jwtClaim key reads only the claims the
+// auth middleware verified.
+func (fh functionHandler) admitRateLimited(rw http.ResponseWriter, req *http.Request) bool {
+	rl := fh.rateLimit
+	claims, _ := authClaims(req)
+	res := fh.rateLimiter.Allow(req.Context(), ratelimit.Bucket{
+		Namespace: rl.namespace,
+		Owner:     rl.owner,
+		Key:       ratelimit.RequestKey(rl.key, req, claims, fh.trustedProxies),
+	}, rl.limit)
+	if res.Allowed {
+		return true
+	}
+	retryAfter := max(int64(math.Ceil(res.RetryAfter.Seconds())), 1)
+	rw.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	fh.writeInvocationError(rw, req, http.StatusTooManyRequests, ferror.ComponentRouter, ferror.ReasonRateLimited, errRateLimited.Error(), errRateLimited)
+	return false
+}
+
+// asyncDeliveryRate paces a function's async deliveries at perSecond through
+// the router's rate-limit buckets, so the cap holds across replicas. The
+// bucket is the function's own, keyed apart from its request-rate buckets.
+func asyncDeliveryRate(limiter *ratelimit.Limiter) asyncinvoke.RateFunc {
+	return func(ctx context.Context, namespace, function string, perSecond int) (bool, time.Duration) {
+		res := limiter.Take(ctx, ratelimit.Bucket{
+			Namespace: namespace,
+			Owner:     "function/" + function,
+			Key:       "async-delivery",
+		}, ratelimit.Limit{Interval: time.Second / time.Duration(perSecond), Burst: int64(perSecond)})
+		return res.Allowed, res.RetryAfter
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+)
+
+// RequestKey returns the bucket key of r under k. A nil k keys every request
+// the same; a request missing the declared header or claim is keyed by its
+// client IP, so leaving the header off does not escape the limit. Keys are
+// prefixed with their source so a header value can never share a bucket with
+// an IP.
+//
+// claims are the token claims the router's auth middleware verified, nil when
+// it verified none. A jwtClaim key reads only those: an unverified token is
+// free for the client to mint afresh on every request, so it would be a
+// fresh bucket every time.
+func RequestKey(k *fv1.RateLimitKey, r *http.Request, claims map[string]any, proxies TrustedProxies) string {
+	if k == nil {
+		return ""
+	}
+	switch k.Source {
+	case fv1.RateLimitKeyHeader:
+		if v := r.Header.Get(k.Name); v != "" {
+			return "header:" + v
+		}
+	case fv1.RateLimitKeyJWTClaim:
+		if v := claimString(claims, k.Name); v != "" {
+			return "claim:" + v
+		}
+	}
+	return "ip:" + proxies.ClientIP(r)
+}
+
+// TrustedProxies are the networks of the proxies in front of the router,
+// whose X-Forwarded-For it believes.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs; a bare address
+// is a single-address network.
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !strings.Contains(f, "/") {
+			addr, err := netip.ParseAddr(f)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", f, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(f)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", f, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// trusts reports whether addr is inside one of the proxies' networks.
+func (p TrustedProxies) trusts(addr string) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP is the connection's peer, unless that peer is a trusted proxy: then
+// it is the address the proxy saw the request come from (the last
+// X-Forwarded-For entry). A client reaching the router directly can write any
+// X-Forwarded-For it likes, and earlier entries are written by the client
+// even through a proxy, so neither is believed.
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !p.trusts(peer) {
+		return peer
+	}
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(xff[len(xff)-1], ",")
+		if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+			return ip
+		}
+	}
+	return peer
+}
+
+// claimString renders a verified claim as a key: strings as they are,
+// anything else formatted.
+func claimString(claims map[string]any, claim string) string {
+	switch v := claims[claim].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
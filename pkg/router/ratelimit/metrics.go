@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/fission/fission/pkg/utils/metrics"
+)
+
+var (
+	// rejections counts requests answered 429, labelled by the namespace and
+	// owner ("httptrigger/<name>" or "function/<name>") of the limit that
+	// rejected them. Bucket keys are deliberately not a label: they are
+	// per-client and unbounded.
+	rejections = metrics.Int64Counter(
+		"fission_router_ratelimit_rejections_total",
+		"Requests rejected with 429 by an HTTPTrigger or Function rate limit.",
+	)
+	// storeErrors counts decisions the statestore could not make (error,
+	// timeout or a bucket contended past the CAS retries) and that fell back
+	// to the replica's own buckets. Sustained errors mean the limit is being
+	// enforced per replica rather than cluster-wide.
+	storeErrors = metrics.Int64Counter(
+		"fission_router_ratelimit_store_errors_total",
+		"Rate-limit decisions that fell back to per-replica buckets because the statestore failed.",
+	)
+)
+
+func recordRejection(b Bucket) {
+	rejections.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("namespace", b.Namespace),
+		attribute.String("owner", b.Owner),
+	))
+}
+
+func recordStoreError() { storeErrors.Add(context.Background(), 1) }
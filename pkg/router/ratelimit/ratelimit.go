@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ratelimit enforces the router's request-rate limits
+// (fv1.RateLimitConfig on HTTPTriggers and Functions). A limit is a token
+// bucket kept in GCRA form: the only state per bucket is its theoretical
+// arrival time (TAT), the instant the bucket will be full again. That single
+// timestamp fits a statestore KV value, so replicas share a bucket by
+// compare-and-swapping it. Without a store, or when it fails, each replica
+// keeps its own buckets in memory.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// kvKeyspace holds the buckets in the statestore, under the namespace and
+	// owner of the trigger or function that declares the limit.
+	kvKeyspace = "ratelimit"
+	// kvTimeout bounds one bucket update against the store; a slower store
+	// is treated as failed and the request is decided locally.
+	kvTimeout = 200 * time.Millisecond
+	// kvAttempts bounds the compare-and-swap retries on a contended bucket.
+	kvAttempts = 3
+	// maxContendedRetry caps the Retry-After of a request rejected because
+	// its bucket stayed contended; it is otherwise one Interval, the time
+	// the bucket takes to gain a token.
+	maxContendedRetry = time.Second
+	// sweepInterval is how often the memory store drops buckets that have
+	// refilled completely (a full bucket and an absent one are the same).
+	sweepInterval = time.Minute
+)
+
+// Limit is a token bucket in GCRA form: each request costs Interval, and up to
+// Burst requests may arrive back to back.
+type Limit struct {
+	Interval time.Duration
+	Burst    int64
+}
+
+// LimitFor converts a RateLimitConfig, applying its defaults: a 1s period and
+// a burst of Requests.
+func LimitFor(c *fv1.RateLimitConfig) Limit {
+	period := time.Second
+	if c.Period != nil && c.Period.Duration > 0 {
+		period = c.Period.Duration
+	}
+	requests := max(int64(c.Requests), 1)
+	burst := int64(c.Burst)
+	if burst <= 0 {
+		burst = requests
+	}
+	return Limit{Interval: max(period/time.Duration(requests), 1), Burst: burst}
+}
+
+// Bucket names one token bucket: the object declaring the limit
+// ("httptrigger/<name>" or "function/<name>" in Namespace) and the request
+// key it was taken for.
+type Bucket struct {
+	Namespace string
+	Owner     string
+	Key       string
+}
+
+func (b Bucket) String() string {
+	return b.Namespace + "/" + b.Owner + "/" + b.Key
+}
+
+// Result is the decision for one request. RetryAfter is set when the request
+// is rejected: the time until the bucket holds a token again.
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// take applies one request to a bucket whose TAT is tat, returning the
+// decision and the TAT to store when the request is allowed.
+func take(l Limit, tat, now time.Time) (Result, time.Time) {
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(l.Interval)
+	tolerance := time.Duration(l.Burst) * l.Interval
+	if wait := next.Sub(now) - tolerance; wait > 0 {
+		return Result{RetryAfter: wait}, tat
+	}
+	return Result{Allowed: true}, next
+}
+
+// Store holds bucket state.
+type Store interface {
+	Take(ctx context.Context, b Bucket, l Limit, now time.Time) (Result, error)
+}
+
+// MemoryStore keeps buckets in process: each replica enforces the limit on
+// its own share of the traffic.
+type MemoryStore struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tats: map[string]time.Time{}}
+}
+
+// Take never fails.
+func (m *MemoryStore) Take(_ context.Context, b Bucket, l Limit, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.After(m.nextSweep) {
+		for k, tat := range m.tats {
+			if !tat.After(now) {
+				delete(m.tats, k)
+			}
+		}
+		m.nextSweep = now.Add(sweepInterval)
+	}
+	key := b.String()
+	res, tat := take(l, m.tats[key], now)
+	if res.Allowed {
+		m.tats[key] = tat
+	}
+	return res, nil
+}
+
+// KVStore keeps buckets in the statestore so every router replica draws from
+// the same one. Each decision is a read and a compare-and-swap; the key
+// expires once the bucket has refilled, so idle keys cost no storage.
+// Replicas' clocks are assumed to agree to well within one Interval.
+type KVStore struct {
+	kv statestore.KVStore
+}
+
+// NewKVStore returns a KVStore over kv.
+func NewKVStore(kv statestore.KVStore) *KVStore {
+	return &KVStore{kv: kv}
+}
+
+// Take returns statestore errors as they are, and an error wrapping
+// statestore.ErrVersionConflict when the bucket stays contended.
+func (s *KVStore) Take(ctx context.Context, b Bucket, l Limit, now time.Time) (Result, error) {
+	scope := statestore.Scope{Namespace: b.Namespace, Owner: b.Owner, Keyspace: kvKeyspace}
+	// Request keys are caller-controlled (a header, a claim), so the stored
+	// key is their digest: bounded length and a safe charset.
+	sum := sha256.Sum256([]byte(b.Key))
+	key := hex.EncodeToString(sum[:16])
+	for range kvAttempts {
+		var (
+			tat     time.Time
+			version int64
+		)
+		v, err := s.kv.Get(ctx, scope, key)
+		switch {
+		case err == nil:
+			nanos, perr := strconv.ParseInt(string(v.Data), 10, 64)
+			if perr != nil {
+				return Result{}, perr
+			}
+			tat, version = time.Unix(0, nanos), v.Version
+		case !errors.Is(err, statestore.ErrNotFound):
+			return Result{}, err
+		}
+		res, next := take(l, tat, now)
+		if !res.Allowed {
+			return res, nil
+		}
+		// The TTL is rounded up to a second so a coarse-grained driver never
+		// expires the key before the bucket has actually refilled.
+		ttl := next.Sub(now).Truncate(time.Second) + time.Second
+		err = s.kv.Set(ctx, scope, key, []byte(strconv.FormatInt(next.UnixNano(), 10)), statestore.SetOptions{IfVersion: &version, TTL: ttl})
+		if err == nil {
+			return res, nil
+		}
+		if !errors.Is(err, statestore.ErrVersionConflict) {
+			return Result{}, err
+		}
+	}
+	return Result{}, statestore.ErrVersionConflict
+}
+
+// Limiter decides requests against the statestore when it has one, falling
+// back to its own MemoryStore for a request the store fails on, so a
+// statestore outage degrades the limit to per-replica rather than failing
+// requests or lifting the limit altogether. A bucket that stays contended is
+// not an outage: it is the busiest traffic the limit exists for, so the
+// request is rejected rather than granted a fresh local allowance.
+type Limiter struct {
+	logger logr.Logger
+	// shared is nil when the router runs without the statestore.
+	shared Store
+	local  *MemoryStore
+	now    func() time.Time
+}
+
+// New returns a Limiter over the statestore's KV, or over process memory
+// alone when kv is nil.
+func New(logger logr.Logger, kv statestore.KVStore) *Limiter {
+	l := &Limiter{logger: logger, local: NewMemoryStore(), now: time.Now}
+	if kv != nil {
+		l.shared = NewKVStore(kv)
+	}
+	return l
+}
+
+// Allow takes one token from b under lim, counting a rejection in the
+// router's metrics.
+func (l *Limiter) Allow(ctx context.Context, b Bucket, lim Limit) Result {
+	return counted(b, l.Take(ctx, b, lim))
+}
+
+// Take takes one token from b under lim without counting a rejection, for
+// callers that pace work rather than reject requests (async delivery caps).
+func (l *Limiter) Take(ctx context.Context, b Bucket, lim Limit) Result {
+	now := l.now()
+	if l.shared != nil {
+		kctx, cancel := context.WithTimeout(ctx, kvTimeout)
+		res, err := l.shared.Take(kctx, b, lim, now)
+		cancel()
+		if err == nil {
+			return res
+		}
+		if errors.Is(err, statestore.ErrVersionConflict) {
+			return Result{RetryAfter: min(lim.Interval, maxContendedRetry)}
+		}
+		recordStoreError()
+		l.logger.V(1).Info("rate limit store failed; deciding locally", "namespace", b.Namespace, "owner", b.Owner, "error", err.Error())
+	}
+	res, _ := l.local.Take(ctx, b, lim, now)
+	return res
+}
+
+func counted(b Bucket, res Result) Result {
+	if !res.Allowed {
+		recordRejection(b)
+	}
+	return res
+}
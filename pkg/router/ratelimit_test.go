@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/router/ratelimit"
+)
+
+func TestRateLimitFor(t *testing.T) {
+	t.Parallel()
+	fnLimit := &fv1.RateLimitConfig{Requests: 5}
+	fn := &fv1.Function{ObjectMeta: metav1.ObjectMeta{Name: "fn", Namespace: "default"}, Spec: fv1.FunctionSpec{RateLimit: fnLimit}}
+	trigger := &fv1.HTTPTrigger{ObjectMeta: metav1.ObjectMeta{Name: "ht", Namespace: "default"}}
+
+	rl := rateLimitFor(trigger, fn)
+	require.NotNil(t, rl)
+	assert.Equal(t, "function/fn", rl.owner, "a trigger without a limit falls back to its function's")
+
+	trigger.Spec.RateLimit = &fv1.RateLimitConfig{Requests: 1, Key: &fv1.RateLimitKey{Source: fv1.RateLimitKeyClientIP}}
+	rl = rateLimitFor(trigger, fn)
+	require.NotNil(t, rl)
+	assert.Equal(t, "httptrigger/ht", rl.owner, "the trigger's own limit wins")
+	assert.Equal(t, fv1.RateLimitKeyClientIP, rl.key.Source)
+
+	assert.Nil(t, rateLimitFor(&fv1.HTTPTrigger{}, nil))
+}
+
+// TestFunctionHandler_RateLimited: once a client's bucket is empty the router
+// answers 429 with Retry-After, attributed to itself.
+func TestFunctionHandler_RateLimited(t *testing.T) {
+	t.Parallel()
+	fh := &functionHandler{
+		logger:           logr.Discard(),
+		function:         &fv1.Function{ObjectMeta: metav1.ObjectMeta{Name: "fn", Namespace: "default"}},
+		structuredErrors: true,
+		rateLimiter:      ratelimit.New(logr.Discard(), nil),
+		rateLimit: &routeRateLimit{
+			namespace: "default",
+			owner:     "httptrigger/ht",
+			limit:     ratelimit.Limit{Interval: 90 * time.Second, Burst: 1},
+			key:       &fv1.RateLimitKey{Source: fv1.RateLimitKeyClientIP},
+		},
+	}
+	req := func(addr string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/fn", nil)
+		r.RemoteAddr = addr
+		return r
+	}
+	require.True(t, fh.admitRateLimited(httptest.NewRecorder(), req("192.0.2.1:1234")))
+
+	rr := httptest.NewRecorder()
+	fh.handler(rr, req("192.0.2.1:5678"))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "90", rr.Header().Get("Retry-After"))
+	var body ferror.InvocationError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, ferror.ComponentRouter, body.Component)
+	assert.Equal(t, ferror.ReasonRateLimited, body.Reason)
+
+	assert.True(t, fh.admitRateLimited(httptest.NewRecorder(), req("192.0.2.2:1234")), "another client has its own bucket")
+}
+
+// TestFunctionHandler_RateLimitForgedClaims: a jwtClaim limit reads only the
+// claims auth verified, so tokens minted with a fresh claim per request all
+// land in their client's IP bucket, while verified callers keep their own.
+func TestFunctionHandler_RateLimitForgedClaims(t *testing.T) {
+	t.Parallel()
+	fh := &functionHandler{
+		logger:           logr.Discard(),
+		function:         &fv1.Function{ObjectMeta: metav1.ObjectMeta{Name: "fn", Namespace: "default"}},
+		structuredErrors: true,
+		rateLimiter:      ratelimit.New(logr.Discard(), nil),
+		rateLimit: &routeRateLimit{
+			namespace: "default",
+			owner:     "httptrigger/ht",
+			limit:     ratelimit.Limit{Interval: time.Minute, Burst: 1},
+			key:       &fv1.RateLimitKey{Source: fv1.RateLimitKeyJWTClaim, Name: "sub"},
+		},
+	}
+	forged := func(sub string) *http.Request {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": sub}).SignedString([]byte("attacker"))
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodGet, "/fn", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return r
+	}
+	require.True(t, fh.admitRateLimited(httptest.NewRecorder(), forged("a")))
+	for _, sub := range []string{"b", "c", "d"} {
+		assert.False(t, fh.admitRateLimited(httptest.NewRecorder(), forged(sub)), "forged sub %q shares the client's bucket", sub)
+	}
+
+	verified := func(sub string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/fn", nil)
+		return r.WithContext(withAuthClaims(r.Context(), jwt.MapClaims{"sub": sub}))
+	}
+	assert.True(t, fh.admitRateLimited(httptest.NewRecorder(), verified("alice")))
+	assert.False(t, fh.admitRateLimited(httptest.NewRecorder(), verified("alice")))
+	assert.True(t, fh.admitRateLimited(httptest.NewRecorder(), verified("bob")), "verified callers have their own buckets")
+}
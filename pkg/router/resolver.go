@@ -76,3 +76,13 @@ type AddressResolver interface {
 	// address had been resolved.
 	Invalidate(fn *fv1.Function, addr *url.URL, reason InvalidateReason)
 }
+
+// OutcomeReporter is the optional AddressResolver facet fed the responses of
+// index-admitted endpoints, for the outlier ejection of Functions with an
+// OutlierEjection block. The transport reports through it only when the
+// resolver implements it.
+type OutcomeReporter interface {
+	// ReportOutcome records that addr answered one of fn's requests with a
+	// failure (a 5xx or no response) or not.
+	ReportOutcome(fn *fv1.Function, addr *url.URL, failed bool)
+}
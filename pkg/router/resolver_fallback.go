@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"time"
 
 	"github.com/go-logr/logr"
 
@@ -218,3 +219,39 @@ func (f *fallbackResolver) Invalidate(fn *fv1.Function, addr *url.URL, reason In
 	}
 	f.executor.Invalidate(fn, addr, reason)
 }
+
+// ReportOutcome feeds fn's outlier ejection (see endpointcache.ReportResult).
+func (f *fallbackResolver) ReportOutcome(fn *fv1.Function, addr *url.URL, failed bool) {
+	oe := fn.Spec.OutlierEjection
+	if oe == nil || addr == nil {
+		return
+	}
+	version := backendVersion(fn)
+	if f.index.ReportResult(fn.Namespace, fn.Name, version, addr.Host, failed, outlierPolicyFor(oe)) {
+		f.logger.Info("ejecting endpoint after consecutive failed responses",
+			"function", fn.Name, "namespace", fn.Namespace, "version", version, "address", addr.Host)
+	}
+}
+
+// The OutlierEjectionConfig defaults, for objects the API server did not
+// default.
+const (
+	defaultEjectionDuration   = 30 * time.Second
+	defaultMaxEjectionPercent = 50
+)
+
+// outlierPolicyFor converts an OutlierEjectionConfig, applying its defaults.
+func outlierPolicyFor(oe *fv1.OutlierEjectionConfig) endpointcache.OutlierPolicy {
+	p := endpointcache.OutlierPolicy{
+		ConsecutiveFailures: int(oe.ConsecutiveFailures),
+		EjectionDuration:    defaultEjectionDuration,
+		MaxEjectionPercent:  int(oe.MaxEjectionPercent),
+	}
+	if oe.EjectionDuration != nil && oe.EjectionDuration.Duration > 0 {
+		p.EjectionDuration = oe.EjectionDuration.Duration
+	}
+	if p.MaxEjectionPercent <= 0 {
+		p.MaxEjectionPercent = defaultMaxEjectionPercent
+	}
+	return p
+}
@@ -276,6 +276,33 @@ func TestFallbackInvalidateQuarantinesEndpoint(t *testing.T) {
 	entry2.Release()
 }
 
+// TestFallbackReportOutcomeEjectsOutlier: with an OutlierEjection block, an
+// endpoint failing its function's requests in a row stops being admitted,
+// with the API server's defaults filled in for an undefaulted object.
+func TestFallbackReportOutcomeEjectsOutlier(t *testing.T) {
+	t.Parallel()
+	ix := endpointcache.NewIndex()
+	ix.ApplySlice(fnSlice("s1", "fn-o", "default", "10.0.0.1", "10.0.0.2"))
+	f := newFallbackForTest(t, ix, &stubExecutor{addr: "10.9.9.9:8888"}, nil)
+
+	fn := poolFn("fn-o")
+	bad := mustParseURL(t, "http://10.0.0.1:8888")
+	f.ReportOutcome(fn, bad, true)
+	assert.Empty(t, ix.Quarantines(), "functions without OutlierEjection are not tracked")
+
+	fn.Spec.OutlierEjection = &fv1.OutlierEjectionConfig{ConsecutiveFailures: 2}
+	assert.Equal(t, endpointcache.OutlierPolicy{ConsecutiveFailures: 2, EjectionDuration: 30 * time.Second, MaxEjectionPercent: 50},
+		outlierPolicyFor(fn.Spec.OutlierEjection))
+	f.ReportOutcome(fn, bad, true)
+	f.ReportOutcome(fn, bad, true)
+	for range 3 {
+		entry, err := f.Resolve(t.Context(), fn, "")
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.2:8888", entry.SvcURL.Host)
+		entry.Release()
+	}
+}
+
 // TestFallbackOnceOnlyBypassesIndex: OnceOnly pods serve exactly one request —
 // even when (stale) slices list them, the resolver must take the executor path.
 func TestFallbackOnceOnlyBypassesIndex(t *testing.T) {
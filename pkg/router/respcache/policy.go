@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package respcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+)
+
+// DefaultMaxTTL caps a response's freshness lifetime when the trigger sets
+// no MaxTTL.
+const DefaultMaxTTL = time.Hour
+
+// Policy is a trigger's cache configuration, resolved at mux build.
+type Policy struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+	// Vary holds the canonical names of the headers keying entries, sorted.
+	Vary   []string
+	Shared bool
+	// Version separates the entries stored under earlier versions of the
+	// trigger, so an edited trigger never answers from them.
+	Version string
+}
+
+// PolicyFor converts an HTTPTriggerCache, applying its defaults. version is
+// the trigger's generation.
+func PolicyFor(c *fv1.HTTPTriggerCache, version int64) Policy {
+	p := Policy{MaxTTL: DefaultMaxTTL, Shared: c.Shared, Version: strconv.FormatInt(version, 10)}
+	if c.DefaultTTL != nil {
+		p.DefaultTTL = c.DefaultTTL.Duration
+	}
+	if c.MaxTTL != nil && c.MaxTTL.Duration > 0 {
+		p.MaxTTL = c.MaxTTL.Duration
+	}
+	for _, h := range c.VaryHeaders {
+		p.Vary = append(p.Vary, http.CanonicalHeaderKey(h))
+	}
+	slices.Sort(p.Vary)
+	p.Vary = slices.Compact(p.Vary)
+	return p
+}
+
+// RequestKey digests what identifies r's entry: the trigger version, host,
+// path, query and the values of the Vary headers. GET and HEAD share it.
+func (p Policy) RequestKey(r *http.Request) string {
+	h := sha256.New()
+	write := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	write(p.Version)
+	write(r.Host)
+	write(r.URL.EscapedPath())
+	write(r.URL.RawQuery)
+	for _, name := range p.Vary {
+		write(name)
+		write(strings.Join(r.Header.Values(name), ","))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Lookup reports how r may use the cache: read when it may be answered from
+// an entry, write when its response may be stored. Only GET and HEAD are
+// cached, and only GET responses stored, since a HEAD response has no body
+// to answer a GET with. A request with no-cache skips the entries but
+// refreshes them; no-store and range requests bypass the cache altogether.
+func Lookup(r *http.Request) (read, write bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false, false
+	}
+	if r.Header.Get("Range") != "" {
+		return false, false
+	}
+	cc := parseCacheControl(r.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false, false
+	}
+	write = r.Method == http.MethodGet
+	if _, ok := cc["no-cache"]; ok || strings.EqualFold(r.Header.Get("Pragma"), "no-cache") {
+		return false, write
+	}
+	return true, write
+}
+
+// Usable reports whether e may answer r: a request carrying credentials is
+// answered only from a public entry.
+func Usable(r *http.Request, e *Entry) bool {
+	return e.Public || !credentialed(r)
+}
+
+type credentialsKey struct{}
+
+// WithCredentials marks r as authenticated by a credential the router removes
+// before the cache sees the request, such as an API key, so the cache treats
+// it like a request carrying an Authorization header.
+func WithCredentials(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), credentialsKey{}, true))
+}
+
+// credentialed reports whether r carries an Authorization header or was
+// marked by WithCredentials.
+func credentialed(r *http.Request) bool {
+	marked, _ := r.Context().Value(credentialsKey{}).(bool)
+	return marked || r.Header.Get("Authorization") != ""
+}
+
+// Store returns the entry to store for a response to r with status and
+// header, received at now, or nil when it must not be stored. The entry has
+// no body yet. A response is stored only when the function declares its
+// lifetime or the trigger sets a DefaultTTL, and a response to a request
+// carrying credentials only when the function marks it public.
+func (p Policy) Store(r *http.Request, status int, header http.Header, now time.Time) *Entry {
+	if status != http.StatusOK || len(header.Values("Set-Cookie")) > 0 {
+		return nil
+	}
+	cc := parseCacheControl(header)
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := cc[d]; ok {
+			return nil
+		}
+	}
+	for _, v := range header.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if name == "*" || !slices.Contains(p.Vary, name) {
+				return nil
+			}
+		}
+	}
+	_, public := cc["public"]
+	sMaxAge, hasSMaxAge := cc["s-maxage"]
+	public = public || hasSMaxAge
+	if !public && credentialed(r) {
+		return nil
+	}
+
+	ttl := p.DefaultTTL
+	switch maxAge, hasMaxAge := cc["max-age"]; {
+	case hasSMaxAge:
+		ttl = deltaSeconds(sMaxAge)
+	case hasMaxAge:
+		ttl = deltaSeconds(maxAge)
+	case header.Get("Expires") != "":
+		// An invalid Expires, such as "0", means already expired.
+		ttl = 0
+		if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+			date, err := http.ParseTime(header.Get("Date"))
+			if err != nil {
+				date = now
+			}
+			ttl = expires.Sub(date)
+		}
+	}
+	ttl = min(ttl, p.MaxTTL)
+	if ttl <= 0 {
+		return nil
+	}
+
+	stored := header.Clone()
+	stored.Del("Age")
+	return &Entry{Status: status, Header: stored, Stored: now, Expires: now.Add(ttl), Public: public}
+}
+
+// Refresh returns stale updated by the 304 the function answered its
+// revalidation with at now, and whether the updated response may still be
+// stored.
+func (p Policy) Refresh(r *http.Request, stale *Entry, header http.Header, now time.Time) (*Entry, bool) {
+	merged := stale.Header.Clone()
+	for k, vs := range header {
+		merged[k] = vs
+	}
+	e := p.Store(r, stale.Status, merged, now)
+	if e == nil {
+		return &Entry{Status: stale.Status, Header: merged, Body: stale.Body, Stored: now, Expires: now}, false
+	}
+	e.Body = stale.Body
+	return e, true
+}
+
+// Revalidate makes r a conditional request for e, so the function can
+// answer 304 instead of the body. A request carrying its own conditions is
+// left alone: its 304 belongs to the client.
+func Revalidate(r *http.Request, e *Entry) bool {
+	if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" || !e.Revalidatable() {
+		return false
+	}
+	if etag := e.Header.Get("ETag"); etag != "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+	if lm := e.Header.Get("Last-Modified"); lm != "" {
+		r.Header.Set("If-Modified-Since", lm)
+	}
+	return true
+}
+
+// NotModified reports whether r's conditions match e, so r is answered 304.
+// If-None-Match takes precedence over If-Modified-Since.
+func NotModified(r *http.Request, e *Entry) bool {
+	if inm := r.Header.Values("If-None-Match"); len(inm) > 0 {
+		etag := e.Header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, v := range inm {
+			for tag := range strings.SplitSeq(v, ",") {
+				tag = strings.TrimSpace(tag)
+				if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	return err == nil && !lm.After(ims)
+}
+
+// Write answers r from e at now: 304 when r's conditions match, else the
+// stored response, without its body for HEAD.
+func Write(w http.ResponseWriter, r *http.Request, e *Entry, now time.Time) {
+	h := w.Header()
+	for k, vs := range e.Header {
+		h[k] = vs
+	}
+	h.Set("Age", strconv.FormatInt(int64(max(now.Sub(e.Stored), 0)/time.Second), 10))
+	if NotModified(r, e) {
+		h.Del("Content-Length")
+		h.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Length", strconv.Itoa(len(e.Body)))
+	w.WriteHeader(e.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(e.Body)
+	}
+}
+
+// parseCacheControl returns the directives of h's Cache-Control, keyed by
+// their lower-cased names, with quotes stripped from values.
+func parseCacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, v := range h.Values("Cache-Control") {
+		for d := range strings.SplitSeq(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if name == "" {
+				continue
+			}
+			cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return cc
+}
+
+// deltaSeconds parses a max-age value; an invalid one means already expired.
+// Values past 2^31 seconds are taken as 2^31, as RFC 9111 asks.
+func deltaSeconds(v string) time.Duration {
+	secs, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(min(secs, math.MaxInt32)) * time.Second
+}
@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package respcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+)
+
+func TestPolicyFor(t *testing.T) {
+	t.Parallel()
+	p := PolicyFor(&fv1.HTTPTriggerCache{
+		DefaultTTL:  &metav1.Duration{Duration: time.Minute},
+		VaryHeaders: []string{"accept-language", "Accept", "Accept-Language"},
+		Shared:      true,
+	}, 3)
+	assert.Equal(t, Policy{DefaultTTL: time.Minute, MaxTTL: DefaultMaxTTL, Vary: []string{"Accept", "Accept-Language"}, Shared: true, Version: "3"}, p)
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name        string
+		method      string
+		header      http.Header
+		read, write bool
+	}{
+		{name: "get", method: http.MethodGet, read: true, write: true},
+		{name: "head is answered but not stored", method: http.MethodHead, read: true},
+		{name: "post bypasses", method: http.MethodPost},
+		{name: "no-store bypasses", method: http.MethodGet, header: http.Header{"Cache-Control": {"no-store"}}},
+		{name: "range bypasses", method: http.MethodGet, header: http.Header{"Range": {"bytes=0-9"}}},
+		{name: "no-cache refreshes", method: http.MethodGet, header: http.Header{"Cache-Control": {"no-cache"}}, write: true},
+		{name: "pragma no-cache refreshes", method: http.MethodGet, header: http.Header{"Pragma": {"no-cache"}}, write: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(tc.method, "/catalog", nil)
+			for k, vs := range tc.header {
+				r.Header[k] = vs
+			}
+			read, write := Lookup(r)
+			assert.Equal(t, tc.read, read, "read")
+			assert.Equal(t, tc.write, write, "write")
+		})
+	}
+}
+
+func TestPolicyStore(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	p := Policy{DefaultTTL: time.Minute, MaxTTL: time.Hour, Vary: []string{"Accept-Language"}}
+	for _, tc := range []struct {
+		name       string
+		status     int
+		header     http.Header
+		authorized bool
+		keyed      bool
+		// ttl is the expected freshness lifetime; 0 means not stored.
+		ttl    time.Duration
+		public bool
+	}{
+		{name: "default ttl", status: http.StatusOK, ttl: time.Minute},
+		{name: "max-age", status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=30"}}, ttl: 30 * time.Second},
+		{name: "s-maxage wins and is public", status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=30, s-maxage=90"}}, ttl: 90 * time.Second, public: true},
+		{name: "capped by max ttl", status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=86400"}}, ttl: time.Hour},
+		{name: "expires", status: http.StatusOK, header: http.Header{"Date": {now.Format(http.TimeFormat)}, "Expires": {now.Add(2 * time.Minute).Format(http.TimeFormat)}}, ttl: 2 * time.Minute},
+		{name: "invalid expires", status: http.StatusOK, header: http.Header{"Expires": {"0"}}},
+		{name: "max-age zero", status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=0"}}},
+		{name: "no-store", status: http.StatusOK, header: http.Header{"Cache-Control": {"no-store"}}},
+		{name: "no-cache", status: http.StatusOK, header: http.Header{"Cache-Control": {"No-Cache"}}},
+		{name: "private", status: http.StatusOK, header: http.Header{"Cache-Control": {"private, max-age=60"}}},
+		{name: "set-cookie", status: http.StatusOK, header: http.Header{"Set-Cookie": {"sid=1"}}},
+		{name: "not ok", status: http.StatusNotFound},
+		{name: "vary on a key header", status: http.StatusOK, header: http.Header{"Vary": {"accept-language"}}, ttl: time.Minute},
+		{name: "vary on another header", status: http.StatusOK, header: http.Header{"Vary": {"Accept-Language, Cookie"}}},
+		{name: "vary star", status: http.StatusOK, header: http.Header{"Vary": {"*"}}},
+		{name: "authorized request", status: http.StatusOK, authorized: true},
+		{name: "authorized request, public response", status: http.StatusOK, header: http.Header{"Cache-Control": {"public, max-age=10"}}, authorized: true, ttl: 10 * time.Second, public: true},
+		{name: "api key request", status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=10"}}, keyed: true},
+		{name: "api key request, public response", status: http.StatusOK, header: http.Header{"Cache-Control": {"public, max-age=10"}}, keyed: true, ttl: 10 * time.Second, public: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+			if tc.authorized {
+				r.Header.Set("Authorization", "Bearer x")
+			}
+			if tc.keyed {
+				r = WithCredentials(r)
+			}
+			header := http.Header{"Age": {"5"}}
+			for k, vs := range tc.header {
+				header[k] = vs
+			}
+			e := p.Store(r, tc.status, header, now)
+			if tc.ttl == 0 {
+				assert.Nil(t, e)
+				return
+			}
+			require.NotNil(t, e)
+			assert.Equal(t, now.Add(tc.ttl), e.Expires)
+			assert.Equal(t, tc.public, e.Public)
+			assert.Empty(t, e.Header.Get("Age"), "the stored age is the router's to compute")
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	assert.Nil(t, Policy{MaxTTL: time.Hour}.Store(r, http.StatusOK, http.Header{}, now),
+		"without a DefaultTTL a response declaring no lifetime is not stored")
+}
+
+func TestUsable(t *testing.T) {
+	t.Parallel()
+	private, public := &Entry{}, &Entry{Public: true}
+	anon := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	authorized := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	authorized.Header.Set("Authorization", "Bearer x")
+	keyed := WithCredentials(httptest.NewRequest(http.MethodGet, "/catalog", nil))
+
+	assert.True(t, Usable(anon, private))
+	assert.False(t, Usable(authorized, private))
+	assert.False(t, Usable(keyed, private), "an API key request never sees another caller's private entry")
+	assert.True(t, Usable(keyed, public))
+}
+
+func TestRequestKey(t *testing.T) {
+	t.Parallel()
+	p := Policy{Vary: []string{"Accept-Language"}, Version: "1"}
+	key := func(target string, header http.Header) string {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, vs := range header {
+			r.Header[k] = vs
+		}
+		return p.RequestKey(r)
+	}
+	base := key("/catalog?page=1", nil)
+	assert.Equal(t, base, key("/catalog?page=1", http.Header{"Accept": {"text/plain"}}), "headers outside Vary do not key entries")
+	assert.NotEqual(t, base, key("/catalog?page=2", nil))
+	assert.NotEqual(t, base, key("/catalog?page=1", http.Header{"Accept-Language": {"fr"}}))
+
+	head := httptest.NewRequest(http.MethodHead, "/catalog?page=1", nil)
+	assert.Equal(t, base, p.RequestKey(head), "HEAD shares GET's entry")
+	p.Version = "2"
+	assert.NotEqual(t, base, p.RequestKey(head), "an edited trigger starts afresh")
+}
+
+func TestNotModified(t *testing.T) {
+	t.Parallel()
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := &Entry{Header: http.Header{"Etag": {`"v2"`}, "Last-Modified": {modified.Format(http.TimeFormat)}}}
+	for _, tc := range []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{name: "unconditional"},
+		{name: "matching etag", header: http.Header{"If-None-Match": {`"v1", "v2"`}}, want: true},
+		{name: "weak etag", header: http.Header{"If-None-Match": {`W/"v2"`}}, want: true},
+		{name: "star", header: http.Header{"If-None-Match": {"*"}}, want: true},
+		{name: "other etag", header: http.Header{"If-None-Match": {`"v1"`}}},
+		{name: "etag takes precedence", header: http.Header{"If-None-Match": {`"v1"`}, "If-Modified-Since": {modified.Format(http.TimeFormat)}}},
+		{name: "not modified since", header: http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}}, want: true},
+		{name: "modified since", header: http.Header{"If-Modified-Since": {modified.Add(-time.Hour).Format(http.TimeFormat)}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+			for k, vs := range tc.header {
+				r.Header[k] = vs
+			}
+			assert.Equal(t, tc.want, NotModified(r, e))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	e := &Entry{
+		Status:  http.StatusOK,
+		Header:  http.Header{"Content-Type": {"application/json"}, "Etag": {`"v1"`}},
+		Body:    []byte(`{"items":[]}`),
+		Stored:  now.Add(-7 * time.Second),
+		Expires: now.Add(time.Minute),
+	}
+
+	rr := httptest.NewRecorder()
+	Write(rr, httptest.NewRequest(http.MethodGet, "/catalog", nil), e, now)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "7", rr.Header().Get("Age"))
+	assert.Equal(t, "12", rr.Header().Get("Content-Length"))
+	assert.Equal(t, `{"items":[]}`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	Write(rr, httptest.NewRequest(http.MethodHead, "/catalog", nil), e, now)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	r := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	r.Header.Set("If-None-Match", `"v1"`)
+	rr = httptest.NewRecorder()
+	Write(rr, r, e, now)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Equal(t, `"v1"`, rr.Header().Get("ETag"))
+	assert.Empty(t, rr.Body.String())
+}
+
+func TestRevalidate(t *testing.T) {
+	t.Parallel()
+	e := &Entry{Header: http.Header{"Etag": {`"v1"`}}}
+	r := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	require.True(t, Revalidate(r, e))
+	assert.Equal(t, `"v1"`, r.Header.Get("If-None-Match"))
+
+	own := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	own.Header.Set("If-None-Match", `"v0"`)
+	assert.False(t, Revalidate(own, e), "a client's own conditions are left alone")
+	assert.False(t, Revalidate(httptest.NewRequest(http.MethodGet, "/catalog", nil), &Entry{Header: http.Header{}}), "nothing to revalidate with")
+}
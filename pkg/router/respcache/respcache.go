@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package respcache caches function responses for the HTTPTriggers that opt
+// in (fv1.HTTPTriggerCache). Entries live in a byte-bounded LRU in the
+// router's memory or, for a trigger whose cache is Shared, in the statestore
+// KV, where every router replica reads them. A store failure is a miss: the
+// request reaches the function as if the trigger had no cache.
+package respcache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// kvKeyspace holds shared entries in the statestore, under the namespace
+	// and owner ("httptrigger/<name>") of the trigger they were stored for.
+	kvKeyspace = "respcache"
+	// kvTimeout bounds one read or write against the store; a slower store
+	// is treated as failed.
+	kvTimeout = 200 * time.Millisecond
+	// purgePage is how many keys a purge lists from the store at a time.
+	purgePage = 500
+	// DefaultMaxBytes bounds the memory all in-process entries use together
+	// when the router is not configured otherwise.
+	DefaultMaxBytes = 64 << 20
+	// MaxEntryBytes caps one stored response body; a larger response is
+	// passed through without being stored.
+	MaxEntryBytes = 1 << 20
+	// staleRetention is how long past its expiry an entry that can be
+	// revalidated is kept, so a function answering 304 spares the body.
+	staleRetention = 10 * time.Minute
+)
+
+// Key names one entry: the trigger it was stored for and the digest of the
+// request it answers (Policy.RequestKey).
+type Key struct {
+	Namespace string
+	Trigger   string
+	Request   string
+}
+
+func (k Key) String() string {
+	return k.Namespace + "/" + k.Trigger + "/" + k.Request
+}
+
+func (k Key) scope() statestore.Scope {
+	return statestore.Scope{Namespace: k.Namespace, Owner: "httptrigger/" + k.Trigger, Keyspace: kvKeyspace}
+}
+
+// Entry is one stored response.
+type Entry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body,omitempty"`
+	// Stored is when the response was received or last revalidated; the Age
+	// of an answer from the entry counts from it.
+	Stored time.Time `json:"stored"`
+	// Expires is when the entry stops being fresh.
+	Expires time.Time `json:"expires"`
+	// Public marks a response that may answer requests carrying an
+	// Authorization header.
+	Public bool `json:"public,omitempty"`
+}
+
+// Fresh reports whether e may answer a request at now without asking the
+// function.
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// Revalidatable reports whether e carries a validator the function can
+// confirm it with.
+func (e *Entry) Revalidatable() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// retainUntil is when e is dropped: at expiry, or staleRetention later when
+// it can be revalidated.
+func (e *Entry) retainUntil() time.Time {
+	if e.Revalidatable() {
+		return e.Expires.Add(staleRetention)
+	}
+	return e.Expires
+}
+
+// size approximates the memory e holds.
+func (e *Entry) size() int64 {
+	n := int64(len(e.Body)) + 64
+	for k, vs := range e.Header {
+		n += int64(len(k))
+		for _, v := range vs {
+			n += int64(len(v))
+		}
+	}
+	return n
+}
+
+type item struct {
+	key   string
+	entry *Entry
+	size  int64
+}
+
+// Cache holds the entries of every trigger on a router replica.
+type Cache struct {
+	logger   logr.Logger
+	maxBytes int64
+	// kv is nil when the router runs without the statestore.
+	kv  statestore.KVStore
+	now func() time.Time
+
+	mu sync.Mutex
+	// lru holds *item, most recently used at the front.
+	lru   *list.List
+	items map[string]*list.Element
+	bytes int64
+}
+
+// New returns a Cache whose in-process entries use at most maxBytes
+// (DefaultMaxBytes when maxBytes <= 0), sharing entries through kv when it is
+// not nil.
+func New(logger logr.Logger, maxBytes int64, kv statestore.KVStore) *Cache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &Cache{
+		logger:   logger,
+		maxBytes: maxBytes,
+		kv:       kv,
+		now:      time.Now,
+		lru:      list.New(),
+		items:    map[string]*list.Element{},
+	}
+}
+
+// Shared reports whether the cache has the statestore to share entries
+// through.
+func (c *Cache) Shared() bool {
+	return c.kv != nil
+}
+
+// Get returns k's entry, fresh or stale, or nil when there is none. shared
+// reads the statestore rather than process memory, when the cache has one.
+func (c *Cache) Get(ctx context.Context, k Key, shared bool) *Entry {
+	if shared && c.kv != nil {
+		return c.getShared(ctx, k)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[k.String()]
+	if !ok {
+		return nil
+	}
+	it := el.Value.(*item)
+	if !c.now().Before(it.entry.retainUntil()) {
+		c.remove(el)
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return it.entry
+}
+
+// Put stores e under k, replacing any entry there. shared writes the
+// statestore rather than process memory, when the cache has one.
+func (c *Cache) Put(ctx context.Context, k Key, e *Entry, shared bool) {
+	if shared && c.kv != nil {
+		c.putShared(ctx, k, e)
+		return
+	}
+	it := &item{key: k.String(), entry: e, size: e.size()}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[it.key]; ok {
+		c.remove(el)
+	}
+	if it.size > c.maxBytes {
+		return
+	}
+	c.items[it.key] = c.lru.PushFront(it)
+	c.bytes += it.size
+	for c.bytes > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Purge removes every entry stored for the trigger namespace/trigger, from
+// this replica's memory and from the statestore, and returns how many it
+// removed. Other replicas' in-process entries are out of its reach.
+func (c *Cache) Purge(ctx context.Context, namespace, trigger string) (int, error) {
+	prefix := Key{Namespace: namespace, Trigger: trigger}.String()
+	purged := 0
+	c.mu.Lock()
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(el)
+			purged++
+		}
+	}
+	c.mu.Unlock()
+	if c.kv == nil {
+		return purged, nil
+	}
+
+	scope := Key{Namespace: namespace, Trigger: trigger}.scope()
+	page := statestore.Page{Limit: purgePage}
+	for {
+		keys, err := c.kv.List(ctx, scope, "", page)
+		if err != nil {
+			return purged, err
+		}
+		for _, key := range keys.Keys {
+			err := c.kv.Delete(ctx, scope, key, 0)
+			if err != nil && !errors.Is(err, statestore.ErrNotFound) {
+				return purged, err
+			}
+			purged++
+		}
+		if keys.Next == "" {
+			return purged, nil
+		}
+		page.Token = keys.Next
+	}
+}
+
+// remove drops el; c.mu must be held.
+func (c *Cache) remove(el *list.Element) {
+	it := c.lru.Remove(el).(*item)
+	delete(c.items, it.key)
+	c.bytes -= it.size
+}
+
+func (c *Cache) getShared(ctx context.Context, k Key) *Entry {
+	ctx, cancel := context.WithTimeout(ctx, kvTimeout)
+	defer cancel()
+	v, err := c.kv.Get(ctx, k.scope(), k.Request)
+	if err != nil {
+		if !errors.Is(err, statestore.ErrNotFound) {
+			c.logger.V(1).Info("response cache store failed; treating as a miss", "namespace", k.Namespace, "trigger", k.Trigger, "error", err.Error())
+		}
+		return nil
+	}
+	var e Entry
+	if err := json.Unmarshal(v.Data, &e); err != nil {
+		c.logger.V(1).Info("dropping undecodable response cache entry", "namespace", k.Namespace, "trigger", k.Trigger, "error", err.Error())
+		return nil
+	}
+	return &e
+}
+
+func (c *Cache) putShared(ctx context.Context, k Key, e *Entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	// The TTL is rounded up to a second so a coarse-grained driver never
+	// expires the key before the entry is due to be dropped.
+	ttl := e.retainUntil().Sub(c.now()).Truncate(time.Second) + time.Second
+	ctx, cancel := context.WithTimeout(ctx, kvTimeout)
+	defer cancel()
+	if err := c.kv.Set(ctx, k.scope(), k.Request, data, statestore.SetOptions{TTL: ttl}); err != nil {
+		c.logger.V(1).Info("response cache store failed; entry not shared", "namespace", k.Namespace, "trigger", k.Trigger, "error", err.Error())
+	}
+}
+
+// Capture returns body with its bytes recorded as they are read. Once body
+// has been read to its end, done is called with them; a body longer than
+// MaxEntryBytes, or one not read to its end, is never passed to done.
+func Capture(body io.ReadCloser, done func([]byte)) io.ReadCloser {
+	return &capture{ReadCloser: body, done: done}
+}
+
+type capture struct {
+	io.ReadCloser
+	buf  []byte
+	over bool
+	done func([]byte)
+}
+
+func (c *capture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if !c.over {
+		if len(c.buf)+n > MaxEntryBytes {
+			c.over, c.buf = true, nil
+		} else {
+			c.buf = append(c.buf, p[:n]...)
+		}
+	}
+	if errors.Is(err, io.EOF) && !c.over && c.done != nil {
+		c.done(c.buf)
+		c.done = nil
+	}
+	return n, err
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package respcache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statestore/memory"
+)
+
+func entry(body string, expires time.Time) *Entry {
+	return &Entry{Status: http.StatusOK, Header: http.Header{}, Body: []byte(body), Stored: time.Now(), Expires: expires}
+}
+
+func memKV(t *testing.T) statestore.KVStore {
+	t.Helper()
+	caps, err := memory.New()
+	require.NoError(t, err)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	return kv
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	body := strings.Repeat("x", 100)
+	// Room for two entries of body plus their overhead, not three.
+	c := New(logr.Discard(), 2*entry(body, time.Time{}).size()+10, nil)
+	later := time.Now().Add(time.Minute)
+	key := func(r string) Key { return Key{Namespace: "default", Trigger: "ht", Request: r} }
+
+	c.Put(t.Context(), key("a"), entry(body, later), false)
+	c.Put(t.Context(), key("b"), entry(body, later), false)
+	require.NotNil(t, c.Get(t.Context(), key("a"), false), "a is now the most recently used")
+	c.Put(t.Context(), key("c"), entry(body, later), false)
+
+	assert.NotNil(t, c.Get(t.Context(), key("a"), false))
+	assert.Nil(t, c.Get(t.Context(), key("b"), false), "the least recently used entry is evicted")
+	assert.NotNil(t, c.Get(t.Context(), key("c"), false))
+	assert.LessOrEqual(t, c.bytes, c.maxBytes)
+
+	c.Put(t.Context(), key("huge"), entry(strings.Repeat("x", 1000), later), false)
+	assert.Nil(t, c.Get(t.Context(), key("huge"), false), "an entry larger than the cache is not stored")
+}
+
+func TestCacheDropsExpiredEntries(t *testing.T) {
+	t.Parallel()
+	c := New(logr.Discard(), 0, nil)
+	k := Key{Namespace: "default", Trigger: "ht", Request: "r"}
+	c.Put(t.Context(), k, entry("gone", time.Now().Add(-time.Second)), false)
+	assert.Nil(t, c.Get(t.Context(), k, false), "an expired entry without validators is dropped")
+
+	stale := entry("stale", time.Now().Add(-time.Second))
+	stale.Header.Set("ETag", `"v1"`)
+	c.Put(t.Context(), k, stale, false)
+	got := c.Get(t.Context(), k, false)
+	require.NotNil(t, got, "an expired entry with a validator is kept for revalidation")
+	assert.False(t, got.Fresh(time.Now()))
+}
+
+// TestCacheSharedAcrossReplicas: two routers over one store serve each
+// other's shared entries, and one purge removes them for both.
+func TestCacheSharedAcrossReplicas(t *testing.T) {
+	t.Parallel()
+	kv := memKV(t)
+	a, b := New(logr.Discard(), 0, kv), New(logr.Discard(), 0, kv)
+	require.True(t, a.Shared())
+	k := Key{Namespace: "default", Trigger: "ht", Request: "r"}
+
+	a.Put(t.Context(), k, entry("catalog", time.Now().Add(time.Minute)), true)
+	got := b.Get(t.Context(), k, true)
+	require.NotNil(t, got)
+	assert.Equal(t, "catalog", string(got.Body))
+	assert.Nil(t, b.Get(t.Context(), k, false), "process memory is separate")
+
+	other := Key{Namespace: "default", Trigger: "ht2", Request: "r"}
+	a.Put(t.Context(), other, entry("other", time.Now().Add(time.Minute)), true)
+
+	purged, err := b.Purge(t.Context(), "default", "ht")
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Nil(t, a.Get(t.Context(), k, true))
+	assert.NotNil(t, a.Get(t.Context(), other, true), "other triggers keep their entries")
+}
+
+func TestCachePurgeMemory(t *testing.T) {
+	t.Parallel()
+	c := New(logr.Discard(), 0, nil)
+	later := time.Now().Add(time.Minute)
+	c.Put(t.Context(), Key{Namespace: "default", Trigger: "ht", Request: "a"}, entry("a", later), false)
+	c.Put(t.Context(), Key{Namespace: "default", Trigger: "ht", Request: "b"}, entry("b", later), false)
+	c.Put(t.Context(), Key{Namespace: "default", Trigger: "ht-v2", Request: "a"}, entry("a", later), false)
+
+	purged, err := c.Purge(t.Context(), "default", "ht")
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.NotNil(t, c.Get(t.Context(), Key{Namespace: "default", Trigger: "ht-v2", Request: "a"}, false), "a trigger whose name extends the purged one is untouched")
+}
+
+type failingKV struct{ statestore.KVStore }
+
+func (failingKV) Get(context.Context, statestore.Scope, string) (statestore.Value, error) {
+	return statestore.Value{}, io.ErrUnexpectedEOF
+}
+
+func TestCacheStoreFailureIsAMiss(t *testing.T) {
+	t.Parallel()
+	c := New(logr.Discard(), 0, failingKV{})
+	assert.Nil(t, c.Get(t.Context(), Key{Namespace: "default", Trigger: "ht", Request: "r"}, true))
+}
+
+func TestCapture(t *testing.T) {
+	t.Parallel()
+	var got []byte
+	done := 0
+	body := Capture(io.NopCloser(strings.NewReader("catalog")), func(b []byte) {
+		got = b
+		done++
+	})
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "catalog", string(data))
+	assert.Equal(t, "catalog", string(got))
+	assert.Equal(t, 1, done)
+
+	done = 0
+	big := Capture(io.NopCloser(strings.NewReader(strings.Repeat("x", MaxEntryBytes+1))), func([]byte) { done++ })
+	_, err = io.Copy(io.Discard, big)
+	require.NoError(t, err)
+	assert.Zero(t, done, "a body over MaxEntryBytes is not stored")
+
+	partial := Capture(io.NopCloser(strings.NewReader("catalog")), func([]byte) { done++ })
+	_, err = partial.Read(make([]byte, 3))
+	require.NoError(t, err)
+	require.NoError(t, partial.Close())
+	assert.Zero(t, done, "a body not read to its end is not stored")
+}
@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/respcache"
+	"github.com/fission/fission/pkg/utils/httpmux"
+)
+
+// routeCache is the response cache policy of an HTTPTrigger route with a
+// Cache block, resolved at mux build.
+type routeCache struct {
+	namespace string
+	trigger   string
+	policy    respcache.Policy
+}
+
+func responseCacheFor(trigger *fv1.HTTPTrigger) *routeCache {
+	if trigger.Spec.Cache == nil {
+		return nil
+	}
+	return &routeCache{
+		namespace: trigger.Namespace,
+		trigger:   trigger.Name,
+		policy:    respcache.PolicyFor(trigger.Spec.Cache, trigger.Generation),
+	}
+}
+
+// cachedRequest follows a cacheable request that missed the cache to the
+// function: the key its response is stored under, whether it may be, and
+// the stale entry the router asked the function to revalidate, if any.
+type cachedRequest struct {
+	key   respcache.Key
+	write bool
+	stale *respcache.Entry
+}
+
+// serveCached answers req from the route's cache and returns true when the
+// cache holds a fresh entry for it. Otherwise it returns how the response
+// is to be cached, nil when the request bypasses the cache; a stale entry
+// that can be revalidated turns req into a conditional request for it.
+func (fh functionHandler) serveCached(rw http.ResponseWriter, req *http.Request) (*cachedRequest, bool) {
+	rc := fh.cacheRoute
+	read, write := respcache.Lookup(req)
+	if !read && !write {
+		recordResponseCache(req.Context(), rc, responseCacheBypass)
+		return nil, false
+	}
+	cr := &cachedRequest{
+		key:   respcache.Key{Namespace: rc.namespace, Trigger: rc.trigger, Request: rc.policy.RequestKey(req)},
+		write: write,
+	}
+	if read {
+		now := time.Now()
+		if e := fh.respCache.Get(req.Context(), cr.key, rc.policy.Shared); e != nil && respcache.Usable(req, e) {
+			if e.Fresh(now) {
+				recordResponseCache(req.Context(), rc, responseCacheHit)
+				respcache.Write(rw, req, e, now)
+				return nil, true
+			}
+			if write && respcache.Revalidate(req, e) {
+				cr.stale = e
+			}
+		}
+	}
+	recordResponseCache(req.Context(), rc, responseCacheMiss)
+	return cr, false
+}
+
+// storeResponse caches resp, the function's answer to req, as cr allows:
+// the body is recorded as the proxy copies it to the client and stored
+// once it has been read to its end. A 304 answering the router's own
+// revalidation is turned back into the stale entry's response, refreshed.
+func (fh functionHandler) storeResponse(cr *cachedRequest, req *http.Request, resp *http.Response) {
+	if !cr.write {
+		return
+	}
+	rc := fh.cacheRoute
+	now := time.Now()
+	if cr.stale != nil && resp.StatusCode == http.StatusNotModified {
+		recordResponseCache(req.Context(), rc, responseCacheRevalidated)
+		e, storable := rc.policy.Refresh(req, cr.stale, resp.Header, now)
+		_ = resp.Body.Close()
+		resp.StatusCode = e.Status
+		resp.Status = fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+		resp.Header = e.Header.Clone()
+		resp.Header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+		resp.ContentLength = int64(len(e.Body))
+		resp.Body = io.NopCloser(bytes.NewReader(e.Body))
+		if storable {
+			fh.putResponse(cr.key, e)
+		}
+		return
+	}
+	e := rc.policy.Store(req, resp.StatusCode, resp.Header, now)
+	if e == nil || resp.ContentLength > respcache.MaxEntryBytes {
+		return
+	}
+	resp.Body = respcache.Capture(resp.Body, func(body []byte) {
+		e.Body = body
+		fh.putResponse(cr.key, e)
+	})
+}
+
+// putResponse stores e under key. A shared entry is written in the
+// background, so the store's latency never delays the response.
+func (fh functionHandler) putResponse(key respcache.Key, e *respcache.Entry) {
+	if fh.cacheRoute.policy.Shared && fh.respCache.Shared() {
+		go fh.respCache.Put(context.Background(), key, e, true)
+		return
+	}
+	fh.respCache.Put(context.Background(), key, e, false)
+}
+
+// Response cache purge API — the surface behind `fission httptrigger cache
+// purge`. DELETE removes every entry of the trigger ?name in ?namespace from
+// the statestore and from the memory of the replica serving the request;
+// other replicas' in-process entries expire on their own.
+const responseCachePath = "/v1/cache/httptrigger"
+
+func (ts *HTTPTriggerSet) registerResponseCacheRoutes(internal *httpmux.Mux) {
+	internal.HandleFunc(responseCachePath, ts.purgeResponseCache).Methods(http.MethodDelete)
+}
+
+// responseCachePurge is the purge API's response.
+type responseCachePurge struct {
+	Purged int `json:"purged"`
+}
+
+func (ts *HTTPTriggerSet) purgeResponseCache(w http.ResponseWriter, r *http.Request) {
+	namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+	if namespace == "" || name == "" || strings.Contains(namespace, "/") || strings.Contains(name, "/") {
+		http.Error(w, "namespace and name are required and must not contain '/'", http.StatusBadRequest)
+		return
+	}
+	purged, err := ts.respCache.Purge(r.Context(), namespace, name)
+	if err != nil {
+		ts.logger.Error(err, "purging response cache", "namespace", namespace, "trigger", name, "purged", purged)
+		http.Error(w, "purging response cache", http.StatusInternalServerError)
+		return
+	}
+	dlqWriteJSON(w, ts, responseCachePurge{Purged: purged})
+}
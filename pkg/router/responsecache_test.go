@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/respcache"
+)
+
+func TestResponseCacheFor(t *testing.T) {
+	t.Parallel()
+	trigger := &fv1.HTTPTrigger{ObjectMeta: metav1.ObjectMeta{Name: "ht", Namespace: "default", Generation: 4}}
+	assert.Nil(t, responseCacheFor(trigger))
+
+	trigger.Spec.Cache = &fv1.HTTPTriggerCache{VaryHeaders: []string{"accept-language"}}
+	rc := responseCacheFor(trigger)
+	require.NotNil(t, rc)
+	assert.Equal(t, "ht", rc.trigger)
+	assert.Equal(t, []string{"Accept-Language"}, rc.policy.Vary)
+	assert.Equal(t, "4", rc.policy.Version)
+}
+
+// cachingHandler builds a functionHandler for a trigger with a response cache
+// in front of upstream.
+func cachingHandler(t *testing.T, upstream *httptest.Server) functionHandler {
+	t.Helper()
+	fh := newHandlerForUpstream(t, streamingFn("uid-cache", nil), upstream, 60)
+	fh.respCache = respcache.New(logr.Discard(), 0, nil)
+	fh.cacheRoute = &routeCache{
+		namespace: "default",
+		trigger:   "ht",
+		policy:    respcache.PolicyFor(&fv1.HTTPTriggerCache{VaryHeaders: []string{"Accept-Language"}}, 1),
+	}
+	return fh
+}
+
+func serveThroughCache(fh functionHandler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for k, vs := range header {
+		r.Header[k] = vs
+	}
+	rr := httptest.NewRecorder()
+	fh.handler(rr, r)
+	return rr
+}
+
+// TestFunctionHandler_ResponseCache: a cacheable GET reaches the function
+// once; repeats, HEADs and matching conditional requests are answered by the
+// router, while other variants and methods still reach the function.
+func TestFunctionHandler_ResponseCache(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = w.Write([]byte("catalog:" + r.Header.Get("Accept-Language")))
+	}))
+	t.Cleanup(upstream.Close)
+	fh := cachingHandler(t, upstream)
+
+	rr := serveThroughCache(fh, http.MethodGet, "/catalog", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "catalog:", rr.Body.String())
+	assert.EqualValues(t, 1, calls.Load())
+
+	rr = serveThroughCache(fh, http.MethodGet, "/catalog", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "catalog:", rr.Body.String())
+	assert.NotEmpty(t, rr.Header().Get("Age"), "answered from the cache")
+	assert.EqualValues(t, 1, calls.Load())
+
+	rr = serveThroughCache(fh, http.MethodHead, "/catalog", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Body.String())
+	assert.EqualValues(t, 1, calls.Load())
+
+	rr = serveThroughCache(fh, http.MethodGet, "/catalog", http.Header{"If-None-Match": {`"v1"`}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.EqualValues(t, 1, calls.Load())
+
+	rr = serveThroughCache(fh, http.MethodGet, "/catalog", http.Header{"Accept-Language": {"fr"}})
+	assert.Equal(t, "catalog:fr", rr.Body.String())
+	assert.EqualValues(t, 2, calls.Load(), "each vary header value has its own entry")
+
+	serveThroughCache(fh, http.MethodPost, "/catalog", nil)
+	assert.EqualValues(t, 3, calls.Load(), "other methods bypass the cache")
+
+	serveThroughCache(fh, http.MethodGet, "/catalog", http.Header{"Cache-Control": {"no-cache"}})
+	assert.EqualValues(t, 4, calls.Load(), "no-cache requests reach the function")
+}
+
+// TestFunctionHandler_ResponseCacheRevalidates: a stale entry is
+// revalidated with its ETag, and the function's 304 is answered with the
+// stored body and refreshes the entry.
+func TestFunctionHandler_ResponseCacheRevalidates(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	var conditional atomic.Value
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		conditional.Store(r.Header.Get("If-None-Match"))
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	t.Cleanup(upstream.Close)
+	fh := cachingHandler(t, upstream)
+
+	req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+	key := respcache.Key{Namespace: "default", Trigger: "ht", Request: fh.cacheRoute.policy.RequestKey(req)}
+	fh.respCache.Put(t.Context(), key, &respcache.Entry{
+		Status:  http.StatusOK,
+		Header:  http.Header{"Etag": {`"v1"`}, "Content-Type": {"text/plain"}},
+		Body:    []byte("catalog"),
+		Stored:  time.Now().Add(-2 * time.Minute),
+		Expires: time.Now().Add(-time.Minute),
+	}, false)
+
+	rr := serveThroughCache(fh, http.MethodGet, "/catalog", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "catalog", rr.Body.String())
+	assert.Equal(t, "text/plain", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `"v1"`, conditional.Load())
+	assert.EqualValues(t, 1, calls.Load())
+
+	rr = serveThroughCache(fh, http.MethodGet, "/catalog", nil)
+	assert.Equal(t, "catalog", rr.Body.String())
+	assert.EqualValues(t, 1, calls.Load(), "the refreshed entry is fresh again")
+}
+
+func TestPurgeResponseCache(t *testing.T) {
+	t.Parallel()
+	ts := &HTTPTriggerSet{logger: logr.Discard(), respCache: respcache.New(logr.Discard(), 0, nil)}
+	ts.respCache.Put(t.Context(), respcache.Key{Namespace: "default", Trigger: "ht", Request: "r"}, &respcache.Entry{
+		Status: http.StatusOK, Header: http.Header{}, Expires: time.Now().Add(time.Minute),
+	}, false)
+
+	rr := httptest.NewRecorder()
+	ts.purgeResponseCache(rr, httptest.NewRequest(http.MethodDelete, responseCachePath+"?namespace=default&name=ht", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var out responseCachePurge
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+	assert.Equal(t, 1, out.Purged)
+
+	rr = httptest.NewRecorder()
+	ts.purgeResponseCache(rr, httptest.NewRequest(http.MethodDelete, responseCachePath+"?namespace=default", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
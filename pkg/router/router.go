@@ -67,8 +67,12 @@ import (
 	"github.com/fission/fission/pkg/generated/clientset/versioned/scheme"
 	"github.com/fission/fission/pkg/mqtrigger/mqpub"
 	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/router/asyncinvoke/fnconfig"
 	"github.com/fission/fission/pkg/router/endpointcache"
+	"github.com/fission/fission/pkg/router/ratelimit"
+	"github.com/fission/fission/pkg/router/respcache"
 	"github.com/fission/fission/pkg/statestore"
+	storagesvcClient "github.com/fission/fission/pkg/storagesvc/client"
 	"github.com/fission/fission/pkg/svcinfo"
 	"github.com/fission/fission/pkg/tenant"
 	"github.com/fission/fission/pkg/throttler"
@@ -684,6 +688,12 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 	// it builds inherits it; the escape hatch restores the legacy plain-text body.
 	triggers.structuredErrors = cfg.structuredErrors
 	triggers.accessLog = cfg.accessLog
+	triggers.trustedProxies = cfg.trustedProxies
+	triggers.respCache = respcache.New(logger.WithName("respcache"), cfg.responseCacheMaxBytes, nil)
+	// Shadow copies go through the internal listener's `:<version>` routes,
+	// signed like the async deliverer's deliveries.
+	triggers.mirrorer = newMirrorer(logger.WithName("mirror"), svcinfo.NewEnvResolver(svcinfo.FlagValues{}).RouterInternalURL(),
+		[]byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET")), nil, defaultMirrorMaxInFlight)
 	// Incremental route updates (RFC-0013) are the only production path:
 	// per-event route-table diffs + handler indirection; muxes rebuild only on
 	// shape changes.
@@ -820,7 +830,32 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 		if qerr != nil {
 			return fmt.Errorf("async invocation: statestore queue capability: %w", qerr)
 		}
-		triggers.asyncInvoker = &asyncInvoker{queue: queue, logger: logger.WithName("async_invoker")}
+		triggers.asyncInvoker = &asyncInvoker{queue: queue, logger: logger.WithName("async_invoker"), maxInvokeDelay: cfg.asyncMaxInvokeDelay}
+
+		// Rate-limit buckets move to the store's KV so a limit holds across
+		// router replicas; without the statestore each replica keeps its own.
+		kv, kerr := caps.KV()
+		if kerr != nil {
+			return fmt.Errorf("async invocation: statestore kv capability: %w", kerr)
+		}
+		triggers.rateLimiter = ratelimit.New(logger.WithName("ratelimit"), kv)
+		// Triggers with a Shared cache keep their responses there too.
+		triggers.respCache = respcache.New(logger.WithName("respcache"), cfg.responseCacheMaxBytes, kv)
+		// Each invocation's lifecycle lands in the same KV, for the status API.
+		status := asyncinvoke.NewStatusStore(kv, cfg.asyncStatusTTL, logger.WithName("async_status"))
+		triggers.asyncInvoker.status = status
+
+		// Large request bodies spill to storagesvc, referenced from the envelope
+		// by content hash, and are collected when the invocation is acked or
+		// purged from the DLQ. Without it the body cap stays small enough for
+		// the queue message.
+		var payloads *asyncinvoke.Payloads
+		if cfg.asyncSpillStorageURL != "" {
+			blobs := storagesvcClient.MakeClient(cfg.asyncSpillStorageURL, []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET")))
+			payloads = asyncinvoke.NewPayloads(kv, blobs, 0, logger.WithName("async_payloads"))
+			triggers.asyncInvoker.payloads = payloads
+			triggers.asyncInvoker.maxBodyBytes = cfg.asyncMaxSpillBytes
+		}
 
 		// Topic destinations publish onto the same store's EventLog (RFC-0027): all
 		// current drivers expose every capability, so an EventLog failure here is a
@@ -867,15 +902,21 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 		triggers.asyncInvoker.publishTopic = publishTopic
 
 		internalURL := svcinfo.NewEnvResolver(svcinfo.FlagValues{}).RouterInternalURL()
-		deliverer := asyncinvoke.NewHTTPDeliverer(internalURL, []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET")), nil, logger.WithName("async_deliverer"))
+		deliverer := asyncinvoke.NewHTTPDeliverer(internalURL, []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET")), nil, logger.WithName("async_deliverer"),
+			asyncinvoke.WithResponseCapture(asyncinvoke.MaxStatusBodyBytes), asyncinvoke.WithPayloads(payloads))
 		// The dispatcher resolves each destination-chain hop's config from the
-		// Manager's Function cache (the fv1↔asyncinvoke mapping lives in async.go).
+		// Manager's Function cache (the fv1↔asyncinvoke mapping lives in fnconfig).
 		dispatcher := asyncinvoke.New(asyncinvoke.Options{
 			Queue:                 queue,
 			Deliverer:             deliverer,
 			Logger:                logger.WithName("async_dispatcher"),
-			ResolveFunctionConfig: newFunctionConfigResolver(crMgr.GetClient(), logger),
+			ResolveFunctionConfig: fnconfig.NewResolver(crMgr.GetClient(), logger),
 			PublishTopic:          publishTopic,
+			Status:                status,
+			Payloads:              payloads,
+			// Functions' delivery caps: in-flight slots in the KV, the rate
+			// through the same shared buckets as request rate limits.
+			Throttler: asyncinvoke.NewThrottler(kv, asyncDeliveryRate(triggers.rateLimiter), logger.WithName("async_throttle")),
 		})
 		if aerr := crMgr.Add(runnableFunc(func(rctx context.Context) error {
 			_ = dispatcher.Run(rctx) // returns only on ctx cancellation
@@ -883,6 +924,22 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 		})); aerr != nil {
 			return fmt.Errorf("async invocation: adding dispatcher runnable: %w", aerr)
 		}
+		// Webhook destinations drain their own queue, so a slow external
+		// endpoint never holds up function deliveries.
+		webhooks := asyncinvoke.NewWebhookDispatcher(asyncinvoke.WebhookOptions{
+			Queue:      queue,
+			Logger:     logger.WithName("async_webhook"),
+			SigningKey: newWebhookKeyStore(logger.WithName("webhook_keys"), kubeClient).signingKey,
+			// Private networks stay unreachable unless the operator names the
+			// in-cluster receivers webhooks are meant for.
+			AllowedNetworks: cfg.asyncWebhookAllowedNetworks,
+		})
+		if aerr := crMgr.Add(runnableFunc(func(rctx context.Context) error {
+			_ = webhooks.Run(rctx) // returns only on ctx cancellation
+			return nil
+		})); aerr != nil {
+			return fmt.Errorf("async invocation: adding webhook dispatcher runnable: %w", aerr)
+		}
 		asyncinvoke.RegisterQueueGauges(queue, asyncinvoke.DefaultQueue)
 		logger.Info("async invocation enabled", "queue", asyncinvoke.DefaultQueue, "deliveryURL", internalURL, "driver", cfg.statestoreDriver)
 	}
@@ -220,6 +220,7 @@ func (ts *HTTPTriggerSet) newFunctionHandlerBase(routeName string, functionMap m
 		rtLogger:                 routeLogger.WithName("roundtripper"),
 		policyByUID:              precomputePolicies(functionMap, fnTimeoutMap, streamIdleDefault),
 		basesByUID:               precomputeFunctionURLBases(functionMap),
+		breakerByUID:             precomputeBreakers(ts.circuitBreakers, functionMap),
 		// Direct callers can go async on any of these routes (RFC-0024); the
 		// dispatcher's own deliveries are gated out by the
 		// X-Fission-Invocation-Id guard in handler(), so they still proxy
@@ -236,6 +237,25 @@ func (ts *HTTPTriggerSet) newFunctionHandlerBase(routeName string, functionMap m
 func (ts *HTTPTriggerSet) buildTriggerHandler(trigger *fv1.HTTPTrigger, rr *resolveResult, fnTimeoutMap map[crd.CacheKeyUG]int) http.Handler {
 	fh := ts.newFunctionHandlerBase(trigger.Name, rr.functionMap, rr.functionWtDistributionList, fnTimeoutMap, rr.stickySource)
 	fh.httpTrigger = trigger
+	fh.apiKeys = ts.apiKeys
+	if ts.rateLimiter != nil {
+		fh.rateLimiter = ts.rateLimiter
+		fh.rateLimit = rateLimitFor(trigger, rr.stickySource)
+		fh.trustedProxies = ts.trustedProxies
+	}
+	if ts.respCache != nil {
+		fh.respCache = ts.respCache
+		fh.cacheRoute = responseCacheFor(trigger)
+	}
+	if ts.mirrorer != nil && rr.mirror != nil {
+		fh.mirrorer = ts.mirrorer
+		fh.mirror = rr.mirror
+	}
+	// triggerConfigError keeps triggers whose transform does not compile
+	// off the mux.
+	if rt, err := compileTransform(trigger.Spec.Transform); err == nil {
+		fh.transform = rt
+	}
 
 	// For FunctionReferenceTypeFunctionName the backend is fixed at build
 	// time; for FunctionReferenceTypeFunctionWeights (canary) the handler
@@ -313,15 +333,16 @@ func (ts *HTTPTriggerSet) newListenerMuxes(featureConfig *config.FeatureConfig)
 	}
 
 	// Panic recovery is added first so it wraps OUTERMOST (it also catches
-	// panics in the auth middleware and the dispatcher). Auth runs as a
-	// pre-match middleware. The internal mux deliberately omits both metrics
+	// panics in the auth middleware and the dispatcher). Caller-sent claim
+	// headers are stripped on every build, auth or not, and auth runs after
+	// as a pre-match middleware. The internal mux deliberately omits both metrics
 	// and auth: those are public-listener concerns, and its HMAC verifier is
 	// wrapped by the bundle process, not here (keeps this unit-testable
 	// without HMAC env state).
 	panicRecover := panicRecoveryMiddleware(ts.logger)
-	publicMW := []func(http.Handler) http.Handler{panicRecover}
+	publicMW := []func(http.Handler) http.Handler{panicRecover, stripClaimsMiddleware}
 	if featureConfig.AuthConfig.IsEnabled {
-		publicMW = append(publicMW, authMiddleware(featureConfig))
+		publicMW = append(publicMW, authMiddleware(ts.authenticatorFor(featureConfig.AuthConfig)))
 	}
 	publicOpts = append(publicOpts, httpmux.WithMiddleware(publicMW...))
 	internalOpts = append(internalOpts, httpmux.WithMiddleware(panicRecover))
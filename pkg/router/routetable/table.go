@@ -61,8 +61,8 @@ func (r *HandlerRef) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // RouteSpec is one HTTPTrigger's derived route: identity + change-detection
 // fields, the match shape, and the swappable handler. Shape equality drives
-// the materializer; (TriggerGen, FnGens, AliasGens, StickyGen) equality
-// drives handler swaps.
+// the materializer; (TriggerGen, FnGens, AliasGens, StickyGen, Mirror)
+// equality drives handler swaps.
 type RouteSpec struct {
 	// Identity / change detection. Generations (not ResourceVersions) on
 	// purpose: the reconcilers are registered with
@@ -125,6 +125,13 @@ type RouteSpec struct {
 	// re-apply the trigger only for ApplyTrigger to answer NoChange.
 	StickyGen int64
 
+	// Mirror is the BackendKey of the version the route mirrors traffic to
+	// ("" when it mirrors nothing). A mirror through an alias lists the alias
+	// in AliasGens, but a repoint of that alias moves neither its Generation
+	// nor any backend in FnGens, so the resolved target is part of the
+	// identity itself.
+	Mirror string
+
 	// Handler is the stable ref registered into the mux. Owned by the
 	// table: ApplyTrigger sets it on insert and preserves it across shape
 	// changes and handler swaps.
@@ -267,7 +274,8 @@ func (t *Table) ApplyTrigger(spec *RouteSpec, build func() http.Handler) ApplyRe
 	}
 	if old.shapeEqual(spec) {
 		if old.TriggerGen == spec.TriggerGen && maps.Equal(old.FnGens, spec.FnGens) &&
-			maps.Equal(old.AliasGens, spec.AliasGens) && old.StickyGen == spec.StickyGen {
+			maps.Equal(old.AliasGens, spec.AliasGens) && old.StickyGen == spec.StickyGen &&
+			old.Mirror == spec.Mirror {
 			return NoChange
 		}
 		old.Handler.Swap(build())
@@ -275,6 +283,7 @@ func (t *Table) ApplyTrigger(spec *RouteSpec, build func() http.Handler) ApplyRe
 		old.FnGens = spec.FnGens
 		old.AliasGens = spec.AliasGens
 		old.StickyGen = spec.StickyGen
+		old.Mirror = spec.Mirror
 		old.Created = spec.Created
 		old.Namespace, old.Name = spec.Namespace, spec.Name
 		t.reindexLocked(old)
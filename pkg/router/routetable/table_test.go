@@ -144,6 +144,27 @@ func TestApplyTriggerDecisionTable(t *testing.T) {
 		assert.Equal(t, NoChange, res)
 	})
 
+	t.Run("mirror target repoint with same shape is HandlerSwapped", func(t *testing.T) {
+		tbl := New()
+		mkSpec := func(mirror string) *RouteSpec {
+			return spec("u1", 1, map[string]int64{"hello": 1}, func(s *RouteSpec) {
+				s.AliasGens = map[string]int64{"next": 1}
+				s.Mirror = mirror
+			})
+		}
+		tbl.ApplyTrigger(mkSpec("hello@hello-v2"), func() http.Handler { return tagHandler("v2") })
+		ref := tbl.Snapshot()[0].Handler
+
+		// The mirror alias was repointed: a Status write, so neither its
+		// Generation nor any primary backend moved.
+		res := tbl.ApplyTrigger(mkSpec("hello@hello-v3"), func() http.Handler { return tagHandler("v3") })
+		assert.Equal(t, HandlerSwapped, res)
+		assert.Equal(t, "v3", serve(t, ref))
+
+		res = tbl.ApplyTrigger(mkSpec("hello@hello-v3"), mustNotBuild(t))
+		assert.Equal(t, NoChange, res)
+	})
+
 	t.Run("path change is ShapeChanged and preserves ref identity", func(t *testing.T) {
 		tbl := New()
 		tbl.ApplyTrigger(spec("u1", 1, map[string]int64{"fn": 10}, nil),
@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/utils/httpmux"
+)
+
+// maxTransformBodyBytes bounds the body the router buffers to validate it or
+// map parameters into it when the trigger sets no MaxBodyBytes.
+const maxTransformBodyBytes = 8 << 20
+
+var errBodyNotObject = errors.New("request body must be empty or a JSON object")
+
+// routeTransform is an HTTPTrigger's Transform, compiled at mux build.
+type routeTransform struct {
+	cfg    *fv1.HTTPTriggerTransform
+	schema *jsonschema.Resolved
+	// maxBody is cfg.MaxBodyBytes, -1 when unset.
+	maxBody int64
+	// bodyMappings is set when a mapping writes into the body, which then
+	// has to be buffered.
+	bodyMappings bool
+}
+
+// compileTransform compiles t, nil when the trigger has none. It fails on a
+// BodySchema that is not a valid, self-contained JSON Schema; CEL cannot
+// check that, so triggerConfigError surfaces it on the trigger's
+// RouteAdmitted condition.
+func compileTransform(t *fv1.HTTPTriggerTransform) (*routeTransform, error) {
+	if t == nil {
+		return nil, nil
+	}
+	rt := &routeTransform{cfg: t, maxBody: -1}
+	if t.MaxBodyBytes != nil {
+		rt.maxBody = *t.MaxBodyBytes
+	}
+	for _, m := range t.Mappings {
+		rt.bodyMappings = rt.bodyMappings || m.BodyField != ""
+	}
+	if t.BodySchema != nil && len(t.BodySchema.Raw) > 0 {
+		var s jsonschema.Schema
+		if err := json.Unmarshal(t.BodySchema.Raw, &s); err != nil {
+			return nil, fmt.Errorf("transform.bodySchema: %w", err)
+		}
+		resolved, err := s.Resolve(nil)
+		if err != nil {
+			return nil, fmt.Errorf("transform.bodySchema: %w", err)
+		}
+		rt.schema = resolved
+	}
+	return rt, nil
+}
+
+// request checks req against the transform and reshapes it for the
+// function. On rejection it returns the status to answer with and why.
+func (rt *routeTransform) request(req *http.Request) (int, error) {
+	hasBody := req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+	if ct := req.Header.Get("Content-Type"); hasBody && len(rt.cfg.AllowedContentTypes) > 0 && !contentTypeAllowed(ct, rt.cfg.AllowedContentTypes) {
+		return http.StatusUnsupportedMediaType, fmt.Errorf("content type %q is not allowed", ct)
+	}
+	if rt.maxBody >= 0 && req.ContentLength > rt.maxBody {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", rt.maxBody)
+	}
+
+	// The body is buffered when it is checked or written to, and when its
+	// length is only known by reading it.
+	validate := rt.schema != nil && !bodylessMethod(req.Method)
+	var body []byte
+	if hasBody && (validate || rt.bodyMappings || (rt.maxBody >= 0 && req.ContentLength < 0)) {
+		limit := rt.maxBody
+		if limit < 0 {
+			limit = maxTransformBodyBytes
+		}
+		var err error
+		if body, err = io.ReadAll(io.LimitReader(req.Body, limit+1)); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("reading request body: %w", err)
+		}
+		if int64(len(body)) > limit {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", limit)
+		}
+		setRequestBody(req, body)
+	}
+	if validate {
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("request body is not valid JSON: %w", err)
+		}
+		if err := rt.schema.Validate(v); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("request body does not match the schema: %w", err)
+		}
+	}
+
+	applyHeaderTransform(req.Header, rt.cfg.RequestHeaders)
+	return rt.mapParameters(req, body)
+}
+
+// mapParameters applies the transform's Mappings to req, whose buffered
+// body is body.
+func (rt *routeTransform) mapParameters(req *http.Request, body []byte) (int, error) {
+	if len(rt.cfg.Mappings) == 0 {
+		return 0, nil
+	}
+	vars := httpmux.Vars(req)
+	query := req.URL.Query()
+	var fields map[string]json.RawMessage
+	for _, m := range rt.cfg.Mappings {
+		var (
+			value string
+			ok    bool
+		)
+		if m.Path != "" {
+			value, ok = vars[m.Path]
+		} else if values := query[m.Query]; len(values) > 0 {
+			value, ok = values[0], true
+		}
+		if !ok {
+			continue
+		}
+		if m.Header != "" {
+			req.Header.Set(m.Header, value)
+			continue
+		}
+		if fields == nil {
+			fields = map[string]json.RawMessage{}
+			if len(bytes.TrimSpace(body)) > 0 {
+				if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+					return http.StatusBadRequest, errBodyNotObject
+				}
+			}
+		}
+		fields[m.BodyField], _ = json.Marshal(value)
+	}
+	if fields != nil {
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("encoding request body: %w", err)
+		}
+		setRequestBody(req, body)
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	return 0, nil
+}
+
+// response edits the headers of the function's response.
+func (rt *routeTransform) response(resp *http.Response) {
+	applyHeaderTransform(resp.Header, rt.cfg.ResponseHeaders)
+}
+
+// applyHeaderTransform edits h per t: Rename, then Remove, then Add.
+func applyHeaderTransform(h http.Header, t *fv1.HeaderTransform) {
+	if t == nil {
+		return
+	}
+	for from, to := range t.Rename {
+		if values := h.Values(from); len(values) > 0 {
+			values = append([]string(nil), values...)
+			h.Del(from)
+			h.Del(to)
+			for _, v := range values {
+				h.Add(to, v)
+			}
+		}
+	}
+	for _, name := range t.Remove {
+		h.Del(name)
+	}
+	for name, value := range t.Add {
+		h.Set(name, value)
+	}
+}
+
+// contentTypeAllowed reports whether the media type of ct is one of
+// allowed, an entry "type/*" allowing every subtype of type.
+func contentTypeAllowed(ct string, allowed []string) bool {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	for _, a := range allowed {
+		if prefix, ok := strings.CutSuffix(a, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == a {
+			return true
+		}
+	}
+	return false
+}
+
+// bodylessMethod reports whether requests with method carry no body to
+// validate.
+func bodylessMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// setRequestBody replaces req's body with body.
+func setRequestBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.TransferEncoding = nil
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+}
@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/utils/httpmux"
+)
+
+const itemSchema = `{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`
+
+func mustCompileTransform(t *testing.T, cfg *fv1.HTTPTriggerTransform) *routeTransform {
+	t.Helper()
+	rt, err := compileTransform(cfg)
+	require.NoError(t, err)
+	return rt
+}
+
+func TestCompileTransform(t *testing.T) {
+	t.Parallel()
+	rt, err := compileTransform(nil)
+	require.NoError(t, err)
+	assert.Nil(t, rt)
+
+	_, err = compileTransform(&fv1.HTTPTriggerTransform{BodySchema: &apiextensionsv1.JSON{Raw: []byte(itemSchema)}})
+	require.NoError(t, err)
+	_, err = compileTransform(&fv1.HTTPTriggerTransform{BodySchema: &apiextensionsv1.JSON{Raw: []byte(`{"properties":{"a":{"$ref":"#/$defs/missing"}}}`)}})
+	require.Error(t, err)
+	_, err = compileTransform(&fv1.HTTPTriggerTransform{BodySchema: &apiextensionsv1.JSON{Raw: []byte(`{"$ref":"https://example.com/item.json"}`)}})
+	require.Error(t, err, "remote references are not resolved")
+}
+
+func TestTransformRequestChecks(t *testing.T) {
+	t.Parallel()
+	maxBody := int64(32)
+	rt := mustCompileTransform(t, &fv1.HTTPTriggerTransform{
+		MaxBodyBytes:        &maxBody,
+		AllowedContentTypes: []string{"application/json", "text/*"},
+		BodySchema:          &apiextensionsv1.JSON{Raw: []byte(itemSchema)},
+	})
+	newReq := func(method, ct, body string) *http.Request {
+		req := httptest.NewRequest(method, "/items", strings.NewReader(body))
+		if ct != "" {
+			req.Header.Set("Content-Type", ct)
+		}
+		return req
+	}
+	for name, tc := range map[string]struct {
+		req  *http.Request
+		want int
+	}{
+		"valid body":               {newReq(http.MethodPost, "application/json; charset=utf-8", `{"name":"a"}`), 0},
+		"wildcard content type":    {newReq(http.MethodPut, "text/plain", `{"name":"a"}`), 0},
+		"content type not allowed": {newReq(http.MethodPost, "application/xml", `<a/>`), http.StatusUnsupportedMediaType},
+		"missing content type":     {newReq(http.MethodPost, "", `{"name":"a"}`), http.StatusUnsupportedMediaType},
+		"body over the limit":      {newReq(http.MethodPost, "application/json", `{"name":"`+strings.Repeat("a", 32)+`"}`), http.StatusRequestEntityTooLarge},
+		"schema mismatch":          {newReq(http.MethodPost, "application/json", `{"name":1}`), http.StatusBadRequest},
+		"not JSON":                 {newReq(http.MethodPost, "application/json", `{`), http.StatusBadRequest},
+		"empty body is not JSON":   {newReq(http.MethodPost, "", ``), http.StatusBadRequest},
+		"GET is not validated":     {newReq(http.MethodGet, "", ``), 0},
+		"chunked body over the limit": {
+			func() *http.Request {
+				req := newReq(http.MethodPatch, "application/json", `{"name":"`+strings.Repeat("a", 32)+`"}`)
+				req.ContentLength = -1
+				return req
+			}(),
+			http.StatusRequestEntityTooLarge,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			status, err := rt.request(tc.req)
+			assert.Equal(t, tc.want, status)
+			assert.Equal(t, tc.want != 0, err != nil, "error: %v", err)
+			if tc.want == 0 && tc.req.ContentLength > 0 {
+				body, err := io.ReadAll(tc.req.Body)
+				require.NoError(t, err)
+				assert.EqualValues(t, tc.req.ContentLength, len(body), "a checked body reaches the function whole")
+			}
+		})
+	}
+}
+
+func TestTransformRequestReshapes(t *testing.T) {
+	t.Parallel()
+	rt := mustCompileTransform(t, &fv1.HTTPTriggerTransform{
+		RequestHeaders: &fv1.HeaderTransform{
+			Add:    map[string]string{"X-Api-Version": "2"},
+			Remove: []string{"Cookie"},
+			Rename: map[string]string{"X-Old": "X-New"},
+		},
+		Mappings: []fv1.ParameterMapping{
+			{Path: "id", Header: "X-Item-Id"},
+			{Query: "tenant", BodyField: "tenant"},
+			{Path: "id", BodyField: "id"},
+			{Query: "absent", Header: "X-Absent"},
+		},
+	})
+	var (
+		status int
+		err    error
+		seen   *http.Request
+	)
+	m := httpmux.New()
+	m.HandleFunc("/items/{id}", func(_ http.ResponseWriter, req *http.Request) {
+		status, err = rt.request(req)
+		seen = req
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/items/42?tenant=acme&tenant=other", strings.NewReader(`{"count":10000000000000001}`))
+	req.Header.Set("Cookie", "a=b")
+	req.Header.Add("X-Old", "1")
+	req.Header.Add("X-Old", "2")
+	m.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	require.NoError(t, err)
+	require.Zero(t, status)
+
+	assert.Equal(t, "2", seen.Header.Get("X-Api-Version"))
+	assert.Empty(t, seen.Header.Get("Cookie"))
+	assert.Empty(t, seen.Header.Values("X-Old"))
+	assert.Equal(t, []string{"1", "2"}, seen.Header.Values("X-New"))
+	assert.Equal(t, "42", seen.Header.Get("X-Item-Id"))
+	assert.NotContains(t, seen.Header, "X-Absent")
+	body, err := io.ReadAll(seen.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":10000000000000001,"id":"42","tenant":"acme"}`, string(body))
+	assert.Contains(t, string(body), "10000000000000001", "other fields pass through verbatim")
+	assert.EqualValues(t, len(body), seen.ContentLength)
+
+	// An empty body becomes an object; any other body must be one.
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/items/7", nil))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(seen.Body)
+	assert.JSONEq(t, `{"id":"7"}`, string(body))
+	assert.Equal(t, "application/json", seen.Header.Get("Content-Type"))
+
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/items/7", strings.NewReader(`[1]`)))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.ErrorIs(t, err, errBodyNotObject)
+}
+
+// TestFunctionHandler_Transform: a rejected request never reaches the
+// function, an accepted one reaches it reshaped, and the function's
+// response headers are edited on the way back.
+func TestFunctionHandler_Transform(t *testing.T) {
+	t.Parallel()
+	var (
+		calls  atomic.Int64
+		tenant atomic.Value
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		tenant.Store(r.Header.Get("X-Tenant"))
+		w.Header().Set("Server", "function")
+		w.Header().Set("X-Internal", "secret")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(upstream.Close)
+
+	fh := newHandlerForUpstream(t, streamingFn("uid-transform", nil), upstream, 60)
+	fh.structuredErrors = true
+	fh.httpTrigger = &fv1.HTTPTrigger{}
+	fh.httpTrigger.Spec.Transform = &fv1.HTTPTriggerTransform{
+		BodySchema:      &apiextensionsv1.JSON{Raw: []byte(itemSchema)},
+		Mappings:        []fv1.ParameterMapping{{Query: "tenant", Header: "X-Tenant"}},
+		ResponseHeaders: &fv1.HeaderTransform{Remove: []string{"X-Internal"}, Rename: map[string]string{"Server": "X-Served-By"}},
+	}
+	fh.transform = mustCompileTransform(t, fh.httpTrigger.Spec.Transform)
+
+	rr := httptest.NewRecorder()
+	fh.handler(rr, httptest.NewRequest(http.MethodPost, "/items?tenant=acme", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var body ferror.InvocationError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, ferror.ReasonInvalidRequest, body.Reason)
+	assert.Zero(t, calls.Load(), "a rejected request costs the function nothing")
+
+	rr = httptest.NewRecorder()
+	fh.handler(rr, httptest.NewRequest(http.MethodPost, "/items?tenant=acme", strings.NewReader(`{"name":"a"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.EqualValues(t, 1, calls.Load())
+	assert.Equal(t, "acme", tenant.Load())
+	assert.Empty(t, rr.Header().Get("X-Internal"))
+	assert.Equal(t, "function", rr.Header().Get("X-Served-By"))
+}
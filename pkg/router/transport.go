@@ -348,6 +348,7 @@ func (roundTripper *RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Re
 			debugDumpResponse(logger, resp)
 		}
 		if err == nil {
+			roundTripper.reportOutcome(resp.StatusCode >= http.StatusInternalServerError)
 			// return response back to user
 			return resp, nil
 		}
@@ -390,6 +391,7 @@ func (roundTripper *RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Re
 					"url", req.URL.Host, "error", err.Error())
 			default:
 				logger.Error(err, "encountered non-network dial error")
+				roundTripper.reportOutcome(true)
 			}
 			return resp, err
 		}
@@ -460,6 +462,18 @@ func (roundTripper *RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Re
 	return nil, ferror.NewInvocationError(ferror.ComponentExecutor, ferror.ReasonExecutorUnavailable, e)
 }
 
+// reportOutcome feeds an index-admitted endpoint's answer to the outlier
+// ejection of a function with an OutlierEjection block. Dial failures are
+// not reported here: Invalidate already takes the endpoint out.
+func (roundTripper *RetryingRoundTripper) reportOutcome(failed bool) {
+	if roundTripper.release == nil || roundTripper.fn.Spec.OutlierEjection == nil {
+		return
+	}
+	if r, ok := roundTripper.resolver.(OutcomeReporter); ok {
+		r.ReportOutcome(roundTripper.fn, roundTripper.serviceURL, failed)
+	}
+}
+
 // jitter adds up to 20% positive random jitter to a backoff duration so that
 // many concurrent retriers (and multiple router replicas) don't retry in
 // lockstep and stampede a function pod as it recovers.
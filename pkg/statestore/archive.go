@@ -0,0 +1,315 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// An archive is JSON lines: an ArchiveHeader, then one ArchiveRecord per line
+// in the order Archiver.Export produced them. It carries no driver state
+// (message ids, lease epochs, watch revisions), so an archive written from
+// one driver loads into any other.
+const (
+	// ArchiveFormat is the header's format name.
+	ArchiveFormat = "fission-statestore-archive"
+	// ArchiveVersion is the version WriteArchive writes. ReadArchive refuses a
+	// newer one.
+	ArchiveVersion = 1
+	// ArchiveBatchBytes bounds, approximately, the records ReadArchive passes
+	// to one Import call, so a batch fits one request to the embedded store
+	// and one Raft log entry.
+	ArchiveBatchBytes = 1 << 20
+)
+
+// ArchiveHeader is the first line of an archive.
+type ArchiveHeader struct {
+	Format    string        `json:"format"`
+	Version   int           `json:"version"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Filter    ArchiveFilter `json:"filter,omitzero"`
+}
+
+// ArchiveFilter selects what Archiver.Export writes. The zero filter selects
+// the whole store.
+//
+// A Namespace selects the KV scopes of that namespace and the streams named
+// "<kind>/<namespace>/...", as topics and watch logs are, plus those listed
+// in Streams (workflow histories are keyed by run UID, so the caller names
+// them). Queues are shared by every namespace, so a namespace's export has no
+// messages.
+type ArchiveFilter struct {
+	Namespace string   `json:"namespace,omitempty"`
+	Streams   []string `json:"streams,omitempty"`
+}
+
+// MatchScope reports whether f selects the KV scope s.
+func (f ArchiveFilter) MatchScope(s Scope) bool {
+	return f.Namespace == "" || s.Namespace == f.Namespace
+}
+
+// MatchStream reports whether f selects stream.
+func (f ArchiveFilter) MatchStream(stream string) bool {
+	if f.Namespace == "" || slices.Contains(f.Streams, stream) {
+		return true
+	}
+	_, rest, ok := strings.Cut(stream, "/")
+	ns, _, nested := strings.Cut(rest, "/")
+	return ok && nested && ns == f.Namespace
+}
+
+// MatchQueues reports whether f selects the queues.
+func (f ArchiveFilter) MatchQueues() bool {
+	return f.Namespace == ""
+}
+
+// ArchiveRecord is one line of an archive after the header. Exactly one field
+// is set.
+type ArchiveRecord struct {
+	KV      *ArchivedKey     `json:"kv,omitempty"`
+	Stream  *ArchivedStream  `json:"stream,omitempty"`
+	Event   *ArchivedEvent   `json:"event,omitempty"`
+	Message *ArchivedMessage `json:"message,omitempty"`
+}
+
+// ArchivedKey is a live KV key. ExpiresAt is zero when it has no TTL.
+type ArchivedKey struct {
+	Scope     Scope     `json:"scope"`
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value"`
+	Version   int64     `json:"version"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+// ArchivedStream is an EventLog stream's head. Its retained events follow it
+// as ArchivedEvent records.
+type ArchivedStream struct {
+	Stream string `json:"stream"`
+	Head   int64  `json:"head"`
+}
+
+// ArchivedEvent is one retained event of a stream.
+type ArchivedEvent struct {
+	Stream  string    `json:"stream"`
+	Seq     int64     `json:"seq"`
+	Type    string    `json:"type"`
+	Payload []byte    `json:"payload"`
+	At      time.Time `json:"at"`
+}
+
+// ArchivedMessageState is the state of an archived message.
+type ArchivedMessageState string
+
+const (
+	ArchivedQueued ArchivedMessageState = "queued"
+	ArchivedDead   ArchivedMessageState = "dead"
+)
+
+// ArchivedMessage is a queued or dead-lettered message. ID is the id it had
+// in the exported store, for reference only. DedupKey and Group are those of
+// a queued message (dead-lettering settles both), Deferred whether a Defer
+// returned it, and Reason and DiedAt those of a dead one.
+type ArchivedMessage struct {
+	Queue      string               `json:"queue"`
+	ID         string               `json:"id"`
+	Body       []byte               `json:"body"`
+	State      ArchivedMessageState `json:"state"`
+	Attempts   int                  `json:"attempts,omitempty"`
+	VisibleAt  time.Time            `json:"visibleAt,omitzero"`
+	EnqueuedAt time.Time            `json:"enqueuedAt"`
+	DedupKey   string               `json:"dedupKey,omitempty"`
+	Group      string               `json:"group,omitempty"`
+	Deferred   bool                 `json:"deferred,omitempty"`
+	Reason     string               `json:"reason,omitempty"`
+	DiedAt     time.Time            `json:"diedAt,omitzero"`
+}
+
+// Validate returns an error wrapping ErrInvalidArchive if r is malformed.
+func (r ArchiveRecord) Validate() error {
+	set := 0
+	for _, p := range []bool{r.KV != nil, r.Stream != nil, r.Event != nil, r.Message != nil} {
+		if p {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("%w: a record must hold exactly one of kv, stream, event and message", ErrInvalidArchive)
+	}
+	switch {
+	case r.KV != nil && r.KV.Version < 1:
+		return fmt.Errorf("%w: key %q has version %d", ErrInvalidArchive, r.KV.Key, r.KV.Version)
+	case r.Stream != nil && (r.Stream.Stream == "" || r.Stream.Head < 0):
+		return fmt.Errorf("%w: stream %q has head %d", ErrInvalidArchive, r.Stream.Stream, r.Stream.Head)
+	case r.Event != nil && r.Event.Seq < 1:
+		return fmt.Errorf("%w: event of stream %q has seq %d", ErrInvalidArchive, r.Event.Stream, r.Event.Seq)
+	case r.Message != nil && r.Message.Queue == "":
+		return fmt.Errorf("%w: message %q has no queue", ErrInvalidArchive, r.Message.ID)
+	case r.Message != nil && r.Message.State != ArchivedQueued && r.Message.State != ArchivedDead:
+		return fmt.Errorf("%w: message %q has unknown state %q", ErrInvalidArchive, r.Message.ID, r.Message.State)
+	case r.Message != nil && r.Message.Attempts < 0:
+		return fmt.Errorf("%w: message %q has %d attempts", ErrInvalidArchive, r.Message.ID, r.Message.Attempts)
+	}
+	return nil
+}
+
+// ValidateImport returns an error wrapping ErrInvalidArchive if a record of
+// an Import call is malformed, or an event record is not above the events
+// before it in its stream or is above the stream's head. stored returns a
+// stream's last stored event seq and its head before the call. Drivers call
+// it before importing anything, so an Import that fails this way changes
+// nothing.
+func ValidateImport(recs []ArchiveRecord, stored func(stream string) (last, head int64, err error)) error {
+	type cursor struct{ last, head int64 }
+	streams := map[string]*cursor{}
+	for _, r := range recs {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		switch {
+		case r.Stream != nil:
+			streams[r.Stream.Stream] = &cursor{head: r.Stream.Head}
+		case r.Event != nil:
+			c := streams[r.Event.Stream]
+			if c == nil {
+				last, head, err := stored(r.Event.Stream)
+				if err != nil {
+					return err
+				}
+				c = &cursor{last: last, head: head}
+				streams[r.Event.Stream] = c
+			}
+			if r.Event.Seq <= c.last || r.Event.Seq > c.head {
+				return fmt.Errorf("%w: event %d of stream %q is not between its last event %d and its head %d",
+					ErrInvalidArchive, r.Event.Seq, r.Event.Stream, c.last, c.head)
+			}
+			c.last = r.Event.Seq
+		}
+	}
+	return nil
+}
+
+// size approximates r's encoded size, for batching.
+func (r ArchiveRecord) size() int {
+	const overhead = 128
+	switch {
+	case r.KV != nil:
+		return overhead + len(r.KV.Scope.Namespace) + len(r.KV.Scope.Owner) + len(r.KV.Scope.Keyspace) + len(r.KV.Key) + len(r.KV.Value)
+	case r.Stream != nil:
+		return overhead + len(r.Stream.Stream)
+	case r.Event != nil:
+		return overhead + len(r.Event.Stream) + len(r.Event.Type) + len(r.Event.Payload)
+	case r.Message != nil:
+		m := r.Message
+		return overhead + len(m.Queue) + len(m.ID) + len(m.Body) + len(m.DedupKey) + len(m.Group) + len(m.Reason)
+	}
+	return overhead
+}
+
+// ArchiveStats counts an archive's records.
+type ArchiveStats struct {
+	Keys, Streams, Events, Messages int64
+}
+
+func (s *ArchiveStats) count(r ArchiveRecord) {
+	switch {
+	case r.KV != nil:
+		s.Keys++
+	case r.Stream != nil:
+		s.Streams++
+	case r.Event != nil:
+		s.Events++
+	case r.Message != nil:
+		s.Messages++
+	}
+}
+
+// WriteArchive writes the archive of what f selects from a to w.
+func WriteArchive(ctx context.Context, w io.Writer, a Archiver, f ArchiveFilter) (ArchiveStats, error) {
+	var st ArchiveStats
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	h := ArchiveHeader{Format: ArchiveFormat, Version: ArchiveVersion, CreatedAt: time.Now().UTC(), Filter: f}
+	if err := enc.Encode(h); err != nil {
+		return st, err
+	}
+	err := a.Export(ctx, f, func(r ArchiveRecord) error {
+		st.count(r)
+		return enc.Encode(r)
+	})
+	if err != nil {
+		return st, err
+	}
+	return st, bw.Flush()
+}
+
+// ReadArchive loads the archive in r into a, in Import calls of about
+// ArchiveBatchBytes. It returns the archive's header and what it imported;
+// on an error, the batches before the failing one are imported.
+func ReadArchive(ctx context.Context, r io.Reader, a Archiver) (ArchiveHeader, ArchiveStats, error) {
+	var (
+		h  ArchiveHeader
+		st ArchiveStats
+	)
+	dec := json.NewDecoder(bufio.NewReader(r))
+	if err := dec.Decode(&h); err != nil {
+		return h, st, fmt.Errorf("%w: reading the header: %v", ErrInvalidArchive, err)
+	}
+	if h.Format != ArchiveFormat {
+		return h, st, fmt.Errorf("%w: format %q is not %q", ErrInvalidArchive, h.Format, ArchiveFormat)
+	}
+	if h.Version < 1 || h.Version > ArchiveVersion {
+		return h, st, fmt.Errorf("%w: version %d is not supported (up to %d)", ErrInvalidArchive, h.Version, ArchiveVersion)
+	}
+	var (
+		batch     []ArchiveRecord
+		batchSize int
+		pending   ArchiveStats
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := a.Import(ctx, batch); err != nil {
+			return err
+		}
+		st.Keys += pending.Keys
+		st.Streams += pending.Streams
+		st.Events += pending.Events
+		st.Messages += pending.Messages
+		batch, batchSize, pending = nil, 0, ArchiveStats{}
+		return nil
+	}
+	for {
+		var rec ArchiveRecord
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return h, st, fmt.Errorf("%w: record %d: %v", ErrInvalidArchive, st.total()+pending.total()+1, err)
+		}
+		if n := rec.size(); batchSize+n > ArchiveBatchBytes {
+			if err := flush(); err != nil {
+				return h, st, err
+			}
+		}
+		batch = append(batch, rec)
+		batchSize += rec.size()
+		pending.count(rec)
+	}
+	return h, st, flush()
+}
+
+func (s ArchiveStats) total() int64 {
+	return s.Keys + s.Streams + s.Events + s.Messages
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestore
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchArchiver exports recs and records the batches it is asked to import.
+type batchArchiver struct {
+	recs    []ArchiveRecord
+	batches [][]ArchiveRecord
+}
+
+func (a *batchArchiver) Export(_ context.Context, _ ArchiveFilter, fn func(ArchiveRecord) error) error {
+	for _, r := range a.recs {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *batchArchiver) Import(_ context.Context, recs []ArchiveRecord) error {
+	a.batches = append(a.batches, recs)
+	return nil
+}
+
+func TestArchiveFilterMatchStream(t *testing.T) {
+	f := ArchiveFilter{Namespace: "ns", Streams: []string{"wfrun/uid-1"}}
+	for stream, want := range map[string]bool{
+		"topic/ns/orders":  true,
+		"kwatch/ns/a/b":    true,
+		"wfrun/uid-1":      true,
+		"wfrun/uid-2":      false,
+		"topic/other/x":    false,
+		"topic/ns":         false,
+		"topic/nsx/orders": false,
+	} {
+		assert.Equal(t, want, f.MatchStream(stream), stream)
+	}
+	assert.True(t, ArchiveFilter{}.MatchStream("wfrun/uid-2"), "the zero filter selects every stream")
+	assert.False(t, f.MatchQueues())
+}
+
+func TestValidateImport(t *testing.T) {
+	stored := func(string) (int64, int64, error) { return 3, 5, nil }
+	event := func(seq int64) ArchiveRecord {
+		return ArchiveRecord{Event: &ArchivedEvent{Stream: "s", Seq: seq}}
+	}
+	require.NoError(t, ValidateImport([]ArchiveRecord{event(4), event(5)}, stored), "events above the stored ones, up to the head")
+	require.ErrorIs(t, ValidateImport([]ArchiveRecord{event(3)}, stored), ErrInvalidArchive, "an event already stored")
+	require.ErrorIs(t, ValidateImport([]ArchiveRecord{event(6)}, stored), ErrInvalidArchive, "an event above the head")
+	require.ErrorIs(t, ValidateImport([]ArchiveRecord{event(5), event(4)}, stored), ErrInvalidArchive, "events out of order")
+
+	reset := ArchiveRecord{Stream: &ArchivedStream{Stream: "s", Head: 2}}
+	require.NoError(t, ValidateImport([]ArchiveRecord{reset, event(1), event(2)}, stored), "a stream record replaces the stored events")
+	require.ErrorIs(t, ValidateImport([]ArchiveRecord{reset, event(3)}, stored), ErrInvalidArchive)
+}
+
+func TestReadArchiveRejectsHeader(t *testing.T) {
+	for name, archive := range map[string]string{
+		"empty":      "",
+		"format":     `{"format":"tar","version":1}`,
+		"newer":      `{"format":"fission-statestore-archive","version":2}`,
+		"bad record": `{"format":"fission-statestore-archive","version":1}` + "\n{",
+	} {
+		a := &batchArchiver{}
+		_, _, err := ReadArchive(t.Context(), strings.NewReader(archive), a)
+		require.ErrorIs(t, err, ErrInvalidArchive, name)
+		assert.Empty(t, a.batches, name)
+	}
+}
+
+func TestArchiveRoundTripBatches(t *testing.T) {
+	value := bytes.Repeat([]byte("v"), ArchiveBatchBytes/3)
+	src := &batchArchiver{}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		src.recs = append(src.recs, ArchiveRecord{KV: &ArchivedKey{Key: key, Value: value, Version: 1}})
+	}
+	src.recs = append(src.recs,
+		ArchiveRecord{Stream: &ArchivedStream{Stream: "s", Head: 1}},
+		ArchiveRecord{Event: &ArchivedEvent{Stream: "s", Seq: 1}},
+	)
+	var buf bytes.Buffer
+	st, err := WriteArchive(t.Context(), &buf, src, ArchiveFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, ArchiveStats{Keys: 4, Streams: 1, Events: 1}, st)
+
+	dst := &batchArchiver{}
+	h, got, err := ReadArchive(t.Context(), &buf, dst)
+	require.NoError(t, err)
+	assert.Equal(t, ArchiveVersion, h.Version)
+	assert.Equal(t, st, got)
+	require.Len(t, dst.batches, 2, "records are imported in batches of about ArchiveBatchBytes")
+	var all []ArchiveRecord
+	for _, b := range dst.batches {
+		all = append(all, b...)
+	}
+	assert.Equal(t, src.recs, all)
+}
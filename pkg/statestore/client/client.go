@@ -216,6 +216,59 @@ func (c *Client) List(ctx context.Context, s statestore.Scope, prefix string, pa
 	return statestore.KeyPage{Keys: resp.Keys, Next: resp.Next}, nil
 }
 
+// Txn implements statestore.TxnKV by forwarding the transaction and its
+// budget to the server, whose backing driver applies it.
+func (c *Client) Txn(ctx context.Context, s statestore.Scope, t statestore.Txn, maxKeys int64) ([]int64, error) {
+	var resp httpapi.KVTxnResp
+	if err := postJSON(c, ctx, httpapi.PathKVTxn, httpapi.KVTxnReq{Scope: s, Txn: t, MaxKeys: maxKeys}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Versions, nil
+}
+
+// Revision implements statestore.WatchableKV.
+func (c *Client) Revision(ctx context.Context, s statestore.Scope) (int64, error) {
+	var resp httpapi.KVRevisionResp
+	if err := postJSON(c, ctx, httpapi.PathKVRevision, httpapi.KVRevisionReq{Scope: s}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Revision, nil
+}
+
+// Watch implements statestore.WatchableKV as a loop of long-polls, each held
+// by the server until there are changes or the wait (kept inside the http
+// client's timeout) runs out. The first poll does not wait, so fn hears that
+// the watch is established promptly.
+func (c *Client) Watch(ctx context.Context, s statestore.Scope, prefix string, fromRevision int64,
+	fn func([]statestore.KVEvent, int64) error) error {
+	wait := httpapi.MaxWatchWait
+	if t := c.hc.Timeout; t > 0 {
+		wait = min(wait, t/2)
+	}
+	req := httpapi.KVWatchReq{Scope: s, Prefix: prefix, FromRevision: fromRevision}
+	established := false
+	for {
+		var resp httpapi.KVWatchResp
+		if err := postJSON(c, ctx, httpapi.PathKVWatch, req, &resp); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if !established || len(resp.Events) > 0 || resp.Revision > req.FromRevision {
+			established = true
+			req.FromRevision = max(req.FromRevision, resp.Revision)
+			if err := fn(resp.Events, req.FromRevision); err != nil {
+				return err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		req.WaitNanos = wait.Nanoseconds()
+	}
+}
+
 // --- EventLog ---
 
 func (c *Client) Append(ctx context.Context, stream string, expectedSeq int64, events []statestore.Event) (int64, error) {
@@ -256,7 +309,7 @@ func (c *Client) Head(ctx context.Context, stream string) (int64, error) {
 func (c *Client) Enqueue(ctx context.Context, queue string, msg statestore.Message, o statestore.EnqueueOptions) (string, error) {
 	var resp httpapi.QueueEnqueueResp
 	if err := postJSON(c, ctx, httpapi.PathQueueEnqueue, httpapi.QueueEnqueueReq{
-		Queue: queue, Body: msg.Body, DelayNanos: o.Delay.Nanoseconds(), DedupKey: o.DedupKey,
+		Queue: queue, Body: msg.Body, DelayNanos: o.Delay.Nanoseconds(), DedupKey: o.DedupKey, Group: o.Group,
 	}, &resp); err != nil {
 		return "", err
 	}
@@ -271,6 +324,16 @@ func (c *Client) Lease(ctx context.Context, queue string, n int, leaseFor time.D
 	return resp.Messages, nil
 }
 
+// LeaseGrouped implements statestore.GroupedQueue by asking the server for a
+// grouped lease, which its backing driver performs.
+func (c *Client) LeaseGrouped(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	var resp httpapi.QueueLeaseResp
+	if err := postJSON(c, ctx, httpapi.PathQueueLease, httpapi.QueueLeaseReq{Queue: queue, N: n, LeaseForNanos: leaseFor.Nanoseconds(), Grouped: true}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Messages, nil
+}
+
 func (c *Client) Ack(ctx context.Context, receipt string) error {
 	return postNoResponse(c, ctx, httpapi.PathQueueAck, httpapi.QueueAckReq{Receipt: receipt})
 }
@@ -279,6 +342,10 @@ func (c *Client) Nack(ctx context.Context, receipt string, retryAfter time.Durat
 	return postNoResponse(c, ctx, httpapi.PathQueueNack, httpapi.QueueNackReq{Receipt: receipt, RetryAfterNanos: retryAfter.Nanoseconds()})
 }
 
+func (c *Client) Defer(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	return postNoResponse(c, ctx, httpapi.PathQueueDefer, httpapi.QueueDeferReq{Receipt: receipt, RetryAfterNanos: retryAfter.Nanoseconds()})
+}
+
 func (c *Client) Kill(ctx context.Context, receipt string, reason string) error {
 	return postNoResponse(c, ctx, httpapi.PathQueueKill, httpapi.QueueKillReq{Receipt: receipt, Reason: reason})
 }
@@ -316,6 +383,58 @@ func (c *Client) Stats(ctx context.Context, queue string) (statestore.QueueStats
 		Visible:          resp.Visible,
 		Leased:           resp.Leased,
 		Dead:             resp.Dead,
+		Deferred:         resp.Deferred,
 		OldestVisibleAge: time.Duration(resp.OldestVisibleAgeNanos),
 	}, nil
 }
+
+// --- Archiver ---
+
+var _ statestore.Archiver = (*Client)(nil)
+
+// Export implements statestore.Archiver over the streamed export endpoint. An
+// export runs as long as the store is large, so it is sent without the
+// client's request timeout; ctx bounds it.
+func (c *Client) Export(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	body, err := json.Marshal(httpapi.ArchiveExportReq{Filter: f})
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+httpapi.PathArchiveExport, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := (&http.Client{Transport: c.hc.Transport}).Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return decodeErr(resp)
+	}
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var line httpapi.ArchiveExportLine
+		if err := dec.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("statestore/client: reading export: %w", err)
+		}
+		switch {
+		case line.Error != nil:
+			return httpapi.CodeToErr(line.Error.Code, line.Error.Message)
+		case line.End:
+			return nil
+		}
+		if err := fn(line.ArchiveRecord); err != nil {
+			return err
+		}
+	}
+}
+
+// Import implements statestore.Archiver.
+func (c *Client) Import(ctx context.Context, recs []statestore.ArchiveRecord) error {
+	return postNoResponse(c, ctx, httpapi.PathArchiveImport, httpapi.ArchiveImportReq{Records: recs})
+}
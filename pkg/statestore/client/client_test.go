@@ -40,6 +40,7 @@ func clientCaps(t *testing.T) statestore.Capabilities {
 func TestConformance_Client(t *testing.T) {
 	statestoretest.RunConformance(t, clientCaps)
 	statestoretest.RunKVLinearizability(t, clientCaps)
+	statestoretest.RunWatchConformance(t, clientCaps)
 }
 
 func TestClient_TimingSmoke(t *testing.T) {
@@ -16,13 +16,13 @@ import (
 const defaultDriver = "memory"
 
 // Config selects and configures a driver set. It is read once at component start
-// (via FromEnv or an explicit literal) and passed to Open. Fields beyond Driver
-// are added as drivers land (Redis address, embedded-store URL).
+// (via FromEnv or an explicit literal) and passed to Open.
 type Config struct {
 	// Driver names the registered driver to open. Empty means "memory".
 	Driver string
 	// DSN is the driver connection string: a Postgres DSN for the "postgres"
-	// driver, a file path for the "sqlite" driver. Ignored by "memory".
+	// driver, a redis:// URL for the "redis" driver, a file path for the
+	// "sqlite" driver. Ignored by "memory".
 	DSN string
 }
 
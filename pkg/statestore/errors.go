@@ -22,6 +22,15 @@ var (
 	// ErrInvalidReceipt is returned by Queue settle methods for a malformed or
 	// stale (wrong-epoch) lease receipt.
 	ErrInvalidReceipt = errors.New("statestore: invalid or stale lease receipt")
+	// ErrCompacted is returned by WatchableKV.Watch when fromRevision is older
+	// than the scope's retained change history.
+	ErrCompacted = errors.New("statestore: revision compacted")
+	// ErrInvalidTxn is returned by TxnKV.Txn for a malformed transaction,
+	// wrapped with what is wrong with it.
+	ErrInvalidTxn = errors.New("statestore: invalid transaction")
+	// ErrInvalidArchive is returned by Archiver.Import and ReadArchive for a
+	// malformed or unsupported archive, wrapped with what is wrong with it.
+	ErrInvalidArchive = errors.New("statestore: invalid archive")
 	// ErrClosed is returned after the store has been closed.
 	ErrClosed = errors.New("statestore: store closed")
 )
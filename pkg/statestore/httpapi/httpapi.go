@@ -11,6 +11,7 @@ package httpapi
 
 import (
 	"errors"
+	"time"
 
 	"github.com/fission/fission/pkg/statestore"
 )
@@ -22,6 +23,10 @@ import (
 // generous headroom.
 const MaxRequestBytes = 4 << 20
 
+// MaxWatchWait caps how long a KV watch request is held open waiting for a
+// change, below the client driver's 30s request timeout.
+const MaxWatchWait = 20 * time.Second
+
 // Route paths, versioned under /v1.
 const (
 	PathHealthz         = "/healthz"
@@ -30,6 +35,9 @@ const (
 	PathKVSet           = "/v1/kv/set"
 	PathKVDelete        = "/v1/kv/delete"
 	PathKVList          = "/v1/kv/list"
+	PathKVWatch         = "/v1/kv/watch"
+	PathKVRevision      = "/v1/kv/revision"
+	PathKVTxn           = "/v1/kv/txn"
 	PathEventAppend     = "/v1/eventlog/append"
 	PathEventRead       = "/v1/eventlog/read"
 	PathEventTrim       = "/v1/eventlog/trim"
@@ -39,10 +47,13 @@ const (
 	PathQueueAck        = "/v1/queue/ack"
 	PathQueueNack       = "/v1/queue/nack"
 	PathQueueKill       = "/v1/queue/kill"
+	PathQueueDefer      = "/v1/queue/defer"
 	PathQueueDeadLetter = "/v1/queue/deadletters"
 	PathQueueRedrive    = "/v1/queue/redrive"
 	PathQueuePurge      = "/v1/queue/purge"
 	PathQueueStats      = "/v1/queue/stats"
+	PathArchiveExport   = "/v1/archive/export"
+	PathArchiveImport   = "/v1/archive/import"
 )
 
 // Error is the JSON error envelope. Code is a stable machine string mapped to a
@@ -67,6 +78,9 @@ const (
 	CodeQuotaExceeded         = "quota_exceeded"
 	CodeInvalidReceipt        = "invalid_receipt"
 	CodeClosed                = "closed"
+	CodeCompacted             = "compacted"
+	CodeInvalidTxn            = "invalid_txn"
+	CodeInvalidArchive        = "invalid_archive"
 	CodeBadRequest            = "bad_request"
 	CodeInternal              = "internal"
 )
@@ -79,6 +93,9 @@ var codeToErr = map[string]error{
 	CodeQuotaExceeded:         statestore.ErrQuotaExceeded,
 	CodeInvalidReceipt:        statestore.ErrInvalidReceipt,
 	CodeClosed:                statestore.ErrClosed,
+	CodeCompacted:             statestore.ErrCompacted,
+	CodeInvalidTxn:            statestore.ErrInvalidTxn,
+	CodeInvalidArchive:        statestore.ErrInvalidArchive,
 }
 
 // ErrToCode maps a statestore error to (httpStatus, wireCode).
@@ -98,6 +115,12 @@ func ErrToCode(err error) (status int, code string) {
 		return 400, CodeInvalidReceipt
 	case errors.Is(err, statestore.ErrClosed):
 		return 503, CodeClosed
+	case errors.Is(err, statestore.ErrCompacted):
+		return 410, CodeCompacted
+	case errors.Is(err, statestore.ErrInvalidTxn):
+		return 400, CodeInvalidTxn
+	case errors.Is(err, statestore.ErrInvalidArchive):
+		return 400, CodeInvalidArchive
 	default:
 		return 500, CodeInternal
 	}
@@ -150,6 +173,44 @@ type KVListResp struct {
 	Next string   `json:"next"`
 }
 
+// KVWatchReq is one long-poll of a statestore.WatchableKV watch: the server
+// holds it up to WaitNanos (capped at MaxWatchWait) for a change under Prefix
+// after FromRevision. WaitNanos <= 0 answers as soon as the watch is
+// established.
+type KVWatchReq struct {
+	Scope        statestore.Scope `json:"scope"`
+	Prefix       string           `json:"prefix"`
+	FromRevision int64            `json:"fromRevision"`
+	WaitNanos    int64            `json:"waitNanos,omitempty"`
+}
+
+// KVWatchResp carries the changes found (possibly none) and the revision the
+// watch reached, which the next poll resumes from.
+type KVWatchResp struct {
+	Events   []statestore.KVEvent `json:"events"`
+	Revision int64                `json:"revision"`
+}
+
+// KVRevisionReq asks for a scope's current revision (0 before its first
+// change), the point a watch starts from to see every later change.
+type KVRevisionReq struct {
+	Scope statestore.Scope `json:"scope"`
+}
+type KVRevisionResp struct {
+	Revision int64 `json:"revision"`
+}
+
+// KVTxnReq is a statestore.TxnKV transaction; MaxKeys is its live-key
+// budget, as in KVSetReq.
+type KVTxnReq struct {
+	Scope   statestore.Scope `json:"scope"`
+	Txn     statestore.Txn   `json:"txn"`
+	MaxKeys int64            `json:"maxKeys,omitempty"`
+}
+type KVTxnResp struct {
+	Versions []int64 `json:"versions"`
+}
+
 // --- EventLog ---
 
 type EventAppendReq struct {
@@ -186,6 +247,7 @@ type QueueEnqueueReq struct {
 	Body       []byte `json:"body"`
 	DelayNanos int64  `json:"delayNanos,omitempty"`
 	DedupKey   string `json:"dedupKey,omitempty"`
+	Group      string `json:"group,omitempty"`
 }
 type QueueEnqueueResp struct {
 	ID string `json:"id"`
@@ -194,6 +256,9 @@ type QueueLeaseReq struct {
 	Queue         string `json:"queue"`
 	N             int    `json:"n"`
 	LeaseForNanos int64  `json:"leaseForNanos"`
+	// Grouped requests a grouped lease (statestore.GroupedQueue): at most one
+	// message per message group is leased at a time.
+	Grouped bool `json:"grouped,omitempty"`
 }
 type QueueLeaseResp struct {
 	Messages []statestore.LeasedMessage `json:"messages"`
@@ -205,6 +270,10 @@ type QueueNackReq struct {
 	Receipt         string `json:"receipt"`
 	RetryAfterNanos int64  `json:"retryAfterNanos"`
 }
+type QueueDeferReq struct {
+	Receipt         string `json:"receipt"`
+	RetryAfterNanos int64  `json:"retryAfterNanos"`
+}
 type QueueKillReq struct {
 	Receipt string `json:"receipt"`
 	Reason  string `json:"reason"`
@@ -236,5 +305,29 @@ type QueueStatsResp struct {
 	Visible               int64 `json:"visible"`
 	Leased                int64 `json:"leased"`
 	Dead                  int64 `json:"dead"`
+	Deferred              int64 `json:"deferred,omitempty"`
 	OldestVisibleAgeNanos int64 `json:"oldestVisibleAgeNanos"`
 }
+
+// --- Archive ---
+
+// ArchiveExportReq selects what a statestore.Archiver export streams.
+type ArchiveExportReq struct {
+	Filter statestore.ArchiveFilter `json:"filter,omitzero"`
+}
+
+// ArchiveExportLine is one line of the export response, which is JSON lines
+// rather than one document so an export of any size streams. Each line is a
+// record, and the last is End, or Error if the export failed part way: a
+// response without either was cut short.
+type ArchiveExportLine struct {
+	statestore.ArchiveRecord
+	Error *Error `json:"error,omitempty"`
+	End   bool   `json:"end,omitempty"`
+}
+
+// ArchiveImportReq is one statestore.Archiver Import call; its size is bounded
+// by MaxRequestBytes, which statestore.ArchiveBatchBytes batches fit.
+type ArchiveImportReq struct {
+	Records []statestore.ArchiveRecord `json:"records"`
+}
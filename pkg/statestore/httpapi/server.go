@@ -5,6 +5,8 @@
 package httpapi
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -26,6 +28,9 @@ func NewHandler(caps statestore.Capabilities) http.Handler {
 	mux.HandleFunc("POST "+PathKVSet, h.kvSet)
 	mux.HandleFunc("POST "+PathKVDelete, h.kvDelete)
 	mux.HandleFunc("POST "+PathKVList, h.kvList)
+	mux.HandleFunc("POST "+PathKVWatch, h.kvWatch)
+	mux.HandleFunc("POST "+PathKVRevision, h.kvRevision)
+	mux.HandleFunc("POST "+PathKVTxn, h.kvTxn)
 	mux.HandleFunc("POST "+PathEventAppend, h.eventAppend)
 	mux.HandleFunc("POST "+PathEventRead, h.eventRead)
 	mux.HandleFunc("POST "+PathEventTrim, h.eventTrim)
@@ -35,10 +40,13 @@ func NewHandler(caps statestore.Capabilities) http.Handler {
 	mux.HandleFunc("POST "+PathQueueAck, h.queueAck)
 	mux.HandleFunc("POST "+PathQueueNack, h.queueNack)
 	mux.HandleFunc("POST "+PathQueueKill, h.queueKill)
+	mux.HandleFunc("POST "+PathQueueDefer, h.queueDefer)
 	mux.HandleFunc("POST "+PathQueueDeadLetter, h.queueDeadLetters)
 	mux.HandleFunc("POST "+PathQueueRedrive, h.queueRedrive)
 	mux.HandleFunc("POST "+PathQueuePurge, h.queuePurge)
 	mux.HandleFunc("POST "+PathQueueStats, h.queueStats)
+	mux.HandleFunc("POST "+PathArchiveExport, h.archiveExport)
+	mux.HandleFunc("POST "+PathArchiveImport, h.archiveImport)
 	return mux
 }
 
@@ -185,6 +193,95 @@ func (h *handler) kvList(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, KVListResp{Keys: page.Keys, Next: page.Next})
 }
 
+func (h *handler) kvTxn(w http.ResponseWriter, r *http.Request) {
+	req, ok := decode[KVTxnReq](w, r)
+	if !ok {
+		return
+	}
+	kv, ok := h.kv(w)
+	if !ok {
+		return
+	}
+	tk, ok := kv.(statestore.TxnKV)
+	if !ok {
+		writeErr(w, statestore.ErrCapabilityUnavailable)
+		return
+	}
+	versions, err := tk.Txn(r.Context(), req.Scope, req.Txn, req.MaxKeys)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, KVTxnResp{Versions: versions})
+}
+
+// wk is kv's WatchableKV capability.
+func (h *handler) wk(w http.ResponseWriter) (statestore.WatchableKV, bool) {
+	kv, ok := h.kv(w)
+	if !ok {
+		return nil, false
+	}
+	wk, ok := kv.(statestore.WatchableKV)
+	if !ok {
+		writeErr(w, statestore.ErrCapabilityUnavailable)
+		return nil, false
+	}
+	return wk, true
+}
+
+// errWatchAnswered stops a long-poll's watch once it has an answer.
+var errWatchAnswered = errors.New("watch answered")
+
+// kvWatch serves one long-poll: it runs the watch until it delivers changes
+// or the wait runs out, and answers with what it reached.
+func (h *handler) kvWatch(w http.ResponseWriter, r *http.Request) {
+	req, ok := decode[KVWatchReq](w, r)
+	if !ok {
+		return
+	}
+	wk, ok := h.wk(w)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	wait := min(time.Duration(req.WaitNanos), MaxWatchWait)
+	if wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wait)
+		defer cancel()
+	}
+	resp := KVWatchResp{Revision: req.FromRevision}
+	err := wk.Watch(ctx, req.Scope, req.Prefix, req.FromRevision, func(events []statestore.KVEvent, rev int64) error {
+		resp.Events, resp.Revision = events, rev
+		if len(events) > 0 || wait <= 0 {
+			return errWatchAnswered
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errWatchAnswered) && (ctx.Err() == nil || r.Context().Err() != nil) {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *handler) kvRevision(w http.ResponseWriter, r *http.Request) {
+	req, ok := decode[KVRevisionReq](w, r)
+	if !ok {
+		return
+	}
+	wk, ok := h.wk(w)
+	if !ok {
+		return
+	}
+	rev, err := wk.Revision(r.Context(), req.Scope)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, KVRevisionResp{Revision: rev})
+}
+
 func (h *handler) eventAppend(w http.ResponseWriter, r *http.Request) {
 	req, ok := decode[EventAppendReq](w, r)
 	if !ok {
@@ -270,6 +367,7 @@ func (h *handler) queueEnqueue(w http.ResponseWriter, r *http.Request) {
 	id, err := q.Enqueue(r.Context(), req.Queue, statestore.Message{Body: req.Body}, statestore.EnqueueOptions{
 		Delay:    time.Duration(req.DelayNanos),
 		DedupKey: req.DedupKey,
+		Group:    req.Group,
 	})
 	if err != nil {
 		writeErr(w, err)
@@ -287,7 +385,18 @@ func (h *handler) queueLease(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	msgs, err := q.Lease(r.Context(), req.Queue, req.N, time.Duration(req.LeaseForNanos))
+	var msgs []statestore.LeasedMessage
+	var err error
+	if req.Grouped {
+		gq, ok := q.(statestore.GroupedQueue)
+		if !ok {
+			writeErr(w, statestore.ErrCapabilityUnavailable)
+			return
+		}
+		msgs, err = gq.LeaseGrouped(r.Context(), req.Queue, req.N, time.Duration(req.LeaseForNanos))
+	} else {
+		msgs, err = q.Lease(r.Context(), req.Queue, req.N, time.Duration(req.LeaseForNanos))
+	}
 	if err != nil {
 		writeErr(w, err)
 		return
@@ -327,6 +436,22 @@ func (h *handler) queueNack(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *handler) queueDefer(w http.ResponseWriter, r *http.Request) {
+	req, ok := decode[QueueDeferReq](w, r)
+	if !ok {
+		return
+	}
+	q, ok := h.q(w)
+	if !ok {
+		return
+	}
+	if err := q.Defer(r.Context(), req.Receipt, time.Duration(req.RetryAfterNanos)); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *handler) queueKill(w http.ResponseWriter, r *http.Request) {
 	req, ok := decode[QueueKillReq](w, r)
 	if !ok {
@@ -412,6 +537,61 @@ func (h *handler) queueStats(w http.ResponseWriter, r *http.Request) {
 		Visible:               st.Visible,
 		Leased:                st.Leased,
 		Dead:                  st.Dead,
+		Deferred:              st.Deferred,
 		OldestVisibleAgeNanos: st.OldestVisibleAge.Nanoseconds(),
 	})
 }
+
+// archiver is the Capabilities' Archiver capability.
+func (h *handler) archiver(w http.ResponseWriter) (statestore.Archiver, bool) {
+	a, ok := h.caps.(statestore.Archiver)
+	if !ok {
+		writeErr(w, statestore.ErrCapabilityUnavailable)
+		return nil, false
+	}
+	return a, true
+}
+
+// archiveExport streams the export as ArchiveExportLine JSON lines. The
+// status is sent with the first line, so an error after it is reported in
+// the last line instead.
+func (h *handler) archiveExport(w http.ResponseWriter, r *http.Request) {
+	req, ok := decode[ArchiveExportReq](w, r)
+	if !ok {
+		return
+	}
+	a, ok := h.archiver(w)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	err := a.Export(r.Context(), req.Filter, func(rec statestore.ArchiveRecord) error {
+		return enc.Encode(ArchiveExportLine{ArchiveRecord: rec})
+	})
+	last := ArchiveExportLine{End: true}
+	if err != nil {
+		_, code := ErrToCode(err)
+		last = ArchiveExportLine{Error: &Error{Code: code, Message: err.Error()}}
+	}
+	if enc.Encode(last) == nil {
+		_ = bw.Flush()
+	}
+}
+
+func (h *handler) archiveImport(w http.ResponseWriter, r *http.Request) {
+	req, ok := decode[ArchiveImportReq](w, r)
+	if !ok {
+		return
+	}
+	a, ok := h.archiver(w)
+	if !ok {
+		return
+	}
+	if err := a.Import(r.Context(), req.Records); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
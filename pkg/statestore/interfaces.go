@@ -40,6 +40,50 @@ type CountedKV interface {
 	SetCounted(ctx context.Context, s Scope, key string, val []byte, o SetOptions, maxKeys int64) error
 }
 
+// WatchableKV is an optional KVStore capability: change notification. Each
+// successful Set, or Delete of a live key, is a change with a revision, a
+// per-scope counter that increases by one with each change in the scope. A
+// failed CAS, or a Delete of an absent or expired key, changes nothing and so
+// has no revision. TTL expiry is not a change either: a put event carries the
+// key's ExpiresAt so a watcher can time the expiry itself.
+//
+// Drivers retain at least KVWatchHistory revisions per scope. Older ones are
+// compacted, and resuming from below them returns ErrCompacted, after which
+// the watcher must re-read the keys it cares about and watch from Revision.
+type WatchableKV interface {
+	// Revision returns the scope's current revision (0 before its first
+	// change). Watching from it sees every later change.
+	Revision(ctx context.Context, s Scope) (int64, error)
+	// Watch delivers the changes to keys under prefix in s with Revision >
+	// fromRevision, in revision order. It calls fn once when the watch is
+	// established and then whenever it advances, with the new changes
+	// (possibly none — changes outside prefix advance it too) and the revision
+	// the watch has reached. Every change up to that revision has been
+	// delivered, so it is the resume point. Watch blocks until ctx is done,
+	// returning ctx.Err(), or fn returns an error, which Watch returns. A
+	// fromRevision below the retained history returns ErrCompacted before fn
+	// is called.
+	Watch(ctx context.Context, s Scope, prefix string, fromRevision int64, fn func(events []KVEvent, revision int64) error) error
+}
+
+// TxnKV is an optional KVStore capability: multi-key transactions, in the
+// manner of etcd's Txn. Txn applies t.Ops, in order, only if every compare in
+// t.Compares holds, and atomically: no reader sees some ops applied and not
+// others. If a compare fails it returns ErrVersionConflict and changes
+// nothing. It returns the version each op leaves its key at (0 after a
+// delete).
+//
+// maxKeys is a live-key budget as in CountedKV, checked atomically with the
+// writes and after the compares: a Txn that creates a live key and would
+// leave the scope with more than maxKeys live keys returns ErrQuotaExceeded,
+// so one that deletes as many keys as it creates fits a full budget.
+// maxKeys <= 0 means no budget. For a WatchableKV driver each put, and each
+// delete of a live key, is a change, and a Txn's changes take consecutive
+// revisions. A malformed t (see Txn.Validate) returns ErrInvalidTxn.
+type TxnKV interface {
+	Txn(ctx context.Context, s Scope, t Txn, maxKeys int64) ([]int64, error)
+}
+
 // AppendAny is the sentinel expectedSeq for EventLog.Append that appends
 // unconditionally at the stream's current head — an atomic server-side
 // increment, not a compare-and-swap. Topic publishers use it (RFC-0027): topic
@@ -78,7 +122,7 @@ type EventLog interface {
 // Queue is an at-least-once work queue with visibility-timeout leases and a
 // dead-letter table.
 //
-// The settle methods (Ack/Nack/Kill) take the lease Receipt, not the durable
+// The settle methods (Ack/Nack/Kill/Defer) take the lease Receipt, not the durable
 // message id. This is the one deliberate rename from the RFC-0021 sketch's
 // Ack(id) so the epoch-guard correspondence (queue.tla invariant I2 / RFC-0021
 // invariant Q2) is legible in the type system: a Receipt is valid only for the
@@ -97,6 +141,13 @@ type Queue interface {
 	// Nack settles a delivery as failed and requeues it after retryAfter, unless
 	// the attempt budget is spent, in which case the message is dead-lettered.
 	Nack(ctx context.Context, receipt string, retryAfter time.Duration) error
+	// Defer returns a leased message to the queue, visible again after
+	// retryAfter, and refunds the lease's attempt: the consumer declined it
+	// without starting a delivery (e.g. throttling), so unlike Nack it never
+	// dead-letters. The message counts as Deferred in Stats until it is
+	// visible again. A stale or malformed receipt returns ErrInvalidReceipt.
+	// Like Kill, it is outside the retry protocol modeled in queue.tla.
+	Defer(ctx context.Context, receipt string, retryAfter time.Duration) error
 	// Kill dead-letters the current delivery immediately with reason. Unlike the
 	// retry path (Nack and lease expiry, which dead-letter only once the attempt
 	// budget is spent — invariant Q3), Kill is a permanent-failure escape hatch:
@@ -121,6 +172,44 @@ type Queue interface {
 	Stats(ctx context.Context, queue string) (QueueStats, error)
 }
 
+// GroupedQueue is an optional Queue capability: FIFO delivery per message
+// group, in the manner of SQS FIFO message group ids. LeaseGrouped behaves like
+// Lease, except that a message enqueued with EnqueueOptions.Group is leased only
+// when it is its group's head — the oldest of the group's unsettled (queued or
+// leased) messages — and no other message of the group is leased. So at most
+// one message per group is in flight, and a group's messages are delivered in
+// enqueue order: a Nacked, Deferred or still-delayed head holds the rest of its
+// group back, while an acked or dead-lettered one releases it. A redriven
+// message rejoins its group in its original position. Ungrouped messages lease
+// exactly as in Lease, and Lease itself ignores groups.
+type GroupedQueue interface {
+	LeaseGrouped(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]LeasedMessage, error)
+}
+
+// Archiver is an optional Capabilities capability: backup, restore and
+// migration between drivers (`fission statestore export/import`, the archive
+// format of WriteArchive).
+//
+// Export calls fn with every record f selects, read from one consistent
+// snapshot: each live key with its version and expiry, each stream's head
+// followed by its retained events in order, and each queue's queued and
+// dead-lettered messages in enqueue order. Acked messages are gone and not
+// exported. A lease cannot survive a restore, so a leased message is exported
+// as queued, visible when its lease expires, with its attempt in flight
+// refunded.
+//
+// Import applies recs in order and atomically where the driver can. A key is
+// stored at its archived version and expiry, replacing the key. A stream
+// record replaces the stream with an empty one at its head, which the
+// stream's event records then fill. A message is added to its queue in its
+// archived state under a new id, since message ids are the driver's. A
+// malformed record, or an event not above its stream's stored events and at
+// most its head, returns ErrInvalidArchive (see ValidateImport).
+type Archiver interface {
+	Export(ctx context.Context, f ArchiveFilter, fn func(ArchiveRecord) error) error
+	Import(ctx context.Context, recs []ArchiveRecord) error
+}
+
 // Capabilities is the driver set a component opens once at start. A consumer asks
 // for exactly the capabilities it needs and fails fast at startup if one is not
 // configured.
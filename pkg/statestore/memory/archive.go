@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var _ statestore.Archiver = (*Store)(nil)
+
+// Export implements statestore.Archiver. It copies what f selects under the
+// lock, so the copy is one consistent snapshot, and calls fn after releasing
+// it, so a slow fn does not stall the store.
+func (s *Store) Export(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	recs, err := s.snapshot(f)
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshot returns the records Export emits for f, keys, streams and queues
+// each in name order.
+func (s *Store) snapshot(f statestore.ArchiveFilter) ([]statestore.ArchiveRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, statestore.ErrClosed
+	}
+	now := time.Now()
+	var recs []statestore.ArchiveRecord
+
+	var keys []kvKey
+	for k, e := range s.kv {
+		scope := statestore.Scope{Namespace: k.ns, Owner: k.owner, Keyspace: k.keyspace}
+		if !e.expired(now) && f.MatchScope(scope) {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b kvKey) int {
+		return cmp.Or(cmp.Compare(a.ns, b.ns), cmp.Compare(a.owner, b.owner),
+			cmp.Compare(a.keyspace, b.keyspace), cmp.Compare(a.key, b.key))
+	})
+	for _, k := range keys {
+		e := s.kv[k]
+		recs = append(recs, statestore.ArchiveRecord{KV: &statestore.ArchivedKey{
+			Scope:     statestore.Scope{Namespace: k.ns, Owner: k.owner, Keyspace: k.keyspace},
+			Key:       k.key,
+			Value:     append([]byte(nil), e.data...),
+			Version:   e.version,
+			ExpiresAt: e.expiresAt,
+		}})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.streams)) {
+		if !f.MatchStream(name) {
+			continue
+		}
+		st := s.streams[name]
+		recs = append(recs, statestore.ArchiveRecord{Stream: &statestore.ArchivedStream{Stream: name, Head: st.head}})
+		for _, e := range st.events {
+			e = cloneEvent(e)
+			recs = append(recs, statestore.ArchiveRecord{Event: &statestore.ArchivedEvent{
+				Stream: name, Seq: e.Seq, Type: e.Type, Payload: e.Payload, At: e.At,
+			}})
+		}
+	}
+
+	if !f.MatchQueues() {
+		return recs, nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.queues)) {
+		for _, m := range s.queues[name].msgs {
+			am := &statestore.ArchivedMessage{
+				Queue:      name,
+				ID:         m.id,
+				Body:       append([]byte(nil), m.body...),
+				Attempts:   m.attempts,
+				VisibleAt:  m.visibleAt,
+				EnqueuedAt: m.enqueuedAt,
+				DedupKey:   m.dedupKey,
+				Group:      m.group,
+			}
+			switch m.state {
+			case qQueued:
+				am.State = statestore.ArchivedQueued
+				am.Deferred = m.deferred
+			case qLeased:
+				am.State = statestore.ArchivedQueued
+				am.Attempts--
+				am.VisibleAt = m.expiry
+			case qDead:
+				am.State = statestore.ArchivedDead
+				am.VisibleAt = time.Time{}
+				am.Group = ""
+				am.Reason = m.reason
+				am.DiedAt = m.diedAt
+			default:
+				continue
+			}
+			recs = append(recs, statestore.ArchiveRecord{Message: am})
+		}
+	}
+	return recs, nil
+}
+
+// Import implements statestore.Archiver, atomically: the records are
+// validated before the first is applied, under one hold of the lock.
+func (s *Store) Import(_ context.Context, recs []statestore.ArchiveRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return statestore.ErrClosed
+	}
+	err := statestore.ValidateImport(recs, func(stream string) (int64, int64, error) {
+		st := s.streams[stream]
+		if st == nil {
+			return 0, 0, nil
+		}
+		var last int64
+		if n := len(st.events); n > 0 {
+			last = st.events[n-1].Seq
+		}
+		return last, st.head, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		switch {
+		case r.KV != nil:
+			e := kvEntry{data: append([]byte(nil), r.KV.Value...), version: r.KV.Version, expiresAt: r.KV.ExpiresAt}
+			s.kv[scopeKey(r.KV.Scope, r.KV.Key)] = e
+			s.recordChange(r.KV.Scope, putEvent(r.KV.Key, e))
+		case r.Stream != nil:
+			s.streams[r.Stream.Stream] = &streamState{head: r.Stream.Head}
+		case r.Event != nil:
+			st := s.streams[r.Event.Stream]
+			st.events = append(st.events, cloneEvent(statestore.Event{
+				Seq: r.Event.Seq, Type: r.Event.Type, Payload: r.Event.Payload, At: r.Event.At,
+			}))
+		case r.Message != nil:
+			am := r.Message
+			q := s.queue(am.Queue)
+			q.seq++
+			m := &qmsg{
+				id:         fmt.Sprintf("%s/%d", am.Queue, q.seq),
+				body:       append([]byte(nil), am.Body...),
+				state:      qQueued,
+				visibleAt:  am.VisibleAt,
+				attempts:   am.Attempts,
+				dedupKey:   am.DedupKey,
+				group:      am.Group,
+				enqueuedAt: am.EnqueuedAt,
+				deferred:   am.Deferred,
+			}
+			if am.State == statestore.ArchivedDead {
+				m.state = qDead
+				m.dedupKey = ""
+				m.group = ""
+				m.deferred = false
+				m.reason = am.Reason
+				m.diedAt = am.DiedAt
+			}
+			q.msgs = append(q.msgs, m)
+		}
+	}
+	return nil
+}
@@ -26,4 +26,5 @@ func TestConformance_Memory(t *testing.T) {
 	}
 	statestoretest.RunConformance(t, factory)
 	statestoretest.RunTimingConformance(t, factory)
+	statestoretest.RunWatchConformance(t, factory)
 }
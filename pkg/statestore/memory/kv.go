@@ -120,6 +120,7 @@ func (s *Store) SetCounted(_ context.Context, scope statestore.Scope, key string
 		next.expiresAt = now.Add(o.TTL)
 	}
 	s.kv[k] = next
+	s.recordChange(scope, putEvent(key, next))
 	return nil
 }
 
@@ -138,10 +139,81 @@ func (s *Store) Delete(_ context.Context, scope statestore.Scope, key string, if
 			return statestore.ErrVersionConflict
 		}
 	}
+	if exists {
+		s.recordChange(scope, deleteEvent(key, cur))
+	}
 	delete(s.kv, k)
 	return nil
 }
 
+// Txn implements statestore.TxnKV. Under the store mutex the compares, the
+// budget check, and the writes are one atomic step.
+func (s *Store) Txn(_ context.Context, scope statestore.Scope, t statestore.Txn, maxKeys int64) ([]int64, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, statestore.ErrClosed
+	}
+	now := time.Now()
+	for _, c := range t.Compares {
+		cur, _ := s.liveEntry(scopeKey(scope, c.Key), now)
+		if cur.version != c.Version {
+			return nil, statestore.ErrVersionConflict
+		}
+	}
+
+	if maxKeys > 0 {
+		var delta int64
+		creates := false
+		for _, op := range t.Ops {
+			_, exists := s.liveEntry(scopeKey(scope, op.Key), now)
+			switch {
+			case op.Type == statestore.TxnPut && !exists:
+				delta++
+				creates = true
+			case op.Type == statestore.TxnDelete && exists:
+				delta--
+			}
+		}
+		if creates {
+			live := delta
+			for ek, e := range s.kv {
+				if ek.ns == scope.Namespace && ek.owner == scope.Owner && ek.keyspace == scope.Keyspace && !e.expired(now) {
+					live++
+				}
+			}
+			if live > maxKeys {
+				return nil, statestore.ErrQuotaExceeded
+			}
+		}
+	}
+
+	versions := make([]int64, len(t.Ops))
+	for i, op := range t.Ops {
+		k := scopeKey(scope, op.Key)
+		cur, exists := s.liveEntry(k, now)
+		if op.Type == statestore.TxnDelete {
+			if exists {
+				s.recordChange(scope, deleteEvent(op.Key, cur))
+			}
+			delete(s.kv, k)
+			continue
+		}
+		next := kvEntry{version: cur.version + 1, data: make([]byte, len(op.Value))}
+		copy(next.data, op.Value)
+		if op.TTL > 0 {
+			next.expiresAt = now.Add(op.TTL)
+		}
+		s.kv[k] = next
+		s.recordChange(scope, putEvent(op.Key, next))
+		versions[i] = next.version
+	}
+	return versions, nil
+}
+
 // List implements statestore.KVStore: lexicographically ordered keys under
 // prefix, paginated via page.Token (the last key of the previous page).
 func (s *Store) List(_ context.Context, scope statestore.Scope, prefix string, page statestore.Page) (statestore.KeyPage, error) {
@@ -172,3 +244,5 @@ func (s *Store) List(_ context.Context, scope statestore.Scope, prefix string, p
 	}
 	return statestore.KeyPage{Keys: keys[:limit], Next: keys[limit-1]}, nil
 }
+
+var _ statestore.TxnKV = (*Store)(nil)
@@ -3,7 +3,8 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package memory is the in-memory statestore driver: all three capabilities
-// (KVStore, EventLog, Queue) behind plain mutex-guarded maps.
+// (KVStore, with CountedKV, TxnKV and WatchableKV, EventLog, and Queue), plus
+// Archiver, behind plain mutex-guarded maps.
 //
 // It is the executable specification for the substrate — the shared conformance
 // suite and the property-based tests treat it as ground truth — and it powers
@@ -33,6 +34,8 @@ type Store struct {
 	closed bool
 
 	kv          map[kvKey]kvEntry
+	changes     map[statestore.Scope]*changeLog
+	changed     chan struct{} // closed and replaced on every KV change
 	streams     map[string]*streamState
 	queues      map[string]*queueState
 	maxAttempts int
@@ -42,6 +45,8 @@ type Store struct {
 func newStore() *Store {
 	return &Store{
 		kv:          make(map[kvKey]kvEntry),
+		changes:     make(map[statestore.Scope]*changeLog),
+		changed:     make(chan struct{}),
 		streams:     make(map[string]*streamState),
 		queues:      make(map[string]*queueState),
 		maxAttempts: statestore.DefaultMaxAttempts,
@@ -96,10 +101,14 @@ func (s *Store) Ping(context.Context) error {
 	return nil
 }
 
-// Close marks the store closed; subsequent operations return ErrClosed.
+// Close marks the store closed; subsequent operations return ErrClosed, and
+// running watches end with it.
 func (s *Store) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.closed = true
+	if !s.closed {
+		s.closed = true
+		s.broadcast()
+	}
 	return nil
 }
@@ -34,9 +34,11 @@ type qmsg struct {
 	attempts   int       // deliveries started so far
 	epoch      int64
 	dedupKey   string
+	group      string // FIFO message group, "" when ungrouped
 	reason     string // dead-letter reason
 	enqueuedAt time.Time
 	diedAt     time.Time
+	deferred   bool // last returned by Defer, not leased since
 }
 
 // queueState is one named queue: messages in insertion order plus a monotonic
@@ -80,6 +82,7 @@ func (s *Store) Enqueue(_ context.Context, queue string, msg statestore.Message,
 		state:      qQueued,
 		visibleAt:  now.Add(o.Delay),
 		dedupKey:   o.DedupKey,
+		group:      o.Group,
 		enqueuedAt: now,
 	}
 	q.msgs = append(q.msgs, m)
@@ -121,6 +124,16 @@ func (m *qmsg) leasable(now time.Time, maxAttempts int) bool {
 // Lease implements statestore.Queue: up to n currently-visible messages, each
 // leased for leaseFor, with the lease epoch bumped so prior deliveries go stale.
 func (s *Store) Lease(_ context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	return s.lease(queue, n, leaseFor, false)
+}
+
+// LeaseGrouped implements statestore.GroupedQueue: Lease, leasing a grouped
+// message only while it heads its group and nothing of the group is leased.
+func (s *Store) LeaseGrouped(_ context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	return s.lease(queue, n, leaseFor, true)
+}
+
+func (s *Store) lease(queue string, n int, leaseFor time.Duration, grouped bool) ([]statestore.LeasedMessage, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.closed {
@@ -129,17 +142,31 @@ func (s *Store) Lease(_ context.Context, queue string, n int, leaseFor time.Dura
 	now := time.Now()
 	q := s.queue(queue)
 	q.leaseExpirations += q.reapExpired(now, s.maxAttempts)
+	// headed holds the groups whose head has been passed: msgs is in insertion
+	// order, so the first unsettled message seen of a group is its head and
+	// every later one waits behind it.
+	var headed map[string]bool
+	if grouped {
+		headed = map[string]bool{}
+	}
 	var out []statestore.LeasedMessage
 	for _, m := range q.msgs {
 		if len(out) >= n {
 			break
 		}
+		if grouped && m.group != "" && (m.state == qQueued || m.state == qLeased) {
+			if headed[m.group] {
+				continue
+			}
+			headed[m.group] = true
+		}
 		if !m.leasable(now, s.maxAttempts) {
 			continue
 		}
 		m.state = qLeased
 		m.epoch++
 		m.attempts++
+		m.deferred = false
 		m.expiry = now.Add(leaseFor)
 		out = append(out, statestore.LeasedMessage{
 			ID:       m.id,
@@ -221,6 +248,25 @@ func (s *Store) Nack(_ context.Context, receipt string, retryAfter time.Duration
 	return nil
 }
 
+// Defer implements statestore.Queue: requeue after retryAfter with the lease's
+// attempt refunded, never dead-lettering.
+func (s *Store) Defer(_ context.Context, receipt string, retryAfter time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return statestore.ErrClosed
+	}
+	m, err := s.settleAcross(receipt)
+	if err != nil {
+		return err
+	}
+	m.state = qQueued
+	m.attempts--
+	m.visibleAt = time.Now().Add(retryAfter)
+	m.deferred = true
+	return nil
+}
+
 // Kill implements statestore.Queue: dead-letter the current delivery immediately
 // (a permanent failure), regardless of remaining attempts.
 func (s *Store) Kill(_ context.Context, receipt string, reason string) error {
@@ -357,6 +403,8 @@ func (s *Store) Stats(_ context.Context, queue string) (statestore.QueueStats, e
 				if oldest.IsZero() || m.enqueuedAt.Before(oldest) {
 					oldest = m.enqueuedAt
 				}
+			} else if m.deferred {
+				st.Deferred++
 			}
 		case qLeased:
 			st.Leased++
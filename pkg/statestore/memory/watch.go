@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+// changeLog is one scope's retained KV change history: events holds the
+// changes with revisions compacted+1 .. rev, in order.
+type changeLog struct {
+	rev       int64
+	compacted int64
+	events    []statestore.KVEvent
+}
+
+// recordChange appends a change to scope's log, trims the log to
+// KVWatchHistory, and wakes the watchers. Caller holds s.mu.
+func (s *Store) recordChange(scope statestore.Scope, ev statestore.KVEvent) {
+	l := s.changes[scope]
+	if l == nil {
+		l = &changeLog{}
+		s.changes[scope] = l
+	}
+	l.rev++
+	ev.Revision = l.rev
+	l.events = append(l.events, ev)
+	if drop := len(l.events) - statestore.KVWatchHistory; drop > 0 {
+		l.compacted += int64(drop)
+		l.events = append([]statestore.KVEvent(nil), l.events[drop:]...)
+	}
+	s.broadcast()
+}
+
+// broadcast wakes every waiting watcher. Caller holds s.mu.
+func (s *Store) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Revision implements statestore.WatchableKV.
+func (s *Store) Revision(_ context.Context, scope statestore.Scope) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, statestore.ErrClosed
+	}
+	if l := s.changes[scope]; l != nil {
+		return l.rev, nil
+	}
+	return 0, nil
+}
+
+// Watch implements statestore.WatchableKV.
+func (s *Store) Watch(ctx context.Context, scope statestore.Scope, prefix string, fromRevision int64,
+	fn func([]statestore.KVEvent, int64) error) error {
+	established := false
+	for {
+		events, head, wake, err := s.changesSince(scope, prefix, fromRevision)
+		if err != nil {
+			return err
+		}
+		if !established || head > fromRevision {
+			established = true
+			fromRevision = max(fromRevision, head)
+			if err := fn(events, fromRevision); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// changesSince returns scope's changes under prefix after from, the scope's
+// head revision, and a channel closed on the next change anywhere in the
+// store.
+func (s *Store) changesSince(scope statestore.Scope, prefix string, from int64) ([]statestore.KVEvent, int64, <-chan struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, 0, nil, statestore.ErrClosed
+	}
+	l := s.changes[scope]
+	if l == nil {
+		return nil, 0, s.changed, nil
+	}
+	if from < l.compacted {
+		return nil, 0, nil, statestore.ErrCompacted
+	}
+	var events []statestore.KVEvent
+	for _, ev := range l.events[min(from-l.compacted, int64(len(l.events))):] {
+		if strings.HasPrefix(ev.Key, prefix) {
+			ev.Value = append([]byte(nil), ev.Value...)
+			events = append(events, ev)
+		}
+	}
+	return events, l.rev, s.changed, nil
+}
+
+// putEvent is the change for a Set that left e as key's entry.
+func putEvent(key string, e kvEntry) statestore.KVEvent {
+	return statestore.KVEvent{
+		Type:      statestore.KVEventPut,
+		Key:       key,
+		Value:     append([]byte(nil), e.data...),
+		Version:   e.version,
+		ExpiresAt: e.expiresAt,
+	}
+}
+
+// deleteEvent is the change for a Delete of key's live entry e.
+func deleteEvent(key string, e kvEntry) statestore.KVEvent {
+	return statestore.KVEvent{Type: statestore.KVEventDelete, Key: key, Version: e.version}
+}
+
+var _ statestore.WatchableKV = (*Store)(nil)
@@ -5,17 +5,20 @@
 // Package postgres is the reference statestore driver for the external
 // (user-managed) deployment mode. It shares all its logic with the embedded
 // SQLite driver via pkg/statestore/sqlstore; only the connection setup and
-// dialect (BYTEA/BIGINT columns, SELECT ... FOR UPDATE SKIP LOCKED leases, and
-// an advisory lock around migrations) differ.
+// dialect (BYTEA/BIGINT columns, SELECT ... FOR UPDATE SKIP LOCKED leases, an
+// advisory lock around migrations, and LISTEN/NOTIFY to wake KV watches across
+// replicas) differ.
 package postgres
 
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 
-	// jackc/pgx/v5/stdlib registers the "pgx" database/sql driver.
-	_ "github.com/jackc/pgx/v5/stdlib"
+	// jackc/pgx/v5/stdlib also registers the "pgx" database/sql driver.
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/statestore/sqlstore"
@@ -26,6 +29,9 @@ import (
 // than racing (resolves RFC-0021 open question 3).
 const migrationLockKey int64 = 0x1502_0021 // "statestore RFC-0021"
 
+// notifyChannel is the NOTIFY channel KV writes signal their scope on.
+const notifyChannel = "fission_statestore_kv"
+
 func init() {
 	statestore.Register("postgres", func(ctx context.Context, c statestore.Config) (statestore.Capabilities, error) {
 		return New(ctx, c.DSN)
@@ -42,6 +48,40 @@ var dialect = sqlstore.Dialect{
 		_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockKey)
 		return err
 	},
+	Notify: "SELECT pg_notify('" + notifyChannel + "', ?)",
+	Listen: listen,
+}
+
+// listen LISTENs on notifyChannel over a dedicated pooled connection until
+// ctx is done or the connection fails. The connection is always discarded
+// afterwards rather than returned to the pool still subscribed.
+func listen(ctx context.Context, db *sql.DB, listening func(), notify func(string)) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	return conn.Raw(func(dc any) error {
+		sc, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("statestore/postgres: unexpected driver connection %T", dc)
+		}
+		pc := sc.Conn()
+		err := func() error {
+			if _, err := pc.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+				return err
+			}
+			listening()
+			for {
+				n, err := pc.WaitForNotification(ctx)
+				if err != nil {
+					return err
+				}
+				notify(n.Payload)
+			}
+		}()
+		return fmt.Errorf("%w: %w", driver.ErrBadConn, err)
+	})
 }
 
 // New opens a Postgres-backed statestore at dsn (a libpq/pgx connection string).
@@ -19,7 +19,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statestore/memory"
 	"github.com/fission/fission/pkg/statestore/postgres"
+	"github.com/fission/fission/pkg/statestore/sqlite"
 	"github.com/fission/fission/pkg/statestore/statestoretest"
 )
 
@@ -69,7 +71,7 @@ func truncate(t *testing.T, dsn string) {
 	db, err := sql.Open("pgx", dsn)
 	require.NoError(t, err)
 	defer func() { _ = db.Close() }()
-	_, err = db.Exec("TRUNCATE state_kv, state_events, state_streams, state_queue")
+	_, err = db.Exec("TRUNCATE state_kv, state_kv_revisions, state_kv_changes, state_events, state_streams, state_queue")
 	require.NoError(t, err)
 }
 
@@ -88,6 +90,34 @@ func TestConformance_Postgres(t *testing.T) {
 	// which exercise the identical shared sqlstore timing code).
 	statestoretest.RunConformance(t, factory)
 	statestoretest.RunKVLinearizability(t, factory)
+	statestoretest.RunWatchConformance(t, factory)
+}
+
+// TestArchive_MemoryToSQLiteToPostgres migrates an archive across every SQL
+// driver: memory to SQLite, then SQLite's archive to Postgres.
+func TestArchive_MemoryToSQLiteToPostgres(t *testing.T) {
+	dsn := pgDSN(t)
+	statestoretest.RunArchiveRoundTrip(t,
+		func(t *testing.T) statestore.Capabilities {
+			caps, err := memory.New()
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = caps.Close() })
+			return caps
+		},
+		func(t *testing.T) statestore.Capabilities {
+			caps, err := sqlite.New(t.Context(), t.TempDir()+"/archive.db")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = caps.Close() })
+			return caps
+		},
+		func(t *testing.T) statestore.Capabilities {
+			caps, err := postgres.New(t.Context(), dsn)
+			require.NoError(t, err)
+			truncate(t, dsn)
+			t.Cleanup(func() { _ = caps.Close() })
+			return caps
+		},
+	)
 }
 
 // TestPostgres_TimingSmoke verifies TTL and lease expiry against real Postgres
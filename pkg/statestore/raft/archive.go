@@ -0,0 +1,352 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var _ statestore.Archiver = (*Store)(nil)
+
+// Export implements statestore.Archiver. It copies this replica's state, as
+// of a linearizable read, to a temporary file and exports from the copy: a
+// bbolt read transaction held for a whole export would keep the state file
+// from growing under the writes meanwhile.
+func (s *Store) Export(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	if err := s.node.linearize(ctx, s.nextID()); err != nil {
+		return err
+	}
+	return s.fsm.exportView(func(v *view) error {
+		v.now = time.Now().UnixNano()
+		if err := v.exportKV(ctx, f, fn); err != nil {
+			return err
+		}
+		if err := v.exportStreams(ctx, f, fn); err != nil {
+			return err
+		}
+		if !f.MatchQueues() {
+			return nil
+		}
+		return v.exportQueues(ctx, fn)
+	})
+}
+
+// Import implements statestore.Archiver: the records are one command, so
+// they apply atomically on every replica. They are validated here first for
+// a descriptive error, and again by the state machine, which decides.
+func (s *Store) Import(ctx context.Context, recs []statestore.ArchiveRecord) error {
+	err := s.read(ctx, func(v *view) error {
+		return statestore.ValidateImport(recs, v.streamBounds)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.write(ctx, &command{Op: opImport, Records: recs})
+	return err
+}
+
+// exportView runs fn over a copy of the state in a temporary file.
+func (f *fsm) exportView(fn func(v *view) error) error {
+	fh, err := os.CreateTemp(f.cfg.dir, "export-*.db")
+	if err != nil {
+		return err
+	}
+	path := fh.Name()
+	defer func() { _ = os.Remove(path) }()
+	err = f.view(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(fh)
+		return err
+	})
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(&view{tx: tx, maxAttempts: f.cfg.maxAttempts})
+	})
+}
+
+// decodeScope splits an entry key of bucketKV into its scope and key.
+func decodeScope(k []byte) (statestore.Scope, string, error) {
+	var fields [3]string
+	n := 0
+	for i := range fields {
+		if len(k) < n+4 {
+			return statestore.Scope{}, "", errCorrupt
+		}
+		l := int(binary.BigEndian.Uint32(k[n:]))
+		n += 4
+		if len(k) < n+l {
+			return statestore.Scope{}, "", errCorrupt
+		}
+		fields[i] = string(k[n : n+l])
+		n += l
+	}
+	return statestore.Scope{Namespace: fields[0], Owner: fields[1], Keyspace: fields[2]}, string(k[n:]), nil
+}
+
+func (v *view) exportKV(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	c := v.tx.Bucket(bucketKV).Cursor()
+	for k, raw := c.First(); k != nil; k, raw = c.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		scope, key, err := decodeScope(k)
+		if err != nil {
+			return err
+		}
+		e, ok := decodeKVEntry(raw)
+		if !ok {
+			return errCorrupt
+		}
+		if e.expired(v.now) || !f.MatchScope(scope) {
+			continue
+		}
+		rec := &statestore.ArchivedKey{Scope: scope, Key: key, Value: e.data, Version: e.version}
+		if e.expiresAt != 0 {
+			rec.ExpiresAt = time.Unix(0, e.expiresAt)
+		}
+		if err := fn(statestore.ArchiveRecord{KV: rec}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *view) exportStreams(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	c := v.tx.Bucket(bucketHeads).Cursor()
+	for k, raw := c.First(); k != nil; k, raw = c.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := string(k[1:])
+		if !f.MatchStream(name) {
+			continue
+		}
+		head := int64(binary.BigEndian.Uint64(raw))
+		if err := fn(statestore.ArchiveRecord{Stream: &statestore.ArchivedStream{Stream: name, Head: head}}); err != nil {
+			return err
+		}
+		b := v.tx.Bucket(bucketStreams).Bucket(k)
+		if b == nil {
+			continue
+		}
+		ec := b.Cursor()
+		for ek, eraw := ec.First(); ek != nil; ek, eraw = ec.Next() {
+			var r eventRecord
+			if err := json.Unmarshal(eraw, &r); err != nil {
+				return err
+			}
+			if err := fn(statestore.ArchiveRecord{Event: &statestore.ArchivedEvent{
+				Stream:  name,
+				Seq:     int64(binary.BigEndian.Uint64(ek)),
+				Type:    r.Type,
+				Payload: r.Payload,
+				At:      time.Unix(0, r.At),
+			}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *view) exportQueues(ctx context.Context, fn func(statestore.ArchiveRecord) error) error {
+	return v.tx.Bucket(bucketQueues).ForEachBucket(func(name []byte) error {
+		q := v.queue(string(name[1:]))
+		c := q.m.Cursor()
+		for k, raw := c.First(); k != nil; k, raw = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var m qmsg
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return err
+			}
+			am := &statestore.ArchivedMessage{
+				Queue:      q.name,
+				ID:         q.id(binary.BigEndian.Uint64(k)),
+				Body:       m.Body,
+				Attempts:   m.Attempts,
+				EnqueuedAt: time.Unix(0, m.EnqueuedAt),
+			}
+			switch m.State {
+			case stateQueued:
+				am.State = statestore.ArchivedQueued
+				am.VisibleAt = time.Unix(0, m.VisibleAt)
+				am.DedupKey, am.Group, am.Deferred = m.DedupKey, m.Group, m.Deferred
+			case stateLeased:
+				am.State = statestore.ArchivedQueued
+				am.VisibleAt = time.Unix(0, m.Expiry)
+				am.Attempts--
+				am.DedupKey, am.Group = m.DedupKey, m.Group
+			case stateDead:
+				am.State = statestore.ArchivedDead
+				am.Reason = m.Reason
+				am.DiedAt = time.Unix(0, m.DiedAt)
+			}
+			if err := fn(statestore.ArchiveRecord{Message: am}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// streamBounds returns a stream's last stored event seq and its head, for
+// statestore.ValidateImport.
+func (v *view) streamBounds(stream string) (int64, int64, error) {
+	var last int64
+	if b := v.tx.Bucket(bucketStreams).Bucket(nested(stream)); b != nil {
+		if k, _ := b.Cursor().Last(); k != nil {
+			last = int64(binary.BigEndian.Uint64(k))
+		}
+	}
+	return last, v.streamHead(stream), nil
+}
+
+// recordKeySizes returns the sizes of the names and keys r stores as bbolt
+// keys, for command.validate.
+func recordKeySizes(r statestore.ArchiveRecord) []int {
+	switch {
+	case r.KV != nil:
+		return []int{len(r.KV.Scope.Namespace) + len(r.KV.Scope.Owner) + len(r.KV.Scope.Keyspace) + len(r.KV.Key)}
+	case r.Stream != nil:
+		return []int{len(r.Stream.Stream)}
+	case r.Event != nil:
+		return []int{len(r.Event.Stream)}
+	case r.Message != nil:
+		return []int{len(r.Message.Queue), len(r.Message.DedupKey), len(r.Message.Group)}
+	}
+	return nil
+}
+
+// importRecords applies c.Records, or none of them if they do not validate.
+func (v *view) importRecords(c *command) (result, error) {
+	if err := statestore.ValidateImport(c.Records, v.streamBounds); err != nil {
+		if errors.Is(err, statestore.ErrInvalidArchive) {
+			return result{Code: codeInvalidArchive}, nil
+		}
+		return result{}, err
+	}
+	for _, r := range c.Records {
+		var err error
+		switch {
+		case r.KV != nil:
+			err = v.importKey(r.KV)
+		case r.Stream != nil:
+			err = v.importStream(r.Stream)
+		case r.Event != nil:
+			err = v.importEvent(r.Event)
+		case r.Message != nil:
+			err = v.importMessage(r.Message)
+		}
+		if err != nil {
+			return result{}, err
+		}
+	}
+	return result{}, nil
+}
+
+func (v *view) importKey(rec *statestore.ArchivedKey) error {
+	k := append(scopePrefix(rec.Scope), rec.Key...)
+	if err := v.kvRemove(k); err != nil {
+		return err
+	}
+	e := kvEntry{version: rec.Version, expiresAt: unixNanos(rec.ExpiresAt), data: rec.Value}
+	if e.expiresAt != 0 {
+		if err := v.tx.Bucket(bucketKVTTL).Put(ttlKey(e.expiresAt, k), nil); err != nil {
+			return err
+		}
+	}
+	if err := v.tx.Bucket(bucketKV).Put(k, e.encode()); err != nil {
+		return err
+	}
+	return v.kvCount(k, 1)
+}
+
+func (v *view) importStream(rec *statestore.ArchivedStream) error {
+	streams := v.tx.Bucket(bucketStreams)
+	if streams.Bucket(nested(rec.Stream)) != nil {
+		if err := streams.DeleteBucket(nested(rec.Stream)); err != nil {
+			return err
+		}
+	}
+	return putInt(v.tx.Bucket(bucketHeads), nested(rec.Stream), rec.Head)
+}
+
+func (v *view) importEvent(rec *statestore.ArchivedEvent) error {
+	b, err := v.tx.Bucket(bucketStreams).CreateBucketIfNotExists(nested(rec.Stream))
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(eventRecord{Type: rec.Type, Payload: rec.Payload, At: unixNanos(rec.At)})
+	if err != nil {
+		return err
+	}
+	return b.Put(u64(uint64(rec.Seq)), raw)
+}
+
+// importMessage adds rec to its queue as the next message, counted as
+// enqueued so the queue's conservation accounting holds.
+func (v *view) importMessage(rec *statestore.ArchivedMessage) error {
+	q, err := v.createQueue(rec.Queue)
+	if err != nil {
+		return err
+	}
+	seq := uint64(getInt(q.meta, keySeq) + 1)
+	if err := putInt(q.meta, keySeq, int64(seq)); err != nil {
+		return err
+	}
+	if err := addInt(q.meta, keyEnqueued, 1); err != nil {
+		return err
+	}
+	m := &qmsg{Body: rec.Body, Attempts: rec.Attempts, EnqueuedAt: unixNanos(rec.EnqueuedAt)}
+	if rec.State == statestore.ArchivedDead {
+		m.State = stateDead
+		m.Reason = rec.Reason
+		m.DiedAt = unixNanos(rec.DiedAt)
+		if err := q.dead.Put([]byte(q.id(seq)), u64(seq)); err != nil {
+			return err
+		}
+		return q.put(seq, m)
+	}
+	m.DedupKey, m.Group, m.Deferred = rec.DedupKey, rec.Group, rec.Deferred
+	if m.DedupKey != "" {
+		if err := q.dedup.Put([]byte(m.DedupKey), u64(seq)); err != nil {
+			return err
+		}
+	}
+	if m.Group != "" {
+		if err := q.groups.Put(groupKey(m.Group, seq), nil); err != nil {
+			return err
+		}
+	}
+	return q.requeue(v, seq, m, unixNanos(rec.VisibleAt))
+}
+
+// unixNanos is t in unix nanoseconds, 0 for the zero time.
+func unixNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
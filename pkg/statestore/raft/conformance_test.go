@@ -0,0 +1,388 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	etcdraft "go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/raftpb"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statestore/statestoretest"
+)
+
+// memNet connects in-process replicas. Messages go through their wire
+// encoding, in order per sender, and a replica can be cut off from the rest.
+type memNet struct {
+	mu    sync.Mutex
+	nodes map[uint64]handler
+	cut   map[uint64]bool
+}
+
+func newMemNet() *memNet {
+	return &memNet{nodes: map[uint64]handler{}, cut: map[uint64]bool{}}
+}
+
+// isolate cuts id off from every other replica, or heals it.
+func (nw *memNet) isolate(id uint64, cut bool) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	nw.cut[id] = cut
+}
+
+func (nw *memNet) transport(id uint64) *memTransport {
+	return &memTransport{net: nw, id: id, q: make(chan raftpb.Message, peerQueueLen), done: make(chan struct{})}
+}
+
+type memTransport struct {
+	net  *memNet
+	id   uint64
+	h    handler
+	q    chan raftpb.Message
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (t *memTransport) start(h handler) error {
+	t.h = h
+	t.net.mu.Lock()
+	t.net.nodes[t.id] = h
+	t.net.mu.Unlock()
+	t.wg.Go(t.run)
+	return nil
+}
+
+func (t *memTransport) stop() {
+	t.net.mu.Lock()
+	delete(t.net.nodes, t.id)
+	t.net.mu.Unlock()
+	close(t.done)
+	t.wg.Wait()
+}
+
+func (t *memTransport) send(msgs []raftpb.Message) {
+	for _, m := range msgs {
+		select {
+		case t.q <- m:
+		default:
+			t.h.reportUnreachable(m.To)
+		}
+	}
+}
+
+func (t *memTransport) run() {
+	for {
+		select {
+		case <-t.done:
+			return
+		case m := <-t.q:
+			t.net.mu.Lock()
+			dst := t.net.nodes[m.To]
+			cut := t.net.cut[m.From] || t.net.cut[m.To]
+			t.net.mu.Unlock()
+			status := etcdraft.SnapshotFinish
+			if dst == nil || cut {
+				t.h.reportUnreachable(m.To)
+				status = etcdraft.SnapshotFailure
+			} else {
+				raw, err := m.Marshal()
+				if err != nil {
+					panic(err)
+				}
+				var wire raftpb.Message
+				if err := wire.Unmarshal(raw); err != nil {
+					panic(err)
+				}
+				_ = dst.step(context.Background(), wire)
+			}
+			if m.Type == raftpb.MsgSnap {
+				t.h.reportSnapshot(m.To, status)
+			}
+		}
+	}
+}
+
+// cluster is a set of replicas on one memNet.
+type cluster struct {
+	t      *testing.T
+	net    *memNet
+	dirs   map[uint64]string
+	stores map[uint64]*Store
+	opts   []Option
+}
+
+func newCluster(t *testing.T, n int, opts ...Option) *cluster {
+	c := &cluster{t: t, net: newMemNet(), dirs: map[uint64]string{}, stores: map[uint64]*Store{}, opts: opts}
+	for id := uint64(1); id <= uint64(n); id++ {
+		c.dirs[id] = t.TempDir()
+	}
+	for id := range c.dirs {
+		c.start(id)
+	}
+	return c
+}
+
+// start (re)starts replica id over its data directory.
+func (c *cluster) start(id uint64) *Store {
+	cfg := defaultConfig()
+	cfg.dir, cfg.id = c.dirs[id], id
+	cfg.peers = map[uint64]string{}
+	for peer := range c.dirs {
+		cfg.peers[peer] = "mem"
+	}
+	cfg.tick = 5 * time.Millisecond
+	for _, o := range c.opts {
+		o(&cfg)
+	}
+	s, err := open(c.t.Context(), cfg, c.net.transport(id))
+	require.NoError(c.t, err)
+	c.t.Cleanup(func() { _ = s.Close() })
+	c.stores[id] = s
+	return s
+}
+
+// leader waits for a replica to know a leader and returns it.
+func (c *cluster) leader() uint64 {
+	var lead uint64
+	require.Eventually(c.t, func() bool {
+		for _, s := range c.stores {
+			if lead = s.node.lead.Load(); lead != etcdraft.None {
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, time.Millisecond)
+	return lead
+}
+
+// follower returns a replica that is not the leader.
+func (c *cluster) follower() *Store {
+	lead := c.leader()
+	for id, s := range c.stores {
+		if id != lead {
+			return s
+		}
+	}
+	c.t.Fatal("no follower")
+	return nil
+}
+
+func singleFactory(t *testing.T) statestore.Capabilities {
+	caps, err := New(t.Context(), "raft://"+t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	return caps
+}
+
+// A single replica must pass the same conformance suite as the memory driver
+// (the executable spec), the timing suite included: the state machine takes
+// time only from commands and the local clock, so virtual time applies.
+func TestConformance_Raft(t *testing.T) {
+	statestoretest.RunConformance(t, singleFactory)
+	statestoretest.RunTimingConformance(t, singleFactory)
+}
+
+func TestConformance_Raft_Linearizability(t *testing.T) {
+	statestoretest.RunKVLinearizability(t, singleFactory)
+}
+
+// Through a follower every write is forwarded to the leader and every read
+// takes a ReadIndex, so the suite checks the replicated paths end to end.
+func TestConformance_RaftCluster(t *testing.T) {
+	statestoretest.RunConformance(t, func(t *testing.T) statestore.Capabilities {
+		return newCluster(t, 3).follower()
+	})
+}
+
+// The linearizability checker runs against a three-replica cluster whose
+// clients spread over all replicas while the leader is repeatedly cut off,
+// so operations straddle elections, lost proposals, and re-proposals.
+func TestConformance_RaftCluster_LinearizabilityUnderFailover(t *testing.T) {
+	statestoretest.RunKVLinearizability(t, func(t *testing.T) statestore.Capabilities {
+		c := newCluster(t, 3)
+		ctx, cancel := context.WithCancel(context.Background())
+		var failovers atomic.Int64
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				lead := c.leader()
+				c.net.isolate(lead, true)
+				sleepCtx(ctx, 150*time.Millisecond)
+				c.net.isolate(lead, false)
+				failovers.Add(1)
+				sleepCtx(ctx, 100*time.Millisecond)
+			}
+		})
+		t.Cleanup(func() {
+			cancel()
+			wg.Wait()
+			t.Logf("%d leader failovers", failovers.Load())
+		})
+		var kvs []statestore.KVStore
+		for id := uint64(1); id <= 3; id++ {
+			kv, err := c.stores[id].KV()
+			require.NoError(t, err)
+			kvs = append(kvs, kv)
+		}
+		return spreadCaps{Capabilities: c.stores[1], kv: &spreadKV{kvs: kvs}}
+	})
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// spreadCaps serves its KV from every replica in turn.
+type spreadCaps struct {
+	statestore.Capabilities
+	kv *spreadKV
+}
+
+func (s spreadCaps) KV() (statestore.KVStore, error) { return s.kv, nil }
+
+type spreadKV struct {
+	kvs  []statestore.KVStore
+	next atomic.Uint64
+}
+
+func (s *spreadKV) pick() statestore.KVStore {
+	return s.kvs[s.next.Add(1)%uint64(len(s.kvs))]
+}
+
+func (s *spreadKV) Get(ctx context.Context, sc statestore.Scope, key string) (statestore.Value, error) {
+	return s.pick().Get(ctx, sc, key)
+}
+
+func (s *spreadKV) Set(ctx context.Context, sc statestore.Scope, key string, val []byte, o statestore.SetOptions) error {
+	return s.pick().Set(ctx, sc, key, val, o)
+}
+
+func (s *spreadKV) Delete(ctx context.Context, sc statestore.Scope, key string, ifVersion int64) error {
+	return s.pick().Delete(ctx, sc, key, ifVersion)
+}
+
+func (s *spreadKV) List(ctx context.Context, sc statestore.Scope, prefix string, page statestore.Page) (statestore.KeyPage, error) {
+	return s.pick().List(ctx, sc, prefix, page)
+}
+
+var testScope = statestore.Scope{Namespace: "default", Owner: "function/f", Keyspace: "ks"}
+
+func TestRestartKeepsState(t *testing.T) {
+	dir := t.TempDir()
+	caps, err := New(t.Context(), "raft://"+dir)
+	require.NoError(t, err)
+	kv, _ := caps.KV()
+	q, _ := caps.Queue()
+	require.NoError(t, kv.Set(t.Context(), testScope, "k", []byte("v"), statestore.SetOptions{}))
+	id, err := q.Enqueue(t.Context(), "q", statestore.Message{Body: []byte("m")}, statestore.EnqueueOptions{})
+	require.NoError(t, err)
+	require.NoError(t, caps.Close())
+
+	caps, err = New(t.Context(), "raft://"+dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	kv, _ = caps.KV()
+	q, _ = caps.Queue()
+	v, err := kv.Get(t.Context(), testScope, "k")
+	require.NoError(t, err)
+	require.Equal(t, "v", string(v.Data))
+	leased, err := q.Lease(t.Context(), "q", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, leased, 1)
+	require.Equal(t, id, leased[0].ID)
+}
+
+// A replica that was down while the others compacted their logs catches up
+// from a snapshot of the leader's state once it is back.
+func TestSnapshotCatchUp(t *testing.T) {
+	c := newCluster(t, 3, WithSnapshotEntries(16))
+	lead := c.leader()
+	var lagging uint64 = 1
+	if lead == lagging {
+		lagging = 2
+	}
+	require.NoError(t, c.stores[lagging].Close())
+
+	kv, _ := c.stores[lead].KV()
+	for i := range 200 {
+		require.NoError(t, kv.Set(t.Context(), testScope, fmt.Sprintf("k%03d", i), []byte{byte(i)}, statestore.SetOptions{}))
+	}
+
+	restarted := c.start(lagging)
+	kv, _ = restarted.KV()
+	page, err := kv.List(t.Context(), testScope, "", statestore.Page{})
+	require.NoError(t, err)
+	require.Len(t, page.Keys, 200)
+	first, err := restarted.node.ms.FirstIndex()
+	require.NoError(t, err)
+	require.Greater(t, first, uint64(16), "caught up from a snapshot, not the log")
+	st := restarted.ConservationStats(t.Context())
+	require.Zero(t, st.Drift())
+}
+
+// A request id is applied once: a re-proposal of an applied command gets the
+// first outcome back instead of running again.
+func TestReproposalAppliesOnce(t *testing.T) {
+	caps := singleFactory(t)
+	s := caps.(*Store)
+	c := &command{ID: s.nextID(), Op: opEnqueue, Now: time.Now().UnixNano(), Queue: "q", Body: []byte("m")}
+	first, err := s.node.propose(t.Context(), c)
+	require.NoError(t, err)
+	again, err := s.node.propose(t.Context(), c)
+	require.NoError(t, err)
+	require.Equal(t, first, again)
+	require.EqualValues(t, 1, s.ConservationStats(t.Context()).Enqueued)
+}
+
+func TestParseDSN(t *testing.T) {
+	c, err := parseDSN("raft:///var/lib/state")
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/state", c.dir)
+	require.EqualValues(t, 1, c.id)
+	require.Empty(t, c.peers)
+
+	c, err = parseDSN("raft:///data?self=s-1&peers=s-0=http://s-0.p:8894,s-1=http://s-1.p:8894,s-2=http://s-2.p:8894")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, c.id)
+	require.Equal(t, map[uint64]string{1: "http://s-0.p:8894", 2: "http://s-1.p:8894", 3: "http://s-2.p:8894"}, c.peers)
+	require.Equal(t, DefaultListen, c.listen)
+
+	for _, bad := range []string{
+		"postgres://x",
+		"raft://",
+		"raft:///data?self=s-9&peers=s-0=http://a",
+		"raft:///data?self=s-0&peers=s-0",
+	} {
+		_, err := parseDSN(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+// TestOpen_Raft opens the driver the way a component does, by name and DSN.
+func TestOpen_Raft(t *testing.T) {
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "raft", DSN: "raft://" + t.TempDir()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	require.Eventually(t, func() bool { return caps.Ping(t.Context()) == nil }, 10*time.Second, 10*time.Millisecond)
+	require.NoError(t, caps.Close())
+	require.ErrorIs(t, caps.Ping(t.Context()), statestore.ErrClosed)
+}
+
+// TestNew_PeersNeedAuth: a replica never serves an unauthenticated peer
+// listener.
+func TestNew_PeersNeedAuth(t *testing.T) {
+	_, err := New(t.Context(), "raft://"+t.TempDir()+"?self=s-0&listen=127.0.0.1:0&peers=s-0=http://127.0.0.1:1,s-1=http://127.0.0.1:2")
+	require.ErrorIs(t, err, ErrUnauthenticatedPeers)
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type eventLog struct{ s *Store }
+
+// A stream is a bucket of its events keyed by sequence, under streams; its
+// head lives in heads, apart from the events, so Trim never moves the append
+// point.
+
+// eventRecord is a stored event.
+type eventRecord struct {
+	Type    string `json:"t,omitempty"`
+	Payload []byte `json:"p,omitempty"`
+	At      int64  `json:"at"`
+}
+
+func (v *view) streamHead(stream string) int64 {
+	return getInt(v.tx.Bucket(bucketHeads), nested(stream))
+}
+
+func (v *view) streamAppend(c *command) (result, error) {
+	head := v.streamHead(c.Stream)
+	if c.Expected != statestore.AppendAny && head != c.Expected {
+		return result{Code: codeVersionConflict, N: head}, nil
+	}
+	if len(c.Events) == 0 {
+		return result{N: head}, nil
+	}
+	b, err := v.tx.Bucket(bucketStreams).CreateBucketIfNotExists(nested(c.Stream))
+	if err != nil {
+		return result{}, err
+	}
+	for _, e := range c.Events {
+		head++
+		raw, err := json.Marshal(eventRecord{Type: e.Type, Payload: e.Payload, At: v.now})
+		if err != nil {
+			return result{}, err
+		}
+		if err := b.Put(u64(uint64(head)), raw); err != nil {
+			return result{}, err
+		}
+	}
+	return result{N: head}, putInt(v.tx.Bucket(bucketHeads), nested(c.Stream), head)
+}
+
+func (v *view) streamTrim(c *command) (result, error) {
+	b := v.tx.Bucket(bucketStreams).Bucket(nested(c.Stream))
+	if b == nil {
+		return result{}, nil
+	}
+	var stale [][]byte
+	cur := b.Cursor()
+	for k, _ := cur.First(); k != nil && int64(binary.BigEndian.Uint64(k)) < c.Below; k, _ = cur.Next() {
+		stale = append(stale, bytes.Clone(k))
+	}
+	return result{}, deleteKeys(b, stale)
+}
+
+func (v *view) streamRead(stream string, fromSeq int64, limit int) ([]statestore.Event, error) {
+	b := v.tx.Bucket(bucketStreams).Bucket(nested(stream))
+	if b == nil {
+		return nil, nil
+	}
+	var out []statestore.Event
+	cur := b.Cursor()
+	for k, raw := cur.Seek(u64(uint64(max(fromSeq+1, 0)))); k != nil; k, raw = cur.Next() {
+		var r eventRecord
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil, err
+		}
+		out = append(out, statestore.Event{
+			Seq:     int64(binary.BigEndian.Uint64(k)),
+			Type:    r.Type,
+			Payload: r.Payload,
+			At:      time.Unix(0, r.At),
+		})
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Append implements statestore.EventLog with optimistic concurrency on the
+// head sequence: it succeeds only when expectedSeq equals the current head
+// (invariant E1), or unconditionally for AppendAny. Events get sequential Seq
+// and the command's time as At; it returns the new head, or the current head
+// with ErrVersionConflict.
+func (l *eventLog) Append(ctx context.Context, stream string, expectedSeq int64, events []statestore.Event) (int64, error) {
+	res, err := l.s.write(ctx, &command{Op: opAppend, Stream: stream, Expected: expectedSeq, Events: events})
+	if err != nil && res.Code == codeOK {
+		return 0, err
+	}
+	return res.N, err
+}
+
+// Read implements statestore.EventLog: up to limit events with Seq > fromSeq,
+// in order. limit <= 0 returns all matching events.
+func (l *eventLog) Read(ctx context.Context, stream string, fromSeq int64, limit int) ([]statestore.Event, error) {
+	var out []statestore.Event
+	err := l.s.read(ctx, func(v *view) (err error) {
+		out, err = v.streamRead(stream, fromSeq, limit)
+		return err
+	})
+	return out, err
+}
+
+// Head implements statestore.EventLog: the stream's head sequence, 0 for an
+// absent stream.
+func (l *eventLog) Head(ctx context.Context, stream string) (int64, error) {
+	var head int64
+	err := l.s.read(ctx, func(v *view) error {
+		head = v.streamHead(stream)
+		return nil
+	})
+	return head, err
+}
+
+// Trim implements statestore.EventLog: drop events with Seq < belowSeq,
+// leaving the head where it is.
+func (l *eventLog) Trim(ctx context.Context, stream string, belowSeq int64) error {
+	_, err := l.s.write(ctx, &command{Op: opTrim, Stream: stream, Below: belowSeq})
+	return err
+}
@@ -0,0 +1,477 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.etcd.io/raft/v3/raftpb"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+// Top-level buckets of state.db.
+var (
+	bucketMeta     = []byte("meta")
+	bucketRequests = []byte("requests") // request id -> result
+	bucketReqTimes = []byte("reqtimes") // fsm time | request id -> nil
+	bucketKV       = []byte("kv")       // scope | key -> version | expiry | data
+	bucketKVTTL    = []byte("kvttl")    // expiry | scope | key -> nil
+	bucketKVCount  = []byte("kvcount")  // scope -> live keys
+	bucketStreams  = []byte("streams")  // stream -> (seq -> event)
+	bucketHeads    = []byte("heads")    // stream -> head seq
+	bucketQueues   = []byte("queues")   // queue -> queue buckets
+	topBuckets     = [][]byte{bucketMeta, bucketRequests, bucketReqTimes, bucketKV, bucketKVTTL, bucketKVCount, bucketStreams, bucketHeads, bucketQueues}
+
+	keyApplied = []byte("applied")
+	keyNow     = []byte("now")
+)
+
+// Command operations.
+const (
+	opSet         = "set"
+	opDelete      = "delete"
+	opAppend      = "append"
+	opTrim        = "trim"
+	opEnqueue     = "enqueue"
+	opLease       = "lease"
+	opAck         = "ack"
+	opNack        = "nack"
+	opDefer       = "defer"
+	opKill        = "kill"
+	opDeadLetters = "deadletters"
+	opRedrive     = "redrive"
+	opPurge       = "purge"
+	opTxn         = "txn"
+	opImport      = "import"
+)
+
+// command is one write, as proposed to the Raft log. Now is the proposer's
+// clock; the state machine never reads its own.
+type command struct {
+	ID  string `json:"id"`
+	Op  string `json:"op"`
+	Now int64  `json:"now"`
+
+	Scope     statestore.Scope `json:"scope,omitzero"`
+	Key       string           `json:"key,omitempty"`
+	Val       []byte           `json:"val,omitempty"`
+	IfVersion *int64           `json:"ifVersion,omitempty"`
+	TTL       time.Duration    `json:"ttl,omitempty"`
+	MaxKeys   int64            `json:"maxKeys,omitempty"`
+	Txn       *statestore.Txn  `json:"txn,omitempty"`
+
+	Stream   string             `json:"stream,omitempty"`
+	Expected int64              `json:"expected,omitempty"`
+	Events   []statestore.Event `json:"events,omitempty"`
+	Below    int64              `json:"below,omitempty"`
+
+	Queue      string          `json:"queue,omitempty"`
+	Body       []byte          `json:"body,omitempty"`
+	Delay      time.Duration   `json:"delay,omitempty"`
+	DedupKey   string          `json:"dedupKey,omitempty"`
+	Group      string          `json:"group,omitempty"`
+	N          int             `json:"n,omitempty"`
+	LeaseFor   time.Duration   `json:"leaseFor,omitempty"`
+	Grouped    bool            `json:"grouped,omitempty"`
+	Receipt    string          `json:"receipt,omitempty"`
+	RetryAfter time.Duration   `json:"retryAfter,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+	IDs        []string        `json:"ids,omitempty"`
+	Page       statestore.Page `json:"page,omitzero"`
+
+	Records []statestore.ArchiveRecord `json:"records,omitempty"`
+}
+
+// resultCode is the statestore error a command's outcome maps to. Outcomes
+// are part of the replicated state (a retried request gets the first
+// outcome back), so they are codes rather than error values.
+type resultCode uint8
+
+const (
+	codeOK resultCode = iota
+	codeVersionConflict
+	codeNotFound
+	codeQuotaExceeded
+	codeInvalidReceipt
+	codeTooLarge
+	codeInvalidArchive
+)
+
+// result is a command's outcome.
+type result struct {
+	Code     resultCode                 `json:"code,omitempty"`
+	N        int64                      `json:"n,omitempty"`
+	ID       string                     `json:"id,omitempty"`
+	Leased   []statestore.LeasedMessage `json:"leased,omitempty"`
+	Dead     []statestore.DeadMessage   `json:"dead,omitempty"`
+	Versions []int64                    `json:"versions,omitempty"`
+}
+
+func (r result) err() error {
+	switch r.Code {
+	case codeVersionConflict:
+		return statestore.ErrVersionConflict
+	case codeNotFound:
+		return statestore.ErrNotFound
+	case codeQuotaExceeded:
+		return statestore.ErrQuotaExceeded
+	case codeInvalidReceipt:
+		return statestore.ErrInvalidReceipt
+	case codeTooLarge:
+		return errTooLarge
+	case codeInvalidArchive:
+		return statestore.ErrInvalidArchive
+	}
+	return nil
+}
+
+// fsm is the replicated state machine: state.db, changed only by applying
+// committed commands in log order. Every replica applies the same commands
+// from the same state, so the application must be deterministic: it reads
+// time from the commands and iterates only ordered bbolt buckets.
+type fsm struct {
+	cfg  config
+	path string
+
+	// mu guards db, which a snapshot from the leader replaces.
+	mu     sync.RWMutex
+	db     *bolt.DB
+	closed bool
+}
+
+// view is a transaction over the state with the time it runs at: a command's
+// time when applying, the local clock when reading.
+type view struct {
+	tx          *bolt.Tx
+	now         int64
+	maxAttempts int
+}
+
+func openFSM(c config) (*fsm, error) {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return nil, err
+	}
+	f := &fsm{cfg: c, path: filepath.Join(c.dir, "state.db")}
+	if err := f.openDB(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fsm) openDB() error {
+	db, err := bolt.Open(f.path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range topBuckets {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return err
+	}
+	f.db = db
+	return nil
+}
+
+func (f *fsm) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return f.db.Close()
+}
+
+// applied returns the index of the last log entry applied to the state.
+func (f *fsm) applied() (uint64, error) {
+	var n uint64
+	err := f.view(func(tx *bolt.Tx) error {
+		n = uint64(getInt(tx.Bucket(bucketMeta), keyApplied))
+		return nil
+	})
+	return n, err
+}
+
+func (f *fsm) view(fn func(tx *bolt.Tx) error) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return statestore.ErrClosed
+	}
+	return f.db.View(fn)
+}
+
+// read runs fn over the current state.
+func (f *fsm) read(fn func(v *view) error) error {
+	return f.view(func(tx *bolt.Tx) error {
+		return fn(&view{tx: tx, maxAttempts: f.cfg.maxAttempts})
+	})
+}
+
+// apply applies the committed entries after the last applied one in one
+// transaction and returns the outcome of each command by request id. A
+// command already applied under its id (a re-proposal) is not applied again;
+// it gets its first outcome back.
+func (f *fsm) apply(ents []raftpb.Entry) (map[string]result, uint64, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return nil, 0, statestore.ErrClosed
+	}
+	results := map[string]result{}
+	var applied uint64
+	err := f.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(bucketMeta)
+		applied = uint64(getInt(meta, keyApplied))
+		v := &view{tx: tx, now: getInt(meta, keyNow), maxAttempts: f.cfg.maxAttempts}
+		for _, e := range ents {
+			if e.Index <= applied {
+				continue
+			}
+			applied = e.Index
+			if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+				continue
+			}
+			var c command
+			if err := json.Unmarshal(e.Data, &c); err != nil {
+				// Every replica skips it alike; the proposer times out.
+				continue
+			}
+			v.now = max(v.now, c.Now)
+			res, err := v.applyOnce(&c, f.cfg.dedupWindow)
+			if err != nil {
+				return fmt.Errorf("applying entry %d (%s): %w", e.Index, c.Op, err)
+			}
+			results[c.ID] = res
+		}
+		if err := putInt(meta, keyNow, v.now); err != nil {
+			return err
+		}
+		return putInt(meta, keyApplied, int64(applied))
+	})
+	return results, applied, err
+}
+
+// applyOnce executes c unless its request id was already executed, and
+// remembers the outcome for dedupWindow.
+func (v *view) applyOnce(c *command, dedupWindow time.Duration) (result, error) {
+	reqs := v.tx.Bucket(bucketRequests)
+	if raw := reqs.Get([]byte(c.ID)); raw != nil {
+		var res result
+		return res, json.Unmarshal(raw, &res)
+	}
+	if err := v.pruneRequests(dedupWindow); err != nil {
+		return result{}, err
+	}
+	if err := v.sweepKV(); err != nil {
+		return result{}, err
+	}
+	res, err := v.exec(c)
+	if err != nil {
+		return result{}, err
+	}
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return result{}, err
+	}
+	if err := reqs.Put([]byte(c.ID), raw); err != nil {
+		return result{}, err
+	}
+	return res, v.tx.Bucket(bucketReqTimes).Put(append(u64(uint64(v.now)), c.ID...), nil)
+}
+
+// pruneRequests forgets the request ids applied more than window ago.
+func (v *view) pruneRequests(window time.Duration) error {
+	if v.now < int64(window) {
+		return nil
+	}
+	times := v.tx.Bucket(bucketReqTimes)
+	reqs := v.tx.Bucket(bucketRequests)
+	cutoff := u64(uint64(v.now - int64(window)))
+	var stale [][]byte
+	c := times.Cursor()
+	for k, _ := c.First(); k != nil && bytes.Compare(k[:8], cutoff) < 0; k, _ = c.Next() {
+		stale = append(stale, bytes.Clone(k))
+	}
+	for _, k := range stale {
+		if err := reqs.Delete(k[8:]); err != nil {
+			return err
+		}
+	}
+	return deleteKeys(times, stale)
+}
+
+// maxKeyBytes bounds every name or key a command stores as a bbolt key,
+// well under bbolt's own limit; an oversized key is rejected before it is
+// proposed, since a bbolt error while applying stops the replica.
+const maxKeyBytes = 16 << 10
+
+var errTooLarge = fmt.Errorf("statestore/raft: key or name longer than %d bytes", maxKeyBytes)
+
+// validate rejects a command with a key or name too large to store.
+func (c *command) validate() error {
+	scope := len(c.Scope.Namespace) + len(c.Scope.Owner) + len(c.Scope.Keyspace)
+	sizes := []int{scope + len(c.Key), len(c.Stream), len(c.Queue), len(c.DedupKey), len(c.Group)}
+	if c.Txn != nil {
+		for _, op := range c.Txn.Ops {
+			sizes = append(sizes, scope+len(op.Key))
+		}
+	}
+	for _, r := range c.Records {
+		sizes = append(sizes, recordKeySizes(r)...)
+	}
+	for _, n := range sizes {
+		if n > maxKeyBytes {
+			return errTooLarge
+		}
+	}
+	return nil
+}
+
+func (v *view) exec(c *command) (result, error) {
+	if c.validate() != nil {
+		return result{Code: codeTooLarge}, nil
+	}
+	switch c.Op {
+	case opSet:
+		return v.kvSet(c)
+	case opDelete:
+		return v.kvDelete(c)
+	case opAppend:
+		return v.streamAppend(c)
+	case opTrim:
+		return v.streamTrim(c)
+	case opEnqueue:
+		return v.enqueue(c)
+	case opLease:
+		return v.lease(c)
+	case opAck, opNack, opDefer, opKill:
+		return v.settle(c)
+	case opDeadLetters:
+		return v.deadLetters(c)
+	case opRedrive:
+		return v.redrive(c)
+	case opPurge:
+		return v.purge(c)
+	case opTxn:
+		return v.kvTxn(c)
+	case opImport:
+		return v.importRecords(c)
+	}
+	// An op this version does not know: a newer replica proposed it. Leave
+	// the state alone rather than diverge.
+	return result{}, nil
+}
+
+// snapshot returns the whole state, for a follower too far behind to catch
+// up from the log.
+func (f *fsm) snapshot() ([]byte, error) {
+	var buf bytes.Buffer
+	err := f.view(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(&buf)
+		return err
+	})
+	return buf.Bytes(), err
+}
+
+// restore replaces the state with a snapshot taken by snapshot.
+func (f *fsm) restore(data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return statestore.ErrClosed
+	}
+	tmp := f.path + ".snap"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := f.db.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+	if err := f.openDB(); err != nil {
+		// Leave the fsm closed rather than half-open; the node fail-stops.
+		f.closed = true
+		return err
+	}
+	return nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	fh, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fh.Write(data); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Sync(); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+// conservationStats sums the queue accounting over every queue.
+func (f *fsm) conservationStats() (statestore.ConservationStats, error) {
+	var st statestore.ConservationStats
+	err := f.read(func(v *view) error {
+		return v.tx.Bucket(bucketQueues).ForEachBucket(func(name []byte) error {
+			q := v.queue(string(name[1:]))
+			st.Enqueued += getInt(q.meta, keyEnqueued) - getInt(q.meta, keyPurged)
+			st.Queued += int64(q.ready.Stats().KeyN + q.delayed.Stats().KeyN)
+			st.Leased += int64(q.leased.Stats().KeyN)
+			st.Dead += int64(q.dead.Stats().KeyN)
+			st.Acked += getInt(q.meta, keyAcked)
+			st.LeaseExpirations += getInt(q.meta, keyExpirations)
+			return nil
+		})
+	})
+	return st, err
+}
+
+var errCorrupt = errors.New("statestore/raft: corrupt state")
+
+// nested is the bucket name of a stream or queue; the prefix admits the empty
+// name, which bbolt does not.
+func nested(name string) []byte {
+	return append([]byte{'.'}, name...)
+}
+
+func getInt(b *bolt.Bucket, k []byte) int64 {
+	raw := b.Get(k)
+	if len(raw) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(raw))
+}
+
+func putInt(b *bolt.Bucket, k []byte, n int64) error {
+	return b.Put(k, u64(uint64(n)))
+}
+
+func addInt(b *bolt.Bucket, k []byte, delta int64) error {
+	return putInt(b, k, getInt(b, k)+delta)
+}
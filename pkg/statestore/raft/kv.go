@@ -0,0 +1,318 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type kvStore struct{ s *Store }
+
+// A KV entry is keyed by its scope prefix (each scope field length-prefixed,
+// so one scope's keys are contiguous and in key order) and the key. Its value
+// is the version and expiry (unix nanoseconds, 0 for none) followed by the
+// data. Entries with an expiry are also indexed by it in kvttl, which every
+// write sweeps, so a stored entry is live as of the last applied command and
+// kvcount holds each scope's live-key count exactly.
+
+func scopePrefix(s statestore.Scope) []byte {
+	var b []byte
+	for _, f := range []string{s.Namespace, s.Owner, s.Keyspace} {
+		b = binary.BigEndian.AppendUint32(b, uint32(len(f)))
+		b = append(b, f...)
+	}
+	return b
+}
+
+type kvEntry struct {
+	version   int64
+	expiresAt int64
+	data      []byte
+}
+
+func decodeKVEntry(raw []byte) (kvEntry, bool) {
+	if len(raw) < 16 {
+		return kvEntry{}, false
+	}
+	return kvEntry{
+		version:   int64(binary.BigEndian.Uint64(raw)),
+		expiresAt: int64(binary.BigEndian.Uint64(raw[8:])),
+		data:      raw[16:],
+	}, true
+}
+
+func (e kvEntry) encode() []byte {
+	b := make([]byte, 16, 16+len(e.data))
+	binary.BigEndian.PutUint64(b, uint64(e.version))
+	binary.BigEndian.PutUint64(b[8:], uint64(e.expiresAt))
+	return append(b, e.data...)
+}
+
+// expired is inclusive of the boundary (invariant K2).
+func (e kvEntry) expired(now int64) bool {
+	return e.expiresAt != 0 && now >= e.expiresAt
+}
+
+// kvLive returns the entry at k if it is present and not expired.
+func (v *view) kvLive(k []byte) (kvEntry, bool) {
+	e, ok := decodeKVEntry(v.tx.Bucket(bucketKV).Get(k))
+	if !ok || e.expired(v.now) {
+		return kvEntry{}, false
+	}
+	return e, true
+}
+
+// sweepKV deletes the entries that have expired by now.
+func (v *view) sweepKV() error {
+	ttl := v.tx.Bucket(bucketKVTTL)
+	var stale [][]byte
+	c := ttl.Cursor()
+	for k, _ := c.First(); k != nil && int64(binary.BigEndian.Uint64(k)) <= v.now; k, _ = c.Next() {
+		stale = append(stale, bytes.Clone(k))
+	}
+	for _, k := range stale {
+		if err := v.kvRemove(k[8:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kvRemove deletes the entry at k, if stored, with its index entries.
+func (v *view) kvRemove(k []byte) error {
+	kv := v.tx.Bucket(bucketKV)
+	e, ok := decodeKVEntry(kv.Get(k))
+	if !ok {
+		return nil
+	}
+	if e.expiresAt != 0 {
+		if err := v.tx.Bucket(bucketKVTTL).Delete(ttlKey(e.expiresAt, k)); err != nil {
+			return err
+		}
+	}
+	if err := kv.Delete(k); err != nil {
+		return err
+	}
+	return v.kvCount(k, -1)
+}
+
+// kvCount adjusts the live-key count of k's scope.
+func (v *view) kvCount(k []byte, delta int64) error {
+	scope := k[:scopeLen(k)]
+	counts := v.tx.Bucket(bucketKVCount)
+	if n := getInt(counts, scope) + delta; n > 0 {
+		return putInt(counts, scope, n)
+	}
+	return counts.Delete(scope)
+}
+
+// scopeLen is the length of the scope prefix of an entry key.
+func scopeLen(k []byte) int {
+	n := 0
+	for range 3 {
+		n += 4 + int(binary.BigEndian.Uint32(k[n:]))
+	}
+	return n
+}
+
+func ttlKey(expiresAt int64, k []byte) []byte {
+	return append(u64(uint64(expiresAt)), k...)
+}
+
+func (v *view) kvSet(c *command) (result, error) {
+	scope := scopePrefix(c.Scope)
+	k := append(scope, c.Key...)
+	cur, exists := v.kvLive(k)
+
+	// The CAS check runs before the budget check: a write that could never
+	// apply is a version conflict, not a quota rejection.
+	if c.IfVersion != nil && cur.version != *c.IfVersion {
+		return result{Code: codeVersionConflict}, nil
+	}
+	if c.MaxKeys > 0 && !exists && getInt(v.tx.Bucket(bucketKVCount), scope) >= c.MaxKeys {
+		return result{Code: codeQuotaExceeded}, nil
+	}
+
+	_, err := v.kvPut(k, cur, c.Val, c.TTL)
+	return result{}, err
+}
+
+// kvPut stores data at k as the version after cur, the live entry there (if
+// any), expiring ttl from now.
+func (v *view) kvPut(k []byte, cur kvEntry, data []byte, ttl time.Duration) (int64, error) {
+	if err := v.kvRemove(k); err != nil {
+		return 0, err
+	}
+	next := kvEntry{version: cur.version + 1, data: data}
+	if ttl > 0 {
+		next.expiresAt = v.now + int64(ttl)
+		if err := v.tx.Bucket(bucketKVTTL).Put(ttlKey(next.expiresAt, k), nil); err != nil {
+			return 0, err
+		}
+	}
+	if err := v.tx.Bucket(bucketKV).Put(k, next.encode()); err != nil {
+		return 0, err
+	}
+	return next.version, v.kvCount(k, 1)
+}
+
+func (v *view) kvDelete(c *command) (result, error) {
+	k := append(scopePrefix(c.Scope), c.Key...)
+	if ifVersion := *c.IfVersion; ifVersion > 0 {
+		if cur, exists := v.kvLive(k); !exists || cur.version != ifVersion {
+			return result{Code: codeVersionConflict}, nil
+		}
+	}
+	return result{}, v.kvRemove(k)
+}
+
+// kvTxn applies c.Txn's ops if its compares hold and the scope's live-key
+// count after them fits c.MaxKeys.
+func (v *view) kvTxn(c *command) (result, error) {
+	if c.Txn == nil {
+		return result{}, nil
+	}
+	scope := scopePrefix(c.Scope)
+	entry := func(key string) []byte { return append(bytes.Clone(scope), key...) }
+	for _, cmp := range c.Txn.Compares {
+		if cur, _ := v.kvLive(entry(cmp.Key)); cur.version != cmp.Version {
+			return result{Code: codeVersionConflict}, nil
+		}
+	}
+	if c.MaxKeys > 0 {
+		var delta int64
+		creates := false
+		for _, op := range c.Txn.Ops {
+			_, exists := v.kvLive(entry(op.Key))
+			switch {
+			case op.Type == statestore.TxnPut && !exists:
+				delta++
+				creates = true
+			case op.Type == statestore.TxnDelete && exists:
+				delta--
+			}
+		}
+		if creates && getInt(v.tx.Bucket(bucketKVCount), scope)+delta > c.MaxKeys {
+			return result{Code: codeQuotaExceeded}, nil
+		}
+	}
+
+	versions := make([]int64, len(c.Txn.Ops))
+	for i, op := range c.Txn.Ops {
+		k := entry(op.Key)
+		if op.Type == statestore.TxnDelete {
+			if err := v.kvRemove(k); err != nil {
+				return result{}, err
+			}
+			continue
+		}
+		cur, _ := v.kvLive(k)
+		n, err := v.kvPut(k, cur, op.Value, op.TTL)
+		if err != nil {
+			return result{}, err
+		}
+		versions[i] = n
+	}
+	return result{Versions: versions}, nil
+}
+
+// kvList returns the page of live keys under prefix after page.Token.
+func (v *view) kvList(s statestore.Scope, prefix string, page statestore.Page) statestore.KeyPage {
+	scope := scopePrefix(s)
+	full := append(bytes.Clone(scope), prefix...)
+	start := full
+	if page.Token != "" && page.Token > prefix {
+		start = append(bytes.Clone(scope), page.Token...)
+	}
+	var keys []string
+	c := v.tx.Bucket(bucketKV).Cursor()
+	for k, raw := c.Seek(start); k != nil && bytes.HasPrefix(k, full); k, raw = c.Next() {
+		key := string(k[len(scope):])
+		if page.Token != "" && key <= page.Token {
+			continue
+		}
+		if e, ok := decodeKVEntry(raw); !ok || e.expired(v.now) {
+			continue
+		}
+		keys = append(keys, key)
+		if page.Limit > 0 && len(keys) > page.Limit {
+			return statestore.KeyPage{Keys: keys[:page.Limit], Next: keys[page.Limit-1]}
+		}
+	}
+	return statestore.KeyPage{Keys: keys}
+}
+
+// Get implements statestore.KVStore.
+func (kv *kvStore) Get(ctx context.Context, s statestore.Scope, key string) (statestore.Value, error) {
+	var val statestore.Value
+	err := kv.s.read(ctx, func(v *view) error {
+		e, ok := v.kvLive(append(scopePrefix(s), key...))
+		if !ok {
+			return statestore.ErrNotFound
+		}
+		val = statestore.Value{Data: bytes.Clone(e.data), Version: e.version}
+		if val.Data == nil {
+			val.Data = []byte{}
+		}
+		return nil
+	})
+	return val, err
+}
+
+// Set implements statestore.KVStore, honoring the IfVersion CAS semantics and
+// TTL from o. An expired key counts as absent.
+func (kv *kvStore) Set(ctx context.Context, s statestore.Scope, key string, val []byte, o statestore.SetOptions) error {
+	return kv.SetCounted(ctx, s, key, val, o, 0)
+}
+
+// SetCounted implements statestore.CountedKV: the budget check and the write
+// are one command, so they are one step of the state machine (RFC-0023 S3).
+func (kv *kvStore) SetCounted(ctx context.Context, s statestore.Scope, key string, val []byte, o statestore.SetOptions, maxKeys int64) error {
+	_, err := kv.s.write(ctx, &command{
+		Op: opSet, Scope: s, Key: key, Val: val, IfVersion: o.IfVersion, TTL: o.TTL, MaxKeys: maxKeys,
+	})
+	return err
+}
+
+// Delete implements statestore.KVStore. ifVersion <= 0 deletes unconditionally
+// (idempotent for an absent key); a positive ifVersion is a CAS delete.
+func (kv *kvStore) Delete(ctx context.Context, s statestore.Scope, key string, ifVersion int64) error {
+	_, err := kv.s.write(ctx, &command{Op: opDelete, Scope: s, Key: key, IfVersion: &ifVersion})
+	return err
+}
+
+// Txn implements statestore.TxnKV: the compares, the budget check and the
+// writes are one command, so they are one step of the state machine.
+func (kv *kvStore) Txn(ctx context.Context, s statestore.Scope, t statestore.Txn, maxKeys int64) ([]int64, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := kv.s.write(ctx, &command{Op: opTxn, Scope: s, Txn: &t, MaxKeys: maxKeys})
+	if err != nil {
+		return nil, err
+	}
+	return res.Versions, nil
+}
+
+// List implements statestore.KVStore: lexicographically ordered keys under
+// prefix, paginated via page.Token (the last key of the previous page).
+func (kv *kvStore) List(ctx context.Context, s statestore.Scope, prefix string, page statestore.Page) (statestore.KeyPage, error) {
+	var out statestore.KeyPage
+	err := kv.s.read(ctx, func(v *view) error {
+		out = v.kvList(s, prefix, page)
+		return nil
+	})
+	return out, err
+}
+
+var (
+	_ statestore.CountedKV = (*kvStore)(nil)
+	_ statestore.TxnKV     = (*kvStore)(nil)
+)
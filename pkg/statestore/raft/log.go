@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	etcdraft "go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// raftLog is the durable half of the Raft storage: the hard state, the latest
+// snapshot's metadata, and the log entries after it, in raft.db. The node
+// serves Raft from a MemoryStorage loaded from it at start and writes every
+// Ready through to it before acting on the Ready.
+type raftLog struct {
+	db *bolt.DB
+}
+
+var (
+	bucketRaft    = []byte("raft")
+	bucketEntries = []byte("entries")
+
+	keyHardState = []byte("hardstate")
+	keySnapshot  = []byte("snapshot")
+)
+
+func openLog(dir string) (*raftLog, error) {
+	db, err := bolt.Open(filepath.Join(dir, "raft.db"), 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketRaft, bucketEntries} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &raftLog{db: db}, nil
+}
+
+func (l *raftLog) close() error { return l.db.Close() }
+
+// load fills ms with the persisted state and reports whether there was any,
+// that is, whether the node is restarting rather than bootstrapping.
+func (l *raftLog) load(ms *etcdraft.MemoryStorage) (snap raftpb.Snapshot, restart bool, err error) {
+	err = l.db.View(func(tx *bolt.Tx) error {
+		rb := tx.Bucket(bucketRaft)
+		if raw := rb.Get(keySnapshot); raw != nil {
+			if err := snap.Unmarshal(raw); err != nil {
+				return err
+			}
+			if err := ms.ApplySnapshot(snap); err != nil {
+				return err
+			}
+			restart = true
+		}
+		if raw := rb.Get(keyHardState); raw != nil {
+			var hs raftpb.HardState
+			if err := hs.Unmarshal(raw); err != nil {
+				return err
+			}
+			if err := ms.SetHardState(hs); err != nil {
+				return err
+			}
+			restart = true
+		}
+		var ents []raftpb.Entry
+		err := tx.Bucket(bucketEntries).ForEach(func(_, v []byte) error {
+			var e raftpb.Entry
+			if err := e.Unmarshal(v); err != nil {
+				return err
+			}
+			ents = append(ents, e)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(ents) > 0 {
+			restart = true
+		}
+		return ms.Append(ents)
+	})
+	return snap, restart, err
+}
+
+// save persists a Ready's hard state and new entries. New entries replace any
+// persisted entries at or after the first of them: a follower's conflicting
+// uncommitted tail is overwritten by the leader's.
+func (l *raftLog) save(hs raftpb.HardState, ents []raftpb.Entry) error {
+	if etcdraft.IsEmptyHardState(hs) && len(ents) == 0 {
+		return nil
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		if !etcdraft.IsEmptyHardState(hs) {
+			raw, err := hs.Marshal()
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(bucketRaft).Put(keyHardState, raw); err != nil {
+				return err
+			}
+		}
+		if len(ents) == 0 {
+			return nil
+		}
+		eb := tx.Bucket(bucketEntries)
+		var stale [][]byte
+		c := eb.Cursor()
+		for k, _ := c.Seek(u64(ents[0].Index)); k != nil; k, _ = c.Next() {
+			stale = append(stale, bytes.Clone(k))
+		}
+		if err := deleteKeys(eb, stale); err != nil {
+			return err
+		}
+		for i := range ents {
+			raw, err := ents[i].Marshal()
+			if err != nil {
+				return err
+			}
+			if err := eb.Put(u64(ents[i].Index), raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// saveSnapshot records snap's metadata (never its data: the state machine is
+// its own snapshot) and drops the entries it covers, or every entry when it
+// was received from the leader and supersedes the whole log.
+func (l *raftLog) saveSnapshot(snap raftpb.Snapshot, dropAll bool) error {
+	meta := raftpb.Snapshot{Metadata: snap.Metadata}
+	raw, err := meta.Marshal()
+	if err != nil {
+		return err
+	}
+	return l.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketRaft).Put(keySnapshot, raw); err != nil {
+			return err
+		}
+		if dropAll {
+			if err := tx.DeleteBucket(bucketEntries); err != nil {
+				return err
+			}
+			_, err := tx.CreateBucket(bucketEntries)
+			return err
+		}
+		return nil
+	})
+}
+
+// compact drops the entries at or below index.
+func (l *raftLog) compact(index uint64) error {
+	return l.db.Update(func(tx *bolt.Tx) error {
+		eb := tx.Bucket(bucketEntries)
+		var stale [][]byte
+		c := eb.Cursor()
+		for k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) <= index; k, _ = c.Next() {
+			stale = append(stale, bytes.Clone(k))
+		}
+		return deleteKeys(eb, stale)
+	})
+}
+
+// deleteKeys deletes keys collected by a cursor walk; deleting under a moving
+// cursor can skip keys.
+func deleteKeys(b *bolt.Bucket, keys [][]byte) error {
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// u64 is the big-endian key encoding, which sorts like the integers.
+func u64(n uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, n)
+}
@@ -0,0 +1,492 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	etcdraft "go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/raftpb"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var errNoLeader = errors.New("statestore/raft: no leader")
+
+// node runs one replica's Raft loop: it persists and sends what Raft hands
+// it, applies committed commands to the fsm, and wakes the operations
+// waiting on them. Any storage error stops the node (fail-stop): a replica
+// that cannot persist or apply must not keep voting.
+type node struct {
+	cfg  config
+	rn   etcdraft.Node
+	ms   *etcdraft.MemoryStorage
+	log  *raftLog
+	fsm  *fsm
+	tr   transport
+	lead atomic.Uint64
+
+	// Loop-owned: the membership, the index of the last snapshot, and the
+	// last log index handed to the fsm.
+	confState raftpb.ConfState
+	snapIndex uint64
+	logIndex  uint64
+
+	mu        sync.Mutex
+	applied   uint64                 // the fsm's applied index
+	appliedCh chan struct{}          // closed when applied advances
+	waiters   map[string]chan result // proposals by request id
+	reads     map[string]chan uint64 // ReadIndex requests by id
+
+	stopc    chan struct{}
+	stopOnce sync.Once
+	done     chan struct{} // closed once the loop has exited
+	err      error         // why it exited; set before done closes
+}
+
+func startNode(ctx context.Context, c config, f *fsm, tr transport) (*node, error) {
+	l, err := openLog(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	n := &node{
+		cfg:       c,
+		ms:        etcdraft.NewMemoryStorage(),
+		log:       l,
+		fsm:       f,
+		tr:        tr,
+		appliedCh: make(chan struct{}),
+		waiters:   map[string]chan result{},
+		reads:     map[string]chan uint64{},
+		stopc:     make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	snap, restart, err := l.load(n.ms)
+	if err == nil && !restart {
+		snap, err = n.bootstrap()
+	}
+	if err == nil {
+		n.applied, err = f.applied()
+	}
+	if err != nil {
+		_ = l.close()
+		return nil, err
+	}
+	n.confState = snap.Metadata.ConfState
+	n.snapIndex = snap.Metadata.Index
+	n.logIndex = snap.Metadata.Index
+
+	rc := &etcdraft.Config{
+		ID:              c.id,
+		ElectionTick:    c.electionTicks,
+		HeartbeatTick:   c.heartbeatTicks,
+		Storage:         n.ms,
+		MaxSizePerMsg:   1 << 20,
+		MaxInflightMsgs: 256,
+		CheckQuorum:     true,
+		PreVote:         true,
+		ReadOnlyOption:  etcdraft.ReadOnlySafe,
+		Logger:          raftLogger{c.logger},
+	}
+	n.rn = etcdraft.RestartNode(rc)
+	if tr != nil {
+		if err := tr.start(n); err != nil {
+			n.rn.Stop()
+			_ = l.close()
+			return nil, err
+		}
+	}
+	go n.run()
+	if len(c.peers) == 0 {
+		// A single voter elects itself; do it now rather than after an
+		// election timeout.
+		_ = n.rn.Campaign(ctx)
+	}
+	return n, nil
+}
+
+// bootstrap seeds a new replica's log with the initial snapshot every
+// replica starts from: empty state and the static voter set. Starting from a
+// common snapshot rather than from conf-change entries means there is nothing
+// to apply before the first election, so a single replica can elect itself
+// at once.
+func (n *node) bootstrap() (raftpb.Snapshot, error) {
+	voters := []uint64{n.cfg.id}
+	if len(n.cfg.peers) > 0 {
+		voters = voters[:0]
+		for id := range n.cfg.peers {
+			voters = append(voters, id)
+		}
+		slices.Sort(voters)
+	}
+	snap := raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{
+		Index:     1,
+		Term:      1,
+		ConfState: raftpb.ConfState{Voters: voters},
+	}}
+	if err := n.log.saveSnapshot(snap, true); err != nil {
+		return snap, err
+	}
+	return snap, n.ms.ApplySnapshot(snap)
+}
+
+func (n *node) run() {
+	ticker := time.NewTicker(n.cfg.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.rn.Tick()
+		case rd := <-n.rn.Ready():
+			if err := n.handle(rd); err != nil {
+				n.cfg.logger.Error(err, "replica stopped: storage failure")
+				n.shutdown(fmt.Errorf("statestore/raft: replica stopped: %w", err))
+				return
+			}
+		case <-n.stopc:
+			n.shutdown(statestore.ErrClosed)
+			return
+		}
+	}
+}
+
+func (n *node) shutdown(err error) {
+	n.err = err
+	n.rn.Stop()
+	if n.tr != nil {
+		n.tr.stop()
+	}
+	_ = n.log.close()
+	close(n.done)
+}
+
+// stop stops the loop and waits for it to exit.
+func (n *node) stop() {
+	n.stopOnce.Do(func() { close(n.stopc) })
+	<-n.done
+}
+
+// handle acts on one Ready: persist, send, apply, answer reads, advance.
+func (n *node) handle(rd etcdraft.Ready) error {
+	if rd.SoftState != nil {
+		if prev := n.lead.Swap(rd.Lead); prev != rd.Lead {
+			n.cfg.logger.Info("raft leader changed", "leader", rd.Lead, "self", n.cfg.id)
+		}
+	}
+	if !etcdraft.IsEmptySnap(rd.Snapshot) {
+		if err := n.installSnapshot(rd.Snapshot); err != nil {
+			return err
+		}
+	}
+	if err := n.log.save(rd.HardState, rd.Entries); err != nil {
+		return err
+	}
+	if !etcdraft.IsEmptyHardState(rd.HardState) {
+		if err := n.ms.SetHardState(rd.HardState); err != nil {
+			return err
+		}
+	}
+	if err := n.ms.Append(rd.Entries); err != nil {
+		return err
+	}
+	n.send(rd.Messages)
+	if err := n.apply(rd.CommittedEntries); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	for _, rs := range rd.ReadStates {
+		if ch, ok := n.reads[string(rs.RequestCtx)]; ok {
+			select {
+			case ch <- rs.Index:
+			default:
+			}
+		}
+	}
+	n.mu.Unlock()
+	if err := n.maybeSnapshot(); err != nil {
+		return err
+	}
+	n.rn.Advance()
+	return nil
+}
+
+// installSnapshot replaces the state with one the leader sent.
+func (n *node) installSnapshot(snap raftpb.Snapshot) error {
+	if err := n.fsm.restore(snap.Data); err != nil {
+		return err
+	}
+	if err := n.log.saveSnapshot(snap, true); err != nil {
+		return err
+	}
+	if err := n.ms.ApplySnapshot(snap); err != nil {
+		return err
+	}
+	n.confState = snap.Metadata.ConfState
+	n.snapIndex = snap.Metadata.Index
+	n.logIndex = snap.Metadata.Index
+	applied, err := n.fsm.applied()
+	if err != nil {
+		return err
+	}
+	n.setApplied(applied)
+	n.cfg.logger.Info("installed snapshot from leader", "index", snap.Metadata.Index)
+	return nil
+}
+
+// send hands messages to the transport, attaching the state to snapshots.
+// The state may be ahead of the snapshot's index; the follower's fsm then
+// skips the entries it already reflects.
+func (n *node) send(msgs []raftpb.Message) {
+	if n.tr == nil {
+		return
+	}
+	out := msgs[:0]
+	for _, m := range msgs {
+		if m.Type == raftpb.MsgSnap {
+			data, err := n.fsm.snapshot()
+			if err != nil {
+				n.cfg.logger.Error(err, "reading state for snapshot", "peer", m.To)
+				n.rn.ReportSnapshot(m.To, etcdraft.SnapshotFailure)
+				continue
+			}
+			snap := *m.Snapshot
+			snap.Data = data
+			m.Snapshot = &snap
+		}
+		out = append(out, m)
+	}
+	n.tr.send(out)
+}
+
+func (n *node) apply(ents []raftpb.Entry) error {
+	if len(ents) == 0 {
+		return nil
+	}
+	for _, e := range ents {
+		var cc raftpb.ConfChangeI
+		switch e.Type {
+		case raftpb.EntryConfChange:
+			var c raftpb.ConfChange
+			if err := c.Unmarshal(e.Data); err != nil {
+				return err
+			}
+			cc = c
+		case raftpb.EntryConfChangeV2:
+			var c raftpb.ConfChangeV2
+			if err := c.Unmarshal(e.Data); err != nil {
+				return err
+			}
+			cc = c
+		default:
+			continue
+		}
+		n.confState = *n.rn.ApplyConfChange(cc)
+	}
+	results, applied, err := n.fsm.apply(ents)
+	if err != nil {
+		return err
+	}
+	n.logIndex = ents[len(ents)-1].Index
+	n.mu.Lock()
+	for id, res := range results {
+		if ch, ok := n.waiters[id]; ok {
+			select {
+			case ch <- res:
+			default:
+			}
+		}
+	}
+	n.mu.Unlock()
+	n.setApplied(applied)
+	return nil
+}
+
+func (n *node) setApplied(applied uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if applied > n.applied {
+		n.applied = applied
+		close(n.appliedCh)
+		n.appliedCh = make(chan struct{})
+	}
+}
+
+// maybeSnapshot snapshots the log once enough entries were applied since the
+// last snapshot, and compacts it, keeping a tail for followers slightly
+// behind so they catch up from the log rather than from a snapshot.
+func (n *node) maybeSnapshot() error {
+	if n.logIndex < n.snapIndex+n.cfg.snapshotEntries {
+		return nil
+	}
+	snap, err := n.ms.CreateSnapshot(n.logIndex, &n.confState, nil)
+	if err != nil {
+		return err
+	}
+	if err := n.log.saveSnapshot(snap, false); err != nil {
+		return err
+	}
+	n.snapIndex = n.logIndex
+	if n.logIndex <= n.cfg.compactKeep {
+		return nil
+	}
+	compact := n.logIndex - n.cfg.compactKeep
+	if err := n.ms.Compact(compact); err != nil && !errors.Is(err, etcdraft.ErrCompacted) {
+		return err
+	}
+	return n.log.compact(compact)
+}
+
+// propose commits c and returns its result. A proposal can be lost (dropped
+// with no leader, or truncated by a new leader), so it is re-proposed until
+// its result arrives; the fsm applies a request id once.
+func (n *node) propose(ctx context.Context, c *command) (result, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return result{}, err
+	}
+	ch := make(chan result, 1)
+	n.mu.Lock()
+	n.waiters[c.ID] = ch
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.waiters, c.ID)
+		n.mu.Unlock()
+	}()
+	for {
+		wait := n.cfg.retryAfter()
+		switch err := n.rn.Propose(ctx, data); {
+		case errors.Is(err, etcdraft.ErrProposalDropped):
+			wait = n.cfg.tick
+		case err != nil:
+			return result{}, n.opErr(ctx, err)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case res := <-ch:
+			timer.Stop()
+			return res, nil
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result{}, ctx.Err()
+		case <-n.done:
+			timer.Stop()
+			return result{}, n.err
+		}
+	}
+}
+
+// linearize returns once the fsm reflects every command committed before it
+// was called (a Raft ReadIndex), retrying a read the leader never answered.
+func (n *node) linearize(ctx context.Context, id string) error {
+	ch := make(chan uint64, 1)
+	n.mu.Lock()
+	n.reads[id] = ch
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.reads, id)
+		n.mu.Unlock()
+	}()
+	for {
+		if err := n.rn.ReadIndex(ctx, []byte(id)); err != nil {
+			return n.opErr(ctx, err)
+		}
+		timer := time.NewTimer(n.cfg.retryAfter())
+		select {
+		case index := <-ch:
+			timer.Stop()
+			return n.waitApplied(ctx, index)
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-n.done:
+			timer.Stop()
+			return n.err
+		}
+	}
+}
+
+func (n *node) waitApplied(ctx context.Context, index uint64) error {
+	for {
+		n.mu.Lock()
+		applied, ch := n.applied, n.appliedCh
+		n.mu.Unlock()
+		if applied >= index {
+			return nil
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-n.done:
+			return n.err
+		}
+	}
+}
+
+// opErr maps an error from the Raft node to an operation's error.
+func (n *node) opErr(ctx context.Context, err error) error {
+	if errors.Is(err, etcdraft.ErrStopped) {
+		<-n.done
+		return n.err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// healthy reports why the replica cannot serve, if it cannot.
+func (n *node) healthy() error {
+	select {
+	case <-n.done:
+		return n.err
+	default:
+	}
+	if n.lead.Load() == etcdraft.None {
+		return errNoLeader
+	}
+	return nil
+}
+
+// handler, for the transport.
+
+func (n *node) step(ctx context.Context, m raftpb.Message) error { return n.rn.Step(ctx, m) }
+func (n *node) reportUnreachable(id uint64)                      { n.rn.ReportUnreachable(id) }
+func (n *node) reportSnapshot(id uint64, status etcdraft.SnapshotStatus) {
+	n.rn.ReportSnapshot(id, status)
+}
+
+// raftLogger adapts a logr.Logger to Raft's logger. Raft's info messages
+// (terms, votes) go to V(1); leader changes are logged by the node itself.
+type raftLogger struct{ l logr.Logger }
+
+func (r raftLogger) Debug(v ...any)                 { r.l.V(2).Info(fmt.Sprint(v...)) }
+func (r raftLogger) Debugf(format string, v ...any) { r.l.V(2).Info(fmt.Sprintf(format, v...)) }
+func (r raftLogger) Info(v ...any)                  { r.l.V(1).Info(fmt.Sprint(v...)) }
+func (r raftLogger) Infof(format string, v ...any)  { r.l.V(1).Info(fmt.Sprintf(format, v...)) }
+func (r raftLogger) Warning(v ...any)               { r.l.Info(fmt.Sprint(v...)) }
+func (r raftLogger) Warningf(format string, v ...any) {
+	r.l.Info(fmt.Sprintf(format, v...))
+}
+func (r raftLogger) Error(v ...any) { r.l.Error(nil, fmt.Sprint(v...)) }
+func (r raftLogger) Errorf(format string, v ...any) {
+	r.l.Error(nil, fmt.Sprintf(format, v...))
+}
+func (r raftLogger) Fatal(v ...any)                 { panic(fmt.Sprint(v...)) }
+func (r raftLogger) Fatalf(format string, v ...any) { panic(fmt.Sprintf(format, v...)) }
+func (r raftLogger) Panic(v ...any)                 { panic(fmt.Sprint(v...)) }
+func (r raftLogger) Panicf(format string, v ...any) { panic(fmt.Sprintf(format, v...)) }
@@ -0,0 +1,628 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type queueStore struct{ s *Store }
+
+// A queue is a bucket under queues holding its messages by sequence (m) and
+// one index per lifecycle state, so every operation walks only the messages
+// it can act on:
+//
+//	ready    seq -> nil                      queued and visible, in enqueue order
+//	delayed  visibleAt | seq -> nil          queued, not yet visible
+//	leased   expiry | seq -> nil             leased
+//	dead     id -> seq                       dead-lettered, in id order
+//	dedup    dedup key -> seq                unsettled messages with a dedup key
+//	groups   group (length-prefixed) | seq   unsettled grouped messages
+//	meta     counters
+//
+// A message id is "<queue>/<seq>", as in the memory driver. Acked messages
+// are deleted and only counted.
+var (
+	bucketMsgs    = []byte("m")
+	bucketReady   = []byte("ready")
+	bucketDelayed = []byte("delayed")
+	bucketLeased  = []byte("leased")
+	bucketDead    = []byte("dead")
+	bucketDedup   = []byte("dedup")
+	bucketGroups  = []byte("groups")
+	queueBuckets  = [][]byte{bucketMsgs, bucketReady, bucketDelayed, bucketLeased, bucketDead, bucketDedup, bucketGroups, bucketMeta}
+
+	keySeq         = []byte("seq")
+	keyEnqueued    = []byte("enqueued")
+	keyAcked       = []byte("acked")
+	keyPurged      = []byte("purged")
+	keyExpirations = []byte("expirations")
+)
+
+// Message states, mirroring queue.tla.
+const (
+	stateQueued uint8 = iota
+	stateLeased
+	stateDead
+)
+
+// qmsg is a stored message. Epoch is bumped on every lease; a settle is valid
+// only against the current epoch (invariant Q2).
+type qmsg struct {
+	Body       []byte `json:"b,omitempty"`
+	State      uint8  `json:"s"`
+	VisibleAt  int64  `json:"v,omitempty"`
+	Expiry     int64  `json:"x,omitempty"`
+	Attempts   int    `json:"a,omitempty"`
+	Epoch      int64  `json:"e,omitempty"`
+	DedupKey   string `json:"k,omitempty"`
+	Group      string `json:"g,omitempty"`
+	Reason     string `json:"r,omitempty"`
+	EnqueuedAt int64  `json:"q"`
+	DiedAt     int64  `json:"d,omitempty"`
+	Deferred   bool   `json:"f,omitempty"`
+}
+
+type queueBucket struct {
+	name                                                 string
+	m, ready, delayed, leased, dead, dedup, groups, meta *bolt.Bucket
+}
+
+// queue returns the named queue's buckets, or nil when it does not exist.
+func (v *view) queue(name string) *queueBucket {
+	b := v.tx.Bucket(bucketQueues).Bucket(nested(name))
+	if b == nil {
+		return nil
+	}
+	return &queueBucket{
+		name:    name,
+		m:       b.Bucket(bucketMsgs),
+		ready:   b.Bucket(bucketReady),
+		delayed: b.Bucket(bucketDelayed),
+		leased:  b.Bucket(bucketLeased),
+		dead:    b.Bucket(bucketDead),
+		dedup:   b.Bucket(bucketDedup),
+		groups:  b.Bucket(bucketGroups),
+		meta:    b.Bucket(bucketMeta),
+	}
+}
+
+func (v *view) createQueue(name string) (*queueBucket, error) {
+	b, err := v.tx.Bucket(bucketQueues).CreateBucketIfNotExists(nested(name))
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range queueBuckets {
+		if _, err := b.CreateBucketIfNotExists(sub); err != nil {
+			return nil, err
+		}
+	}
+	return v.queue(name), nil
+}
+
+func (q *queueBucket) id(seq uint64) string {
+	return q.name + "/" + strconv.FormatUint(seq, 10)
+}
+
+func (q *queueBucket) get(seq uint64) (*qmsg, error) {
+	raw := q.m.Get(u64(seq))
+	if raw == nil {
+		return nil, errCorrupt
+	}
+	var m qmsg
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (q *queueBucket) put(seq uint64, m *qmsg) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return q.m.Put(u64(seq), raw)
+}
+
+func timeSeqKey(t int64, seq uint64) []byte {
+	return append(u64(uint64(t)), u64(seq)...)
+}
+
+func groupKey(group string, seq uint64) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(group)))
+	b = append(b, group...)
+	return append(b, u64(seq)...)
+}
+
+// requeue makes m queued, visible at visibleAt: ready if that is due by now,
+// delayed otherwise.
+func (q *queueBucket) requeue(v *view, seq uint64, m *qmsg, visibleAt int64) error {
+	m.State = stateQueued
+	m.VisibleAt = visibleAt
+	var err error
+	if visibleAt <= v.now {
+		err = q.ready.Put(u64(seq), nil)
+	} else {
+		err = q.delayed.Put(timeSeqKey(visibleAt, seq), nil)
+	}
+	if err != nil {
+		return err
+	}
+	return q.put(seq, m)
+}
+
+// kill dead-letters m. Dead-lettering settles the message, so it gives up its
+// dedup key and its place in its group.
+func (q *queueBucket) kill(v *view, seq uint64, m *qmsg, reason string) error {
+	m.State = stateDead
+	m.Reason = reason
+	m.DiedAt = v.now
+	if err := q.unsettle(seq, m); err != nil {
+		return err
+	}
+	if err := q.dead.Put([]byte(q.id(seq)), u64(seq)); err != nil {
+		return err
+	}
+	return q.put(seq, m)
+}
+
+// unsettle drops the dedup and group entries of a message being settled.
+func (q *queueBucket) unsettle(seq uint64, m *qmsg) error {
+	if m.DedupKey != "" {
+		if err := q.dedup.Delete([]byte(m.DedupKey)); err != nil {
+			return err
+		}
+		m.DedupKey = ""
+	}
+	if m.Group != "" {
+		return q.groups.Delete(groupKey(m.Group, seq))
+	}
+	return nil
+}
+
+// promote moves the delayed messages that are visible by now to ready.
+func (q *queueBucket) promote(v *view) error {
+	var due [][]byte
+	c := q.delayed.Cursor()
+	for k, _ := c.First(); k != nil && int64(binary.BigEndian.Uint64(k)) <= v.now; k, _ = c.Next() {
+		due = append(due, bytes.Clone(k))
+	}
+	for _, k := range due {
+		if err := q.ready.Put(k[8:], nil); err != nil {
+			return err
+		}
+	}
+	return deleteKeys(q.delayed, due)
+}
+
+// reapExpired processes the leases whose visibility timeout has passed, as
+// the memory driver does: an expired lease with its attempt budget spent is
+// dead-lettered (SQS maxReceiveCount), any other is returned to the queue.
+func (q *queueBucket) reapExpired(v *view) error {
+	var expired [][]byte
+	c := q.leased.Cursor()
+	for k, _ := c.First(); k != nil && int64(binary.BigEndian.Uint64(k)) <= v.now; k, _ = c.Next() {
+		expired = append(expired, bytes.Clone(k))
+	}
+	if err := deleteKeys(q.leased, expired); err != nil {
+		return err
+	}
+	for _, k := range expired {
+		seq := binary.BigEndian.Uint64(k[8:])
+		m, err := q.get(seq)
+		if err != nil {
+			return err
+		}
+		if m.Attempts >= v.maxAttempts {
+			err = q.kill(v, seq, m, statestore.ReasonLeaseExpired)
+		} else {
+			err = q.requeue(v, seq, m, v.now)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return addInt(q.meta, keyExpirations, int64(len(expired)))
+}
+
+func (v *view) enqueue(c *command) (result, error) {
+	q, err := v.createQueue(c.Queue)
+	if err != nil {
+		return result{}, err
+	}
+	if c.DedupKey != "" {
+		if raw := q.dedup.Get([]byte(c.DedupKey)); raw != nil {
+			return result{ID: q.id(binary.BigEndian.Uint64(raw))}, nil
+		}
+	}
+	seq := uint64(getInt(q.meta, keySeq) + 1)
+	if err := putInt(q.meta, keySeq, int64(seq)); err != nil {
+		return result{}, err
+	}
+	if err := addInt(q.meta, keyEnqueued, 1); err != nil {
+		return result{}, err
+	}
+	m := &qmsg{Body: c.Body, DedupKey: c.DedupKey, Group: c.Group, EnqueuedAt: v.now}
+	if c.DedupKey != "" {
+		if err := q.dedup.Put([]byte(c.DedupKey), u64(seq)); err != nil {
+			return result{}, err
+		}
+	}
+	if c.Group != "" {
+		if err := q.groups.Put(groupKey(c.Group, seq), nil); err != nil {
+			return result{}, err
+		}
+	}
+	return result{ID: q.id(seq)}, q.requeue(v, seq, m, v.now+int64(c.Delay))
+}
+
+// groupHead returns the sequence of the first unsettled message of group.
+func (q *queueBucket) groupHead(group string) uint64 {
+	prefix := groupKey(group, 0)[:4+len(group)]
+	k, _ := q.groups.Cursor().Seek(prefix)
+	if k == nil || !bytes.HasPrefix(k, prefix) {
+		return 0
+	}
+	return binary.BigEndian.Uint64(k[len(prefix):])
+}
+
+func (v *view) lease(c *command) (result, error) {
+	q := v.queue(c.Queue)
+	if q == nil {
+		return result{}, nil
+	}
+	if err := q.reapExpired(v); err != nil {
+		return result{}, err
+	}
+	if err := q.promote(v); err != nil {
+		return result{}, err
+	}
+	// Pick first, then lease: the ready index must not change under the
+	// cursor. A grouped message is eligible only as its group's head, and the
+	// groups index still holds a head leased by this very call, so at most
+	// one message per group is picked.
+	var picked []uint64
+	cur := q.ready.Cursor()
+	for k, _ := cur.First(); k != nil && len(picked) < c.N; k, _ = cur.Next() {
+		seq := binary.BigEndian.Uint64(k)
+		if c.Grouped {
+			m, err := q.get(seq)
+			if err != nil {
+				return result{}, err
+			}
+			if m.Group != "" && q.groupHead(m.Group) != seq {
+				continue
+			}
+		}
+		picked = append(picked, seq)
+	}
+	var res result
+	for _, seq := range picked {
+		m, err := q.get(seq)
+		if err != nil {
+			return result{}, err
+		}
+		if m.Attempts >= v.maxAttempts {
+			continue
+		}
+		m.State = stateLeased
+		m.Epoch++
+		m.Attempts++
+		m.Deferred = false
+		m.Expiry = v.now + int64(c.LeaseFor)
+		if err := q.ready.Delete(u64(seq)); err != nil {
+			return result{}, err
+		}
+		if err := q.leased.Put(timeSeqKey(m.Expiry, seq), nil); err != nil {
+			return result{}, err
+		}
+		if err := q.put(seq, m); err != nil {
+			return result{}, err
+		}
+		id := q.id(seq)
+		res.Leased = append(res.Leased, statestore.LeasedMessage{
+			ID:       id,
+			Receipt:  statestore.EncodeReceipt(id, m.Epoch),
+			Body:     m.Body,
+			Attempts: m.Attempts,
+		})
+	}
+	return res, nil
+}
+
+// settle resolves c.Receipt to its leased message, checks the epoch guard
+// (invariants Q1, Q2), and applies the settle op.
+func (v *view) settle(c *command) (result, error) {
+	invalid := result{Code: codeInvalidReceipt}
+	id, epoch, ok := statestore.DecodeReceipt(c.Receipt)
+	if !ok {
+		return invalid, nil
+	}
+	name, seqStr, ok := strings.CutLast(id, "/")
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if !ok || err != nil {
+		return invalid, nil
+	}
+	q := v.queue(name)
+	if q == nil || q.m.Get(u64(seq)) == nil {
+		return invalid, nil
+	}
+	m, err := q.get(seq)
+	if err != nil {
+		return result{}, err
+	}
+	if m.State != stateLeased || m.Epoch != epoch {
+		return invalid, nil
+	}
+	if err := q.leased.Delete(timeSeqKey(m.Expiry, seq)); err != nil {
+		return result{}, err
+	}
+	m.Expiry = 0
+	switch c.Op {
+	case opAck:
+		if err := q.unsettle(seq, m); err != nil {
+			return result{}, err
+		}
+		if err := addInt(q.meta, keyAcked, 1); err != nil {
+			return result{}, err
+		}
+		return result{}, q.m.Delete(u64(seq))
+	case opNack:
+		if m.Attempts >= v.maxAttempts {
+			return result{}, q.kill(v, seq, m, statestore.ReasonRetriesExhausted)
+		}
+		return result{}, q.requeue(v, seq, m, v.now+int64(c.RetryAfter))
+	case opDefer:
+		m.Attempts--
+		m.Deferred = true
+		return result{}, q.requeue(v, seq, m, v.now+int64(c.RetryAfter))
+	default: // opKill
+		return result{}, q.kill(v, seq, m, c.Reason)
+	}
+}
+
+func (v *view) deadLetters(c *command) (result, error) {
+	q := v.queue(c.Queue)
+	if q == nil {
+		return result{}, nil
+	}
+	// Surface messages exhausted purely by lease expiry even if no Lease call
+	// has run since, so DeadLetters reflects the true dead set.
+	if err := q.reapExpired(v); err != nil {
+		return result{}, err
+	}
+	var res result
+	cur := q.dead.Cursor()
+	for k, sv := cur.Seek([]byte(c.Page.Token)); k != nil; k, sv = cur.Next() {
+		if c.Page.Token != "" && string(k) <= c.Page.Token {
+			continue
+		}
+		if c.Page.Limit > 0 && len(res.Dead) == c.Page.Limit {
+			break
+		}
+		m, err := q.get(binary.BigEndian.Uint64(sv))
+		if err != nil {
+			return result{}, err
+		}
+		res.Dead = append(res.Dead, statestore.DeadMessage{
+			ID:         string(k),
+			Body:       m.Body,
+			Reason:     m.Reason,
+			Attempts:   m.Attempts,
+			EnqueuedAt: time.Unix(0, m.EnqueuedAt),
+			DiedAt:     time.Unix(0, m.DiedAt),
+		})
+	}
+	return res, nil
+}
+
+func (v *view) redrive(c *command) (result, error) {
+	q := v.queue(c.Queue)
+	if q == nil {
+		return result{}, nil
+	}
+	var res result
+	for _, id := range c.IDs {
+		raw := q.dead.Get([]byte(id))
+		if raw == nil {
+			continue
+		}
+		seq := binary.BigEndian.Uint64(raw)
+		m, err := q.get(seq)
+		if err != nil {
+			return result{}, err
+		}
+		if err := q.dead.Delete([]byte(id)); err != nil {
+			return result{}, err
+		}
+		m.Attempts = 0
+		m.Reason = ""
+		m.DiedAt = 0
+		if m.Group != "" {
+			if err := q.groups.Put(groupKey(m.Group, seq), nil); err != nil {
+				return result{}, err
+			}
+		}
+		if err := q.requeue(v, seq, m, v.now); err != nil {
+			return result{}, err
+		}
+		res.N++
+	}
+	return res, nil
+}
+
+func (v *view) purge(c *command) (result, error) {
+	q := v.queue(c.Queue)
+	if q == nil {
+		return result{}, nil
+	}
+	var ids [][]byte
+	var res result
+	err := q.dead.ForEach(func(k, sv []byte) error {
+		ids = append(ids, bytes.Clone(k))
+		res.N++
+		return q.m.Delete(sv)
+	})
+	if err != nil {
+		return result{}, err
+	}
+	if err := deleteKeys(q.dead, ids); err != nil {
+		return result{}, err
+	}
+	return res, addInt(q.meta, keyPurged, res.N)
+}
+
+// stats reads the backlog snapshot without reaping (see statestore.QueueStats).
+func (v *view) stats(name string) (statestore.QueueStats, error) {
+	var st statestore.QueueStats
+	q := v.queue(name)
+	if q == nil {
+		return st, nil
+	}
+	oldest := int64(-1)
+	// Ready is in enqueue order, so its first message is its oldest.
+	if k, _ := q.ready.Cursor().First(); k != nil {
+		m, err := q.get(binary.BigEndian.Uint64(k))
+		if err != nil {
+			return st, err
+		}
+		oldest = m.EnqueuedAt
+		st.Visible = int64(q.ready.Stats().KeyN)
+	}
+	err := q.delayed.ForEach(func(k, _ []byte) error {
+		m, err := q.get(binary.BigEndian.Uint64(k[8:]))
+		if err != nil {
+			return err
+		}
+		switch {
+		case m.VisibleAt <= v.now:
+			st.Visible++
+			if oldest < 0 || m.EnqueuedAt < oldest {
+				oldest = m.EnqueuedAt
+			}
+		case m.Deferred:
+			st.Deferred++
+		}
+		return nil
+	})
+	if err != nil {
+		return st, err
+	}
+	st.Leased = int64(q.leased.Stats().KeyN)
+	st.Dead = int64(q.dead.Stats().KeyN)
+	if oldest >= 0 {
+		st.OldestVisibleAge = time.Duration(v.now - oldest)
+	}
+	return st, nil
+}
+
+// Enqueue implements statestore.Queue. With a DedupKey set, an existing
+// not-yet-settled message with the same key collapses the enqueue.
+func (qs *queueStore) Enqueue(ctx context.Context, queue string, msg statestore.Message, o statestore.EnqueueOptions) (string, error) {
+	res, err := qs.s.write(ctx, &command{
+		Op: opEnqueue, Queue: queue, Body: msg.Body, Delay: o.Delay, DedupKey: o.DedupKey, Group: o.Group,
+	})
+	return res.ID, err
+}
+
+// Lease implements statestore.Queue: up to n currently-visible messages, each
+// leased for leaseFor, with the lease epoch bumped so prior deliveries go stale.
+func (qs *queueStore) Lease(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	res, err := qs.s.write(ctx, &command{Op: opLease, Queue: queue, N: n, LeaseFor: leaseFor})
+	return res.Leased, err
+}
+
+// LeaseGrouped implements statestore.GroupedQueue: Lease, leasing a grouped
+// message only while it heads its group and nothing of the group is leased.
+func (qs *queueStore) LeaseGrouped(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	res, err := qs.s.write(ctx, &command{Op: opLease, Queue: queue, N: n, LeaseFor: leaseFor, Grouped: true})
+	return res.Leased, err
+}
+
+// Ack implements statestore.Queue: settle the current delivery as succeeded.
+func (qs *queueStore) Ack(ctx context.Context, receipt string) error {
+	_, err := qs.s.write(ctx, &command{Op: opAck, Receipt: receipt})
+	return err
+}
+
+// Nack implements statestore.Queue: requeue after retryAfter, or dead-letter
+// when the attempt budget is spent (invariant Q3).
+func (qs *queueStore) Nack(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	_, err := qs.s.write(ctx, &command{Op: opNack, Receipt: receipt, RetryAfter: retryAfter})
+	return err
+}
+
+// Defer implements statestore.Queue: requeue after retryAfter with the lease's
+// attempt refunded, never dead-lettering.
+func (qs *queueStore) Defer(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	_, err := qs.s.write(ctx, &command{Op: opDefer, Receipt: receipt, RetryAfter: retryAfter})
+	return err
+}
+
+// Kill implements statestore.Queue: dead-letter the current delivery
+// immediately, regardless of remaining attempts.
+func (qs *queueStore) Kill(ctx context.Context, receipt string, reason string) error {
+	_, err := qs.s.write(ctx, &command{Op: opKill, Receipt: receipt, Reason: reason})
+	return err
+}
+
+// DeadLetters implements statestore.Queue: a page of dead-lettered messages,
+// ordered by id, paginated by page.Token (the last id of the previous page).
+// It reaps expired leases first, so it is a write.
+func (qs *queueStore) DeadLetters(ctx context.Context, queue string, page statestore.Page) ([]statestore.DeadMessage, error) {
+	res, err := qs.s.write(ctx, &command{Op: opDeadLetters, Queue: queue, Page: page})
+	return res.Dead, err
+}
+
+// Redrive implements statestore.Queue: return dead-lettered messages to the
+// queue with attempts reset.
+func (qs *queueStore) Redrive(ctx context.Context, queue string, ids []string) (int64, error) {
+	res, err := qs.s.write(ctx, &command{Op: opRedrive, Queue: queue, IDs: ids})
+	return res.N, err
+}
+
+// Purge implements statestore.Queue: permanently drop every dead-lettered
+// message for queue, returning the count removed.
+func (qs *queueStore) Purge(ctx context.Context, queue string) (int64, error) {
+	res, err := qs.s.write(ctx, &command{Op: opPurge, Queue: queue})
+	return res.N, err
+}
+
+// Stats implements statestore.Queue: a read-only snapshot of the queue's
+// backlog. An unknown queue reports a zero snapshot.
+func (qs *queueStore) Stats(ctx context.Context, queue string) (statestore.QueueStats, error) {
+	var st statestore.QueueStats
+	err := qs.s.read(ctx, func(v *view) (err error) {
+		st, err = v.stats(queue)
+		return err
+	})
+	return st, err
+}
+
+var _ statestore.GroupedQueue = (*queueStore)(nil)
+
+// compile-time guard: the Store reports the conservation stats of the queue
+// it returns from Queue(), so the drift gauge observes it.
+var _ statestore.ConservationReporter = (*Store)(nil)
+
+// ConservationStats is the reporter the metrics layer reads for the
+// conservation drift gauge (invariant T1), from this replica's applied state.
+func (s *Store) ConservationStats(context.Context) statestore.ConservationStats {
+	st, err := s.fsm.conservationStats()
+	if err != nil {
+		s.cfg.logger.V(1).Info("reading conservation stats", "error", err)
+	}
+	return st
+}
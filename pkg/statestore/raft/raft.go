@@ -0,0 +1,305 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package raft is the replicated embedded statestore driver: every replica of
+// the statestore StatefulSet keeps the state in a local bbolt file and the
+// replicas agree on it with Raft (go.etcd.io/raft), so the embedded mode
+// survives the loss of a minority of pods without an external database. It
+// implements all three capabilities: KVStore (with CountedKV and TxnKV),
+// EventLog, and Queue (with GroupedQueue), plus Archiver.
+//
+// Every write is a command in the Raft log. A replica applies committed
+// commands in log order to its bbolt state machine, so all replicas move
+// through the same states; the proposer stamps each command with its clock so
+// TTL and lease expiry are decided identically everywhere. Reads take a Raft
+// ReadIndex, so they are linearizable on any replica, leader or not. A
+// command carries a request id the state machine remembers for a while, so a
+// proposal that may have been lost in a leader change is re-proposed without
+// being applied twice.
+//
+// Membership is static: the peer set is fixed by the DSN (the StatefulSet's
+// replicas), and adding or removing a replica means redeploying with a new
+// peer list over empty volumes.
+package raft
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/utils/loggerfactory"
+)
+
+func init() {
+	statestore.Register("raft", func(ctx context.Context, c statestore.Config) (statestore.Capabilities, error) {
+		return New(ctx, c.DSN, WithLogger(loggerfactory.GetLogger().WithName("statestore-raft")))
+	})
+}
+
+// DefaultListen is the peer (Raft transport) listen address when the DSN
+// names peers but no listen address (svcinfo.PortStatestorePeer).
+const DefaultListen = ":8894"
+
+// config is a node's parsed configuration.
+type config struct {
+	dir    string
+	id     uint64
+	peers  map[uint64]string // node id -> peer URL; empty for a single node
+	listen string
+
+	tick           time.Duration
+	electionTicks  int
+	heartbeatTicks int
+	// snapshotEntries is how many applied entries trigger a log snapshot, and
+	// compactKeep how many of them the log keeps behind it for slow followers.
+	snapshotEntries uint64
+	compactKeep     uint64
+	// dedupWindow is how long applied request ids are remembered.
+	dedupWindow time.Duration
+
+	maxAttempts int
+	logger      logr.Logger
+	auth        peerAuth
+}
+
+// peerAuth authenticates the peer transport: verify wraps the inbound
+// handler and sign the outbound round tripper. Either may be nil.
+type peerAuth struct {
+	verify func(http.Handler) http.Handler
+	sign   func(http.RoundTripper) http.RoundTripper
+}
+
+func defaultConfig() config {
+	return config{
+		id:              1,
+		tick:            100 * time.Millisecond,
+		electionTicks:   10,
+		heartbeatTicks:  1,
+		snapshotEntries: 10000,
+		compactKeep:     5000,
+		dedupWindow:     10 * time.Minute,
+		maxAttempts:     statestore.DefaultMaxAttempts,
+		logger:          logr.Discard(),
+	}
+}
+
+// retryAfter is how long a proposal or read waits before it is retried: long
+// enough for an election to finish.
+func (c config) retryAfter() time.Duration {
+	return 2 * time.Duration(c.electionTicks) * c.tick
+}
+
+// ErrUnauthenticatedPeers is returned by New for a DSN naming peers without
+// WithPeerAuth: the peer listener would apply whatever any client on the
+// network sent it to the replicated state.
+var ErrUnauthenticatedPeers = errors.New("statestore/raft: replicas need peer authentication (WithPeerAuth)")
+
+// Option configures a Store.
+type Option func(*config)
+
+// WithMaxAttempts sets the queue attempt budget (deliveries before a Nack
+// dead-letters). n <= 0 is ignored. Every replica must use the same budget.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxAttempts = n
+		}
+	}
+}
+
+// WithLogger sets the logger for leadership changes and Raft warnings.
+func WithLogger(l logr.Logger) Option {
+	return func(c *config) { c.logger = l }
+}
+
+// WithPeerAuth authenticates the Raft transport between replicas: verify
+// wraps the peer listener's handler and sign wraps the client transport
+// replicas send with (an HMAC verifier and signer pair, typically).
+func WithPeerAuth(verify func(http.Handler) http.Handler, sign func(http.RoundTripper) http.RoundTripper) Option {
+	return func(c *config) { c.auth = peerAuth{verify: verify, sign: sign} }
+}
+
+// WithTickInterval sets the Raft tick; the election timeout is ten ticks and
+// the heartbeat one. d <= 0 is ignored.
+func WithTickInterval(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.tick = d
+		}
+	}
+}
+
+// WithSnapshotEntries sets how many applied log entries trigger a snapshot
+// and log compaction. n == 0 is ignored.
+func WithSnapshotEntries(n uint64) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.snapshotEntries = n
+			c.compactKeep = n / 2
+		}
+	}
+}
+
+// parseDSN reads a raft:// DSN:
+//
+//	raft:///var/lib/fission-statestore?self=statestore-1&peers=statestore-0=http://statestore-0.statestore-peer:8894,statestore-1=http://...&listen=:8894
+//
+// The path is the data directory. peers lists every replica as name=URL in a
+// fixed order (a replica's node id is its 1-based position), and self names
+// this replica. Without peers the node is a single-replica cluster.
+func parseDSN(dsn string) (config, error) {
+	c := defaultConfig()
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return c, err
+	}
+	if u.Scheme != "raft" {
+		return c, fmt.Errorf("scheme %q, want raft://", u.Scheme)
+	}
+	if c.dir = u.Host + u.Path; c.dir == "" {
+		return c, errors.New("missing data directory")
+	}
+	q := u.Query()
+	peers := q.Get("peers")
+	if peers == "" {
+		return c, nil
+	}
+	self := q.Get("self")
+	c.id = 0
+	c.peers = map[uint64]string{}
+	for i, p := range strings.Split(peers, ",") {
+		name, addr, ok := strings.Cut(p, "=")
+		if !ok || name == "" || addr == "" {
+			return c, fmt.Errorf("peer %q is not name=URL", p)
+		}
+		id := uint64(i + 1)
+		c.peers[id] = addr
+		if name == self {
+			c.id = id
+		}
+	}
+	if c.id == 0 {
+		return c, fmt.Errorf("self %q is not among the peers", self)
+	}
+	if c.listen = q.Get("listen"); c.listen == "" {
+		c.listen = DefaultListen
+	}
+	return c, nil
+}
+
+// New opens the replica described by dsn (see parseDSN) and starts it. It
+// does not wait for a leader: operations block until the cluster has one,
+// and Ping reports whether it has.
+func New(ctx context.Context, dsn string, opts ...Option) (statestore.Capabilities, error) {
+	c, err := parseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("statestore/raft: DSN: %w", err)
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+	var tr transport
+	if len(c.peers) > 0 {
+		if c.auth.verify == nil || c.auth.sign == nil {
+			return nil, ErrUnauthenticatedPeers
+		}
+		tr = newHTTPTransport(c.id, c.listen, c.peers, c.auth)
+	}
+	s, err := open(ctx, c, tr)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Store is the Raft-replicated Capabilities of one replica.
+type Store struct {
+	cfg  config
+	fsm  *fsm
+	node *node
+
+	idPrefix string
+	idSeq    atomic.Uint64
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// open starts a replica over tr (nil for a single node).
+func open(ctx context.Context, c config, tr transport) (*Store, error) {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	s := &Store{cfg: c, idPrefix: hex.EncodeToString(b[:]) + "-"}
+	var err error
+	if s.fsm, err = openFSM(c); err != nil {
+		return nil, fmt.Errorf("statestore/raft: %w", err)
+	}
+	if s.node, err = startNode(ctx, c, s.fsm, tr); err != nil {
+		_ = s.fsm.close()
+		return nil, fmt.Errorf("statestore/raft: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) KV() (statestore.KVStore, error)        { return &kvStore{s}, nil }
+func (s *Store) EventLog() (statestore.EventLog, error) { return &eventLog{s}, nil }
+func (s *Store) Queue() (statestore.Queue, error)       { return &queueStore{s}, nil }
+
+// Ping reports whether this replica is running and knows a leader, so a
+// readiness gate keeps traffic off a replica cut off from the quorum.
+func (s *Store) Ping(context.Context) error {
+	return s.node.healthy()
+}
+
+// Close stops the replica. Operations in flight return ErrClosed.
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		s.node.stop()
+		s.closeErr = s.fsm.close()
+	})
+	return s.closeErr
+}
+
+// nextID returns a request id unique across replicas and restarts.
+func (s *Store) nextID() string {
+	return s.idPrefix + strconv.FormatUint(s.idSeq.Add(1), 10)
+}
+
+// write proposes c and returns its result once this replica has applied it.
+func (s *Store) write(ctx context.Context, c *command) (result, error) {
+	if err := c.validate(); err != nil {
+		return result{}, err
+	}
+	c.ID = s.nextID()
+	c.Now = time.Now().UnixNano()
+	res, err := s.node.propose(ctx, c)
+	if err != nil {
+		return result{}, err
+	}
+	return res, res.err()
+}
+
+// read runs fn against local state once it reflects every write that
+// completed before read was called.
+func (s *Store) read(ctx context.Context, fn func(v *view) error) error {
+	if err := s.node.linearize(ctx, s.nextID()); err != nil {
+		return err
+	}
+	return s.fsm.read(func(v *view) error {
+		v.now = time.Now().UnixNano()
+		return fn(v)
+	})
+}
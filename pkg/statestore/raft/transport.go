@@ -0,0 +1,395 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	etcdraft "go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// PathMessage is the peer endpoint replicas POST Raft messages to.
+const PathMessage = "/raft/message"
+
+// PathSnapshot is the peer endpoint replicas POST snapshots to, in chunks.
+const PathSnapshot = "/raft/snapshot"
+
+// peerQueueLen bounds the messages buffered for one peer. Raft retransmits
+// whatever is dropped when a slow or unreachable peer's queue is full.
+const peerQueueLen = 4096
+
+// peerBatch bounds the messages sent to a peer in one request, and
+// peerBatchBytes their size: a batch is closed once it reaches it.
+const (
+	peerBatch      = 256
+	peerBatchBytes = 8 << 20
+)
+
+// snapshotChunk bounds the state one snapshot request carries; a larger
+// snapshot goes out in consecutive chunks.
+const snapshotChunk = 4 << 20
+
+// maxPeerMessage bounds one inbound message. An entry holds one command,
+// which the capability API bounds (httpapi.MaxRequestBytes), and an append
+// carries entries up to the Raft message size or a single larger one.
+const maxPeerMessage = 16 << 20
+
+// MaxPeerRequestBytes bounds one peer request: a batch closed at
+// peerBatchBytes by a message of up to maxPeerMessage, or a snapshot chunk.
+// An authenticating wrapper around the peer listener (WithPeerAuth) must
+// accept bodies this large.
+const MaxPeerRequestBytes = 32 << 20
+
+// handler is the node side of a transport: where inbound messages go and how
+// delivery failures are reported back to Raft.
+type handler interface {
+	step(ctx context.Context, m raftpb.Message) error
+	reportUnreachable(id uint64)
+	reportSnapshot(id uint64, status etcdraft.SnapshotStatus)
+}
+
+// transport carries Raft messages between replicas. send never blocks the
+// Raft loop: messages that cannot be delivered are dropped and reported.
+type transport interface {
+	start(h handler) error
+	send(msgs []raftpb.Message)
+	stop()
+}
+
+// httpTransport is the production transport: every replica serves PathMessage
+// on its listen address and POSTs batches of length-prefixed messages to each
+// peer from one worker per peer, so a slow peer never delays the others.
+// Snapshots go to PathSnapshot in chunks, so no request holds the whole state.
+type httpTransport struct {
+	id     uint64
+	listen string
+	peers  map[uint64]string
+
+	verify func(http.Handler) http.Handler
+	client *http.Client
+
+	srv     *http.Server
+	queues  map[uint64]chan raftpb.Message
+	h       handler
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	snapMu sync.Mutex
+	snaps  map[uint64]*inboundSnapshot // sender id -> snapshot being received
+}
+
+// inboundSnapshot is a snapshot a peer is sending in chunks: its message
+// without the state, and the state received so far.
+type inboundSnapshot struct {
+	m     raftpb.Message
+	total uint64
+	data  []byte
+}
+
+func newHTTPTransport(id uint64, listen string, peers map[uint64]string, auth peerAuth) *httpTransport {
+	rt := http.DefaultTransport
+	if auth.sign != nil {
+		rt = auth.sign(rt)
+	}
+	verify := auth.verify
+	if verify == nil {
+		verify = func(h http.Handler) http.Handler { return h }
+	}
+	return &httpTransport{
+		id:     id,
+		listen: listen,
+		peers:  peers,
+		verify: verify,
+		// No client timeout: a snapshot may take a while, and a request
+		// stuck on a dead peer is cut off by the dial and header timeouts.
+		client: &http.Client{Transport: rt},
+	}
+}
+
+func (t *httpTransport) start(h handler) error {
+	ln, err := net.Listen("tcp", t.listen)
+	if err != nil {
+		return fmt.Errorf("peer listener: %w", err)
+	}
+	t.h = h
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	mux := http.NewServeMux()
+	mux.Handle("POST "+PathMessage, t.verify(http.HandlerFunc(t.serveMessages)))
+	mux.Handle("POST "+PathSnapshot, t.verify(http.HandlerFunc(t.serveSnapshot)))
+	t.snaps = map[uint64]*inboundSnapshot{}
+	t.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = t.srv.Serve(ln) }()
+
+	t.queues = make(map[uint64]chan raftpb.Message, len(t.peers))
+	for id, addr := range t.peers {
+		if id == t.id {
+			continue
+		}
+		q := make(chan raftpb.Message, peerQueueLen)
+		t.queues[id] = q
+		t.workers.Go(func() { t.run(id, strings.TrimSuffix(addr, "/"), q) })
+	}
+	return nil
+}
+
+func (t *httpTransport) stop() {
+	t.cancel()
+	t.workers.Wait()
+	_ = t.srv.Close()
+}
+
+func (t *httpTransport) send(msgs []raftpb.Message) {
+	for _, m := range msgs {
+		q, ok := t.queues[m.To]
+		if !ok {
+			continue
+		}
+		select {
+		case q <- m:
+		default:
+			t.failed(m)
+		}
+	}
+}
+
+// failed reports an undelivered message so Raft probes the peer again (and
+// retries a snapshot) instead of waiting on a response that will not come.
+func (t *httpTransport) failed(m raftpb.Message) {
+	t.h.reportUnreachable(m.To)
+	if m.Type == raftpb.MsgSnap {
+		t.h.reportSnapshot(m.To, etcdraft.SnapshotFailure)
+	}
+}
+
+// run delivers q to the peer at base until the transport stops. Snapshots
+// are sent after the batch they were drained with.
+func (t *httpTransport) run(to uint64, base string, q chan raftpb.Message) {
+	batch := make([]raftpb.Message, 0, peerBatch)
+	var snaps []raftpb.Message
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case m := <-q:
+			batch = append(batch[:0], m)
+		}
+		size := batch[0].Size()
+	drain:
+		for len(batch) < peerBatch && size < peerBatchBytes {
+			select {
+			case m := <-q:
+				batch = append(batch, m)
+				size += m.Size()
+			default:
+				break drain
+			}
+		}
+		msgs := batch[:0]
+		snaps = snaps[:0]
+		for _, m := range batch {
+			if m.Type == raftpb.MsgSnap {
+				snaps = append(snaps, m)
+			} else {
+				msgs = append(msgs, m)
+			}
+		}
+		if len(msgs) > 0 {
+			if err := t.post(base+PathMessage, msgs); err != nil {
+				t.h.reportUnreachable(to)
+			}
+		}
+		for _, m := range snaps {
+			status := etcdraft.SnapshotFinish
+			if err := t.postSnapshot(base+PathSnapshot, m); err != nil {
+				t.h.reportUnreachable(to)
+				status = etcdraft.SnapshotFailure
+			}
+			t.h.reportSnapshot(to, status)
+		}
+	}
+}
+
+func (t *httpTransport) post(url string, batch []raftpb.Message) error {
+	var body bytes.Buffer
+	for i := range batch {
+		raw, err := batch[i].Marshal()
+		if err != nil {
+			return err
+		}
+		body.Write(binary.AppendUvarint(nil, uint64(len(raw))))
+		body.Write(raw)
+	}
+	return t.do(url, &body)
+}
+
+// postSnapshot sends a snapshot message as chunks of up to snapshotChunk
+// bytes of its state. Each chunk carries the message without the state, the
+// chunk's offset and the state's total size, then the chunk itself.
+func (t *httpTransport) postSnapshot(url string, m raftpb.Message) error {
+	data := m.Snapshot.Data
+	snap := *m.Snapshot
+	snap.Data = nil
+	m.Snapshot = &snap
+	head, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	for off := 0; ; {
+		end := min(off+snapshotChunk, len(data))
+		var body bytes.Buffer
+		body.Write(binary.AppendUvarint(nil, uint64(len(head))))
+		body.Write(head)
+		body.Write(binary.AppendUvarint(nil, uint64(off)))
+		body.Write(binary.AppendUvarint(nil, uint64(len(data))))
+		body.Write(data[off:end])
+		if err := t.do(url, &body); err != nil {
+			return err
+		}
+		if off = end; off == len(data) {
+			return nil
+		}
+	}
+}
+
+func (t *httpTransport) do(url string, body *bytes.Buffer) error {
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("peer %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// readMessage reads one length-prefixed message; io.EOF means the body
+// holds no more.
+func readMessage(br *bufio.Reader) (raftpb.Message, error) {
+	var m raftpb.Message
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return m, err
+	}
+	if n > maxPeerMessage {
+		return m, fmt.Errorf("message of %d bytes exceeds %d", n, maxPeerMessage)
+	}
+	raw := make([]byte, n)
+	if _, err := io.ReadFull(br, raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return m, err
+	}
+	return m, m.Unmarshal(raw)
+}
+
+// serveMessages steps every message of a batch into the local node.
+func (t *httpTransport) serveMessages(w http.ResponseWriter, r *http.Request) {
+	br := bufio.NewReader(http.MaxBytesReader(w, r.Body, MaxPeerRequestBytes))
+	for {
+		m, err := readMessage(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := t.h.step(r.Context(), m); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveSnapshot takes one chunk of a snapshot (see postSnapshot) and steps
+// the snapshot into the local node once the last chunk is in.
+func (t *httpTransport) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	br := bufio.NewReader(http.MaxBytesReader(w, r.Body, MaxPeerRequestBytes))
+	m, err := readMessage(br)
+	if err == nil && (m.Type != raftpb.MsgSnap || m.Snapshot == nil) {
+		err = fmt.Errorf("%s is not a snapshot", m.Type)
+	}
+	var off, total uint64
+	if err == nil {
+		off, err = binary.ReadUvarint(br)
+	}
+	if err == nil {
+		total, err = binary.ReadUvarint(br)
+	}
+	var chunk []byte
+	if err == nil {
+		chunk, err = io.ReadAll(io.LimitReader(br, snapshotChunk+1))
+	}
+	if err == nil && len(chunk) > snapshotChunk {
+		err = fmt.Errorf("snapshot chunk exceeds %d bytes", snapshotChunk)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	full, done, err := t.assemble(m, off, total, chunk)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if done {
+		if err := t.h.step(r.Context(), full); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// assemble adds the chunk at off of m's snapshot to the one being received
+// from m.From, and returns the whole message once all total bytes are in. A
+// chunk at offset 0 starts the transfer over; any other chunk must continue
+// it, or the transfer is dropped and Raft retries it from the start.
+func (t *httpTransport) assemble(m raftpb.Message, off, total uint64, chunk []byte) (raftpb.Message, bool, error) {
+	t.snapMu.Lock()
+	defer t.snapMu.Unlock()
+	in := t.snaps[m.From]
+	if off == 0 {
+		in = &inboundSnapshot{m: m, total: total}
+		t.snaps[m.From] = in
+	}
+	if in == nil || in.m.Term != m.Term || in.m.Snapshot.Metadata.Index != m.Snapshot.Metadata.Index ||
+		in.total != total || uint64(len(in.data)) != off || off+uint64(len(chunk)) > total {
+		delete(t.snaps, m.From)
+		return raftpb.Message{}, false, fmt.Errorf("chunk at %d of %d does not continue the snapshot from %d", off, total, m.From)
+	}
+	in.data = append(in.data, chunk...)
+	if uint64(len(in.data)) < total {
+		return raftpb.Message{}, false, nil
+	}
+	delete(t.snaps, m.From)
+	full := in.m
+	snap := *full.Snapshot
+	snap.Data = in.data
+	full.Snapshot = &snap
+	return full, true, nil
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package raft
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	etcdraft "go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// recordingHandler collects what a transport delivers and reports.
+type recordingHandler struct {
+	stepped  chan raftpb.Message
+	snapshot chan etcdraft.SnapshotStatus
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{stepped: make(chan raftpb.Message, 16), snapshot: make(chan etcdraft.SnapshotStatus, 16)}
+}
+
+func (h *recordingHandler) step(_ context.Context, m raftpb.Message) error {
+	h.stepped <- m
+	return nil
+}
+
+func (h *recordingHandler) reportUnreachable(uint64) {}
+
+func (h *recordingHandler) reportSnapshot(_ uint64, status etcdraft.SnapshotStatus) {
+	h.snapshot <- status
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+	return addr
+}
+
+// TestHTTPTransportChunksSnapshots: a snapshot larger than one chunk reaches
+// the peer whole, through requests no larger than MaxPeerRequestBytes.
+func TestHTTPTransportChunksSnapshots(t *testing.T) {
+	addrs := map[uint64]string{1: freeAddr(t), 2: freeAddr(t)}
+	peers := map[uint64]string{1: "http://" + addrs[1], 2: "http://" + addrs[2]}
+	var largest atomic.Int64
+	auth := peerAuth{
+		verify: func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.ContentLength > largest.Load() {
+					largest.Store(r.ContentLength)
+				}
+				h.ServeHTTP(w, r)
+			})
+		},
+		sign: func(rt http.RoundTripper) http.RoundTripper { return rt },
+	}
+	handlers := map[uint64]*recordingHandler{}
+	transports := map[uint64]*httpTransport{}
+	for id := range peers {
+		transports[id] = newHTTPTransport(id, addrs[id], peers, auth)
+		handlers[id] = newRecordingHandler()
+		require.NoError(t, transports[id].start(handlers[id]))
+		t.Cleanup(transports[id].stop)
+	}
+
+	data := bytes.Repeat([]byte("state"), 3*snapshotChunk/5+7)
+	snap := raftpb.Message{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 3, Snapshot: &raftpb.Snapshot{
+		Data:     data,
+		Metadata: raftpb.SnapshotMetadata{Index: 40, Term: 3},
+	}}
+	heartbeat := raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 3}
+	transports[1].send([]raftpb.Message{snap, heartbeat})
+
+	got := map[raftpb.MessageType]raftpb.Message{}
+	for range 2 {
+		select {
+		case m := <-handlers[2].stepped:
+			got[m.Type] = m
+		case <-time.After(10 * time.Second):
+			t.Fatal("messages not delivered")
+		}
+	}
+	require.Contains(t, got, raftpb.MsgHeartbeat)
+	require.Equal(t, data, got[raftpb.MsgSnap].Snapshot.Data)
+	require.EqualValues(t, 40, got[raftpb.MsgSnap].Snapshot.Metadata.Index)
+	require.Equal(t, etcdraft.SnapshotFinish, <-handlers[1].snapshot)
+	require.Greater(t, largest.Load(), int64(0))
+	require.LessOrEqual(t, largest.Load(), int64(snapshotChunk+1<<10), "the snapshot went out in chunks")
+}
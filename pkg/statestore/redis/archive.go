@@ -0,0 +1,425 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var _ statestore.Archiver = (*Store)(nil)
+
+// Export implements statestore.Archiver. Redis has no snapshot across keys
+// in different hash slots, so each keyspace, stream and queue is read
+// atomically on its own, and the export is consistent per keyspace, stream
+// and queue rather than across them. Export from a quiesced store for a
+// consistent backup.
+func (s *Store) Export(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	scopes, err := s.scanTags(ctx, "kv", "ver", 3)
+	if err != nil {
+		return err
+	}
+	for _, names := range scopes {
+		sc := statestore.Scope{Namespace: names[0], Owner: names[1], Keyspace: names[2]}
+		if !f.MatchScope(sc) {
+			continue
+		}
+		if err := s.exportKeyspace(ctx, sc, fn); err != nil {
+			return err
+		}
+	}
+	streams, err := s.scanTags(ctx, "ev", "head", 1)
+	if err != nil {
+		return err
+	}
+	for _, names := range streams {
+		if !f.MatchStream(names[0]) {
+			continue
+		}
+		if err := s.exportStream(ctx, names[0], fn); err != nil {
+			return err
+		}
+	}
+	if !f.MatchQueues() {
+		return nil
+	}
+	queues, err := s.rdb.SMembers(ctx, s.queuesKey()).Result()
+	if err != nil {
+		return storeErr(err)
+	}
+	slices.Sort(queues)
+	for _, queue := range queues {
+		if err := s.exportQueue(ctx, queue, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanTags returns the names in the tags of kind that have a key named
+// suffix, each tag holding n names, sorted.
+func (s *Store) scanTags(ctx context.Context, kind, suffix string, n int) ([][]string, error) {
+	pattern := globEscape(s.prefix) + "{" + kind + ":*}:" + suffix
+	seen := map[string]bool{}
+	var out [][]string
+	iter := s.rdb.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		names, ok := parseTag(strings.TrimSuffix(strings.TrimPrefix(key, s.prefix), suffix), kind)
+		if !ok || len(names) != n {
+			continue
+		}
+		out = append(out, names)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, storeErr(err)
+	}
+	slices.SortFunc(out, slices.Compare)
+	return out, nil
+}
+
+// parseTag reverses tag for a key with the store prefix and the key name
+// after the tag removed: "{kind:len:name...}:".
+func parseTag(s, kind string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(s, "{"+kind)
+	if !ok {
+		return nil, false
+	}
+	var names []string
+	for {
+		if rest == "}:" {
+			return names, true
+		}
+		rest, ok = strings.CutPrefix(rest, ":")
+		if !ok {
+			return nil, false
+		}
+		l, after, ok := strings.Cut(rest, ":")
+		n, err := strconv.Atoi(l)
+		if !ok || err != nil || n < 0 || n > len(after) {
+			return nil, false
+		}
+		names = append(names, after[:n])
+		rest = after[n:]
+	}
+}
+
+// globEscape escapes the SCAN MATCH metacharacters in s.
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
+
+func (s *Store) exportKeyspace(ctx context.Context, sc statestore.Scope, fn func(statestore.ArchiveRecord) error) error {
+	tag := (&kvStore{s}).tag(sc)
+	var (
+		data, ver *goredis.MapStringStringCmd
+		ttl       *goredis.ZSliceCmd
+	)
+	_, err := s.rdb.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+		data = p.HGetAll(ctx, tag+"data")
+		ver = p.HGetAll(ctx, tag+"ver")
+		ttl = p.ZRangeWithScores(ctx, tag+"ttl", 0, -1)
+		return nil
+	})
+	if err != nil {
+		return storeErr(err)
+	}
+	expiry := map[string]int64{}
+	for _, z := range ttl.Val() {
+		key, _ := z.Member.(string)
+		expiry[key] = int64(z.Score)
+	}
+	now := nowMillis()
+	for _, key := range slices.Sorted(maps.Keys(ver.Val())) {
+		exp, expires := expiry[key]
+		if expires && exp <= now {
+			continue
+		}
+		rec := &statestore.ArchivedKey{Scope: sc, Key: key, Value: toBytes(data.Val()[key]), Version: toInt64(ver.Val()[key])}
+		if expires {
+			rec.ExpiresAt = time.UnixMilli(exp)
+		}
+		if err := fn(statestore.ArchiveRecord{KV: rec}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) exportStream(ctx context.Context, stream string, fn func(statestore.ArchiveRecord) error) error {
+	tag := s.tag("ev", stream)
+	var (
+		head *goredis.StringCmd
+		log  *goredis.XMessageSliceCmd
+	)
+	_, err := s.rdb.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+		head = p.Get(ctx, tag+"head")
+		log = p.XRange(ctx, tag+"log", "-", "+")
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return storeErr(err)
+	}
+	h, _ := head.Int64()
+	if err := fn(statestore.ArchiveRecord{Stream: &statestore.ArchivedStream{Stream: stream, Head: h}}); err != nil {
+		return err
+	}
+	for _, m := range log.Val() {
+		seq, _, _ := strings.Cut(m.ID, "-")
+		ev := &statestore.ArchivedEvent{Stream: stream, Payload: toBytes(m.Values["p"])}
+		ev.Seq, _ = strconv.ParseInt(seq, 10, 64)
+		ev.Type, _ = m.Values["t"].(string)
+		ev.At = time.UnixMilli(toInt64(m.Values["at"]))
+		if err := fn(statestore.ArchiveRecord{Event: ev}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ARGV: now, maxAttempts. It returns each queued, leased and dead message's
+// id followed by its seq, body, st, att, vis, exp, dd, grp, rsn, enq, died
+// and def fields.
+var queueExport = goredis.NewScript(luaQueueLib + `
+local out = {}
+for _, set in ipairs({'ready', 'delayed', 'leased', 'dead'}) do
+  for _, id in ipairs(redis.call('ZRANGE', base .. set, 0, -1)) do
+    local m = redis.call('HMGET', mkey(id), 'seq', 'body', 'st', 'att', 'vis', 'exp', 'dd', 'grp', 'rsn', 'enq', 'died', 'def')
+    out[#out + 1] = id
+    for i = 1, 12 do out[#out + 1] = m[i] or '' end
+  end
+end
+return out
+`)
+
+func (s *Store) exportQueue(ctx context.Context, queue string, fn func(statestore.ArchiveRecord) error) error {
+	res, err := (&queueStore{s}).run(ctx, queueExport, queue).Slice()
+	if err != nil {
+		return storeErr(err)
+	}
+	type entry struct {
+		seq int64
+		msg *statestore.ArchivedMessage
+	}
+	var msgs []entry
+	for i := 0; i+12 < len(res); i += 13 {
+		f := func(j int) any { return res[i+1+j] }
+		m := &statestore.ArchivedMessage{
+			Queue:      queue,
+			Body:       toBytes(f(1)),
+			Attempts:   int(toInt64(f(3))),
+			EnqueuedAt: time.UnixMilli(toInt64(f(9))),
+		}
+		m.ID, _ = res[i].(string)
+		switch st, _ := f(2).(string); st {
+		case "queued":
+			m.State = statestore.ArchivedQueued
+			m.VisibleAt = time.UnixMilli(toInt64(f(4)))
+			m.Deferred = toInt64(f(11)) == 1
+		case "leased":
+			m.State = statestore.ArchivedQueued
+			m.VisibleAt = time.UnixMilli(toInt64(f(5)))
+			m.Attempts--
+		case "dead":
+			m.State = statestore.ArchivedDead
+			m.Reason, _ = f(8).(string)
+			if died := toInt64(f(10)); died != 0 {
+				m.DiedAt = time.UnixMilli(died)
+			}
+		}
+		if m.State == statestore.ArchivedQueued {
+			m.DedupKey, _ = f(6).(string)
+			m.Group, _ = f(7).(string)
+		}
+		msgs = append(msgs, entry{toInt64(f(0)), m})
+	}
+	slices.SortFunc(msgs, func(a, b entry) int { return cmp.Compare(a.seq, b.seq) })
+	for _, e := range msgs {
+		if err := fn(statestore.ArchiveRecord{Message: e.msg}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ARGV: now, then each key's name, value, version and expiresAt (empty for
+// none).
+var kvImport = goredis.NewScript(luaKVLib + `
+for i = 2, #ARGV, 4 do
+  local key = ARGV[i]
+  redis.call('HSET', data, key, ARGV[i + 1])
+  redis.call('HSET', ver, key, ARGV[i + 2])
+  if ARGV[i + 3] ~= '' then
+    redis.call('ZADD', ttl, ARGV[i + 3], key)
+  else
+    redis.call('ZREM', ttl, key)
+  end
+  redis.call('ZADD', idx, 0, key)
+end
+return 0
+`)
+
+// ARGV: the head to reset the stream to (empty to append to it as it is),
+// then each event's seq, type, payload and at.
+var eventImport = goredis.NewScript(`
+local log, headKey = KEYS[1] .. 'log', KEYS[1] .. 'head'
+if ARGV[1] ~= '' then
+  redis.call('DEL', log)
+  redis.call('SET', headKey, ARGV[1])
+end
+for i = 2, #ARGV, 4 do
+  redis.call('XADD', log, ARGV[i] .. '-0', 't', ARGV[i + 1], 'p', ARGV[i + 2], 'at', ARGV[i + 3])
+end
+return 0
+`)
+
+// ARGV: now, maxAttempts, queue, then each message's body, state, attempts,
+// visible-at, enqueued-at, dedup key, group, deferred (1 or 0), reason and
+// died-at. Each is counted as enqueued, so the conservation accounting holds.
+var queueImport = goredis.NewScript(luaQueueLib + `
+local n = 0
+for i = 4, #ARGV, 10 do
+  local seq = redis.call('INCR', base .. 'seq')
+  local id = ARGV[3] .. '/' .. seq
+  local k = mkey(id)
+  redis.call('HSET', k, 'body', ARGV[i], 'seq', seq, 'att', ARGV[i + 2], 'ep', 0, 'enq', ARGV[i + 4])
+  if ARGV[i + 1] == 'dead' then
+    redis.call('HSET', k, 'st', 'dead', 'rsn', ARGV[i + 8], 'died', ARGV[i + 9])
+    redis.call('ZADD', base .. 'dead', 0, id)
+  else
+    if ARGV[i + 5] ~= '' then
+      redis.call('HSET', k, 'dd', ARGV[i + 5])
+      redis.call('HSET', base .. 'dedup', ARGV[i + 5], id)
+    end
+    if ARGV[i + 6] ~= '' then
+      redis.call('HSET', k, 'grp', ARGV[i + 6])
+      redis.call('ZADD', base .. 'g:' .. ARGV[i + 6], seq, id)
+    end
+    makeQueued(id, k, ARGV[i + 3])
+    if ARGV[i + 7] == '1' then
+      redis.call('HSET', k, 'def', 1)
+      redis.call('ZADD', base .. 'deferred', ARGV[i + 3], id)
+    end
+  end
+  n = n + 1
+end
+redis.call('HINCRBY', base .. 'stats', 'enqueued', n)
+return n
+`)
+
+// importGroup is a run of consecutive records with one hash tag, which one
+// script imports.
+type importGroup struct {
+	script *goredis.Script
+	tag    string
+	queue  string
+	args   []any
+}
+
+// Import implements statestore.Archiver. The records are validated first,
+// so a malformed batch imports nothing; then each run of consecutive records
+// of one keyspace, stream or queue is one script, atomic on its own.
+func (s *Store) Import(ctx context.Context, recs []statestore.ArchiveRecord) error {
+	err := statestore.ValidateImport(recs, func(stream string) (int64, int64, error) {
+		tag := s.tag("ev", stream)
+		head, err := s.rdb.Get(ctx, tag+"head").Int64()
+		if errors.Is(err, goredis.Nil) {
+			return 0, 0, nil
+		}
+		if err != nil {
+			return 0, 0, storeErr(err)
+		}
+		last, err := s.rdb.XRevRangeN(ctx, tag+"log", "+", "-", 1).Result()
+		if err != nil {
+			return 0, 0, storeErr(err)
+		}
+		var seq int64
+		if len(last) > 0 {
+			n, _, _ := strings.Cut(last[0].ID, "-")
+			seq, _ = strconv.ParseInt(n, 10, 64)
+		}
+		return seq, head, nil
+	})
+	if err != nil {
+		return err
+	}
+	var groups []*importGroup
+	group := func(script *goredis.Script, tag string, head ...any) *importGroup {
+		if n := len(groups); n > 0 && groups[n-1].script == script && groups[n-1].tag == tag && len(head) == 0 {
+			return groups[n-1]
+		}
+		g := &importGroup{script: script, tag: tag, args: head}
+		groups = append(groups, g)
+		return g
+	}
+	for _, r := range recs {
+		switch {
+		case r.KV != nil:
+			g := group(kvImport, (&kvStore{s}).tag(r.KV.Scope))
+			if len(g.args) == 0 {
+				g.args = append(g.args, nowMillis())
+			}
+			exp := ""
+			if !r.KV.ExpiresAt.IsZero() {
+				exp = itoa(r.KV.ExpiresAt.UnixMilli())
+			}
+			g.args = append(g.args, r.KV.Key, r.KV.Value, r.KV.Version, exp)
+		case r.Stream != nil:
+			group(eventImport, s.tag("ev", r.Stream.Stream), itoa(r.Stream.Head))
+		case r.Event != nil:
+			g := group(eventImport, s.tag("ev", r.Event.Stream))
+			if len(g.args) == 0 {
+				g.args = append(g.args, "")
+			}
+			g.args = append(g.args, r.Event.Seq, r.Event.Type, r.Event.Payload, millis(r.Event.At))
+		case r.Message != nil:
+			m := r.Message
+			g := group(queueImport, (&queueStore{s}).tag(m.Queue))
+			if len(g.args) == 0 {
+				g.queue = m.Queue
+				g.args = append(g.args, nowMillis(), s.maxAttempts, m.Queue)
+			}
+			deferred := 0
+			if m.Deferred {
+				deferred = 1
+			}
+			g.args = append(g.args, m.Body, string(m.State), m.Attempts, millis(m.VisibleAt), millis(m.EnqueuedAt),
+				m.DedupKey, m.Group, deferred, m.Reason, millis(m.DiedAt))
+		}
+	}
+	for _, g := range groups {
+		if err := s.run(ctx, g.script, g.tag, g.args...).Err(); err != nil {
+			return storeErr(err)
+		}
+		if g.queue != "" {
+			if err := s.rdb.SAdd(ctx, s.queuesKey(), g.queue).Err(); err != nil {
+				return storeErr(err)
+			}
+		}
+	}
+	return nil
+}
+
+// millis is t in unix milliseconds, 0 for the zero time.
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/proto"
+	"github.com/alicebob/miniredis/v2/server"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statestore/redis"
+	"github.com/fission/fission/pkg/statestore/statestoretest"
+)
+
+// newFactory starts an in-process Redis-compatible server (miniredis) for one
+// top-level test and returns a factory of stores on it, each under its own key
+// prefix so every subtest starts empty. Stores dial the server over net.Pipe
+// rather than TCP: a goroutine blocked on a pipe is durably blocked, so the
+// virtual-time timing suite runs against this driver too — the driver takes
+// its clock from the client, never the server.
+func newFactory(t *testing.T) statestoretest.Factory {
+	srv := miniredis.RunT(t)
+	var n atomic.Int64
+	return func(t *testing.T) statestore.Capabilities {
+		rdb := goredis.NewClient(&goredis.Options{
+			Dialer: func(context.Context, string, string) (net.Conn, error) {
+				client, server := net.Pipe()
+				go servePipe(srv.Server(), server)
+				return client, nil
+			},
+		})
+		caps, err := redis.Open(t.Context(), rdb, redis.WithKeyPrefix("t"+strconv.FormatInt(n.Add(1), 10)+":"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = caps.Close() })
+		return caps
+	}
+}
+
+// servePipe dispatches the commands read from conn to srv until conn closes.
+// It stands in for srv.ServeConn, whose connection bookkeeping cannot be shared
+// between a synctest bubble and the test that started the server outside it.
+func servePipe(srv *server.Server, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	peer := server.NewPeer(bufio.NewWriter(conn))
+	for !peer.Closed() {
+		raw, err := proto.Read(r)
+		if err != nil {
+			return
+		}
+		args, err := proto.ReadStrings(raw)
+		if err != nil {
+			return
+		}
+		srv.Dispatch(peer, args)
+		peer.Flush()
+	}
+}
+
+// The Redis driver must pass the same conformance suite as the memory driver
+// (the executable spec), the timing suite included.
+func TestConformance_Redis(t *testing.T) {
+	factory := newFactory(t)
+	statestoretest.RunConformance(t, factory)
+	statestoretest.RunTimingConformance(t, factory)
+}
+
+func TestConformance_Redis_Linearizability(t *testing.T) {
+	statestoretest.RunKVLinearizability(t, newFactory(t))
+}
+
+// TestOpen_Redis opens the driver the way a component does, by name and URL.
+func TestOpen_Redis(t *testing.T) {
+	srv := miniredis.RunT(t)
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "redis", DSN: "redis://" + srv.Addr()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	require.NoError(t, caps.Ping(t.Context()))
+
+	_, err = statestore.Open(t.Context(), statestore.Config{Driver: "redis"})
+	require.Error(t, err, "an empty DSN is rejected")
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type eventLog struct{ s *Store }
+
+// A stream is a Redis Stream (log) whose entry ids are "<seq>-0", plus the
+// head sequence kept apart in head so Trim never moves the append point.
+//
+// ARGV: now, expectedSeq (-1 for AppendAny), then a type and payload per
+// event. It returns {applied, head}; a lost CAS appends nothing and reports
+// the current head.
+var eventAppend = goredis.NewScript(`
+local log, headKey = KEYS[1] .. 'log', KEYS[1] .. 'head'
+local head = tonumber(redis.call('GET', headKey) or '0')
+if ARGV[2] ~= '-1' and tonumber(ARGV[2]) ~= head then return {0, head} end
+for i = 3, #ARGV, 2 do
+  head = head + 1
+  redis.call('XADD', log, head .. '-0', 't', ARGV[i], 'p', ARGV[i + 1], 'at', ARGV[1])
+end
+redis.call('SET', headKey, head)
+return {1, head}
+`)
+
+func (e *eventLog) tag(stream string) string { return e.s.tag("ev", stream) }
+
+// Append implements statestore.EventLog with optimistic concurrency on the
+// head sequence (invariant E1). The head check and the appends are one
+// script, so exactly one of several racing CAS appenders wins, and
+// expectedSeq = AppendAny appenders all land at disjoint, gapless seqs.
+func (e *eventLog) Append(ctx context.Context, stream string, expectedSeq int64, events []statestore.Event) (int64, error) {
+	args := make([]any, 0, 2+2*len(events))
+	args = append(args, nowMillis(), expectedSeq)
+	for _, ev := range events {
+		args = append(args, ev.Type, ev.Payload)
+	}
+	res, err := e.s.run(ctx, eventAppend, e.tag(stream), args...).Int64Slice()
+	if err != nil {
+		return 0, storeErr(err)
+	}
+	if res[0] == 0 {
+		return res[1], statestore.ErrVersionConflict
+	}
+	return res[1], nil
+}
+
+// Read implements statestore.EventLog: up to limit events with seq > fromSeq,
+// in order. limit <= 0 returns all matching events.
+func (e *eventLog) Read(ctx context.Context, stream string, fromSeq int64, limit int) ([]statestore.Event, error) {
+	key, start := e.tag(stream)+"log", strconv.FormatInt(max(fromSeq, 0)+1, 10)+"-0"
+	var (
+		msgs []goredis.XMessage
+		err  error
+	)
+	if limit > 0 {
+		msgs, err = e.s.rdb.XRangeN(ctx, key, start, "+", int64(limit)).Result()
+	} else {
+		msgs, err = e.s.rdb.XRange(ctx, key, start, "+").Result()
+	}
+	if err != nil {
+		return nil, storeErr(err)
+	}
+	out := make([]statestore.Event, 0, len(msgs))
+	for _, m := range msgs {
+		seq, _, _ := strings.Cut(m.ID, "-")
+		ev := statestore.Event{Payload: toBytes(m.Values["p"])}
+		ev.Seq, _ = strconv.ParseInt(seq, 10, 64)
+		ev.Type, _ = m.Values["t"].(string)
+		ev.At = time.UnixMilli(toInt64(m.Values["at"]))
+		out = append(out, ev)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// Head implements statestore.EventLog: the stream's current head sequence, 0
+// for an absent stream, with no side effects.
+func (e *eventLog) Head(ctx context.Context, stream string) (int64, error) {
+	head, err := e.s.rdb.Get(ctx, e.tag(stream)+"head").Int64()
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+	return head, storeErr(err)
+}
+
+// Trim implements statestore.EventLog: drop events with seq < belowSeq.
+func (e *eventLog) Trim(ctx context.Context, stream string, belowSeq int64) error {
+	if belowSeq <= 1 {
+		return nil
+	}
+	return storeErr(e.s.rdb.XTrimMinID(ctx, e.tag(stream)+"log", strconv.FormatInt(belowSeq, 10)+"-0").Err())
+}
@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"errors"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type kvStore struct{ s *Store }
+
+// A keyspace is four keys under its tag: data and ver hash each key to its
+// value and version, ttl scores the keys that expire by their expiry, and idx
+// is the lexicographic index List pages through (all members score 0, so
+// ZRANGEBYLEX orders them byte-exactly). A key is live when it has a version
+// and no elapsed expiry; expired keys are swept by later writes to the
+// keyspace.
+const luaKVLib = `
+local data, ver, ttl, idx = KEYS[1] .. 'data', KEYS[1] .. 'ver', KEYS[1] .. 'ttl', KEYS[1] .. 'idx'
+local now = tonumber(ARGV[1])
+local function live(key)
+  local v = redis.call('HGET', ver, key)
+  if not v then return nil end
+  local exp = redis.call('ZSCORE', ttl, key)
+  if exp and tonumber(exp) <= now then return nil end
+  return tonumber(v)
+end
+local function sweep()
+  for _, key in ipairs(redis.call('ZRANGEBYSCORE', ttl, '-inf', now, 'LIMIT', 0, 64)) do
+    redis.call('HDEL', data, key)
+    redis.call('HDEL', ver, key)
+    redis.call('ZREM', ttl, key)
+    redis.call('ZREM', idx, key)
+  end
+end
+`
+
+// Script results for the conditional writes.
+const (
+	kvOK       = 0
+	kvConflict = 1
+	kvQuota    = 2
+)
+
+// ARGV: now, key.
+var kvGet = goredis.NewScript(luaKVLib + `
+local v = live(ARGV[2])
+if not v then return false end
+return {redis.call('HGET', data, ARGV[2]), v}
+`)
+
+// ARGV: now, key, value, ifVersion (empty for none), expiresAt (empty for none),
+// maxKeys (<= 0 for no budget). The CAS check precedes the budget check,
+// so a write that could never apply is a conflict, not a quota rejection.
+var kvSet = goredis.NewScript(luaKVLib + `
+sweep()
+local key = ARGV[2]
+local cur = live(key)
+if ARGV[4] ~= '' and tonumber(ARGV[4]) ~= (cur or 0) then return 1 end
+local maxKeys = tonumber(ARGV[6])
+if not cur and maxKeys > 0 then
+  -- An expired key still stored is in both terms, so this counts live keys.
+  if redis.call('HLEN', ver) - redis.call('ZCOUNT', ttl, '-inf', now) >= maxKeys then return 2 end
+end
+redis.call('HSET', data, key, ARGV[3])
+redis.call('HSET', ver, key, (cur or 0) + 1)
+if ARGV[5] ~= '' then
+  redis.call('ZADD', ttl, ARGV[5], key)
+else
+  redis.call('ZREM', ttl, key)
+end
+redis.call('ZADD', idx, 0, key)
+return 0
+`)
+
+// ARGV: now, key, ifVersion (<= 0 for unconditional).
+var kvDelete = goredis.NewScript(luaKVLib + `
+sweep()
+local key = ARGV[2]
+local ifVersion = tonumber(ARGV[3])
+if ifVersion > 0 and live(key) ~= ifVersion then return 1 end
+redis.call('HDEL', data, key)
+redis.call('HDEL', ver, key)
+redis.call('ZREM', ttl, key)
+redis.call('ZREM', idx, key)
+return 0
+`)
+
+// ARGV: now, maxKeys (<= 0 for no budget), the number of compares, each
+// compare's key and version, then each op's type, key, value and expiresAt
+// (empty for none). It returns the result code followed by each op's new
+// version. The compares precede the budget check, as in kvSet.
+var kvTxn = goredis.NewScript(luaKVLib + `
+sweep()
+local maxKeys = tonumber(ARGV[2])
+local i = 4
+for _ = 1, tonumber(ARGV[3]) do
+  if (live(ARGV[i]) or 0) ~= tonumber(ARGV[i + 1]) then return {1} end
+  i = i + 2
+end
+local first = i
+if maxKeys > 0 then
+  local delta, creates = 0, false
+  for j = first, #ARGV, 4 do
+    local exists = live(ARGV[j + 1]) ~= nil
+    if ARGV[j] == 'put' and not exists then
+      delta, creates = delta + 1, true
+    elseif ARGV[j] == 'delete' and exists then
+      delta = delta - 1
+    end
+  end
+  if creates and redis.call('HLEN', ver) - redis.call('ZCOUNT', ttl, '-inf', now) + delta > maxKeys then return {2} end
+end
+local out = {0}
+for j = first, #ARGV, 4 do
+  local key = ARGV[j + 1]
+  if ARGV[j] == 'delete' then
+    redis.call('HDEL', data, key)
+    redis.call('HDEL', ver, key)
+    redis.call('ZREM', ttl, key)
+    redis.call('ZREM', idx, key)
+    out[#out + 1] = 0
+  else
+    local v = (live(key) or 0) + 1
+    redis.call('HSET', data, key, ARGV[j + 2])
+    redis.call('HSET', ver, key, v)
+    if ARGV[j + 3] ~= '' then
+      redis.call('ZADD', ttl, ARGV[j + 3], key)
+    else
+      redis.call('ZREM', ttl, key)
+    end
+    redis.call('ZADD', idx, 0, key)
+    out[#out + 1] = v
+  end
+end
+return out
+`)
+
+// ARGV: now, ZRANGEBYLEX min, ZRANGEBYLEX max, limit (<= 0 for all). It
+// returns up to limit+1 live keys, the extra one telling the caller a further
+// page exists.
+var kvList = goredis.NewScript(luaKVLib + `
+local limit = tonumber(ARGV[4])
+local out = {}
+local offset = 0
+while true do
+  local batch = redis.call('ZRANGEBYLEX', idx, ARGV[2], ARGV[3], 'LIMIT', offset, 256)
+  for _, key in ipairs(batch) do
+    local exp = redis.call('ZSCORE', ttl, key)
+    if not exp or tonumber(exp) > now then
+      out[#out + 1] = key
+      if limit > 0 and #out > limit then return out end
+    end
+  end
+  if #batch < 256 then return out end
+  offset = offset + 256
+end
+`)
+
+func (k *kvStore) tag(sc statestore.Scope) string {
+	return k.s.tag("kv", sc.Namespace, sc.Owner, sc.Keyspace)
+}
+
+// Get implements statestore.KVStore.
+func (k *kvStore) Get(ctx context.Context, sc statestore.Scope, key string) (statestore.Value, error) {
+	res, err := k.s.run(ctx, kvGet, k.tag(sc), nowMillis(), key).Slice()
+	if errors.Is(err, goredis.Nil) {
+		return statestore.Value{}, statestore.ErrNotFound
+	}
+	if err != nil {
+		return statestore.Value{}, storeErr(err)
+	}
+	return statestore.Value{Data: toBytes(res[0]), Version: toInt64(res[1])}, nil
+}
+
+// Set implements statestore.KVStore with the CAS-on-version semantics (an
+// absent or expired key is version 0).
+func (k *kvStore) Set(ctx context.Context, sc statestore.Scope, key string, val []byte, o statestore.SetOptions) error {
+	return k.set(ctx, sc, key, val, o, 0)
+}
+
+// SetCounted implements statestore.CountedKV: the live-key count and the
+// write are one script, so racing creators can never exceed maxKeys
+// (RFC-0023 S3).
+func (k *kvStore) SetCounted(ctx context.Context, sc statestore.Scope, key string, val []byte, o statestore.SetOptions, maxKeys int64) error {
+	return k.set(ctx, sc, key, val, o, maxKeys)
+}
+
+func (k *kvStore) set(ctx context.Context, sc statestore.Scope, key string, val []byte, o statestore.SetOptions, maxKeys int64) error {
+	now := nowMillis()
+	var ifVersion, expiresAt string
+	if o.IfVersion != nil {
+		ifVersion = itoa(*o.IfVersion)
+	}
+	if o.TTL > 0 {
+		expiresAt = itoa(now + o.TTL.Milliseconds())
+	}
+	res, err := k.s.run(ctx, kvSet, k.tag(sc), now, key, val, ifVersion, expiresAt, maxKeys).Int()
+	return kvResult(res, err)
+}
+
+// Delete implements statestore.KVStore. ifVersion <= 0 deletes
+// unconditionally (idempotent for an absent key); a positive ifVersion is a
+// CAS delete of a live key at exactly that version.
+func (k *kvStore) Delete(ctx context.Context, sc statestore.Scope, key string, ifVersion int64) error {
+	res, err := k.s.run(ctx, kvDelete, k.tag(sc), nowMillis(), key, ifVersion).Int()
+	return kvResult(res, err)
+}
+
+// Txn implements statestore.TxnKV: the compares, the budget check and the
+// writes are one script.
+func (k *kvStore) Txn(ctx context.Context, sc statestore.Scope, t statestore.Txn, maxKeys int64) ([]int64, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	now := nowMillis()
+	args := []any{now, maxKeys, len(t.Compares)}
+	for _, c := range t.Compares {
+		args = append(args, c.Key, c.Version)
+	}
+	for _, op := range t.Ops {
+		var expiresAt string
+		if op.TTL > 0 {
+			expiresAt = itoa(now + op.TTL.Milliseconds())
+		}
+		args = append(args, string(op.Type), op.Key, op.Value, expiresAt)
+	}
+	res, err := k.s.run(ctx, kvTxn, k.tag(sc), args...).Int64Slice()
+	if err != nil {
+		return nil, storeErr(err)
+	}
+	if err := kvResult(int(res[0]), nil); err != nil {
+		return nil, err
+	}
+	return res[1:], nil
+}
+
+// kvResult maps a conditional write's script result to its error.
+func kvResult(res int, err error) error {
+	switch {
+	case err != nil:
+		return storeErr(err)
+	case res == kvConflict:
+		return statestore.ErrVersionConflict
+	case res == kvQuota:
+		return statestore.ErrQuotaExceeded
+	}
+	return nil
+}
+
+// List implements statestore.KVStore: byte-ordered keys under prefix,
+// paginated by page.Token (the last key returned), excluding expired keys.
+// page.Limit <= 0 returns all matching keys.
+func (k *kvStore) List(ctx context.Context, sc statestore.Scope, prefix string, page statestore.Page) (statestore.KeyPage, error) {
+	lo, hi := lexRange(prefix, page.Token)
+	keys, err := k.s.run(ctx, kvList, k.tag(sc), nowMillis(), lo, hi, page.Limit).StringSlice()
+	if err != nil {
+		return statestore.KeyPage{}, storeErr(err)
+	}
+	if page.Limit > 0 && len(keys) > page.Limit {
+		return statestore.KeyPage{Keys: keys[:page.Limit], Next: keys[page.Limit-1]}, nil
+	}
+	return statestore.KeyPage{Keys: keys}, nil
+}
+
+// lexRange returns the ZRANGEBYLEX bounds of the keys under prefix that sort
+// after token.
+func lexRange(prefix, token string) (lo, hi string) {
+	lo = "[" + prefix
+	if token != "" && token >= prefix {
+		lo = "(" + token
+	}
+	hi = "+"
+	// The first string after every key with the prefix: drop trailing 0xff
+	// bytes and increment the last byte left.
+	end := []byte(prefix)
+	for len(end) > 0 && end[len(end)-1] == 0xff {
+		end = end[:len(end)-1]
+	}
+	if len(end) > 0 {
+		end[len(end)-1]++
+		hi = "(" + string(end)
+	}
+	return lo, hi
+}
@@ -0,0 +1,513 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+type queueStore struct{ s *Store }
+
+// A queue's keys, under its tag:
+//
+//	seq          counter minting message ids "<queue>/<seq>"
+//	m:<seq>      hash: one message (body, state st, seq, attempts att, lease
+//	             epoch ep, visible-at vis, lease expiry exp, dedup key dd,
+//	             group grp, dead reason rsn, enqueued-at enq, died-at died,
+//	             deferred def)
+//	ready        zset of visible queued ids by seq, the lease order
+//	delayed      zset of not-yet-visible queued ids by visible-at
+//	deferred     zset of the delayed ids a Defer returned, by visible-at
+//	leased       zset of leased ids by lease expiry
+//	dead         zset of dead-lettered ids, score 0 so they page by id
+//	dedup        hash: dedup key -> id of its unsettled message
+//	g:<group>    zset of a group's unsettled (queued or leased) ids by seq;
+//	             the first is the group's head
+//	gleased      hash: group -> count of its leased messages
+//	stats        hash: enqueued, acked and expirations counters
+//
+// An acked message's hash is deleted; the acked counter keeps the
+// conservation accounting (invariant T1).
+//
+// ARGV[1] is now and ARGV[2] the attempt budget in every queue script.
+const luaQueueLib = `
+local base = KEYS[1]
+local now = tonumber(ARGV[1])
+local maxAttempts = tonumber(ARGV[2])
+local function mkey(id) return base .. 'm:' .. string.match(id, '%d+$') end
+local function makeQueued(id, k, vis)
+  redis.call('HSET', k, 'st', 'queued', 'vis', vis)
+  if tonumber(vis) <= now then
+    redis.call('ZADD', base .. 'ready', redis.call('HGET', k, 'seq'), id)
+  else
+    redis.call('ZADD', base .. 'delayed', vis, id)
+  end
+end
+local function settled(id, k)
+  local f = redis.call('HMGET', k, 'dd', 'grp')
+  if f[1] and redis.call('HGET', base .. 'dedup', f[1]) == id then
+    redis.call('HDEL', base .. 'dedup', f[1])
+  end
+  redis.call('HDEL', k, 'dd')
+  if f[2] then redis.call('ZREM', base .. 'g:' .. f[2], id) end
+end
+local function makeDead(id, k, reason)
+  settled(id, k)
+  redis.call('HSET', k, 'st', 'dead', 'rsn', reason, 'died', ARGV[1])
+  redis.call('ZADD', base .. 'dead', 0, id)
+end
+local function unlease(id, k)
+  redis.call('ZREM', base .. 'leased', id)
+  local grp = redis.call('HGET', k, 'grp')
+  if grp and redis.call('HINCRBY', base .. 'gleased', grp, -1) <= 0 then
+    redis.call('HDEL', base .. 'gleased', grp)
+  end
+end
+-- reap dead-letters leases whose budget is spent (exhausted purely by expiry,
+-- SQS maxReceiveCount) and requeues the rest, then makes the delayed messages
+-- that are due visible.
+local function reap(reason)
+  for _, id in ipairs(redis.call('ZRANGEBYSCORE', base .. 'leased', '-inf', now)) do
+    local k = mkey(id)
+    unlease(id, k)
+    redis.call('HINCRBY', base .. 'stats', 'expirations', 1)
+    if tonumber(redis.call('HGET', k, 'att')) >= maxAttempts then
+      makeDead(id, k, reason)
+    else
+      makeQueued(id, k, ARGV[1])
+    end
+  end
+  for _, id in ipairs(redis.call('ZRANGEBYSCORE', base .. 'delayed', '-inf', now)) do
+    redis.call('ZREM', base .. 'delayed', id)
+    redis.call('ZADD', base .. 'ready', redis.call('HGET', mkey(id), 'seq'), id)
+  end
+end
+`
+
+// ARGV: now, maxAttempts, queue, body, visible-at, dedup key, group. It
+// returns the message id and its seq, 0 when a dedup key collapsed the
+// enqueue onto an unsettled message.
+var queueEnqueue = goredis.NewScript(luaQueueLib + `
+if ARGV[6] ~= '' then
+  local id = redis.call('HGET', base .. 'dedup', ARGV[6])
+  if id then return {id, 0} end
+end
+local seq = redis.call('INCR', base .. 'seq')
+local id = ARGV[3] .. '/' .. seq
+local k = mkey(id)
+redis.call('HSET', k, 'body', ARGV[4], 'seq', seq, 'att', 0, 'ep', 0, 'enq', ARGV[1])
+if ARGV[6] ~= '' then
+  redis.call('HSET', k, 'dd', ARGV[6])
+  redis.call('HSET', base .. 'dedup', ARGV[6], id)
+end
+if ARGV[7] ~= '' then
+  redis.call('HSET', k, 'grp', ARGV[7])
+  redis.call('ZADD', base .. 'g:' .. ARGV[7], seq, id)
+end
+redis.call('HINCRBY', base .. 'stats', 'enqueued', 1)
+makeQueued(id, k, ARGV[5])
+return {id, seq}
+`)
+
+// ARGV: now, maxAttempts, lease-expiry reason, n, lease expiry, grouped (1
+// or 0). It returns id, body, epoch and attempts for each leased message.
+//
+// A grouped lease takes a grouped message only while it heads its group and
+// nothing of the group is leased.
+var queueLease = goredis.NewScript(luaQueueLib + `
+reap(ARGV[3])
+local n, grouped = tonumber(ARGV[4]), ARGV[6] == '1'
+local picked = {}
+local offset = 0
+while #picked < n do
+  local batch = redis.call('ZRANGE', base .. 'ready', offset, offset + 99)
+  for _, id in ipairs(batch) do
+    local f = redis.call('HMGET', mkey(id), 'att', 'grp')
+    local ok = tonumber(f[1]) < maxAttempts
+    if ok and grouped and f[2] then
+      ok = redis.call('ZRANGE', base .. 'g:' .. f[2], 0, 0)[1] == id
+        and not redis.call('HGET', base .. 'gleased', f[2])
+    end
+    if ok then
+      picked[#picked + 1] = id
+      if #picked == n then break end
+    end
+  end
+  if #batch < 100 then break end
+  offset = offset + 100
+end
+local out = {}
+for _, id in ipairs(picked) do
+  local k = mkey(id)
+  redis.call('ZREM', base .. 'ready', id)
+  redis.call('ZREM', base .. 'deferred', id)
+  local ep = redis.call('HINCRBY', k, 'ep', 1)
+  local att = redis.call('HINCRBY', k, 'att', 1)
+  redis.call('HSET', k, 'st', 'leased', 'exp', ARGV[5], 'def', 0)
+  redis.call('ZADD', base .. 'leased', ARGV[5], id)
+  local grp = redis.call('HGET', k, 'grp')
+  if grp then redis.call('HINCRBY', base .. 'gleased', grp, 1) end
+  out[#out + 1] = id
+  out[#out + 1] = redis.call('HGET', k, 'body')
+  out[#out + 1] = ep
+  out[#out + 1] = att
+end
+return out
+`)
+
+// Settle operations.
+const (
+	opAck   = "ack"
+	opNack  = "nack"
+	opDefer = "defer"
+	opKill  = "kill"
+)
+
+// ARGV: now, maxAttempts, id, epoch, op, visible-at (nack, defer), reason
+// (kill, and a nack that spends the budget). It returns 1, or 0 when the
+// receipt is stale: the message is not leased, or leased at another epoch
+// (invariant Q2).
+var queueSettle = goredis.NewScript(luaQueueLib + `
+local id, op = ARGV[3], ARGV[5]
+local k = mkey(id)
+local m = redis.call('HMGET', k, 'st', 'ep', 'att')
+if m[1] ~= 'leased' or m[2] ~= ARGV[4] then return 0 end
+unlease(id, k)
+if op == 'ack' then
+  settled(id, k)
+  redis.call('DEL', k)
+  redis.call('HINCRBY', base .. 'stats', 'acked', 1)
+elseif op == 'kill' or (op == 'nack' and tonumber(m[3]) >= maxAttempts) then
+  makeDead(id, k, ARGV[7])
+elseif op == 'defer' then
+  redis.call('HINCRBY', k, 'att', -1)
+  redis.call('HSET', k, 'def', 1)
+  redis.call('ZADD', base .. 'deferred', ARGV[6], id)
+  makeQueued(id, k, ARGV[6])
+else
+  makeQueued(id, k, ARGV[6])
+end
+return 1
+`)
+
+// ARGV: now, maxAttempts, lease-expiry reason, ZRANGEBYLEX min, limit (<= 0
+// for all). It returns id, body, reason, attempts, enqueued-at and died-at
+// for each dead message.
+var queueDeadLetters = goredis.NewScript(luaQueueLib + `
+reap(ARGV[3])
+local ids
+if tonumber(ARGV[5]) > 0 then
+  ids = redis.call('ZRANGEBYLEX', base .. 'dead', ARGV[4], '+', 'LIMIT', 0, ARGV[5])
+else
+  ids = redis.call('ZRANGEBYLEX', base .. 'dead', ARGV[4], '+')
+end
+local out = {}
+for _, id in ipairs(ids) do
+  local m = redis.call('HMGET', mkey(id), 'body', 'rsn', 'att', 'enq', 'died')
+  out[#out + 1] = id
+  for i = 1, 5 do out[#out + 1] = m[i] or '' end
+end
+return out
+`)
+
+// ARGV: now, maxAttempts, then the ids to redrive. It returns how many were
+// dead and are queued again, visible now with attempts reset.
+var queueRedrive = goredis.NewScript(luaQueueLib + `
+local n = 0
+for i = 3, #ARGV do
+  local id = ARGV[i]
+  local k = mkey(id)
+  if redis.call('ZREM', base .. 'dead', id) == 1 then
+    redis.call('HSET', k, 'att', 0)
+    redis.call('HDEL', k, 'rsn', 'died')
+    local grp = redis.call('HGET', k, 'grp')
+    if grp then redis.call('ZADD', base .. 'g:' .. grp, redis.call('HGET', k, 'seq'), id) end
+    makeQueued(id, k, ARGV[1])
+    n = n + 1
+  end
+end
+return n
+`)
+
+// ARGV: now, maxAttempts. Removing only dead messages lowers enqueued and the
+// dead count equally, so conservation drift stays zero (invariant T1).
+var queuePurge = goredis.NewScript(luaQueueLib + `
+local ids = redis.call('ZRANGE', base .. 'dead', 0, -1)
+for _, id in ipairs(ids) do redis.call('DEL', mkey(id)) end
+redis.call('DEL', base .. 'dead')
+if #ids > 0 then redis.call('HINCRBY', base .. 'stats', 'enqueued', -#ids) end
+return #ids
+`)
+
+// ARGV: now, maxAttempts. Read-only: it does not reap, so expired leases
+// still count as leased (see statestore.QueueStats). It returns visible,
+// leased, dead, deferred and the oldest visible message's age.
+var queueStats = goredis.NewScript(luaQueueLib + `
+local visible = redis.call('ZCARD', base .. 'ready') + redis.call('ZCOUNT', base .. 'delayed', '-inf', now)
+local oldest
+local first = redis.call('ZRANGE', base .. 'ready', 0, 0)[1]
+if first then oldest = tonumber(redis.call('HGET', mkey(first), 'enq')) end
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', base .. 'delayed', '-inf', now)) do
+  local enq = tonumber(redis.call('HGET', mkey(id), 'enq'))
+  if not oldest or enq < oldest then oldest = enq end
+end
+local age = 0
+if oldest and now > oldest then age = now - oldest end
+return {
+  visible,
+  redis.call('ZCARD', base .. 'leased'),
+  redis.call('ZCARD', base .. 'dead'),
+  redis.call('ZCOUNT', base .. 'deferred', '(' .. ARGV[1], '+inf'),
+  age,
+}
+`)
+
+// ARGV: now, maxAttempts. It returns enqueued, queued, leased, acked, dead
+// and expirations.
+var queueConservation = goredis.NewScript(luaQueueLib + `
+local c = redis.call('HMGET', base .. 'stats', 'enqueued', 'acked', 'expirations')
+return {
+  tonumber(c[1] or '0'),
+  redis.call('ZCARD', base .. 'ready') + redis.call('ZCARD', base .. 'delayed'),
+  redis.call('ZCARD', base .. 'leased'),
+  tonumber(c[2] or '0'),
+  redis.call('ZCARD', base .. 'dead'),
+  tonumber(c[3] or '0'),
+}
+`)
+
+func (q *queueStore) tag(queue string) string { return q.s.tag("q", queue) }
+
+// queuesKey is the set of queue names ConservationStats walks.
+func (s *Store) queuesKey() string { return s.prefix + "queues" }
+
+// run runs a queue script, prepending the ARGV every queue script takes.
+func (q *queueStore) run(ctx context.Context, script *goredis.Script, queue string, args ...any) *goredis.Cmd {
+	return q.s.run(ctx, script, q.tag(queue), append([]any{nowMillis(), q.s.maxAttempts}, args...)...)
+}
+
+// Enqueue implements statestore.Queue, collapsing a not-yet-settled DedupKey.
+// The dedup lookup and the insert are one script, so the collapse holds even
+// for racing enqueues.
+func (q *queueStore) Enqueue(ctx context.Context, queue string, msg statestore.Message, o statestore.EnqueueOptions) (string, error) {
+	res, err := q.run(ctx, queueEnqueue, queue,
+		queue, msg.Body, nowMillis()+o.Delay.Milliseconds(), o.DedupKey, o.Group).Slice()
+	if err != nil {
+		return "", storeErr(err)
+	}
+	id, _ := res[0].(string)
+	// The first message of a queue registers it for ConservationStats; the
+	// set lives outside the queue's hash tag, so it is a separate command.
+	if toInt64(res[1]) == 1 {
+		if err := q.s.rdb.SAdd(ctx, q.s.queuesKey(), queue).Err(); err != nil {
+			return "", storeErr(err)
+		}
+	}
+	return id, nil
+}
+
+// Lease implements statestore.Queue: reap expirations, then lease up to n
+// visible messages, bumping each lease's epoch.
+func (q *queueStore) Lease(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	return q.lease(ctx, queue, n, leaseFor, false)
+}
+
+// LeaseGrouped implements statestore.GroupedQueue: Lease, leasing a grouped
+// message only while it heads its group and nothing of the group is leased.
+func (q *queueStore) LeaseGrouped(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	return q.lease(ctx, queue, n, leaseFor, true)
+}
+
+func (q *queueStore) lease(ctx context.Context, queue string, n int, leaseFor time.Duration, grouped bool) ([]statestore.LeasedMessage, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	groupedArg := 0
+	if grouped {
+		groupedArg = 1
+	}
+	res, err := q.run(ctx, queueLease, queue,
+		statestore.ReasonLeaseExpired, n, nowMillis()+leaseFor.Milliseconds(), groupedArg).Slice()
+	if err != nil {
+		return nil, storeErr(err)
+	}
+	var out []statestore.LeasedMessage
+	for i := 0; i+3 < len(res); i += 4 {
+		id, _ := res[i].(string)
+		epoch := toInt64(res[i+2])
+		out = append(out, statestore.LeasedMessage{
+			ID:       id,
+			Receipt:  statestore.EncodeReceipt(id, epoch),
+			Body:     toBytes(res[i+1]),
+			Attempts: int(toInt64(res[i+3])),
+		})
+	}
+	return out, nil
+}
+
+// queueOf returns the queue a message id belongs to, and false for an id this
+// driver did not mint.
+func queueOf(id string) (string, bool) {
+	queue, seq, ok := strings.CutLast(id, "/")
+	if !ok {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(seq, 10, 64); err != nil {
+		return "", false
+	}
+	return queue, true
+}
+
+// settle runs a settle op for receipt.
+func (q *queueStore) settle(ctx context.Context, receipt, op string, retryAfter time.Duration, reason string) error {
+	id, epoch, ok := statestore.DecodeReceipt(receipt)
+	if !ok {
+		return statestore.ErrInvalidReceipt
+	}
+	queue, ok := queueOf(id)
+	if !ok {
+		return statestore.ErrInvalidReceipt
+	}
+	res, err := q.run(ctx, queueSettle, queue,
+		id, epoch, op, nowMillis()+retryAfter.Milliseconds(), reason).Int()
+	if err != nil {
+		return storeErr(err)
+	}
+	if res == 0 {
+		return statestore.ErrInvalidReceipt
+	}
+	return nil
+}
+
+// Ack implements statestore.Queue.
+func (q *queueStore) Ack(ctx context.Context, receipt string) error {
+	return q.settle(ctx, receipt, opAck, 0, "")
+}
+
+// Nack implements statestore.Queue: requeue after retryAfter, or dead-letter
+// once the attempt budget is spent (invariant Q3).
+func (q *queueStore) Nack(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	return q.settle(ctx, receipt, opNack, retryAfter, statestore.ReasonRetriesExhausted)
+}
+
+// Defer implements statestore.Queue: requeue after retryAfter with the lease's
+// attempt refunded, never dead-lettering.
+func (q *queueStore) Defer(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	return q.settle(ctx, receipt, opDefer, retryAfter, "")
+}
+
+// Kill implements statestore.Queue: dead-letter the current delivery
+// immediately.
+func (q *queueStore) Kill(ctx context.Context, receipt string, reason string) error {
+	return q.settle(ctx, receipt, opKill, 0, reason)
+}
+
+// DeadLetters implements statestore.Queue: a page of dead-lettered messages,
+// ordered by id, paginated by page.Token (the last id of the previous page).
+// It reaps first, so messages exhausted purely by lease expiry show up even
+// if no Lease call has run since.
+func (q *queueStore) DeadLetters(ctx context.Context, queue string, page statestore.Page) ([]statestore.DeadMessage, error) {
+	lo := "-"
+	if page.Token != "" {
+		lo = "(" + page.Token
+	}
+	res, err := q.run(ctx, queueDeadLetters, queue, statestore.ReasonLeaseExpired, lo, page.Limit).Slice()
+	if err != nil {
+		return nil, storeErr(err)
+	}
+	var out []statestore.DeadMessage
+	for i := 0; i+5 < len(res); i += 6 {
+		dm := statestore.DeadMessage{
+			Body:       toBytes(res[i+1]),
+			Attempts:   int(toInt64(res[i+3])),
+			EnqueuedAt: time.UnixMilli(toInt64(res[i+4])),
+		}
+		dm.ID, _ = res[i].(string)
+		dm.Reason, _ = res[i+2].(string)
+		if died := toInt64(res[i+5]); died != 0 {
+			dm.DiedAt = time.UnixMilli(died)
+		}
+		out = append(out, dm)
+	}
+	return out, nil
+}
+
+// Redrive implements statestore.Queue: return dead messages to the queue with
+// attempts reset.
+func (q *queueStore) Redrive(ctx context.Context, queue string, ids []string) (int64, error) {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		if owner, ok := queueOf(id); ok && owner == queue {
+			args = append(args, id)
+		}
+	}
+	if len(args) == 0 {
+		return 0, nil
+	}
+	n, err := q.run(ctx, queueRedrive, queue, args...).Int64()
+	return n, storeErr(err)
+}
+
+// Purge implements statestore.Queue: delete every dead-lettered message for
+// queue and return the count removed.
+func (q *queueStore) Purge(ctx context.Context, queue string) (int64, error) {
+	n, err := q.run(ctx, queuePurge, queue).Int64()
+	return n, storeErr(err)
+}
+
+// Stats implements statestore.Queue: a read-only snapshot of the queue's
+// backlog. An unknown queue reports a zero snapshot.
+func (q *queueStore) Stats(ctx context.Context, queue string) (statestore.QueueStats, error) {
+	res, err := q.run(ctx, queueStats, queue).Int64Slice()
+	if err != nil {
+		return statestore.QueueStats{}, storeErr(err)
+	}
+	return statestore.QueueStats{
+		Visible:          res[0],
+		Leased:           res[1],
+		Dead:             res[2],
+		Deferred:         res[3],
+		OldestVisibleAge: time.Duration(res[4]) * time.Millisecond,
+	}, nil
+}
+
+// compile-time guard: the Store (the Capabilities value) is the conservation
+// reporter NewScoped registers, so the drift gauge actually observes it.
+var _ statestore.ConservationReporter = (*Store)(nil)
+
+// ConservationStats implements statestore.ConservationReporter (invariant
+// T1), summing each queue's counts, which one script reads atomically. On a
+// read failure it records a scrape error and returns a zero value, as the SQL
+// drivers do.
+func (s *Store) ConservationStats(ctx context.Context) statestore.ConservationStats {
+	var st statestore.ConservationStats
+	queues, err := s.rdb.SMembers(ctx, s.queuesKey()).Result()
+	if err != nil {
+		statestore.RecordConservationScrapeError(ctx)
+		return st
+	}
+	q := &queueStore{s}
+	for _, queue := range queues {
+		c, err := q.run(ctx, queueConservation, queue).Int64Slice()
+		if err != nil {
+			statestore.RecordConservationScrapeError(ctx)
+			return statestore.ConservationStats{}
+		}
+		st.Enqueued += c[0]
+		st.Queued += c[1]
+		st.Leased += c[2]
+		st.Acked += c[3]
+		st.Dead += c[4]
+		st.LeaseExpirations += c[5]
+	}
+	return st
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis is the statestore driver for teams that already run Redis (or
+// a Redis-protocol server) and want a low-latency backend for keyed state
+// without operating Postgres. It implements all three capabilities: KVStore
+// (with CountedKV and TxnKV), EventLog on Redis Streams, and Queue (with
+// GroupedQueue), plus Archiver.
+//
+// Every operation that reads-then-writes is a single Lua script, so it is
+// atomic on the server exactly as the SQL drivers' statements are in their
+// transactions. All keys one script touches share a hash tag, so the layout is
+// Redis Cluster compatible. Time is the client's: scripts are passed "now" in
+// unix milliseconds and never read the server clock, so TTL expiry and lease
+// expiry are exact on read (invariant K2) and testable under virtual time.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func init() {
+	statestore.Register("redis", func(ctx context.Context, c statestore.Config) (statestore.Capabilities, error) {
+		return New(ctx, c.DSN)
+	})
+}
+
+// DefaultKeyPrefix prefixes every key the driver writes, so the store can share
+// a Redis database with other applications.
+const DefaultKeyPrefix = "fission:statestore:"
+
+// Store is the Redis-backed Capabilities.
+type Store struct {
+	rdb         *goredis.Client
+	prefix      string
+	maxAttempts int
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithKeyPrefix replaces DefaultKeyPrefix. It must not contain '{' or '}',
+// which would break the hash tags.
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Store) { s.prefix = prefix }
+}
+
+// WithMaxAttempts sets the queue attempt budget (deliveries before a Nack
+// dead-letters). n <= 0 is ignored.
+func WithMaxAttempts(n int) Option {
+	return func(s *Store) {
+		if n > 0 {
+			s.maxAttempts = n
+		}
+	}
+}
+
+// New opens a Redis-backed statestore at dsn, a redis://, rediss:// or
+// unix:// URL as accepted by go-redis (e.g. redis://:password@host:6379/0).
+func New(ctx context.Context, dsn string, opts ...Option) (statestore.Capabilities, error) {
+	if dsn == "" {
+		return nil, errors.New("statestore/redis: empty DSN")
+	}
+	o, err := goredis.ParseURL(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("statestore/redis: %w", err)
+	}
+	rdb := goredis.NewClient(o)
+	s, err := Open(ctx, rdb, opts...)
+	if err != nil {
+		_ = rdb.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open returns a Store over rdb after checking the server is reachable. The
+// Store owns rdb from then on: Close closes it.
+func Open(ctx context.Context, rdb *goredis.Client, opts ...Option) (*Store, error) {
+	s := &Store{rdb: rdb, prefix: DefaultKeyPrefix, maxAttempts: statestore.DefaultMaxAttempts}
+	for _, o := range opts {
+		o(s)
+	}
+	if err := s.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("statestore/redis: ping: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) KV() (statestore.KVStore, error)        { return &kvStore{s}, nil }
+func (s *Store) EventLog() (statestore.EventLog, error) { return &eventLog{s}, nil }
+func (s *Store) Queue() (statestore.Queue, error)       { return &queueStore{s}, nil }
+
+// Ping reports whether the server is reachable.
+func (s *Store) Ping(ctx context.Context) error {
+	return storeErr(s.rdb.Ping(ctx).Err())
+}
+
+// Close closes the client; subsequent operations return ErrClosed.
+func (s *Store) Close() error {
+	return s.rdb.Close()
+}
+
+// tag returns the prefix shared by every key of one keyspace, stream or queue.
+// The names are length-prefixed so distinct ones never collide, and the braces
+// make the prefix a Redis Cluster hash tag, so one script's keys are in one
+// slot.
+func (s *Store) tag(kind string, names ...string) string {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString("{")
+	b.WriteString(kind)
+	for _, n := range names {
+		b.WriteString(":")
+		b.WriteString(strconv.Itoa(len(n)))
+		b.WriteString(":")
+		b.WriteString(n)
+	}
+	b.WriteString("}:")
+	return b.String()
+}
+
+// run runs script with the given hash-tag prefix as its only key. Scripts
+// derive their key names from it.
+func (s *Store) run(ctx context.Context, script *goredis.Script, tag string, args ...any) *goredis.Cmd {
+	return script.Run(ctx, s.rdb, []string{tag}, args...)
+}
+
+// storeErr maps the closed-client error to statestore.ErrClosed.
+func storeErr(err error) error {
+	if errors.Is(err, goredis.ErrClosed) {
+		return statestore.ErrClosed
+	}
+	return err
+}
+
+// nowMillis is the current wall clock as unix milliseconds, the unit every
+// stored time and script clock uses.
+func nowMillis() int64 { return time.Now().UnixMilli() }
+
+// itoa formats a script argument.
+func itoa(n int64) string { return strconv.FormatInt(n, 10) }
+
+// toInt64 reads an integer from a script reply element, which is an integer
+// or a decimal string depending on whether it came from Lua or a hash field.
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
+
+// toBytes reads a binary-safe string from a script reply element, nil when
+// it is empty or absent.
+func toBytes(v any) []byte {
+	if str, ok := v.(string); ok && str != "" {
+		return []byte(str)
+	}
+	return nil
+}
@@ -67,6 +67,31 @@ func (c *scopedCaps) Close() error {
 	return c.inner.Close()
 }
 
+// Export and Import implement Archiver so the wrapper keeps the capability of
+// an archiving driver; over one without it, they return
+// ErrCapabilityUnavailable.
+func (c *scopedCaps) Export(ctx context.Context, f ArchiveFilter, fn func(ArchiveRecord) error) error {
+	a, ok := c.inner.(Archiver)
+	if !ok {
+		recordOp(ctx, "archive", "export")
+		return ErrCapabilityUnavailable
+	}
+	err := a.Export(ctx, f, fn)
+	observe(ctx, "archive", "export", err)
+	return err
+}
+
+func (c *scopedCaps) Import(ctx context.Context, recs []ArchiveRecord) error {
+	a, ok := c.inner.(Archiver)
+	if !ok {
+		recordOp(ctx, "archive", "import")
+		return ErrCapabilityUnavailable
+	}
+	err := a.Import(ctx, recs)
+	observe(ctx, "archive", "import", err)
+	return err
+}
+
 // isBusinessOutcome reports whether err is an expected control-flow result rather
 // than an operational failure, so the errors_total counter tracks real failures
 // (IO, closed store) and not routine not-found/conflict/quota outcomes.
@@ -75,7 +100,10 @@ func isBusinessOutcome(err error) bool {
 	case errors.Is(err, ErrNotFound),
 		errors.Is(err, ErrVersionConflict),
 		errors.Is(err, ErrQuotaExceeded),
-		errors.Is(err, ErrInvalidReceipt):
+		errors.Is(err, ErrInvalidReceipt),
+		errors.Is(err, ErrCompacted),
+		errors.Is(err, ErrInvalidTxn),
+		errors.Is(err, ErrInvalidArchive):
 		return true
 	default:
 		return false
@@ -153,6 +181,69 @@ func (k *scopedKV) List(ctx context.Context, s Scope, prefix string, page Page)
 	return kp, err
 }
 
+// Txn implements TxnKV, enforcing the same quota as Set: every put's value
+// is checked against MaxValueBytes, and the live-key budget is checked by the
+// driver atomically with the writes. A positive maxKeys is authoritative (the
+// statestoresvc head forwards the budget its caller resolved, as with
+// SetCounted); otherwise the scope's MaxKeys applies.
+func (k *scopedKV) Txn(ctx context.Context, s Scope, t Txn, maxKeys int64) ([]int64, error) {
+	tk, ok := k.inner.(TxnKV)
+	if !ok {
+		recordOp(ctx, "kv", "txn")
+		return nil, ErrCapabilityUnavailable
+	}
+	q := k.resolver.Resolve(s)
+	if q.MaxValueBytes > 0 {
+		for _, op := range t.Ops {
+			if op.Type == TxnPut && int64(len(op.Value)) > q.MaxValueBytes {
+				recordOp(ctx, "kv", "txn")
+				recordQuotaRejection(ctx, "value_bytes")
+				return nil, ErrQuotaExceeded
+			}
+		}
+	}
+	if maxKeys <= 0 {
+		maxKeys = q.MaxKeys
+	}
+	versions, err := tk.Txn(ctx, s, t, maxKeys)
+	if errors.Is(err, ErrQuotaExceeded) {
+		recordQuotaRejection(ctx, "keys")
+	}
+	observe(ctx, "kv", "txn", err)
+	return versions, err
+}
+
+// Revision and Watch implement WatchableKV so the wrapper keeps the capability
+// of a watchable driver; over one without it, they return
+// ErrCapabilityUnavailable.
+func (k *scopedKV) Revision(ctx context.Context, s Scope) (int64, error) {
+	wk, ok := k.inner.(WatchableKV)
+	if !ok {
+		recordOp(ctx, "kv", "revision")
+		return 0, ErrCapabilityUnavailable
+	}
+	rev, err := wk.Revision(ctx, s)
+	observe(ctx, "kv", "revision", err)
+	return rev, err
+}
+
+// Watch records one operation per watch, not per delivery. A watch ended by
+// its context is its normal end, not a failure.
+func (k *scopedKV) Watch(ctx context.Context, s Scope, prefix string, fromRevision int64, fn func([]KVEvent, int64) error) error {
+	wk, ok := k.inner.(WatchableKV)
+	if !ok {
+		recordOp(ctx, "kv", "watch")
+		return ErrCapabilityUnavailable
+	}
+	err := wk.Watch(ctx, s, prefix, fromRevision, fn)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		observe(ctx, "kv", "watch", nil)
+	} else {
+		observe(ctx, "kv", "watch", err)
+	}
+	return err
+}
+
 // meteredEventLog adds metrics to an EventLog.
 type meteredEventLog struct{ inner EventLog }
 
@@ -195,6 +286,19 @@ func (q *meteredQueue) Lease(ctx context.Context, queue string, n int, leaseFor
 	return l, err
 }
 
+// LeaseGrouped implements GroupedQueue so the wrapper keeps the capability of
+// a grouped driver; over one without it, it returns ErrCapabilityUnavailable.
+func (q *meteredQueue) LeaseGrouped(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]LeasedMessage, error) {
+	gq, ok := q.inner.(GroupedQueue)
+	if !ok {
+		recordOp(ctx, "queue", "lease")
+		return nil, ErrCapabilityUnavailable
+	}
+	l, err := gq.LeaseGrouped(ctx, queue, n, leaseFor)
+	observe(ctx, "queue", "lease", err)
+	return l, err
+}
+
 func (q *meteredQueue) Ack(ctx context.Context, receipt string) error {
 	err := q.inner.Ack(ctx, receipt)
 	observe(ctx, "queue", "ack", err)
@@ -207,6 +311,12 @@ func (q *meteredQueue) Nack(ctx context.Context, receipt string, retryAfter time
 	return err
 }
 
+func (q *meteredQueue) Defer(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	err := q.inner.Defer(ctx, receipt, retryAfter)
+	observe(ctx, "queue", "defer", err)
+	return err
+}
+
 func (q *meteredQueue) Kill(ctx context.Context, receipt string, reason string) error {
 	err := q.inner.Kill(ctx, receipt, reason)
 	observe(ctx, "queue", "kill", err)
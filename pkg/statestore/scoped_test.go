@@ -99,6 +99,25 @@ func TestScoped_QuotaMaxKeys(t *testing.T) {
 	require.NoError(t, kv.Set(ctx, sc, "a", []byte("v2"), statestore.SetOptions{}))
 }
 
+func TestScoped_TxnQuota(t *testing.T) {
+	kv, _ := scoped(t, statestore.Quota{MaxKeys: 2, MaxValueBytes: 4})
+	tk, ok := kv.(statestore.TxnKV)
+	require.True(t, ok, "scoped KV must forward TxnKV")
+	ctx := t.Context()
+	put := func(key, val string) statestore.TxnOp {
+		return statestore.TxnOp{Type: statestore.TxnPut, Key: key, Value: []byte(val)}
+	}
+
+	_, err := tk.Txn(ctx, sc, statestore.Txn{Ops: []statestore.TxnOp{put("a", "1"), put("b", "12345")}}, 0)
+	require.ErrorIs(t, err, statestore.ErrQuotaExceeded, "every put's value is held to MaxValueBytes")
+	_, err = tk.Txn(ctx, sc, statestore.Txn{Ops: []statestore.TxnOp{put("a", "1"), put("b", "2"), put("c", "3")}}, 0)
+	require.ErrorIs(t, err, statestore.ErrQuotaExceeded, "the scope's MaxKeys applies without a caller budget")
+	_, err = tk.Txn(ctx, sc, statestore.Txn{Ops: []statestore.TxnOp{put("a", "1"), put("b", "2")}}, 0)
+	require.NoError(t, err)
+	_, err = tk.Txn(ctx, sc, statestore.Txn{Ops: []statestore.TxnOp{put("c", "3")}}, 3)
+	require.NoError(t, err, "a caller budget is authoritative")
+}
+
 func TestScoped_RecordsOps(t *testing.T) {
 	kv, _ := scoped(t, statestore.Quota{})
 	before := counterTotal(t, "fission_statestore_ops_total")
@@ -12,6 +12,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"path/filepath"
 
 	// modernc.org/sqlite registers the "sqlite" database/sql driver.
 	_ "modernc.org/sqlite"
@@ -71,5 +72,9 @@ func New(ctx context.Context, dsn string) (statestore.Capabilities, error) {
 		_ = db.Close()
 		return nil, err
 	}
+	// Exports spool beside the database, on the volume sized for its data.
+	if dsn != ":memory:" {
+		store.SetSpoolDir(filepath.Dir(dsn))
+	}
 	return store, nil
 }
@@ -5,11 +5,17 @@
 package sqlite_test
 
 import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statestore/memory"
 	"github.com/fission/fission/pkg/statestore/sqlite"
 	"github.com/fission/fission/pkg/statestore/statestoretest"
 )
@@ -28,6 +34,7 @@ func TestConformance_SQLite(t *testing.T) {
 	}
 	statestoretest.RunConformance(t, factory)
 	statestoretest.RunTimingConformance(t, factory)
+	statestoretest.RunWatchConformance(t, factory)
 }
 
 func TestConformance_SQLite_Linearizability(t *testing.T) {
@@ -38,3 +45,88 @@ func TestConformance_SQLite_Linearizability(t *testing.T) {
 		return caps
 	})
 }
+
+// TestArchive_MemoryToSQLite migrates an archive from the memory driver, as a
+// dev setup holds it, to SQLite. TestArchive_MemoryToSQLiteToPostgres carries
+// it on to Postgres.
+func TestArchive_MemoryToSQLite(t *testing.T) {
+	statestoretest.RunArchiveRoundTrip(t,
+		func(t *testing.T) statestore.Capabilities {
+			caps, err := memory.New()
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = caps.Close() })
+			return caps
+		},
+		func(t *testing.T) statestore.Capabilities {
+			caps, err := sqlite.New(t.Context(), t.TempDir()+"/archive.db")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = caps.Close() })
+			return caps
+		},
+	)
+}
+
+// TestExport_DoesNotBlockWriters pins that an export reads a snapshot: a
+// write while the archive is still streaming must land, not wait on the
+// store's one connection for as long as the reader takes.
+func TestExport_DoesNotBlockWriters(t *testing.T) {
+	caps, err := sqlite.New(t.Context(), t.TempDir()+"/state.db")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	scope := statestore.Scope{Namespace: "ns", Owner: "fn", Keyspace: "ks"}
+	require.NoError(t, kv.Set(t.Context(), scope, "a", []byte("1"), statestore.SetOptions{}))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	var keys []string
+	err = caps.(statestore.Archiver).Export(ctx, statestore.ArchiveFilter{}, func(r statestore.ArchiveRecord) error {
+		if r.KV != nil {
+			keys = append(keys, r.KV.Key)
+		}
+		return kv.Set(ctx, scope, "b", []byte("2"), statestore.SetOptions{})
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, keys, "the export is the snapshot taken before the write")
+
+	v, err := kv.Get(t.Context(), scope, "b")
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), v.Data)
+}
+
+// TestExport_SpoolsSelectedRecordsBesideDatabase: a scoped export spools only
+// the records it selects, in the database's directory rather than the system
+// temp dir, and removes the spool when it ends.
+func TestExport_SpoolsSelectedRecordsBesideDatabase(t *testing.T) {
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+	dir := t.TempDir()
+	caps, err := sqlite.New(t.Context(), filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	for ns, value := range map[string]string{"a": "tenant-a", "b": "tenant-b"} {
+		scope := statestore.Scope{Namespace: ns, Owner: "fn", Keyspace: "ks"}
+		require.NoError(t, kv.Set(t.Context(), scope, "k", []byte(value), statestore.SetOptions{}))
+	}
+
+	var values []string
+	err = caps.(statestore.Archiver).Export(t.Context(), statestore.ArchiveFilter{Namespace: "a"}, func(r statestore.ArchiveRecord) error {
+		if r.KV != nil {
+			values = append(values, string(r.KV.Value))
+		}
+		spools, err := filepath.Glob(filepath.Join(dir, "export-*"))
+		require.NoError(t, err)
+		require.Len(t, spools, 1)
+		raw, err := os.ReadFile(spools[0])
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), base64.StdEncoding.EncodeToString([]byte("tenant-b")))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant-a"}, values)
+	spools, err := filepath.Glob(filepath.Join(dir, "export-*"))
+	require.NoError(t, err)
+	require.Empty(t, spools, "the spool is removed")
+}
@@ -0,0 +1,344 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var _ statestore.Archiver = (*Store)(nil)
+
+// Export implements statestore.Archiver. It reads inside one read-only
+// transaction, REPEATABLE READ on Postgres so every query sees the same
+// snapshot. SQLite has a single connection, so a transaction held while fn
+// streams would stall every writer for as long as the client reads; the
+// selected records are spooled first and the export reads the spool instead.
+func (s *Store) Export(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	if s.dialect.Name == "sqlite" {
+		return s.exportSnapshot(ctx, f, fn)
+	}
+	return s.exportFrom(ctx, s.db, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, f, fn)
+}
+
+// exportSnapshot writes the records f selects to a spool, holding the
+// connection only while it does, and passes them to fn from the spool. The
+// spool is a file in the spool directory (see SetSpoolDir), removed when the
+// export ends, or memory when there is none.
+func (s *Store) exportSnapshot(ctx context.Context, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	var spool io.ReadWriter = new(bytes.Buffer)
+	if s.spoolDir != "" {
+		file, err := os.CreateTemp(s.spoolDir, "export-*.spool")
+		if err != nil {
+			return fmt.Errorf("statestore/sqlstore: spool for export: %w", err)
+		}
+		defer func() {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}()
+		spool = file
+	}
+	w := bufio.NewWriter(spool)
+	enc := json.NewEncoder(w)
+	if err := s.exportFrom(ctx, s.db, nil, f, func(r statestore.ArchiveRecord) error { return enc.Encode(r) }); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("statestore/sqlstore: spool for export: %w", err)
+	}
+	if file, ok := spool.(*os.File); ok {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	dec := json.NewDecoder(bufio.NewReader(spool))
+	for {
+		var r statestore.ArchiveRecord
+		err := dec.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Store) exportFrom(ctx context.Context, db *sql.DB, opts *sql.TxOptions, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := s.exportKV(ctx, tx, f, fn); err != nil {
+		return err
+	}
+	if err := s.exportStreams(ctx, tx, f, fn); err != nil {
+		return err
+	}
+	if !f.MatchQueues() {
+		return nil
+	}
+	return s.exportQueues(ctx, tx, fn)
+}
+
+func (s *Store) exportKV(ctx context.Context, tx *sql.Tx, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	col := s.dialect.Collate
+	query := `SELECT namespace, owner, keyspace, key, value, version, expires_at FROM state_kv
+		 WHERE (expires_at IS NULL OR expires_at > ?)`
+	args := []any{nowNanos()}
+	if f.Namespace != "" {
+		query += ` AND namespace = ?`
+		args = append(args, f.Namespace)
+	}
+	query += ` ORDER BY namespace` + col + `, owner` + col + `, keyspace` + col + `, key` + col
+	rows, err := tx.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			k       statestore.ArchivedKey
+			expires sql.NullInt64
+		)
+		if err := rows.Scan(&k.Scope.Namespace, &k.Scope.Owner, &k.Scope.Keyspace, &k.Key, &k.Value, &k.Version, &expires); err != nil {
+			return err
+		}
+		k.ExpiresAt = nullableTime(expires)
+		if err := fn(statestore.ArchiveRecord{KV: &k}); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *Store) exportStreams(ctx context.Context, tx *sql.Tx, f statestore.ArchiveFilter, fn func(statestore.ArchiveRecord) error) error {
+	// Collect the heads first: a transaction's connection reads one result
+	// set at a time.
+	rows, err := tx.QueryContext(ctx, `SELECT stream, head FROM state_streams ORDER BY stream`+s.dialect.Collate)
+	if err != nil {
+		return err
+	}
+	var streams []statestore.ArchivedStream
+	for rows.Next() {
+		var st statestore.ArchivedStream
+		if err := rows.Scan(&st.Stream, &st.Head); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if f.MatchStream(st.Stream) {
+			streams = append(streams, st)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	for _, st := range streams {
+		if err := fn(statestore.ArchiveRecord{Stream: &st}); err != nil {
+			return err
+		}
+		if err := s.exportEvents(ctx, tx, st.Stream, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) exportEvents(ctx context.Context, tx *sql.Tx, stream string, fn func(statestore.ArchiveRecord) error) error {
+	rows, err := tx.QueryContext(ctx, s.rebind(
+		`SELECT seq, type, payload, at FROM state_events WHERE stream = ? ORDER BY seq`), stream)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			ev statestore.ArchivedEvent
+			at int64
+		)
+		if err := rows.Scan(&ev.Seq, &ev.Type, &ev.Payload, &at); err != nil {
+			return err
+		}
+		ev.Stream = stream
+		ev.At = unixNanos(at)
+		if err := fn(statestore.ArchiveRecord{Event: &ev}); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *Store) exportQueues(ctx context.Context, tx *sql.Tx, fn func(statestore.ArchiveRecord) error) error {
+	col := s.dialect.Collate
+	rows, err := tx.QueryContext(ctx, s.rebind(
+		`SELECT queue, id, body, state, visible_at, expiry, attempts, dedup_key, group_key, reason, enqueued_at, died_at, deferred
+		 FROM state_queue WHERE state IN (?, ?, ?) ORDER BY queue`+col+`, enqueued_at, id`+col),
+		stQueued, stLeased, stDead,
+	)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			m                     statestore.ArchivedMessage
+			state                 string
+			visibleAt, enqueuedAt int64
+			expiry, diedAt        sql.NullInt64
+			dedup, group, reason  sql.NullString
+			deferred              int64
+		)
+		if err := rows.Scan(&m.Queue, &m.ID, &m.Body, &state, &visibleAt, &expiry, &m.Attempts,
+			&dedup, &group, &reason, &enqueuedAt, &diedAt, &deferred); err != nil {
+			return err
+		}
+		m.EnqueuedAt = unixNanos(enqueuedAt)
+		switch state {
+		case stQueued:
+			m.State = statestore.ArchivedQueued
+			m.VisibleAt = unixNanos(visibleAt)
+			m.DedupKey, m.Group, m.Deferred = dedup.String, group.String, deferred != 0
+		case stLeased:
+			m.State = statestore.ArchivedQueued
+			m.VisibleAt = nullableTime(expiry)
+			m.Attempts--
+			m.DedupKey, m.Group = dedup.String, group.String
+		case stDead:
+			m.State = statestore.ArchivedDead
+			m.Reason = reason.String
+			m.DiedAt = nullableTime(diedAt)
+		}
+		if err := fn(statestore.ArchiveRecord{Message: &m}); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Import implements statestore.Archiver in one transaction. A key is written
+// under its scope's lockScope and recorded as a put for the scope's watches.
+func (s *Store) Import(ctx context.Context, recs []statestore.ArchiveRecord) error {
+	scopes := map[statestore.Scope]bool{}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		err := statestore.ValidateImport(recs, func(stream string) (int64, int64, error) {
+			var last, head int64
+			err := tx.QueryRowContext(ctx, s.rebind(`SELECT head FROM state_streams WHERE stream = ?`), stream).Scan(&head)
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, 0, nil
+			}
+			if err != nil {
+				return 0, 0, err
+			}
+			err = tx.QueryRowContext(ctx, s.rebind(
+				`SELECT COALESCE(MAX(seq), 0) FROM state_events WHERE stream = ?`), stream).Scan(&last)
+			return last, head, err
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range recs {
+			var err error
+			switch {
+			case r.KV != nil:
+				scopes[r.KV.Scope] = true
+				err = s.importKey(ctx, tx, r.KV)
+			case r.Stream != nil:
+				err = s.importStream(ctx, tx, r.Stream)
+			case r.Event != nil:
+				_, err = s.execOn(ctx, tx,
+					`INSERT INTO state_events (stream, seq, type, payload, at) VALUES (?, ?, ?, ?, ?)`,
+					r.Event.Stream, r.Event.Seq, r.Event.Type, r.Event.Payload, timeNanos(r.Event.At),
+				)
+			case r.Message != nil:
+				err = s.importMessage(ctx, tx, r.Message)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for sc := range scopes {
+			s.watchers.wake(sc)
+		}
+	}
+	return err
+}
+
+func (s *Store) importKey(ctx context.Context, tx *sql.Tx, k *statestore.ArchivedKey) error {
+	if err := s.lockScope(ctx, tx, k.Scope); err != nil {
+		return err
+	}
+	expires := nullNanos(k.ExpiresAt.UnixNano(), !k.ExpiresAt.IsZero())
+	value := k.Value
+	if value == nil {
+		value = []byte{}
+	}
+	if _, err := s.execOn(ctx, tx,
+		`INSERT INTO state_kv (namespace, owner, keyspace, key, value, version, expires_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (namespace, owner, keyspace, key) DO UPDATE SET
+		   value = excluded.value, version = excluded.version, expires_at = excluded.expires_at`,
+		k.Scope.Namespace, k.Scope.Owner, k.Scope.Keyspace, k.Key, value, k.Version, expires,
+	); err != nil {
+		return err
+	}
+	return s.recordChange(ctx, tx, k.Scope, statestore.KVEvent{
+		Type: statestore.KVEventPut, Key: k.Key, Value: value, Version: k.Version, ExpiresAt: k.ExpiresAt,
+	})
+}
+
+func (s *Store) importStream(ctx context.Context, tx *sql.Tx, st *statestore.ArchivedStream) error {
+	if _, err := s.execOn(ctx, tx, `DELETE FROM state_events WHERE stream = ?`, st.Stream); err != nil {
+		return err
+	}
+	_, err := s.execOn(ctx, tx,
+		`INSERT INTO state_streams (stream, head) VALUES (?, ?)
+		 ON CONFLICT (stream) DO UPDATE SET head = excluded.head`,
+		st.Stream, st.Head,
+	)
+	return err
+}
+
+func (s *Store) importMessage(ctx context.Context, tx *sql.Tx, m *statestore.ArchivedMessage) error {
+	var (
+		dedup, group, reason sql.NullString
+		diedAt               sql.NullInt64
+		deferred             int64
+	)
+	state := stQueued
+	if m.State == statestore.ArchivedDead {
+		state = stDead
+		reason = sql.NullString{String: m.Reason, Valid: true}
+		diedAt = nullNanos(m.DiedAt.UnixNano(), !m.DiedAt.IsZero())
+	} else {
+		dedup = sql.NullString{String: m.DedupKey, Valid: m.DedupKey != ""}
+		group = sql.NullString{String: m.Group, Valid: m.Group != ""}
+		if m.Deferred {
+			deferred = 1
+		}
+	}
+	_, err := s.execOn(ctx, tx,
+		`INSERT INTO state_queue (id, queue, body, state, visible_at, attempts, epoch, dedup_key, group_key, reason, enqueued_at, died_at, deferred)
+		 VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?)`,
+		newMessageID(m.Queue), m.Queue, m.Body, state, timeNanos(m.VisibleAt), m.Attempts,
+		dedup, group, reason, timeNanos(m.EnqueuedAt), diedAt, deferred,
+	)
+	return err
+}
@@ -13,9 +13,33 @@ import (
 // nowNanos is the current wall clock as unix nanoseconds — the on-disk time unit.
 func nowNanos() int64 { return time.Now().UnixNano() }
 
+// enqueueNanos is nowNanos made strictly increasing across the store's
+// enqueues, so messages enqueued one after another keep their order under
+// enqueued_at even on a clock too coarse to tell them apart — the order a
+// message group's FIFO delivery promises.
+func (s *Store) enqueueNanos() int64 {
+	for {
+		now, last := nowNanos(), s.lastEnqueue.Load()
+		if now <= last {
+			now = last + 1
+		}
+		if s.lastEnqueue.CompareAndSwap(last, now) {
+			return now
+		}
+	}
+}
+
 // unixNanos converts stored unix-nanoseconds back to a time.Time.
 func unixNanos(n int64) time.Time { return time.Unix(0, n) }
 
+// timeNanos converts t to unix nanoseconds, 0 for the zero time.
+func timeNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
 // nullableTime converts a nullable unix-nanos column to a time.Time (zero when
 // the column is NULL).
 func nullableTime(n sql.NullInt64) time.Time {
@@ -44,121 +44,119 @@ func (k *kvStore) Get(ctx context.Context, sc statestore.Scope, key string) (sta
 // version check and lose the update (SQLite's single writer hides it, but the
 // contract must hold on both). The row lock the UPDATE/upsert takes serializes
 // concurrent CAS on the same key, and the WHERE re-check on the committed row is
-// what makes CAS linearizable (invariant K1).
+// what makes CAS linearizable (invariant K1). The write and its change record
+// (see recordChange) commit together.
 func (k *kvStore) Set(ctx context.Context, sc statestore.Scope, key string, val []byte, o statestore.SetOptions) error {
-	return k.setOn(ctx, k.s.db, sc, key, val, o)
+	err := k.s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := k.s.lockScope(ctx, tx, sc); err != nil {
+			return err
+		}
+		_, err := k.setOn(ctx, tx, sc, key, val, o)
+		return err
+	})
+	if err == nil {
+		k.s.watchers.wake(sc)
+	}
+	return err
 }
 
-// setOn is Set against e (the pool or a transaction), so SetCounted can run
-// the identical statements inside its quota transaction.
-func (k *kvStore) setOn(ctx context.Context, e execer, sc statestore.Scope, key string, val []byte, o statestore.SetOptions) error {
+// setOn is Set inside tx, so SetCounted and Txn can run the identical
+// statements inside their transactions. RETURNING yields the new version, and
+// no row means the CAS/create-only check failed on the committed row.
+func (k *kvStore) setOn(ctx context.Context, tx *sql.Tx, sc statestore.Scope, key string, val []byte, o statestore.SetOptions) (int64, error) {
 	now := nowNanos()
 	var expires sql.NullInt64
 	if o.TTL > 0 {
 		expires = nullNanos(now+o.TTL.Nanoseconds(), true)
 	}
 
+	var row *sql.Row
 	switch {
 	case o.IfVersion == nil:
 		// Unconditional upsert. An expired existing row counts as absent, so its
 		// version resets to 1 (parity with the memory driver).
-		_, err := k.s.execOn(ctx, e,
+		row = tx.QueryRowContext(ctx, k.s.rebind(
 			`INSERT INTO state_kv (namespace, owner, keyspace, key, value, version, expires_at)
 			 VALUES (?, ?, ?, ?, ?, 1, ?)
 			 ON CONFLICT (namespace, owner, keyspace, key) DO UPDATE SET
 			   value = excluded.value,
 			   version = CASE WHEN state_kv.expires_at IS NOT NULL AND state_kv.expires_at <= ?
 			                  THEN 1 ELSE state_kv.version + 1 END,
-			   expires_at = excluded.expires_at`,
+			   expires_at = excluded.expires_at
+			 RETURNING version`),
 			sc.Namespace, sc.Owner, sc.Keyspace, key, val, expires, now,
 		)
-		return err
 
 	case *o.IfVersion == 0:
 		// Create-only: succeed if the key is absent or expired; conflict if a live
 		// row exists.
-		res, err := k.s.execOn(ctx, e,
+		row = tx.QueryRowContext(ctx, k.s.rebind(
 			`INSERT INTO state_kv (namespace, owner, keyspace, key, value, version, expires_at)
 			 VALUES (?, ?, ?, ?, ?, 1, ?)
 			 ON CONFLICT (namespace, owner, keyspace, key) DO UPDATE SET
 			   value = excluded.value, version = 1, expires_at = excluded.expires_at
-			 WHERE state_kv.expires_at IS NOT NULL AND state_kv.expires_at <= ?`,
+			 WHERE state_kv.expires_at IS NOT NULL AND state_kv.expires_at <= ?
+			 RETURNING version`),
 			sc.Namespace, sc.Owner, sc.Keyspace, key, val, expires, now,
 		)
-		return conflictIfNoRows(res, err)
 
 	default:
 		// CAS on *o.IfVersion: match a live row at exactly that version.
-		res, err := k.s.execOn(ctx, e,
+		row = tx.QueryRowContext(ctx, k.s.rebind(
 			`UPDATE state_kv SET value = ?, version = version + 1, expires_at = ?
 			 WHERE namespace = ? AND owner = ? AND keyspace = ? AND key = ?
-			   AND version = ? AND (expires_at IS NULL OR expires_at > ?)`,
+			   AND version = ? AND (expires_at IS NULL OR expires_at > ?)
+			 RETURNING version`),
 			val, expires, sc.Namespace, sc.Owner, sc.Keyspace, key, *o.IfVersion, now,
 		)
-		return conflictIfNoRows(res, err)
 	}
+	var version int64
+	if err := row.Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, statestore.ErrVersionConflict
+		}
+		return 0, err
+	}
+	return version, k.s.recordChange(ctx, tx, sc, statestore.KVEvent{
+		Type: statestore.KVEventPut, Key: key, Value: val, Version: version, ExpiresAt: nullableTime(expires),
+	})
 }
 
 // SetCounted implements statestore.CountedKV. The transaction first takes a
-// row lock on the keyspace's state_quota row, serializing every counted writer
-// to that keyspace (Postgres: ON CONFLICT DO UPDATE locks the row under READ
-// COMMITTED; SQLite's single writer serializes anyway). Under that lock the
-// TTL-filtered live-key COUNT and the write are one atomic step (RFC-0023 S3 /
-// quota.tla), and expired rows drop out of the count with no drift. Scopes
-// enforcing a budget must funnel all writes through SetCounted (scopedKV
-// does); the per-key statements in setOn stay atomic regardless.
+// row lock on the keyspace's state_quota row (lockQuota), serializing every
+// counted writer to that keyspace. Under that lock the TTL-filtered live-key
+// COUNT and the write are one atomic step (RFC-0023 S3 / quota.tla), and
+// expired rows drop out of the count with no drift. Scopes enforcing a budget
+// must funnel all writes through SetCounted or Txn (scopedKV does); the
+// per-key statements in setOn stay atomic regardless.
 func (k *kvStore) SetCounted(ctx context.Context, sc statestore.Scope, key string, val []byte, o statestore.SetOptions, maxKeys int64) error {
 	if maxKeys <= 0 {
 		return k.Set(ctx, sc, key, val, o)
 	}
-	return k.s.inTx(ctx, func(tx *sql.Tx) error {
-		if _, err := k.s.execOn(ctx, tx,
-			`INSERT INTO state_quota (namespace, owner, keyspace) VALUES (?, ?, ?)
-			 ON CONFLICT (namespace, owner, keyspace) DO UPDATE SET keyspace = excluded.keyspace`,
-			sc.Namespace, sc.Owner, sc.Keyspace,
-		); err != nil {
+	err := k.s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := k.lockQuota(ctx, tx, sc); err != nil {
+			return err
+		}
+		if err := k.s.lockScope(ctx, tx, sc); err != nil {
 			return err
 		}
 
 		now := nowNanos()
-		var (
-			version int64
-			expires sql.NullInt64
-		)
-		err := tx.QueryRowContext(ctx, k.s.rebind(
-			`SELECT version, expires_at FROM state_kv WHERE namespace = ? AND owner = ? AND keyspace = ? AND key = ?`),
-			sc.Namespace, sc.Owner, sc.Keyspace, key,
-		).Scan(&version, &expires)
-		exists := false
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-		case err != nil:
+		curVersion, err := k.liveVersion(ctx, tx, sc, key, now)
+		if err != nil {
 			return err
-		default:
-			exists = !expiredAt(expires, now)
 		}
 
 		// CAS precedence before the budget check (parity with the memory
 		// driver): a write that could never apply is a version conflict, not a
 		// quota rejection.
-		if o.IfVersion != nil {
-			curVersion := int64(0)
-			if exists {
-				curVersion = version
-			}
-			if curVersion != *o.IfVersion {
-				return statestore.ErrVersionConflict
-			}
+		if o.IfVersion != nil && curVersion != *o.IfVersion {
+			return statestore.ErrVersionConflict
 		}
 
-		if !exists {
-			var live int64
-			if err := tx.QueryRowContext(ctx, k.s.rebind(
-				`SELECT COUNT(*) FROM state_kv
-				 WHERE namespace = ? AND owner = ? AND keyspace = ? AND key <> ?
-				   AND (expires_at IS NULL OR expires_at > ?)`),
-				sc.Namespace, sc.Owner, sc.Keyspace, key, now,
-			).Scan(&live); err != nil {
+		if curVersion == 0 {
+			live, err := k.liveKeys(ctx, tx, sc, now)
+			if err != nil {
 				return err
 			}
 			if live >= maxKeys {
@@ -166,45 +164,185 @@ func (k *kvStore) SetCounted(ctx context.Context, sc statestore.Scope, key strin
 			}
 		}
 
-		return k.setOn(ctx, tx, sc, key, val, o)
+		_, err = k.setOn(ctx, tx, sc, key, val, o)
+		return err
 	})
+	if err == nil {
+		k.s.watchers.wake(sc)
+	}
+	return err
 }
 
-// conflictIfNoRows maps an atomic write that changed no rows to
-// ErrVersionConflict (the CAS/create-only check failed on the committed row).
-func conflictIfNoRows(res sql.Result, err error) error {
-	if err != nil {
-		return err
-	}
-	n, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if n == 0 {
-		return statestore.ErrVersionConflict
+// lockQuota takes the row lock on the keyspace's state_quota row until tx
+// ends (Postgres: ON CONFLICT DO UPDATE locks the row under READ COMMITTED;
+// SQLite's single writer serializes anyway). Lock order: state_quota, then
+// state_kv_revisions (lockScope), then state_kv rows.
+func (k *kvStore) lockQuota(ctx context.Context, tx *sql.Tx, sc statestore.Scope) error {
+	_, err := k.s.execOn(ctx, tx,
+		`INSERT INTO state_quota (namespace, owner, keyspace) VALUES (?, ?, ?)
+		 ON CONFLICT (namespace, owner, keyspace) DO UPDATE SET keyspace = excluded.keyspace`,
+		sc.Namespace, sc.Owner, sc.Keyspace,
+	)
+	return err
+}
+
+// liveVersion returns key's version inside tx, or 0 if it is absent or
+// expired at now.
+func (k *kvStore) liveVersion(ctx context.Context, tx *sql.Tx, sc statestore.Scope, key string, now int64) (int64, error) {
+	var (
+		version int64
+		expires sql.NullInt64
+	)
+	err := tx.QueryRowContext(ctx, k.s.rebind(
+		`SELECT version, expires_at FROM state_kv WHERE namespace = ? AND owner = ? AND keyspace = ? AND key = ?`),
+		sc.Namespace, sc.Owner, sc.Keyspace, key,
+	).Scan(&version, &expires)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	case expiredAt(expires, now):
+		return 0, nil
 	}
-	return nil
+	return version, nil
+}
+
+// liveKeys counts the scope's keys live at now, inside tx.
+func (k *kvStore) liveKeys(ctx context.Context, tx *sql.Tx, sc statestore.Scope, now int64) (int64, error) {
+	var live int64
+	err := tx.QueryRowContext(ctx, k.s.rebind(
+		`SELECT COUNT(*) FROM state_kv
+		 WHERE namespace = ? AND owner = ? AND keyspace = ?
+		   AND (expires_at IS NULL OR expires_at > ?)`),
+		sc.Namespace, sc.Owner, sc.Keyspace, now,
+	).Scan(&live)
+	return live, err
 }
 
 // Delete implements statestore.KVStore. ifVersion <= 0 deletes unconditionally
 // (idempotent for an absent key); a positive ifVersion is an atomic CAS delete
 // (a live row at exactly that version), so a concurrent writer cannot slip
-// between a version check and the delete.
+// between a version check and the delete. Only deleting a live key is a
+// change: an absent or already-expired one records nothing.
 func (k *kvStore) Delete(ctx context.Context, sc statestore.Scope, key string, ifVersion int64) error {
+	err := k.s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := k.s.lockScope(ctx, tx, sc); err != nil {
+			return err
+		}
+		return k.deleteOn(ctx, tx, sc, key, ifVersion)
+	})
+	if err == nil {
+		k.s.watchers.wake(sc)
+	}
+	return err
+}
+
+// deleteOn is Delete inside tx.
+func (k *kvStore) deleteOn(ctx context.Context, tx *sql.Tx, sc statestore.Scope, key string, ifVersion int64) error {
+	now := nowNanos()
+	query := `DELETE FROM state_kv WHERE namespace = ? AND owner = ? AND keyspace = ? AND key = ?`
+	args := []any{sc.Namespace, sc.Owner, sc.Keyspace, key}
 	if ifVersion > 0 {
-		res, err := k.s.exec(ctx,
-			`DELETE FROM state_kv
-			 WHERE namespace = ? AND owner = ? AND keyspace = ? AND key = ?
-			   AND version = ? AND (expires_at IS NULL OR expires_at > ?)`,
-			sc.Namespace, sc.Owner, sc.Keyspace, key, ifVersion, nowNanos(),
-		)
-		return conflictIfNoRows(res, err)
+		query += ` AND version = ? AND (expires_at IS NULL OR expires_at > ?)`
+		args = append(args, ifVersion, now)
 	}
-	_, err := k.s.exec(ctx,
-		`DELETE FROM state_kv WHERE namespace = ? AND owner = ? AND keyspace = ? AND key = ?`,
-		sc.Namespace, sc.Owner, sc.Keyspace, key,
+	var (
+		version int64
+		expires sql.NullInt64
 	)
-	return err
+	err := tx.QueryRowContext(ctx, k.s.rebind(query+` RETURNING version, expires_at`), args...).Scan(&version, &expires)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && ifVersion > 0:
+		return statestore.ErrVersionConflict
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	case expiredAt(expires, now):
+		return nil
+	}
+	return k.s.recordChange(ctx, tx, sc, statestore.KVEvent{
+		Type: statestore.KVEventDelete, Key: key, Version: version,
+	})
+}
+
+// Txn implements statestore.TxnKV in one transaction. It holds the scope's
+// revisions row (lockScope) before reading anything, so no other write to
+// the scope lands between the compares and the ops, and with a budget it
+// first takes the state_quota row like SetCounted.
+func (k *kvStore) Txn(ctx context.Context, sc statestore.Scope, t statestore.Txn, maxKeys int64) ([]int64, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	var versions []int64
+	err := k.s.inTx(ctx, func(tx *sql.Tx) error {
+		if maxKeys > 0 {
+			if err := k.lockQuota(ctx, tx, sc); err != nil {
+				return err
+			}
+		}
+		if err := k.s.lockScope(ctx, tx, sc); err != nil {
+			return err
+		}
+
+		now := nowNanos()
+		for _, c := range t.Compares {
+			v, err := k.liveVersion(ctx, tx, sc, c.Key, now)
+			if err != nil {
+				return err
+			}
+			if v != c.Version {
+				return statestore.ErrVersionConflict
+			}
+		}
+
+		if maxKeys > 0 {
+			var delta int64
+			creates := false
+			for _, op := range t.Ops {
+				v, err := k.liveVersion(ctx, tx, sc, op.Key, now)
+				if err != nil {
+					return err
+				}
+				switch {
+				case op.Type == statestore.TxnPut && v == 0:
+					delta++
+					creates = true
+				case op.Type == statestore.TxnDelete && v > 0:
+					delta--
+				}
+			}
+			if creates {
+				live, err := k.liveKeys(ctx, tx, sc, now)
+				if err != nil {
+					return err
+				}
+				if live+delta > maxKeys {
+					return statestore.ErrQuotaExceeded
+				}
+			}
+		}
+
+		versions = make([]int64, len(t.Ops))
+		for i, op := range t.Ops {
+			var err error
+			if op.Type == statestore.TxnPut {
+				versions[i], err = k.setOn(ctx, tx, sc, op.Key, op.Value, statestore.SetOptions{TTL: op.TTL})
+			} else {
+				err = k.deleteOn(ctx, tx, sc, op.Key, 0)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	k.s.watchers.wake(sc)
+	return versions, nil
 }
 
 // List implements statestore.KVStore: lexicographic (byte-exact) keys under
@@ -246,3 +384,5 @@ func (k *kvStore) List(ctx context.Context, sc statestore.Scope, prefix string,
 	}
 	return statestore.KeyPage{Keys: keys}, nil
 }
+
+var _ statestore.TxnKV = (*kvStore)(nil)
@@ -85,6 +85,52 @@ func (d Dialect) migrations() []migration {
 				)`,
 			},
 		},
+		{
+			// deferred marks a queued message handed back by Queue.Defer (not
+			// leased since), for QueueStats.Deferred.
+			version: 3,
+			stmts: []string{
+				fmt.Sprintf(`ALTER TABLE state_queue ADD COLUMN deferred %s NOT NULL DEFAULT 0`, i64),
+			},
+		},
+		{
+			// group_key is a message's FIFO group (EnqueueOptions.Group), NULL
+			// when ungrouped; the index serves LeaseGrouped's head check.
+			version: 4,
+			stmts: []string{
+				`ALTER TABLE state_queue ADD COLUMN group_key TEXT`,
+				`CREATE INDEX IF NOT EXISTS idx_state_queue_group ON state_queue (queue, group_key, enqueued_at)`,
+			},
+		},
+		{
+			// state_kv_revisions holds each KV scope's change counter and the
+			// revision its history is compacted through; the write that bumps it
+			// locks the row, so revisions commit in order. state_kv_changes is
+			// the retained history WatchableKV reads (op is "put" or "delete").
+			version: 5,
+			stmts: []string{
+				fmt.Sprintf(`CREATE TABLE IF NOT EXISTS state_kv_revisions (
+					namespace TEXT NOT NULL,
+					owner     TEXT NOT NULL,
+					keyspace  TEXT NOT NULL,
+					revision  %s   NOT NULL,
+					compacted %s   NOT NULL DEFAULT 0,
+					PRIMARY KEY (namespace, owner, keyspace)
+				)`, i64, i64),
+				fmt.Sprintf(`CREATE TABLE IF NOT EXISTS state_kv_changes (
+					namespace  TEXT NOT NULL,
+					owner      TEXT NOT NULL,
+					keyspace   TEXT NOT NULL,
+					revision   %s   NOT NULL,
+					key        TEXT NOT NULL,
+					op         TEXT NOT NULL,
+					value      %s,
+					version    %s   NOT NULL,
+					expires_at %s,
+					PRIMARY KEY (namespace, owner, keyspace, revision)
+				)`, i64, blob, i64, i64),
+			},
+		},
 	}
 }
 
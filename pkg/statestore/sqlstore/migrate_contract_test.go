@@ -119,11 +119,18 @@ func TestMigrationsAreAppendOnly(t *testing.T) {
 		"sqlite": {
 			1: "sha256:2e10ff688eb25ac73abba0094027304608f6524d6272f54d19d7d7f63b53e6a0",
 			2: "sha256:6afe6f1cc5f6aac71cc82657e8962d0a2e92a408abbb896e9e939f7a0f5fc43d",
+			3: "sha256:54d7996fa117b0aa542b5e2468f3d0d1818ab22fdcbfa4b94733fee1a6a5a5e8",
+			4: "sha256:5f4435166bf44c5c101a2c3d1e29056b12d047975fed73a722e8fcf8c4151f31",
+			5: "sha256:f7a15a74032dbc7c3c42c8e9f5dd593c273436fd0bb14955bac50525b42ffb54",
 		},
 		"postgres": {
 			1: "sha256:4c3072401bd6d5d60aa52941edae910fe82a7ebba8ca2ceee526a78e37cfa840",
 			// Identical to sqlite's: migration 2 uses no dialect-specific types.
 			2: "sha256:6afe6f1cc5f6aac71cc82657e8962d0a2e92a408abbb896e9e939f7a0f5fc43d",
+			3: "sha256:868f4fcc34d131c900fb98ee0287de8b4b8b9403b5bb45a5a33a977d0c97dc7c",
+			// Identical to sqlite's: migration 4 uses no dialect-specific types.
+			4: "sha256:5f4435166bf44c5c101a2c3d1e29056b12d047975fed73a722e8fcf8c4151f31",
+			5: "sha256:4db388741423bb2bc10463279653c30c900f11d5d45e2550745b53e4d7c92630",
 		},
 	}
 
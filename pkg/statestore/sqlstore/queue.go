@@ -75,14 +75,17 @@ func (q *queueStore) Enqueue(ctx context.Context, queue string, msg statestore.M
 		}
 	}
 	id := newMessageID(queue)
-	var dedup sql.NullString
+	var dedup, group sql.NullString
 	if o.DedupKey != "" {
 		dedup = sql.NullString{String: o.DedupKey, Valid: true}
 	}
+	if o.Group != "" {
+		group = sql.NullString{String: o.Group, Valid: true}
+	}
 	_, err := q.s.exec(ctx,
-		`INSERT INTO state_queue (id, queue, body, state, visible_at, attempts, epoch, dedup_key, enqueued_at)
-		 VALUES (?, ?, ?, ?, ?, 0, 0, ?, ?)`,
-		id, queue, msg.Body, stQueued, now+o.Delay.Nanoseconds(), dedup, now,
+		`INSERT INTO state_queue (id, queue, body, state, visible_at, attempts, epoch, dedup_key, group_key, enqueued_at)
+		 VALUES (?, ?, ?, ?, ?, 0, 0, ?, ?, ?)`,
+		id, queue, msg.Body, stQueued, now+o.Delay.Nanoseconds(), dedup, group, q.s.enqueueNanos(),
 	)
 	if err != nil {
 		return "", err
@@ -93,6 +96,26 @@ func (q *queueStore) Enqueue(ctx context.Context, queue string, msg statestore.M
 // Lease implements statestore.Queue: reap expirations, then lease up to n visible
 // messages, bumping each lease's epoch.
 func (q *queueStore) Lease(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	return q.lease(ctx, queue, n, leaseFor, "")
+}
+
+// groupHead restricts a lease to ungrouped messages and group heads: a grouped
+// message is a candidate only when no message of its group is leased or queued
+// ahead of it. Because only heads qualify, two concurrent Postgres leasers
+// contend for the same row and SKIP LOCKED lets just one take it.
+const groupHead = ` AND (m.group_key IS NULL OR NOT EXISTS (
+	SELECT 1 FROM state_queue o
+	WHERE o.queue = m.queue AND o.group_key = m.group_key
+	  AND (o.state = '` + stLeased + `' OR (o.state = '` + stQueued + `'
+	       AND (o.enqueued_at < m.enqueued_at OR (o.enqueued_at = m.enqueued_at AND o.id < m.id))))))`
+
+// LeaseGrouped implements statestore.GroupedQueue: Lease, leasing a grouped
+// message only while it heads its group and nothing of the group is leased.
+func (q *queueStore) LeaseGrouped(ctx context.Context, queue string, n int, leaseFor time.Duration) ([]statestore.LeasedMessage, error) {
+	return q.lease(ctx, queue, n, leaseFor, groupHead)
+}
+
+func (q *queueStore) lease(ctx context.Context, queue string, n int, leaseFor time.Duration, filter string) ([]statestore.LeasedMessage, error) {
 	now := nowNanos()
 	var out []statestore.LeasedMessage
 	err := q.s.inTx(ctx, func(tx *sql.Tx) error {
@@ -100,9 +123,9 @@ func (q *queueStore) Lease(ctx context.Context, queue string, n int, leaseFor ti
 			return err
 		}
 		rows, err := tx.QueryContext(ctx, q.s.rebind(
-			`SELECT id, body, epoch, attempts FROM state_queue
-			 WHERE queue = ? AND state = ? AND visible_at <= ? AND attempts < ?
-			 ORDER BY enqueued_at, id LIMIT ?`+q.s.dialect.LockClause),
+			`SELECT m.id, m.body, m.epoch, m.attempts FROM state_queue m
+			 WHERE m.queue = ? AND m.state = ? AND m.visible_at <= ? AND m.attempts < ?`+filter+`
+			 ORDER BY m.enqueued_at, m.id LIMIT ?`+q.s.dialect.LockClause),
 			queue, stQueued, now, q.s.maxAttempts, n,
 		)
 		if err != nil {
@@ -130,7 +153,7 @@ func (q *queueStore) Lease(ctx context.Context, queue string, n int, leaseFor ti
 		_ = rows.Close()
 
 		expiry := now + leaseFor.Nanoseconds()
-		leaseSQL := q.s.rebind(`UPDATE state_queue SET state = ?, epoch = ?, attempts = ?, expiry = ? WHERE id = ?`)
+		leaseSQL := q.s.rebind(`UPDATE state_queue SET state = ?, epoch = ?, attempts = ?, expiry = ?, deferred = 0 WHERE id = ?`)
 		for _, c := range cands {
 			newEpoch := c.epoch + 1
 			newAttempts := c.attempts + 1
@@ -213,6 +236,21 @@ func (q *queueStore) Nack(ctx context.Context, receipt string, retryAfter time.D
 	return nil
 }
 
+// Defer implements statestore.Queue: requeue after retryAfter with the lease's
+// attempt refunded, never dead-lettering.
+func (q *queueStore) Defer(ctx context.Context, receipt string, retryAfter time.Duration) error {
+	id, epoch, ok := statestore.DecodeReceipt(receipt)
+	if !ok {
+		return statestore.ErrInvalidReceipt
+	}
+	res, err := q.s.exec(ctx,
+		`UPDATE state_queue SET state = ?, visible_at = ?, attempts = attempts - 1, deferred = 1
+		 WHERE id = ? AND state = ? AND epoch = ?`,
+		stQueued, nowNanos()+retryAfter.Nanoseconds(), id, stLeased, epoch,
+	)
+	return settleResult(res, err)
+}
+
 // Kill implements statestore.Queue: dead-letter the current delivery immediately.
 func (q *queueStore) Kill(ctx context.Context, receipt string, reason string) error {
 	id, epoch, ok := statestore.DecodeReceipt(receipt)
@@ -329,22 +367,23 @@ func (q *queueStore) Purge(ctx context.Context, queue string) (int64, error) {
 func (q *queueStore) Stats(ctx context.Context, queue string) (statestore.QueueStats, error) {
 	now := nowNanos()
 	var (
-		visible, leased, dead int64
-		oldest                sql.NullInt64
+		visible, leased, dead, deferred int64
+		oldest                          sql.NullInt64
 	)
 	err := q.s.queryRow(ctx,
 		`SELECT
 		   COALESCE(SUM(CASE WHEN state = ? AND visible_at <= ? THEN 1 ELSE 0 END), 0),
 		   COALESCE(SUM(CASE WHEN state = ? THEN 1 ELSE 0 END), 0),
 		   COALESCE(SUM(CASE WHEN state = ? THEN 1 ELSE 0 END), 0),
+		   COALESCE(SUM(CASE WHEN state = ? AND visible_at > ? AND deferred = 1 THEN 1 ELSE 0 END), 0),
 		   MIN(CASE WHEN state = ? AND visible_at <= ? THEN enqueued_at END)
 		 FROM state_queue WHERE queue = ?`,
-		stQueued, now, stLeased, stDead, stQueued, now, queue,
-	).Scan(&visible, &leased, &dead, &oldest)
+		stQueued, now, stLeased, stDead, stQueued, now, stQueued, now, queue,
+	).Scan(&visible, &leased, &dead, &deferred, &oldest)
 	if err != nil {
 		return statestore.QueueStats{}, err
 	}
-	st := statestore.QueueStats{Visible: visible, Leased: leased, Dead: dead}
+	st := statestore.QueueStats{Visible: visible, Leased: leased, Dead: dead, Deferred: deferred}
 	if oldest.Valid {
 		if age := now - oldest.Int64; age > 0 {
 			st.OldestVisibleAge = time.Duration(age)
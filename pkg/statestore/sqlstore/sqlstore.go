@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/fission/fission/pkg/statestore"
 )
@@ -39,6 +40,14 @@ type Dialect struct {
 	// AdvisoryLock, if non-nil, is run before migrations to serialize concurrent
 	// starters (Postgres pg_advisory_xact_lock); nil on single-writer SQLite.
 	AdvisoryLock func(ctx context.Context, tx *sql.Tx) error
+	// Notify, if set, is run in each KV write's transaction with the changed
+	// scope as its one argument, so watches in other processes wake when it
+	// commits (Postgres pg_notify); empty on single-process SQLite.
+	Notify string
+	// Listen, if non-nil, subscribes a connection from db to the Notify
+	// channel until ctx is done or the connection fails, calling listening
+	// once subscribed and notify with each payload; nil on SQLite.
+	Listen func(ctx context.Context, db *sql.DB, listening func(), notify func(payload string)) error
 }
 
 // Store is the shared SQL-backed Capabilities.
@@ -46,18 +55,27 @@ type Store struct {
 	db          *sql.DB
 	dialect     Dialect
 	maxAttempts int
+	lastEnqueue atomic.Int64 // see enqueueNanos
+	watchers    *watchHub
+	spoolDir    string // see SetSpoolDir
 }
 
 // Open runs migrations and returns a Store over db. Callers (the postgres/sqlite
 // driver packages) own opening and configuring db (pool size, pragmas).
 func Open(ctx context.Context, db *sql.DB, d Dialect) (*Store, error) {
-	s := &Store{db: db, dialect: d, maxAttempts: statestore.DefaultMaxAttempts}
+	s := &Store{db: db, dialect: d, maxAttempts: statestore.DefaultMaxAttempts, watchers: newWatchHub()}
 	if err := s.migrate(ctx); err != nil {
 		return nil, fmt.Errorf("statestore/sqlstore: migrate: %w", err)
 	}
 	return s, nil
 }
 
+// SetSpoolDir sets the directory a SQLite export spools the records it reads
+// to (see Export). Empty spools them in memory.
+func (s *Store) SetSpoolDir(dir string) {
+	s.spoolDir = dir
+}
+
 // SetMaxAttempts overrides the queue attempt budget (for tests / per-deployment
 // tuning). n <= 0 is ignored.
 func (s *Store) SetMaxAttempts(n int) {
@@ -73,8 +91,11 @@ func (s *Store) Queue() (statestore.Queue, error)       { return &queueStore{s},
 // Ping reports whether the database is reachable.
 func (s *Store) Ping(ctx context.Context) error { return s.db.PingContext(ctx) }
 
-// Close closes the underlying pool.
-func (s *Store) Close() error { return s.db.Close() }
+// Close ends running watches and closes the underlying pool.
+func (s *Store) Close() error {
+	s.watchers.close()
+	return s.db.Close()
+}
 
 // rebind translates the ?-placeholders the shared queries are written with into
 // the dialect's style ($1, $2, … on Postgres; unchanged on SQLite).
@@ -0,0 +1,320 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// compactSlack is how far a scope's history may grow past
+	// KVWatchHistory before a write compacts it, so compaction runs once per
+	// compactSlack writes rather than on every one.
+	compactSlack = 100
+	// watchBatch bounds the changes one watch query reads.
+	watchBatch = 256
+	// watchPoll is how often a watch re-reads with no wake-up: a backstop for
+	// a notification lost while the listener reconnects.
+	watchPoll = 10 * time.Second
+)
+
+// lockScope locks sc's state_kv_revisions row until tx ends, creating it at
+// revision 0. Every KV write takes it before touching state_kv (after
+// state_quota when counted), so all writers lock in one order and cannot
+// deadlock, a scope's writes serialize, and a Txn's compares and writes have
+// no other writer between them.
+func (s *Store) lockScope(ctx context.Context, tx *sql.Tx, sc statestore.Scope) error {
+	_, err := s.execOn(ctx, tx,
+		`INSERT INTO state_kv_revisions (namespace, owner, keyspace, revision) VALUES (?, ?, ?, 0)
+		 ON CONFLICT (namespace, owner, keyspace) DO UPDATE SET revision = state_kv_revisions.revision`,
+		sc.Namespace, sc.Owner, sc.Keyspace,
+	)
+	return err
+}
+
+// recordChange assigns ev the scope's next revision and stores it in the
+// change history, inside the write's transaction tx, which holds lockScope:
+// so revisions commit in order and a watch that reads revision N has every
+// change up to N visible. Every compactSlack changes past KVWatchHistory the
+// write also drops the oldest history, and with a Notify dialect it signals
+// the change to watches in other processes.
+func (s *Store) recordChange(ctx context.Context, tx *sql.Tx, sc statestore.Scope, ev statestore.KVEvent) error {
+	var rev, compacted int64
+	if err := tx.QueryRowContext(ctx, s.rebind(
+		`UPDATE state_kv_revisions SET revision = revision + 1
+		 WHERE namespace = ? AND owner = ? AND keyspace = ?
+		 RETURNING revision, compacted`),
+		sc.Namespace, sc.Owner, sc.Keyspace,
+	).Scan(&rev, &compacted); err != nil {
+		return err
+	}
+	expires := nullNanos(ev.ExpiresAt.UnixNano(), !ev.ExpiresAt.IsZero())
+	if _, err := s.execOn(ctx, tx,
+		`INSERT INTO state_kv_changes (namespace, owner, keyspace, revision, key, op, value, version, expires_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sc.Namespace, sc.Owner, sc.Keyspace, rev, ev.Key, string(ev.Type), ev.Value, ev.Version, expires,
+	); err != nil {
+		return err
+	}
+	if rev-compacted > statestore.KVWatchHistory+compactSlack {
+		compacted = rev - statestore.KVWatchHistory
+		if _, err := s.execOn(ctx, tx,
+			`DELETE FROM state_kv_changes WHERE namespace = ? AND owner = ? AND keyspace = ? AND revision <= ?`,
+			sc.Namespace, sc.Owner, sc.Keyspace, compacted,
+		); err != nil {
+			return err
+		}
+		if _, err := s.execOn(ctx, tx,
+			`UPDATE state_kv_revisions SET compacted = ? WHERE namespace = ? AND owner = ? AND keyspace = ?`,
+			compacted, sc.Namespace, sc.Owner, sc.Keyspace,
+		); err != nil {
+			return err
+		}
+	}
+	if s.dialect.Notify != "" {
+		if _, err := s.execOn(ctx, tx, s.dialect.Notify, notifyPayload(sc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyPayload encodes sc for the Notify channel.
+func notifyPayload(sc statestore.Scope) string {
+	b, _ := json.Marshal([3]string{sc.Namespace, sc.Owner, sc.Keyspace})
+	return string(b)
+}
+
+// Revision implements statestore.WatchableKV.
+func (k *kvStore) Revision(ctx context.Context, sc statestore.Scope) (int64, error) {
+	var rev int64
+	err := k.s.queryRow(ctx,
+		`SELECT revision FROM state_kv_revisions WHERE namespace = ? AND owner = ? AND keyspace = ?`,
+		sc.Namespace, sc.Owner, sc.Keyspace,
+	).Scan(&rev)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return rev, err
+}
+
+// Watch implements statestore.WatchableKV. It re-reads the history whenever
+// a write to the scope commits: writes through this Store wake it directly,
+// and with a Listen dialect so do writes from other processes.
+func (k *kvStore) Watch(ctx context.Context, sc statestore.Scope, prefix string, fromRevision int64,
+	fn func([]statestore.KVEvent, int64) error) error {
+	k.s.listen()
+	established := false
+	for {
+		// Subscribe before reading, so a change that commits after the read
+		// still wakes this watch.
+		wake, ok := k.s.watchers.wait(sc)
+		if !ok {
+			return statestore.ErrClosed
+		}
+		events, reached, more, err := k.changesSince(ctx, sc, prefix, fromRevision)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if !established || reached > fromRevision {
+			established = true
+			fromRevision = max(fromRevision, reached)
+			if err := fn(events, fromRevision); err != nil {
+				return err
+			}
+		}
+		if more {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		case <-time.After(watchPoll):
+		}
+	}
+}
+
+// changesSince reads up to watchBatch of scope's changes under prefix after
+// from. It returns them, the revision they reach, and whether further
+// changes remain past it.
+func (k *kvStore) changesSince(ctx context.Context, sc statestore.Scope, prefix string, from int64) ([]statestore.KVEvent, int64, bool, error) {
+	head, compacted, err := k.revisionRow(ctx, sc)
+	switch {
+	case err != nil:
+		return nil, 0, false, err
+	case from < compacted:
+		return nil, 0, false, statestore.ErrCompacted
+	case head <= from:
+		return nil, head, false, nil
+	}
+
+	rows, err := k.s.query(ctx,
+		`SELECT revision, key, op, value, version, expires_at FROM state_kv_changes
+		 WHERE namespace = ? AND owner = ? AND keyspace = ? AND revision > ? AND revision <= ?
+		   AND key LIKE ? ESCAPE '\'
+		 ORDER BY revision LIMIT ?`,
+		sc.Namespace, sc.Owner, sc.Keyspace, from, head, escapeLikePrefix(prefix), watchBatch,
+	)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer func() { _ = rows.Close() }()
+	var events []statestore.KVEvent
+	for rows.Next() {
+		var (
+			ev      statestore.KVEvent
+			op      string
+			expires sql.NullInt64
+		)
+		if err := rows.Scan(&ev.Revision, &ev.Key, &op, &ev.Value, &ev.Version, &expires); err != nil {
+			return nil, 0, false, err
+		}
+		ev.Type, ev.ExpiresAt = statestore.KVEventType(op), nullableTime(expires)
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, false, err
+	}
+
+	// A write may have compacted past from while the rows were read, taking
+	// some of them with it; the compaction commits with its new mark, so
+	// re-reading the mark tells.
+	if _, compacted, err = k.revisionRow(ctx, sc); err != nil {
+		return nil, 0, false, err
+	}
+	if from < compacted {
+		return nil, 0, false, statestore.ErrCompacted
+	}
+	if len(events) == watchBatch {
+		return events, events[len(events)-1].Revision, true, nil
+	}
+	return events, head, false, nil
+}
+
+// revisionRow reads scope's revision and compaction mark (both 0 before its
+// first change).
+func (k *kvStore) revisionRow(ctx context.Context, sc statestore.Scope) (int64, int64, error) {
+	var rev, compacted int64
+	err := k.s.queryRow(ctx,
+		`SELECT revision, compacted FROM state_kv_revisions WHERE namespace = ? AND owner = ? AND keyspace = ?`,
+		sc.Namespace, sc.Owner, sc.Keyspace,
+	).Scan(&rev, &compacted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, nil
+	}
+	return rev, compacted, err
+}
+
+// watchHub wakes a Store's watches when their scope changes. A waiter gets
+// its scope's channel, which is closed and forgotten on the next change.
+type watchHub struct {
+	mu     sync.Mutex
+	waits  map[statestore.Scope]chan struct{}
+	closed bool
+	cancel context.CancelFunc // stops the listener; nil until it starts
+	done   chan struct{}      // closed when the listener has stopped
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{waits: make(map[statestore.Scope]chan struct{})}
+}
+
+// wait returns a channel closed on sc's next change, or false once the Store
+// is closed.
+func (h *watchHub) wait(sc statestore.Scope) (<-chan struct{}, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	ch, ok := h.waits[sc]
+	if !ok {
+		ch = make(chan struct{})
+		h.waits[sc] = ch
+	}
+	return ch, true
+}
+
+// wake wakes sc's waiters.
+func (h *watchHub) wake(sc statestore.Scope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ch, ok := h.waits[sc]; ok {
+		close(ch)
+		delete(h.waits, sc)
+	}
+}
+
+// wakeAll wakes every waiter, e.g. when notifications may have been missed.
+func (h *watchHub) wakeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sc, ch := range h.waits {
+		close(ch)
+		delete(h.waits, sc)
+	}
+}
+
+// notified wakes the scope a Notify payload names.
+func (h *watchHub) notified(payload string) {
+	var sc [3]string
+	if err := json.Unmarshal([]byte(payload), &sc); err != nil {
+		h.wakeAll()
+		return
+	}
+	h.wake(statestore.Scope{Namespace: sc[0], Owner: sc[1], Keyspace: sc[2]})
+}
+
+// listen starts the dialect's listener for this Store's first watch. It
+// holds one pooled connection and reconnects until the Store closes; every
+// (re)subscription wakes all watches, since notifications sent while it was
+// down are lost.
+func (s *Store) listen() {
+	h := s.watchers
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s.dialect.Listen == nil || h.closed || h.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel, h.done = cancel, make(chan struct{})
+	go func() {
+		defer close(h.done)
+		for {
+			_ = s.dialect.Listen(ctx, s.db, h.wakeAll, h.notified)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+}
+
+// close ends the watches and stops the listener.
+func (h *watchHub) close() {
+	h.mu.Lock()
+	h.closed = true
+	cancel, done := h.cancel, h.done
+	h.mu.Unlock()
+	h.wakeAll()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+var _ statestore.WatchableKV = (*kvStore)(nil)
@@ -3,18 +3,28 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package statestoresvc implements the fission-bundle --statestorePort subsystem:
-// the embedded-mode statestore. A single replica owns a PVC-backed SQLite file
-// and serves the RFC-0021 capability API (pkg/statestore/httpapi) over a
-// ClusterIP-only Service, authenticated with the ServiceStatestore HMAC key like
-// the other internal listeners. It is deliberately single-writer and not HA.
+// the embedded-mode statestore. It serves the RFC-0021 capability API
+// (pkg/statestore/httpapi) over a ClusterIP-only Service, authenticated with the
+// ServiceStatestore HMAC key like the other internal listeners, from one of two
+// engines chosen by STATESTORE_DRIVER:
+//
+//   - sqlite (the default): a single replica owns a PVC-backed SQLite file. It
+//     is deliberately single-writer and not HA.
+//   - raft: every replica of a StatefulSet keeps a bbolt file on its own PVC and
+//     the replicas replicate it with Raft (pkg/statestore/raft), so the store
+//     survives the loss of a minority of pods. Any replica serves the API. The
+//     replicas' Raft transport is signed with the ServiceStatestorePeer key.
 package statestoresvc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	"golang.org/x/sync/errgroup"
@@ -23,6 +33,7 @@ import (
 	"github.com/fission/fission/pkg/crd"
 	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/statestore/httpapi"
+	"github.com/fission/fission/pkg/statestore/raft"
 
 	// Register the embedded SQLite driver so statestore.Open(sqlite) resolves.
 	_ "github.com/fission/fission/pkg/statestore/sqlite"
@@ -37,7 +48,7 @@ type Options struct {
 	// Listener optionally pre-binds the listener.
 	Listener net.Listener
 	// Caps optionally injects a pre-opened Capabilities (tests). When nil, Start
-	// opens the embedded SQLite store at STATESTORE_DSN.
+	// opens the STATESTORE_DRIVER engine at STATESTORE_DSN.
 	Caps statestore.Capabilities
 }
 
@@ -48,15 +59,17 @@ func Start(ctx context.Context, _ crd.ClientGeneratorInterface, logger logr.Logg
 	mgr *errgroup.Group, opts Options) error {
 	logger = logger.WithName("statestore")
 
+	// HMAC-verify the capability API on the internal listener (empty master =
+	// pass-through, matching the router-internal convention). /healthz and /readyz
+	// stay unauthenticated so the kubelet can probe them.
+	master := []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET"))
+	masterOld := []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET_OLD"))
+
 	caps := opts.Caps
 	if caps == nil {
-		dsn := os.Getenv("STATESTORE_DSN")
-		if dsn == "" {
-			return fmt.Errorf("statestore: STATESTORE_DSN is required in embedded mode (the SQLite file path)")
-		}
-		opened, err := statestore.Open(ctx, statestore.Config{Driver: "sqlite", DSN: dsn})
+		opened, err := openEmbedded(ctx, logger, master, masterOld)
 		if err != nil {
-			return fmt.Errorf("statestore: opening embedded store: %w", err)
+			return err
 		}
 		caps = opened
 	}
@@ -70,11 +83,6 @@ func Start(ctx context.Context, _ crd.ClientGeneratorInterface, logger logr.Logg
 
 	handler := httpapi.NewHandler(caps)
 
-	// HMAC-verify the capability API on the internal listener (empty master =
-	// pass-through, matching the router-internal convention). /healthz and /readyz
-	// stay unauthenticated so the kubelet can probe them.
-	master := []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET"))
-	masterOld := []byte(os.Getenv("FISSION_INTERNAL_AUTH_SECRET_OLD"))
 	if len(master) > 0 {
 		verifier := hmacauth.ServiceVerifier(master, masterOld, hmacauth.ServiceStatestore, hmacauth.VerifierOpts{
 			SkewSec:      60,
@@ -98,3 +106,52 @@ func Start(ctx context.Context, _ crd.ClientGeneratorInterface, logger logr.Logg
 	})
 	return nil
 }
+
+// openEmbedded opens the STATESTORE_DRIVER engine at STATESTORE_DSN.
+func openEmbedded(ctx context.Context, logger logr.Logger, master, masterOld []byte) (statestore.Capabilities, error) {
+	dsn := os.Getenv("STATESTORE_DSN")
+	driver := os.Getenv("STATESTORE_DRIVER")
+	switch driver {
+	case "", "sqlite":
+		if dsn == "" {
+			return nil, fmt.Errorf("statestore: STATESTORE_DSN is required in embedded mode (the SQLite file path)")
+		}
+		caps, err := statestore.Open(ctx, statestore.Config{Driver: "sqlite", DSN: dsn})
+		if err != nil {
+			return nil, fmt.Errorf("statestore: opening embedded store: %w", err)
+		}
+		return caps, nil
+	case "raft":
+		if dsn == "" {
+			return nil, fmt.Errorf("statestore: STATESTORE_DSN is required in embedded mode (the raft:// replica DSN)")
+		}
+		// Replicas refuse an unauthenticated peer transport (the DSN names
+		// peers but there is no secret to sign with); a single replica has
+		// no peers and starts either way.
+		ropts := []raft.Option{raft.WithLogger(logger.WithName("raft"))}
+		if len(master) > 0 {
+			// Peer requests carry Raft snapshots, so the verifier spools large
+			// bodies to disk rather than holding them in memory.
+			verify := hmacauth.ServiceVerifier(master, masterOld, hmacauth.ServiceStatestorePeer, hmacauth.VerifierOpts{
+				SkewSec:             60,
+				MaxBodyBytes:        raft.MaxPeerRequestBytes,
+				SpoolThresholdBytes: 1 << 20,
+				Logger:              logger,
+			})
+			sign := func(rt http.RoundTripper) http.RoundTripper {
+				return hmacauth.ServiceSigner(master, hmacauth.ServiceStatestorePeer, rt, time.Now)
+			}
+			ropts = append(ropts, raft.WithPeerAuth(verify, sign))
+		}
+		caps, err := raft.New(ctx, dsn, ropts...)
+		if errors.Is(err, raft.ErrUnauthenticatedPeers) {
+			return nil, fmt.Errorf("statestore: FISSION_INTERNAL_AUTH_SECRET is required for replicated raft: %w", err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("statestore: opening embedded store: %w", err)
+		}
+		return caps, nil
+	default:
+		return nil, fmt.Errorf("statestore: unsupported STATESTORE_DRIVER %q in embedded mode (want sqlite or raft)", driver)
+	}
+}
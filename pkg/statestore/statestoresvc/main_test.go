@@ -19,6 +19,7 @@ import (
 	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/statestore/client"
 	"github.com/fission/fission/pkg/statestore/memory"
+	"github.com/fission/fission/pkg/statestore/raft"
 	"github.com/fission/fission/pkg/statestore/statestoresvc"
 )
 
@@ -68,3 +69,16 @@ func TestStatestoreHead_HMACRoundTrip(t *testing.T) {
 	// ...but the health probe stays reachable unauthenticated.
 	require.NoError(t, unsigned.Ping(ctx))
 }
+
+// TestStatestoreHead_RaftPeersNeedSecret: replicated raft refuses to start
+// its peer transport without the internal auth secret.
+func TestStatestoreHead_RaftPeersNeedSecret(t *testing.T) {
+	t.Setenv("FISSION_INTERNAL_AUTH_SECRET", "")
+	t.Setenv("STATESTORE_DRIVER", "raft")
+	t.Setenv("STATESTORE_DSN", "raft://"+t.TempDir()+"?self=s-0&listen=127.0.0.1:0&peers=s-0=http://127.0.0.1:1,s-1=http://127.0.0.1:2")
+
+	g, _ := errgroup.WithContext(t.Context())
+	err := statestoresvc.Start(t.Context(), nil, logr.Discard(), g, statestoresvc.Options{Port: 0})
+	require.ErrorIs(t, err, raft.ErrUnauthenticatedPeers)
+	require.ErrorContains(t, err, "FISSION_INTERNAL_AUTH_SECRET")
+}
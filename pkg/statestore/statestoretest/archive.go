@@ -0,0 +1,260 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestoretest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var archiveOtherScope = statestore.Scope{Namespace: "other", Owner: "function/conf", Keyspace: "ks"}
+
+// RunArchiveRoundTrip checks that an archive migrates across drivers: it
+// fills a store from the first factory, then loads the archive of each store
+// into a store from the next factory, and checks every store restores the
+// first one's state and exports the same archive. Every factory's store must
+// implement statestore.Archiver.
+func RunArchiveRoundTrip(t *testing.T, factories ...Factory) {
+	t.Helper()
+	require.NotEmpty(t, factories)
+	src := archiver(t, factories[0](t))
+	populateArchive(t, src)
+	var buf bytes.Buffer
+	_, err := statestore.WriteArchive(t.Context(), &buf, src, statestore.ArchiveFilter{})
+	require.NoError(t, err)
+	want := archiveRecords(t, src, statestore.ArchiveFilter{})
+	for _, newCaps := range factories[1:] {
+		caps := newCaps(t)
+		dst := archiver(t, caps)
+		_, _, err := statestore.ReadArchive(t.Context(), &buf, dst)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, want, archiveRecords(t, dst, statestore.ArchiveFilter{}))
+		buf.Reset()
+		_, err = statestore.WriteArchive(t.Context(), &buf, dst, statestore.ArchiveFilter{})
+		require.NoError(t, err)
+		checkArchiveRestored(t, caps)
+	}
+}
+
+// runArchive checks the statestore.Archiver contract. Every in-repo driver
+// implements it.
+func runArchive(t *testing.T, newCaps Factory) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		RunArchiveRoundTrip(t, newCaps, newCaps)
+	})
+
+	t.Run("StatsAndHeader", func(t *testing.T) {
+		a := archiver(t, newCaps(t))
+		populateArchive(t, a)
+		var buf bytes.Buffer
+		st, err := statestore.WriteArchive(t.Context(), &buf, a, statestore.ArchiveFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, statestore.ArchiveStats{Keys: 3, Streams: 4, Events: 4, Messages: 5}, st)
+
+		dst := archiver(t, newCaps(t))
+		h, got, err := statestore.ReadArchive(t.Context(), &buf, dst)
+		require.NoError(t, err)
+		assert.Equal(t, statestore.ArchiveFormat, h.Format)
+		assert.Equal(t, statestore.ArchiveVersion, h.Version)
+		assert.Equal(t, st, got)
+	})
+
+	t.Run("NamespaceFilter", func(t *testing.T) {
+		a := archiver(t, newCaps(t))
+		populateArchive(t, a)
+		recs := archiveRecords(t, a, statestore.ArchiveFilter{Namespace: "ns", Streams: []string{"wfrun/uid-1"}})
+		var keys, streams []string
+		for _, r := range recs {
+			switch {
+			case r.KV != nil:
+				assert.Equal(t, confScope, r.KV.Scope)
+				keys = append(keys, r.KV.Key)
+			case r.Stream != nil:
+				streams = append(streams, r.Stream.Stream)
+			case r.Message != nil:
+				t.Errorf("a namespace's export has message %q", r.Message.ID)
+			}
+		}
+		assert.ElementsMatch(t, []string{"a", "ttl"}, keys)
+		assert.ElementsMatch(t, []string{"topic/ns/t", "topic/ns/empty", "wfrun/uid-1"}, streams)
+	})
+
+	t.Run("InvalidImportChangesNothing", func(t *testing.T) {
+		caps := newCaps(t)
+		a := archiver(t, caps)
+		err := a.Import(t.Context(), []statestore.ArchiveRecord{
+			{KV: &statestore.ArchivedKey{Scope: confScope, Key: "k", Value: []byte("v"), Version: 1}},
+			{Stream: &statestore.ArchivedStream{Stream: "topic/ns/bad", Head: 1}},
+			{Event: &statestore.ArchivedEvent{Stream: "topic/ns/bad", Seq: 2, Type: "t", Payload: []byte("p")}},
+		})
+		require.ErrorIs(t, err, statestore.ErrInvalidArchive, "an event above its stream's head")
+		err = a.Import(t.Context(), []statestore.ArchiveRecord{{}})
+		require.ErrorIs(t, err, statestore.ErrInvalidArchive, "an empty record")
+
+		kv, err := caps.KV()
+		require.NoError(t, err)
+		_, err = kv.Get(t.Context(), confScope, "k")
+		require.ErrorIs(t, err, statestore.ErrNotFound)
+		el, err := caps.EventLog()
+		require.NoError(t, err)
+		head, err := el.Head(t.Context(), "topic/ns/bad")
+		require.NoError(t, err)
+		assert.Zero(t, head)
+	})
+}
+
+func archiver(t *testing.T, caps statestore.Capabilities) statestore.Archiver {
+	t.Helper()
+	a, ok := caps.(statestore.Archiver)
+	require.True(t, ok, "driver must implement statestore.Archiver")
+	return a
+}
+
+// archiveRecords exports what f selects from a, normalized for comparison
+// across drivers: message ids are the driver's and dropped, and times are
+// compared to the millisecond, the coarsest precision a driver stores.
+func archiveRecords(t *testing.T, a statestore.Archiver, f statestore.ArchiveFilter) []statestore.ArchiveRecord {
+	t.Helper()
+	norm := func(tm time.Time) time.Time {
+		if tm.IsZero() {
+			return tm
+		}
+		return tm.UTC().Truncate(time.Millisecond)
+	}
+	var recs []statestore.ArchiveRecord
+	err := a.Export(t.Context(), f, func(r statestore.ArchiveRecord) error {
+		switch {
+		case r.KV != nil:
+			r.KV.ExpiresAt = norm(r.KV.ExpiresAt)
+		case r.Event != nil:
+			r.Event.At = norm(r.Event.At)
+		case r.Message != nil:
+			r.Message.ID = ""
+			r.Message.VisibleAt = norm(r.Message.VisibleAt)
+			r.Message.EnqueuedAt = norm(r.Message.EnqueuedAt)
+			r.Message.DiedAt = norm(r.Message.DiedAt)
+		}
+		recs = append(recs, r)
+		return nil
+	})
+	require.NoError(t, err)
+	return recs
+}
+
+// populateArchive fills a store with some of everything an archive carries:
+// versioned, expiring and deleted keys in two namespaces, trimmed and
+// emptied streams, and queued, delayed, deferred, leased and dead messages.
+func populateArchive(t *testing.T, a statestore.Archiver) {
+	t.Helper()
+	ctx := t.Context()
+	caps := a.(statestore.Capabilities)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	require.NoError(t, kv.Set(ctx, confScope, "a", []byte("a1"), statestore.SetOptions{}))
+	require.NoError(t, kv.Set(ctx, confScope, "a", []byte("a2"), statestore.SetOptions{}))
+	require.NoError(t, kv.Set(ctx, confScope, "ttl", []byte("t"), statestore.SetOptions{TTL: time.Hour}))
+	require.NoError(t, kv.Set(ctx, confScope, "gone", []byte("g"), statestore.SetOptions{}))
+	require.NoError(t, kv.Delete(ctx, confScope, "gone", 0))
+	require.NoError(t, kv.Set(ctx, archiveOtherScope, "b", []byte("b"), statestore.SetOptions{}))
+
+	el, err := caps.EventLog()
+	require.NoError(t, err)
+	events := func(n int) []statestore.Event {
+		evs := make([]statestore.Event, n)
+		for i := range evs {
+			evs[i] = statestore.Event{Type: "e", Payload: []byte{byte('0' + i)}}
+		}
+		return evs
+	}
+	_, err = el.Append(ctx, "topic/ns/t", 0, events(3))
+	require.NoError(t, err)
+	require.NoError(t, el.Trim(ctx, "topic/ns/t", 2))
+	_, err = el.Append(ctx, "topic/ns/empty", 0, events(2))
+	require.NoError(t, err)
+	require.NoError(t, el.Trim(ctx, "topic/ns/empty", 3))
+	_, err = el.Append(ctx, "wfrun/uid-1", 0, events(1))
+	require.NoError(t, err)
+	_, err = el.Append(ctx, "topic/other/t", 0, events(1))
+	require.NoError(t, err)
+
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	enqueue := func(body string, o statestore.EnqueueOptions) {
+		_, err := q.Enqueue(ctx, "aq", statestore.Message{Body: []byte(body)}, o)
+		require.NoError(t, err)
+	}
+	enqueue("dead", statestore.EnqueueOptions{DedupKey: "d"})
+	l, err := q.Lease(ctx, "aq", 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	require.NoError(t, q.Kill(ctx, l[0].Receipt, "boom"))
+	enqueue("leased", statestore.EnqueueOptions{Group: "g"})
+	enqueue("deferred", statestore.EnqueueOptions{Group: "g"})
+	enqueue("delayed", statestore.EnqueueOptions{Delay: time.Hour})
+	enqueue("plain", statestore.EnqueueOptions{})
+	l, err = q.Lease(ctx, "aq", 2, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, l, 2)
+	require.Equal(t, "deferred", string(l[1].Body))
+	require.NoError(t, q.Defer(ctx, l[1].Receipt, time.Hour))
+}
+
+// checkArchiveRestored checks a store loaded from populateArchive's archive
+// behaves as the original: versions and heads continue where they were, and
+// the messages are in the states they were archived in.
+func checkArchiveRestored(t *testing.T, caps statestore.Capabilities) {
+	t.Helper()
+	ctx := t.Context()
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	v, err := kv.Get(ctx, confScope, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a2", string(v.Data))
+	assert.EqualValues(t, 2, v.Version)
+	require.NoError(t, kv.Set(ctx, confScope, "a", []byte("a3"), statestore.SetOptions{IfVersion: new(int64(2))}))
+	_, err = kv.Get(ctx, confScope, "ttl")
+	require.NoError(t, err)
+	_, err = kv.Get(ctx, confScope, "gone")
+	require.ErrorIs(t, err, statestore.ErrNotFound)
+
+	el, err := caps.EventLog()
+	require.NoError(t, err)
+	evs, err := el.Read(ctx, "topic/ns/t", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, evs, 2)
+	assert.EqualValues(t, 2, evs[0].Seq)
+	assert.Equal(t, "1", string(evs[0].Payload))
+	head, err := el.Append(ctx, "topic/ns/t", 3, []statestore.Event{{Type: "e"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, head)
+	head, err = el.Head(ctx, "topic/ns/empty")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, head, "a stream trimmed empty keeps its head")
+
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	st, err := q.Stats(ctx, "aq")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, st.Visible)
+	assert.Zero(t, st.Leased, "a lease does not survive a restore")
+	assert.EqualValues(t, 1, st.Deferred)
+	assert.EqualValues(t, 1, st.Dead)
+	dead, err := q.DeadLetters(ctx, "aq", statestore.Page{})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "dead", string(dead[0].Body))
+	assert.Equal(t, "boom", dead[0].Reason)
+	l, err := q.Lease(ctx, "aq", 5, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	assert.Equal(t, "plain", string(l[0].Body))
+	assert.Equal(t, 1, l[0].Attempts)
+}
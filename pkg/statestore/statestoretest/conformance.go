@@ -40,8 +40,10 @@ var confScope = statestore.Scope{Namespace: "ns", Owner: "function/conf", Keyspa
 func RunConformance(t *testing.T, newCaps Factory) {
 	t.Helper()
 	t.Run("KV", func(t *testing.T) { runKV(t, newCaps) })
+	t.Run("KVTxn", func(t *testing.T) { runTxn(t, newCaps) })
 	t.Run("EventLog", func(t *testing.T) { runEventLog(t, newCaps) })
 	t.Run("Queue", func(t *testing.T) { runQueue(t, newCaps) })
+	t.Run("Archive", func(t *testing.T) { runArchive(t, newCaps) })
 }
 
 // RunTimingConformance checks the time-dependent behavior (K2 exact-on-read TTL,
@@ -462,6 +464,33 @@ func runQueue(t *testing.T, newCaps Factory) {
 		assert.Equal(t, 1, l[0].Attempts, "attempts reset on redrive")
 	})
 
+	t.Run("DeferRefundsAttempt", func(t *testing.T) {
+		q := queueOrSkip(t, newCaps)
+		ctx := t.Context()
+		const dq = "deferq"
+		_, err := q.Enqueue(ctx, dq, statestore.Message{Body: []byte("m")}, statestore.EnqueueOptions{})
+		require.NoError(t, err)
+		// Deferring more times than any attempt budget never dead-letters, and
+		// every lease reports the same attempt.
+		for range 10 {
+			l, lerr := q.Lease(ctx, dq, 1, time.Minute)
+			require.NoError(t, lerr)
+			require.Len(t, l, 1)
+			assert.Equal(t, 1, l[0].Attempts)
+			require.NoError(t, q.Defer(ctx, l[0].Receipt, 0))
+			require.ErrorIs(t, q.Defer(ctx, l[0].Receipt, 0), statestore.ErrInvalidReceipt, "a settled receipt is stale")
+		}
+		l, err := q.Lease(ctx, dq, 1, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, l, 1)
+		require.NoError(t, q.Defer(ctx, l[0].Receipt, time.Hour))
+		st, err := q.Stats(ctx, dq)
+		require.NoError(t, err)
+		assert.Zero(t, st.Visible)
+		assert.EqualValues(t, 1, st.Deferred)
+		assert.Zero(t, st.Dead)
+	})
+
 	t.Run("PurgeDeadLetters", func(t *testing.T) {
 		q := queueOrSkip(t, newCaps)
 		ctx := t.Context()
@@ -541,6 +570,81 @@ func runQueue(t *testing.T, newCaps Factory) {
 		assert.EqualValues(t, 1, st.Leased)
 		assert.Zero(t, st.Dead)
 	})
+
+	t.Run("GroupedLease", func(t *testing.T) {
+		q := queueOrSkip(t, newCaps)
+		ctx := t.Context()
+		// statestore.GroupedQueue (FIFO message groups): at most one message
+		// per group is leased, always the group's oldest unsettled one.
+		gq, ok := q.(statestore.GroupedQueue)
+		require.True(t, ok, "driver must implement statestore.GroupedQueue")
+		const gqName = "groupq"
+		enqueue := func(body, group string) string {
+			id, err := q.Enqueue(ctx, gqName, statestore.Message{Body: []byte(body)}, statestore.EnqueueOptions{Group: group})
+			require.NoError(t, err)
+			return id
+		}
+		lease := func() map[string]statestore.LeasedMessage {
+			l, err := gq.LeaseGrouped(ctx, gqName, 10, time.Minute)
+			require.NoError(t, err)
+			got := map[string]statestore.LeasedMessage{}
+			for _, m := range l {
+				got[string(m.Body)] = m
+			}
+			return got
+		}
+		keys := func(m map[string]statestore.LeasedMessage) []string {
+			var out []string
+			for k := range m {
+				out = append(out, k)
+			}
+			return out
+		}
+		enqueue("a1", "a")
+		enqueue("a2", "a")
+		enqueue("b1", "b")
+		enqueue("u1", "")
+		enqueue("a3", "a")
+		enqueue("u2", "")
+
+		got := lease()
+		assert.ElementsMatch(t, []string{"a1", "b1", "u1", "u2"}, keys(got),
+			"one head per group; ungrouped messages are unaffected")
+		assert.Empty(t, lease(), "a leased head holds its group back")
+
+		// A Nacked head stays the head: the group waits for its retry.
+		require.NoError(t, q.Nack(ctx, got["a1"].Receipt, time.Hour))
+		assert.Empty(t, lease(), "a delayed head still holds its group back")
+
+		// An acked head releases the next message of its group only.
+		require.NoError(t, q.Ack(ctx, got["b1"].Receipt))
+		assert.Empty(t, lease())
+
+		// Plain Lease ignores groups.
+		l, err := q.Lease(ctx, gqName, 1, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, l, 1)
+		assert.Equal(t, "a2", string(l[0].Body), "Lease takes the oldest visible message regardless of group")
+		require.NoError(t, q.Defer(ctx, l[0].Receipt, 0))
+
+		// A dead head releases its group, in order.
+		id := enqueue("c1", "c")
+		enqueue("c2", "c")
+		got = lease()
+		assert.ElementsMatch(t, []string{"c1"}, keys(got))
+		require.NoError(t, q.Kill(ctx, got["c1"].Receipt, "permanent"))
+		got = lease()
+		assert.ElementsMatch(t, []string{"c2"}, keys(got))
+		require.NoError(t, q.Ack(ctx, got["c2"].Receipt))
+
+		// A redriven message rejoins its group: nothing of c is unsettled, so
+		// it is the head again.
+		n, err := q.Redrive(ctx, gqName, []string{id})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+		got = lease()
+		assert.ElementsMatch(t, []string{"c1"}, keys(got))
+	})
 }
 
 // --- Time-dependent subtests (synctest; in-process drivers only) ---
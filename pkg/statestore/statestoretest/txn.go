@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestoretest
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+func txnPut(key, val string) statestore.TxnOp {
+	return statestore.TxnOp{Type: statestore.TxnPut, Key: key, Value: []byte(val)}
+}
+
+func txnDelete(key string) statestore.TxnOp {
+	return statestore.TxnOp{Type: statestore.TxnDelete, Key: key}
+}
+
+// runTxn checks the statestore.TxnKV contract. Every in-repo driver
+// implements it.
+func runTxn(t *testing.T, newCaps Factory) {
+	txnKV := func(t *testing.T) (statestore.KVStore, statestore.TxnKV) {
+		t.Helper()
+		kv := kvOrSkip(t, newCaps)
+		tk, ok := kv.(statestore.TxnKV)
+		require.True(t, ok, "driver must implement statestore.TxnKV")
+		return kv, tk
+	}
+	value := func(t *testing.T, kv statestore.KVStore, key string) statestore.Value {
+		t.Helper()
+		v, err := kv.Get(t.Context(), confScope, key)
+		require.NoError(t, err)
+		return v
+	}
+
+	t.Run("AllOrNothing", func(t *testing.T) {
+		kv, tk := txnKV(t)
+		ctx := t.Context()
+		require.NoError(t, kv.Set(ctx, confScope, "a", []byte("a1"), statestore.SetOptions{}))
+		require.NoError(t, kv.Set(ctx, confScope, "b", []byte("b1"), statestore.SetOptions{}))
+
+		versions, err := tk.Txn(ctx, confScope, statestore.Txn{
+			Compares: []statestore.TxnCompare{{Key: "a", Version: 1}, {Key: "b", Version: 1}, {Key: "c", Version: 0}},
+			Ops:      []statestore.TxnOp{txnPut("a", "a2"), txnDelete("b"), txnPut("c", "c1"), txnDelete("absent")},
+		}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 0, 1, 0}, versions, "each op's version, 0 after a delete")
+		assert.Equal(t, statestore.Value{Data: []byte("a2"), Version: 2}, value(t, kv, "a"))
+		assert.Equal(t, statestore.Value{Data: []byte("c1"), Version: 1}, value(t, kv, "c"))
+		_, err = kv.Get(ctx, confScope, "b")
+		require.ErrorIs(t, err, statestore.ErrNotFound)
+
+		// One stale compare fails the whole transaction.
+		_, err = tk.Txn(ctx, confScope, statestore.Txn{
+			Compares: []statestore.TxnCompare{{Key: "a", Version: 2}, {Key: "c", Version: 7}},
+			Ops:      []statestore.TxnOp{txnPut("a", "x"), txnPut("d", "x"), txnDelete("c")},
+		}, 0)
+		require.ErrorIs(t, err, statestore.ErrVersionConflict)
+		assert.Equal(t, statestore.Value{Data: []byte("a2"), Version: 2}, value(t, kv, "a"))
+		assert.Equal(t, statestore.Value{Data: []byte("c1"), Version: 1}, value(t, kv, "c"))
+		_, err = kv.Get(ctx, confScope, "d")
+		require.ErrorIs(t, err, statestore.ErrNotFound)
+
+		// Version 0 compares absent: it fails once the key exists.
+		_, err = tk.Txn(ctx, confScope, statestore.Txn{
+			Compares: []statestore.TxnCompare{{Key: "c", Version: 0}},
+			Ops:      []statestore.TxnOp{txnPut("c", "x")},
+		}, 0)
+		require.ErrorIs(t, err, statestore.ErrVersionConflict)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		kv, tk := txnKV(t)
+		ctx := t.Context()
+		op := txnPut("t", "v")
+		op.TTL = time.Hour
+		_, err := tk.Txn(ctx, confScope, statestore.Txn{Ops: []statestore.TxnOp{op}}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v"), value(t, kv, "t").Data)
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		kv, tk := txnKV(t)
+		ctx := t.Context()
+		const maxKeys = 2
+		_, err := tk.Txn(ctx, confScope, statestore.Txn{Ops: []statestore.TxnOp{txnPut("q1", "v"), txnPut("q2", "v")}}, maxKeys)
+		require.NoError(t, err)
+		_, err = tk.Txn(ctx, confScope, statestore.Txn{Ops: []statestore.TxnOp{txnPut("q1", "v2"), txnPut("q3", "v")}}, maxKeys)
+		require.ErrorIs(t, err, statestore.ErrQuotaExceeded)
+		assert.EqualValues(t, 1, value(t, kv, "q1").Version, "a rejected transaction writes nothing")
+
+		// A failed compare is a conflict, not a quota rejection.
+		_, err = tk.Txn(ctx, confScope, statestore.Txn{
+			Compares: []statestore.TxnCompare{{Key: "q1", Version: 9}},
+			Ops:      []statestore.TxnOp{txnPut("q3", "v")},
+		}, maxKeys)
+		require.ErrorIs(t, err, statestore.ErrVersionConflict)
+
+		// Renaming a key at a full budget fits: the delete frees the slot.
+		_, err = tk.Txn(ctx, confScope, statestore.Txn{Ops: []statestore.TxnOp{txnPut("q3", "v"), txnDelete("q1")}}, maxKeys)
+		require.NoError(t, err)
+		_, err = tk.Txn(ctx, confScope, statestore.Txn{Ops: []statestore.TxnOp{txnPut("q4", "v")}}, 0)
+		require.NoError(t, err, "maxKeys <= 0 means no budget")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		kv, tk := txnKV(t)
+		ctx := t.Context()
+		for name, txn := range map[string]statestore.Txn{
+			"NoOps":            {Compares: []statestore.TxnCompare{{Key: "a"}}},
+			"KeyWrittenTwice":  {Ops: []statestore.TxnOp{txnPut("a", "1"), txnDelete("a")}},
+			"KeyComparedTwice": {Compares: []statestore.TxnCompare{{Key: "a"}, {Key: "a", Version: 1}}, Ops: []statestore.TxnOp{txnPut("a", "1")}},
+			"NegativeVersion":  {Compares: []statestore.TxnCompare{{Key: "a", Version: -1}}, Ops: []statestore.TxnOp{txnPut("a", "1")}},
+			"UnknownOp":        {Ops: []statestore.TxnOp{txnPut("a", "1"), {Type: "incr", Key: "b"}}},
+			"EmptyPutKey":      {Ops: []statestore.TxnOp{txnPut("a", "1"), txnPut("", "1")}},
+			"EmptyDeleteKey":   {Ops: []statestore.TxnOp{txnPut("a", "1"), txnDelete("")}},
+			"EmptyCompareKey":  {Compares: []statestore.TxnCompare{{Key: ""}}, Ops: []statestore.TxnOp{txnPut("a", "1")}},
+			"TooManyOps": {Ops: func() []statestore.TxnOp {
+				ops := make([]statestore.TxnOp, statestore.MaxTxnOps+1)
+				for i := range ops {
+					ops[i] = txnPut(strconv.Itoa(i), "v")
+				}
+				return ops
+			}()},
+		} {
+			_, err := tk.Txn(ctx, confScope, txn, 0)
+			require.ErrorIs(t, err, statestore.ErrInvalidTxn, name)
+		}
+		_, err := kv.Get(ctx, confScope, "a")
+		require.ErrorIs(t, err, statestore.ErrNotFound)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		// Racing transfers between two counters, each a read of both then a
+		// Txn comparing both versions: if a Txn ever applied over a write it
+		// did not compare against, the total would drift.
+		kv, tk := txnKV(t)
+		ctx := t.Context()
+		const total, workers, transfers = 100, 8, 5
+		require.NoError(t, kv.Set(ctx, confScope, "x", []byte(strconv.Itoa(total)), statestore.SetOptions{}))
+		require.NoError(t, kv.Set(ctx, confScope, "y", []byte("0"), statestore.SetOptions{}))
+
+		errs := make(chan error, workers)
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() {
+				for done := 0; done < transfers; {
+					x, err := kv.Get(ctx, confScope, "x")
+					if err != nil {
+						errs <- err
+						return
+					}
+					y, err := kv.Get(ctx, confScope, "y")
+					if err != nil {
+						errs <- err
+						return
+					}
+					xn, _ := strconv.Atoi(string(x.Data))
+					yn, _ := strconv.Atoi(string(y.Data))
+					_, err = tk.Txn(ctx, confScope, statestore.Txn{
+						Compares: []statestore.TxnCompare{{Key: "x", Version: x.Version}, {Key: "y", Version: y.Version}},
+						Ops:      []statestore.TxnOp{txnPut("x", strconv.Itoa(xn-1)), txnPut("y", strconv.Itoa(yn+1))},
+					}, 0)
+					switch {
+					case errors.Is(err, statestore.ErrVersionConflict):
+					case err != nil:
+						errs <- err
+						return
+					default:
+						done++
+					}
+				}
+			})
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, strconv.Itoa(total-workers*transfers), string(value(t, kv, "x").Data))
+		assert.Equal(t, strconv.Itoa(workers*transfers), string(value(t, kv, "y").Data))
+		assert.EqualValues(t, workers*transfers+1, value(t, kv, "x").Version, "every transfer wrote x once")
+	})
+}
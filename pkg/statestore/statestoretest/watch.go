@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statestoretest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+)
+
+var watchScope = statestore.Scope{Namespace: "ns", Owner: "function/watch", Keyspace: "ks"}
+
+// errWatchDone ends a watch the suite has seen enough of.
+var errWatchDone = errors.New("watch done")
+
+// RunWatchConformance checks the statestore.WatchableKV contract: which writes
+// are changes, revision order, prefix filtering, resuming, live delivery, and
+// compaction. Only watchable drivers (memory, SQLite, Postgres, and the
+// embedded client over one of them) run it.
+func RunWatchConformance(t *testing.T, newCaps Factory) {
+	t.Helper()
+	t.Run("KVWatch", func(t *testing.T) { runWatch(t, newCaps) })
+}
+
+func runWatch(t *testing.T, newCaps Factory) {
+	watchable := func(t *testing.T) (statestore.KVStore, statestore.WatchableKV) {
+		t.Helper()
+		kv := kvOrSkip(t, newCaps)
+		wk, ok := kv.(statestore.WatchableKV)
+		require.True(t, ok, "driver must implement statestore.WatchableKV")
+		return kv, wk
+	}
+
+	t.Run("ChangesInRevisionOrder", func(t *testing.T) {
+		kv, wk := watchable(t)
+		ctx := t.Context()
+		rev, err := wk.Revision(ctx, watchScope)
+		require.NoError(t, err)
+		require.Zero(t, rev, "a scope with no changes is at revision 0")
+
+		require.NoError(t, kv.Set(ctx, watchScope, "a", []byte("a1"), statestore.SetOptions{IfVersion: new(int64(0))}))
+		require.NoError(t, kv.Set(ctx, watchScope, "a", []byte("a2"), statestore.SetOptions{IfVersion: new(int64(1))}))
+		require.ErrorIs(t, kv.Set(ctx, watchScope, "a", []byte("x"), statestore.SetOptions{IfVersion: new(int64(1))}), statestore.ErrVersionConflict)
+		require.NoError(t, kv.Set(ctx, watchScope, "b", []byte("b1"), statestore.SetOptions{TTL: time.Hour}))
+		require.NoError(t, kv.Delete(ctx, watchScope, "a", 0))
+		require.NoError(t, kv.Delete(ctx, watchScope, "a", 0), "deleting an absent key is not a change")
+		require.ErrorIs(t, kv.Delete(ctx, watchScope, "b", 9), statestore.ErrVersionConflict)
+
+		rev, err = wk.Revision(ctx, watchScope)
+		require.NoError(t, err)
+		require.EqualValues(t, 4, rev, "failed CAS and no-op deletes are not changes")
+
+		evs, reached := collect(t, wk, "", 0, 4)
+		require.Len(t, evs, 4)
+		require.EqualValues(t, 4, reached)
+		for i, ev := range evs {
+			require.EqualValues(t, i+1, ev.Revision)
+		}
+		assert.Equal(t, statestore.KVEventPut, evs[0].Type)
+		assert.Equal(t, "a", evs[0].Key)
+		assert.Equal(t, "a1", string(evs[0].Value))
+		assert.EqualValues(t, 1, evs[0].Version)
+		assert.True(t, evs[0].ExpiresAt.IsZero())
+		assert.Equal(t, "a2", string(evs[1].Value))
+		assert.EqualValues(t, 2, evs[1].Version)
+		assert.Equal(t, "b", evs[2].Key)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), evs[2].ExpiresAt, time.Minute, "a put carries its expiry")
+		assert.Equal(t, statestore.KVEventDelete, evs[3].Type)
+		assert.Equal(t, "a", evs[3].Key)
+		assert.EqualValues(t, 2, evs[3].Version, "a delete carries the version it removed")
+		assert.Empty(t, evs[3].Value)
+	})
+
+	t.Run("TxnChanges", func(t *testing.T) {
+		kv, wk := watchable(t)
+		tk, ok := kv.(statestore.TxnKV)
+		require.True(t, ok, "driver must implement statestore.TxnKV")
+		ctx := t.Context()
+		_, err := tk.Txn(ctx, watchScope, statestore.Txn{
+			Ops: []statestore.TxnOp{txnPut("a", "1"), txnDelete("absent"), txnPut("b", "2")},
+		}, 0)
+		require.NoError(t, err)
+		_, err = tk.Txn(ctx, watchScope, statestore.Txn{
+			Compares: []statestore.TxnCompare{{Key: "a", Version: 9}},
+			Ops:      []statestore.TxnOp{txnDelete("a")},
+		}, 0)
+		require.ErrorIs(t, err, statestore.ErrVersionConflict)
+
+		evs, reached := collect(t, wk, "", 0, 2)
+		require.Len(t, evs, 2)
+		assert.EqualValues(t, 2, reached, "a failed transaction changes nothing")
+		assert.Equal(t, "a", evs[0].Key)
+		assert.EqualValues(t, 1, evs[0].Revision)
+		assert.Equal(t, "b", evs[1].Key)
+		assert.EqualValues(t, 2, evs[1].Revision, "a transaction's changes take consecutive revisions")
+	})
+
+	t.Run("PrefixAndResume", func(t *testing.T) {
+		kv, wk := watchable(t)
+		ctx := t.Context()
+		for _, k := range []string{"cfg/x", "other", "cfg/y", "cfg_z"} {
+			require.NoError(t, kv.Set(ctx, watchScope, k, []byte(k), statestore.SetOptions{}))
+		}
+		evs, reached := collect(t, wk, "cfg/", 0, 2)
+		require.Len(t, evs, 2)
+		assert.Equal(t, "cfg/x", evs[0].Key)
+		assert.EqualValues(t, 1, evs[0].Revision)
+		assert.Equal(t, "cfg/y", evs[1].Key, "the prefix is literal: _ is not a wildcard")
+		assert.EqualValues(t, 3, evs[1].Revision)
+		assert.GreaterOrEqual(t, reached, int64(3))
+
+		evs, _ = collect(t, wk, "", 2, 2)
+		require.Len(t, evs, 2)
+		assert.EqualValues(t, 3, evs[0].Revision, "a watch resumes after fromRevision")
+		assert.EqualValues(t, 4, evs[1].Revision)
+	})
+
+	t.Run("EstablishedAtHead", func(t *testing.T) {
+		kv, wk := watchable(t)
+		ctx := t.Context()
+		require.NoError(t, kv.Set(ctx, watchScope, "k", []byte("v"), statestore.SetOptions{}))
+		calls := 0
+		err := wk.Watch(ctx, watchScope, "", 1, func(evs []statestore.KVEvent, rev int64) error {
+			calls++
+			assert.Empty(t, evs)
+			assert.EqualValues(t, 1, rev)
+			return errWatchDone
+		})
+		require.ErrorIs(t, err, errWatchDone, "Watch returns fn's error")
+		require.Equal(t, 1, calls, "fn hears the watch is established with nothing new")
+	})
+
+	t.Run("LiveChanges", func(t *testing.T) {
+		kv, wk := watchable(t)
+		ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+		defer cancel()
+		established := make(chan struct{})
+		var got []statestore.KVEvent
+		done := make(chan error, 1)
+		go func() {
+			first := true
+			done <- wk.Watch(ctx, watchScope, "p/", 0, func(evs []statestore.KVEvent, _ int64) error {
+				if first {
+					first = false
+					close(established)
+				}
+				got = append(got, evs...)
+				if len(got) > 0 {
+					return errWatchDone
+				}
+				return nil
+			})
+		}()
+		select {
+		case <-established:
+		case err := <-done:
+			require.NoError(t, err, "watch ended before it was established")
+		}
+		other := statestore.Scope{Namespace: watchScope.Namespace, Owner: "function/other", Keyspace: watchScope.Keyspace}
+		require.NoError(t, kv.Set(ctx, other, "p/1", []byte("elsewhere"), statestore.SetOptions{}))
+		require.NoError(t, kv.Set(ctx, watchScope, "q", []byte("q"), statestore.SetOptions{}))
+		require.NoError(t, kv.Set(ctx, watchScope, "p/1", []byte("v"), statestore.SetOptions{}))
+		require.ErrorIs(t, <-done, errWatchDone)
+		require.Len(t, got, 1)
+		assert.Equal(t, "p/1", got[0].Key)
+		assert.Equal(t, "v", string(got[0].Value))
+		assert.EqualValues(t, 2, got[0].Revision, "revisions count the watched scope's changes only")
+	})
+
+	t.Run("ContextEndsWatch", func(t *testing.T) {
+		_, wk := watchable(t)
+		ctx, cancel := context.WithCancel(t.Context())
+		err := wk.Watch(ctx, watchScope, "", 0, func([]statestore.KVEvent, int64) error {
+			cancel()
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Compacted", func(t *testing.T) {
+		kv, wk := watchable(t)
+		ctx := t.Context()
+		const writes = 2*statestore.KVWatchHistory + 1
+		for i := range writes {
+			require.NoError(t, kv.Set(ctx, watchScope, "k", []byte{byte(i)}, statestore.SetOptions{}))
+		}
+		err := wk.Watch(ctx, watchScope, "", 0, func([]statestore.KVEvent, int64) error {
+			return errWatchDone
+		})
+		require.ErrorIs(t, err, statestore.ErrCompacted, "history older than KVWatchHistory is compacted")
+
+		from := int64(writes - statestore.KVWatchHistory)
+		evs, reached := collect(t, wk, "", from, statestore.KVWatchHistory)
+		require.Len(t, evs, statestore.KVWatchHistory, "the last KVWatchHistory revisions are retained")
+		assert.EqualValues(t, from+1, evs[0].Revision)
+		assert.EqualValues(t, writes, reached)
+	})
+}
+
+// collect watches prefix from fromRevision until it has n events, returning
+// them and the revision the watch reached.
+func collect(t *testing.T, wk statestore.WatchableKV, prefix string, fromRevision int64, n int) ([]statestore.KVEvent, int64) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+	var (
+		evs     []statestore.KVEvent
+		reached int64
+	)
+	err := wk.Watch(ctx, watchScope, prefix, fromRevision, func(batch []statestore.KVEvent, rev int64) error {
+		evs, reached = append(evs, batch...), rev
+		if len(evs) >= n {
+			return errWatchDone
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errWatchDone)
+	return evs, reached
+}
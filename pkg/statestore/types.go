@@ -6,6 +6,7 @@ package statestore
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -57,6 +58,110 @@ type SetOptions struct {
 	TTL       time.Duration
 }
 
+// MaxTxnOps bounds both the compares and the ops of one Txn.
+const MaxTxnOps = 64
+
+// TxnOpType is the kind of a Txn write.
+type TxnOpType string
+
+const (
+	TxnPut    TxnOpType = "put"
+	TxnDelete TxnOpType = "delete"
+)
+
+// TxnCompare holds when Key is at Version, an absent or expired key being
+// version 0 (the SetOptions.IfVersion convention).
+type TxnCompare struct {
+	Key     string
+	Version int64
+}
+
+// TxnOp is one write of a Txn: a put of Value, expiring TTL after the write
+// (0 for never), or an unconditional delete.
+type TxnOp struct {
+	Type  TxnOpType
+	Key   string
+	Value []byte
+	TTL   time.Duration
+}
+
+// Txn is a multi-key transaction for TxnKV: Ops apply only if every compare
+// in Compares holds. A key appears at most once in Compares and at most once
+// in Ops, and may appear in both.
+type Txn struct {
+	Compares []TxnCompare
+	Ops      []TxnOp
+}
+
+// Validate returns an error wrapping ErrInvalidTxn if t is malformed. Drivers
+// call it before touching the store, so every driver rejects the same
+// transactions.
+func (t Txn) Validate() error {
+	if len(t.Ops) == 0 {
+		return fmt.Errorf("%w: no ops", ErrInvalidTxn)
+	}
+	if len(t.Compares) > MaxTxnOps || len(t.Ops) > MaxTxnOps {
+		return fmt.Errorf("%w: more than %d compares or ops", ErrInvalidTxn, MaxTxnOps)
+	}
+	compared := make(map[string]bool, len(t.Compares))
+	for _, c := range t.Compares {
+		if c.Key == "" {
+			return fmt.Errorf("%w: compare on an empty key", ErrInvalidTxn)
+		}
+		if c.Version < 0 {
+			return fmt.Errorf("%w: compare on %q has a negative version", ErrInvalidTxn, c.Key)
+		}
+		if compared[c.Key] {
+			return fmt.Errorf("%w: key %q is compared twice", ErrInvalidTxn, c.Key)
+		}
+		compared[c.Key] = true
+	}
+	written := make(map[string]bool, len(t.Ops))
+	for _, op := range t.Ops {
+		if op.Key == "" {
+			return fmt.Errorf("%w: %s of an empty key", ErrInvalidTxn, op.Type)
+		}
+		switch op.Type {
+		case TxnPut:
+			if op.TTL < 0 {
+				return fmt.Errorf("%w: put of %q has a negative TTL", ErrInvalidTxn, op.Key)
+			}
+		case TxnDelete:
+		default:
+			return fmt.Errorf("%w: op on %q has unknown type %q", ErrInvalidTxn, op.Key, op.Type)
+		}
+		if written[op.Key] {
+			return fmt.Errorf("%w: key %q is written twice", ErrInvalidTxn, op.Key)
+		}
+		written[op.Key] = true
+	}
+	return nil
+}
+
+// KVWatchHistory is how many revisions per scope a WatchableKV driver retains
+// at least, and so how far behind the head a watch can resume.
+const KVWatchHistory = 1000
+
+// KVEventType is the kind of a KV change.
+type KVEventType string
+
+const (
+	KVEventPut    KVEventType = "put"
+	KVEventDelete KVEventType = "delete"
+)
+
+// KVEvent is one change delivered by WatchableKV.Watch. For a put, Value and
+// Version are the key's new value and version and ExpiresAt its expiry (zero
+// when it has no TTL); for a delete, Version is the version that was deleted.
+type KVEvent struct {
+	Type      KVEventType
+	Key       string
+	Value     []byte
+	Version   int64
+	ExpiresAt time.Time
+	Revision  int64
+}
+
 // Page is an opaque forward-only pagination cursor.
 type Page struct {
 	Token string // "" means the first page.
@@ -114,9 +219,12 @@ type DeadMessage struct {
 //   - Delay: the earliest lease time is now+Delay (0 means immediately leasable).
 //   - DedupKey: if a not-yet-settled message with the same (queue, DedupKey)
 //     already exists, Enqueue is a no-op that returns that message's id.
+//   - Group: the message group (FIFO key) the message belongs to. Only
+//     GroupedQueue.LeaseGrouped honors it; empty means ungrouped.
 type EnqueueOptions struct {
 	Delay    time.Duration
 	DedupKey string
+	Group    string
 }
 
 // QueueStats is a point-in-time snapshot of one queue's backlog, powering the
@@ -133,6 +241,9 @@ type QueueStats struct {
 	Leased int64
 	// Dead is dead-lettered messages awaiting inspection or redrive.
 	Dead int64
+	// Deferred is queued messages a consumer handed back with Defer (throttled
+	// rather than failed) that are not yet visible again.
+	Deferred int64
 	// OldestVisibleAge is now minus the enqueue time of the oldest visible
 	// message, or 0 when none are visible.
 	OldestVisibleAge time.Duration
@@ -18,6 +18,9 @@ func TestSentinelsAreDistinct(t *testing.T) {
 		ErrCapabilityUnavailable,
 		ErrQuotaExceeded,
 		ErrInvalidReceipt,
+		ErrCompacted,
+		ErrInvalidTxn,
+		ErrInvalidArchive,
 		ErrClosed,
 	}
 	for i := range errs {
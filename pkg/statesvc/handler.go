@@ -56,11 +56,14 @@ type handler struct {
 	kv     statestore.KVStore
 	index  *FunctionIndex
 	logger logr.Logger
+	stop   <-chan struct{} // closed at shutdown; ends watch streams
 }
 
-// newHandler builds the authenticated API handler. ready gates /readyz.
-func newHandler(kv statestore.KVStore, index *FunctionIndex, auth *authenticator, ready func() bool, logger logr.Logger) http.Handler {
-	h := &handler{kv: kv, index: index, logger: logger}
+// newHandler builds the authenticated API handler. ready gates /readyz, and
+// closing stop ends open watch streams so shutdown can drain (nil never
+// does).
+func newHandler(kv statestore.KVStore, index *FunctionIndex, auth *authenticator, ready func() bool, stop <-chan struct{}, logger logr.Logger) http.Handler {
+	h := &handler{kv: kv, index: index, logger: logger, stop: stop}
 
 	api := http.NewServeMux()
 	api.HandleFunc("GET /v1/state/{key}", h.get)
@@ -68,6 +71,7 @@ func newHandler(kv statestore.KVStore, index *FunctionIndex, auth *authenticator
 	api.HandleFunc("DELETE /v1/state/{key}", h.del)
 	api.HandleFunc("POST /v1/state/{key}/cas", h.cas)
 	api.HandleFunc("GET /v1/state", h.list)
+	api.HandleFunc("POST "+stateapi.PathTxn, h.txn)
 	authed := auth.middleware(h.requireKnownKeyspace(api))
 
 	root := http.NewServeMux()
@@ -81,6 +85,7 @@ func newHandler(kv statestore.KVStore, index *FunctionIndex, auth *authenticator
 	})
 	root.Handle("/v1/state", authed)
 	root.Handle("/v1/state/", authed)
+	root.Handle(stateapi.PathTxn, authed)
 	return root
 }
 
@@ -224,6 +229,10 @@ func (h *handler) cas(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has(stateapi.QueryWatch) {
+		h.watch(w, r)
+		return
+	}
 	sc, _ := scopeFrom(r.Context())
 	limit := defaultListLimit
 	if ls := r.URL.Query().Get("limit"); ls != "" {
@@ -46,7 +46,7 @@ func newTestServer(t *testing.T, fns map[types.NamespacedName]*fv1.StateConfig)
 	require.NoError(t, err)
 
 	auth := newAuthenticator(testMaster, nil, hmacauth.VerifierOpts{SkewSec: 60, MaxBodyBytes: 1 << 20})
-	h := newHandler(kv, index, auth, func() bool { return true }, logr.Discard())
+	h := newHandler(kv, index, auth, func() bool { return true }, nil, logr.Discard())
 	srv := httptest.NewServer(h)
 	t.Cleanup(srv.Close)
 	return srv, index
@@ -288,7 +288,7 @@ func TestHandlerDevPassThroughMode(t *testing.T) {
 	kv, err := scoped.KV()
 	require.NoError(t, err)
 	auth := newAuthenticator(nil, nil, hmacauth.VerifierOpts{}) // no master secret
-	srv := httptest.NewServer(newHandler(kv, index, auth, func() bool { return true }, logr.Discard()))
+	srv := httptest.NewServer(newHandler(kv, index, auth, func() bool { return true }, nil, logr.Discard()))
 	t.Cleanup(srv.Close)
 
 	// Bearer accepted on claims alone (any token), for a claimed keyspace.
@@ -11,6 +11,8 @@
 // instead of a silent runtime divergence.
 package stateapi
 
+import "time"
+
 // Scope-claim request headers (bearer/function path). The namespace and
 // keyspace a request operates on are CLAIMS; they become the store Scope only
 // after the per-keyspace bearer token — derived from exactly those claims —
@@ -30,6 +32,29 @@ const (
 	QueryScopeKeyspace  = "scope-keyspace"
 )
 
+// PathTxn is the multi-key transaction route: POST a TxnRequest, get a
+// TxnResponse back.
+const PathTxn = "/v1/state:txn"
+
+// Watch query parameters. GET /v1/state?watch=<prefix> streams the changes to
+// keys under prefix (empty watches the whole keyspace) as Server-Sent Events
+// instead of listing. A watch resumes after the revision in the Last-Event-ID
+// header (sent by EventSource on reconnect) or, failing that, QueryRevision;
+// with neither it starts at the current revision.
+const (
+	QueryWatch    = "watch"
+	QueryRevision = "revision"
+)
+
+// Watch stream SSE event names. Every put and delete event's id is its
+// revision; bare "id:" lines between events advance the resume point past
+// changes outside the watched prefix. An error event ends the stream.
+const (
+	EventPut    = "put"
+	EventDelete = "delete"
+	EventError  = "error"
+)
+
 // Machine-readable error codes returned in Error.Code.
 const (
 	CodeBadRequest      = "bad_request"
@@ -40,6 +65,7 @@ const (
 	CodeQuotaValueBytes = "quota_value_bytes"
 	CodeQuotaKeys       = "quota_keys"
 	CodeUnavailable     = "capability_unavailable"
+	CodeCompacted       = "revision_compacted"
 	CodeInternal        = "internal"
 )
 
@@ -49,6 +75,17 @@ type Error struct {
 	Code  string `json:"code"`
 }
 
+// WatchEvent is the data of a put or delete watch event. For a put, Value
+// (base64) and Version are the key's new value and version, and ExpiresAt its
+// expiry when it has a TTL; for a delete, Version is the version deleted.
+type WatchEvent struct {
+	Key       string    `json:"key"`
+	Value     []byte    `json:"value,omitempty"`
+	Version   int64     `json:"version"`
+	Revision  int64     `json:"revision"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
 // CASRequest is the POST /v1/state/{key}/cas body — an explicit
 // compare-and-swap for clients without If-Match plumbing. Value is base64
 // (JSON []byte); ExpectVersion 0 means create-only.
@@ -63,3 +100,66 @@ type ListResponse struct {
 	Keys   []string `json:"keys"`
 	Cursor string   `json:"cursor,omitempty"`
 }
+
+// Transaction op kinds.
+const (
+	TxnPut    = "put"
+	TxnDelete = "delete"
+)
+
+// TxnCompare holds when Key is at Version; Version 0 means absent.
+type TxnCompare struct {
+	Key     string `json:"key"`
+	Version int64  `json:"version"`
+}
+
+// TxnOp is one write of a transaction. A put writes Value (base64) with TTL,
+// a Go duration like the X-Fission-State-TTL header ("" applies the
+// keyspace's DefaultTTL); a delete removes Key if it exists.
+type TxnOp struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value []byte `json:"value,omitempty"`
+	TTL   string `json:"ttl,omitempty"`
+}
+
+// TxnRequest is the POST /v1/state:txn body: Ops apply atomically, in order,
+// only if every compare in Compare holds; otherwise the response is 412
+// version_conflict and nothing changes. A key appears at most once in Compare
+// and once in Ops.
+type TxnRequest struct {
+	Compare []TxnCompare `json:"compare,omitempty"`
+	Ops     []TxnOp      `json:"ops"`
+}
+
+// TxnResponse is the POST /v1/state:txn body on success: the version each op
+// left its key at, in op order (0 after a delete).
+type TxnResponse struct {
+	Versions []int64 `json:"versions"`
+}
+
+// IfVersion is the compare that Key is at version.
+func IfVersion(key string, version int64) TxnCompare {
+	return TxnCompare{Key: key, Version: version}
+}
+
+// IfAbsent is the compare that Key does not exist.
+func IfAbsent(key string) TxnCompare {
+	return TxnCompare{Key: key}
+}
+
+// Put is the op that writes value to key with the keyspace's DefaultTTL.
+func Put(key string, value []byte) TxnOp {
+	return TxnOp{Op: TxnPut, Key: key, Value: value}
+}
+
+// PutTTL is the op that writes value to key, expiring ttl after the write (0
+// for never).
+func PutTTL(key string, value []byte, ttl time.Duration) TxnOp {
+	return TxnOp{Op: TxnPut, Key: key, Value: value, TTL: ttl.String()}
+}
+
+// Delete is the op that deletes key.
+func Delete(key string) TxnOp {
+	return TxnOp{Op: TxnDelete, Key: key}
+}
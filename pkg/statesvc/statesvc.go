@@ -164,7 +164,7 @@ func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger l
 		return scoped.Ping(pingCtx) == nil
 	}
 
-	handler := newHandler(kv, index, auth, ready, logger)
+	handler := newHandler(kv, index, auth, ready, ctx.Done(), logger)
 	mgr.Go(func() error {
 		httpserver.Serve(ctx, logger, mgr, httpserver.ServerOptions{
 			Name: "statesvc", Addr: strconv.Itoa(opts.Port), Listener: opts.Listener, Handler: handler,
@@ -41,7 +41,7 @@ func newBubbleHandler(t *testing.T, fns map[types.NamespacedName]*fv1.StateConfi
 	kv, err := scoped.KV()
 	require.NoError(t, err)
 	auth := newAuthenticator(testMaster, nil, hmacauth.VerifierOpts{SkewSec: 60})
-	return newHandler(kv, index, auth, func() bool { return true }, logr.Discard())
+	return newHandler(kv, index, auth, func() bool { return true }, nil, logr.Discard())
 }
 
 func bubbleReq(h http.Handler, method, path, ns, ks, token string, body []byte, hdrs map[string]string) *httptest.ResponseRecorder {
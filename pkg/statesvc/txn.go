@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statesvc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statesvc/stateapi"
+	"github.com/fission/fission/pkg/utils/httpx"
+)
+
+// maxTxnBodyBytes bounds a transaction body. Each value is also held to the
+// keyspace's MaxValueBytes; this caps their sum, so one transaction cannot
+// outgrow the request the statestore wire will carry.
+const maxTxnBodyBytes = 2 << 20
+
+// txn serves POST /v1/state:txn (statestore.TxnKV): writes to several keys of
+// the keyspace, applied atomically and only if every compare holds. A failed
+// compare is 412 version_conflict, as with If-Match, and the key budget and
+// value size are enforced as for PUT.
+func (h *handler) txn(w http.ResponseWriter, r *http.Request) {
+	sc, _ := scopeFrom(r.Context())
+	tk, ok := h.kv.(statestore.TxnKV)
+	if !ok {
+		writeStoreErr(w, statestore.ErrCapabilityUnavailable)
+		return
+	}
+	var req stateapi.TxnRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxTxnBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, stateapi.CodeBadRequest, "invalid transaction body: "+err.Error())
+		return
+	}
+	t, err := h.storeTxn(req, sc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, stateapi.CodeBadRequest, err.Error())
+		return
+	}
+	maxBytes := h.index.Resolve(sc.scope).MaxValueBytes
+	for _, op := range t.Ops {
+		if int64(len(op.Value)) > maxBytes {
+			writeValueTooLarge(w)
+			return
+		}
+	}
+	versions, err := tk.Txn(r.Context(), sc.scope, t, 0)
+	if err != nil {
+		writeStoreErr(w, err)
+		return
+	}
+	_ = httpx.WriteJSON(w, http.StatusOK, stateapi.TxnResponse{Versions: versions})
+}
+
+// storeTxn converts req, applying the keyspace's DefaultTTL to puts without a
+// TTL, and validates it.
+func (h *handler) storeTxn(req stateapi.TxnRequest, sc authedScope) (statestore.Txn, error) {
+	t := statestore.Txn{Ops: make([]statestore.TxnOp, len(req.Ops))}
+	for _, c := range req.Compare {
+		t.Compares = append(t.Compares, statestore.TxnCompare{Key: c.Key, Version: c.Version})
+	}
+	for i, op := range req.Ops {
+		t.Ops[i] = statestore.TxnOp{Type: statestore.TxnOpType(op.Op), Key: op.Key, Value: op.Value}
+		if op.Op != stateapi.TxnPut {
+			continue
+		}
+		if op.TTL == "" {
+			t.Ops[i].TTL = h.index.DefaultTTL(sc.scope.Namespace, sc.scope.Keyspace)
+			continue
+		}
+		ttl, err := time.ParseDuration(op.TTL)
+		if err != nil || ttl < 0 {
+			return t, fmt.Errorf("the ttl of the put of %q must be a non-negative Go duration (e.g. 300s)", op.Key)
+		}
+		t.Ops[i].TTL = ttl
+	}
+	if err := t.Validate(); err != nil {
+		return t, errors.New(strings.TrimPrefix(err.Error(), "statestore: "))
+	}
+	return t, nil
+}
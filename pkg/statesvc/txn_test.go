@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statesvc
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/statesvc/stateapi"
+)
+
+func doTxn(t *testing.T, srv *httptest.Server, ns, keyspace string, req stateapi.TxnRequest) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	return doState(t, srv, http.MethodPost, stateapi.PathTxn, ns, keyspace, stateToken(ns, keyspace), body, nil)
+}
+
+func errCode(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	var e stateapi.Error
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+	return e.Code
+}
+
+func TestHandlerTxn(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t, twoFns())
+	tok := stateToken("ns-a", "fn-a")
+	put := doState(t, srv, http.MethodPut, "/v1/state/from", "ns-a", "fn-a", tok, []byte("10"), nil)
+	require.Equal(t, http.StatusNoContent, put.StatusCode)
+
+	resp := doTxn(t, srv, "ns-a", "fn-a", stateapi.TxnRequest{
+		Compare: []stateapi.TxnCompare{stateapi.IfVersion("from", 1), stateapi.IfAbsent("to")},
+		Ops:     []stateapi.TxnOp{stateapi.Delete("from"), stateapi.PutTTL("to", []byte("10"), time.Hour)},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var tr stateapi.TxnResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tr))
+	assert.Equal(t, []int64{0, 1}, tr.Versions)
+
+	get := doState(t, srv, http.MethodGet, "/v1/state/to", "ns-a", "fn-a", tok, nil, nil)
+	require.Equal(t, http.StatusOK, get.StatusCode)
+	b, _ := io.ReadAll(get.Body)
+	assert.Equal(t, "10", string(b))
+	get = doState(t, srv, http.MethodGet, "/v1/state/from", "ns-a", "fn-a", tok, nil, nil)
+	assert.Equal(t, http.StatusNotFound, get.StatusCode)
+
+	// The same transaction again: its compares no longer hold.
+	resp = doTxn(t, srv, "ns-a", "fn-a", stateapi.TxnRequest{
+		Compare: []stateapi.TxnCompare{stateapi.IfVersion("from", 1), stateapi.IfAbsent("to")},
+		Ops:     []stateapi.TxnOp{stateapi.Delete("from"), stateapi.Put("to", []byte("20"))},
+	})
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	assert.Equal(t, stateapi.CodeVersionConflict, errCode(t, resp))
+
+	// Keys are scoped: ns-b's keyspace does not see ns-a's key.
+	resp = doTxn(t, srv, "ns-b", "fn-b", stateapi.TxnRequest{
+		Compare: []stateapi.TxnCompare{stateapi.IfAbsent("to")},
+		Ops:     []stateapi.TxnOp{stateapi.Put("to", []byte("b"))},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHandlerTxnRejections(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t, map[types.NamespacedName]*fv1.StateConfig{
+		fnA: {MaxValueBytes: 8, MaxKeys: 2},
+	})
+
+	for name, req := range map[string]stateapi.TxnRequest{
+		"NoOps":           {},
+		"DuplicateKey":    {Ops: []stateapi.TxnOp{stateapi.Put("a", nil), stateapi.Delete("a")}},
+		"UnknownOp":       {Ops: []stateapi.TxnOp{{Op: "incr", Key: "a"}}},
+		"BadTTL":          {Ops: []stateapi.TxnOp{{Op: stateapi.TxnPut, Key: "a", TTL: "soon"}}},
+		"NegativeVersion": {Compare: []stateapi.TxnCompare{stateapi.IfVersion("a", -1)}, Ops: []stateapi.TxnOp{stateapi.Delete("a")}},
+	} {
+		resp := doTxn(t, srv, "ns-a", "fn-a", req)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+		assert.Equal(t, stateapi.CodeBadRequest, errCode(t, resp), name)
+	}
+
+	resp := doTxn(t, srv, "ns-a", "fn-a", stateapi.TxnRequest{
+		Ops: []stateapi.TxnOp{stateapi.Put("a", []byte("v")), stateapi.Put("big", []byte("123456789"))},
+	})
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, stateapi.CodeQuotaValueBytes, errCode(t, resp))
+
+	resp = doTxn(t, srv, "ns-a", "fn-a", stateapi.TxnRequest{
+		Ops: []stateapi.TxnOp{stateapi.Put("a", []byte("v")), stateapi.Put("b", []byte("v")), stateapi.Put("c", []byte("v"))},
+	})
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, stateapi.CodeQuotaKeys, errCode(t, resp))
+
+	// Another keyspace's token is refused here as on the rest of the API.
+	resp = doState(t, srv, http.MethodPost, stateapi.PathTxn, "ns-a", "fn-a", stateToken("ns-b", "fn-b"), []byte(`{"ops":[]}`), nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
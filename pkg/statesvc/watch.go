@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statesvc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statesvc/stateapi"
+)
+
+const (
+	// watchHeartbeat is how often an idle watch stream writes, so proxies
+	// keep it open, and how often it reports progress outside its prefix.
+	watchHeartbeat = 15 * time.Second
+	// maxWatchDuration ends a watch stream so clients reconnect (resuming
+	// from Last-Event-ID) and long-lived streams rebalance across replicas.
+	maxWatchDuration = 10 * time.Minute
+	// watchRetry is the reconnect delay the stream advises EventSource.
+	watchRetry = 2 * time.Second
+)
+
+// watch serves GET /v1/state?watch=<prefix> as a Server-Sent Events stream of
+// the keyspace's changes under prefix (statestore.WatchableKV). Errors before
+// the stream starts are ordinary JSON errors — a resume point older than the
+// retained history is 410 revision_compacted, after which the client re-lists
+// and watches from the current revision. Later errors end the stream with an
+// error event.
+func (h *handler) watch(w http.ResponseWriter, r *http.Request) {
+	sc, _ := scopeFrom(r.Context())
+	wk, ok := h.kv.(statestore.WatchableKV)
+	if !ok {
+		writeStoreErr(w, statestore.ErrCapabilityUnavailable)
+		return
+	}
+	from, err := watchFrom(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, stateapi.CodeBadRequest, err.Error())
+		return
+	}
+	if from < 0 {
+		if from, err = wk.Revision(r.Context(), sc.scope); err != nil {
+			writeWatchErr(w, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), maxWatchDuration)
+	defer cancel()
+	// End the stream when the server shuts down, so the drain is not held
+	// open by watches.
+	go func() {
+		select {
+		case <-h.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s := &sseStream{w: w, rc: http.NewResponseController(w)}
+	defer s.stopHeartbeat()
+	err = wk.Watch(ctx, sc.scope, r.URL.Query().Get(stateapi.QueryWatch), from, func(events []statestore.KVEvent, rev int64) error {
+		if !s.started {
+			if err := s.start(rev); err != nil {
+				return err
+			}
+		}
+		return s.send(events, rev)
+	})
+	switch {
+	case s.started && ctx.Err() == nil && !errors.Is(err, errStreamWrite):
+		s.fail(err)
+	case s.started || ctx.Err() != nil:
+	default:
+		writeWatchErr(w, err)
+	}
+}
+
+// watchFrom returns the revision a watch resumes after, or -1 to start at the
+// current revision.
+func watchFrom(r *http.Request) (int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get(stateapi.QueryRevision)
+	}
+	if v == "" {
+		return -1, nil
+	}
+	rev, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || rev < 0 {
+		return 0, errors.New("the resume revision (Last-Event-ID or " + stateapi.QueryRevision + ") must be a non-negative integer")
+	}
+	return rev, nil
+}
+
+// writeWatchErr is writeStoreErr plus the watch-only compaction outcome.
+func writeWatchErr(w http.ResponseWriter, err error) {
+	if errors.Is(err, statestore.ErrCompacted) {
+		writeError(w, http.StatusGone, stateapi.CodeCompacted, "resume revision is older than the retained change history; re-list and watch again")
+		return
+	}
+	writeStoreErr(w, err)
+}
+
+// errStreamWrite ends a watch whose client has gone away.
+var errStreamWrite = errors.New("watch stream write failed")
+
+// sseStream writes a watch's Server-Sent Events. Watch deliveries and the
+// heartbeat write concurrently, so every write holds mu.
+type sseStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+
+	mu      sync.Mutex
+	started bool
+	// sentRev is the last id written and rev the revision the watch has
+	// reached; the heartbeat writes rev when it is ahead.
+	sentRev, rev int64
+	err          error
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// start writes the stream's headers and its first id, the revision the watch
+// starts at, and starts the heartbeat.
+func (s *sseStream) start(rev int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = true
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	s.printf("retry: %d\nid: %d\n\n", watchRetry.Milliseconds(), rev)
+	s.sentRev, s.rev = rev, rev
+	s.done = make(chan struct{})
+	s.wg.Go(s.heartbeat)
+	return s.flush()
+}
+
+// send writes one delivery's events.
+func (s *sseStream) send(events []statestore.KVEvent, rev int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ev := range events {
+		data, err := json.Marshal(stateapi.WatchEvent{
+			Key: ev.Key, Value: ev.Value, Version: ev.Version, Revision: ev.Revision, ExpiresAt: ev.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		s.printf("id: %d\nevent: %s\ndata: %s\n\n", ev.Revision, ev.Type, data)
+		s.sentRev = ev.Revision
+	}
+	s.rev = rev
+	if len(events) == 0 {
+		return s.err
+	}
+	return s.flush()
+}
+
+// heartbeat keeps an idle stream open and moves the client's resume point
+// past changes outside the prefix.
+func (s *sseStream) heartbeat() {
+	t := time.NewTicker(watchHeartbeat)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		}
+		s.mu.Lock()
+		if s.rev > s.sentRev {
+			s.printf("id: %d\n\n", s.rev)
+			s.sentRev = s.rev
+		} else {
+			s.printf(":\n\n")
+		}
+		_ = s.flush()
+		s.mu.Unlock()
+	}
+}
+
+// fail ends a started stream with an error event.
+func (s *sseStream) fail(err error) {
+	code, msg := stateapi.CodeInternal, "internal error"
+	if errors.Is(err, statestore.ErrCompacted) {
+		code, msg = stateapi.CodeCompacted, "the watch fell behind the retained change history; re-list and watch again"
+	}
+	data, _ := json.Marshal(stateapi.Error{Error: msg, Code: code})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.printf("event: %s\ndata: %s\n\n", stateapi.EventError, data)
+	_ = s.flush()
+}
+
+// stopHeartbeat stops the heartbeat and waits for it.
+func (s *sseStream) stopHeartbeat() {
+	if s.done != nil {
+		close(s.done)
+		s.wg.Wait()
+	}
+}
+
+// printf writes to the stream, remembering the first failure. Caller holds mu.
+func (s *sseStream) printf(format string, args ...any) {
+	if s.err != nil {
+		return
+	}
+	if _, err := fmt.Fprintf(s.w, format, args...); err != nil {
+		s.err = errStreamWrite
+	}
+}
+
+// flush pushes buffered events to the client. Caller holds mu.
+func (s *sseStream) flush() error {
+	if s.err == nil && s.rc.Flush() != nil {
+		s.err = errStreamWrite
+	}
+	return s.err
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package statesvc
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fission/fission/pkg/statestore"
+	"github.com/fission/fission/pkg/statesvc/stateapi"
+)
+
+// sseEvent is one parsed Server-Sent Events block.
+type sseEvent struct {
+	id, event, data string
+}
+
+// nextEvent reads the next block that carries an event, skipping comments and
+// id-only blocks.
+func nextEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	for {
+		var ev sseEvent
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				break
+			}
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				ev.id = value
+			case "event":
+				ev.event = value
+			case "data":
+				ev.data = value
+			}
+		}
+		if ev.event != "" {
+			return ev
+		}
+	}
+}
+
+func TestHandlerWatchStream(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t, twoFns())
+	tok := stateToken("ns-a", "fn-a")
+
+	resp := doState(t, srv, http.MethodGet, "/v1/state?watch=cfg.", "ns-a", "fn-a", tok, nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	stream := bufio.NewReader(resp.Body)
+
+	put := doState(t, srv, http.MethodPut, "/v1/state/cfg.flag", "ns-a", "fn-a", tok, []byte("on"), nil)
+	require.Equal(t, http.StatusNoContent, put.StatusCode)
+	put = doState(t, srv, http.MethodPut, "/v1/state/other", "ns-a", "fn-a", tok, []byte("x"), nil)
+	require.Equal(t, http.StatusNoContent, put.StatusCode)
+	put = doState(t, srv, http.MethodPut, "/v1/state/cfg.flag", "ns-b", "fn-b", stateToken("ns-b", "fn-b"), []byte("elsewhere"), nil)
+	require.Equal(t, http.StatusNoContent, put.StatusCode)
+	del := doState(t, srv, http.MethodDelete, "/v1/state/cfg.flag", "ns-a", "fn-a", tok, nil, nil)
+	require.Equal(t, http.StatusNoContent, del.StatusCode)
+
+	ev := nextEvent(t, stream)
+	assert.Equal(t, stateapi.EventPut, ev.event)
+	assert.Equal(t, "1", ev.id)
+	var we stateapi.WatchEvent
+	require.NoError(t, json.Unmarshal([]byte(ev.data), &we))
+	assert.Equal(t, "cfg.flag", we.Key)
+	assert.Equal(t, "on", string(we.Value))
+	assert.EqualValues(t, 1, we.Version)
+
+	ev = nextEvent(t, stream)
+	assert.Equal(t, stateapi.EventDelete, ev.event)
+	assert.Equal(t, "3", ev.id, "changes outside the prefix still count revisions; other keyspaces do not")
+
+	// A reconnect resumes after Last-Event-ID.
+	resumed := doState(t, srv, http.MethodGet, "/v1/state?watch=cfg.", "ns-a", "fn-a", tok, nil,
+		map[string]string{"Last-Event-ID": "1"})
+	require.Equal(t, http.StatusOK, resumed.StatusCode)
+	ev = nextEvent(t, bufio.NewReader(resumed.Body))
+	assert.Equal(t, stateapi.EventDelete, ev.event)
+	assert.Equal(t, "3", ev.id)
+}
+
+func TestHandlerWatchResumeErrors(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t, twoFns())
+	tok := stateToken("ns-a", "fn-a")
+
+	resp := doState(t, srv, http.MethodGet, "/v1/state?watch=&revision=-1", "ns-a", "fn-a", tok, nil, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	for i := range statestore.KVWatchHistory + 1 {
+		put := doState(t, srv, http.MethodPut, "/v1/state/k", "ns-a", "fn-a", tok, []byte(strconv.Itoa(i)), nil)
+		require.Equal(t, http.StatusNoContent, put.StatusCode)
+	}
+	resp = doState(t, srv, http.MethodGet, "/v1/state?watch=", "ns-a", "fn-a", tok, nil,
+		map[string]string{"Last-Event-ID": "0"})
+	require.Equal(t, http.StatusGone, resp.StatusCode)
+	var e stateapi.Error
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+	assert.Equal(t, stateapi.CodeCompacted, e.Code)
+}
@@ -144,8 +144,8 @@ func (client *StorageClient) exists(itemID string) error {
 // filter reports whether an item must be left out of a listing.
 type filter func(objectInfo) bool
 
-// getItemIDsWithFilter returns the IDs of all items in the container that the
-// filter does not exclude.
+// getItemIDsWithFilter returns the IDs of all archives in the container that
+// the filter does not exclude. Async invocation payloads are never included.
 func (client *StorageClient) getItemIDsWithFilter(exclude filter) ([]string, error) {
 	items, err := client.backend.list(client.config.storage.getSubDir())
 	if err != nil {
@@ -154,7 +154,7 @@ func (client *StorageClient) getItemIDsWithFilter(exclude filter) ([]string, err
 
 	archiveIDList := make([]string, 0)
 	for _, item := range items {
-		if exclude(item) {
+		if isPayloadID(item.id) || exclude(item) {
 			continue
 		}
 		archiveIDList = append(archiveIDList, item.id)
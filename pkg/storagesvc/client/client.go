@@ -57,6 +57,12 @@ type (
 		Download(ctx context.Context, id string, filePath string) error
 		GetFile(ctx context.Context, id string) (*http.Response, error)
 		Delete(ctx context.Context, id string) error
+		// PutPayload, GetPayload and DeletePayload manage async invocation
+		// payloads: content-addressed blobs the router spills out of the
+		// async queue, named "<sha256 hex>.<generation>" within namespace.
+		PutPayload(ctx context.Context, namespace, name string, r io.Reader, size int64) error
+		GetPayload(ctx context.Context, namespace, name string) (io.ReadCloser, error)
+		DeletePayload(ctx context.Context, namespace, name string) error
 	}
 	client struct {
 		url        string
@@ -415,3 +421,67 @@ func (c *client) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (c *client) payloadURL(namespace, name string) string {
+	return c.url + "/payload?" + url.Values{"namespace": {namespace}, "name": {name}}.Encode()
+}
+
+// PutPayload uploads size bytes from r as payload name in namespace.
+func (c *client) PutPayload(ctx context.Context, namespace, name string, r io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.payloadURL(namespace, name), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("payload upload error %v", resp.Status)
+	}
+	return nil
+}
+
+// GetPayload opens payload name in namespace for reading. The caller closes
+// the returned reader. A missing payload is an error wrapping
+// storagesvc.ErrNotFound.
+func (c *client) GetPayload(ctx context.Context, namespace, name string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.payloadURL(namespace, name), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("payload %s/%s: %w", namespace, name, storagesvc.ErrNotFound)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("payload download error %v", resp.Status)
+	}
+}
+
+// DeletePayload deletes payload name in namespace; a payload that is already
+// gone is not an error.
+func (c *client) DeletePayload(ctx context.Context, namespace, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.payloadURL(namespace, name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("payload delete error %v", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package storagesvc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	hmacauth "github.com/fission/fission/pkg/auth/hmac"
+	otelUtils "github.com/fission/fission/pkg/utils/otel"
+)
+
+// payloadMarker is the path segment under which async invocation payloads are
+// stored (".../_payload_/<namespace>/<name>"). Like archiveTenantMarker it
+// contains an underscore, so it cannot collide with a namespace or a UUID, and
+// it is RESERVED as a sub-directory name. Payloads are not archives: they are
+// left out of archive listings and never reaped by the archive pruner — their
+// owner (the router's async dispatcher) deletes them.
+const payloadMarker = "_payload_"
+
+// payloadNameRE is the only accepted payload name: the hex SHA-256 of the
+// content, a '.', and a short generation token. Being this strict keeps a
+// name from ever carrying a path separator or "..".
+var payloadNameRE = regexp.MustCompile(`^[0-9a-f]{64}\.[0-9a-z]{1,32}$`)
+
+// errDigestMismatch is returned by putPayload when the uploaded content does
+// not hash to the digest in its name.
+var errDigestMismatch = errors.New("payload content does not match its digest")
+
+// isPayloadID reports whether id names a payload rather than an archive.
+func isPayloadID(id string) bool {
+	return slices.Contains(strings.Split(path.Clean(strings.ReplaceAll(id, "\\", "/")), "/"), payloadMarker)
+}
+
+// payloadObjectName is the backend name of payload name owned by namespace.
+func (client *StorageClient) payloadObjectName(namespace, name string) string {
+	return path.Join(client.config.storage.getSubDir(), payloadMarker, namespace, name)
+}
+
+// putPayload stores r as payload name, verifying that the content hashes to
+// the digest the name carries. A mismatching upload is removed again. A
+// payload already stored at the expected size is left alone rather than
+// rewritten under a concurrent reader; a reader verifies the digest anyway.
+func (client *StorageClient) putPayload(namespace, name string, r io.Reader, size int64) error {
+	objectName := client.payloadObjectName(namespace, name)
+	if stored, err := client.getFileSize(objectName); err == nil && size >= 0 && stored == size {
+		return nil
+	}
+	h := sha256.New()
+	if _, err := client.backend.put(objectName, io.TeeReader(r, h), size); err != nil {
+		client.logger.Error(err, "error writing payload on storage", "payload", objectName)
+		return ErrWritingFile
+	}
+	if hex.EncodeToString(h.Sum(nil)) != name[:sha256.Size*2] {
+		_ = client.backend.remove(objectName)
+		return errDigestMismatch
+	}
+	return nil
+}
+
+// payloadRequest validates the ?namespace and ?name of a payload request and
+// authorizes the caller for the namespace, writing the error response and
+// returning false when the request cannot be served.
+func (ss *StorageService) payloadRequest(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+	if !validNamespaceLabel(namespace) || !payloadNameRE.MatchString(name) {
+		http.Error(w, "a valid namespace and payload name are required", http.StatusBadRequest)
+		return "", "", false
+	}
+	// A namespace-scoped caller may only touch its own namespace's payloads;
+	// 404 rather than 403, as for archives.
+	if authNS, _ := hmacauth.AuthenticatedNamespace(r.Context()); authNS != "" && authNS != namespace {
+		http.Error(w, "not found", http.StatusNotFound)
+		return "", "", false
+	}
+	return namespace, name, true
+}
+
+// payloadPutHandler stores the raw request body as a payload. The name is
+// content-addressed, so re-uploading the same payload is harmless.
+func (ss *StorageService) payloadPutHandler(w http.ResponseWriter, r *http.Request) {
+	namespace, name, ok := ss.payloadRequest(w, r)
+	if !ok {
+		return
+	}
+	err := ss.storageClient.putPayload(namespace, name, r.Body, r.ContentLength)
+	switch {
+	case errors.Is(err, errDigestMismatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		otelUtils.LoggerWithTraceID(r.Context(), ss.logger).Error(err, "error saving payload", "namespace", namespace, "name", name)
+		http.Error(w, "Error saving payload", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (ss *StorageService) payloadGetHandler(w http.ResponseWriter, r *http.Request) {
+	namespace, name, ok := ss.payloadRequest(w, r)
+	if !ok {
+		return
+	}
+	objectName := ss.storageClient.payloadObjectName(namespace, name)
+	size, err := ss.storageClient.getFileSize(objectName)
+	if err == nil {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		err = ss.storageClient.copyFileToStream(objectName, w)
+	}
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, "payload not found", http.StatusNotFound)
+	case err != nil:
+		otelUtils.LoggerWithTraceID(r.Context(), ss.logger).Error(err, "error reading payload", "namespace", namespace, "name", name)
+		http.Error(w, "Error reading payload", http.StatusInternalServerError)
+	}
+}
+
+// payloadDeleteHandler deletes a payload; deleting one that is already gone
+// succeeds, so the owner can retry a delete blindly.
+func (ss *StorageService) payloadDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	namespace, name, ok := ss.payloadRequest(w, r)
+	if !ok {
+		return
+	}
+	err := ss.storageClient.removeFileByID(ss.storageClient.payloadObjectName(namespace, name))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		otelUtils.LoggerWithTraceID(r.Context(), ss.logger).Error(err, "error deleting payload", "namespace", namespace, "name", name)
+		http.Error(w, "Error deleting payload", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
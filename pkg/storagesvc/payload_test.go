@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package storagesvc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadHandlers(t *testing.T) {
+	t.Parallel()
+	sc, err := MakeStorageClient(logr.Discard(), NewLocalStorage(t.TempDir()))
+	require.NoError(t, err)
+	h := MakeStorageService(logr.Discard(), sc, nil, nil, 0).makeHandler()
+
+	content := strings.Repeat("spilled body ", 1000)
+	sum := sha256.Sum256([]byte(content))
+	name := hex.EncodeToString(sum[:]) + ".g1"
+	do := func(method, ns, name, body string) *httptest.ResponseRecorder {
+		target := "/v1/payload?" + url.Values{"namespace": {ns}, "name": {name}}.Encode()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "team-a", name, content).Code)
+	rr := do(http.MethodGet, "team-a", name, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, content, rr.Body.String())
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "team-b", name, "").Code, "payloads are namespace-scoped")
+
+	ids, err := sc.getItemIDsWithFilter(sc.filterAllItems)
+	require.NoError(t, err)
+	assert.Empty(t, ids, "payloads are not archives: never listed or pruned")
+
+	t.Run("content must match the digest", func(t *testing.T) {
+		other := strings.Repeat("0", 64) + ".g1"
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "team-a", other, content).Code)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "team-a", other, "").Code, "a mismatching upload is not kept")
+	})
+	t.Run("names cannot escape the payload directory", func(t *testing.T) {
+		for _, bad := range []string{"../" + name, name + "/x", "abc.g1", strings.ToUpper(name)} {
+			assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "team-a", bad, "").Code, bad)
+		}
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "../team-a", name, "").Code)
+	})
+
+	require.Equal(t, http.StatusOK, do(http.MethodDelete, "team-a", name, "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodDelete, "team-a", name, "").Code, "deleting twice succeeds")
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "team-a", name, "").Code)
+}
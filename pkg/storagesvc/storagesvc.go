@@ -379,6 +379,10 @@ func (ss *StorageService) makeHandler() http.Handler {
 	m.HandleFunc("/v1/archive", ss.getOrListHandler).Methods("GET")
 	m.HandleFunc("/v1/archive", ss.deleteHandler).Methods("DELETE")
 	m.HandleFunc("/v1/archive", ss.infoHandler).Methods("HEAD")
+	// Async invocation payloads spilled by the router (payload.go).
+	m.HandleFunc("/v1/payload", ss.payloadPutHandler).Methods("PUT")
+	m.HandleFunc("/v1/payload", ss.payloadGetHandler).Methods("GET")
+	m.HandleFunc("/v1/payload", ss.payloadDeleteHandler).Methods("DELETE")
 	m.HandleFunc("/healthz", ss.healthHandler).Methods("GET")
 
 	// Storagesvc is router/builder/function-pod internal per
@@ -47,6 +47,9 @@ const (
 	PortMCP = 8890
 	// PortStatestore is the embedded statestore's capability API port (RFC-0021).
 	PortStatestore = 8891
+	// PortStatestorePeer is the Raft transport port between the replicas of
+	// the replicated embedded statestore (statestore.embedded.engine=raft).
+	PortStatestorePeer = 8894
 	// PortWorkflow is the workflow engine head's port (RFC-0022): health
 	// probes plus the read-only run-history endpoint.
 	PortWorkflow = 8892
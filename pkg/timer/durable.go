@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package timer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+)
+
+const (
+	// slotKeyspace holds one create-only claim per fired slot, scoped to the
+	// trigger, so a slot is enqueued once however many timer replicas (or a
+	// catch-up after restart) reach it.
+	slotKeyspace = "timer-slots"
+	// slotClaimTTL outlives any replica lag or catch-up window by a wide
+	// margin; claims are only bookkeeping and expire on their own.
+	slotClaimTTL = 24 * time.Hour
+
+	// slotEnqueueAttempts / slotEnqueueBackoff bound how long a firing retries
+	// an unreachable store before the slot is reported lost.
+	slotEnqueueAttempts = 5
+	slotEnqueueBackoff  = 500 * time.Millisecond
+)
+
+// slotEnqueuer is the timer's durable firing path: each slot becomes one
+// RFC-0024 async invocation on the router's queue, so delivery, retries and
+// the dead-letter queue (and `fission fn dlq` redrive) are the async
+// dispatcher's, not the timer's. Exactly-once per slot rests on two guards:
+// a create-only KV claim keyed by trigger UID and slot, which stops a second
+// replica or a restarted timer from enqueuing a slot already enqueued, and
+// the same key as the message DedupKey, which collapses an enqueue retried
+// after an ambiguous store error.
+//
+// ConcurrencyPolicy is enforced on the queue too, so it holds across
+// replicas. A Forbid or Replace trigger's slots share one message group,
+// which the dispatcher delivers one at a time: two of its invocations never
+// overlap. The trigger's last enqueued invocation is recorded next to its
+// slot claims, and its status decides the new slot: Forbid skips it while
+// that invocation is unsettled, Replace cancels that invocation if it has
+// not started yet. An attempt already in flight cannot be recalled from
+// another process, so under Replace the new slot waits for it in the group.
+type slotEnqueuer struct {
+	queue     statestore.Queue
+	kv        statestore.KVStore
+	status    *asyncinvoke.StatusStore
+	queueName string
+	// resolveFn stamps the target function's InvocationConfig (retry policy,
+	// destinations, timeout) into the envelope, exactly as a router enqueue
+	// does. nil, or a function that does not exist yet, enqueues with the
+	// dispatcher defaults; delivery then fails and dead-letters visibly.
+	resolveFn asyncinvoke.FunctionConfigResolver
+	now       func() time.Time
+}
+
+// slotResult is what enqueue did with a slot.
+type slotResult int
+
+const (
+	// slotEnqueued: the slot is on the queue.
+	slotEnqueued slotResult = iota
+	// slotAlreadyClaimed: another replica, or an earlier catch-up, took it.
+	slotAlreadyClaimed
+	// slotForbidden: a Forbid trigger's previous invocation is unsettled.
+	slotForbidden
+)
+
+// slotScope is the KV scope of a trigger's slot claims.
+func slotScope(t fv1.TimeTrigger) statestore.Scope {
+	return statestore.Scope{
+		Namespace: t.Namespace,
+		Owner:     "timetrigger/" + t.Name,
+		Keyspace:  slotKeyspace,
+	}
+}
+
+// slotKey identifies one slot of one trigger incarnation: the UID keeps a
+// deleted-and-recreated trigger of the same name from inheriting claims.
+func slotKey(t fv1.TimeTrigger, slot time.Time) string {
+	return "timetrigger/" + string(t.UID) + "/" + strconv.FormatInt(slot.Unix(), 10)
+}
+
+// lastKey holds the id of the last invocation enqueued for a Forbid or
+// Replace trigger incarnation.
+func lastKey(t fv1.TimeTrigger) string {
+	return "timetrigger/" + string(t.UID) + "/last"
+}
+
+// slotGroup is the message group a Forbid or Replace trigger incarnation's
+// slots are delivered in, keyed by UID like slotKey so a recreated trigger is
+// not ordered behind its predecessor's leftovers; Allow slots are ungrouped
+// and may overlap.
+func slotGroup(t fv1.TimeTrigger) string {
+	if t.Spec.GetConcurrencyPolicy() == fv1.TimeTriggerConcurrencyAllow {
+		return ""
+	}
+	return "timetrigger/" + string(t.UID)
+}
+
+// slotEnvelope builds the async envelope that invokes t's function for slot,
+// carrying the rendered Body and Headers. An alias pin rides the function
+// route name (`name:alias`), a version pin rides FunctionVersion so the
+// deliverer's GC fallback applies, mirroring how a pinned async destination
+// is fired.
+func slotEnvelope(t fv1.TimeTrigger, cfg asyncinvoke.FunctionConfig, slot, now time.Time) (asyncinvoke.Envelope, error) {
+	body, headers, err := renderPayload(t, slot, now)
+	if err != nil {
+		return asyncinvoke.Envelope{}, err
+	}
+	function := t.Spec.Name
+	if t.Spec.Version == "" && t.Spec.Alias != "" {
+		function += ":" + t.Spec.Alias
+	}
+	method := t.Spec.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	return asyncinvoke.Envelope{
+		Version:         asyncinvoke.EnvelopeVersion,
+		Namespace:       t.Namespace,
+		Function:        function,
+		FunctionVersion: t.Spec.Version,
+		Method:          method,
+		Subpath:         t.Spec.Subpath,
+		Headers:         headers,
+		Body:            []byte(body),
+		EnqueueTime:     now,
+		MessageGroup:    slotGroup(t),
+		FunctionTimeout: cfg.FunctionTimeout,
+		Policy:          cfg.Policy,
+		OnSuccess:       cfg.OnSuccess,
+		OnFailure:       cfg.OnFailure,
+	}, nil
+}
+
+// enqueue durably enqueues slot for t under its ConcurrencyPolicy. A slot
+// already claimed (fired elsewhere or earlier), or skipped by Forbid, is not
+// an error. On a failure the claim is released so a retry or a later
+// catch-up can fire the slot.
+func (s *slotEnqueuer) enqueue(ctx context.Context, t fv1.TimeTrigger, slot time.Time) (string, slotResult, error) {
+	key := slotKey(t, slot)
+	if claimed, err := s.claim(ctx, t, slot); !claimed || err != nil {
+		return "", slotAlreadyClaimed, err
+	}
+	id, res, err := s.enqueueClaimed(ctx, t, slot, key)
+	if err != nil {
+		// Release on a detached context: the claim must not outlive a slot
+		// that never made it onto the queue just because ctx is done.
+		dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slotEnqueueBackoff)
+		defer cancel()
+		_ = s.kv.Delete(dctx, slotScope(t), key, 1) // version 1: our own create
+		return "", slotEnqueued, err
+	}
+	return id, res, nil
+}
+
+func (s *slotEnqueuer) enqueueClaimed(ctx context.Context, t fv1.TimeTrigger, slot time.Time, key string) (string, slotResult, error) {
+	group := slotGroup(t)
+	var lastVersion int64
+	if group != "" {
+		prev, version, err := s.last(ctx, t)
+		if err != nil {
+			return "", slotEnqueued, err
+		}
+		lastVersion = version
+		if prev != "" {
+			if forbidden, err := s.applyPolicy(ctx, t, prev); forbidden || err != nil {
+				return "", slotForbidden, err
+			}
+		}
+	}
+
+	var cfg asyncinvoke.FunctionConfig
+	if s.resolveFn != nil {
+		cfg, _ = s.resolveFn(ctx, t.Namespace, t.Spec.Name)
+	}
+	env, err := slotEnvelope(t, cfg, slot, s.now())
+	var data []byte
+	if err == nil {
+		data, err = env.Encode()
+	}
+	opts := statestore.EnqueueOptions{DedupKey: key}
+	if group != "" {
+		opts.Group = asyncinvoke.GroupKey(env.Namespace, env.Function, group)
+	}
+	var id string
+	if err == nil {
+		id, err = s.queue.Enqueue(ctx, s.queueName, statestore.Message{Body: data}, opts)
+	}
+	if err != nil {
+		return "", slotEnqueued, fmt.Errorf("enqueuing slot: %w", err)
+	}
+	s.status.Queued(ctx, id, env)
+	if group != "" {
+		// Best effort: the slot is on the queue either way, and its group
+		// still keeps it from overlapping the previous invocation. A
+		// conflict means a concurrent slot recorded itself, which is as good.
+		_ = s.kv.Set(ctx, slotScope(t), lastKey(t), []byte(id),
+			statestore.SetOptions{IfVersion: &lastVersion, TTL: slotClaimTTL})
+	}
+	return id, slotEnqueued, nil
+}
+
+// last returns t's last enqueued invocation id and the version of its
+// record, "" and 0 when there is none.
+func (s *slotEnqueuer) last(ctx context.Context, t fv1.TimeTrigger) (string, int64, error) {
+	v, err := s.kv.Get(ctx, slotScope(t), lastKey(t))
+	if errors.Is(err, statestore.ErrNotFound) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("reading last invocation: %w", err)
+	}
+	return string(v.Data), v.Version, nil
+}
+
+// applyPolicy applies t's Forbid or Replace policy to its previous
+// invocation prev, reporting whether Forbid skips the new slot. An invocation
+// whose status has expired is long settled.
+func (s *slotEnqueuer) applyPolicy(ctx context.Context, t fv1.TimeTrigger, prev string) (bool, error) {
+	switch t.Spec.GetConcurrencyPolicy() {
+	case fv1.TimeTriggerConcurrencyForbid:
+		st, err := s.status.Get(ctx, t.Namespace, prev)
+		if errors.Is(err, statestore.ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("reading last invocation status: %w", err)
+		}
+		switch st.State {
+		case asyncinvoke.StateQueued, asyncinvoke.StateRunning, asyncinvoke.StateRetrying:
+			return true, nil
+		}
+	case fv1.TimeTriggerConcurrencyReplace:
+		_, err := s.status.Cancel(ctx, t.Namespace, prev)
+		if err != nil && !errors.Is(err, statestore.ErrNotFound) && !errors.Is(err, asyncinvoke.ErrNotCancellable) {
+			return false, fmt.Errorf("cancelling last invocation: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// claim takes slot's create-only claim for t. It returns false, and no
+// error, when the slot is already claimed.
+func (s *slotEnqueuer) claim(ctx context.Context, t fv1.TimeTrigger, slot time.Time) (bool, error) {
+	err := s.kv.Set(ctx, slotScope(t), slotKey(t, slot), []byte(slot.UTC().Format(time.RFC3339)),
+		statestore.SetOptions{IfVersion: new(int64(0)), TTL: slotClaimTTL})
+	if errors.Is(err, statestore.ErrVersionConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claiming slot: %w", err)
+	}
+	return true, nil
+}
+
+// fireDurable enqueues slot for t, retrying an unreachable store with a
+// bounded doubling backoff. A slot that still cannot be enqueued is lost and
+// logged at error level: the queue, not the timer, owns retrying delivery.
+// The retries stop when the timer does.
+func (timer *Timer) fireDurable(t fv1.TimeTrigger, slot time.Time) {
+	logger := timer.logger.WithValues("trigger_name", t.Name, "trigger_namespace", t.Namespace, "slot", slot)
+	delay := slotEnqueueBackoff
+	var err error
+	for attempt := 1; attempt <= slotEnqueueAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(timer.ctx, 10*time.Second)
+		var (
+			id  string
+			res slotResult
+		)
+		id, res, err = timer.slots.enqueue(ctx, t, slot)
+		cancel()
+		if err == nil {
+			switch res {
+			case slotEnqueued:
+				logger.Info("enqueued time trigger slot", "invocationId", id)
+			case slotAlreadyClaimed:
+				logger.V(1).Info("time trigger slot already enqueued; skipping")
+			case slotForbidden:
+				logger.Info("skipping schedule slot, previous invocation still running", "concurrency_policy", fv1.TimeTriggerConcurrencyForbid)
+			}
+			return
+		}
+		if attempt < slotEnqueueAttempts && !sleepCtx(timer.ctx, delay) {
+			logger.Info("timer stopping; abandoning time trigger slot", "error", err)
+			return
+		}
+		delay *= 2
+	}
+	logger.Error(err, "enqueuing time trigger slot failed, giving up")
+}
+
+// sleepCtx sleeps d or returns false when ctx ends first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
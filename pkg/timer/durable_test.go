@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package timer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/statestore"
+	// Register the in-memory driver for the slot enqueue tests.
+	_ "github.com/fission/fission/pkg/statestore/memory"
+)
+
+func memSlotEnqueuer(t *testing.T) *slotEnqueuer {
+	t.Helper()
+	caps, err := statestore.Open(t.Context(), statestore.Config{Driver: "memory"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = caps.Close() })
+	q, err := caps.Queue()
+	require.NoError(t, err)
+	kv, err := caps.KV()
+	require.NoError(t, err)
+	return &slotEnqueuer{
+		queue:     q,
+		kv:        kv,
+		status:    asyncinvoke.NewStatusStore(kv, 0, logr.Discard()),
+		queueName: asyncinvoke.DefaultQueue,
+		now:       time.Now,
+	}
+}
+
+func slotTrigger() fv1.TimeTrigger {
+	return fv1.TimeTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "tt", Namespace: "ns", UID: "uid-1"},
+		Spec: fv1.TimeTriggerSpec{
+			Cron:              "@every 1m",
+			FunctionReference: fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "fn"},
+		},
+	}
+}
+
+// failingQueue fails every enqueue, standing in for an unreachable store.
+type failingQueue struct{ statestore.Queue }
+
+func (failingQueue) Enqueue(context.Context, string, statestore.Message, statestore.EnqueueOptions) (string, error) {
+	return "", errors.New("store unavailable")
+}
+
+func TestSlotEnqueueOncePerSlot(t *testing.T) {
+	t.Parallel()
+	s := memSlotEnqueuer(t)
+	tt := slotTrigger()
+	slot := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	id, res, err := s.enqueue(t.Context(), tt, slot)
+	require.NoError(t, err)
+	require.Equal(t, slotEnqueued, res)
+	require.NotEmpty(t, id)
+
+	// A second replica (or a restarted timer catching up) reaching the same
+	// slot finds it claimed and enqueues nothing.
+	_, res, err = s.enqueue(t.Context(), tt, slot)
+	require.NoError(t, err)
+	assert.Equal(t, slotAlreadyClaimed, res)
+
+	// The next slot is its own claim.
+	_, res, err = s.enqueue(t.Context(), tt, slot.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, slotEnqueued, res)
+
+	// A recreated trigger (new UID) does not inherit the old claims.
+	tt.UID = "uid-2"
+	_, res, err = s.enqueue(t.Context(), tt, slot)
+	require.NoError(t, err)
+	assert.Equal(t, slotEnqueued, res)
+
+	stats, err := s.queue.Stats(t.Context(), asyncinvoke.DefaultQueue)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, stats.Visible)
+
+	l, err := s.queue.Lease(t.Context(), asyncinvoke.DefaultQueue, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	assert.Equal(t, id, l[0].ID)
+	env, err := asyncinvoke.Decode(l[0].Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ns", env.Namespace)
+	assert.Equal(t, "fn", env.Function)
+	assert.Equal(t, "POST", env.Method)
+	assert.Empty(t, env.MessageGroup, "Allow slots may overlap")
+
+	st, err := s.status.Get(t.Context(), "ns", id)
+	require.NoError(t, err)
+	assert.Equal(t, asyncinvoke.StateQueued, st.State, "a slot is recorded like any async invocation")
+}
+
+func TestSlotEnqueueReleasesClaimOnFailure(t *testing.T) {
+	t.Parallel()
+	s := memSlotEnqueuer(t)
+	tt := slotTrigger()
+	slot := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	working := s.queue
+	s.queue = failingQueue{working}
+	_, _, err := s.enqueue(t.Context(), tt, slot)
+	require.Error(t, err)
+
+	// The failed slot's claim was released, so a retry can still enqueue it.
+	s.queue = working
+	_, res, err := s.enqueue(t.Context(), tt, slot)
+	require.NoError(t, err)
+	assert.Equal(t, slotEnqueued, res)
+}
+
+func TestSlotEnvelope(t *testing.T) {
+	t.Parallel()
+	cfg := asyncinvoke.FunctionConfig{
+		FunctionTimeout: 45,
+		Policy:          asyncinvoke.Policy{MaxAttempts: 7},
+	}
+
+	slot := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := slot.Add(2 * time.Second)
+
+	tt := slotTrigger()
+	tt.Spec.Method = "PUT"
+	tt.Spec.Subpath = "/tick"
+	tt.Spec.Body = `{"slot":"{{ .ScheduledTime }}"}`
+	tt.Spec.Headers = map[string]string{"X-Window": "hourly"}
+	env, err := slotEnvelope(tt, cfg, slot, now)
+	require.NoError(t, err)
+	assert.Equal(t, asyncinvoke.EnvelopeVersion, env.Version)
+	assert.Equal(t, "fn", env.Function)
+	assert.Empty(t, env.FunctionVersion)
+	assert.Equal(t, "PUT", env.Method)
+	assert.Equal(t, "/tick", env.Subpath)
+	assert.Equal(t, `{"slot":"2026-03-01T09:00:00Z"}`, string(env.Body))
+	assert.Equal(t, map[string]string{
+		"X-Fission-Timer-Name": "tt",
+		"X-Window":             "hourly",
+		"Content-Type":         "application/json",
+	}, env.Headers)
+	assert.Equal(t, now, env.EnqueueTime)
+	assert.Equal(t, 45, env.FunctionTimeout)
+	assert.Equal(t, 7, env.Policy.MaxAttempts)
+
+	alias := slotTrigger()
+	alias.Spec.Alias = "live"
+	env, err = slotEnvelope(alias, cfg, slot, now)
+	require.NoError(t, err)
+	assert.Equal(t, "fn:live", env.Function)
+	assert.Empty(t, env.Body)
+
+	version := slotTrigger()
+	version.Spec.Version = "fn-v2"
+	env, err = slotEnvelope(version, cfg, slot, now)
+	require.NoError(t, err)
+	assert.Equal(t, "fn", env.Function)
+	assert.Equal(t, "fn-v2", env.FunctionVersion)
+}
+
+// TestSlotEnqueueForbid: a Forbid trigger's slot is skipped, on every
+// replica sharing the store, while the previous invocation is unsettled.
+func TestSlotEnqueueForbid(t *testing.T) {
+	t.Parallel()
+	replicaA := memSlotEnqueuer(t)
+	replicaB := *replicaA
+	tt := slotTrigger()
+	tt.Spec.ConcurrencyPolicy = fv1.TimeTriggerConcurrencyForbid
+	slot := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	id, res, err := replicaA.enqueue(t.Context(), tt, slot)
+	require.NoError(t, err)
+	require.Equal(t, slotEnqueued, res)
+
+	_, res, err = replicaB.enqueue(t.Context(), tt, slot.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, slotForbidden, res, "the previous invocation is still queued")
+	_, res, err = replicaB.enqueue(t.Context(), tt, slot.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, slotAlreadyClaimed, res, "a skipped slot stays claimed")
+
+	// Once it settles the next slot runs.
+	_, err = replicaA.status.Cancel(t.Context(), "ns", id)
+	require.NoError(t, err)
+	_, res, err = replicaB.enqueue(t.Context(), tt, slot.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, slotEnqueued, res)
+}
+
+// TestSlotEnqueueReplace: a Replace trigger's slot cancels the previous
+// invocation before it starts, and the trigger's invocations share a message
+// group, so one that already started is never overlapped.
+func TestSlotEnqueueReplace(t *testing.T) {
+	t.Parallel()
+	s := memSlotEnqueuer(t)
+	tt := slotTrigger()
+	tt.Spec.ConcurrencyPolicy = fv1.TimeTriggerConcurrencyReplace
+	slot := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	first, res, err := s.enqueue(t.Context(), tt, slot)
+	require.NoError(t, err)
+	require.Equal(t, slotEnqueued, res)
+	second, res, err := s.enqueue(t.Context(), tt, slot.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, slotEnqueued, res)
+
+	st, err := s.status.Get(t.Context(), "ns", first)
+	require.NoError(t, err)
+	assert.Equal(t, asyncinvoke.StateCancelled, st.State)
+	st, err = s.status.Get(t.Context(), "ns", second)
+	require.NoError(t, err)
+	assert.Equal(t, asyncinvoke.StateQueued, st.State)
+
+	gq, ok := s.queue.(statestore.GroupedQueue)
+	require.True(t, ok)
+	l, err := gq.LeaseGrouped(t.Context(), asyncinvoke.DefaultQueue, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1, "one invocation of the trigger in flight at a time")
+	assert.Equal(t, first, l[0].ID)
+	env, err := asyncinvoke.Decode(l[0].Body)
+	require.NoError(t, err)
+	assert.Equal(t, "timetrigger/uid-1", env.MessageGroup)
+
+	// A trigger recreated under the same name is a new group, not queued
+	// behind its predecessor's leftovers.
+	tt.UID = "uid-2"
+	third, res, err := s.enqueue(t.Context(), tt, slot.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, slotEnqueued, res)
+	l, err = gq.LeaseGrouped(t.Context(), asyncinvoke.DefaultQueue, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, l, 1)
+	assert.Equal(t, third, l[0].ID)
+}
+
+// TestFireDurableStopsWithTimer: retrying an unreachable store gives up as
+// soon as the timer stops, rather than sleeping out its backoff.
+func TestFireDurableStopsWithTimer(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(t.Context())
+	timer := MakeTimer(ctx, logr.Discard(), nil, "http://router.fission")
+	timer.slots = memSlotEnqueuer(t)
+	timer.slots.queue = failingQueue{timer.slots.queue}
+
+	done := make(chan struct{})
+	go func() {
+		timer.fireDurable(slotTrigger(), time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(slotEnqueueBackoff):
+		t.Fatal("fireDurable kept backing off after the timer stopped")
+	}
+}
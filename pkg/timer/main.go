@@ -7,6 +7,8 @@ package timer
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 	"golang.org/x/sync/errgroup"
@@ -14,23 +16,75 @@ import (
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	"github.com/fission/fission/pkg/controller"
 	"github.com/fission/fission/pkg/crd"
+	"github.com/fission/fission/pkg/router/asyncinvoke"
+	"github.com/fission/fission/pkg/router/asyncinvoke/fnconfig"
+	"github.com/fission/fission/pkg/statestore"
 	"github.com/fission/fission/pkg/utils/crmanager"
+
+	// The statestore drivers durable delivery opens, as the router's async
+	// path does: the HTTP client (embedded mode), Postgres and Redis (external).
+	_ "github.com/fission/fission/pkg/statestore/client"
+	_ "github.com/fission/fission/pkg/statestore/postgres"
+	_ "github.com/fission/fission/pkg/statestore/redis"
 )
 
 func Start(ctx context.Context, clientGen crd.ClientGeneratorInterface, logger logr.Logger, _ *errgroup.Group, routerUrl string) error {
 	// Active-passive HA via native controller-runtime leader election: only the
 	// elected leader schedules cron triggers, so two replicas don't double-fire
-	// timers. No-op when LEADER_ELECTION_ENABLED is unset (single-replica
-	// default). The TimeTrigger reconciler watches through the Manager's cache and
+	// timers on the direct path. No-op when LEADER_ELECTION_ENABLED is unset
+	// (single-replica default). With durable delivery the slot claims make
+	// several active replicas safe too. The TimeTrigger reconciler watches through the Manager's cache and
 	// runs only on the elected leader.
 	crMgr, err := crmanager.NewTriggerManager(ctx, clientGen, "fission-timer", logger)
 	if err != nil {
 		return err
 	}
+	timer := MakeTimer(ctx, logger, crMgr.GetClient(), routerUrl)
+
+	// Durable delivery (TIMER_DURABLE_DELIVERY, set by the chart when async
+	// invocation is on): every slot is claimed once in the statestore, so
+	// replicas cannot double-fire, and goes onto the router's RFC-0024 async
+	// queue, so a router hiccup no longer drops it. ConcurrencyPolicy is
+	// enforced there too (see slotEnqueuer), reading and writing the same
+	// invocation status records as the router (ASYNC_STATUS_TTL).
+	// Env reads live here, never in MakeTimer. Open does not dial; an
+	// unreachable store surfaces per slot in fireDurable.
+	if os.Getenv("TIMER_DURABLE_DELIVERY") == "true" {
+		opened, err := statestore.Open(ctx, statestore.FromEnv())
+		if err != nil {
+			return fmt.Errorf("durable delivery: opening statestore: %w", err)
+		}
+		caps := statestore.NewScoped(opened, nil)
+		defer func() { _ = caps.Close() }()
+		queue, err := caps.Queue()
+		if err != nil {
+			return fmt.Errorf("durable delivery: statestore queue capability: %w", err)
+		}
+		kv, err := caps.KV()
+		if err != nil {
+			return fmt.Errorf("durable delivery: statestore KV capability: %w", err)
+		}
+		var statusTTL time.Duration
+		if raw := os.Getenv("ASYNC_STATUS_TTL"); raw != "" {
+			if statusTTL, err = time.ParseDuration(raw); err != nil {
+				logger.Error(err, "failed to parse 'ASYNC_STATUS_TTL' - using the default", "value", raw)
+			}
+		}
+		timer.slots = &slotEnqueuer{
+			queue:     queue,
+			kv:        kv,
+			status:    asyncinvoke.NewStatusStore(kv, statusTTL, logger.WithName("async_status")),
+			queueName: asyncinvoke.DefaultQueue,
+			resolveFn: fnconfig.NewResolver(crMgr.GetClient(), logger),
+			now:       time.Now,
+		}
+		logger.Info("timer durable delivery enabled", "queue", asyncinvoke.DefaultQueue)
+	}
+
 	r := &TimeTriggerReconciler{
 		logger: logger.WithName("timetrigger_reconciler"),
 		client: crMgr.GetClient(),
-		timer:  MakeTimer(logger, routerUrl),
+		timer:  timer,
 	}
 	if err := controller.RegisterTenantScoped(crMgr, &fv1.TimeTrigger{}, r, "timetrigger"); err != nil {
 		return fmt.Errorf("error registering timetrigger reconciler: %w", err)
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package payload renders a TimeTrigger's Body and Headers templates for one
+// firing. It imports nothing from this repo, so the API validation
+// (pkg/apis/core/v1), the timer and the CLI all render through the same code:
+// a template the webhook admits is exactly one the timer can execute.
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"text/template"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// Data is what the Body and Headers templates are executed against.
+type Data struct {
+	// TriggerName and Namespace name the firing TimeTrigger.
+	TriggerName string
+	Namespace   string
+	// ScheduledTime is the cron slot being fired; FireTime is when the timer
+	// actually fired it, later than ScheduledTime on a catch-up.
+	ScheduledTime Time
+	FireTime      Time
+}
+
+// Time prints as RFC 3339 and keeps time.Time's methods, so a template can
+// write {{ .ScheduledTime }} as well as {{ .ScheduledTime.Format "2006-01-02" }}
+// or {{ .ScheduledTime.Unix }}.
+type Time struct{ time.Time }
+
+func (t Time) String() string { return t.Format(time.RFC3339) }
+
+// NewData builds the template data for one firing of a trigger.
+func NewData(name, namespace string, scheduled, fired time.Time) Data {
+	return Data{
+		TriggerName:   name,
+		Namespace:     namespace,
+		ScheduledTime: Time{scheduled},
+		FireTime:      Time{fired},
+	}
+}
+
+// sample is the firing Validate renders against. Its values are JSON-safe
+// exactly like every real firing's, so a body that renders to valid JSON here
+// does so at fire time too.
+var sample = NewData("example", "default",
+	time.Date(2006, 1, 2, 15, 4, 0, 0, time.UTC), time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC))
+
+// Validate parses body and the header value templates and renders them once
+// against sample data: the body must render to JSON, header names must be
+// valid field names and rendered values valid field values.
+func Validate(body string, headers map[string]string) error {
+	var errs error
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		if !httpguts.ValidHeaderFieldName(k) {
+			errs = errors.Join(errs, fmt.Errorf("header %q: not a valid header name", k))
+		}
+	}
+	rbody, rheaders, err := Render(body, headers, sample)
+	if err != nil {
+		return errors.Join(errs, err)
+	}
+	if rbody != "" && !json.Valid([]byte(rbody)) {
+		errs = errors.Join(errs, fmt.Errorf("body: does not render to valid JSON: %s", rbody))
+	}
+	for _, k := range slices.Sorted(maps.Keys(rheaders)) {
+		if !httpguts.ValidHeaderFieldValue(rheaders[k]) {
+			errs = errors.Join(errs, fmt.Errorf("header %q: does not render to a valid header value", k))
+		}
+	}
+	return errs
+}
+
+// Render executes body and the header value templates against d. A non-empty
+// body is JSON, so Content-Type defaults to application/json unless headers
+// set one.
+func Render(body string, headers map[string]string, d Data) (string, map[string]string, error) {
+	rbody, err := execute("body", body, d)
+	if err != nil {
+		return "", nil, err
+	}
+	rheaders := make(map[string]string, len(headers)+1)
+	for _, k := range slices.Sorted(maps.Keys(headers)) {
+		v, err := execute("header "+k, headers[k], d)
+		if err != nil {
+			return "", nil, err
+		}
+		rheaders[k] = v
+	}
+	if rbody != "" && !hasHeader(rheaders, "Content-Type") {
+		rheaders["Content-Type"] = "application/json"
+	}
+	return rbody, rheaders, nil
+}
+
+func execute(name, text string, d Data) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: The Fission Authors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package payload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+	d := NewData("daily", "ns",
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 2, 0, time.UTC))
+
+	body, headers, err := Render(
+		`{"window":"daily","day":"{{ .ScheduledTime.Format "2006-01-02" }}","at":"{{ .FireTime }}","ns":"{{ .Namespace }}"}`,
+		map[string]string{"X-Trigger": "{{ .TriggerName }}"}, d)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"window":"daily","day":"2026-03-01","at":"2026-03-01T00:00:02Z","ns":"ns"}`, body)
+	assert.Equal(t, map[string]string{"X-Trigger": "daily", "Content-Type": "application/json"}, headers)
+
+	// An explicit Content-Type, in any spelling, is kept.
+	_, headers, err = Render(`{}`, map[string]string{"content-type": "application/cloudevents+json"}, d)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"content-type": "application/cloudevents+json"}, headers)
+
+	// No body, no Content-Type.
+	body, headers, err = Render("", nil, d)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+	assert.Empty(t, headers)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+		wantErr string
+	}{
+		{name: "empty"},
+		{name: "templated JSON", body: `{"slot":"{{ .ScheduledTime }}","n":{{ .FireTime.Unix }}}`,
+			headers: map[string]string{"X-Trigger": "{{ .TriggerName }}"}},
+		{name: "parse error", body: `{"slot":"{{ .ScheduledTime }"}`, wantErr: "body:"},
+		{name: "unknown field", body: `{"slot":"{{ .Slot }}"}`, wantErr: "can't evaluate field Slot"},
+		{name: "not JSON", body: `slot={{ .ScheduledTime }}`, wantErr: "does not render to valid JSON"},
+		{name: "bad header name", headers: map[string]string{"X Window": "hourly"}, wantErr: "not a valid header name"},
+		{name: "bad header value", headers: map[string]string{"X-Window": "a\nb"}, wantErr: "not render to a valid header value"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := Validate(tc.body, tc.headers)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
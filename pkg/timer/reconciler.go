@@ -15,7 +15,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
+	"github.com/fission/fission/pkg/conditions"
 	"github.com/fission/fission/pkg/controller"
+	"github.com/fission/fission/pkg/timer/payload"
 )
 
 // TimeTriggerReconciler keeps the in-process cron schedules in sync with the
@@ -66,10 +68,51 @@ func (r *TimeTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	r.timer.addUpdate(tt)
+	// An unknown TimeZone is the same kind of admitted-but-unschedulable spec:
+	// the tz database lookup is not expressible in CEL either.
+	sched, err := tt.Spec.Schedule()
+	if err != nil {
+		r.timer.remove(req.NamespacedName)
+		controller.SetConditions(ctx, r.logger, r.client, tt,
+			metav1.Condition{
+				Type: fv1.TimeTriggerConditionScheduled, Status: metav1.ConditionFalse,
+				Reason:  fv1.TimeTriggerReasonInvalidTimeZone,
+				Message: fmt.Sprintf("invalid time zone %q: %v", tt.Spec.TimeZone, err),
+			},
+			metav1.Condition{
+				Type: fv1.TimeTriggerConditionReady, Status: metav1.ConditionFalse,
+				Reason:  fv1.TimeTriggerReasonInvalidTimeZone,
+				Message: "trigger is not firing: invalid time zone",
+			},
+		)
+		return ctrl.Result{}, nil
+	}
+
+	// Body/Headers templates are the third such check: admitting a template
+	// that cannot render would otherwise only show up as a skipped slot in
+	// the timer's log.
+	if err := payload.Validate(tt.Spec.Body, tt.Spec.Headers); err != nil {
+		r.timer.remove(req.NamespacedName)
+		controller.SetConditions(ctx, r.logger, r.client, tt,
+			metav1.Condition{
+				Type: fv1.TimeTriggerConditionScheduled, Status: metav1.ConditionFalse,
+				Reason:  fv1.TimeTriggerReasonInvalidPayload,
+				Message: conditions.TruncateMessage(fmt.Sprintf("invalid body or headers: %v", err)),
+			},
+			metav1.Condition{
+				Type: fv1.TimeTriggerConditionReady, Status: metav1.ConditionFalse,
+				Reason:  fv1.TimeTriggerReasonInvalidPayload,
+				Message: "trigger is not firing: invalid body or headers",
+			},
+		)
+		return ctrl.Result{}, nil
+	}
 
 	// Best-effort Scheduled + Ready conditions. Status writes never gate the
-	// schedule; SetConditions skips the write when nothing changed.
+	// schedule; SetConditions skips the write when nothing changed. Written
+	// before the schedule starts: the loop's own lastScheduleTime /
+	// nextScheduleTime patch would otherwise bump the resourceVersion under
+	// this full-status Update and make it conflict.
 	controller.SetConditions(ctx, r.logger, r.client, tt,
 		metav1.Condition{
 			Type: fv1.TimeTriggerConditionScheduled, Status: metav1.ConditionTrue,
@@ -82,5 +125,6 @@ func (r *TimeTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			Message: "trigger is firing on schedule",
 		},
 	)
+	r.timer.addUpdate(tt, sched)
 	return ctrl.Result{}, nil
 }
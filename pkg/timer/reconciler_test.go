@@ -6,6 +6,7 @@ package timer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
@@ -35,7 +36,7 @@ func TestTimeTriggerReconciler(t *testing.T) {
 	r := &TimeTriggerReconciler{
 		logger: logr.Discard(),
 		client: c,
-		timer:  MakeTimer(logr.Discard(), "http://router.fission"),
+		timer:  MakeTimer(t.Context(), logr.Discard(), c, "http://router.fission"),
 	}
 	key := types.NamespacedName{Namespace: "default", Name: "cron1"}
 	req := ctrl.Request{NamespacedName: key}
@@ -51,6 +52,11 @@ func TestTimeTriggerReconciler(t *testing.T) {
 	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt), got))
 	assert.True(t, conditions.IsTrue(got.Status.Conditions, fv1.TimeTriggerConditionScheduled), "Scheduled condition should be True")
 	assert.True(t, conditions.IsTrue(got.Status.Conditions, fv1.TimeTriggerConditionReady), "Ready condition should be True")
+	require.Eventually(t, func() bool {
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt), got))
+		return got.Status.NextScheduleTime != nil
+	}, 5*time.Second, 10*time.Millisecond, "the schedule loop should publish nextScheduleTime")
+	assert.True(t, conditions.IsTrue(got.Status.Conditions, fv1.TimeTriggerConditionReady), "the status patch must not drop the conditions")
 
 	// Reconcile again is idempotent (no error, entry still present).
 	_, err = r.Reconcile(ctx, req)
@@ -82,7 +88,7 @@ func TestTimeTriggerReconciler_InvalidCron(t *testing.T) {
 	r := &TimeTriggerReconciler{
 		logger: logr.Discard(),
 		client: c,
-		timer:  MakeTimer(logr.Discard(), "http://router.fission"),
+		timer:  MakeTimer(t.Context(), logr.Discard(), c, "http://router.fission"),
 	}
 	key := types.NamespacedName{Namespace: "default", Name: "bad-cron"}
 	ctx := t.Context()
@@ -102,3 +108,72 @@ func TestTimeTriggerReconciler_InvalidCron(t *testing.T) {
 	assert.Equal(t, metav1.ConditionFalse, cond.Status)
 	assert.Equal(t, fv1.TimeTriggerReasonInvalidCron, cond.Reason)
 }
+
+// TestTimeTriggerReconciler_InvalidTimeZone: an unknown TimeZone is admitted
+// (CEL cannot consult the tz database) but must surface as
+// Scheduled=False / InvalidTimeZone instead of being scheduled.
+func TestTimeTriggerReconciler_InvalidTimeZone(t *testing.T) {
+	tt := &fv1.TimeTrigger{
+		Name: "bad-tz", Namespace: "default", Generation: 1,
+		Spec: fv1.TimeTriggerSpec{Cron: "0 0 * * *", TimeZone: "Mars/Olympus", FunctionReference: fv1.FunctionReference{Name: "fn"}},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(tt).
+		WithStatusSubresource(&fv1.TimeTrigger{}).
+		Build()
+	r := &TimeTriggerReconciler{
+		logger: logr.Discard(),
+		client: c,
+		timer:  MakeTimer(t.Context(), logr.Discard(), c, "http://router.fission"),
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "bad-tz"}
+	ctx := t.Context()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	_, ok := r.timer.triggers[key]
+	assert.False(t, ok, "a trigger with an unknown time zone must not be scheduled")
+
+	got := &fv1.TimeTrigger{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt), got))
+	cond := meta.FindStatusCondition(got.Status.Conditions, fv1.TimeTriggerConditionScheduled)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, fv1.TimeTriggerReasonInvalidTimeZone, cond.Reason)
+}
+
+func TestTimeTriggerReconciler_InvalidPayload(t *testing.T) {
+	tt := &fv1.TimeTrigger{
+		Name: "bad-body", Namespace: "default", Generation: 1,
+		Spec: fv1.TimeTriggerSpec{
+			Cron:              "0 0 * * *",
+			Body:              `{"slot":"{{ .Slot }}"}`,
+			FunctionReference: fv1.FunctionReference{Name: "fn"},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(tt).
+		WithStatusSubresource(&fv1.TimeTrigger{}).
+		Build()
+	r := &TimeTriggerReconciler{
+		logger: logr.Discard(),
+		client: c,
+		timer:  MakeTimer(t.Context(), logr.Discard(), c, "http://router.fission"),
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "bad-body"}
+	ctx := t.Context()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	_, ok := r.timer.triggers[key]
+	assert.False(t, ok, "a trigger whose body cannot render must not be scheduled")
+
+	got := &fv1.TimeTrigger{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(tt), got))
+	cond := meta.FindStatusCondition(got.Status.Conditions, fv1.TimeTriggerConditionScheduled)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, fv1.TimeTriggerReasonInvalidPayload, cond.Reason)
+}
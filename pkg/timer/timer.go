@@ -6,71 +6,121 @@ package timer
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 	"github.com/fission/fission/pkg/publisher"
+	"github.com/fission/fission/pkg/timer/payload"
 	"github.com/fission/fission/pkg/utils"
+
+	// Embed the tz database so TimeTriggerSpec.TimeZone resolves on the
+	// distroless image, which ships no /usr/share/zoneinfo.
+	_ "time/tzdata"
 )
 
 type (
 	Timer struct {
+		// ctx is the timer's lifetime: schedule loops and durable firings
+		// stop with it.
+		ctx       context.Context
 		logger    logr.Logger
+		client    client.Client
 		triggers  map[types.NamespacedName]*timerTriggerWithCron
 		routerUrl string
+		publisher *publisher.WebhookPublisher
+		// slots, when set, is the durable firing path (see slotEnqueuer);
+		// nil fires through publisher directly.
+		slots *slotEnqueuer
+
+		// now is the clock the schedule loops read; tests swap it.
+		now func() time.Time
 	}
 
+	// timerTriggerWithCron is one registered trigger. The schedule loop is
+	// replaced on every spec change, but the entry itself (and so the set of
+	// in-flight invocations) survives the update, which keeps
+	// ConcurrencyPolicy honest across an edit.
 	timerTriggerWithCron struct {
 		trigger fv1.TimeTrigger
-		cron    *cron.Cron
+		// stop cancels the running schedule loop.
+		stop context.CancelFunc
+
+		mu sync.Mutex
+		// running holds a cancel func per in-flight invocation, keyed by a
+		// per-trigger sequence number.
+		running map[uint64]context.CancelFunc
+		seq     uint64
 	}
 )
 
-func MakeTimer(logger logr.Logger, routerUrl string) *Timer {
+// maxCatchUpIterations bounds missedSlot's forward walk from the catch-up
+// window start to now, the same guard poolmgr's lastSched uses for a cron far
+// denser than the window.
+const maxCatchUpIterations = 100_000
+
+// timerNameHeader names the firing trigger on every invocation.
+const timerNameHeader = "X-Fission-Timer-Name"
+
+func MakeTimer(ctx context.Context, logger logr.Logger, c client.Client, routerUrl string) *Timer {
 	timer := &Timer{
+		ctx:       ctx,
 		logger:    logger.WithName("timer"),
+		client:    c,
 		triggers:  make(map[types.NamespacedName]*timerTriggerWithCron),
 		routerUrl: routerUrl,
+		publisher: publisher.MakeWebhookPublisher(logger, routerUrl),
+		now:       time.Now,
 	}
 	return timer
 }
 
-// addUpdate (re)registers the cron entry for a time trigger. An existing entry
+// addUpdate (re)registers the schedule for a time trigger. An existing loop
 // for the same trigger is stopped and replaced so a changed schedule takes
 // effect. Keyed by namespaced name so the reconciler can tear it down on a
-// delete (when only the name is known).
-func (timer *Timer) addUpdate(timeTrigger *fv1.TimeTrigger) {
+// delete (when only the name is known). The caller has already validated
+// sched against the trigger's spec.
+func (timer *Timer) addUpdate(timeTrigger *fv1.TimeTrigger, sched cron.Schedule) {
 	key := types.NamespacedName{Namespace: timeTrigger.Namespace, Name: timeTrigger.Name}
 	logger := timer.logger.WithValues("trigger_name", timeTrigger.Name, "trigger_namespace", timeTrigger.Namespace)
 
 	if item, ok := timer.triggers[key]; ok {
-		if item.cron != nil {
-			item.cron.Stop()
+		if item.stop != nil {
+			item.stop()
 		}
 		item.trigger = *timeTrigger
-		item.cron = timer.newCron(*timeTrigger, timer.routerUrl)
+		item.stop = timer.start(item, sched, false)
 		logger.V(1).Info("cron updated")
 		return
 	}
-	timer.triggers[key] = &timerTriggerWithCron{
+	item := &timerTriggerWithCron{
 		trigger: *timeTrigger,
-		cron:    timer.newCron(*timeTrigger, timer.routerUrl),
+		running: make(map[uint64]context.CancelFunc),
 	}
+	item.stop = timer.start(item, sched, true)
+	timer.triggers[key] = item
 	logger.V(1).Info("cron added")
 }
 
-// remove stops and drops the cron entry for a deleted time trigger. No-op if
+// remove stops and drops the schedule for a deleted time trigger. No-op if
 // the trigger was never registered (e.g. a delete observed before any add).
+// Invocations already in flight are left to finish, as before.
 func (timer *Timer) remove(key types.NamespacedName) {
 	item, ok := timer.triggers[key]
 	if !ok {
 		return
 	}
-	if item.cron != nil {
-		item.cron.Stop()
+	if item.stop != nil {
+		item.stop()
 	}
 	delete(timer.triggers, key)
 	timer.logger.WithValues("trigger_name", key.Name, "trigger_namespace", key.Namespace).V(1).Info("cron deleted")
@@ -79,8 +129,8 @@ func (timer *Timer) remove(key types.NamespacedName) {
 // functionTargetURL builds the internal-listener URL a TimeTrigger's cron
 // fires at: UrlForFunctionReference(ref, namespace) + Subpath, where the
 // alias-else-version suffix selection is UrlForFunctionReference's job --
-// extracted from newCron so it is unit-testable without spinning up a
-// cron.Cron.
+// extracted from the schedule loop so it is unit-testable without running
+// one.
 //
 // TimeTriggerSpec embeds FunctionReference (json "functionref"), so
 // t.Spec.FunctionReference is that embedded value, read the same way
@@ -90,27 +140,191 @@ func functionTargetURL(t fv1.TimeTrigger) string {
 	return utils.UrlForFunctionReference(t.Spec.FunctionReference, t.Namespace) + t.Spec.Subpath
 }
 
-func (timer *Timer) newCron(t fv1.TimeTrigger, routerUrl string) *cron.Cron {
-	target := functionTargetURL(t)
+// missedSlot returns the most recent slot of sched in (after, now] that is no
+// older than deadline, i.e. the one firing StartingDeadlineSeconds allows a
+// restarted timer to catch up. ok is false when there is none.
+func missedSlot(sched cron.Schedule, after, now time.Time, deadline time.Duration) (time.Time, bool) {
+	if earliest := now.Add(-deadline); after.Before(earliest) {
+		// Next is strictly-after; step back a nanosecond so a slot exactly
+		// at the deadline edge still counts.
+		after = earliest.Add(-time.Nanosecond)
+	}
+	slot := sched.Next(after)
+	if slot.IsZero() || slot.After(now) {
+		return time.Time{}, false
+	}
+	for range maxCatchUpIterations {
+		next := sched.Next(slot)
+		if next.IsZero() || next.After(now) {
+			return slot, true
+		}
+		slot = next
+	}
+	return slot, true
+}
+
+// start launches the schedule loop for item and returns its stop func. On the
+// first registration in this process (catchUp) a slot missed while no timer
+// was running is fired once, within StartingDeadlineSeconds of now.
+func (timer *Timer) start(item *timerTriggerWithCron, sched cron.Schedule, catchUp bool) context.CancelFunc {
+	t := item.trigger
+	ctx, cancel := context.WithCancel(timer.ctx)
+
+	var missed time.Time
+	if catchUp && t.Spec.StartingDeadlineSeconds != nil {
+		after := t.CreationTimestamp.Time
+		if t.Status.LastScheduleTime != nil {
+			after = t.Status.LastScheduleTime.Time
+		}
+		deadline := time.Duration(*t.Spec.StartingDeadlineSeconds) * time.Second
+		if slot, ok := missedSlot(sched, after, timer.now(), deadline); ok {
+			missed = slot
+		}
+	}
 
-	// create one publisher per-cron timer
-	timerPublisher := publisher.MakeWebhookPublisher(timer.logger, routerUrl)
+	go timer.run(ctx, item, t, sched, missed)
+	timer.logger.Info("started cron for time trigger", "trigger_name", t.Name, "trigger_namespace", t.Namespace,
+		"cron", t.Spec.Cron, "time_zone", t.Spec.TimeZone, "concurrency_policy", t.Spec.GetConcurrencyPolicy())
+	return cancel
+}
 
-	c := cron.New(
-		cron.WithParser(
-			cron.NewParser(
-				cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)))
-	c.AddFunc(t.Spec.Cron, func() { //nolint: errCheck
-		headers := map[string]string{
-			"X-Fission-Timer-Name": t.Name,
+// run fires item on sched until ctx is cancelled. Slots are tracked by their
+// scheduled time rather than the wall clock at wake-up, so LastScheduleTime
+// and the catch-up bookkeeping name the slot, not the timer's latency.
+func (timer *Timer) run(ctx context.Context, item *timerTriggerWithCron, t fv1.TimeTrigger, sched cron.Schedule, missed time.Time) {
+	from := timer.now()
+	if !missed.IsZero() {
+		timer.fire(item, t, missed)
+	}
+	timer.patchStatus(ctx, t, missed, sched.Next(from))
+	for {
+		slot := sched.Next(from)
+		if slot.IsZero() {
+			// No further slot within the parser's search horizon.
+			return
+		}
+		wait := time.NewTimer(slot.Sub(timer.now()))
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return
+		case <-wait.C:
+		}
+		timer.fire(item, t, slot)
+		// Resume from the slot just fired, or from now if the process
+		// stalled past several slots (a suspended node): like robfig/cron,
+		// slots skipped that way are not replayed back to back.
+		from = slot
+		if now := timer.now(); now.After(from) {
+			from = now
 		}
+		timer.patchStatus(ctx, t, slot, sched.Next(from))
+	}
+}
+
+// fire invokes t's function for slot. With durable delivery on, the slot is
+// enqueued (fireDurable), once across replicas, and the queue enforces
+// ConcurrencyPolicy; otherwise it is fired directly.
+func (timer *Timer) fire(item *timerTriggerWithCron, t fv1.TimeTrigger, slot time.Time) {
+	if timer.slots != nil {
+		go timer.fireDurable(t, slot)
+		return
+	}
+	timer.fireDirect(item, t, slot)
+}
 
-		// with the addition of multi-tenancy, the users can create functions in any namespace. however,
-		// the triggers can only be created in the same namespace as the function.
-		// so essentially, function namespace = trigger namespace.
-		(timerPublisher).Publish(context.Background(), "", headers, t.Spec.Method, target)
-	})
-	c.Start()
-	timer.logger.Info("started cron for time trigger", "trigger_name", t.Name, "trigger_namespace", t.Namespace, "cron", t.Spec.Cron)
-	return c
+// fireDirect invokes t's function for slot, applying ConcurrencyPolicy
+// against the trigger's invocations still in flight. The invocation runs on
+// its own goroutine and is not tied to the schedule loop, so a spec update
+// does not cut it short.
+func (timer *Timer) fireDirect(item *timerTriggerWithCron, t fv1.TimeTrigger, slot time.Time) {
+	logger := timer.logger.WithValues("trigger_name", t.Name, "trigger_namespace", t.Namespace, "slot", slot)
+	body, headers, err := renderPayload(t, slot, timer.now())
+	if err != nil {
+		// The reconciler does not schedule a trigger whose templates fail
+		// payload.Validate, so this is only a safety net.
+		logger.Error(err, "rendering time trigger payload failed, skipping schedule slot")
+		return
+	}
+
+	item.mu.Lock()
+	switch t.Spec.GetConcurrencyPolicy() {
+	case fv1.TimeTriggerConcurrencyForbid:
+		if len(item.running) > 0 {
+			item.mu.Unlock()
+			logger.Info("skipping schedule slot, previous invocation still running", "concurrency_policy", fv1.TimeTriggerConcurrencyForbid)
+			return
+		}
+	case fv1.TimeTriggerConcurrencyReplace:
+		for id, cancel := range item.running {
+			cancel()
+			delete(item.running, id)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	item.seq++
+	id := item.seq
+	item.running[id] = cancel
+	item.mu.Unlock()
+
+	// with the addition of multi-tenancy, the users can create functions in any namespace. however,
+	// the triggers can only be created in the same namespace as the function.
+	// so essentially, function namespace = trigger namespace.
+	target := functionTargetURL(t)
+	go func() {
+		defer func() {
+			item.mu.Lock()
+			delete(item.running, id)
+			item.mu.Unlock()
+			cancel()
+		}()
+		if err := timer.publisher.Send(ctx, body, headers, t.Spec.Method, target); err != nil && errors.Is(err, context.Canceled) {
+			logger.Info("invocation cancelled by a newer schedule slot", "concurrency_policy", fv1.TimeTriggerConcurrencyReplace)
+		}
+	}()
+}
+
+// renderPayload renders t's Body and Headers templates for slot, fired at
+// now. X-Fission-Timer-Name is set last, replacing any spelling of it in
+// Headers, so the function can always tell which trigger fired.
+func renderPayload(t fv1.TimeTrigger, slot, now time.Time) (string, map[string]string, error) {
+	body, headers, err := payload.Render(t.Spec.Body, t.Spec.Headers, payload.NewData(t.Name, t.Namespace, slot, now))
+	if err != nil {
+		return "", nil, err
+	}
+	for k := range headers {
+		if http.CanonicalHeaderKey(k) == timerNameHeader {
+			delete(headers, k)
+		}
+	}
+	headers[timerNameHeader] = t.Name
+	return body, headers, nil
+}
+
+// patchStatus records the last fired and next scheduled slot on the
+// trigger's status. A zero last leaves LastScheduleTime untouched. It is a
+// merge patch of just these two fields, so it never fights the reconciler's
+// condition writes, and best effort: a failed write only delays what
+// kubectl shows, never a firing.
+func (timer *Timer) patchStatus(ctx context.Context, t fv1.TimeTrigger, last, next time.Time) {
+	if timer.client == nil {
+		return
+	}
+	status := map[string]any{}
+	if !last.IsZero() {
+		status["lastScheduleTime"] = metav1.NewTime(last)
+	}
+	if !next.IsZero() {
+		status["nextScheduleTime"] = metav1.NewTime(next)
+	}
+	if len(status) == 0 {
+		return
+	}
+	data, err := json.Marshal(map[string]any{"status": status})
+	if err != nil {
+		return
+	}
+	if err := timer.client.Status().Patch(ctx, &t, client.RawPatch(types.MergePatchType, data)); err != nil && ctx.Err() == nil {
+		timer.logger.V(1).Info("status update failed", "trigger_name", t.Name, "trigger_namespace", t.Namespace, "error", err)
+	}
 }
@@ -5,9 +5,17 @@
 package timer
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	fv1 "github.com/fission/fission/pkg/apis/core/v1"
 )
@@ -62,3 +70,182 @@ func TestFunctionTargetURL(t *testing.T) {
 		})
 	}
 }
+
+// TestMissedSlot pins the StartingDeadlineSeconds catch-up window: only the
+// most recent slot after the last fired one, and no older than the deadline,
+// is caught up.
+func TestMissedSlot(t *testing.T) {
+	t.Parallel()
+	spec := fv1.TimeTriggerSpec{Cron: "0 * * * *"} // hourly, on the hour
+	sched, err := spec.Schedule()
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 1, 10, 20, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		after    time.Time
+		deadline time.Duration
+		want     time.Time
+		ok       bool
+	}{
+		{
+			name:     "last slot already fired",
+			after:    time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local),
+			deadline: time.Hour,
+		},
+		{
+			name:     "missed slot within deadline",
+			after:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local),
+			deadline: time.Hour,
+			want:     time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local),
+			ok:       true,
+		},
+		{
+			name:     "several missed slots: only the latest fires",
+			after:    time.Date(2026, 2, 28, 0, 0, 0, 0, time.Local),
+			deadline: 24 * time.Hour,
+			want:     time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local),
+			ok:       true,
+		},
+		{
+			name:     "missed slot older than deadline",
+			after:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local),
+			deadline: 10 * time.Minute,
+		},
+		{
+			name:     "slot exactly at the deadline edge",
+			after:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local),
+			deadline: 20 * time.Minute,
+			want:     time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local),
+			ok:       true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := missedSlot(sched, tc.after, now, tc.deadline)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.True(t, tc.want.Equal(got), "want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestScheduleTimeZone checks TimeZone moves the slots, and that a CRON_TZ
+// prefix on the cron itself still wins.
+func TestScheduleTimeZone(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	sched, err := fv1.TimeTriggerSpec{Cron: "0 9 * * *", TimeZone: "Asia/Tokyo"}.Schedule()
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC).Add(24*time.Hour).Equal(sched.Next(from)),
+		"09:00 JST is 00:00 UTC, strictly after from")
+
+	sched, err = fv1.TimeTriggerSpec{Cron: "CRON_TZ=UTC 0 9 * * *", TimeZone: "Asia/Tokyo"}.Schedule()
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC).Equal(sched.Next(from)))
+
+	_, err = fv1.TimeTriggerSpec{Cron: "0 9 * * *", TimeZone: "Mars/Olympus"}.Schedule()
+	assert.Error(t, err)
+}
+
+// TestFireConcurrencyPolicy drives fire directly against a function that
+// blocks until released, checking how each policy treats a slot that comes
+// due while the previous invocation is still running.
+func TestFireConcurrencyPolicy(t *testing.T) {
+	tests := []struct {
+		policy        fv1.TimeTriggerConcurrencyPolicy
+		wantStarted   int32
+		wantCancelled int32
+	}{
+		{policy: fv1.TimeTriggerConcurrencyAllow, wantStarted: 2},
+		{policy: fv1.TimeTriggerConcurrencyForbid, wantStarted: 1},
+		{policy: fv1.TimeTriggerConcurrencyReplace, wantStarted: 2, wantCancelled: 1},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			var started, cancelled atomic.Int32
+			release := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				started.Add(1)
+				select {
+				case <-release:
+				case <-r.Context().Done():
+					cancelled.Add(1)
+				}
+			}))
+			defer srv.Close()
+			defer close(release)
+
+			timer := MakeTimer(t.Context(), logr.Discard(), nil, srv.URL)
+			tt := fv1.TimeTrigger{
+				Name: "tt", Namespace: "default",
+				Spec: fv1.TimeTriggerSpec{
+					Cron:              "@hourly",
+					Method:            http.MethodPost,
+					FunctionReference: fv1.FunctionReference{Name: "fn"},
+					ConcurrencyPolicy: tc.policy,
+				},
+			}
+			item := &timerTriggerWithCron{trigger: tt, running: make(map[uint64]context.CancelFunc)}
+
+			timer.fire(item, tt, time.Now())
+			require.Eventually(t, func() bool { return started.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+			timer.fire(item, tt, time.Now())
+
+			require.Eventually(t, func() bool {
+				return started.Load() == tc.wantStarted && cancelled.Load() == tc.wantCancelled
+			}, 5*time.Second, 10*time.Millisecond)
+			require.Never(t, func() bool {
+				return started.Load() != tc.wantStarted || cancelled.Load() != tc.wantCancelled
+			}, 200*time.Millisecond, 20*time.Millisecond)
+		})
+	}
+}
+
+// TestFirePayload checks that a firing carries the rendered Body and Headers
+// templates, with the timer's own X-Fission-Timer-Name winning over a
+// differently-cased entry in Headers.
+func TestFirePayload(t *testing.T) {
+	type request struct {
+		body   string
+		header http.Header
+	}
+	got := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- request{body: string(body), header: r.Header}
+	}))
+	defer srv.Close()
+
+	timer := MakeTimer(t.Context(), logr.Discard(), nil, srv.URL)
+	fired := time.Date(2026, 3, 1, 9, 0, 3, 0, time.UTC)
+	timer.now = func() time.Time { return fired }
+	tt := fv1.TimeTrigger{
+		Name: "rollup", Namespace: "default",
+		Spec: fv1.TimeTriggerSpec{
+			Cron:              "@hourly",
+			Method:            http.MethodPost,
+			FunctionReference: fv1.FunctionReference{Name: "fn"},
+			Body:              `{"trigger":"{{ .TriggerName }}","slot":{{ .ScheduledTime.Unix }},"fired":"{{ .FireTime }}"}`,
+			Headers: map[string]string{
+				"X-Window":             "hourly",
+				"x-fission-timer-name": "spoofed",
+			},
+		},
+	}
+	item := &timerTriggerWithCron{trigger: tt, running: make(map[uint64]context.CancelFunc)}
+
+	timer.fire(item, tt, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	select {
+	case r := <-got:
+		assert.JSONEq(t, `{"trigger":"rollup","slot":1772355600,"fired":"2026-03-01T09:00:03Z"}`, r.body)
+		assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+		assert.Equal(t, "hourly", r.header.Get("X-Window"))
+		assert.Equal(t, []string{"rollup"}, r.header.Values("X-Fission-Timer-Name"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("function was not invoked")
+	}
+}
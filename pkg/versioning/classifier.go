@@ -132,6 +132,13 @@ func RuntimeAffecting(old, new fv1.FunctionSpec) bool {
 	//   - Tool: MCP catalog metadata (description/schema/tool name/alias);
 	//     the MCP registry reads live Functions directly, so this never
 	//     drives what gets specialized or how an invocation behaves.
+	//   - RateLimit: router admission in front of the function; it decides
+	//     whether a request is let through, not what the function does with
+	//     one.
+	//   - CircuitBreaker: router-only; it stops sending requests to a failing
+	//     function, and no pod is respecialized for it.
+	//   - OutlierEjection: router-only; it stops sending requests to a
+	//     failing pod, and no pod is respecialized for it.
 	//   - Versioning: the RFC-0025 recursion guard. The publish snapshot
 	//     zeroes this field before it is ever stored (see
 	//     normalizedSnapshot/Publish in publish.go), so a Versioning-only
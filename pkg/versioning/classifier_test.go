@@ -51,7 +51,12 @@ var notAffectingFields = map[string]struct{}{
 	"RetainPods":             {},
 	"ProvisionedConcurrency": {},
 	"Tool":                   {},
+	"RateLimit":              {},
 	"Versioning":             {},
+	// Router-only failure isolation: decides which requests reach a pod,
+	// never what the pod runs, so no respecialization.
+	"CircuitBreaker":  {},
+	"OutlierEjection": {},
 }
 
 // TestRuntimeAffecting_FieldCoverage is the completeness guard: it walks
@@ -126,7 +131,10 @@ func baseSpec() fv1.FunctionSpec {
 			Protocol:           fv1.StreamingProtocol("sse"),
 			IdleTimeoutSeconds: 30,
 		},
-		Tool: &fv1.ToolConfig{Description: "a tool"},
+		Tool:            &fv1.ToolConfig{Description: "a tool"},
+		RateLimit:       &fv1.RateLimitConfig{Requests: 10},
+		CircuitBreaker:  &fv1.CircuitBreakerConfig{ConsecutiveFailures: 5},
+		OutlierEjection: &fv1.OutlierEjectionConfig{ConsecutiveFailures: 3},
 		State: &fv1.StateConfig{
 			Keyspace: "ks",
 		},
@@ -224,6 +232,12 @@ func goldenTableCases() []goldenTableCase {
 		{"ProvisionedConcurrency nil->set", func(s *fv1.FunctionSpec) { s.ProvisionedConcurrency = nil }, false},
 		{"Tool", func(s *fv1.FunctionSpec) { s.Tool.Description = "different tool" }, false},
 		{"Tool nil->set", func(s *fv1.FunctionSpec) { s.Tool = nil }, false},
+		{"RateLimit", func(s *fv1.FunctionSpec) { s.RateLimit.Requests = 99 }, false},
+		{"RateLimit nil->set", func(s *fv1.FunctionSpec) { s.RateLimit = nil }, false},
+		{"CircuitBreaker", func(s *fv1.FunctionSpec) { s.CircuitBreaker.ConsecutiveFailures = 50 }, false},
+		{"CircuitBreaker nil->set", func(s *fv1.FunctionSpec) { s.CircuitBreaker = nil }, false},
+		{"OutlierEjection", func(s *fv1.FunctionSpec) { s.OutlierEjection.ConsecutiveFailures = 30 }, false},
+		{"OutlierEjection nil->set", func(s *fv1.FunctionSpec) { s.OutlierEjection = nil }, false},
 		{"Versioning", func(s *fv1.FunctionSpec) { s.Versioning.Mode = fv1.VersioningMode("manual") }, false},
 		{"Versioning nil->set", func(s *fv1.FunctionSpec) { s.Versioning = nil }, false},
 	}
@@ -314,7 +328,7 @@ func TestRuntimeAffecting_Rapid_NotAffectingMutationNeverAffects(t *testing.T) {
 		mutated := *s.DeepCopy()
 
 		switch rapid.SampledFrom([]string{
-			"IdleTimeout", "RetainPods", "ProvisionedConcurrency", "Tool", "Versioning",
+			"IdleTimeout", "RetainPods", "ProvisionedConcurrency", "Tool", "RateLimit", "CircuitBreaker", "OutlierEjection", "Versioning",
 		}).Draw(rt, "field") {
 		case "IdleTimeout":
 			v := rapid.IntRange(0, 100000).Draw(rt, "idleTimeout")
@@ -329,6 +343,18 @@ func TestRuntimeAffecting_Rapid_NotAffectingMutationNeverAffects(t *testing.T) {
 			mutated.Tool = &fv1.ToolConfig{
 				Description: rapid.StringN(0, 40, -1).Draw(rt, "toolDescription"),
 			}
+		case "RateLimit":
+			mutated.RateLimit = &fv1.RateLimitConfig{
+				Requests: rapid.Int32Range(1, 1000).Draw(rt, "requests"),
+			}
+		case "CircuitBreaker":
+			mutated.CircuitBreaker = &fv1.CircuitBreakerConfig{
+				ConsecutiveFailures: rapid.Int32Range(1, 100).Draw(rt, "cbFailures"),
+			}
+		case "OutlierEjection":
+			mutated.OutlierEjection = &fv1.OutlierEjectionConfig{
+				ConsecutiveFailures: rapid.Int32Range(1, 100).Draw(rt, "oeFailures"),
+			}
 		case "Versioning":
 			mode := rapid.SampledFrom([]string{"auto", "manual"}).Draw(rt, "mode")
 			mutated.Versioning = &fv1.VersioningConfig{Mode: fv1.VersioningMode(mode)}
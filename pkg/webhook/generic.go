@@ -48,6 +48,14 @@ type DeleteValidator[T client.Object] interface {
 	ValidateDeletion(ctx context.Context, obj T) error
 }
 
+// AccessValidator is an optional facet for checks that depend on who is
+// making the request — e.g. that the requester may read what the object
+// grants access to. It runs on create and update after Validator; ctx carries
+// the admission.Request (admission.RequestFromContext).
+type AccessValidator[T client.Object] interface {
+	ValidateAccess(ctx context.Context, obj T) error
+}
+
 // GenericWebhook implements the webhook interfaces for a generic type T.
 type GenericWebhook[T client.Object] struct {
 	Logger          logr.Logger
@@ -56,6 +64,7 @@ type GenericWebhook[T client.Object] struct {
 	Warner          Warner[T]
 	UpdateValidator UpdateValidator[T]
 	DeleteValidator DeleteValidator[T]
+	AccessValidator AccessValidator[T]
 }
 
 // SetupWebhookWithManager sets up the webhook with the manager.
@@ -76,19 +85,26 @@ func (w *GenericWebhook[T]) Default(_ context.Context, obj T) error {
 }
 
 // ValidateCreate implements admission.Validator.
-func (w *GenericWebhook[T]) ValidateCreate(_ context.Context, obj T) (admission.Warnings, error) {
+func (w *GenericWebhook[T]) ValidateCreate(ctx context.Context, obj T) (admission.Warnings, error) {
 	w.Logger.V(1).Info("validate create", "name", obj.GetName())
 	// Warnings are independent of Validator: a webhook may warn without
 	// rejecting anything, and gating them on Validator would silently drop
 	// the warnings such a webhook was written to emit.
 	if w.Validator != nil {
-		return w.warnings(obj), w.Validator.Validate(obj)
+		if err := w.Validator.Validate(obj); err != nil {
+			return w.warnings(obj), err
+		}
+	}
+	if w.AccessValidator != nil {
+		if err := w.AccessValidator.ValidateAccess(ctx, obj); err != nil {
+			return w.warnings(obj), err
+		}
 	}
 	return w.warnings(obj), nil
 }
 
 // ValidateUpdate implements admission.Validator.
-func (w *GenericWebhook[T]) ValidateUpdate(_ context.Context, oldObj, newObj T) (admission.Warnings, error) {
+func (w *GenericWebhook[T]) ValidateUpdate(ctx context.Context, oldObj, newObj T) (admission.Warnings, error) {
 	w.Logger.V(1).Info("validate update", "name", newObj.GetName())
 	if w.Validator != nil {
 		if err := w.Validator.Validate(newObj); err != nil {
@@ -100,6 +116,11 @@ func (w *GenericWebhook[T]) ValidateUpdate(_ context.Context, oldObj, newObj T)
 			return w.warnings(newObj), err
 		}
 	}
+	if w.AccessValidator != nil {
+		if err := w.AccessValidator.ValidateAccess(ctx, newObj); err != nil {
+			return w.warnings(newObj), err
+		}
+	}
 	return w.warnings(newObj), nil
 }
 
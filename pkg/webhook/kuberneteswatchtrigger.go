@@ -5,8 +5,13 @@
 package webhook
 
 import (
+	"context"
 	"fmt"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
@@ -17,11 +22,22 @@ import (
 
 type KubernetesWatchTrigger struct {
 	GenericWebhook[*v1.KubernetesWatchTrigger]
+	// mapper resolves the watched kind to its resource and scope; kubeClient
+	// asks the API server whether the requester may read it.
+	mapper     meta.RESTMapper
+	kubeClient kubernetes.Interface
 }
 
 func (r *KubernetesWatchTrigger) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	r.Logger = loggerfactory.GetLogger().WithName("kuberneteswatchtrigger-resource")
 	r.Validator = r
+	r.AccessValidator = r
+	r.mapper = mgr.GetRESTMapper()
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r.kubeClient = kubeClient
 	return r.GenericWebhook.SetupWebhookWithManager(mgr, &v1.KubernetesWatchTrigger{})
 }
 
@@ -37,7 +53,8 @@ var _ admission.Validator[*v1.KubernetesWatchTrigger] = &KubernetesWatchTrigger{
 func (r *KubernetesWatchTrigger) Validate(new *v1.KubernetesWatchTrigger) error {
 	// Field rules (type enum, namespace DNS, function-reference) are enforced by
 	// the API server via CEL; the webhook runs only the non-CEL checks
-	// (label-selector qualified key/value) plus the cross-namespace check below.
+	// (label-selector qualified key/value, apiVersion syntax) plus the
+	// cross-namespace check below and the RBAC check in ValidateAccess.
 	if err := new.ValidateForAdmission(); err != nil {
 		return v1.AggregateValidationErrors("Watch", err)
 	}
@@ -53,3 +70,69 @@ func (r *KubernetesWatchTrigger) Validate(new *v1.KubernetesWatchTrigger) error
 	}
 	return nil
 }
+
+// watchVerbs are what the kubewatcher does with the watched kind: it lists to
+// find a starting resource version and then watches.
+var watchVerbs = []string{"list", "watch"}
+
+// ValidateAccess refuses a trigger whose creator could not list and watch the
+// watched kind in the trigger's namespace themselves. The kubewatcher watches
+// with its own, broader credentials and delivers every event to the trigger's
+// function, so without this check a KubernetesWatchTrigger would be a way to
+// read Secrets or any other kind the requester has no access to. It also
+// refuses kinds the API server does not serve and cluster-scoped kinds, which
+// the kubewatcher would refuse to watch anyway.
+func (r *KubernetesWatchTrigger) ValidateAccess(ctx context.Context, new *v1.KubernetesWatchTrigger) error {
+	gvk, err := new.Spec.GroupVersionKind()
+	if err != nil {
+		return ferror.MakeError(ferror.ErrorInvalidArgument, fmt.Sprintf("KubernetesWatchTrigger.spec: %v", err))
+	}
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("KubernetesWatchTrigger.spec: kind %s is not served by the API server", gvk))
+	}
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", gvk, err)
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("KubernetesWatchTrigger.spec: %s is cluster-scoped; only namespaced kinds can be watched", gvk.Kind))
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, v := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	for _, verb := range watchVerbs {
+		sar := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   req.UserInfo.Username,
+				Groups: req.UserInfo.Groups,
+				UID:    req.UserInfo.UID,
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: new.Namespace,
+					Verb:      verb,
+					Group:     mapping.Resource.Group,
+					Version:   mapping.Resource.Version,
+					Resource:  mapping.Resource.Resource,
+				},
+			},
+		}
+		resp, err := r.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("checking %s access to %s: %w", verb, mapping.Resource.GroupResource(), err)
+		}
+		if !resp.Status.Allowed {
+			return ferror.MakeError(ferror.ErrorInvalidArgument,
+				fmt.Sprintf("KubernetesWatchTrigger: user %q cannot %s %s in namespace %q, so cannot watch them through a trigger",
+					req.UserInfo.Username, verb, mapping.Resource.GroupResource(), new.Namespace))
+		}
+	}
+	return nil
+}
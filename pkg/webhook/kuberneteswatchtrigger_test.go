@@ -5,9 +5,20 @@
 package webhook
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
 	v1 "github.com/fission/fission/pkg/apis/core/v1"
 )
 
@@ -66,3 +77,82 @@ func TestKubernetesWatchTriggerWebhook_Validate_CrossNamespace(t *testing.T) {
 		})
 	}
 }
+
+// sarWebhook returns a KubernetesWatchTrigger webhook whose access reviews
+// allow exactly the resources in allowed ("group/resource" → verbs).
+func sarWebhook(t *testing.T, allowed map[string][]string) (*KubernetesWatchTrigger, *[]authorizationv1.SubjectAccessReviewSpec) {
+	t.Helper()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Node"}, meta.RESTScopeRoot)
+
+	var reviews []authorizationv1.SubjectAccessReviewSpec
+	kc := kubefake.NewClientset()
+	kc.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		sar := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviews = append(reviews, sar.Spec)
+		attrs := sar.Spec.ResourceAttributes
+		sar.Status.Allowed = slices.Contains(allowed[attrs.Group+"/"+attrs.Resource], attrs.Verb)
+		return true, sar, nil
+	})
+	r := &KubernetesWatchTrigger{mapper: mapper, kubeClient: kc}
+	r.Validator = r
+	r.AccessValidator = r
+	return r, &reviews
+}
+
+func TestKubernetesWatchTriggerWebhook_ValidateAccess(t *testing.T) {
+	ctx := admission.NewContextWithRequest(t.Context(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}},
+		},
+	})
+	kwt := func(apiVersion, kind string) *v1.KubernetesWatchTrigger {
+		w := makeValidKWT("default", "default")
+		if kind != "" {
+			w.Spec.Type = ""
+			w.Spec.APIVersion = apiVersion
+			w.Spec.Kind = kind
+		}
+		return w
+	}
+
+	cases := []struct {
+		name    string
+		w       *v1.KubernetesWatchTrigger
+		allowed map[string][]string
+		wantErr string
+	}{
+		{name: "legacy type with access", w: kwt("", ""), allowed: map[string][]string{"/pods": {"list", "watch"}}},
+		{name: "legacy type without watch", w: kwt("", ""), allowed: map[string][]string{"/pods": {"list"}},
+			wantErr: `user "alice" cannot watch pods`},
+		{name: "CRD with access", w: kwt("argoproj.io/v1alpha1", "Workflow"),
+			allowed: map[string][]string{"argoproj.io/workflows": {"list", "watch"}}},
+		{name: "secrets without access", w: kwt("v1", "Secret"), wantErr: `user "alice" cannot list secrets`},
+		{name: "unknown kind", w: kwt("cert-manager.io/v1", "Certificate"), wantErr: "is not served by the API server"},
+		{name: "cluster-scoped kind", w: kwt("v1", "Node"), allowed: map[string][]string{"/nodes": {"list", "watch"}},
+			wantErr: "Node is cluster-scoped"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, reviews := sarWebhook(t, tc.allowed)
+			_, err := r.ValidateCreate(ctx, tc.w)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected acceptance, got: %v", err)
+				}
+				for _, spec := range *reviews {
+					if spec.User != "alice" || !slices.Equal(spec.Groups, []string{"team-a"}) || spec.ResourceAttributes.Namespace != "default" {
+						t.Fatalf("review not made for the requester in the trigger namespace: %+v", spec)
+					}
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
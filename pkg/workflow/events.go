@@ -106,12 +106,12 @@ func decodeEvent(se statestore.Event) (Event, error) {
 // streamName is the run's EventLog stream. Keyed on UID, not name: a
 // delete-and-recreate under the same name must never resume the old log.
 func streamName(run *fv1.WorkflowRun) string {
-	return streamNameForUID(string(run.UID))
+	return StreamNameForUID(string(run.UID))
 }
 
-// streamNameForUID is streamName for call sites that only hold the UID
-// (timers, GC, the history API).
-func streamNameForUID(uid string) string {
+// StreamNameForUID is streamName for call sites that only hold the UID
+// (timers, GC, the history API, `fission statestore export`).
+func StreamNameForUID(uid string) string {
 	return "wfrun/" + uid
 }
 
@@ -40,6 +40,7 @@ import (
 	_ "github.com/fission/fission/pkg/statestore/client"   // embedded-mode driver
 	_ "github.com/fission/fission/pkg/statestore/memory"   // dev/test driver
 	_ "github.com/fission/fission/pkg/statestore/postgres" // external-mode driver
+	_ "github.com/fission/fission/pkg/statestore/redis"    // external-mode driver
 	storagesvcClient "github.com/fission/fission/pkg/storagesvc/client"
 	"github.com/fission/fission/pkg/utils/crmanager"
 	"github.com/fission/fission/pkg/utils/httpserver"
@@ -75,7 +75,7 @@ func (e *Engine) timerPollOnce(ctx context.Context) int {
 		// wrote in between: harmless — the fold's TimersFired set is
 		// idempotent and no W-invariant covers timer events.
 		ev := Event{Type: EvTimerFired, State: tm.State, Branch: tm.Branch, Region: tm.Region, Attempt: tm.Attempt}
-		stream := streamNameForUID(tm.UID)
+		stream := StreamNameForUID(tm.UID)
 		head, err := e.el.Head(ctx, stream)
 		if err != nil {
 			e.logger.Error(err, "reading stream head for timer; will retry", "run", tm.Name, "state", tm.State)
@@ -305,6 +305,42 @@ func TestCELKubernetesWatchTriggerNamespace(t *testing.T) {
 	}
 }
 
+func TestCELKubernetesWatchTriggerKind(t *testing.T) {
+	fc := client(t)
+	kwt := func(name, typ, apiVersion, kind string) *fv1.KubernetesWatchTrigger {
+		return &fv1.KubernetesWatchTrigger{
+			Name: name, Namespace: ns,
+			Spec: fv1.KubernetesWatchTriggerSpec{
+				Namespace:         ns,
+				Type:              typ,
+				APIVersion:        apiVersion,
+				Kind:              kind,
+				FunctionReference: fv1.FunctionReference{Type: fv1.FunctionReferenceTypeFunctionName, Name: "fn"},
+			},
+		}
+	}
+	cases := []struct {
+		obj     *fv1.KubernetesWatchTrigger
+		wantErr bool
+	}{
+		{kwt("kwt-kind-ok", "", "apps/v1", "Deployment"), false},
+		{kwt("kwt-kind-and-type", "POD", "apps/v1", "Deployment"), true},
+		{kwt("kwt-kind-only", "", "", "Deployment"), true},
+		{kwt("kwt-apiversion-only", "POD", "apps/v1", ""), true},
+		{kwt("kwt-neither", "", "", ""), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.obj.Name, func(t *testing.T) {
+			_, err := fc.CoreV1().KubernetesWatchTriggers(ns).Create(t.Context(), tc.obj, metav1.CreateOptions{})
+			if tc.wantErr {
+				require.Error(t, err, "apiserver should reject a spec without exactly one of type or apiVersion/kind")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 // TestCELFunctionVersionAndAliasInstall is the RFC-0025 early CEL-cost check
 // (Task 1, Step 3b): FunctionVersionSpec embeds a full FunctionSpec one level
 // deeper than Function itself (Function.spec vs FunctionVersion.spec.snapshot),
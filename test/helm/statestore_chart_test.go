@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/fission/fission/pkg/svcinfo"
 )
@@ -32,6 +34,64 @@ func TestStatestoreChartPorts(t *testing.T) {
 	})
 }
 
+// TestStatestoreRaftChart checks the raft engine's StatefulSet: one peer-list
+// DSN naming every replica on svcinfo.PortStatestorePeer through the headless
+// peer Service, the peer port open between replicas, and no SQLite leftovers.
+func TestStatestoreRaftChart(t *testing.T) {
+	docs := render(t,
+		"--set", "statestore.enabled=true", "--set", "statestore.mode=embedded",
+		"--set", "statestore.embedded.engine=raft", "--set", "networkPolicy.enabled=true")
+
+	t.Run("statefulset replaces the deployment", func(t *testing.T) {
+		assert.Nil(t, docs.find("Deployment", svcinfo.SvcStatestore))
+		assert.Nil(t, docs.find("PersistentVolumeClaim", svcinfo.SvcStatestore))
+		require.NotNil(t, docs.find("PodDisruptionBudget", svcinfo.SvcStatestore))
+	})
+
+	t.Run("replicas share one peer list", func(t *testing.T) {
+		sts := findAs[appsv1.StatefulSet](t, docs, "StatefulSet", svcinfo.SvcStatestore)
+		require.EqualValues(t, 3, *sts.Spec.Replicas)
+		assert.Equal(t, appsv1.ParallelPodManagement, sts.Spec.PodManagementPolicy)
+		c := sts.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, fmt.Sprint(svcinfo.PortStatestore), argAfter(c.Args, "--statestorePort"))
+		driver, _ := envValue(c, "STATESTORE_DRIVER")
+		assert.Equal(t, "raft", driver)
+		dsn, _ := envValue(c, "STATESTORE_DSN")
+		assert.Contains(t, dsn, "self=$(POD_NAME)")
+		for i := range 3 {
+			assert.Contains(t, dsn, fmt.Sprintf("statestore-%d=http://statestore-%d.statestore-peer.", i, i))
+		}
+		assert.Contains(t, dsn, fmt.Sprintf(".svc:%d", svcinfo.PortStatestorePeer))
+	})
+
+	t.Run("peer service publishes unready replicas", func(t *testing.T) {
+		svc := findAs[corev1.Service](t, docs, "Service", "statestore-peer")
+		assert.Equal(t, corev1.ClusterIPNone, svc.Spec.ClusterIP)
+		assert.True(t, svc.Spec.PublishNotReadyAddresses, "a replica is ready only once its peers can reach it")
+		require.Len(t, svc.Spec.Ports, 1)
+		assert.EqualValues(t, svcinfo.PortStatestorePeer, svc.Spec.Ports[0].Port)
+	})
+
+	t.Run("networkpolicy admits peers on the peer port", func(t *testing.T) {
+		np := networkPolicy(t, docs, "statestore")
+		var found bool
+		for _, rule := range np.Spec.Ingress {
+			for _, p := range rule.Ports {
+				found = found || p.Port.IntValue() == svcinfo.PortStatestorePeer
+			}
+		}
+		assert.True(t, found, "replicas cannot replicate without the peer port")
+		assert.True(t, npAllowsFromSvc(np, svcinfo.SvcStatestore))
+	})
+
+	t.Run("unknown engine fails the render", func(t *testing.T) {
+		_, err := renderErr(t,
+			"--set", "statestore.enabled=true", "--set", "statestore.mode=embedded",
+			"--set", "statestore.embedded.engine=etcd")
+		require.Error(t, err)
+	})
+}
+
 // TestStateSvcChart is the drift check for the RFC-0023 statesvc head: port
 // constants against svcinfo, statestore client-driver env wiring, and
 // membership in the statestore NetworkPolicy allowlist (the silent-drop bite).